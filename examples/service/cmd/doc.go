@@ -50,7 +50,8 @@ and included in health checks.
   - Bootstrap lifecycle: startup and shutdown are coordinated with a shared
     wait group and shutdown signal channel.
   - Structured logging: configurable format and level with build metadata
-    (`program`, `version`, `release`) attached to each record.
+    (`program`, `version`, `release`) attached to each record, written to
+    stderr or to a rotating file (`log.file`).
   - HTTP stack: request instrumentation middleware, Prometheus metrics
     exposure, and standard failure handlers.
   - Health reporting: default status endpoint can be upgraded to dependency-
@@ -15,6 +15,7 @@ import (
 	"github.com/tecnickcom/nurago/pkg/bootstrap"
 	"github.com/tecnickcom/nurago/pkg/healthcheck"
 	"github.com/tecnickcom/nurago/pkg/httpclient"
+	"github.com/tecnickcom/nurago/pkg/httprecover"
 	"github.com/tecnickcom/nurago/pkg/httpserver"
 	"github.com/tecnickcom/nurago/pkg/httputil"
 	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
@@ -73,10 +74,21 @@ func bind(cfg *appConfig, appInfo *jsendx.AppInfo, mtr instr.Metrics, wg *sync.W
 			return err
 		}
 
-		middleware := func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+		instrument := func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
 			return m.InstrumentHandler(args.Path, next.ServeHTTP)
 		}
 
+		// Recovers the handler panics with a logged stack trace, a metrics error
+		// count, and a JSendX 500 response. It goes first so it also covers the
+		// instrumentation, which then records the 500 response.
+		recoverer := httprecover.New(
+			httprecover.WithLogger(l),
+			httprecover.WithMetrics(m),
+			httprecover.WithAppInfo(appInfo),
+		)
+
+		middleware := []httpserver.MiddlewareFn{recoverer.MiddlewareFn, instrument}
+
 		// MONITORING SERVER
 
 		httpMonitoringOpts := []httpserver.Option{
@@ -85,7 +97,7 @@ func bind(cfg *appConfig, appInfo *jsendx.AppInfo, mtr instr.Metrics, wg *sync.W
 			httpserver.WithRequestTimeout(time.Duration(cfg.Servers.Monitoring.Timeout) * time.Second),
 			httpserver.WithMetricsHandlerFunc(m.MetricsHandlerFunc()),
 			httpserver.WithTraceIDHeaderName(traceid.DefaultHeader),
-			httpserver.WithMiddlewareFn(middleware),
+			httpserver.WithMiddlewareFn(middleware...),
 			httpserver.WithNotFoundHandlerFunc(jsx.DefaultNotFoundHandlerFunc(appInfo)),
 			httpserver.WithMethodNotAllowedHandlerFunc(jsx.DefaultMethodNotAllowedHandlerFunc(appInfo)),
 			httpserver.WithPanicHandlerFunc(jsx.DefaultPanicHandlerFunc(appInfo)),
@@ -99,6 +111,8 @@ func bind(cfg *appConfig, appInfo *jsendx.AppInfo, mtr instr.Metrics, wg *sync.W
 			httpserver.WithShutdownSignalChan(sc),
 		}
 
+		httpMonitoringOpts = append(httpMonitoringOpts, browserSecurityOpts(cfgServer(cfg.Servers.Monitoring))...)
+
 		httpMonitoringServer, err := httpserver.New(ctx, httpserver.NopBinder(), httpMonitoringOpts...)
 		if err != nil {
 			return fmt.Errorf("error creating monitoring HTTP server: %w", err)
@@ -305,7 +319,7 @@ func startServiceServer(
 	binder httpserver.Binder,
 	srv cfgServer,
 	l *slog.Logger,
-	middleware httpserver.MiddlewareFn,
+	middleware []httpserver.MiddlewareFn,
 	logRedactor *redact.Redactor,
 	wg *sync.WaitGroup,
 	sc chan struct{},
@@ -314,7 +328,7 @@ func startServiceServer(
 		httpserver.WithLogger(l),
 		httpserver.WithServerAddr(srv.Address),
 		httpserver.WithRequestTimeout(time.Duration(srv.Timeout) * time.Second),
-		httpserver.WithMiddlewareFn(middleware),
+		httpserver.WithMiddlewareFn(middleware...),
 		httpserver.WithTraceIDHeaderName(traceid.DefaultHeader),
 		httpserver.WithEnableDefaultRoutes(httpserver.PingRoute),
 		httpserver.WithRedactFn(logRedactor.BytesToString),
@@ -322,6 +336,8 @@ func startServiceServer(
 		httpserver.WithShutdownSignalChan(sc),
 	}
 
+	opts = append(opts, browserSecurityOpts(srv)...)
+
 	server, err := httpserver.New(ctx, binder, opts...)
 	if err != nil {
 		return fmt.Errorf("error creating %s HTTP server: %w", name, err)
@@ -332,6 +348,40 @@ func startServiceServer(
 	return nil
 }
 
+// browserSecurityOpts returns the server options enabling the configured
+// security response headers and CORS policy.
+//
+// The security headers start from httpserver.DefaultSecurityHeadersConfig, with
+// the values exposed in the configuration overridden (an empty string omits
+// its header). CORS is only needed when browser applications on other origins
+// call the server.
+func browserSecurityOpts(srv cfgServer) []httpserver.Option {
+	opts := []httpserver.Option{}
+
+	if srv.SecurityHeaders.Enabled {
+		sh := httpserver.DefaultSecurityHeadersConfig()
+		sh.HSTSMaxAge = time.Duration(srv.SecurityHeaders.HSTSMaxAge) * time.Second
+		sh.ContentSecurityPolicy = srv.SecurityHeaders.ContentSecurityPolicy
+		sh.FrameOptions = srv.SecurityHeaders.FrameOptions
+		sh.ReferrerPolicy = srv.SecurityHeaders.ReferrerPolicy
+
+		opts = append(opts, httpserver.WithSecurityHeaders(sh))
+	}
+
+	if srv.CORS.Enabled {
+		opts = append(opts, httpserver.WithCORS(httpserver.CORSConfig{
+			AllowedOrigins:   srv.CORS.AllowedOrigins,
+			AllowedMethods:   srv.CORS.AllowedMethods,
+			AllowedHeaders:   srv.CORS.AllowedHeaders,
+			ExposedHeaders:   srv.CORS.ExposedHeaders,
+			AllowCredentials: srv.CORS.AllowCredentials,
+			MaxAge:           time.Duration(srv.CORS.MaxAge) * time.Second,
+		}))
+	}
+
+	return opts
+}
+
 // newDatabase creates, instruments, and health-checks a named SQL connection.
 //
 // It sets connection pool options, startup ping behavior, metrics
@@ -14,6 +14,7 @@ import (
 	"github.com/nuragoexampleowner/nuragoexample/internal/metrics"
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/bootstrap"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
 	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
 	libmtr "github.com/tecnickcom/nurago/pkg/metrics"
 )
@@ -307,3 +308,44 @@ func Test_bind(t *testing.T) {
 		})
 	}
 }
+
+func Test_browserSecurityOpts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		srv     cfgServer
+		wantLen int
+	}{
+		{
+			name:    "disabled",
+			srv:     cfgServer{},
+			wantLen: 0,
+		},
+		{
+			name:    "security headers",
+			srv:     cfgServer{SecurityHeaders: cfgSecurityHeaders{Enabled: true, HSTSMaxAge: 60}},
+			wantLen: 1,
+		},
+		{
+			name: "security headers and cors",
+			srv: cfgServer{
+				CORS:            cfgCORS{Enabled: true, AllowedOrigins: []string{"https://example.com"}, MaxAge: 600},
+				SecurityHeaders: cfgSecurityHeaders{Enabled: true},
+			},
+			wantLen: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := browserSecurityOpts(tt.srv)
+			require.Len(t, opts, tt.wantLen)
+
+			_, err := httpserver.New(t.Context(), httpserver.NopBinder(), append(opts, httpserver.WithServerAddr(":0"))...)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -141,15 +141,35 @@ func newLogConfig(cfg *appConfig, version, release, argLogFormat, argLogLevel st
 		slog.String("release", release),
 	}
 
-	// logFormat and logLevel were already parsed and validated above and the
-	// other options are static, so NewConfig cannot fail here; the error is
-	// intentionally discarded.
-	logcfg, _ := logutil.NewConfig(
+	opts := []logutil.Option{
 		logutil.WithOutWriter(os.Stderr),
 		logutil.WithFormat(logFormat),
 		logutil.WithLevel(logLevel),
 		logutil.WithCommonAttr(logattr...),
-	)
+	}
+
+	if cfg.Log.File.Path != "" {
+		opts = append(opts, logutil.WithFileOutput(newLogFileConfig(&cfg.Log.File)))
+	}
+
+	logcfg, err := logutil.NewConfig(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("log config error: %w", err)
+	}
 
 	return logcfg, nil
 }
+
+// newLogFileConfig converts the log file settings to the rotating file writer
+// configuration, reopening the file on SIGHUP for external rotation tools.
+func newLogFileConfig(fc *config.LogFileConfig) logutil.FileWriterConfig {
+	return logutil.FileWriterConfig{
+		Path:           fc.Path,
+		MaxSize:        int64(fc.MaxSize) * 1024 * 1024,
+		RotateEvery:    time.Duration(fc.RotateInterval) * time.Second,
+		MaxBackups:     fc.MaxBackups,
+		MaxAge:         time.Duration(fc.MaxAge) * 24 * time.Hour,
+		Compress:       fc.Compress,
+		ReopenOnSignal: true,
+	}
+}
@@ -2,11 +2,15 @@ package cli
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/bootstrap"
+	"github.com/tecnickcom/nurago/pkg/config"
+	"github.com/tecnickcom/nurago/pkg/logutil"
 	"github.com/tecnickcom/nurago/pkg/testutil"
 )
 
@@ -165,3 +169,38 @@ func matchHelpOutput(t *testing.T, out string) {
 
 	t.Errorf("The help message was expected")
 }
+
+func Test_newLogConfig_file(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "log", "service.log")
+
+	cfg := &appConfig{}
+	cfg.Log.Format = "JSON"
+	cfg.Log.Level = "INFO"
+	cfg.Log.File = config.LogFileConfig{Path: path, MaxSize: 1, RotateInterval: 3600, MaxBackups: 2, MaxAge: 7}
+
+	logcfg, err := newLogConfig(cfg, "1.2.3", "4", "", "")
+	require.NoError(t, err)
+
+	logcfg.SlogLogger().Info("to file")
+	require.NoError(t, logcfg.Close(t.Context()))
+
+	data, err := os.ReadFile(path) //nolint:gosec
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"msg":"to file"`)
+
+	require.Equal(t, logutil.FileWriterConfig{
+		Path:           path,
+		MaxSize:        1 << 20,
+		RotateEvery:    time.Hour,
+		MaxBackups:     2,
+		MaxAge:         7 * 24 * time.Hour,
+		ReopenOnSignal: true,
+	}, newLogFileConfig(&cfg.Log.File))
+
+	cfg.Log.File.Path = filepath.Join(path, "invalid")
+
+	_, err = newLogConfig(cfg, "1.2.3", "4", "", "")
+	require.Error(t, err)
+}
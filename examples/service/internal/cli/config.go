@@ -29,9 +29,31 @@ const (
 // validatorNewFn defines the validator constructor and can be overwritten for testing.
 var validatorNewFn = validator.New //nolint:gochecknoglobals
 
+// cfgCORS contains the Cross-Origin Resource Sharing policy of a server.
+type cfgCORS struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	AllowedOrigins   []string `mapstructure:"allowed_origins"   validate:"required_if=Enabled true"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"           validate:"min=0"`
+}
+
+// cfgSecurityHeaders contains the security response headers of a server.
+type cfgSecurityHeaders struct {
+	Enabled               bool   `mapstructure:"enabled"`
+	HSTSMaxAge            int    `mapstructure:"hsts_max_age"            validate:"min=0"`
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	FrameOptions          string `mapstructure:"frame_options"`
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`
+}
+
 type cfgServer struct {
-	Address string `mapstructure:"address" validate:"required,hostname_port"`
-	Timeout int    `mapstructure:"timeout" validate:"required,min=1"`
+	Address         string             `mapstructure:"address"          validate:"required,hostname_port"`
+	Timeout         int                `mapstructure:"timeout"          validate:"required,min=1"`
+	CORS            cfgCORS            `mapstructure:"cors"`
+	SecurityHeaders cfgSecurityHeaders `mapstructure:"security_headers"`
 }
 
 type cfgServerMonitoring cfgServer
@@ -88,8 +110,8 @@ type appConfig struct {
 // SetDefaults registers baseline configuration values used when no explicit
 // value is provided by files, remote providers, or environment variables.
 //
-// It sets defaults for server endpoints, timeouts, external clients, and
-// database pools.
+// It sets defaults for server endpoints, timeouts, browser security headers,
+// external clients, and database pools.
 func (c *appConfig) SetDefaults(v config.Viper) {
 	v.SetDefault("enabled", true)
 
@@ -101,6 +123,13 @@ func (c *appConfig) SetDefaults(v config.Viper) {
 
 	v.SetDefault("servers.public.address", ":8073")
 	v.SetDefault("servers.public.timeout", 60)
+	v.SetDefault("servers.public.cors.enabled", false)
+	v.SetDefault("servers.public.cors.max_age", 600)
+	v.SetDefault("servers.public.security_headers.enabled", true)
+	v.SetDefault("servers.public.security_headers.hsts_max_age", 63072000)
+	v.SetDefault("servers.public.security_headers.content_security_policy", "default-src 'none'; frame-ancestors 'none'")
+	v.SetDefault("servers.public.security_headers.frame_options", "DENY")
+	v.SetDefault("servers.public.security_headers.referrer_policy", "no-referrer")
 
 	v.SetDefault("clients.ipify.address", "https://api.ipify.org")
 	v.SetDefault("clients.ipify.timeout", 1)
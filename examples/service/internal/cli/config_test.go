@@ -20,7 +20,7 @@ func Test_appConfig_SetDefaults(t *testing.T) {
 	require.True(t, v.GetBool("enabled"))
 	require.Equal(t, dbDriver, v.GetString("db.main.driver"))
 	require.Equal(t, dbDriver, v.GetString("db.read.driver"))
-	require.Len(t, v.AllKeys(), 24)
+	require.Len(t, v.AllKeys(), 31)
 }
 
 func getValidTestConfig() appConfig {
@@ -215,6 +215,21 @@ func Test_appConfig_Validate(t *testing.T) {
 			fcfg:    func(cfg appConfig) appConfig { cfg.Servers.Public.Timeout = 0; return cfg },
 			wantErr: true,
 		},
+		{
+			name:    "empty servers.public.cors.allowed_origins",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Servers.Public.CORS.Enabled = true; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid servers.public.cors.max_age",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Servers.Public.CORS.MaxAge = -1; return cfg },
+			wantErr: true,
+		},
+		{
+			name:    "invalid servers.public.security_headers.hsts_max_age",
+			fcfg:    func(cfg appConfig) appConfig { cfg.Servers.Public.SecurityHeaders.HSTSMaxAge = -1; return cfg },
+			wantErr: true,
+		},
 		{
 			name:    "empty clients",
 			fcfg:    func(cfg appConfig) appConfig { cfg.Clients = cfgClients{}; return cfg },
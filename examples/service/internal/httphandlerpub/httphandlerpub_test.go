@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/openapi"
 )
 
 func TestNew(t *testing.T) {
@@ -49,3 +51,26 @@ func TestHTTPHandlerPublic_handleGenUID(t *testing.T) {
 	require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
 	require.NotEmpty(t, string(body))
 }
+
+func TestHTTPHandlerPublic_openapi(t *testing.T) {
+	t.Parallel()
+
+	spec, err := openapi.LoadFile("../../openapi_public.yaml", openapi.WithStrict())
+	require.NoError(t, err)
+
+	hh := New(nil, nil)
+
+	routes, err := spec.Bind(hh.BindHTTP(t.Context()))
+	require.NoError(t, err, "all the public routes must be documented")
+
+	for _, route := range routes {
+		handler := httpserver.ApplyMiddleware(httpserver.MiddlewareArgs{}, route.Handler, route.Middleware...)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequestWithContext(t.Context(), route.Method, route.Path, nil)
+
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
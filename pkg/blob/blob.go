@@ -0,0 +1,105 @@
+/*
+Package blob provides a storage-agnostic [Store] of binary objects (blobs)
+addressed by key, so services can depend on an interface instead of a specific
+storage service and switch the storage through configuration.
+
+# Backends
+
+  - [MemoryStore] keeps the objects in process, for unit tests.
+  - [FileStore] keeps the objects as files under a local directory, for local
+    development without an S3 emulator.
+  - [S3Store] keeps the objects in an AWS S3 bucket through
+    [github.com/tecnickcom/nurago/pkg/s3], optionally under a key prefix.
+
+[Open] returns the backend matching the scheme of a storage URL, so it can be
+selected by a configuration value:
+
+	mem://
+	file:///var/lib/service/data
+	file:relative/data
+	s3://bucket/optional/prefix?region=eu-west-1&endpoint=http://localhost:4566
+
+# Keys
+
+The keys are slash-separated paths, like "reports/2024/05.json", valid as
+[io/fs.ValidPath]: not empty, without leading, trailing or repeated slashes,
+and without "." or ".." elements ([ValidKey]). Invalid keys are rejected with
+[ErrInvalidKey] by every backend, so keys behave the same everywhere and
+cannot escape the root directory of a [FileStore].
+
+# Conformance
+
+The [github.com/tecnickcom/nurago/pkg/blob/blobtest] package contains the
+conformance tests every backend must pass, and can test custom backends.
+
+# Usage
+
+	store, err := blob.Open(ctx, cfg.StorageURL)
+	if err != nil {
+	    return err
+	}
+
+	err = store.Put(ctx, "reports/latest.json", reader)
+	// ...
+
+	rc, err := store.Get(ctx, "reports/latest.json")
+	if errors.Is(err, blob.ErrNotFound) {
+	    // ...
+	}
+	defer rc.Close()
+
+	for info, err := range store.List(ctx, "reports/") {
+	    // ...
+	}
+*/
+package blob
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"iter"
+	"time"
+)
+
+// Store stores binary objects by key. The implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the content of the object key, which the caller must close.
+	// It returns an error matching [ErrNotFound] when the object does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put stores the content of r as the object key, replacing any existing
+	// object; a nil r stores an empty object.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Delete removes the object key; a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// List returns an iterator over the objects whose key starts with prefix,
+	// in lexicographic key order; an empty prefix lists all the objects. A
+	// failure is yielded with a zero Info and ends the iteration.
+	List(ctx context.Context, prefix string) iter.Seq2[Info, error]
+
+	// Stat returns the information of the object key. It returns an error
+	// matching [ErrNotFound] when the object does not exist.
+	Stat(ctx context.Context, key string) (*Info, error)
+}
+
+// Info describes a stored object.
+type Info struct {
+	// Key is the object key.
+	Key string
+
+	// Size is the object size in bytes.
+	Size int64
+
+	// LastModified is the time the object was last stored.
+	LastModified time.Time
+}
+
+// ValidKey reports whether key is a valid object key: a slash-separated path
+// valid as [io/fs.ValidPath], other than the root ".".
+func ValidKey(key string) bool {
+	return key != "." && fs.ValidPath(key)
+}
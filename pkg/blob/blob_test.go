@@ -0,0 +1,36 @@
+package blob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "a", want: true},
+		{key: "a/b.txt", want: true},
+		{key: "a b/.c/d..e", want: true},
+		{key: ""},
+		{key: "."},
+		{key: ".."},
+		{key: "/a"},
+		{key: "a/"},
+		{key: "a//b"},
+		{key: "a/./b"},
+		{key: "a/../b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, ValidKey(tt.key))
+		})
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Package blobtest provides the conformance tests of the
+[github.com/tecnickcom/nurago/pkg/blob.Store] implementations, so every backend
+behaves the same way.
+
+A backend test calls [Run] with a function returning a new empty store:
+
+	func TestConformance(t *testing.T) {
+	    t.Parallel()
+
+	    blobtest.Run(t, func(t *testing.T) blob.Store {
+	        store, err := blob.NewFileStore(t.TempDir())
+	        require.NoError(t, err)
+
+	        return store
+	    })
+	}
+*/
+package blobtest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/blob"
+)
+
+// NewStoreFunc returns a new empty store for a test.
+type NewStoreFunc func(t *testing.T) blob.Store
+
+// Run runs the conformance tests as parallel subtests of t, each with a new
+// store returned by newStore.
+func Run(t *testing.T, newStore NewStoreFunc) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s blob.Store)
+	}{
+		{name: "PutGet", fn: testPutGet},
+		{name: "Overwrite", fn: testOverwrite},
+		{name: "EmptyObject", fn: testEmptyObject},
+		{name: "LargeObject", fn: testLargeObject},
+		{name: "NotFound", fn: testNotFound},
+		{name: "Delete", fn: testDelete},
+		{name: "Stat", fn: testStat},
+		{name: "List", fn: testList},
+		{name: "ListStop", fn: testListStop},
+		{name: "InvalidKey", fn: testInvalidKey},
+		{name: "ReaderError", fn: testReaderError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// put stores data as the object key.
+func put(t *testing.T, s blob.Store, key, data string) {
+	t.Helper()
+
+	require.NoError(t, s.Put(t.Context(), key, strings.NewReader(data)))
+}
+
+// get returns the content of the object key.
+func get(t *testing.T, s blob.Store, key string) string {
+	t.Helper()
+
+	rc, err := s.Get(t.Context(), key)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	return string(data)
+}
+
+// list returns the keys listed with prefix.
+func list(t *testing.T, s blob.Store, prefix string) []string {
+	t.Helper()
+
+	keys := make([]string, 0)
+
+	for info, err := range s.List(t.Context(), prefix) {
+		require.NoError(t, err)
+
+		keys = append(keys, info.Key)
+	}
+
+	return keys
+}
+
+func testPutGet(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	put(t, s, "a.txt", "alpha")
+	put(t, s, "dir/sub/b.json", `{"b":true}`)
+
+	require.Equal(t, "alpha", get(t, s, "a.txt"))
+	require.Equal(t, `{"b":true}`, get(t, s, "dir/sub/b.json"))
+}
+
+func testOverwrite(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	put(t, s, "key", "first version")
+	put(t, s, "key", "second")
+
+	require.Equal(t, "second", get(t, s, "key"))
+
+	info, err := s.Stat(t.Context(), "key")
+	require.NoError(t, err)
+	require.Equal(t, int64(6), info.Size)
+}
+
+func testEmptyObject(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	put(t, s, "empty", "")
+	require.NoError(t, s.Put(t.Context(), "nil", nil))
+
+	require.Empty(t, get(t, s, "empty"))
+	require.Empty(t, get(t, s, "nil"))
+	require.Equal(t, []string{"empty", "nil"}, list(t, s, ""))
+}
+
+func testLargeObject(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+
+	require.NoError(t, s.Put(t.Context(), "large.bin", iotest.HalfReader(bytes.NewReader(data))))
+	require.Equal(t, string(data), get(t, s, "large.bin"))
+}
+
+func testNotFound(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	put(t, s, "dir/a", "a")
+
+	for _, key := range []string{"missing", "dir", "dir/a/b", "dir/b"} {
+		rc, err := s.Get(t.Context(), key)
+		require.ErrorIs(t, err, blob.ErrNotFound, key)
+		require.Nil(t, rc)
+
+		info, err := s.Stat(t.Context(), key)
+		require.ErrorIs(t, err, blob.ErrNotFound, key)
+		require.Nil(t, info)
+	}
+}
+
+func testDelete(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	put(t, s, "dir/a", "a")
+	put(t, s, "dir/sub/b", "b")
+
+	require.NoError(t, s.Delete(t.Context(), "dir/sub/b"))
+	require.NoError(t, s.Delete(t.Context(), "dir/sub/b"))
+	require.NoError(t, s.Delete(t.Context(), "missing"))
+	require.NoError(t, s.Delete(t.Context(), "dir"))
+
+	_, err := s.Stat(t.Context(), "dir/sub/b")
+	require.ErrorIs(t, err, blob.ErrNotFound)
+	require.Equal(t, []string{"dir/a"}, list(t, s, ""))
+
+	// the key of a deleted object can be reused at any depth
+	put(t, s, "dir/sub", "c")
+	require.Equal(t, "c", get(t, s, "dir/sub"))
+}
+
+func testStat(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	start := time.Now().Add(-time.Minute)
+
+	put(t, s, "dir/key", "12345")
+
+	info, err := s.Stat(t.Context(), "dir/key")
+	require.NoError(t, err)
+	require.Equal(t, "dir/key", info.Key)
+	require.Equal(t, int64(5), info.Size)
+	require.True(t, info.LastModified.After(start), "last modified %v", info.LastModified)
+
+	for listed, err := range s.List(t.Context(), "dir/") {
+		require.NoError(t, err)
+		require.Equal(t, info.Key, listed.Key)
+		require.Equal(t, info.Size, listed.Size)
+		require.True(t, listed.LastModified.After(start), "last modified %v", listed.LastModified)
+	}
+}
+
+func testList(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	require.Empty(t, list(t, s, ""))
+
+	for _, key := range []string{"b/2", "a.txt", "a/b/c", "ab", "b/1", "a-z"} {
+		put(t, s, key, key)
+	}
+
+	require.Equal(t, []string{"a-z", "a.txt", "a/b/c", "ab", "b/1", "b/2"}, list(t, s, ""))
+	require.Equal(t, []string{"a-z", "a.txt", "a/b/c", "ab"}, list(t, s, "a"))
+	require.Equal(t, []string{"a/b/c"}, list(t, s, "a/"))
+	require.Equal(t, []string{"a/b/c"}, list(t, s, "a/b"))
+	require.Equal(t, []string{"a/b/c"}, list(t, s, "a/b/c"))
+	require.Equal(t, []string{"b/1"}, list(t, s, "b/1"))
+	require.Empty(t, list(t, s, "c"))
+	require.Empty(t, list(t, s, "a/b/c/"))
+	require.Empty(t, list(t, s, "/a"))
+	require.Empty(t, list(t, s, "../a"))
+}
+
+func testListStop(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	for _, key := range []string{"k1", "k2", "k3"} {
+		put(t, s, key, key)
+	}
+
+	var keys []string
+
+	for info, err := range s.List(t.Context(), "k") {
+		require.NoError(t, err)
+
+		keys = append(keys, info.Key)
+
+		if len(keys) == 2 {
+			break
+		}
+	}
+
+	require.Equal(t, []string{"k1", "k2"}, keys)
+}
+
+func testInvalidKey(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	for _, key := range []string{"", ".", "/a", "a/", "a//b", "../a", "a/../b", "./a"} {
+		rc, err := s.Get(t.Context(), key)
+		require.ErrorIs(t, err, blob.ErrInvalidKey, key)
+		require.Nil(t, rc)
+
+		require.ErrorIs(t, s.Put(t.Context(), key, strings.NewReader("x")), blob.ErrInvalidKey, key)
+		require.ErrorIs(t, s.Delete(t.Context(), key), blob.ErrInvalidKey, key)
+
+		info, err := s.Stat(t.Context(), key)
+		require.ErrorIs(t, err, blob.ErrInvalidKey, key)
+		require.Nil(t, info)
+	}
+
+	require.Empty(t, list(t, s, ""))
+}
+
+func testReaderError(t *testing.T, s blob.Store) {
+	t.Helper()
+
+	errRead := errors.New("read error")
+
+	err := s.Put(t.Context(), "key", io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errRead)))
+	require.ErrorIs(t, err, errRead)
+
+	// a failed upload leaves no object
+	_, err = s.Stat(t.Context(), "key")
+	require.ErrorIs(t, err, blob.ErrNotFound)
+	require.Empty(t, list(t, s, ""))
+}
@@ -0,0 +1,212 @@
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/blob"
+	"github.com/tecnickcom/nurago/pkg/blob/blobtest"
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+func TestMemoryStore_conformance(t *testing.T) {
+	t.Parallel()
+
+	blobtest.Run(t, func(*testing.T) blob.Store { return blob.NewMemoryStore() })
+}
+
+func TestFileStore_conformance(t *testing.T) {
+	t.Parallel()
+
+	blobtest.Run(t, func(t *testing.T) blob.Store {
+		t.Helper()
+
+		store, err := blob.NewFileStore(t.TempDir())
+		require.NoError(t, err)
+
+		return store
+	})
+}
+
+func TestS3Store_conformance(t *testing.T) {
+	t.Parallel()
+
+	for _, prefix := range []string{"", "service/"} {
+		t.Run("prefix "+prefix, func(t *testing.T) {
+			t.Parallel()
+
+			blobtest.Run(t, func(t *testing.T) blob.Store {
+				t.Helper()
+
+				bucket := &s3bucket{objects: make(map[string]s3object)}
+
+				if prefix != "" {
+					// an object outside the store prefix
+					bucket.objects["other"] = s3object{}
+				}
+
+				cli, err := s3.New(t.Context(), "bucket", s3.WithS3Client(bucket))
+				require.NoError(t, err)
+
+				store, err := blob.NewS3Store(cli, prefix)
+				require.NoError(t, err)
+
+				return store
+			})
+		})
+	}
+}
+
+// s3object is an object stored by s3bucket.
+type s3object struct {
+	data     []byte
+	modified time.Time
+}
+
+// s3bucket is an in-memory S3 bucket implementing s3.S3.
+type s3bucket struct {
+	mu      sync.Mutex
+	objects map[string]s3object
+	err     error
+}
+
+// notFoundError is the error of a missing object, with its HTTP status code
+// like the AWS SDK response errors.
+type notFoundError struct{}
+
+func (notFoundError) Error() string       { return "not found" }
+func (notFoundError) HTTPStatusCode() int { return http.StatusNotFound }
+
+func (b *s3bucket) AbortMultipartUpload(_ context.Context, _ *awss3.AbortMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.AbortMultipartUploadOutput, error) {
+	return &awss3.AbortMultipartUploadOutput{}, nil
+}
+
+func (b *s3bucket) CompleteMultipartUpload(_ context.Context, _ *awss3.CompleteMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CompleteMultipartUploadOutput, error) {
+	return &awss3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (b *s3bucket) CopyObject(_ context.Context, _ *awss3.CopyObjectInput, _ ...func(*awss3.Options)) (*awss3.CopyObjectOutput, error) {
+	return &awss3.CopyObjectOutput{}, nil
+}
+
+func (b *s3bucket) CreateMultipartUpload(_ context.Context, _ *awss3.CreateMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CreateMultipartUploadOutput, error) {
+	return &awss3.CreateMultipartUploadOutput{}, nil
+}
+
+func (b *s3bucket) DeleteObject(_ context.Context, params *awss3.DeleteObjectInput, _ ...func(*awss3.Options)) (*awss3.DeleteObjectOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.objects, aws.ToString(params.Key))
+
+	return &awss3.DeleteObjectOutput{}, nil
+}
+
+func (b *s3bucket) DeleteObjects(_ context.Context, _ *awss3.DeleteObjectsInput, _ ...func(*awss3.Options)) (*awss3.DeleteObjectsOutput, error) {
+	return &awss3.DeleteObjectsOutput{}, nil
+}
+
+func (b *s3bucket) GetObject(_ context.Context, params *awss3.GetObjectInput, _ ...func(*awss3.Options)) (*awss3.GetObjectOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	obj, ok := b.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, notFoundError{}
+	}
+
+	return &awss3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(obj.data))}, nil
+}
+
+func (b *s3bucket) HeadBucket(_ context.Context, _ *awss3.HeadBucketInput, _ ...func(*awss3.Options)) (*awss3.HeadBucketOutput, error) {
+	return &awss3.HeadBucketOutput{}, nil
+}
+
+func (b *s3bucket) HeadObject(_ context.Context, params *awss3.HeadObjectInput, _ ...func(*awss3.Options)) (*awss3.HeadObjectOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	obj, ok := b.objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, notFoundError{}
+	}
+
+	return &awss3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(obj.data))), LastModified: aws.Time(obj.modified)}, nil
+}
+
+// ListObjectsV2 lists the objects by pages of two, to exercise the pagination.
+func (b *s3bucket) ListObjectsV2(_ context.Context, params *awss3.ListObjectsV2Input, _ ...func(*awss3.Options)) (*awss3.ListObjectsV2Output, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var keys []string
+
+	for key := range b.objects {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) && key > aws.ToString(params.ContinuationToken) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	out := &awss3.ListObjectsV2Output{}
+
+	for i, key := range keys {
+		if i == 2 {
+			out.IsTruncated = aws.Bool(true)
+			out.NextContinuationToken = aws.String(keys[i-1])
+
+			break
+		}
+
+		obj := b.objects[key]
+		out.Contents = append(out.Contents, types.Object{
+			Key:          aws.String(key),
+			Size:         aws.Int64(int64(len(obj.data))),
+			LastModified: aws.Time(obj.modified),
+		})
+	}
+
+	return out, nil
+}
+
+func (b *s3bucket) PutObject(_ context.Context, params *awss3.PutObjectInput, _ ...func(*awss3.Options)) (*awss3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.objects[aws.ToString(params.Key)] = s3object{data: data, modified: time.Now()}
+
+	return &awss3.PutObjectOutput{}, nil
+}
+
+func (b *s3bucket) UploadPart(_ context.Context, _ *awss3.UploadPartInput, _ ...func(*awss3.Options)) (*awss3.UploadPartOutput, error) {
+	return &awss3.UploadPartOutput{}, nil
+}
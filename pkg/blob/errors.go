@@ -0,0 +1,28 @@
+package blob
+
+import "errors"
+
+// Exported sentinel errors returned by this package. Match them with errors.Is.
+var (
+	// ErrNotFound is matched by the errors of Get and Stat when the object
+	// does not exist.
+	ErrNotFound = errors.New("blob: object not found")
+
+	// ErrInvalidKey is returned by the Store methods when a key is not valid
+	// (see ValidKey), before any storage access.
+	ErrInvalidKey = errors.New("blob: invalid object key")
+
+	// ErrNilClient is returned by NewS3Store when the S3 client is nil.
+	ErrNilClient = errors.New("blob: nil s3 client")
+
+	// ErrEmptyDir is returned by NewFileStore when the directory is empty.
+	ErrEmptyDir = errors.New("blob: empty directory")
+
+	// ErrInvalidURL is returned by Open when the storage URL cannot be parsed
+	// or lacks the directory or bucket name.
+	ErrInvalidURL = errors.New("blob: invalid storage URL")
+
+	// ErrUnsupportedScheme is returned by Open for a storage URL scheme other
+	// than mem, file and s3.
+	ErrUnsupportedScheme = errors.New("blob: unsupported storage URL scheme")
+)
@@ -0,0 +1,77 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/blob"
+)
+
+func Example() {
+	// The storage URL usually comes from the configuration, e.g.
+	// "file:///var/lib/service/data" or "s3://my-bucket/service/".
+	store, err := blob.Open(context.TODO(), "mem://")
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	err = store.Put(context.TODO(), "reports/latest.json", strings.NewReader(`{"ok":true}`))
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	rc, err := store.Get(context.TODO(), "reports/latest.json")
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	err = rc.Close()
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	fmt.Println(string(data))
+
+	for info, err := range store.List(context.TODO(), "reports/") {
+		if err != nil {
+			fmt.Println("error:", err)
+
+			return
+		}
+
+		fmt.Println(info.Key, info.Size)
+	}
+
+	err = store.Delete(context.TODO(), "reports/latest.json")
+	if err != nil {
+		fmt.Println("error:", err)
+
+		return
+	}
+
+	_, err = store.Stat(context.TODO(), "reports/latest.json")
+	fmt.Println(errors.Is(err, blob.ErrNotFound))
+
+	// Output:
+	// {"ok":true}
+	// reports/latest.json 11
+	// true
+}
@@ -0,0 +1,332 @@
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+const (
+	// fileTmpPrefix is the name prefix of the temporary files written by
+	// FileStore.Put, which are ignored by FileStore.List.
+	fileTmpPrefix = ".blob-tmp-"
+
+	// fileDirPerm is the permission of the directories created by FileStore.
+	fileDirPerm = 0o750
+
+	// filePerm is the permission of the files created by FileStore.
+	filePerm = 0o640
+)
+
+// FileStore is a [Store] keeping each object as a file under a local
+// directory, with the key as relative path, suitable for local development and
+// tests. The files are accessed through an [os.Root], so no key can reach
+// outside the directory, even through symbolic links.
+//
+// Put writes a temporary file (named with the ".blob-tmp-" prefix) and renames
+// it, so readers never see a partial object. Delete removes the directories
+// left empty. As on a filesystem a path cannot be both a file and a directory,
+// unlike S3, storing both "a" and "a/b" fails.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a [FileStore] keeping the objects under dir, which is
+// created if missing.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, ErrEmptyDir
+	}
+
+	err := os.MkdirAll(dir, fileDirPerm)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the storage directory: %w", err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Get implements [Store].
+func (s *FileStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	root, err := s.openRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	// the file stays open after the root is closed
+	f, err := root.Open(filepath.FromSlash(key))
+	if err != nil {
+		return nil, fileError(key, err)
+	}
+
+	fi, err := f.Stat()
+	if err == nil && !fi.Mode().IsRegular() {
+		err = fs.ErrNotExist
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, fileError(key, err)
+	}
+
+	return f, nil
+}
+
+// Put implements [Store].
+func (s *FileStore) Put(_ context.Context, key string, r io.Reader) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	root, err := s.openRoot()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	dir := path.Dir(key)
+	tmp := path.Join(dir, fileTmpPrefix+rand.Text())
+
+	f, err := createFile(root, dir, tmp)
+	if err != nil {
+		return fmt.Errorf("cannot create the object file: %w", err)
+	}
+
+	if r != nil {
+		_, err = io.Copy(f, r)
+		if err != nil {
+			err = fmt.Errorf("cannot write the object file: %w", err)
+		}
+	}
+
+	cerr := f.Close()
+	if err == nil && cerr != nil {
+		err = fmt.Errorf("cannot close the object file: %w", cerr)
+	}
+
+	if err == nil {
+		err = root.Rename(filepath.FromSlash(tmp), filepath.FromSlash(key))
+		if err != nil {
+			err = fmt.Errorf("cannot rename the object file: %w", err)
+		}
+	}
+
+	if err != nil {
+		_ = root.Remove(filepath.FromSlash(tmp))
+		return err
+	}
+
+	return nil
+}
+
+// Delete implements [Store].
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	root, err := s.openRoot()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	fi, err := root.Lstat(filepath.FromSlash(key))
+	if err != nil || fi.IsDir() {
+		// a missing object, or a directory, is not an object to delete
+		return nil //nolint:nilerr
+	}
+
+	err = root.Remove(filepath.FromSlash(key))
+	if err != nil && !notExist(err) {
+		return fmt.Errorf("cannot delete the object file: %w", err)
+	}
+
+	// remove the parent directories left empty; Remove fails on the first
+	// non-empty one
+	for dir := path.Dir(key); dir != "."; dir = path.Dir(dir) {
+		if root.Remove(filepath.FromSlash(dir)) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+// List implements [Store]. It walks the directories matching prefix and
+// sorts the keys, so the listing is held in memory.
+func (s *FileStore) List(ctx context.Context, prefix string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		list, err := s.list(ctx, prefix)
+		if err != nil {
+			yield(Info{}, err)
+			return
+		}
+
+		for _, info := range list {
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Stat implements [Store].
+func (s *FileStore) Stat(_ context.Context, key string) (*Info, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	root, err := s.openRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	fi, err := root.Stat(filepath.FromSlash(key))
+	if err == nil && !fi.Mode().IsRegular() {
+		err = fs.ErrNotExist
+	}
+
+	if err != nil {
+		return nil, fileError(key, err)
+	}
+
+	return &Info{Key: key, Size: fi.Size(), LastModified: fi.ModTime()}, nil
+}
+
+// list returns the sorted objects whose key starts with prefix.
+func (s *FileStore) list(ctx context.Context, prefix string) ([]Info, error) {
+	// walk only the deepest directory containing all the matching keys
+	dir := "."
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i]
+	}
+
+	if !fs.ValidPath(dir) {
+		// no valid key can start with prefix
+		return nil, nil
+	}
+
+	root, err := s.openRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = root.Close() }()
+
+	list := make([]Info, 0)
+
+	err = fs.WalkDir(root.FS(), dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && notExist(err) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		if d.IsDir() {
+			if p != dir && !strings.HasPrefix(p+"/", prefix) && !strings.HasPrefix(prefix, p+"/") {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || strings.HasPrefix(d.Name(), fileTmpPrefix) || !strings.HasPrefix(p, prefix) {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			if notExist(err) {
+				// deleted while listing
+				return nil
+			}
+
+			return err //nolint:wrapcheck
+		}
+
+		list = append(list, Info{Key: p, Size: fi.Size(), LastModified: fi.ModTime()})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the object files: %w", err)
+	}
+
+	slices.SortFunc(list, func(a, b Info) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return list, nil
+}
+
+// openRoot opens the storage directory.
+func (s *FileStore) openRoot() (*os.Root, error) {
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the storage directory: %w", err)
+	}
+
+	return root, nil
+}
+
+// createFile creates the new file name in dir, creating dir if missing.
+func createFile(root *os.Root, dir, name string) (*os.File, error) {
+	// a concurrent Delete can remove dir after it is created: retry once
+	for attempt := 0; ; attempt++ {
+		if dir != "." {
+			err := root.MkdirAll(filepath.FromSlash(dir), fileDirPerm)
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+		}
+
+		f, err := root.OpenFile(filepath.FromSlash(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, filePerm)
+		if err != nil && notExist(err) && attempt == 0 {
+			continue
+		}
+
+		return f, err //nolint:wrapcheck
+	}
+}
+
+// fileError maps a missing file error to ErrNotFound.
+func fileError(key string, err error) error {
+	if notExist(err) {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return fmt.Errorf("cannot access the object file: %w", err)
+}
+
+// notExist reports whether err reports a missing file, including a path
+// element that is a file rather than a directory.
+func notExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+}
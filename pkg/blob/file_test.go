@@ -0,0 +1,174 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileStore(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "a", "b")
+
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+	require.DirExists(t, dir)
+	require.Equal(t, dir, s.dir)
+
+	s, err = NewFileStore("")
+	require.ErrorIs(t, err, ErrEmptyDir)
+	require.Nil(t, s)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	s, err = NewFileStore(filepath.Join(file, "dir"))
+	require.Error(t, err)
+	require.Nil(t, s)
+}
+
+func TestFileStore_layout(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(t.Context(), "a/b/c.txt", strings.NewReader("data")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "a", "b", "c.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+
+	// the temporary files of the uploads in progress are not listed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a", fileTmpPrefix+"x"), nil, 0o600))
+
+	keys := listKeys(t, s, "")
+	require.Equal(t, []string{"a/b/c.txt"}, keys)
+
+	// a failed upload removes its temporary file
+	err = s.Put(t.Context(), "a/d", iotest.ErrReader(errors.New("read error")))
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "a"))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the directories left empty are removed
+	require.NoError(t, s.Delete(t.Context(), "a/b/c.txt"))
+	require.NoDirExists(t, filepath.Join(dir, "a", "b"))
+	require.DirExists(t, filepath.Join(dir, "a"))
+}
+
+func TestFileStore_conflict(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(t.Context(), "a/b", strings.NewReader("b")))
+
+	err = s.Put(t.Context(), "a", strings.NewReader("a"))
+	require.ErrorContains(t, err, "cannot rename the object file")
+
+	err = s.Put(t.Context(), "a/b/c", strings.NewReader("c"))
+	require.ErrorContains(t, err, "cannot create the object file")
+
+	require.Equal(t, []string{"a/b"}, listKeys(t, s, ""))
+}
+
+func TestFileStore_symlink(t *testing.T) {
+	t.Parallel()
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o600))
+
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	rc, err := s.Get(t.Context(), "link/secret")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrNotFound)
+	require.Nil(t, rc)
+
+	err = s.Put(t.Context(), "link/new", strings.NewReader("x"))
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(outside, "new"))
+
+	// the symbolic links are not listed
+	require.Empty(t, listKeys(t, s, ""))
+}
+
+func TestFileStore_missingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(dir))
+
+	_, err = s.Get(t.Context(), "a")
+	require.ErrorContains(t, err, "cannot open the storage directory")
+
+	err = s.Put(t.Context(), "a", nil)
+	require.ErrorContains(t, err, "cannot open the storage directory")
+
+	err = s.Delete(t.Context(), "a")
+	require.ErrorContains(t, err, "cannot open the storage directory")
+
+	_, err = s.Stat(t.Context(), "a")
+	require.ErrorContains(t, err, "cannot open the storage directory")
+
+	for _, err := range s.List(t.Context(), "") {
+		require.ErrorContains(t, err, "cannot open the storage directory")
+	}
+}
+
+func TestFileStore_List_canceled(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.Put(t.Context(), "a", nil))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	var errs []error
+
+	for info, err := range s.List(ctx, "") {
+		require.Zero(t, info)
+
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], context.Canceled)
+}
+
+// listKeys returns the keys listed with prefix.
+func listKeys(t *testing.T, s Store, prefix string) []string {
+	t.Helper()
+
+	keys := make([]string, 0)
+
+	for info, err := range s.List(t.Context(), prefix) {
+		require.NoError(t, err)
+
+		keys = append(keys, info.Key)
+	}
+
+	return keys
+}
@@ -0,0 +1,144 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryObject is an object stored by a MemoryStore.
+type memoryObject struct {
+	data     []byte
+	modified time.Time
+}
+
+// MemoryStore is an in-process [Store], suitable for unit tests and
+// single-instance tools. The objects are lost when the process exits.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	nowFn   func() time.Time
+}
+
+// NewMemoryStore returns an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		objects: make(map[string]memoryObject),
+		nowFn:   time.Now,
+	}
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	// the stored data is never modified, as Put replaces the whole object
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+// Put implements [Store].
+func (s *MemoryStore) Put(_ context.Context, key string, r io.Reader) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	var data []byte
+
+	if r != nil {
+		var err error
+
+		data, err = io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("cannot read object data: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = memoryObject{data: data, modified: s.nowFn()}
+
+	return nil
+}
+
+// Delete implements [Store].
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+
+	return nil
+}
+
+// List implements [Store]. It lists a snapshot of the objects taken when the
+// iteration starts.
+func (s *MemoryStore) List(_ context.Context, prefix string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		s.mu.RLock()
+
+		list := make([]Info, 0)
+
+		for key, obj := range s.objects {
+			if strings.HasPrefix(key, prefix) {
+				list = append(list, obj.info(key))
+			}
+		}
+
+		s.mu.RUnlock()
+
+		slices.SortFunc(list, func(a, b Info) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+
+		for _, info := range list {
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Stat implements [Store].
+func (s *MemoryStore) Stat(_ context.Context, key string) (*Info, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	info := obj.info(key)
+
+	return &info, nil
+}
+
+// info returns the information of the object key.
+func (o memoryObject) info(key string) Info {
+	return Info{Key: key, Size: int64(len(o.data)), LastModified: o.modified}
+}
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_lastModified(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.nowFn = func() time.Time { return now }
+
+	require.NoError(t, s.Put(t.Context(), "key", nil))
+
+	info, err := s.Stat(t.Context(), "key")
+	require.NoError(t, err)
+	require.Equal(t, &Info{Key: "key", LastModified: now}, info)
+}
@@ -0,0 +1,123 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/tecnickcom/nurago/pkg/awsopt"
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+// Storage URL schemes supported by Open.
+const (
+	// SchemeMemory selects a MemoryStore: "mem://".
+	SchemeMemory = "mem"
+
+	// SchemeFile selects a FileStore: "file:///absolute/dir" or "file:relative/dir".
+	SchemeFile = "file"
+
+	// SchemeS3 selects an S3Store: "s3://bucket/optional/prefix".
+	SchemeS3 = "s3"
+)
+
+// Open returns the [Store] selected by the scheme of the storage URL rawURL:
+//
+//   - "mem://" returns a new [MemoryStore];
+//   - "file:///absolute/dir" or "file:relative/dir" returns a [FileStore]
+//     keeping the objects under the directory;
+//   - "s3://bucket/prefix" returns an [S3Store] keeping the objects in the
+//     bucket under the optional key prefix (a trailing slash is added when
+//     missing). The "region" query parameter sets the AWS region, and the
+//     "endpoint" parameter the S3 endpoint URL, with path-style addressing,
+//     e.g. for a local S3-compatible server. More client options can be set
+//     with [WithS3Options].
+//
+// It returns [ErrInvalidURL] or [ErrUnsupportedScheme] for an invalid URL.
+func Open(ctx context.Context, rawURL string, opts ...Option) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	c := &cfg{}
+
+	for _, applyOpt := range opts {
+		applyOpt(c)
+	}
+
+	switch u.Scheme {
+	case SchemeMemory:
+		return NewMemoryStore(), nil
+	case SchemeFile:
+		return openFile(u)
+	case SchemeS3:
+		return openS3(ctx, u, c.s3Opts)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, u.Scheme)
+	}
+}
+
+// openFile returns the FileStore of a file URL.
+func openFile(u *url.URL) (Store, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("%w: the file URL host must be empty or localhost", ErrInvalidURL)
+	}
+
+	dir := u.Path
+
+	if u.Opaque != "" {
+		var err error
+
+		dir, err = url.PathUnescape(u.Opaque)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidURL, err)
+		}
+	}
+
+	if dir == "" {
+		return nil, fmt.Errorf("%w: missing directory", ErrInvalidURL)
+	}
+
+	return NewFileStore(filepath.FromSlash(dir))
+}
+
+// openS3 returns the S3Store of an s3 URL.
+func openS3(ctx context.Context, u *url.URL, opts []s3.Option) (Store, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing bucket name", ErrInvalidURL)
+	}
+
+	q := u.Query()
+
+	var urlOpts []s3.Option
+
+	if region := q.Get("region"); region != "" {
+		o := awsopt.Options{}
+		o.WithRegion(region)
+
+		urlOpts = append(urlOpts, s3.WithAWSOptions(o))
+	}
+
+	if endpoint := q.Get("endpoint"); endpoint != "" {
+		urlOpts = append(urlOpts,
+			s3.WithEndpointMutable(endpoint),
+			s3.WithSrvOptionFuncs(func(o *awss3.Options) { o.UsePathStyle = true }),
+		)
+	}
+
+	client, err := s3.New(ctx, u.Host, append(urlOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the s3 client: %w", err)
+	}
+
+	prefix := strings.TrimPrefix(u.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return NewS3Store(client, prefix)
+}
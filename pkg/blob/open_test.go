@@ -0,0 +1,145 @@
+package blob
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		url        string
+		opts       []Option
+		wantType   Store
+		wantDir    string
+		wantPrefix string
+		wantErr    error
+	}{
+		{
+			name:     "memory",
+			url:      "mem://",
+			wantType: &MemoryStore{},
+		},
+		{
+			name:     "file",
+			url:      "file://" + filepath.ToSlash(dir) + "/data",
+			wantType: &FileStore{},
+			wantDir:  filepath.Join(dir, "data"),
+		},
+		{
+			name:     "file localhost",
+			url:      "file://localhost" + filepath.ToSlash(dir),
+			wantType: &FileStore{},
+			wantDir:  dir,
+		},
+		{
+			name:     "s3",
+			url:      "s3://bucket",
+			opts:     []Option{WithS3Options(s3.WithS3Client(nil))},
+			wantType: &S3Store{},
+		},
+		{
+			name:       "s3 with prefix and parameters",
+			url:        "s3://bucket/service/data?region=eu-west-1&endpoint=http://localhost:4566",
+			wantType:   &S3Store{},
+			wantPrefix: "service/data/",
+		},
+		{
+			name:    "s3 options error",
+			url:     "s3://bucket/",
+			opts:    []Option{WithS3Options(s3.WithPartSize(1))},
+			wantErr: s3.ErrInvalidPartSize,
+		},
+		{
+			name:    "s3 without bucket",
+			url:     "s3:///prefix",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "file with host",
+			url:     "file://host/dir",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "file without directory",
+			url:     "file://",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "file with invalid escape",
+			url:     "file:a%zzb",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "invalid URL",
+			url:     "mem://%zz",
+			wantErr: ErrInvalidURL,
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "ftp://host/dir",
+			wantErr: ErrUnsupportedScheme,
+		},
+		{
+			name:    "missing scheme",
+			url:     "/var/data",
+			wantErr: ErrUnsupportedScheme,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := Open(t.Context(), tt.url, tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, store)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.IsType(t, tt.wantType, store)
+
+			switch s := store.(type) {
+			case *FileStore:
+				require.Equal(t, tt.wantDir, s.dir)
+				require.DirExists(t, s.dir)
+			case *S3Store:
+				require.Equal(t, "bucket", s.client.Bucket())
+				require.Equal(t, tt.wantPrefix, s.prefix)
+			}
+		})
+	}
+}
+
+func TestOpen_relativeFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	store, err := Open(t.Context(), "file:relative/data")
+	require.NoError(t, err)
+	require.IsType(t, &FileStore{}, store)
+
+	fi, err := os.Stat(filepath.Join(dir, "relative", "data"))
+	require.NoError(t, err)
+	require.True(t, fi.IsDir())
+}
+
+func TestWithS3Options(t *testing.T) {
+	t.Parallel()
+
+	c := &cfg{}
+	WithS3Options(s3.WithPartSize(s3.MinPartSize))(c)
+	WithS3Options(s3.WithUploadConcurrency(1), s3.WithUploadConcurrency(2))(c)
+	require.Len(t, c.s3Opts, 3)
+}
@@ -0,0 +1,20 @@
+package blob
+
+import "github.com/tecnickcom/nurago/pkg/s3"
+
+// Option is a type to allow setting custom [Open] options.
+type Option func(*cfg)
+
+// cfg holds the Open configuration.
+type cfg struct {
+	s3Opts []s3.Option
+}
+
+// WithS3Options appends options of the S3 client created by [Open] for an s3
+// URL, e.g. s3.WithAWSOptions; they are applied after, and take precedence
+// over, the options set by the URL parameters.
+func WithS3Options(opts ...s3.Option) Option {
+	return func(c *cfg) {
+		c.s3Opts = append(c.s3Opts, opts...)
+	}
+}
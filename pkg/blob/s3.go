@@ -0,0 +1,107 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+// S3Store is a [Store] keeping the objects in an AWS S3 bucket through a
+// [github.com/tecnickcom/nurago/pkg/s3.Client], under an optional key prefix
+// to share a bucket.
+type S3Store struct {
+	client *s3.Client
+	prefix string
+}
+
+// NewS3Store returns an [S3Store] keeping the objects in the bucket of client,
+// with keys prepended with prefix (e.g. "service/"; empty for none).
+func NewS3Store(client *s3.Client, prefix string) (*S3Store, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+
+	return &S3Store{client: client, prefix: prefix}, nil
+}
+
+// Get implements [Store].
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	obj, err := s.client.Get(ctx, s.prefix+key)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return obj.Body(), nil
+}
+
+// Put implements [Store]. Large content is uploaded with a multipart upload.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	return s.client.Put(ctx, s.prefix+key, r) //nolint:wrapcheck
+}
+
+// Delete implements [Store].
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+
+	return s.client.Delete(ctx, s.prefix+key) //nolint:wrapcheck
+}
+
+// List implements [Store]. The objects are listed lazily, one page at a time.
+func (s *S3Store) List(ctx context.Context, prefix string) iter.Seq2[Info, error] {
+	return func(yield func(Info, error) bool) {
+		for obj, err := range s.client.Objects(ctx, s.prefix+prefix) {
+			if err != nil {
+				yield(Info{}, err)
+				return
+			}
+
+			info := Info{
+				Key:          strings.TrimPrefix(obj.Key, s.prefix),
+				Size:         obj.Size,
+				LastModified: obj.LastModified,
+			}
+
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Stat implements [Store].
+func (s *S3Store) Stat(ctx context.Context, key string) (*Info, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	obj, err := s.client.Head(ctx, s.prefix+key)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return &Info{Key: key, Size: obj.Size, LastModified: obj.LastModified}, nil
+}
+
+// s3Error adds ErrNotFound to an error reporting a missing S3 object.
+func s3Error(err error) error {
+	if errors.Is(err, s3.ErrObjectNotFound) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return err //nolint:wrapcheck
+}
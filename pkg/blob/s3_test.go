@@ -0,0 +1,52 @@
+package blob_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/blob"
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+func TestNewS3Store(t *testing.T) {
+	t.Parallel()
+
+	store, err := blob.NewS3Store(nil, "")
+	require.ErrorIs(t, err, blob.ErrNilClient)
+	require.Nil(t, store)
+}
+
+func TestS3Store_errors(t *testing.T) {
+	t.Parallel()
+
+	errS3 := errors.New("s3 error")
+	bucket := &s3bucket{objects: make(map[string]s3object), err: errS3}
+
+	cli, err := s3.New(t.Context(), "bucket", s3.WithS3Client(bucket))
+	require.NoError(t, err)
+
+	store, err := blob.NewS3Store(cli, "prefix/")
+	require.NoError(t, err)
+
+	rc, err := store.Get(t.Context(), "key")
+	require.ErrorIs(t, err, errS3)
+	require.NotErrorIs(t, err, blob.ErrNotFound)
+	require.Nil(t, rc)
+
+	info, err := store.Stat(t.Context(), "key")
+	require.ErrorIs(t, err, errS3)
+	require.NotErrorIs(t, err, blob.ErrNotFound)
+	require.Nil(t, info)
+
+	var errs []error
+
+	for info, err := range store.List(t.Context(), "") {
+		require.Zero(t, info)
+
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], errS3)
+}
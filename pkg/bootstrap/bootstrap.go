@@ -29,6 +29,9 @@ list of [Option] values that tune the runtime behavior:
     fires first, Bootstrap returns an error wrapping [ErrShutdownTimeout].
  8. The metrics client is closed so buffered measurements are flushed before
     the process exits.
+ 9. The logutil.Config supplied with [WithLogConfig] is closed, so the records
+    buffered by an asynchronous output (see [logutil.WithAsyncWriter]) are
+    written, bounded by the shutdown timeout.
 
 # Notes
 
@@ -80,6 +83,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/tecnickcom/nurago/pkg/logutil"
 	"github.com/tecnickcom/nurago/pkg/metrics"
 )
 
@@ -148,6 +152,7 @@ func Bootstrap(bindFn BindFunc, opts ...Option) error {
 	err = bindFn(ctx, l, m)
 	if err != nil {
 		closeMetricsClient(m, l)
+		closeLogConfig(cfg.logConfig, cfg.shutdownTimeout, l)
 
 		return fmt.Errorf("application bootstrap error: %w", err)
 	}
@@ -177,6 +182,9 @@ func Bootstrap(bindFn BindFunc, opts ...Option) error {
 
 	l.Info("application stopped")
 
+	// write the log records still buffered, last
+	closeLogConfig(cfg.logConfig, cfg.shutdownTimeout, l)
+
 	if !completed {
 		return fmt.Errorf("shutdown exceeded %s: %w", cfg.shutdownTimeout, ErrShutdownTimeout)
 	}
@@ -210,6 +218,26 @@ func closeMetricsClient(m metrics.Client, l *slog.Logger) {
 	}
 }
 
+// closeLogConfig closes the log configuration (nil when WithLogConfig is unused)
+// within timeout, logging any close error.
+//
+// Closing writes the records buffered by an asynchronous output, so the last
+// lines logged before shutdown are not lost; the logger writes synchronously
+// afterwards, including the close error itself.
+func closeLogConfig(c *logutil.Config, timeout time.Duration, l *slog.Logger) {
+	if c == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := c.Close(ctx)
+	if err != nil {
+		l.Error("error closing the log output", slog.Any("error", err))
+	}
+}
+
 // syncWaitGroupTimeout waits for wg completion with an upper time bound, returning
 // true if wg completed and false if the timeout fired first.
 //
@@ -1,10 +1,12 @@
 package bootstrap
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"log/slog"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -350,6 +352,101 @@ func TestBootstrap_closesMetricsClientOnShutdown(t *testing.T) {
 	require.True(t, m.closed.Load(), "the metrics client must be closed on shutdown")
 }
 
+// slowWriter is a thread-safe io.Writer taking a while per write, optionally failing.
+type slowWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	err error
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	return w.buf.Write(p) //nolint:wrapcheck
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+//nolint:paralleltest // cannot run in parallel because signals are received by all parallel tests
+func TestBootstrap_closesAsyncLogWriterOnShutdown(t *testing.T) {
+	out := &slowWriter{}
+
+	logCfg, err := logutil.NewConfig(
+		logutil.WithOutWriter(out),
+		logutil.WithLevel(logutil.LevelDebug),
+		logutil.WithAsyncWriter(logutil.AsyncWriterConfig{}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	bindFn := func(_ context.Context, l *slog.Logger, _ metrics.Client) error {
+		for range 50 {
+			l.Info("burst")
+		}
+
+		return nil
+	}
+
+	prev := slog.Default()
+
+	defer slog.SetDefault(prev)
+
+	err = Bootstrap(
+		bindFn,
+		WithContext(ctx),
+		WithLogConfig(logCfg),
+		WithShutdownTimeout(5*time.Second),
+	)
+	require.NoError(t, err)
+
+	got := out.String()
+	require.Equal(t, 50, strings.Count(got, `"burst"`))
+	require.Contains(t, got, "application stopped", "the records buffered on exit must be written")
+}
+
+func Test_closeLogConfig(t *testing.T) {
+	t.Parallel()
+
+	var logBuf bytes.Buffer
+
+	l := slog.New(slog.NewJSONHandler(&logBuf, nil))
+
+	closeLogConfig(nil, time.Second, l)
+	closeLogConfig(logutil.DefaultConfig(), time.Second, l)
+	require.Empty(t, logBuf.String())
+
+	out := &slowWriter{err: errors.New("disk full")}
+
+	logCfg, err := logutil.NewConfig(
+		logutil.WithOutWriter(out),
+		logutil.WithAsyncWriter(logutil.AsyncWriterConfig{}),
+	)
+	require.NoError(t, err)
+
+	_, err = logCfg.Out.Write([]byte("line\n"))
+	require.NoError(t, err)
+
+	closeLogConfig(logCfg, time.Second, l)
+	require.Contains(t, logBuf.String(), "error closing the log output")
+	require.Contains(t, logBuf.String(), "disk full")
+}
+
 func Test_syncWaitGroupTimeout(t *testing.T) {
 	t.Parallel()
 
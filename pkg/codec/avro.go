@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+)
+
+// AvroCodec is the Avro binary [Codec], mapping the Go values to the schema
+// set with WithSchema as documented by github.com/hamba/avro/v2 (e.g. the
+// struct fields by their `avro` tag). With a registry, the messages written
+// with another schema version are resolved to the codec schema, following the
+// Avro schema evolution rules.
+type AvroCodec struct {
+	cfg     config
+	schema  avro.Schema
+	reg     *registration
+	writers *schemaCache[avro.Schema]
+}
+
+// NewAvro returns an Avro codec. The Avro schema is required.
+func NewAvro(opts ...Option) (*AvroCodec, error) {
+	c := &AvroCodec{}
+
+	for _, applyOpt := range opts {
+		applyOpt(&c.cfg)
+	}
+
+	err := c.cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.schema == "" {
+		return nil, fmt.Errorf("missing Avro schema: %w", ErrInvalidOptions)
+	}
+
+	c.schema, err = parseAvroSchema(c.cfg.schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema: %w: %w", ErrInvalidOptions, err)
+	}
+
+	if c.cfg.registry != nil {
+		c.reg = &registration{cfg: &c.cfg, schema: Schema{Type: SchemaTypeAvro, Definition: c.cfg.schema}}
+		c.writers = newSchemaCache(c.cfg.registry, SchemaTypeAvro, c.resolve)
+	}
+
+	return c, nil
+}
+
+// Encode returns the Avro encoding of data.
+func (c *AvroCodec) Encode(ctx context.Context, data any) ([]byte, error) {
+	payload, err := avro.Marshal(c.schema, data)
+	if err != nil {
+		return nil, fmt.Errorf("avro encode: %w: %w", ErrInvalidData, err)
+	}
+
+	if c.reg == nil {
+		return payload, nil
+	}
+
+	id, err := c.reg.schemaID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(appendWireHeader(make([]byte, 0, wireHeaderLen+len(payload)), id), payload...), nil
+}
+
+// Decode decodes the Avro msg into data.
+func (c *AvroCodec) Decode(ctx context.Context, msg []byte, data any) error {
+	payload, schema := msg, c.schema
+
+	if c.reg != nil {
+		id, rest, err := parseWireHeader(msg)
+		if err != nil {
+			return err
+		}
+
+		payload = rest
+
+		schema, err = c.writers.get(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := avro.Unmarshal(schema, payload, data)
+	if err != nil {
+		return fmt.Errorf("avro decode: %w: %w", ErrInvalidData, err)
+	}
+
+	return nil
+}
+
+// resolve returns the schema decoding the payloads written with the writer
+// schema s into the codec schema.
+func (c *AvroCodec) resolve(s Schema) (avro.Schema, error) {
+	writer, err := parseAvroSchema(s.Definition)
+	if err != nil {
+		return nil, err
+	}
+
+	if writer.Fingerprint() == c.schema.Fingerprint() {
+		return c.schema, nil
+	}
+
+	resolved, err := avro.NewSchemaCompatibility().Resolve(c.schema, writer)
+	if err != nil {
+		return nil, fmt.Errorf("incompatible Avro schema: %w", err)
+	}
+
+	return resolved, nil
+}
+
+// parseAvroSchema parses an Avro schema with a dedicated cache, so the named
+// types of different schemas do not conflict.
+func parseAvroSchema(definition string) (avro.Schema, error) {
+	schema, err := avro.ParseWithCache(definition, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("avro schema parse: %w", err)
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,130 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAvroSchemaV1 = `{"type":"record","name":"Person","fields":[{"name":"name","type":"string"}]}`
+	testAvroSchemaV2 = `{"type":"record","name":"Person","fields":[
+		{"name":"name","type":"string"},
+		{"name":"age","type":"int","default":7}
+	]}`
+)
+
+func TestNewAvro(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "schema", opts: []Option{WithSchema(testAvroSchemaV2)}},
+		{name: "registry", opts: []Option{WithSchema(testAvroSchemaV2), WithRegistry(NewMemoryRegistry(), "s")}},
+		{name: "missing schema", wantErr: true},
+		{name: "invalid schema", opts: []Option{WithSchema(`{"type":"record"}`)}, wantErr: true},
+		{name: "registry without subject", opts: []Option{WithSchema(testAvroSchemaV2), WithRegistry(NewMemoryRegistry(), "")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewAvro(tt.opts...)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidOptions)
+				require.Nil(t, c)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, c)
+		})
+	}
+}
+
+func TestAvroCodec(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, err := NewAvro(WithSchema(testAvroSchemaV2))
+	require.NoError(t, err)
+
+	b, err := c.Encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{10, 'a', 'l', 'i', 'c', 'e', 84}, b)
+
+	var got testPerson
+
+	require.NoError(t, c.Decode(ctx, b, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 42}, got)
+
+	_, err = c.Encode(ctx, "alice")
+	require.ErrorIs(t, err, ErrInvalidData)
+
+	require.ErrorIs(t, c.Decode(ctx, []byte{10, 'a'}, &got), ErrInvalidData)
+}
+
+func TestAvroCodec_registry(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := NewMemoryRegistry()
+
+	v1, err := NewAvro(WithSchema(testAvroSchemaV1), WithRegistry(r, "people-value"))
+	require.NoError(t, err)
+
+	v2, err := NewAvro(WithSchema(testAvroSchemaV2), WithRegistry(r, "people-value"))
+	require.NoError(t, err)
+
+	b1, err := v1.Encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 1}, b1[:wireHeaderLen])
+
+	b2, err := v2.Encode(ctx, testPerson{Name: "bob", Age: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 2}, b2[:wireHeaderLen])
+
+	var got testPerson
+
+	// same schema.
+	require.NoError(t, v2.Decode(ctx, b2, &got))
+	require.Equal(t, testPerson{Name: "bob", Age: 42}, got)
+
+	// older writer schema: the missing field takes its default.
+	got = testPerson{}
+	require.NoError(t, v2.Decode(ctx, b1, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 7}, got)
+
+	// newer writer schema: the extra field is skipped.
+	got = testPerson{}
+	require.NoError(t, v1.Decode(ctx, b2, &got))
+	require.Equal(t, testPerson{Name: "bob"}, got)
+
+	incompatibleID, err := r.Register(ctx, "other", Schema{Type: SchemaTypeAvro, Definition: `"string"`})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, v2.Decode(ctx, appendWireHeader(nil, incompatibleID), &got), "incompatible Avro schema")
+
+	invalidID, err := r.Register(ctx, "other", Schema{Type: SchemaTypeAvro, Definition: `{`})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, v2.Decode(ctx, appendWireHeader(nil, invalidID), &got), "avro schema parse")
+
+	jsonID, err := r.Register(ctx, "other", Schema{Type: SchemaTypeJSON, Definition: "{}"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, v2.Decode(ctx, appendWireHeader(nil, jsonID), &got), ErrSchemaMismatch)
+	require.ErrorIs(t, v2.Decode(ctx, nil, &got), ErrInvalidWireFormat)
+
+	failing, err := NewAvro(WithSchema(testAvroSchemaV2), WithRegistry(failingRegistry{}, "s"))
+	require.NoError(t, err)
+
+	_, err = failing.Encode(ctx, testPerson{Name: "alice"})
+	require.ErrorContains(t, err, "register failed")
+}
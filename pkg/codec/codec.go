@@ -0,0 +1,154 @@
+/*
+Package codec provides schema-aware message payload encodings, interoperable
+with non-Go producers and consumers, for the messaging packages (kafka, sqs,
+redis and valkey).
+
+# Codecs
+
+  - [NewJSON]: JSON, optionally validated against a JSON Schema.
+  - [NewProtobuf]: Protocol Buffers binary encoding of proto.Message values.
+  - [NewAvro]: Avro binary encoding with an Avro schema.
+
+Each codec implements [Codec], whose methods match the TEncodeFunc and
+TDecodeFunc types of the kafka package, so they plug into the existing
+options:
+
+	c, err := codec.NewJSON(codec.WithSchema(schema))
+	if err != nil {
+	    return err
+	}
+
+	producer, err := kafka.NewProducer(brokers, topic, kafka.WithMessageEncodeFunc(c.Encode))
+
+The packages with string payloads (sqs, redis and valkey) use the
+[StringEncodeFunc] and [StringDecodeFunc] adapters, which base64-encode the
+binary payloads (Protobuf, Avro and the Confluent wire format) and pass the
+plain JSON payloads through unchanged:
+
+	client, err := sqs.New(ctx, queueURL, msgGroupID,
+	    sqs.WithMessageEncodeFunc(codec.StringEncodeFunc(c)),
+	    sqs.WithMessageDecodeFunc(codec.StringDecodeFunc(c)),
+	)
+
+# Schema Registry
+
+With [WithRegistry] the codecs register their schema under a subject of a
+[Registry] and use the Confluent wire format: a zero magic byte, the 4-byte
+big-endian schema ID, the Protobuf message indexes (Protobuf only), and the
+payload. When decoding, the schema of the message ID is fetched from the
+registry (and cached) to validate (JSON), check (Protobuf: the schema type and
+the message indexes of the decoded type) or resolve (Avro) the payload, so the
+messages written with an older schema version stay readable.
+
+[NewHTTPRegistry] is a client of the Confluent Schema Registry REST API (also
+implemented by compatible registries), and [NewMemoryRegistry] an in-memory
+registry for tests.
+
+# Errors
+
+The errors match the exported sentinels ([ErrInvalidOptions],
+[ErrInvalidData], [ErrInvalidWireFormat], [ErrSchemaNotFound],
+[ErrSchemaMismatch] and [ErrValidation]) with errors.Is.
+*/
+package codec
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+)
+
+// Codec encodes and decodes message payloads.
+type Codec interface {
+	// Encode returns the encoding of data.
+	Encode(ctx context.Context, data any) ([]byte, error)
+
+	// Decode decodes msg into data, which must be a pointer.
+	Decode(ctx context.Context, msg []byte, data any) error
+}
+
+// SchemaType is the type of a schema, as named by the Confluent Schema
+// Registry.
+type SchemaType string
+
+// Schema types.
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+// Schema is a schema stored in a [Registry].
+type Schema struct {
+	// Type is the schema type.
+	Type SchemaType
+
+	// Definition is the schema text: an Avro schema, a .proto file, or a JSON
+	// Schema.
+	Definition string
+}
+
+// Registry stores the schemas by ID.
+type Registry interface {
+	// Register registers schema under subject and returns its ID. Registering
+	// an already registered schema returns its existing ID.
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+
+	// Schema returns the schema with the given ID, or an error matching
+	// ErrSchemaNotFound.
+	Schema(ctx context.Context, id int) (Schema, error)
+}
+
+// textCodec is implemented by the codecs whose encoding may be text.
+type textCodec interface {
+	// text reports whether the encoding is valid UTF-8 text.
+	text() bool
+}
+
+// isText reports whether the encoding of c is valid UTF-8 text.
+func isText(c Codec) bool {
+	tc, ok := c.(textCodec)
+
+	return ok && tc.text()
+}
+
+// StringEncodeFunc returns an encode function with a string result, for the
+// sqs, redis and valkey TEncodeFunc options: the encoding of c, base64-encoded
+// unless it is text (a JSON codec without registry).
+func StringEncodeFunc(c Codec) func(ctx context.Context, data any) (string, error) {
+	text := isText(c)
+
+	return func(ctx context.Context, data any) (string, error) {
+		b, err := c.Encode(ctx, data)
+		if err != nil {
+			return "", err //nolint:wrapcheck
+		}
+
+		if text {
+			return string(b), nil
+		}
+
+		return base64.StdEncoding.EncodeToString(b), nil
+	}
+}
+
+// StringDecodeFunc returns a decode function with a string input, for the sqs,
+// redis and valkey TDecodeFunc options: it decodes msg with c, after
+// base64-decoding it unless the encoding of c is text, as for
+// [StringEncodeFunc].
+func StringDecodeFunc(c Codec) func(ctx context.Context, msg string, data any) error {
+	text := isText(c)
+
+	return func(ctx context.Context, msg string, data any) error {
+		if text {
+			return c.Decode(ctx, []byte(msg), data) //nolint:wrapcheck
+		}
+
+		b, err := base64.StdEncoding.DecodeString(msg)
+		if err != nil {
+			return fmt.Errorf("base64 decode: %w: %w", ErrInvalidWireFormat, err)
+		}
+
+		return c.Decode(ctx, b, data) //nolint:wrapcheck
+	}
+}
@@ -0,0 +1,65 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringCodecFuncs(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, err := NewAvro(WithSchema(testAvroSchemaV2), WithRegistry(NewMemoryRegistry(), "people-value"))
+	require.NoError(t, err)
+
+	encode := StringEncodeFunc(c)
+	decode := StringDecodeFunc(c)
+
+	s, err := encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.Equal(t, "AAAAAAEKYWxpY2VU", s)
+
+	var got testPerson
+
+	require.NoError(t, decode(ctx, s, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 42}, got)
+
+	require.ErrorIs(t, decode(ctx, "not base64!", &got), ErrInvalidWireFormat)
+
+	_, err = encode(ctx, "alice")
+	require.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestStringCodecFuncs_text(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, err := NewJSON()
+	require.NoError(t, err)
+
+	encode := StringEncodeFunc(c)
+	decode := StringDecodeFunc(c)
+
+	s, err := encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"alice","age":42}`, s)
+
+	var got testPerson
+
+	require.NoError(t, decode(ctx, s, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 42}, got)
+
+	require.ErrorIs(t, decode(ctx, "not json", &got), ErrInvalidData)
+
+	// the wire format header is binary
+	reg, err := NewJSON(WithSchema(`{"type":"object"}`), WithRegistry(NewMemoryRegistry(), "people-value"))
+	require.NoError(t, err)
+
+	s, err = StringEncodeFunc(reg)(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.NoError(t, StringDecodeFunc(reg)(ctx, s, &got))
+	require.ErrorIs(t, StringDecodeFunc(reg)(ctx, "{}", &got), ErrInvalidWireFormat)
+}
@@ -0,0 +1,32 @@
+package codec
+
+import "errors"
+
+// Sentinel errors returned by the package. Match them with errors.Is so callers
+// can distinguish configuration problems from invalid messages.
+var (
+	// ErrInvalidOptions is returned by the constructors when a required option
+	// is missing or invalid.
+	ErrInvalidOptions = errors.New("codec: missing or invalid options")
+
+	// ErrInvalidData is returned when the data type is not supported by the
+	// codec, or the data does not match its schema.
+	ErrInvalidData = errors.New("codec: invalid data")
+
+	// ErrInvalidWireFormat is returned by Decode when a message is not in the
+	// expected wire format.
+	ErrInvalidWireFormat = errors.New("codec: invalid wire format")
+
+	// ErrSchemaNotFound is returned when the registry has no schema with the
+	// requested ID.
+	ErrSchemaNotFound = errors.New("codec: schema not found")
+
+	// ErrSchemaMismatch is returned by Decode when the schema of a message is
+	// not of the codec type, or, for Protobuf, when the message indexes do not
+	// match the decoded message type.
+	ErrSchemaMismatch = errors.New("codec: schema type mismatch")
+
+	// ErrValidation is returned by the JSON codec when a payload does not
+	// validate against the JSON Schema.
+	ErrValidation = errors.New("codec: JSON Schema validation failed")
+)
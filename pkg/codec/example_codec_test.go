@@ -0,0 +1,65 @@
+package codec_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/tecnickcom/nurago/pkg/codec"
+)
+
+func ExampleNewJSON() {
+	c, err := codec.NewJSON(
+		codec.WithSchema(`{"type": "object", "required": ["name"]}`),
+		codec.WithRegistry(codec.NewMemoryRegistry(), "people-value"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	ctx := context.Background()
+
+	msg, err := c.Encode(ctx, Person{Name: "alice"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%v %s\n", msg[:5], msg[5:])
+
+	var p Person
+
+	err = c.Decode(ctx, msg, &p)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(p.Name)
+
+	// Output:
+	// [0 0 0 0 1] {"name":"alice"}
+	// alice
+}
+
+func ExampleStringEncodeFunc() {
+	c, err := codec.NewAvro(codec.WithSchema(`"string"`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// e.g. sqs.WithMessageEncodeFunc(codec.StringEncodeFunc(c))
+	encode := codec.StringEncodeFunc(c)
+
+	msg, err := encode(context.Background(), "hello")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(msg)
+
+	// Output:
+	// CmhlbGxv
+}
@@ -0,0 +1,259 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRegistryTimeout is the default timeout of the registry requests.
+	defaultRegistryTimeout = 10 * time.Second
+
+	// registryContentType is the media type of the Schema Registry API.
+	registryContentType = "application/vnd.schemaregistry.v1+json"
+
+	// maxRegistryBodyBytes caps the size of a registry response body read.
+	maxRegistryBodyBytes = 8 << 20
+)
+
+// HTTPClient is the minimal HTTP transport contract used by [HTTPRegistry].
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// RegistryOption configures an [HTTPRegistry].
+type RegistryOption func(r *HTTPRegistry)
+
+// WithHTTPClient injects a custom HTTP client implementation.
+func WithHTTPClient(hc HTTPClient) RegistryOption {
+	return func(r *HTTPRegistry) {
+		r.httpClient = hc
+	}
+}
+
+// WithTimeout sets the registry request timeout (default 10 seconds).
+// Non-positive values are ignored.
+func WithTimeout(timeout time.Duration) RegistryOption {
+	return func(r *HTTPRegistry) {
+		if timeout > 0 {
+			r.timeout = timeout
+		}
+	}
+}
+
+// WithBasicAuth sets the credentials of the registry requests, e.g. the API
+// key and secret of a Confluent Cloud registry.
+func WithBasicAuth(username, password string) RegistryOption {
+	return func(r *HTTPRegistry) {
+		r.username = username
+		r.password = password
+	}
+}
+
+// HTTPRegistry is a [Registry] client of the Confluent Schema Registry REST
+// API. The registered IDs and the fetched schemas are cached, as they are
+// immutable.
+//
+// It is safe for concurrent use.
+type HTTPRegistry struct {
+	httpClient HTTPClient
+	baseURL    string
+	timeout    time.Duration
+	username   string
+	password   string
+
+	mu      sync.RWMutex
+	ids     map[registryKey]int
+	schemas map[int]Schema
+}
+
+// registryKey is the cache key of a registered schema.
+type registryKey struct {
+	subject string
+	schema  Schema
+}
+
+// registrySchema is the schema representation of the registry API.
+type registrySchema struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"` // empty means AVRO
+}
+
+// registryID is the registration response of the registry API.
+type registryID struct {
+	ID int `json:"id"`
+}
+
+// registryError is the error response of the registry API.
+type registryError struct {
+	ErrorCode int    `json:"error_code"` //nolint:tagliatelle
+	Message   string `json:"message"`
+}
+
+// NewHTTPRegistry returns a client of the registry at baseURL (e.g.
+// "http://localhost:8081"), which must be an absolute http or https URL.
+func NewHTTPRegistry(baseURL string, opts ...RegistryOption) (*HTTPRegistry, error) {
+	r := &HTTPRegistry{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		timeout: defaultRegistryTimeout,
+		ids:     make(map[registryKey]int),
+		schemas: make(map[int]Schema),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(r)
+	}
+
+	u, err := url.ParseRequestURI(r.baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid registry address %q: %w", baseURL, ErrInvalidOptions)
+	}
+
+	if r.httpClient == nil {
+		r.httpClient = &http.Client{Timeout: r.timeout}
+	}
+
+	return r, nil
+}
+
+// Register registers schema under subject and returns its ID.
+func (r *HTTPRegistry) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := registryKey{subject: subject, schema: schema}
+
+	r.mu.RLock()
+	id, ok := r.ids[key]
+	r.mu.RUnlock()
+
+	if ok {
+		return id, nil
+	}
+
+	req := registrySchema{Schema: schema.Definition}
+	if schema.Type != SchemaTypeAvro {
+		req.SchemaType = schema.Type
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return 0, fmt.Errorf("cannot encode the registry request: %w", err)
+	}
+
+	var resp registryID
+
+	err = r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	r.ids[key] = resp.ID
+	r.schemas[resp.ID] = schema
+	r.mu.Unlock()
+
+	return resp.ID, nil
+}
+
+// Schema returns the schema with the given ID.
+func (r *HTTPRegistry) Schema(ctx context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	s, ok := r.schemas[id]
+	r.mu.RUnlock()
+
+	if ok {
+		return s, nil
+	}
+
+	var resp registrySchema
+
+	err := r.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &resp)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	s = Schema{Type: resp.SchemaType, Definition: resp.Schema}
+	if s.Type == "" {
+		s.Type = SchemaTypeAvro
+	}
+
+	r.mu.Lock()
+	r.schemas[id] = s
+	r.mu.Unlock()
+
+	return s, nil
+}
+
+// do performs a registry API request and decodes the response into out. A 404
+// response is reported with an error matching ErrSchemaNotFound.
+//
+//nolint:nonamedreturns
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, body []byte, out any) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Accept", registryContentType)
+
+	if body != nil {
+		req.Header.Set("Content-Type", registryContentType)
+	}
+
+	if r.username != "" || r.password != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed performing the registry request: %w", err)
+	}
+
+	defer func() { err = errors.Join(err, resp.Body.Close()) }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryBodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed reading the registry response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return registryStatusError(resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("failed decoding the registry response: %w", err)
+	}
+
+	return nil
+}
+
+// registryStatusError returns the error of a registry response with an
+// unexpected status code.
+func registryStatusError(status int, body []byte) error {
+	var rerr registryError
+
+	msg := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &rerr) == nil && rerr.Message != "" {
+		msg = rerr.Message
+	}
+
+	err := fmt.Errorf("unexpected registry status code %d: %s", status, msg)
+
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrSchemaNotFound, err)
+	}
+
+	return err
+}
@@ -0,0 +1,213 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRegistryServer returns a Schema Registry API stub backed by a
+// MemoryRegistry, counting the requests.
+func newTestRegistryServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mem := NewMemoryRegistry()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		user, pass, _ := r.BasicAuth()
+		if user != "key" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_code":401,"message":"Unauthorized"}`))
+
+			return
+		}
+
+		if r.Header.Get("Content-Type") != registryContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var req registrySchema
+
+		if json.NewDecoder(r.Body).Decode(&req) != nil || req.Schema == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error_code":42201,"message":"Invalid schema"}`))
+
+			return
+		}
+
+		if req.SchemaType == "" {
+			req.SchemaType = SchemaTypeAvro
+		}
+
+		id, _ := mem.Register(r.Context(), r.PathValue("subject"), Schema{Type: req.SchemaType, Definition: req.Schema})
+
+		_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(id) + `}`))
+	})
+
+	mux.HandleFunc("GET /schemas/ids/{id}", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		id, _ := strconv.Atoi(r.PathValue("id"))
+
+		s, err := mem.Schema(r.Context(), id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+
+			return
+		}
+
+		resp := registrySchema{Schema: s.Definition}
+		if s.Type != SchemaTypeAvro {
+			resp.SchemaType = s.Type
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("GET /bad/schemas/ids/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`not json`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestNewHTTPRegistry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		opts    []RegistryOption
+		wantErr bool
+	}{
+		{name: "valid", baseURL: "http://localhost:8081/"},
+		{name: "https with options", baseURL: "https://registry.example.com", opts: []RegistryOption{
+			WithTimeout(time.Second), WithTimeout(0), WithBasicAuth("k", "s"), WithHTTPClient(http.DefaultClient),
+		}},
+		{name: "empty", baseURL: "", wantErr: true},
+		{name: "relative", baseURL: "registry:8081", wantErr: true},
+		{name: "bad scheme", baseURL: "ftp://registry", wantErr: true},
+		{name: "missing host", baseURL: "http://", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := NewHTTPRegistry(tt.baseURL, tt.opts...)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidOptions)
+				require.Nil(t, r)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, r.httpClient)
+			require.False(t, strings.HasSuffix(r.baseURL, "/"))
+		})
+	}
+}
+
+func TestHTTPRegistry(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	srv := newTestRegistryServer(t, &requests)
+
+	r, err := NewHTTPRegistry(srv.URL, WithBasicAuth("key", "secret"))
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	avroSchema := Schema{Type: SchemaTypeAvro, Definition: `"string"`}
+	protoSchema := Schema{Type: SchemaTypeProtobuf, Definition: `syntax = "proto3"; message M {}`}
+
+	id1, err := r.Register(ctx, "a-value", avroSchema)
+	require.NoError(t, err)
+
+	id2, err := r.Register(ctx, "a-value", protoSchema)
+	require.NoError(t, err)
+	require.NotEqual(t, id1, id2)
+
+	id, err := r.Register(ctx, "a-value", avroSchema)
+	require.NoError(t, err)
+	require.Equal(t, id1, id)
+	require.Equal(t, int32(2), requests.Load(), "the registered IDs are cached")
+
+	// a fresh client fetches the schemas by ID.
+	r2, err := NewHTTPRegistry(srv.URL)
+	require.NoError(t, err)
+
+	for range 2 {
+		s, err := r2.Schema(ctx, id1)
+		require.NoError(t, err)
+		require.Equal(t, avroSchema, s)
+	}
+
+	s, err := r2.Schema(ctx, id2)
+	require.NoError(t, err)
+	require.Equal(t, protoSchema, s)
+	require.Equal(t, int32(4), requests.Load(), "the fetched schemas are cached")
+
+	_, err = r2.Schema(ctx, 99)
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+	require.ErrorContains(t, err, "unexpected registry status code 404: Schema not found")
+
+	_, err = r2.Register(ctx, "a-value", avroSchema)
+	require.ErrorContains(t, err, "unexpected registry status code 401: Unauthorized")
+	require.NotErrorIs(t, err, ErrSchemaNotFound)
+
+	_, err = r.Register(ctx, "a-value", Schema{Type: SchemaTypeJSON})
+	require.ErrorContains(t, err, "unexpected registry status code 422: Invalid schema")
+
+	r3, err := NewHTTPRegistry(srv.URL + "/bad")
+	require.NoError(t, err)
+
+	_, err = r3.Schema(ctx, 1)
+	require.ErrorContains(t, err, "failed decoding the registry response")
+}
+
+// httpClientFunc is an HTTPClient function.
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPRegistry_transportError(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewHTTPRegistry("http://registry.invalid", WithHTTPClient(httpClientFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("dial error")
+	})))
+	require.NoError(t, err)
+
+	_, err = r.Schema(t.Context(), 1)
+	require.ErrorContains(t, err, "failed performing the registry request: dial error")
+}
+
+func TestRegistryStatusError(t *testing.T) {
+	t.Parallel()
+
+	err := registryStatusError(http.StatusInternalServerError, []byte(" oops \n"))
+	require.EqualError(t, err, "unexpected registry status code 500: oops")
+}
@@ -0,0 +1,162 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// jsonSchemaURL is the location of the compiled JSON Schemas.
+const jsonSchemaURL = "schema.json"
+
+// JSONCodec is the JSON [Codec]. When a JSON Schema is configured with
+// WithSchema, the payloads are validated against it; with a registry, the
+// decoded payloads are validated against the schema of their ID.
+type JSONCodec struct {
+	cfg     config
+	schema  *jsonschema.Schema
+	reg     *registration
+	writers *schemaCache[*jsonschema.Schema]
+}
+
+// NewJSON returns a JSON codec.
+func NewJSON(opts ...Option) (*JSONCodec, error) {
+	c := &JSONCodec{}
+
+	for _, applyOpt := range opts {
+		applyOpt(&c.cfg)
+	}
+
+	err := c.cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.schema != "" {
+		c.schema, err = compileJSONSchema(c.cfg.schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON Schema: %w: %w", ErrInvalidOptions, err)
+		}
+	}
+
+	if c.cfg.registry != nil {
+		c.reg = &registration{cfg: &c.cfg, schema: Schema{Type: SchemaTypeJSON, Definition: c.cfg.schema}}
+		c.writers = newSchemaCache(c.cfg.registry, SchemaTypeJSON, func(s Schema) (*jsonschema.Schema, error) {
+			return compileJSONSchema(s.Definition)
+		})
+	}
+
+	return c, nil
+}
+
+// Encode returns the JSON encoding of data, validated against the configured
+// JSON Schema, if any.
+func (c *JSONCodec) Encode(ctx context.Context, data any) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("JSON encode: %w: %w", ErrInvalidData, err)
+	}
+
+	err = validateJSON(c.schema, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.reg == nil {
+		return payload, nil
+	}
+
+	id, err := c.reg.schemaID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(appendWireHeader(make([]byte, 0, wireHeaderLen+len(payload)), id), payload...), nil
+}
+
+// Decode decodes the JSON msg into data, after validating it.
+func (c *JSONCodec) Decode(ctx context.Context, msg []byte, data any) error {
+	payload, schema := msg, c.schema
+
+	if c.reg != nil {
+		id, rest, err := parseWireHeader(msg)
+		if err != nil {
+			return err
+		}
+
+		payload = rest
+
+		schema, err = c.writers.get(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := validateJSON(schema, payload)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(payload, data)
+	if err != nil {
+		return fmt.Errorf("JSON decode: %w: %w", ErrInvalidData, err)
+	}
+
+	return nil
+}
+
+// text reports whether the encoding is plain JSON text, without the binary
+// header of the Confluent wire format.
+func (c *JSONCodec) text() bool {
+	return c.reg == nil
+}
+
+// compileJSONSchema compiles a JSON Schema definition. An empty definition
+// returns a nil schema, which validates any payload.
+func compileJSONSchema(definition string) (*jsonschema.Schema, error) {
+	if definition == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(definition))
+	if err != nil {
+		return nil, fmt.Errorf("JSON Schema decode: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+
+	err = compiler.AddResource(jsonSchemaURL, doc)
+	if err != nil {
+		return nil, fmt.Errorf("JSON Schema load: %w", err)
+	}
+
+	schema, err := compiler.Compile(jsonSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("JSON Schema compile: %w", err)
+	}
+
+	return schema, nil
+}
+
+// validateJSON validates the JSON payload against schema, if not nil.
+func validateJSON(schema *jsonschema.Schema, payload []byte) error {
+	if schema == nil {
+		return nil
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("JSON decode: %w: %w", ErrInvalidData, err)
+	}
+
+	err = schema.Validate(doc)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	return nil
+}
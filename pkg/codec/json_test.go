@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testJSONSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name"]
+}`
+
+type testPerson struct {
+	Name string `json:"name" avro:"name"`
+	Age  int    `json:"age"  avro:"age"`
+}
+
+func TestNewJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "plain"},
+		{name: "schema", opts: []Option{WithSchema(testJSONSchema)}},
+		{name: "registry", opts: []Option{WithSchema(testJSONSchema), WithRegistry(NewMemoryRegistry(), "s")}},
+		{name: "invalid schema JSON", opts: []Option{WithSchema(`{`)}, wantErr: true},
+		{name: "invalid schema", opts: []Option{WithSchema(`{"type": 1}`)}, wantErr: true},
+		{name: "registry without subject", opts: []Option{WithSchema(testJSONSchema), WithRegistry(NewMemoryRegistry(), "")}, wantErr: true},
+		{name: "registry without schema", opts: []Option{WithRegistry(NewMemoryRegistry(), "s")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewJSON(tt.opts...)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidOptions)
+				require.Nil(t, c)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, c)
+		})
+	}
+}
+
+func TestJSONCodec(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, err := NewJSON(WithSchema(testJSONSchema))
+	require.NoError(t, err)
+
+	b, err := c.Encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"alice","age":42}`, string(b))
+
+	var got testPerson
+
+	require.NoError(t, c.Decode(ctx, b, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 42}, got)
+
+	_, err = c.Encode(ctx, testPerson{Name: "bob", Age: -1})
+	require.ErrorIs(t, err, ErrValidation)
+
+	_, err = c.Encode(ctx, make(chan int))
+	require.ErrorIs(t, err, ErrInvalidData)
+
+	require.ErrorIs(t, c.Decode(ctx, []byte(`{"age":1}`), &got), ErrValidation)
+	require.ErrorIs(t, c.Decode(ctx, []byte(`{`), &got), ErrInvalidData)
+
+	plain, err := NewJSON()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, plain.Decode(ctx, []byte(`{"name":1}`), &got), ErrInvalidData)
+}
+
+func TestJSONCodec_registry(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := NewMemoryRegistry()
+
+	c, err := NewJSON(WithSchema(testJSONSchema), WithRegistry(r, "people-value"))
+	require.NoError(t, err)
+
+	b, err := c.Encode(ctx, testPerson{Name: "alice", Age: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 1}, b[:wireHeaderLen])
+	require.JSONEq(t, `{"name":"alice","age":42}`, string(b[wireHeaderLen:]))
+
+	var got testPerson
+
+	require.NoError(t, c.Decode(ctx, b, &got))
+	require.Equal(t, testPerson{Name: "alice", Age: 42}, got)
+
+	// a payload written with a newer, stricter schema version.
+	id, err := r.Register(ctx, "people-value", Schema{Type: SchemaTypeJSON, Definition: `{"required": ["email"]}`})
+	require.NoError(t, err)
+
+	msg := appendWireHeader(nil, id)
+	require.ErrorIs(t, c.Decode(ctx, append(msg, `{"name":"bob"}`...), &got), ErrValidation)
+	require.NoError(t, c.Decode(ctx, append(msg, `{"name":"bob","email":"b@example.com"}`...), &got))
+	require.Equal(t, "bob", got.Name)
+
+	avroID, err := r.Register(ctx, "other", Schema{Type: SchemaTypeAvro, Definition: `"string"`})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.Decode(ctx, appendWireHeader(nil, avroID), &got), ErrSchemaMismatch)
+	require.ErrorIs(t, c.Decode(ctx, []byte(`{}`), &got), ErrInvalidWireFormat)
+
+	failing, err := NewJSON(WithSchema(testJSONSchema), WithRegistry(failingRegistry{}, "s"))
+	require.NoError(t, err)
+
+	_, err = failing.Encode(ctx, testPerson{Name: "alice"})
+	require.ErrorContains(t, err, "register failed")
+}
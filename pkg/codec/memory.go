@@ -0,0 +1,72 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// MemoryRegistry is an in-memory [Registry], mainly for tests. Like the
+// Confluent Schema Registry, it assigns the same ID to the same schema
+// registered under different subjects.
+//
+// It is safe for concurrent use.
+type MemoryRegistry struct {
+	mu       sync.RWMutex
+	schemas  []Schema // the schema with ID i is at index i-1
+	ids      map[Schema]int
+	subjects map[string][]int
+}
+
+// NewMemoryRegistry returns an empty MemoryRegistry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		ids:      make(map[Schema]int),
+		subjects: make(map[string][]int),
+	}
+}
+
+// Register registers schema under subject and returns its ID.
+func (r *MemoryRegistry) Register(_ context.Context, subject string, schema Schema) (int, error) {
+	if subject == "" {
+		return 0, fmt.Errorf("empty subject: %w", ErrInvalidOptions)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.ids[schema]
+	if !ok {
+		r.schemas = append(r.schemas, schema)
+		id = len(r.schemas)
+		r.ids[schema] = id
+	}
+
+	if !slices.Contains(r.subjects[subject], id) {
+		r.subjects[subject] = append(r.subjects[subject], id)
+	}
+
+	return id, nil
+}
+
+// Schema returns the schema with the given ID.
+func (r *MemoryRegistry) Schema(_ context.Context, id int) (Schema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id < 1 || id > len(r.schemas) {
+		return Schema{}, fmt.Errorf("schema %d: %w", id, ErrSchemaNotFound)
+	}
+
+	return r.schemas[id-1], nil
+}
+
+// Versions returns the IDs of the schemas registered under subject, in
+// registration order.
+func (r *MemoryRegistry) Versions(subject string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.subjects[subject])
+}
@@ -0,0 +1,50 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := NewMemoryRegistry()
+
+	s1 := Schema{Type: SchemaTypeJSON, Definition: `{"type":"string"}`}
+	s2 := Schema{Type: SchemaTypeJSON, Definition: `{"type":"integer"}`}
+
+	id1, err := r.Register(ctx, "a", s1)
+	require.NoError(t, err)
+	require.Equal(t, 1, id1)
+
+	id, err := r.Register(ctx, "a", s1)
+	require.NoError(t, err)
+	require.Equal(t, id1, id)
+
+	id, err = r.Register(ctx, "b", s1)
+	require.NoError(t, err)
+	require.Equal(t, id1, id, "the same schema has the same ID in all subjects")
+
+	id2, err := r.Register(ctx, "a", s2)
+	require.NoError(t, err)
+	require.Equal(t, 2, id2)
+
+	require.Equal(t, []int{1, 2}, r.Versions("a"))
+	require.Equal(t, []int{1}, r.Versions("b"))
+	require.Empty(t, r.Versions("c"))
+
+	got, err := r.Schema(ctx, id2)
+	require.NoError(t, err)
+	require.Equal(t, s2, got)
+
+	_, err = r.Schema(ctx, 0)
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	_, err = r.Schema(ctx, 3)
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	_, err = r.Register(ctx, "", s1)
+	require.ErrorIs(t, err, ErrInvalidOptions)
+}
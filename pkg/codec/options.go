@@ -0,0 +1,49 @@
+package codec
+
+import "fmt"
+
+// Option configures a codec.
+type Option func(c *config)
+
+// config holds the codec configuration options.
+type config struct {
+	registry Registry
+	subject  string
+	schema   string
+}
+
+// validate checks the options common to the codecs.
+func (c *config) validate() error {
+	if c.registry == nil {
+		return nil
+	}
+
+	if c.subject == "" {
+		return fmt.Errorf("empty registry subject: %w", ErrInvalidOptions)
+	}
+
+	if c.schema == "" {
+		return fmt.Errorf("missing schema to register: %w", ErrInvalidOptions)
+	}
+
+	return nil
+}
+
+// WithRegistry enables the Confluent wire format: the codec schema (see
+// WithSchema, required) is registered in r under subject on the first Encode,
+// and the schemas of the decoded messages are fetched from r by ID.
+func WithRegistry(r Registry, subject string) Option {
+	return func(c *config) {
+		c.registry = r
+		c.subject = subject
+	}
+}
+
+// WithSchema sets the schema definition of the codec: the JSON Schema
+// validating the JSON payloads, the .proto file registered by the Protobuf
+// codec, or the Avro schema (required) of the Avro codec.
+func WithSchema(definition string) Option {
+	return func(c *config) {
+		c.schema = definition
+	}
+}
@@ -0,0 +1,26 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewMemoryRegistry()
+
+	cfg := &config{}
+	WithRegistry(r, "subject")(cfg)
+	require.Same(t, r, cfg.registry)
+	require.Equal(t, "subject", cfg.subject)
+}
+
+func TestWithSchema(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithSchema(`"string"`)(cfg)
+	require.Equal(t, `"string"`, cfg.schema)
+}
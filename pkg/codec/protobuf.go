@@ -0,0 +1,185 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtobufCodec is the Protocol Buffers [Codec] of proto.Message values. With
+// a registry, the .proto file set with WithSchema is registered, and the
+// message indexes of the Confluent wire format locate the message type in it.
+type ProtobufCodec struct {
+	cfg     config
+	reg     *registration
+	writers *schemaCache[struct{}]
+}
+
+// NewProtobuf returns a Protobuf codec.
+func NewProtobuf(opts ...Option) (*ProtobufCodec, error) {
+	c := &ProtobufCodec{}
+
+	for _, applyOpt := range opts {
+		applyOpt(&c.cfg)
+	}
+
+	err := c.cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.registry != nil {
+		c.reg = &registration{cfg: &c.cfg, schema: Schema{Type: SchemaTypeProtobuf, Definition: c.cfg.schema}}
+		c.writers = newSchemaCache(c.cfg.registry, SchemaTypeProtobuf, func(Schema) (struct{}, error) {
+			return struct{}{}, nil
+		})
+	}
+
+	return c, nil
+}
+
+// Encode returns the Protobuf encoding of data, which must be a proto.Message.
+func (c *ProtobufCodec) Encode(ctx context.Context, data any) ([]byte, error) {
+	m, ok := data.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message: %w", data, ErrInvalidData)
+	}
+
+	if c.reg == nil {
+		b, err := proto.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("protobuf encode: %w: %w", ErrInvalidData, err)
+		}
+
+		return b, nil
+	}
+
+	id, err := c.reg.schemaID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b := appendMessageIndexes(appendWireHeader(nil, id), m.ProtoReflect().Descriptor())
+
+	b, err = proto.MarshalOptions{}.MarshalAppend(b, m)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf encode: %w: %w", ErrInvalidData, err)
+	}
+
+	return b, nil
+}
+
+// Decode decodes the Protobuf msg into data, which must be a proto.Message.
+// With a registry, a message whose schema is not Protobuf, or whose message
+// indexes do not locate the type of data, is rejected with an error matching
+// [ErrSchemaMismatch].
+func (c *ProtobufCodec) Decode(ctx context.Context, msg []byte, data any) error {
+	m, ok := data.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message: %w", data, ErrInvalidData)
+	}
+
+	payload := msg
+
+	if c.reg != nil {
+		id, rest, err := parseWireHeader(msg)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.writers.get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var indexes []int
+
+		indexes, payload, err = parseMessageIndexes(rest)
+		if err != nil {
+			return err
+		}
+
+		want := messageIndexes(m.ProtoReflect().Descriptor())
+		if !slices.Equal(indexes, want) {
+			return fmt.Errorf("message indexes %v, not %v of %s: %w",
+				indexes, want, m.ProtoReflect().Descriptor().FullName(), ErrSchemaMismatch)
+		}
+	}
+
+	err := proto.Unmarshal(payload, m)
+	if err != nil {
+		return fmt.Errorf("protobuf decode: %w: %w", ErrInvalidData, err)
+	}
+
+	return nil
+}
+
+// messageIndexes returns the path of a message type in its .proto file: the
+// index of the top-level message, followed by the indexes of the nested ones.
+func messageIndexes(md protoreflect.MessageDescriptor) []int {
+	var indexes []int
+
+	for d := protoreflect.Descriptor(md); ; d = d.Parent() {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+
+		indexes = append(indexes, d.Index())
+	}
+
+	slices.Reverse(indexes)
+
+	return indexes
+}
+
+// appendMessageIndexes appends the Confluent wire format message indexes of a
+// message type: their count and values as zigzag varints, or a single zero
+// for the first top-level message.
+func appendMessageIndexes(dst []byte, md protoreflect.MessageDescriptor) []byte {
+	indexes := messageIndexes(md)
+
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(dst, 0)
+	}
+
+	dst = binary.AppendVarint(dst, int64(len(indexes)))
+
+	for _, i := range indexes {
+		dst = binary.AppendVarint(dst, int64(i))
+	}
+
+	return dst
+}
+
+// parseMessageIndexes returns the Confluent wire format message indexes at the
+// start of msg and the rest of the message.
+func parseMessageIndexes(msg []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(msg)
+	if n <= 0 || count < 0 || count > int64(len(msg)) {
+		return nil, nil, fmt.Errorf("invalid message indexes: %w", ErrInvalidWireFormat)
+	}
+
+	msg = msg[n:]
+
+	if count == 0 {
+		return []int{0}, msg, nil
+	}
+
+	indexes := make([]int, count)
+
+	for i := range indexes {
+		v, n := binary.Varint(msg)
+		if n <= 0 || v < 0 {
+			return nil, nil, fmt.Errorf("invalid message indexes: %w", ErrInvalidWireFormat)
+		}
+
+		indexes[i] = int(v)
+		msg = msg[n:]
+	}
+
+	return indexes, msg, nil
+}
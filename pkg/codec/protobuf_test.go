@@ -0,0 +1,146 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testProtoSchema = `syntax = "proto3"; package google.protobuf; message StringValue { string value = 1; }`
+
+func TestNewProtobuf(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProtobuf()
+	require.NoError(t, err)
+
+	_, err = NewProtobuf(WithRegistry(NewMemoryRegistry(), "s"))
+	require.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestProtobufCodec(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, err := NewProtobuf()
+	require.NoError(t, err)
+
+	b, err := c.Encode(ctx, wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	want, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+	require.Equal(t, want, b)
+
+	got := &wrapperspb.StringValue{}
+
+	require.NoError(t, c.Decode(ctx, b, got))
+	require.Equal(t, "hello", got.GetValue())
+
+	_, err = c.Encode(ctx, "hello")
+	require.ErrorIs(t, err, ErrInvalidData)
+
+	var s string
+
+	require.ErrorIs(t, c.Decode(ctx, b, &s), ErrInvalidData)
+	require.ErrorIs(t, c.Decode(ctx, []byte{0xff}, got), ErrInvalidData)
+}
+
+func TestProtobufCodec_registry(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	r := NewMemoryRegistry()
+
+	c, err := NewProtobuf(WithSchema(testProtoSchema), WithRegistry(r, "greetings-value"))
+	require.NoError(t, err)
+
+	b, err := c.Encode(ctx, wrapperspb.String("hello"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 1, 2, 14}, b[:7], "header and message indexes [7]")
+
+	got := &wrapperspb.StringValue{}
+
+	require.NoError(t, c.Decode(ctx, b, got))
+	require.Equal(t, "hello", got.GetValue())
+
+	s, err := r.Schema(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, Schema{Type: SchemaTypeProtobuf, Definition: testProtoSchema}, s)
+
+	jsonID, err := r.Register(ctx, "other", Schema{Type: SchemaTypeJSON, Definition: "{}"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.Decode(ctx, appendWireHeader(nil, jsonID), got), ErrSchemaMismatch)
+
+	// a payload of another message type is not decoded
+	require.ErrorIs(t, c.Decode(ctx, b, &wrapperspb.Int64Value{}), ErrSchemaMismatch)
+	require.ErrorIs(t, c.Decode(ctx, []byte{1}, got), ErrInvalidWireFormat)
+	require.ErrorIs(t, c.Decode(ctx, appendWireHeader(nil, 1), got), ErrInvalidWireFormat)
+
+	failing, err := NewProtobuf(WithSchema(testProtoSchema), WithRegistry(failingRegistry{}, "s"))
+	require.NoError(t, err)
+
+	_, err = failing.Encode(ctx, wrapperspb.String("hello"))
+	require.ErrorContains(t, err, "register failed")
+}
+
+func TestMessageIndexes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		msg         proto.Message
+		wantIndexes []int
+		wantBytes   []byte
+	}{
+		{
+			name:        "first top-level message",
+			msg:         &wrapperspb.DoubleValue{},
+			wantIndexes: []int{0},
+			wantBytes:   []byte{0},
+		},
+		{
+			name:        "top-level message",
+			msg:         &wrapperspb.StringValue{},
+			wantIndexes: []int{7},
+			wantBytes:   []byte{2, 14},
+		},
+		{
+			name:        "nested message",
+			msg:         &descriptorpb.DescriptorProto_ExtensionRange{},
+			wantIndexes: []int{2, 0},
+			wantBytes:   []byte{4, 4, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			md := tt.msg.ProtoReflect().Descriptor()
+			require.Equal(t, tt.wantIndexes, messageIndexes(md))
+
+			b := appendMessageIndexes(nil, md)
+			require.Equal(t, tt.wantBytes, b)
+
+			indexes, rest, err := parseMessageIndexes(append(b, 'x'))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantIndexes, indexes)
+			require.Equal(t, []byte("x"), rest)
+		})
+	}
+}
+
+func TestParseMessageIndexes_invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, msg := range [][]byte{nil, {1}, {2}, {4, 1}, {0x80}} {
+		_, _, err := parseMessageIndexes(msg)
+		require.ErrorIs(t, err, ErrInvalidWireFormat, "%v", msg)
+	}
+}
@@ -0,0 +1,134 @@
+package codec
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/tecnickcom/nurago/pkg/sfcache"
+)
+
+const (
+	// wireMagic is the first byte of the Confluent wire format.
+	wireMagic byte = 0
+
+	// wireHeaderLen is the length of the magic byte and the schema ID.
+	wireHeaderLen = 5
+)
+
+// appendWireHeader appends the Confluent wire format header of a schema ID.
+func appendWireHeader(dst []byte, id int) []byte {
+	dst = append(dst, wireMagic)
+
+	return binary.BigEndian.AppendUint32(dst, uint32(id)) //nolint:gosec // registry IDs are int32
+}
+
+// parseWireHeader returns the schema ID of a Confluent wire format message
+// and the rest of the message.
+func parseWireHeader(msg []byte) (int, []byte, error) {
+	if len(msg) < wireHeaderLen || msg[0] != wireMagic {
+		return 0, nil, fmt.Errorf("missing schema header: %w", ErrInvalidWireFormat)
+	}
+
+	return int(binary.BigEndian.Uint32(msg[1:wireHeaderLen])), msg[wireHeaderLen:], nil
+}
+
+// registration registers the schema of a codec once, on first use.
+type registration struct {
+	cfg    *config
+	schema Schema
+
+	mu sync.Mutex
+	id int
+}
+
+// schemaID returns the registry ID of the codec schema, registering it on the
+// first successful call.
+func (r *registration) schemaID(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.id != 0 {
+		return r.id, nil
+	}
+
+	id, err := r.cfg.registry.Register(ctx, r.cfg.subject, r.schema)
+	if err != nil {
+		return 0, fmt.Errorf("cannot register the schema under subject %q: %w", r.cfg.subject, err)
+	}
+
+	r.id = id
+
+	return id, nil
+}
+
+// schemaCache caches a value derived from the registry schema of each ID.
+// Concurrent misses of an ID share a single fetch, and do not delay the hits
+// of the other IDs.
+type schemaCache[T any] struct {
+	registry Registry
+	typ      SchemaType
+	load     func(s Schema) (T, error)
+	flights  *sfcache.Cache[int, T]
+
+	mu     sync.RWMutex
+	values map[int]T
+}
+
+// newSchemaCache returns a schemaCache of the schemas of type typ in r,
+// converted by load.
+func newSchemaCache[T any](r Registry, typ SchemaType, load func(s Schema) (T, error)) *schemaCache[T] {
+	c := &schemaCache[T]{
+		registry: r,
+		typ:      typ,
+		load:     load,
+		values:   make(map[int]T),
+	}
+
+	// The zero config caches nothing and only coalesces the fetches: the
+	// immutable schemas are kept in values.
+	c.flights = sfcache.New(c.fetch, sfcache.Config{})
+
+	return c
+}
+
+// get returns the value of the schema with the given ID, fetching and
+// converting it on the first call. A schema of another type is rejected with
+// an error matching ErrSchemaMismatch.
+func (c *schemaCache[T]) get(ctx context.Context, id int) (T, error) {
+	c.mu.RLock()
+	v, ok := c.values[id]
+	c.mu.RUnlock()
+
+	if ok {
+		return v, nil
+	}
+
+	return c.flights.Lookup(ctx, id) //nolint:wrapcheck // fetch errors are already wrapped
+}
+
+// fetch fetches and converts the schema with the given ID, and caches it.
+func (c *schemaCache[T]) fetch(ctx context.Context, id int) (T, error) {
+	var zero T
+
+	s, err := c.registry.Schema(ctx, id)
+	if err != nil {
+		return zero, fmt.Errorf("cannot fetch the schema %d: %w", id, err)
+	}
+
+	if s.Type != c.typ {
+		return zero, fmt.Errorf("schema %d is %s, not %s: %w", id, s.Type, c.typ, ErrSchemaMismatch)
+	}
+
+	v, err := c.load(s)
+	if err != nil {
+		return zero, fmt.Errorf("cannot load the schema %d: %w", id, err)
+	}
+
+	c.mu.Lock()
+	c.values[id] = v
+	c.mu.Unlock()
+
+	return v, nil
+}
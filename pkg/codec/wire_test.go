@@ -0,0 +1,178 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireHeader(t *testing.T) {
+	t.Parallel()
+
+	b := appendWireHeader([]byte{}, 258)
+	require.Equal(t, []byte{0, 0, 0, 1, 2}, b)
+
+	id, rest, err := parseWireHeader(append(b, 'x'))
+	require.NoError(t, err)
+	require.Equal(t, 258, id)
+	require.Equal(t, []byte("x"), rest)
+
+	_, _, err = parseWireHeader([]byte{0, 0, 0, 1})
+	require.ErrorIs(t, err, ErrInvalidWireFormat)
+
+	_, _, err = parseWireHeader([]byte{1, 0, 0, 0, 1})
+	require.ErrorIs(t, err, ErrInvalidWireFormat)
+}
+
+// failingRegistry is a Registry failing every call.
+type failingRegistry struct{}
+
+func (failingRegistry) Register(context.Context, string, Schema) (int, error) {
+	return 0, errors.New("register failed")
+}
+
+func (failingRegistry) Schema(context.Context, int) (Schema, error) {
+	return Schema{}, errors.New("lookup failed")
+}
+
+func TestRegistration_schemaID(t *testing.T) {
+	t.Parallel()
+
+	r := NewMemoryRegistry()
+
+	_, err := r.Register(t.Context(), "other", Schema{Type: SchemaTypeJSON, Definition: "{}"})
+	require.NoError(t, err)
+
+	reg := &registration{
+		cfg:    &config{registry: r, subject: "s"},
+		schema: Schema{Type: SchemaTypeAvro, Definition: `"string"`},
+	}
+
+	id, err := reg.schemaID(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+
+	id, err = reg.schemaID(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+	require.Equal(t, []int{2}, r.Versions("s"))
+
+	reg = &registration{cfg: &config{registry: failingRegistry{}, subject: "s"}}
+
+	_, err = reg.schemaID(t.Context())
+	require.ErrorContains(t, err, `cannot register the schema under subject "s": register failed`)
+}
+
+func TestSchemaCache_get(t *testing.T) {
+	t.Parallel()
+
+	r := NewMemoryRegistry()
+
+	jsonID, err := r.Register(t.Context(), "s", Schema{Type: SchemaTypeJSON, Definition: "{}"})
+	require.NoError(t, err)
+
+	avroID, err := r.Register(t.Context(), "s", Schema{Type: SchemaTypeAvro, Definition: `"string"`})
+	require.NoError(t, err)
+
+	loads := 0
+
+	c := newSchemaCache(r, SchemaTypeJSON, func(s Schema) (string, error) {
+		loads++
+
+		if s.Definition == "" {
+			return "", errors.New("empty")
+		}
+
+		return s.Definition, nil
+	})
+
+	for range 2 {
+		v, err := c.get(t.Context(), jsonID)
+		require.NoError(t, err)
+		require.Equal(t, "{}", v)
+	}
+
+	require.Equal(t, 1, loads)
+
+	_, err = c.get(t.Context(), avroID)
+	require.ErrorIs(t, err, ErrSchemaMismatch)
+
+	_, err = c.get(t.Context(), 99)
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	emptyID, err := r.Register(t.Context(), "s", Schema{Type: SchemaTypeJSON})
+	require.NoError(t, err)
+
+	_, err = c.get(t.Context(), emptyID)
+	require.ErrorContains(t, err, "cannot load the schema 3: empty")
+}
+
+// blockingRegistry is a Registry whose Schema calls for the blocked ID wait
+// for release, and which counts the Schema calls.
+type blockingRegistry struct {
+	Registry
+
+	blocked int
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (r *blockingRegistry) Schema(ctx context.Context, id int) (Schema, error) {
+	r.calls.Add(1)
+
+	if id == r.blocked {
+		<-r.release
+	}
+
+	return r.Registry.Schema(ctx, id) //nolint:wrapcheck
+}
+
+func TestSchemaCache_get_concurrent(t *testing.T) {
+	t.Parallel()
+
+	mem := NewMemoryRegistry()
+
+	fastID, err := mem.Register(t.Context(), "s", Schema{Type: SchemaTypeJSON, Definition: "{}"})
+	require.NoError(t, err)
+
+	slowID, err := mem.Register(t.Context(), "s", Schema{Type: SchemaTypeJSON, Definition: `{"type":"object"}`})
+	require.NoError(t, err)
+
+	r := &blockingRegistry{Registry: mem, blocked: slowID, release: make(chan struct{})}
+
+	c := newSchemaCache(r, SchemaTypeJSON, func(s Schema) (string, error) {
+		return s.Definition, nil
+	})
+
+	_, err = c.get(t.Context(), fastID)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Go(func() {
+			v, gerr := c.get(t.Context(), slowID)
+			assert.NoError(t, gerr)
+			assert.JSONEq(t, `{"type":"object"}`, v)
+		})
+	}
+
+	// The cached schemas are served while the slow fetch is in flight.
+	require.Eventually(t, func() bool { return r.calls.Load() == 2 }, time.Second, time.Millisecond)
+
+	v, err := c.get(t.Context(), fastID)
+	require.NoError(t, err)
+	require.Equal(t, "{}", v)
+
+	close(r.release)
+	wg.Wait()
+
+	// The concurrent misses shared a single fetch.
+	require.Equal(t, int32(2), r.calls.Load())
+}
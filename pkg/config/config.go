@@ -96,6 +96,13 @@ const (
 	keyLogFormat  = "log.format"
 	keyLogLevel   = "log.level"
 	keyLogNetwork = "log.network"
+
+	keyLogFilePath           = "log.file.path"
+	keyLogFileMaxSize        = "log.file.max_size"
+	keyLogFileRotateInterval = "log.file.rotate_interval"
+	keyLogFileMaxBackups     = "log.file.max_backups"
+	keyLogFileMaxAge         = "log.file.max_age"
+	keyLogFileCompress       = "log.file.compress"
 )
 
 // Logger configuration default values.
@@ -104,6 +111,13 @@ const (
 	defaultLogLevel   = "DEBUG"
 	defaultLogAddress = ""
 	defaultLogNetwork = ""
+
+	defaultLogFilePath           = ""
+	defaultLogFileMaxSize        = 0
+	defaultLogFileRotateInterval = 0
+	defaultLogFileMaxBackups     = 0
+	defaultLogFileMaxAge         = 0
+	defaultLogFileCompress       = false
 )
 
 // Extra parameters key names.
@@ -197,6 +211,30 @@ type LogConfig struct {
 
 	// Address is the optional remote syslog network address: (ip:port) or just (:port).
 	Address string `mapstructure:"address" validate:"omitempty,hostname_port"`
+
+	// File is the optional rotating log file configuration.
+	File LogFileConfig `mapstructure:"file"`
+}
+
+// LogFileConfig contains the configuration for the optional rotating log file.
+type LogFileConfig struct {
+	// Path is the optional log file path. When empty, the logs are written to stderr.
+	Path string `mapstructure:"path"`
+
+	// MaxSize is the size in megabytes a log file is rotated at (0 = no rotation by size).
+	MaxSize int `mapstructure:"max_size" validate:"min=0"`
+
+	// RotateInterval is the period in seconds a log file is rotated at (0 = no rotation by time).
+	RotateInterval int `mapstructure:"rotate_interval" validate:"min=0"`
+
+	// MaxBackups is the number of rotated log files retained (0 = all).
+	MaxBackups int `mapstructure:"max_backups" validate:"min=0"`
+
+	// MaxAge is the number of days the rotated log files are retained (0 = forever).
+	MaxAge int `mapstructure:"max_age" validate:"min=0"`
+
+	// Compress enables the gzip compression of the rotated log files.
+	Compress bool `mapstructure:"compress"`
 }
 
 // RemoteSourceConfig contains the remote source options used to locate and load
@@ -287,6 +325,12 @@ func loadLocalConfig(v Viper, cmdName, configDir, envPrefix string, cfg Configur
 	v.SetDefault(keyLogLevel, defaultLogLevel)
 	v.SetDefault(keyLogAddress, defaultLogAddress)
 	v.SetDefault(keyLogNetwork, defaultLogNetwork)
+	v.SetDefault(keyLogFilePath, defaultLogFilePath)
+	v.SetDefault(keyLogFileMaxSize, defaultLogFileMaxSize)
+	v.SetDefault(keyLogFileRotateInterval, defaultLogFileRotateInterval)
+	v.SetDefault(keyLogFileMaxBackups, defaultLogFileMaxBackups)
+	v.SetDefault(keyLogFileMaxAge, defaultLogFileMaxAge)
+	v.SetDefault(keyLogFileCompress, defaultLogFileCompress)
 
 	// set default config name and type
 	v.SetConfigName(defaultConfigName)
@@ -125,6 +125,12 @@ func mockViper(ctrl *gomock.Controller) *MockViper {
 	mock.EXPECT().SetDefault(keyLogLevel, defaultLogLevel)
 	mock.EXPECT().SetDefault(keyLogAddress, defaultLogAddress)
 	mock.EXPECT().SetDefault(keyLogNetwork, defaultLogNetwork)
+	mock.EXPECT().SetDefault(keyLogFilePath, defaultLogFilePath)
+	mock.EXPECT().SetDefault(keyLogFileMaxSize, defaultLogFileMaxSize)
+	mock.EXPECT().SetDefault(keyLogFileRotateInterval, defaultLogFileRotateInterval)
+	mock.EXPECT().SetDefault(keyLogFileMaxBackups, defaultLogFileMaxBackups)
+	mock.EXPECT().SetDefault(keyLogFileMaxAge, defaultLogFileMaxAge)
+	mock.EXPECT().SetDefault(keyLogFileCompress, defaultLogFileCompress)
 
 	mock.EXPECT().SetDefault("shutdown_timeout", defaultShutdownTimeout)
 
@@ -7,6 +7,9 @@ Package errutil provides helpers for error handling in Go applications.
   - JoinFnError executes an error-producing function and joins its result into an
     existing error value using errors.Join, for defer/cleanup logic where
     secondary failures must not overwrite the primary error.
+  - Stack captures the calling goroutine stack as a list of Frame values,
+    carrying the same file, line, and function metadata as Trace, e.g. to
+    report the origin of a recovered panic.
   - Errors enumerates the individual errors aggregated within an errors.Join
     value, returning a single-element slice for a plain error and nil for nil.
   - Trace(nil) returns nil, and JoinFnError supports nil and non-nil combinations
@@ -0,0 +1,58 @@
+package errutil
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Frame is a single call-stack frame, carrying the caller metadata that Trace
+// annotates errors with.
+type Frame struct {
+	// File is the source file path, as recorded by the compiler (see Trace).
+	File string `json:"file"`
+
+	// Line is the line number in File.
+	Line int `json:"line"`
+
+	// Function is the fully qualified function name.
+	Function string `json:"function"`
+}
+
+// String formats the frame like the caller metadata of Trace.
+func (f Frame) String() string {
+	return fmt.Sprintf("file: %s, line: %d, function: %s", f.File, f.Line, f.Function)
+}
+
+// Stack returns up to depth frames of the calling goroutine stack, starting
+// from the caller of Stack. skip omits that many additional frames, e.g. 1 to
+// start from the caller's caller.
+//
+// It returns nil when depth is not positive or no frame is available.
+func Stack(skip, depth int) []Frame {
+	if depth <= 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, depth)
+
+	// skip runtime.Callers and Stack itself
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := make([]Frame, 0, n)
+	iter := runtime.CallersFrames(pcs[:n])
+
+	for {
+		fr, more := iter.Next()
+
+		frames = append(frames, Frame{File: fr.File, Line: fr.Line, Function: fr.Function})
+
+		if !more {
+			break
+		}
+	}
+
+	return frames
+}
@@ -0,0 +1,38 @@
+package errutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stackTest(skip, depth int) []Frame {
+	return Stack(skip, depth)
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+
+	frames := stackTest(0, 2)
+	require.Len(t, frames, 2)
+	require.Equal(t, "github.com/tecnickcom/nurago/pkg/errutil.stackTest", frames[0].Function)
+	require.Equal(t, 10, frames[0].Line)
+	require.Contains(t, frames[0].File, "/pkg/errutil/stack_test.go")
+	require.Equal(t, "github.com/tecnickcom/nurago/pkg/errutil.TestStack", frames[1].Function)
+	require.Equal(t, 16, frames[1].Line)
+
+	frames = stackTest(1, 1)
+	require.Len(t, frames, 1)
+	require.Equal(t, "github.com/tecnickcom/nurago/pkg/errutil.TestStack", frames[0].Function)
+
+	require.Nil(t, Stack(0, 0))
+	require.Nil(t, Stack(0, -1))
+	require.Nil(t, Stack(1000, 8))
+}
+
+func TestFrame_String(t *testing.T) {
+	t.Parallel()
+
+	f := Frame{File: "/src/main.go", Line: 42, Function: "main.main"}
+	require.Equal(t, "file: /src/main.go, line: 42, function: main.main", f.String())
+}
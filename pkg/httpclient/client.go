@@ -10,9 +10,11 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	libhttputil "github.com/tecnickcom/nurago/pkg/httputil"
 	"github.com/tecnickcom/nurago/pkg/random"
 	"github.com/tecnickcom/nurago/pkg/redact"
 	"github.com/tecnickcom/nurago/pkg/traceid"
@@ -61,6 +63,7 @@ type Client struct {
 	logger            *slog.Logger
 	rnd               *random.Rnd
 	maxDumpSize       int64
+	idempotentMethods []string
 }
 
 // defaultTransport returns a private transport for a new client.
@@ -242,6 +245,8 @@ func (c *Client) Do(r *http.Request) (*http.Response, error) {
 
 	reqID, r := c.propagateTraceID(ctx, r)
 
+	c.setIdempotencyKey(r)
+
 	l = l.With(
 		slog.String(c.logPrefix+traceid.DefaultLogKey, reqID),
 		slog.Time(c.logPrefix+"request_time", reqTime),
@@ -329,6 +334,16 @@ func (c *Client) propagateTraceID(ctx context.Context, r *http.Request) (string,
 	return reqID, r
 }
 
+// setIdempotencyKey sets a fresh Idempotency-Key header on r when enabled for
+// the request method and not already set.
+func (c *Client) setIdempotencyKey(r *http.Request) {
+	if !slices.Contains(c.idempotentMethods, r.Method) || r.Header.Get(libhttputil.HeaderIdempotencyKey) != "" {
+		return
+	}
+
+	r.Header.Set(libhttputil.HeaderIdempotencyKey, c.rnd.UUIDv7().String())
+}
+
 // redactErrorForLog returns a log-safe view of err. A failed request yields a
 // *url.Error whose message embeds the full request URL; its query string and
 // userinfo are redacted here (matching how request_query is redacted) so
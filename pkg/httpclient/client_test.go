@@ -21,6 +21,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	libhttputil "github.com/tecnickcom/nurago/pkg/httputil"
 	"github.com/tecnickcom/nurago/pkg/redact"
 	"github.com/tecnickcom/nurago/pkg/traceid"
 )
@@ -1247,3 +1248,69 @@ func TestClient_Do_TimeoutFires(t *testing.T) {
 	require.Nil(t, resp)
 	require.ErrorIs(t, derr, context.DeadlineExceeded)
 }
+
+func TestClient_Do_IdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		wantSent func(t *testing.T, key string)
+	}{
+		{
+			name:   "generated",
+			method: http.MethodPost,
+			wantSent: func(t *testing.T, key string) {
+				t.Helper()
+				require.Len(t, key, 36)
+			},
+		},
+		{
+			name:   "caller key",
+			method: http.MethodPatch,
+			key:    "caller-key",
+			wantSent: func(t *testing.T, key string) {
+				t.Helper()
+				require.Equal(t, "caller-key", key)
+			},
+		},
+		{
+			name:   "other method",
+			method: http.MethodGet,
+			wantSent: func(t *testing.T, key string) {
+				t.Helper()
+				require.Empty(t, key)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent string
+
+			server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				sent = r.Header.Get(libhttputil.HeaderIdempotencyKey)
+			}))
+			t.Cleanup(server.Close)
+
+			client := New(WithIdempotencyKey())
+
+			req, err := http.NewRequestWithContext(t.Context(), tt.method, server.URL, nil)
+			require.NoError(t, err)
+
+			if tt.key != "" {
+				req.Header.Set(libhttputil.HeaderIdempotencyKey, tt.key)
+			}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			tt.wantSent(t, sent)
+			require.Equal(t, tt.key, req.Header.Get(libhttputil.HeaderIdempotencyKey), "caller's request headers must not be mutated")
+		})
+	}
+}
@@ -14,6 +14,15 @@ request headers. Timeout, logger, component tag, log field prefix, trace ID
 header name, transport, dial context, and the redaction function are configurable
 through options.
 
+# Idempotency Keys
+
+[WithIdempotencyKey] attaches a fresh Idempotency-Key header to each
+non-idempotent request (POST and PATCH by default) that has none, for servers
+using [github.com/tecnickcom/nurago/pkg/idempotency]. A key already on the
+request is kept, so the key set once by a retrier
+([github.com/tecnickcom/nurago/pkg/httpretrier.WithIdempotencyKey]) wrapping
+this client is preserved across its attempts.
+
 # Logging Behavior
 
 At debug level, request and response dumps are logged (after redaction).
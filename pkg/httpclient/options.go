@@ -204,3 +204,20 @@ func WithLogger(logger *slog.Logger) Option {
 		c.logger = logger
 	}
 }
+
+// WithIdempotencyKey makes [Client.Do] attach a fresh Idempotency-Key header (a
+// UUIDv7) to the requests with one of the given methods (default POST and
+// PATCH) that do not carry one yet. The caller's request is not modified.
+//
+// Each call of Do gets a new key, so a retry of the request through a new Do
+// call is a new operation for the server: to retry the same operation, set the
+// key on the request, or use httpretrier.WithIdempotencyKey around this client.
+func WithIdempotencyKey(methods ...string) Option {
+	return func(c *Client) {
+		if len(methods) == 0 {
+			methods = []string{http.MethodPost, http.MethodPatch}
+		}
+
+		c.idempotentMethods = methods
+	}
+}
@@ -244,3 +244,14 @@ func TestWithTLSClientConfig_AfterRoundTripperNoOp(t *testing.T) {
 	require.NotSame(t, cfg, inner.TLSClientConfig,
 		"WithTLSClientConfig after WithRoundTripper must not take effect")
 }
+
+func TestWithIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	c := defaultClient()
+	WithIdempotencyKey()(c)
+	require.Equal(t, []string{http.MethodPost, http.MethodPatch}, c.idempotentMethods)
+
+	WithIdempotencyKey(http.MethodPut)(c)
+	require.Equal(t, []string{http.MethodPut}, c.idempotentMethods)
+}
@@ -0,0 +1,109 @@
+package httpcompress
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// etagHashSize is the number of SHA-256 bytes in a generated ETag.
+const etagHashSize = 16
+
+// generateETag returns a strong ETag of body.
+func generateETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:etagHashSize]) + `"`
+}
+
+// encodedETag returns the ETag of the enc-coded representation of the
+// resource tagged etag, so caches never mix up the representations.
+func encodedETag(etag string, enc Encoding) string {
+	if enc == "" || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+
+	return etag[:len(etag)-1] + "-" + string(enc) + `"`
+}
+
+// notModified reports whether a GET or HEAD request is answered with a 304
+// Not Modified, given the ETag and Last-Modified response headers.
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatch(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatch reports whether the If-None-Match header value list matches etag
+// under the weak comparison.
+func etagMatch(list, etag string) bool {
+	want := opaqueTag(etag)
+
+	for {
+		list = strings.TrimLeft(list, " \t,")
+		if list == "" {
+			return false
+		}
+
+		if list[0] == '*' {
+			return true
+		}
+
+		tag, rest := scanETag(list)
+		if tag == "" {
+			return false
+		}
+
+		if opaqueTag(tag) == want {
+			return true
+		}
+
+		list = rest
+	}
+}
+
+// scanETag returns the entity tag at the start of s and the remainder of s,
+// or "" when s does not start with a valid tag.
+func scanETag(s string) (string, string) {
+	start := 0
+	if strings.HasPrefix(s, "W/") {
+		start = 2
+	}
+
+	if len(s)-start < 2 || s[start] != '"' {
+		return "", ""
+	}
+
+	end := strings.IndexByte(s[start+1:], '"')
+	if end < 0 {
+		return "", ""
+	}
+
+	end += start + 2
+
+	return s[:end], s[end:]
+}
+
+// opaqueTag returns etag without its weakness indicator.
+func opaqueTag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
@@ -0,0 +1,105 @@
+package httpcompress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateETag(t *testing.T) {
+	t.Parallel()
+
+	a := generateETag([]byte("a"))
+	require.Len(t, a, 24)
+	require.Equal(t, byte('"'), a[0])
+	require.Equal(t, byte('"'), a[len(a)-1])
+	require.Equal(t, a, generateETag([]byte("a")))
+	require.NotEqual(t, a, generateETag([]byte("b")))
+}
+
+func TestEncodedETag(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `"x"`, encodedETag(`"x"`, ""))
+	require.Equal(t, `"x-gzip"`, encodedETag(`"x"`, Gzip))
+	require.Equal(t, `W/"x-zstd"`, encodedETag(`W/"x"`, Zstd))
+	require.Equal(t, `bad`, encodedETag(`bad`, Gzip))
+}
+
+func TestEtagMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		list string
+		etag string
+		want bool
+	}{
+		{name: "exact", list: `"a"`, etag: `"a"`, want: true},
+		{name: "weak request", list: `W/"a"`, etag: `"a"`, want: true},
+		{name: "weak response", list: `"a"`, etag: `W/"a"`, want: true},
+		{name: "list", list: `"x", "y" ,"a"`, etag: `"a"`, want: true},
+		{name: "comma in tag", list: `"a,b"`, etag: `"a,b"`, want: true},
+		{name: "wildcard", list: `*`, etag: `"a"`, want: true},
+		{name: "mismatch", list: `"x", "y"`, etag: `"a"`},
+		{name: "unquoted", list: `a`, etag: `"a"`},
+		{name: "unterminated", list: `"a`, etag: `"a"`},
+		{name: "empty", list: ` , `, etag: `"a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, etagMatch(tt.list, tt.etag))
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+
+	const (
+		lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+		before       = "Mon, 02 Jan 2006 15:04:04 GMT"
+	)
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		etag         string
+		lastModified string
+		want         bool
+	}{
+		{name: "no conditions", etag: `"a"`, lastModified: lastModified},
+		{name: "etag match", headers: map[string]string{"If-None-Match": `"a"`}, etag: `"a"`, want: true},
+		{name: "etag mismatch", headers: map[string]string{"If-None-Match": `"b"`}, etag: `"a"`},
+		{name: "no etag", headers: map[string]string{"If-None-Match": `"a"`}},
+		{
+			name:         "if-none-match has precedence",
+			headers:      map[string]string{"If-None-Match": `"b"`, "If-Modified-Since": lastModified},
+			etag:         `"a"`,
+			lastModified: lastModified,
+		},
+		{name: "not modified since", headers: map[string]string{"If-Modified-Since": lastModified}, lastModified: lastModified, want: true},
+		{name: "modified since", headers: map[string]string{"If-Modified-Since": before}, lastModified: lastModified},
+		{name: "no last-modified", headers: map[string]string{"If-Modified-Since": lastModified}},
+		{name: "invalid since", headers: map[string]string{"If-Modified-Since": "yesterday"}, lastModified: lastModified},
+		{name: "invalid last-modified", headers: map[string]string{"If-Modified-Since": lastModified}, lastModified: "today"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			require.Equal(t, tt.want, notModified(r, tt.etag, tt.lastModified))
+		})
+	}
+}
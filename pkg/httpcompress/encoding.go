@@ -0,0 +1,172 @@
+package httpcompress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression levels.
+const (
+	// MinLevel is the fastest compression level.
+	MinLevel = 1
+
+	// MaxLevel is the smallest-output compression level.
+	MaxLevel = 9
+
+	// DefaultLevel is the default compression level.
+	DefaultLevel = 6
+)
+
+// zstdWindowSize is the maximum zstd window, as decoders are only required to
+// support up to 8 MiB (RFC 8878 §3.1.1.1.2).
+const zstdWindowSize = 8 << 20
+
+// encoder is a pooled streaming compressor.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// newEncoderPools returns a pool of encoders for each encoding.
+func newEncoderPools(encodings []Encoding, level int) (map[Encoding]*sync.Pool, error) {
+	pools := make(map[Encoding]*sync.Pool, len(encodings))
+
+	for _, enc := range encodings {
+		newFn, err := encoderFactory(enc, level)
+		if err != nil {
+			return nil, err
+		}
+
+		// Build one encoder up front so the pool never fails later.
+		first := newFn()
+		pool := &sync.Pool{New: func() any { return newFn() }}
+		pool.Put(first)
+
+		pools[enc] = pool
+	}
+
+	return pools, nil
+}
+
+// encoderFactory returns the constructor of the encoders of enc.
+func encoderFactory(enc Encoding, level int) (func() encoder, error) {
+	switch enc {
+	case Gzip:
+		return func() encoder {
+			w, _ := gzip.NewWriterLevel(io.Discard, level) //nolint:errcheck // level validated by New
+
+			return w
+		}, nil
+	case Deflate:
+		return func() encoder {
+			w, _ := zlib.NewWriterLevel(io.Discard, level) //nolint:errcheck // level validated by New
+
+			return w
+		}, nil
+	case Zstd:
+		opts := []zstd.EOption{
+			zstd.WithEncoderLevel(zstdLevel(level)),
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(zstdWindowSize),
+			zstd.WithLowerEncoderMem(true),
+		}
+
+		if _, err := zstd.NewWriter(nil, opts...); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidLevel, err)
+		}
+
+		return func() encoder {
+			w, _ := zstd.NewWriter(nil, opts...) //nolint:errcheck // options checked on construction
+
+			return w
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, enc)
+}
+
+// zstdLevel maps a compression level to the nearest zstd speed setting.
+func zstdLevel(level int) zstd.EncoderLevel {
+	switch {
+	case level <= 3:
+		return zstd.SpeedFastest
+	case level <= 6:
+		return zstd.SpeedDefault
+	case level <= 8:
+		return zstd.SpeedBetterCompression
+	}
+
+	return zstd.SpeedBestCompression
+}
+
+// negotiate returns the preferred encoding among the supported ones accepted
+// by the Accept-Encoding header, or "" for the identity coding.
+func negotiate(acceptEncoding string, supported []Encoding) Encoding {
+	if acceptEncoding == "" || len(supported) == 0 {
+		return ""
+	}
+
+	weights := parseAcceptEncoding(acceptEncoding)
+
+	var (
+		best       Encoding
+		bestWeight float64
+	)
+
+	for _, enc := range supported {
+		w, ok := weights[string(enc)]
+		if !ok {
+			w = weights["*"]
+		}
+
+		if w > bestWeight {
+			best, bestWeight = enc, w
+		}
+	}
+
+	return best
+}
+
+// parseAcceptEncoding returns the weight of each coding listed in an
+// Accept-Encoding header. A missing or invalid weight counts as 1.
+func parseAcceptEncoding(header string) map[string]float64 {
+	weights := make(map[string]float64)
+
+	for item := range strings.SplitSeq(header, ",") {
+		coding, params, _ := strings.Cut(item, ";")
+
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		if coding == "x-gzip" {
+			coding = string(Gzip)
+		}
+
+		weight := 1.0
+
+		for param := range strings.SplitSeq(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q >= 0 && q <= 1 {
+				weight = q
+			}
+		}
+
+		weights[coding] = weight
+	}
+
+	return weights
+}
@@ -0,0 +1,66 @@
+package httpcompress
+
+import (
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	all := defaultEncodings()
+
+	tests := []struct {
+		name      string
+		header    string
+		supported []Encoding
+		want      Encoding
+	}{
+		{name: "no header", header: "", supported: all, want: ""},
+		{name: "no encodings", header: "gzip", supported: nil, want: ""},
+		{name: "server preference", header: "gzip, deflate, zstd", supported: all, want: Zstd},
+		{name: "client weight", header: "zstd;q=0.5, gzip", supported: all, want: Gzip},
+		{name: "x-gzip alias", header: "x-gzip", supported: all, want: Gzip},
+		{name: "case insensitive", header: "GZIP;Q=1", supported: all, want: Gzip},
+		{name: "wildcard", header: "*", supported: all, want: Zstd},
+		{name: "wildcard exclusion", header: "*, zstd;q=0", supported: all, want: Gzip},
+		{name: "refused", header: "gzip;q=0", supported: all, want: ""},
+		{name: "unsupported", header: "br", supported: all, want: ""},
+		{name: "identity", header: "identity", supported: all, want: ""},
+		{name: "invalid weight", header: "deflate;q=x", supported: all, want: Deflate},
+		{name: "empty items", header: ", ,deflate", supported: all, want: Deflate},
+		{name: "restricted", header: "zstd, gzip", supported: []Encoding{Gzip}, want: Gzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, negotiate(tt.header, tt.supported))
+		})
+	}
+}
+
+func TestZstdLevel(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, zstd.SpeedFastest, zstdLevel(MinLevel))
+	require.Equal(t, zstd.SpeedDefault, zstdLevel(DefaultLevel))
+	require.Equal(t, zstd.SpeedBetterCompression, zstdLevel(8))
+	require.Equal(t, zstd.SpeedBestCompression, zstdLevel(MaxLevel))
+}
+
+func TestEncoderFactory(t *testing.T) {
+	t.Parallel()
+
+	for _, enc := range defaultEncodings() {
+		newFn, err := encoderFactory(enc, DefaultLevel)
+		require.NoError(t, err)
+		require.NotNil(t, newFn())
+	}
+
+	_, err := encoderFactory("br", DefaultLevel)
+	require.ErrorIs(t, err, ErrUnknownEncoding)
+}
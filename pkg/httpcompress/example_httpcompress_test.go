@@ -0,0 +1,45 @@
+package httpcompress_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httpcompress"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+)
+
+func ExampleCompressor_MiddlewareFn() {
+	comp, err := httpcompress.New(httpcompress.WithEncodings(httpcompress.Gzip))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handler := comp.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"items":[`+strings.Repeat(`"item",`, 500)+`"item"]}`)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	fmt.Println(rr.Code, rr.Header().Get("Content-Encoding"), rr.Header().Get("Vary"))
+
+	// The client revalidates with the received ETag.
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	fmt.Println(rr.Code, rr.Body.Len())
+
+	// Output:
+	// 200 gzip Accept-Encoding
+	// 304 0
+}
@@ -0,0 +1,200 @@
+/*
+Package httpcompress provides an [github.com/tecnickcom/nurago/pkg/httpserver]
+middleware that compresses the responses and answers conditional requests,
+cutting the bandwidth of the JSON APIs built on
+[github.com/tecnickcom/nurago/pkg/httputil/jsendx].
+
+A [Compressor] exposes itself as an
+[github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareFn], to be installed
+server-wide (httpserver.WithMiddlewareFn) or per route
+(httpserver.Route.Middleware):
+
+	comp, err := httpcompress.New(httpcompress.WithMinSize(512))
+	// ...
+	srv, err := httpserver.New(ctx, binder, httpserver.WithMiddlewareFn(comp.MiddlewareFn))
+
+# Compression
+
+The content coding is negotiated from the Accept-Encoding request header
+(RFC 9110 §12.5.3) among zstd (RFC 8878), gzip and deflate (zlib, RFC 1950),
+all implemented in pure Go. Ties between equally weighted codings are broken by
+the server preference set with [WithEncodings]. Only the responses of an
+allowed content type ([WithContentTypes]) and of at least the minimum size
+([WithMinSize]) are compressed; they carry a `Vary: Accept-Encoding` header
+whether the client accepted a coding or not, so shared caches keep the
+representations apart. Responses already encoded by the handler, partial
+(206) responses and responses without a body are passed through unchanged.
+
+# Conditional Requests
+
+The successful (200) responses to GET and HEAD requests get a strong ETag,
+computed from the uncompressed body unless the handler set one, and suffixed
+with the content coding so each representation has its own tag. A request
+whose If-None-Match matches the tag (weak comparison, RFC 9110 §13.1.2), or,
+in its absence, whose If-Modified-Since is not earlier than the Last-Modified
+header set by the handler (§13.1.3), is answered with 304 Not Modified and no
+body.
+
+A bodiless response to a HEAD request is coded, tagged (with the ETag set by
+the handler) and given a Vary header like the GET response it mirrors, its
+size being taken from the Content-Length set by the handler.
+
+# Buffering
+
+The response is buffered up to a limit ([WithMaxBufferSize]), so the body can
+be hashed and compressed at once, with an exact Content-Length. A larger
+response, or one the handler flushes, is streamed: it is compressed on the fly
+and gets no automatic ETag.
+
+# Logging
+
+The middleware writes the response through an
+[github.com/tecnickcom/nurago/pkg/httputil.ResponseWriterWrapper], reusing the
+one it receives, such as the writer of the httpserver logger middleware, which
+always runs outside the route and server-wide middleware. Its own writer
+implements that interface too, reporting the status and size sent to the
+client (e.g. 304 and the compressed size) rather than the ones set by the
+handler.
+*/
+package httpcompress
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+)
+
+// Encoding is an HTTP content coding.
+type Encoding string
+
+// Supported content codings.
+const (
+	Zstd    Encoding = "zstd"
+	Gzip    Encoding = "gzip"
+	Deflate Encoding = "deflate"
+)
+
+const (
+	// DefaultMinSize is the default minimum size of a compressed response body.
+	DefaultMinSize = 1024
+
+	// DefaultMaxBufferSize is the default size beyond which a response is streamed.
+	DefaultMaxBufferSize = 1 << 20
+)
+
+var (
+	// ErrUnknownEncoding is returned by [New] for an unsupported content coding.
+	ErrUnknownEncoding = errors.New("httpcompress: unknown encoding")
+
+	// ErrInvalidLevel is returned by [New] for an invalid compression level.
+	ErrInvalidLevel = errors.New("httpcompress: invalid compression level")
+
+	// ErrInvalidSize is returned by [New] for a negative minimum size or a
+	// buffer size smaller than the minimum size.
+	ErrInvalidSize = errors.New("httpcompress: invalid size")
+)
+
+// defaultEncodings returns the default content codings, in order of preference.
+func defaultEncodings() []Encoding {
+	return []Encoding{Zstd, Gzip, Deflate}
+}
+
+// defaultContentTypes returns the default compressible media types.
+func defaultContentTypes() []string {
+	return []string{
+		"text/*",
+		"application/json",
+		"application/problem+json",
+		"application/ld+json",
+		"application/x-ndjson",
+		"application/javascript",
+		"application/xml",
+		"application/wasm",
+		"image/svg+xml",
+	}
+}
+
+// Compressor compresses HTTP responses and answers conditional requests.
+type Compressor struct {
+	encodings     []Encoding
+	level         int
+	contentTypes  []string
+	minSize       int
+	maxBufferSize int
+	etag          bool
+	pools         map[Encoding]*sync.Pool
+}
+
+// New constructs a Compressor.
+func New(opts ...Option) (*Compressor, error) {
+	c := &Compressor{
+		encodings:     defaultEncodings(),
+		level:         DefaultLevel,
+		contentTypes:  defaultContentTypes(),
+		minSize:       DefaultMinSize,
+		maxBufferSize: DefaultMaxBufferSize,
+		etag:          true,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(c)
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	types := make([]string, 0, len(c.contentTypes))
+	for _, ct := range c.contentTypes {
+		types = append(types, strings.ToLower(strings.TrimSpace(ct)))
+	}
+
+	c.contentTypes = types
+	c.encodings = slices.Clone(c.encodings)
+
+	pools, err := newEncoderPools(c.encodings, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	c.pools = pools
+
+	return c, nil
+}
+
+// validate checks the configuration.
+func (c *Compressor) validate() error {
+	for _, enc := range c.encodings {
+		if !slices.Contains(defaultEncodings(), enc) {
+			return fmt.Errorf("%w: %q", ErrUnknownEncoding, enc)
+		}
+	}
+
+	if c.level < MinLevel || c.level > MaxLevel {
+		return fmt.Errorf("%w: %d", ErrInvalidLevel, c.level)
+	}
+
+	if c.minSize < 0 || c.maxBufferSize < c.minSize {
+		return fmt.Errorf("%w: min %d, buffer %d", ErrInvalidSize, c.minSize, c.maxBufferSize)
+	}
+
+	return nil
+}
+
+// MiddlewareFn implements [httpserver.MiddlewareFn], compressing the
+// responses of next and answering the conditional requests.
+func (c *Compressor) MiddlewareFn(_ httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := c.newResponseWriter(w, r)
+		defer rw.release()
+
+		next.ServeHTTP(rw, r)
+
+		rw.finish()
+	})
+}
@@ -0,0 +1,456 @@
+package httpcompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+)
+
+var testBody = strings.Repeat(`{"status":"success","data":"compressible payload"}`, 100)
+
+func decode(t *testing.T, enc string, body []byte) string {
+	t.Helper()
+
+	var (
+		r   io.Reader
+		err error
+	)
+
+	switch enc {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	case "zstd":
+		r, err = zstd.NewReader(bytes.NewReader(body))
+	default:
+		return string(body)
+	}
+
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(out)
+}
+
+func serve(t *testing.T, c *Compressor, h http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	c.MiddlewareFn(httpserver.MiddlewareArgs{}, h).ServeHTTP(rr, r)
+
+	return rr
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "defaults"},
+		{name: "no encodings", opts: []Option{WithEncodings()}},
+		{name: "unknown encoding", opts: []Option{WithEncodings("br")}, wantErr: ErrUnknownEncoding},
+		{name: "low level", opts: []Option{WithLevel(0)}, wantErr: ErrInvalidLevel},
+		{name: "high level", opts: []Option{WithLevel(10)}, wantErr: ErrInvalidLevel},
+		{name: "negative min size", opts: []Option{WithMinSize(-1)}, wantErr: ErrInvalidSize},
+		{name: "small buffer", opts: []Option{WithMinSize(10), WithMaxBufferSize(9)}, wantErr: ErrInvalidSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, c)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, c.pools, len(c.encodings))
+		})
+	}
+}
+
+func TestNew_contentTypes(t *testing.T) {
+	t.Parallel()
+
+	types := []string{" Text/HTML "}
+
+	c, err := New(WithContentTypes(types...))
+	require.NoError(t, err)
+	require.Equal(t, []string{"text/html"}, c.contentTypes)
+	require.Equal(t, " Text/HTML ", types[0], "the caller slice must not be modified")
+}
+
+func TestCompressor_MiddlewareFn_encodings(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	for _, enc := range []string{"zstd", "gzip", "deflate", "identity"} {
+		t.Run(enc, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Accept-Encoding", enc)
+
+			rr := serve(t, c, jsonHandler(testBody), r)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+			require.Empty(t, rr.Header().Get("ETag"), "only GET and HEAD responses are tagged")
+			require.Equal(t, strconv.Itoa(rr.Body.Len()), rr.Header().Get("Content-Length"))
+			require.Equal(t, testBody, decode(t, enc, rr.Body.Bytes()))
+
+			if enc == "identity" {
+				require.Empty(t, rr.Header().Get("Content-Encoding"))
+
+				return
+			}
+
+			require.Equal(t, enc, rr.Header().Get("Content-Encoding"))
+			require.Less(t, rr.Body.Len(), len(testBody))
+		})
+	}
+}
+
+func TestCompressor_MiddlewareFn_skip(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantVary  bool
+		wantBody  string
+		wantCType string
+	}{
+		{
+			name:      "small",
+			handler:   jsonHandler(`{"a":1}`),
+			wantBody:  `{"a":1}`,
+			wantCType: "application/json; charset=utf-8",
+		},
+		{
+			name: "not compressible",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				_, _ = io.WriteString(w, testBody)
+			},
+			wantBody:  testBody,
+			wantCType: "image/png",
+		},
+		{
+			name: "already encoded",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Encoding", "br")
+				_, _ = io.WriteString(w, testBody)
+			},
+			wantBody:  testBody,
+			wantCType: "text/plain",
+		},
+		{
+			name: "partial",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = io.WriteString(w, testBody)
+			},
+			wantBody:  testBody,
+			wantCType: "text/plain",
+		},
+		{
+			name:      "sniffed binary",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(bytes.Repeat([]byte{0}, 2048)) },
+			wantBody:  string(bytes.Repeat([]byte{0}, 2048)),
+			wantCType: "application/octet-stream",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+
+			rr := serve(t, c, tt.handler, r)
+
+			require.Empty(t, rr.Header().Get("Vary"))
+			require.NotEqual(t, "gzip", rr.Header().Get("Content-Encoding"))
+			require.Equal(t, tt.wantCType, rr.Header().Get("Content-Type"))
+			require.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestCompressor_MiddlewareFn_errorStatus(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	rr := serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, testBody)
+	}, r)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	require.Empty(t, rr.Header().Get("ETag"), "only 200 responses are tagged")
+	require.Equal(t, testBody, decode(t, "gzip", rr.Body.Bytes()))
+}
+
+func TestCompressor_MiddlewareFn_conditional(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	get := func(method, acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		return serve(t, c, jsonHandler(testBody), r)
+	}
+
+	plain := get(http.MethodGet, "", "")
+	etag := plain.Header().Get("ETag")
+	require.Equal(t, generateETag([]byte(testBody)), etag)
+
+	gz := get(http.MethodGet, "gzip", "")
+	gzETag := gz.Header().Get("ETag")
+	require.Equal(t, encodedETag(etag, Gzip), gzETag)
+
+	rr := get(http.MethodGet, "gzip", gzETag)
+	require.Equal(t, http.StatusNotModified, rr.Code)
+	require.Empty(t, rr.Body.String())
+	require.Equal(t, gzETag, rr.Header().Get("ETag"))
+	require.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	require.Empty(t, rr.Header().Get("Content-Type"))
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Empty(t, rr.Header().Get("Content-Length"))
+
+	rr = get(http.MethodGet, "", gzETag)
+	require.Equal(t, http.StatusOK, rr.Code, "a tag of another representation must not match")
+
+	rr = get(http.MethodHead, "", etag)
+	require.Equal(t, http.StatusNotModified, rr.Code)
+}
+
+func TestCompressor_MiddlewareFn_handlerValidators(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(WithETag(false))
+	require.NoError(t, err)
+
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = io.WriteString(w, testBody)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	rr := serve(t, c, handler, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("ETag"))
+
+	r.Header.Set("If-Modified-Since", lastModified)
+	rr = serve(t, c, handler, r)
+	require.Equal(t, http.StatusNotModified, rr.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "deflate")
+	r.Header.Set("If-None-Match", `W/"v1-deflate"`)
+
+	rr = serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `W/"v1"`)
+		_, _ = io.WriteString(w, testBody)
+	}, r)
+	require.Equal(t, http.StatusNotModified, rr.Code)
+	require.Equal(t, `W/"v1-deflate"`, rr.Header().Get("ETag"))
+}
+
+func TestCompressor_MiddlewareFn_empty(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	rr := serve(t, c, func(_ http.ResponseWriter, _ *http.Request) {}, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("ETag"))
+
+	rr = serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, generateETag(nil), rr.Header().Get("ETag"))
+
+	rr = serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "42")
+		w.WriteHeader(http.StatusOK)
+	}, httptest.NewRequest(http.MethodHead, "/", nil))
+	require.Empty(t, rr.Header().Get("ETag"), "a bodiless HEAD response cannot be tagged")
+	require.Equal(t, "42", rr.Header().Get("Content-Length"))
+
+	rr = serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestCompressor_MiddlewareFn_logging(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	handler := httpserver.ApplyMiddleware(
+		httpserver.MiddlewareArgs{Logger: logger},
+		jsonHandler(testBody),
+		httpserver.LoggerMiddlewareFn,
+		c.MiddlewareFn,
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	require.Contains(t, logs.String(), `"response_code":200`)
+	require.Contains(t, logs.String(), `"response_size":`+strconv.Itoa(rr.Body.Len())+`}`)
+
+	logs.Reset()
+
+	r.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	require.Contains(t, logs.String(), `"response_code":304`)
+	require.Contains(t, logs.String(), `"response_size":0}`)
+}
+
+func TestCompressor_MiddlewareFn_wrapper(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	rr := httptest.NewRecorder()
+	out := httputil.NewResponseWriterWrapper(rr)
+
+	var (
+		inner httputil.ResponseWriterWrapper
+		tee   bytes.Buffer
+	)
+
+	c.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+
+		inner, ok = w.(httputil.ResponseWriterWrapper)
+		require.True(t, ok)
+
+		inner.Tee(&tee)
+		jsonHandler(testBody)(w, r)
+
+		require.Zero(t, inner.Status(), "nothing is sent while buffering")
+	})).ServeHTTP(out, r)
+
+	require.Equal(t, http.StatusOK, out.Status())
+	require.Equal(t, rr.Body.Len(), out.Size())
+	require.Less(t, out.Size(), len(testBody))
+	require.Equal(t, out.Status(), inner.Status())
+	require.Equal(t, out.Size(), inner.Size())
+	require.Equal(t, rr.Body.Bytes(), tee.Bytes(), "the tee gets the bytes sent")
+}
+
+func TestCompressor_MiddlewareFn_head(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Type", "application/json")
+		h.Set("Content-Length", strconv.Itoa(len(testBody)))
+		h.Set("ETag", `"v1"`)
+
+		if r.Method == http.MethodGet {
+			_, _ = io.WriteString(w, testBody)
+		}
+	}
+
+	send := func(method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+
+		return serve(t, c, handler, r)
+	}
+
+	get := send(http.MethodGet)
+	head := send(http.MethodHead)
+
+	require.Equal(t, http.StatusOK, head.Code)
+	require.Empty(t, head.Body.String())
+
+	for _, k := range []string{"Content-Type", "Content-Encoding", "ETag", "Vary"} {
+		require.NotEmpty(t, get.Header().Get(k), k)
+		require.Equal(t, get.Header().Values(k), head.Header().Values(k), k)
+	}
+
+	require.Empty(t, head.Header().Get("Content-Length"), "the coded length is unknown")
+
+	r := httptest.NewRequest(http.MethodHead, "/", nil)
+	r.Header.Set("If-None-Match", get.Header().Get("ETag"))
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	rr := serve(t, c, handler, r)
+	require.Equal(t, http.StatusNotModified, rr.Code)
+}
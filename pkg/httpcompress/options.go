@@ -0,0 +1,59 @@
+package httpcompress
+
+// Option is a type to allow setting custom compressor options.
+type Option func(*Compressor)
+
+// WithEncodings sets the supported content codings, in order of preference
+// (default: zstd, gzip, deflate). No encoding disables the compression, leaving
+// only the conditional-request handling.
+func WithEncodings(encodings ...Encoding) Option {
+	return func(c *Compressor) {
+		c.encodings = encodings
+	}
+}
+
+// WithLevel sets the compression level, from [MinLevel] (fastest) to
+// [MaxLevel] (smallest), [DefaultLevel] being a balance of the two. The level
+// is mapped to the nearest zstd speed setting.
+func WithLevel(level int) Option {
+	return func(c *Compressor) {
+		c.level = level
+	}
+}
+
+// WithContentTypes sets the media types eligible for compression. An entry
+// is either an exact media type (e.g. "application/json") or a type wildcard
+// (e.g. "text/*"). Already compressed formats (images, archives) gain nothing
+// and should not be listed.
+func WithContentTypes(types ...string) Option {
+	return func(c *Compressor) {
+		c.contentTypes = types
+	}
+}
+
+// WithMinSize sets the minimum size in bytes of a compressed response body
+// (default [DefaultMinSize]). Smaller bodies are sent as they are, as the
+// compression would barely shrink them.
+func WithMinSize(size int) Option {
+	return func(c *Compressor) {
+		c.minSize = size
+	}
+}
+
+// WithMaxBufferSize sets the size in bytes up to which a response is buffered
+// (default [DefaultMaxBufferSize]). Larger responses are streamed, without an
+// automatic ETag. It must not be smaller than the minimum size.
+func WithMaxBufferSize(size int) Option {
+	return func(c *Compressor) {
+		c.maxBufferSize = size
+	}
+}
+
+// WithETag enables (default) or disables the automatic ETag generation. The
+// conditional requests are still answered from the ETag and Last-Modified
+// headers set by the handlers.
+func WithETag(enabled bool) Option {
+	return func(c *Compressor) {
+		c.etag = enabled
+	}
+}
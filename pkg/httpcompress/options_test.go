@@ -0,0 +1,55 @@
+package httpcompress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEncodings(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{}
+	WithEncodings(Gzip)(c)
+	require.Equal(t, []Encoding{Gzip}, c.encodings)
+}
+
+func TestWithLevel(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{}
+	WithLevel(MaxLevel)(c)
+	require.Equal(t, MaxLevel, c.level)
+}
+
+func TestWithContentTypes(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{}
+	WithContentTypes("text/csv")(c)
+	require.Equal(t, []string{"text/csv"}, c.contentTypes)
+}
+
+func TestWithMinSize(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{}
+	WithMinSize(256)(c)
+	require.Equal(t, 256, c.minSize)
+}
+
+func TestWithMaxBufferSize(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{}
+	WithMaxBufferSize(4096)(c)
+	require.Equal(t, 4096, c.maxBufferSize)
+}
+
+func TestWithETag(t *testing.T) {
+	t.Parallel()
+
+	c := &Compressor{etag: true}
+	WithETag(false)(c)
+	require.False(t, c.etag)
+}
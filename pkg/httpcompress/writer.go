@@ -0,0 +1,408 @@
+package httpcompress
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+)
+
+// maxPooledBufferSize is the capacity beyond which a buffer is not pooled,
+// so an occasional large response does not pin its memory.
+const maxPooledBufferSize = 64 << 10
+
+// bufferPool holds the response buffers.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// responseWriter buffers, compresses and conditionally answers a response.
+// It writes downstream through an [httputil.ResponseWriterWrapper], whose
+// status and size it reports as its own.
+type responseWriter struct {
+	http.ResponseWriter
+
+	out      httputil.ResponseWriterWrapper
+	c        *Compressor
+	r        *http.Request
+	buf      *bytes.Buffer
+	status   int
+	headed   bool // the handler has set the status
+	started  bool // the header has been sent downstream
+	hijacked bool
+	encoding Encoding
+	enc      encoder
+	err      error
+}
+
+// newResponseWriter wraps w for the request r. A w that already tracks the
+// status and size (e.g. the one of the httpserver logger) is written to
+// directly.
+func (c *Compressor) newResponseWriter(w http.ResponseWriter, r *http.Request) *responseWriter {
+	buf, _ := bufferPool.Get().(*bytes.Buffer) //nolint:errcheck // the pool only holds buffers
+	buf.Reset()
+
+	out, ok := w.(httputil.ResponseWriterWrapper)
+	if !ok {
+		out = httputil.NewResponseWriterWrapper(w)
+	}
+
+	return &responseWriter{
+		ResponseWriter: out,
+		out:            out,
+		c:              c,
+		r:              r,
+		buf:            buf,
+	}
+}
+
+// WriteHeader records the status code of the response. Informational (1xx)
+// statuses other than 101 are forwarded at once, as they do not conclude the
+// header exchange.
+func (w *responseWriter) WriteHeader(code int) {
+	if w.headed || w.hijacked {
+		return
+	}
+
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	w.status = code
+	w.headed = true
+
+	if w.passThrough() {
+		w.start("")
+	}
+}
+
+// Write buffers the response body, or streams it once the buffer is full.
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+
+	if !w.headed {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.started {
+		return w.writeBody(p)
+	}
+
+	w.buf.Write(p)
+
+	if w.buf.Len() > w.c.maxBufferSize {
+		w.stream()
+	}
+
+	return len(p), w.err
+}
+
+// Flush streams the response written so far, implementing [http.Flusher].
+func (w *responseWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+
+	if !w.headed {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.started {
+		w.stream()
+	}
+
+	if w.enc != nil {
+		if err := w.enc.Flush(); err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements the [http.Hijacker] interface.
+//
+//nolint:wrapcheck
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the Hijacker is not supported by the ResponseWriter")
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for [http.ResponseController].
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the status code sent to the client, implementing
+// [httputil.ResponseWriterWrapper]: it is 0 until the response is sent
+// downstream, and 304 for a request answered as not modified.
+func (w *responseWriter) Status() int {
+	return w.out.Status()
+}
+
+// Size returns the number of (compressed) bytes sent to the client,
+// implementing [httputil.ResponseWriterWrapper].
+func (w *responseWriter) Size() int {
+	return w.out.Size()
+}
+
+// Tee sets a writer that receives a copy of the bytes sent to the client,
+// compressed if the response is, implementing [httputil.ResponseWriterWrapper].
+func (w *responseWriter) Tee(tw io.Writer) {
+	w.out.Tee(tw)
+}
+
+// passThrough reports whether the response must be sent unchanged.
+func (w *responseWriter) passThrough() bool {
+	switch w.status {
+	case http.StatusSwitchingProtocols, http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return true
+	}
+
+	h := w.Header()
+
+	ce := h.Get("Content-Encoding")
+
+	return (ce != "" && !strings.EqualFold(ce, "identity")) || h.Get("Content-Range") != ""
+}
+
+// choose returns the encoding of a response starting with body, setting the
+// sniffed Content-Type and the Vary header of a compressible response. The
+// size of a bodiless HEAD response is taken from its Content-Length, so it is
+// coded like the GET response it mirrors.
+func (w *responseWriter) choose(body []byte) Encoding {
+	h := w.Header()
+
+	// Sniff the type as net/http would, before the body gets compressed.
+	if _, ok := h["Content-Type"]; !ok && len(body) > 0 {
+		h.Set("Content-Type", http.DetectContentType(body))
+	}
+
+	size := len(body)
+	if size == 0 && w.r.Method == http.MethodHead {
+		size, _ = strconv.Atoi(h.Get("Content-Length")) //nolint:errcheck // an invalid length counts as empty
+	}
+
+	if len(w.c.encodings) == 0 || size < w.c.minSize || !w.c.compressible(h.Get("Content-Type")) {
+		return ""
+	}
+
+	addVary(h)
+
+	return negotiate(w.r.Header.Get("Accept-Encoding"), w.c.encodings)
+}
+
+// stream sends the header and the buffered body downstream, and makes the
+// next writes go straight through the encoder.
+func (w *responseWriter) stream() {
+	body := w.buf.Bytes()
+
+	w.start(w.choose(body))
+	_, _ = w.writeBody(body)
+
+	w.buf.Reset()
+}
+
+// start sends the header downstream for a body coded with enc.
+func (w *responseWriter) start(enc Encoding) {
+	w.started = true
+
+	if enc != "" {
+		h := w.Header()
+		h.Set("Content-Encoding", string(enc))
+		h.Del("Content-Length")
+
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", encodedETag(etag, enc))
+		}
+
+		w.encoding = enc
+		w.enc, _ = w.c.pools[enc].Get().(encoder) //nolint:errcheck // the pools only hold encoders
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// writeBody writes p downstream, through the encoder if any.
+func (w *responseWriter) writeBody(p []byte) (int, error) {
+	var (
+		n   int
+		err error
+	)
+
+	if w.enc != nil {
+		n, err = w.enc.Write(p)
+	} else {
+		n, err = w.ResponseWriter.Write(p)
+	}
+
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+
+	return n, err //nolint:wrapcheck // passed through from the underlying writer
+}
+
+// finish completes the response once the handler has returned.
+func (w *responseWriter) finish() {
+	if w.hijacked {
+		return
+	}
+
+	if w.started {
+		if w.enc != nil {
+			_ = w.enc.Close()
+		}
+
+		return
+	}
+
+	if !w.headed {
+		if w.r.Method != http.MethodHead {
+			// Nothing written: let net/http send its implicit empty 200.
+			return
+		}
+
+		// The headers set for a HEAD request still mirror the GET response.
+		w.status = http.StatusOK
+		w.headed = true
+	}
+
+	body := w.buf.Bytes()
+	h := w.Header()
+	enc := w.choose(body)
+
+	if w.status == http.StatusOK && (w.r.Method == http.MethodGet || w.r.Method == http.MethodHead) {
+		etag := h.Get("ETag")
+		if etag == "" && w.c.etag && (len(body) > 0 || w.r.Method == http.MethodGet) {
+			etag = generateETag(body)
+		}
+
+		if etag != "" {
+			etag = encodedETag(etag, enc)
+			h.Set("ETag", etag)
+		}
+
+		if notModified(w.r, etag, h.Get("Last-Modified")) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			h.Del("Content-Encoding")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+	}
+
+	switch {
+	case enc != "" && len(body) == 0:
+		// A bodiless HEAD response: the coded length is unknown.
+		h.Set("Content-Encoding", string(enc))
+		h.Del("Content-Length")
+	case enc != "":
+		body = w.compress(enc, body)
+		h.Set("Content-Encoding", string(enc))
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+	case h.Get("Content-Length") == "" && len(body) > 0:
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	_, _ = w.writeBody(body)
+}
+
+// compress returns body coded with enc. The result is held by the buffer of
+// the writer, which must not be used afterwards.
+func (w *responseWriter) compress(enc Encoding, body []byte) []byte {
+	out, _ := bufferPool.Get().(*bytes.Buffer) //nolint:errcheck // the pool only holds buffers
+	out.Reset()
+
+	e, _ := w.c.pools[enc].Get().(encoder) //nolint:errcheck // the pools only hold encoders
+	e.Reset(out)
+	_, _ = e.Write(body) // writes to a bytes.Buffer cannot fail
+	_ = e.Close()
+	w.c.pools[enc].Put(e)
+
+	// Swap the buffers so both return to the pool.
+	putBuffer(w.buf)
+	w.buf = out
+
+	return out.Bytes()
+}
+
+// release returns the buffer and the encoder to their pools.
+func (w *responseWriter) release() {
+	putBuffer(w.buf)
+
+	if w.enc != nil {
+		w.c.pools[w.encoding].Put(w.enc)
+	}
+}
+
+// putBuffer returns buf to the pool, unless it grew too large.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
+
+// compressible reports whether a response of the given Content-Type may be compressed.
+func (c *Compressor) compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	if mediaType == "" {
+		return false
+	}
+
+	for _, allowed := range c.contentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+
+			continue
+		}
+
+		if mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addVary adds Accept-Encoding to the Vary header, unless already covered.
+func addVary(h http.Header) {
+	for _, v := range h.Values("Vary") {
+		for token := range strings.SplitSeq(v, ",") {
+			token = strings.TrimSpace(token)
+			if token == "*" || strings.EqualFold(token, "Accept-Encoding") {
+				return
+			}
+		}
+	}
+
+	h.Add("Vary", "Accept-Encoding")
+}
@@ -0,0 +1,218 @@
+package httpcompress
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+
+	err error
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, h.err
+}
+
+type statusRecorder struct {
+	*httptest.ResponseRecorder
+
+	codes []int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.codes = append(s.codes, code)
+}
+
+func TestResponseWriter_stream(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(WithMinSize(10), WithMaxBufferSize(100))
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+
+	rr := serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", "9999")
+		w.Header().Set("ETag", `"v2"`)
+
+		for range 10 {
+			_, err := io.WriteString(w, strings.Repeat("x", 50))
+			require.NoError(t, err)
+		}
+	}, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	require.Empty(t, rr.Header().Get("Content-Length"))
+	require.Equal(t, `"v2-gzip"`, rr.Header().Get("ETag"))
+	require.Equal(t, strings.Repeat("x", 500), decode(t, "gzip", rr.Body.Bytes()))
+}
+
+func TestResponseWriter_Flush(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(WithMinSize(4))
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "deflate")
+
+	rr := serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+
+		f, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		f.Flush()
+		_, _ = io.WriteString(w, "hello")
+		f.Flush()
+		_, _ = io.WriteString(w, " world")
+	}, r)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, rr.Flushed)
+	require.Empty(t, rr.Header().Get("Content-Encoding"), "the body was empty when the response was committed")
+	require.Empty(t, rr.Header().Get("ETag"))
+	require.Equal(t, "hello world", rr.Body.String())
+
+	rr = serve(t, c, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "hello")
+		http.NewResponseController(w).Flush() //nolint:errcheck
+		_, _ = io.WriteString(w, " world")
+	}, r)
+
+	require.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	require.Equal(t, "hello world", decode(t, "deflate", rr.Body.Bytes()))
+}
+
+func TestResponseWriter_informational(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	rec := &statusRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := c.newResponseWriter(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	defer w.release()
+
+	w.WriteHeader(http.StatusEarlyHints)
+	require.False(t, w.headed)
+
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	require.Equal(t, http.StatusCreated, w.status)
+
+	w.finish()
+	require.Equal(t, []int{http.StatusEarlyHints, http.StatusCreated}, rec.codes)
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	w := c.newResponseWriter(httptest.NewRecorder(), r)
+	_, _, err = w.Hijack()
+	require.Error(t, err)
+	w.release()
+
+	hr := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), err: errors.New("fail")}
+	w = c.newResponseWriter(hr, r)
+	_, _, err = w.Hijack()
+	require.Error(t, err)
+	require.False(t, w.hijacked)
+	w.release()
+
+	hr = &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w = c.newResponseWriter(hr, r)
+
+	defer w.release()
+
+	_, _, err = w.Hijack()
+	require.NoError(t, err)
+	require.True(t, w.hijacked)
+
+	_, err = w.Write([]byte("x"))
+	require.ErrorIs(t, err, http.ErrHijacked)
+
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+	w.finish()
+	require.False(t, hr.Flushed)
+	require.Empty(t, hr.Body.String())
+}
+
+func TestResponseWriter_Unwrap(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	w := c.newResponseWriter(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	defer w.release()
+
+	out, ok := w.Unwrap().(interface{ Unwrap() http.ResponseWriter })
+	require.True(t, ok)
+	require.Equal(t, rec, out.Unwrap())
+
+	ww := httputil.NewResponseWriterWrapper(rec)
+	w2 := c.newResponseWriter(ww, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	defer w2.release()
+
+	require.Equal(t, ww, w2.Unwrap(), "a wrapper is written to directly")
+}
+
+func TestCompressor_compressible(t *testing.T) {
+	t.Parallel()
+
+	c, err := New()
+	require.NoError(t, err)
+
+	require.True(t, c.compressible("text/html; charset=utf-8"))
+	require.True(t, c.compressible("Application/JSON"))
+	require.True(t, c.compressible("image/svg+xml"))
+	require.False(t, c.compressible("image/png"))
+	require.False(t, c.compressible("application/jsonx"))
+	require.False(t, c.compressible(""))
+}
+
+func TestAddVary(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	addVary(h)
+	require.Equal(t, []string{"Accept-Encoding"}, h.Values("Vary"))
+
+	h = http.Header{"Vary": {"Origin, accept-encoding"}}
+	addVary(h)
+	require.Equal(t, []string{"Origin, accept-encoding"}, h.Values("Vary"))
+
+	h = http.Header{"Vary": {"*"}}
+	addVary(h)
+	require.Equal(t, []string{"*"}, h.Values("Vary"))
+
+	h = http.Header{"Vary": {"Origin"}}
+	addVary(h)
+	require.Equal(t, []string{"Origin", "Accept-Encoding"}, h.Values("Vary"))
+}
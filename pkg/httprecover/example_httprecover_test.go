@@ -0,0 +1,56 @@
+package httprecover_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httprecover"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/slack"
+)
+
+func ExampleRecoverer_MiddlewareFn() {
+	rec := httprecover.New(
+		httprecover.WithLogger(slog.New(slog.DiscardHandler)),
+		httprecover.WithAppInfo(&jsendx.AppInfo{ProgramName: "example"}),
+	)
+
+	args := httpserver.MiddlewareArgs{Method: http.MethodGet, Path: "/orders"}
+
+	handler := rec.MiddlewareFn(args, http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		panic("unexpected state")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	fmt.Println(rr.Code)
+
+	// Output:
+	// 500
+}
+
+//nolint:testableexamples
+func ExampleWithNotifyFunc() {
+	sc, err := slack.New("https://hooks.slack.com/services/T000/B000/XXXX", "alerts", ":fire:", "", "#incidents")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rec := httprecover.New(
+		httprecover.WithNotifyFunc(func(ctx context.Context, report *httprecover.Report) {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			_ = sc.Send(ctx, report.String(), "", "", "", "")
+		}),
+	)
+
+	_ = rec
+}
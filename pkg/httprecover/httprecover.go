@@ -0,0 +1,291 @@
+/*
+Package httprecover provides an [github.com/tecnickcom/nurago/pkg/httpserver]
+middleware that recovers the panics of the HTTP handlers and reports them
+through the service logging, metrics and alerting pipeline.
+
+Without it a panicking handler is caught by net/http (or by the router panic
+handler), far from the request context. A [Recoverer] instead recovers the
+panic next to the handler and:
+
+  - captures the panic value and the stack of the panicking goroutine as
+    [github.com/tecnickcom/nurago/pkg/errutil.Frame] values, starting at the
+    panic site;
+  - logs them at error level with the request trace ID, method and path;
+  - increments the metrics error counter
+    IncErrorCounter("panic", route, "500"), where route is the route path
+    pattern;
+  - answers with a 500 Internal Server Error JSendX envelope
+    ([github.com/tecnickcom/nurago/pkg/httputil/jsendx]), or with a custom
+    response handler ([WithResponseHandler]);
+  - optionally forwards a [Report] to a notifier, e.g. a
+    [github.com/tecnickcom/nurago/pkg/slack.Client] ([WithNotifyFunc]).
+
+The Recoverer exposes itself as an
+[github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareFn]. Install it first
+among the server-wide middleware, so it also covers the ones after it:
+
+	rec := httprecover.New(
+		httprecover.WithAppInfo(appInfo),
+		httprecover.WithMetrics(metricsClient),
+	)
+	srv, err := httpserver.New(ctx, binder, httpserver.WithMiddlewareFn(rec.MiddlewareFn, instrumentFn))
+
+A panic carrying http.ErrAbortHandler is re-raised untouched, so net/http
+aborts the response silently as documented. When the handler panics after
+the response header has been sent, a well-formed error response can no longer
+be written: the panic is still logged, counted and notified, then the response
+is aborted with http.ErrAbortHandler so the client sees a truncated reply
+rather than a seemingly successful one.
+*/
+package httprecover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/errutil"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+const (
+	// DefaultStackDepth is the default maximum number of stack frames reported.
+	DefaultStackDepth = 32
+
+	// metricsTask is the task label of the panic error counter.
+	metricsTask = "panic"
+
+	// metricsCode is the error code label of the panic error counter.
+	metricsCode = "500"
+
+	// runtimeFrames is the number of extra frames captured to make room for the
+	// recovery and runtime frames trimmed from the stack.
+	runtimeFrames = 8
+
+	// reportFrames is the number of frames included in [Report.String].
+	reportFrames = 10
+)
+
+// Report describes a recovered panic.
+type Report struct {
+	// Time is when the panic was recovered.
+	Time time.Time `json:"time"`
+
+	// Value is the value passed to panic.
+	Value any `json:"-"`
+
+	// Message is the string representation of Value.
+	Message string `json:"message"`
+
+	// Frames is the stack of the panicking goroutine, starting at the panic site.
+	Frames []errutil.Frame `json:"frames"`
+
+	// TraceID is the request trace ID, if any.
+	TraceID string `json:"trace_id"`
+
+	// Method is the request method.
+	Method string `json:"method"`
+
+	// Path is the request URL path.
+	Path string `json:"path"`
+
+	// Route is the path pattern of the route that panicked.
+	Route string `json:"route"`
+}
+
+// String returns a plain-text summary of the report, with the top stack
+// frames, suitable for chat notifications.
+func (rp *Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "panic: %s\n", rp.Message)
+	fmt.Fprintf(&sb, "request: %s %s (route: %s)\n", rp.Method, rp.Path, rp.Route)
+	fmt.Fprintf(&sb, "trace_id: %s\n", rp.TraceID)
+	fmt.Fprintf(&sb, "time: %s\n", rp.Time.Format(time.RFC3339))
+
+	for i, f := range rp.Frames {
+		if i == reportFrames {
+			fmt.Fprintf(&sb, "... %d more frames\n", len(rp.Frames)-reportFrames)
+
+			break
+		}
+
+		sb.WriteString(f.String())
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// NotifyFunc forwards a panic report to an external notifier.
+//
+// It is called in a separate goroutine, after the response is written, with a
+// context detached from the request cancellation: it should bound its own
+// duration (e.g. with context.WithTimeout).
+type NotifyFunc func(ctx context.Context, report *Report)
+
+// Recoverer recovers the panics of HTTP handlers.
+type Recoverer struct {
+	logger     *slog.Logger
+	metrics    metrics.Client
+	appInfo    *jsendx.AppInfo
+	respond    http.Handler
+	notify     NotifyFunc
+	stackDepth int
+	nowFn      func() time.Time
+}
+
+// New constructs a Recoverer. By default the panics are logged with
+// slog.Default(), not counted, not notified, and answered with a JSendX 500
+// envelope without program metadata (see [WithAppInfo]).
+func New(opts ...Option) *Recoverer {
+	rec := &Recoverer{
+		logger:     slog.Default(),
+		metrics:    &metrics.Default{},
+		stackDepth: DefaultStackDepth,
+		nowFn:      time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(rec)
+	}
+
+	if rec.logger == nil {
+		rec.logger = slog.Default()
+	}
+
+	if rec.metrics == nil {
+		rec.metrics = &metrics.Default{}
+	}
+
+	if rec.stackDepth <= 0 {
+		rec.stackDepth = DefaultStackDepth
+	}
+
+	if rec.respond == nil {
+		jsx := jsendx.NewJSXResp(httputil.NewHTTPResp(rec.logger))
+		rec.respond = jsx.DefaultPanicHandlerFunc(rec.appInfo)
+	}
+
+	return rec
+}
+
+// MiddlewareFn implements [httpserver.MiddlewareFn], recovering the panics of
+// next.
+func (rec *Recoverer) MiddlewareFn(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+	logger := args.Logger
+	if logger == nil {
+		logger = rec.logger
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := httputil.NewResponseWriterWrapper(w)
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+
+			rec.handle(logger, args.Path, ww, r, p)
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// handle reports the recovered panic p and writes the error response.
+func (rec *Recoverer) handle(logger *slog.Logger, route string, w httputil.ResponseWriterWrapper, r *http.Request, p any) {
+	ctx := r.Context()
+
+	report := &Report{
+		Time:    rec.nowFn().UTC(),
+		Value:   p,
+		Message: fmt.Sprint(p),
+		Frames:  panicFrames(errutil.Stack(2, rec.stackDepth+runtimeFrames), rec.stackDepth),
+		TraceID: traceid.FromContext(ctx, ""),
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Route:   route,
+	}
+
+	rec.metrics.IncErrorCounter(metricsTask, route, metricsCode)
+
+	logger.ErrorContext(ctx, "panic recovered",
+		slog.String(traceid.DefaultLogKey, report.TraceID),
+		slog.String("request_method", report.Method),
+		slog.String("request_path", report.Path),
+		slog.String("route", report.Route),
+		slog.String("panic", report.Message),
+		slog.Any("stacktrace", report.Frames),
+	)
+
+	if rec.notify != nil {
+		go rec.runNotify(context.WithoutCancel(ctx), logger, report)
+	}
+
+	if w.Status() != 0 {
+		// The header is already sent: abort the response instead of appending
+		// an error body to a partial one.
+		panic(http.ErrAbortHandler)
+	}
+
+	rec.respond.ServeHTTP(w, r)
+}
+
+// runNotify calls the notifier, containing its own panics.
+func (rec *Recoverer) runNotify(ctx context.Context, logger *slog.Logger, report *Report) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.ErrorContext(ctx, "panic notifier failed",
+				slog.String(traceid.DefaultLogKey, report.TraceID),
+				slog.String("panic", fmt.Sprint(p)),
+			)
+		}
+	}()
+
+	rec.notify(ctx, report)
+}
+
+// panicFrames trims the recovery and runtime frames (runtime.gopanic,
+// runtime.sigpanic, ...) preceding the panic site from frames, and caps the
+// result at depth frames.
+func panicFrames(frames []errutil.Frame, depth int) []errutil.Frame {
+	for i, f := range frames {
+		if !isRuntimeFrame(f) {
+			continue
+		}
+
+		j := i
+		for j < len(frames) && isRuntimeFrame(frames[j]) {
+			j++
+		}
+
+		frames = frames[j:]
+
+		break
+	}
+
+	if len(frames) > depth {
+		frames = frames[:depth]
+	}
+
+	return frames
+}
+
+// isRuntimeFrame reports whether f belongs to the Go runtime package.
+func isRuntimeFrame(f errutil.Frame) bool {
+	return strings.HasPrefix(f.Function, "runtime.")
+}
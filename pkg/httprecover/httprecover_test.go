@@ -0,0 +1,357 @@
+package httprecover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/errutil"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+type fakeMetrics struct {
+	metrics.Default
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *fakeMetrics) IncErrorCounter(task, operation, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[task+"/"+operation+"/"+code]++
+}
+
+// panickingHandler panics with v; the tests expect its frame on top of the stack.
+func panickingHandler(v any) http.HandlerFunc {
+	return func(_ http.ResponseWriter, _ *http.Request) {
+		panic(v)
+	}
+}
+
+func newTestRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+
+	return r.WithContext(traceid.NewContext(r.Context(), "trace-123"))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	rec := New(WithLogger(nil), WithMetrics(nil), WithStackDepth(-1))
+	require.NotNil(t, rec.logger)
+	require.NotNil(t, rec.metrics)
+	require.NotNil(t, rec.respond)
+	require.Equal(t, DefaultStackDepth, rec.stackDepth)
+}
+
+func TestRecoverer_MiddlewareFn(t *testing.T) {
+	t.Parallel()
+
+	var logBuf bytes.Buffer
+
+	m := &fakeMetrics{}
+	reports := make(chan *Report, 1)
+
+	rec := New(
+		WithMetrics(m),
+		WithAppInfo(&jsendx.AppInfo{ProgramName: "test", ProgramVersion: "1.2.3", ProgramRelease: "4"}),
+		WithNotifyFunc(func(_ context.Context, report *Report) { reports <- report }),
+	)
+	rec.nowFn = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	args := httpserver.MiddlewareArgs{
+		Method: http.MethodGet,
+		Path:   "/items/:id",
+		Logger: slog.New(slog.NewJSONHandler(&logBuf, nil)),
+	}
+
+	h := rec.MiddlewareFn(args, panickingHandler("boom"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newTestRequest())
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var resp jsendx.Response
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "test", resp.Program)
+	require.Equal(t, http.StatusInternalServerError, resp.Code)
+	require.Equal(t, "internal error", resp.Data)
+
+	require.Equal(t, map[string]int{"panic//items/:id/500": 1}, m.counts)
+
+	logOut := logBuf.String()
+	require.Contains(t, logOut, `"msg":"panic recovered"`)
+	require.Contains(t, logOut, `"traceid":"trace-123"`)
+	require.Contains(t, logOut, `"request_path":"/items/42"`)
+	require.Contains(t, logOut, `"route":"/items/:id"`)
+	require.Contains(t, logOut, `"panic":"boom"`)
+	require.Contains(t, logOut, `"function":"github.com/tecnickcom/nurago/pkg/httprecover.panickingHandler.func1"`)
+
+	select {
+	case report := <-reports:
+		require.Equal(t, "boom", report.Value)
+		require.Equal(t, "boom", report.Message)
+		require.Equal(t, "trace-123", report.TraceID)
+		require.Equal(t, http.MethodGet, report.Method)
+		require.Equal(t, "/items/42", report.Path)
+		require.Equal(t, "/items/:id", report.Route)
+		require.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), report.Time)
+		require.NotEmpty(t, report.Frames)
+		require.Equal(t, "github.com/tecnickcom/nurago/pkg/httprecover.panickingHandler.func1", report.Frames[0].Function)
+		require.LessOrEqual(t, len(report.Frames), DefaultStackDepth)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "notifier not called")
+	}
+}
+
+func TestRecoverer_MiddlewareFn_noPanic(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+	rec := New(WithMetrics(m))
+
+	h := rec.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newTestRequest())
+
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.Empty(t, m.counts)
+}
+
+func TestRecoverer_MiddlewareFn_errAbortHandler(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+	rec := New(WithMetrics(m))
+
+	h := rec.MiddlewareFn(httpserver.MiddlewareArgs{}, panickingHandler(http.ErrAbortHandler))
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), newTestRequest())
+	})
+	require.Empty(t, m.counts)
+}
+
+func TestRecoverer_MiddlewareFn_headerSent(t *testing.T) {
+	t.Parallel()
+
+	var logBuf bytes.Buffer
+
+	m := &fakeMetrics{}
+	rec := New(WithMetrics(m), WithLogger(slog.New(slog.NewJSONHandler(&logBuf, nil))))
+
+	h := rec.MiddlewareFn(httpserver.MiddlewareArgs{Path: "/stream"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+
+		panic(errors.New("late failure"))
+	}))
+
+	w := httptest.NewRecorder()
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(w, newTestRequest())
+	})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "partial", w.Body.String())
+	require.Equal(t, map[string]int{"panic//stream/500": 1}, m.counts)
+	require.Contains(t, logBuf.String(), `"panic":"late failure"`)
+}
+
+func TestRecoverer_MiddlewareFn_responseHandler(t *testing.T) {
+	t.Parallel()
+
+	rec := New(
+		WithLogger(slog.New(slog.DiscardHandler)),
+		WithResponseHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "custom", http.StatusInternalServerError)
+		})),
+	)
+
+	h := rec.MiddlewareFn(httpserver.MiddlewareArgs{}, panickingHandler(42))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newTestRequest())
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "custom\n", w.Body.String())
+}
+
+func TestRecoverer_MiddlewareFn_notifierPanic(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		logBuf bytes.Buffer
+	)
+
+	done := make(chan struct{})
+
+	logger := slog.New(slog.NewJSONHandler(&lockedWriter{mu: &mu, w: &logBuf}, nil))
+
+	rec := New(
+		WithLogger(logger),
+		WithNotifyFunc(func(_ context.Context, _ *Report) {
+			defer close(done)
+
+			panic("notifier down")
+		}),
+	)
+
+	h := rec.MiddlewareFn(httpserver.MiddlewareArgs{}, panickingHandler("boom"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newTestRequest())
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	<-done
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return strings.Contains(logBuf.String(), `"msg":"panic notifier failed"`)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRecoverer_MiddlewareFn_server(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+	rec := New(WithMetrics(m), WithLogger(slog.New(slog.DiscardHandler)))
+
+	binder := &testBinder{routes: []httpserver.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/panic",
+			Handler:     panickingHandler("boom"),
+			Description: "panics",
+		},
+	}}
+
+	srv, err := httpserver.New(
+		t.Context(),
+		binder,
+		httpserver.WithServerAddr(":0"),
+		httpserver.WithLogger(slog.New(slog.DiscardHandler)),
+		httpserver.WithMiddlewareFn(rec.MiddlewareFn),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	srv.StartServerCtx(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+srv.Addr().String()+"/panic", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Contains(t, string(body), `"data":"internal error"`)
+	require.Equal(t, map[string]int{"panic//panic/500": 1}, m.counts)
+}
+
+func TestPanicFrames(t *testing.T) {
+	t.Parallel()
+
+	frame := func(fn string) errutil.Frame { return errutil.Frame{Function: fn} }
+
+	frames := []errutil.Frame{
+		frame("main.recover"),
+		frame("runtime.gopanic"),
+		frame("runtime.sigpanic"),
+		frame("main.handler"),
+		frame("main.serve"),
+		frame("runtime.goexit"),
+	}
+
+	got := panicFrames(frames, 10)
+	require.Equal(t, []errutil.Frame{frame("main.handler"), frame("main.serve"), frame("runtime.goexit")}, got)
+
+	got = panicFrames(frames, 1)
+	require.Equal(t, []errutil.Frame{frame("main.handler")}, got)
+
+	got = panicFrames([]errutil.Frame{frame("main.a"), frame("main.b")}, 10)
+	require.Equal(t, []errutil.Frame{frame("main.a"), frame("main.b")}, got)
+
+	require.Empty(t, panicFrames(nil, 10))
+}
+
+func TestReport_String(t *testing.T) {
+	t.Parallel()
+
+	frames := make([]errutil.Frame, 12)
+	for i := range frames {
+		frames[i] = errutil.Frame{File: "main.go", Line: i + 1, Function: "main.f" + strconv.Itoa(i)}
+	}
+
+	report := &Report{
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "boom",
+		Frames:  frames,
+		TraceID: "trace-123",
+		Method:  http.MethodPost,
+		Path:    "/items",
+		Route:   "/items",
+	}
+
+	got := report.String()
+	require.True(t, strings.HasPrefix(got, "panic: boom\nrequest: POST /items (route: /items)\ntrace_id: trace-123\ntime: 2026-01-02T03:04:05Z\n"))
+	require.Contains(t, got, "file: main.go, line: 10, function: main.f9\n")
+	require.NotContains(t, got, "main.f10")
+	require.True(t, strings.HasSuffix(got, "... 2 more frames\n"))
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	return lw.w.Write(p) //nolint:wrapcheck
+}
+
+type testBinder struct {
+	routes []httpserver.Route
+}
+
+func (b *testBinder) BindHTTP(_ context.Context) []httpserver.Route {
+	return b.routes
+}
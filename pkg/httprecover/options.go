@@ -0,0 +1,60 @@
+package httprecover
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+// Option is a type to allow setting custom Recoverer options.
+type Option func(*Recoverer)
+
+// WithLogger sets the logger used when the middleware receives none from
+// [github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareArgs], and by the
+// default response writer. A nil logger restores slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(rec *Recoverer) {
+		rec.logger = logger
+	}
+}
+
+// WithMetrics sets the metrics client counting the recovered panics through
+// IncErrorCounter("panic", route, "500"). A nil client disables the metrics.
+func WithMetrics(m metrics.Client) Option {
+	return func(rec *Recoverer) {
+		rec.metrics = m
+	}
+}
+
+// WithAppInfo sets the program metadata of the default JSendX 500 response.
+func WithAppInfo(info *jsendx.AppInfo) Option {
+	return func(rec *Recoverer) {
+		rec.appInfo = info
+	}
+}
+
+// WithResponseHandler sets the handler writing the response of a recovered
+// panic, replacing the default JSendX envelope. It should send a 500 status.
+func WithResponseHandler(h http.Handler) Option {
+	return func(rec *Recoverer) {
+		rec.respond = h
+	}
+}
+
+// WithNotifyFunc sets a function forwarding each panic report to an external
+// notifier, e.g. a Slack channel.
+func WithNotifyFunc(fn NotifyFunc) Option {
+	return func(rec *Recoverer) {
+		rec.notify = fn
+	}
+}
+
+// WithStackDepth sets the maximum number of stack frames reported (default
+// [DefaultStackDepth]). A non-positive value restores the default.
+func WithStackDepth(depth int) Option {
+	return func(rec *Recoverer) {
+		rec.stackDepth = depth
+	}
+}
@@ -0,0 +1,63 @@
+package httprecover
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+)
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	rec := &Recoverer{}
+	WithLogger(logger)(rec)
+	require.Equal(t, logger, rec.logger)
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+	rec := &Recoverer{}
+	WithMetrics(m)(rec)
+	require.Equal(t, m, rec.metrics)
+}
+
+func TestWithAppInfo(t *testing.T) {
+	t.Parallel()
+
+	info := &jsendx.AppInfo{ProgramName: "test"}
+	rec := &Recoverer{}
+	WithAppInfo(info)(rec)
+	require.Equal(t, info, rec.appInfo)
+}
+
+func TestWithResponseHandler(t *testing.T) {
+	t.Parallel()
+
+	h := http.NotFoundHandler()
+	rec := &Recoverer{}
+	WithResponseHandler(h)(rec)
+	require.NotNil(t, rec.respond)
+}
+
+func TestWithNotifyFunc(t *testing.T) {
+	t.Parallel()
+
+	rec := &Recoverer{}
+	WithNotifyFunc(func(_ context.Context, _ *Report) {})(rec)
+	require.NotNil(t, rec.notify)
+}
+
+func TestWithStackDepth(t *testing.T) {
+	t.Parallel()
+
+	rec := &Recoverer{}
+	WithStackDepth(5)(rec)
+	require.Equal(t, 5, rec.stackDepth)
+}
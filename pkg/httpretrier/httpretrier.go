@@ -32,6 +32,14 @@ reduce synchronized retry storms. Optionally, [WithRespectRetryAfter] makes the
 retrier wait at least the server-provided Retry-After delay, and [WithOnRetry]
 exposes each scheduled retry for logging or metrics.
 
+# Idempotency Keys
+
+[WithIdempotencyKey] attaches one generated Idempotency-Key header to every
+attempt of a non-idempotent request (POST and PATCH by default), so a server
+using [github.com/tecnickcom/nurago/pkg/idempotency] executes the operation at
+most once however many attempts are made. A key already set by the caller is
+kept.
+
 # Request Body Replay
 
 When a request has a body and retries are needed, the retrier relies on
@@ -46,10 +54,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/tecnickcom/nurago/pkg/backoff"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/random"
 )
 
 const (
@@ -127,6 +138,8 @@ type HTTPRetrier struct {
 	maxRetryAfter     time.Duration
 	retryIfFn         RetryIfFn
 	onRetry           OnRetryFn
+	idempotentMethods []string
+	rnd               *random.Rnd
 	httpClient        HTTPClient
 }
 
@@ -198,6 +211,8 @@ func (c *HTTPRetrier) Do(r *http.Request) (*http.Response, error) {
 	}
 	defer s.timer.Stop()
 
+	r = c.setIdempotencyKey(r)
+
 	for {
 		select {
 		case <-r.Context().Done():
@@ -210,6 +225,20 @@ func (c *HTTPRetrier) Do(r *http.Request) (*http.Response, error) {
 	}
 }
 
+// setIdempotencyKey returns a copy of r carrying a generated Idempotency-Key
+// header, shared by all the attempts, when enabled for the request method and
+// not already set. Otherwise it returns r.
+func (c *HTTPRetrier) setIdempotencyKey(r *http.Request) *http.Request {
+	if !slices.Contains(c.idempotentMethods, r.Method) || r.Header.Get(httputil.HeaderIdempotencyKey) != "" {
+		return r
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set(httputil.HeaderIdempotencyKey, c.rnd.UUIDv7().String())
+
+	return r
+}
+
 // defaultRetryIf is the default retry policy: returns true only when error is not nil (transport failures).
 func defaultRetryIf(_ *http.Response, err error) bool {
 	return err != nil
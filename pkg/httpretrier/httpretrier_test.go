@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httputil"
 	"github.com/tecnickcom/nurago/pkg/testutil"
 	"go.uber.org/mock/gomock"
 )
@@ -1097,3 +1098,82 @@ func TestHTTPRetrier_Do_maxDelayCaps(t *testing.T) {
 	require.Greater(t, gap3, 15*time.Millisecond, "capped gap should still be near the cap")
 	require.Less(t, gap3, 120*time.Millisecond, "gap must be capped well below the raw exponential value")
 }
+
+func TestHTTPRetrier_Do_idempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		method  string
+		key     string
+		wantKey func(t *testing.T, key string)
+	}{
+		{
+			name:   "generated",
+			method: http.MethodPost,
+			wantKey: func(t *testing.T, key string) {
+				t.Helper()
+				require.Len(t, key, 36)
+			},
+		},
+		{
+			name:   "caller key",
+			method: http.MethodPost,
+			key:    "caller-key",
+			wantKey: func(t *testing.T, key string) {
+				t.Helper()
+				require.Equal(t, "caller-key", key)
+			},
+		},
+		{
+			name:   "other method",
+			method: http.MethodGet,
+			wantKey: func(t *testing.T, key string) {
+				t.Helper()
+				require.Empty(t, key)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockHTTP := NewMockHTTPClient(ctrl)
+
+			var keys []string
+
+			mockHTTP.EXPECT().Do(gomock.Any()).Times(2).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+				keys = append(keys, r.Header.Get(httputil.HeaderIdempotencyKey))
+
+				return nil, errors.New("network error")
+			})
+
+			r, err := http.NewRequestWithContext(t.Context(), tt.method, "/", nil)
+			require.NoError(t, err)
+
+			if tt.key != "" {
+				r.Header.Set(httputil.HeaderIdempotencyKey, tt.key)
+			}
+
+			retrier, err := New(
+				mockHTTP,
+				WithIdempotencyKey(),
+				WithAttempts(2),
+				WithDelay(1*time.Millisecond),
+				WithJitter(1*time.Millisecond),
+			)
+			require.NoError(t, err)
+
+			resp, err := retrier.Do(r)
+			require.Error(t, err)
+			require.Nil(t, resp)
+
+			require.Len(t, keys, 2)
+			require.Equal(t, keys[0], keys[1], "every attempt must carry the same key")
+			tt.wantKey(t, keys[0])
+			require.Equal(t, tt.key, r.Header.Get(httputil.HeaderIdempotencyKey), "the caller request must not be modified")
+		})
+	}
+}
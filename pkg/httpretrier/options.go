@@ -2,7 +2,10 @@ package httpretrier
 
 import (
 	"errors"
+	"net/http"
 	"time"
+
+	"github.com/tecnickcom/nurago/pkg/random"
 )
 
 // Option configures an [HTTPRetrier] instance.
@@ -144,3 +147,21 @@ func WithOnRetry(onRetry OnRetryFn) Option {
 		return nil
 	}
 }
+
+// WithIdempotencyKey makes [HTTPRetrier.Do] attach a generated Idempotency-Key
+// header (a UUIDv7) to the requests with one of the given methods (default POST
+// and PATCH) that do not carry one yet. The same key is sent with every attempt
+// of a request, so the server can recognize the retries. The caller's request
+// is not modified.
+func WithIdempotencyKey(methods ...string) Option {
+	return func(r *HTTPRetrier) error {
+		if len(methods) == 0 {
+			methods = []string{http.MethodPost, http.MethodPatch}
+		}
+
+		r.idempotentMethods = methods
+		r.rnd = random.New(nil)
+
+		return nil
+	}
+}
@@ -169,3 +169,18 @@ func TestWithMaxRetryAfter(t *testing.T) {
 	err = WithMaxRetryAfter(v)(c)
 	require.Error(t, err)
 }
+
+func TestWithIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	c := defaultHTTPRetrier()
+
+	err := WithIdempotencyKey()(c)
+	require.NoError(t, err)
+	require.Equal(t, []string{http.MethodPost, http.MethodPatch}, c.idempotentMethods)
+	require.NotNil(t, c.rnd)
+
+	err = WithIdempotencyKey(http.MethodPut)(c)
+	require.NoError(t, err)
+	require.Equal(t, []string{http.MethodPut}, c.idempotentMethods)
+}
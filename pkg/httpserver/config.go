@@ -9,6 +9,7 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"os"
 	"runtime/debug"
 	"slices"
 	"strconv"
@@ -46,6 +47,10 @@ func GetPublicIPDefaultFunc() GetPublicIPFunc {
 type config struct {
 	router                        *httprouter.Router
 	serverAddr                    string
+	unixSocketPath                string
+	unixSocketMode                os.FileMode
+	listenerFile                  *os.File
+	h2c                           bool
 	traceIDHeaderName             string
 	requestTimeout                time.Duration
 	serverReadHeaderTimeout       time.Duration
@@ -59,6 +64,7 @@ type config struct {
 	defaultEnabledRoutes          []DefaultRoute
 	indexHandlerFunc              IndexHandlerFunc
 	ipHandlerFunc                 http.HandlerFunc
+	logLevelHandlerFunc           http.HandlerFunc
 	metricsHandlerFunc            http.HandlerFunc
 	pingHandlerFunc               http.HandlerFunc
 	pprofHandlerFunc              http.HandlerFunc
@@ -68,6 +74,8 @@ type config struct {
 	panicHandlerFunc              http.HandlerFunc
 	redactFn                      RedactFn
 	middleware                    []MiddlewareFn
+	cors                          *corsPolicy
+	securityHeaders               *securityHeaders
 	disableDefaultRouteLogger     map[DefaultRoute]bool
 	disableRouteLogger            bool
 	disableNotFoundLogger         bool
@@ -121,6 +129,18 @@ func (c *config) isIndexRouteEnabled() bool {
 	return slices.Contains(c.defaultEnabledRoutes, IndexRoute)
 }
 
+// listenAddr returns the description of the configured listener address.
+func (c *config) listenAddr() string {
+	switch {
+	case c.listenerFile != nil:
+		return "fd:" + strconv.FormatUint(uint64(c.listenerFile.Fd()), 10)
+	case c.unixSocketPath != "":
+		return "unix:" + c.unixSocketPath
+	default:
+		return c.serverAddr
+	}
+}
+
 // validateAddr checks if a http server bind address is valid.
 // The host part may be empty, a hostname, an IPv4 or a bracketed IPv6 address
 // (e.g. ":8080", "localhost:8080", "0.0.0.0:8080", "[::1]:8080").
@@ -164,6 +184,10 @@ func (c *config) commonMiddleware(noRouteLogger bool, rTimeout time.Duration) []
 		middleware = append(middleware, LoggerMiddlewareFn)
 	}
 
+	// The response headers are set outside the timeout handler, which would
+	// discard them from its 503 responses.
+	middleware = append(middleware, c.headerMiddleware()...)
+
 	// A positive per-route timeout overrides the global one; a negative value
 	// (e.g. DisableTimeout) disables the timeout for the route entirely.
 	timeout := c.requestTimeout
@@ -182,6 +206,50 @@ func (c *config) commonMiddleware(noRouteLogger bool, rTimeout time.Duration) []
 	return append(middleware, c.middleware...)
 }
 
+// headerMiddleware returns the security-headers and CORS middleware, when configured.
+func (c *config) headerMiddleware() []MiddlewareFn {
+	var middleware []MiddlewareFn
+
+	if c.securityHeaders != nil {
+		middleware = append(middleware, c.securityHeaders.middlewareFn)
+	}
+
+	if c.cors != nil {
+		middleware = append(middleware, c.cors.middlewareFn)
+	}
+
+	return middleware
+}
+
+// preflightHandler returns the handler of the automatic OPTIONS responses of
+// the router: the CORS middleware answers the preflight requests, and the
+// other OPTIONS requests get a 204 with the Allow header set by the router.
+// The global middleware (e.g. authentication) is not applied, as browsers send
+// preflight requests without credentials.
+func (c *config) preflightHandler() http.Handler {
+	var middleware []MiddlewareFn
+
+	if c.maxRequestBodyBytes > 0 {
+		middleware = append(middleware, func(_ MiddlewareArgs, next http.Handler) http.Handler {
+			return http.MaxBytesHandler(next, c.maxRequestBodyBytes)
+		})
+	}
+
+	if !c.disableRouteLogger {
+		middleware = append(middleware, LoggerMiddlewareFn)
+	}
+
+	middleware = append(middleware, c.headerMiddleware()...)
+
+	return ApplyMiddleware(
+		c.mwArgs(http.MethodOptions, "*", "CORS preflight"),
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		middleware...,
+	)
+}
+
 // mwArgs builds the MiddlewareArgs shared by every route and default handler.
 func (c *config) mwArgs(method, path, description string) MiddlewareArgs {
 	return MiddlewareArgs{
@@ -213,6 +281,10 @@ func (c *config) setRouter() {
 		)
 	}
 
+	if c.cors != nil && c.router.GlobalOPTIONS == nil {
+		c.router.GlobalOPTIONS = c.preflightHandler()
+	}
+
 	if c.router.PanicHandler == nil {
 		c.router.PanicHandler = c.newPanicHandler(c.commonMiddleware(false, 0))
 	}
@@ -324,3 +396,16 @@ func (c *config) defaultPanicHandlerFunc() http.HandlerFunc {
 		},
 	)
 }
+
+// setListener sets the Unix domain socket or the inherited listener file,
+// replacing (and releasing) the previous listener configuration. When both are
+// empty the server listens on the TCP address.
+func (c *config) setListener(unixSocketPath string, unixSocketMode os.FileMode, listenerFile *os.File) {
+	if c.listenerFile != nil && c.listenerFile != listenerFile {
+		_ = c.listenerFile.Close()
+	}
+
+	c.unixSocketPath = unixSocketPath
+	c.unixSocketMode = unixSocketMode
+	c.listenerFile = listenerFile
+}
@@ -23,6 +23,7 @@ func Test_defaultConfig(t *testing.T) {
 	require.NotNil(t, cfg.pprofHandlerFunc)
 	require.NotNil(t, cfg.statusHandlerFunc)
 	require.NotNil(t, cfg.ipHandlerFunc)
+	require.Nil(t, cfg.logLevelHandlerFunc, "the log level route needs a controller")
 	require.NotEmpty(t, cfg.serverAddr)
 	require.NotEqual(t, 0, cfg.shutdownTimeout)
 	require.NotEmpty(t, cfg.traceIDHeaderName)
@@ -0,0 +1,333 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS request and response headers (https://fetch.spec.whatwg.org/#http-cors-protocol).
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAllow                         = "Allow"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// CORSConfig configures the Cross-Origin Resource Sharing middleware (see
+// [CORSMiddlewareFn] and [WithCORS]).
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to read the responses, e.g.
+	// "https://app.example.com". An entry may contain one "*" wildcard, e.g.
+	// "https://*.example.com" (any subdomain) or "http://localhost:*" (any
+	// port); "*" alone allows every origin. Matching is case-insensitive.
+	AllowedOrigins []string
+
+	// AllowedMethods lists the methods allowed in cross-origin requests. When
+	// empty, the preflight responses allow the methods the router serves on
+	// the requested path (its Allow header), or else the common REST methods.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed in cross-origin
+	// requests, besides the CORS-safelisted ones; "*" allows any header. When
+	// empty, it defaults to Authorization, Content-Type and the trace ID header
+	// of the server.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers readable by the client
+	// scripts, besides the CORS-safelisted ones (e.g. "RateLimit-Remaining").
+	ExposedHeaders []string
+
+	// AllowCredentials allows the requests carrying cookies or HTTP
+	// authentication. It cannot be combined with the "*" origin, which would
+	// let any site act on behalf of the users.
+	AllowCredentials bool
+
+	// MaxAge is how long the browsers may cache a preflight response; zero
+	// leaves it to the browser default (5 seconds). It is sent in whole seconds.
+	MaxAge time.Duration
+}
+
+// corsPolicy is a validated [CORSConfig].
+type corsPolicy struct {
+	allowAll    bool
+	origins     []string    // exact origins
+	patterns    [][2]string // wildcard origins, split at the "*"
+	methods     []string    // empty: router Allow header, or defaultCORSMethods
+	headers     []string    // canonical header names
+	anyHeader   bool
+	traceID     bool // also allow the trace ID header of the server
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+// defaultCORSMethods returns the methods allowed when neither the
+// configuration nor the router lists them.
+func defaultCORSMethods() []string {
+	return []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+}
+
+// newCORSPolicy validates cfg.
+func newCORSPolicy(cfg CORSConfig) (*corsPolicy, error) {
+	if len(cfg.AllowedOrigins) == 0 {
+		return nil, fmt.Errorf("%w: no allowed origins", ErrInvalidCORSConfig)
+	}
+
+	if cfg.MaxAge < 0 {
+		return nil, fmt.Errorf("%w: negative max age", ErrInvalidCORSConfig)
+	}
+
+	p := &corsPolicy{
+		methods:     slices.Clone(cfg.AllowedMethods),
+		exposed:     strings.Join(canonicalHeaders(cfg.ExposedHeaders), ", "),
+		credentials: cfg.AllowCredentials,
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.FormatInt(int64((cfg.MaxAge+time.Second-1)/time.Second), 10)
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+
+		switch strings.Count(origin, "*") {
+		case 0:
+			p.origins = append(p.origins, origin)
+		case 1:
+			if origin == "*" {
+				p.allowAll = true
+
+				continue
+			}
+
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.patterns = append(p.patterns, [2]string{prefix, suffix})
+		default:
+			return nil, fmt.Errorf("%w: origin %q has more than one wildcard", ErrInvalidCORSConfig, origin)
+		}
+	}
+
+	if p.allowAll && p.credentials {
+		return nil, fmt.Errorf("%w: credentials cannot be allowed for every origin", ErrInvalidCORSConfig)
+	}
+
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Authorization", "Content-Type"}
+		p.traceID = true
+	}
+
+	for _, h := range headers {
+		if strings.TrimSpace(h) == "*" {
+			p.anyHeader = true
+		}
+	}
+
+	p.headers = canonicalHeaders(slices.DeleteFunc(slices.Clone(headers), func(h string) bool {
+		return strings.TrimSpace(h) == "*" || strings.TrimSpace(h) == ""
+	}))
+
+	return p, nil
+}
+
+// canonicalHeaders returns the canonical form of the header names.
+func canonicalHeaders(names []string) []string {
+	out := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, http.CanonicalHeaderKey(name))
+		}
+	}
+
+	return out
+}
+
+// allowOrigin reports whether origin is allowed.
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if slices.Contains(p.origins, origin) {
+		return true
+	}
+
+	for _, pt := range p.patterns {
+		if len(origin) > len(pt[0])+len(pt[1]) && strings.HasPrefix(origin, pt[0]) && strings.HasSuffix(origin, pt[1]) {
+			// The wildcard spans subdomain labels or a port, never a path.
+			if !strings.ContainsAny(origin[len(pt[0]):len(origin)-len(pt[1])], "/?#@") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// allowedMethods returns the methods allowed on the requested resource.
+func (p *corsPolicy) allowedMethods(h http.Header) []string {
+	if len(p.methods) > 0 {
+		return p.methods
+	}
+
+	// The router sets the Allow header of the automatic OPTIONS responses.
+	if allow := h.Get(headerAllow); allow != "" {
+		methods := strings.Split(allow, ",")
+		for i, m := range methods {
+			methods[i] = strings.TrimSpace(m)
+		}
+
+		return methods
+	}
+
+	return defaultCORSMethods()
+}
+
+// allowHeaders returns the requested headers when they are all allowed.
+func (p *corsPolicy) allowHeaders(requested, traceIDHeaderName string) (string, bool) {
+	traceIDHeaderName = http.CanonicalHeaderKey(traceIDHeaderName)
+
+	var names []string
+
+	for name := range strings.SplitSeq(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		canonical := http.CanonicalHeaderKey(name)
+
+		if !p.anyHeader && !slices.Contains(p.headers, canonical) && (!p.traceID || canonical != traceIDHeaderName) {
+			return "", false
+		}
+
+		names = append(names, name)
+	}
+
+	return strings.Join(names, ", "), true
+}
+
+// setOrigin sets the headers granting access to origin.
+func (p *corsPolicy) setOrigin(h http.Header, origin string) {
+	if p.allowAll && !p.credentials {
+		h.Set(headerAccessControlAllowOrigin, "*")
+	} else {
+		h.Set(headerAccessControlAllowOrigin, origin)
+	}
+
+	if p.credentials {
+		h.Set(headerAccessControlAllowCredentials, "true")
+	}
+}
+
+// preflight answers a CORS preflight request. Denied requests get a 204
+// without the CORS headers, which the browser reports as a failure.
+func (p *corsPolicy) preflight(w http.ResponseWriter, r *http.Request, traceIDHeaderName string) {
+	h := w.Header()
+	h.Add(headerVary, headerOrigin)
+	h.Add(headerVary, headerAccessControlRequestMethod)
+	h.Add(headerVary, headerAccessControlRequestHeaders)
+
+	origin := r.Header.Get(headerOrigin)
+	methods := p.allowedMethods(h)
+	headers, headersOK := p.allowHeaders(r.Header.Get(headerAccessControlRequestHeaders), traceIDHeaderName)
+
+	if p.allowOrigin(origin) && headersOK && slices.Contains(methods, r.Header.Get(headerAccessControlRequestMethod)) {
+		p.setOrigin(h, origin)
+		h.Set(headerAccessControlAllowMethods, strings.Join(methods, ", "))
+
+		if headers != "" {
+			h.Set(headerAccessControlAllowHeaders, headers)
+		}
+
+		if p.maxAge != "" {
+			h.Set(headerAccessControlMaxAge, p.maxAge)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// middlewareFn returns the CORS middleware of the policy.
+func (p *corsPolicy) middlewareFn(args MiddlewareArgs, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get(headerOrigin)
+		if origin == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get(headerAccessControlRequestMethod) != "" {
+			p.preflight(w, r, args.TraceIDHeaderName)
+
+			return
+		}
+
+		h := w.Header()
+
+		if !p.allowAll || p.credentials {
+			h.Add(headerVary, headerOrigin)
+		}
+
+		if p.allowOrigin(origin) {
+			p.setOrigin(h, origin)
+
+			if p.exposed != "" {
+				h.Set(headerAccessControlExposeHeaders, p.exposed)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CORSMiddlewareFn returns a middleware applying the CORS policy cfg, for
+// use on individual routes (see [WithCORS] to apply it to the whole server).
+//
+// Cross-origin responses from allowed origins carry the
+// Access-Control-Allow-Origin (and -Credentials, -Expose-Headers) headers.
+// Preflight requests (OPTIONS with Access-Control-Request-Method) are
+// answered with 204 No Content without reaching next, carrying the allowed
+// methods and headers when the origin, method and headers are all allowed.
+//
+// The router answers the OPTIONS requests of a path itself, without any route
+// middleware, unless an OPTIONS route is registered on it. A path served with
+// this middleware must therefore also get an explicit OPTIONS route using it,
+// for the preflight requests to be answered:
+//
+//	cors, err := httpserver.CORSMiddlewareFn(cfg)
+//	// ...
+//	routes := []httpserver.Route{
+//		{Method: http.MethodPut, Path: "/items/:id", Handler: putItem, Middleware: []httpserver.MiddlewareFn{cors}},
+//		{Method: http.MethodOptions, Path: "/items/:id", Handler: noContent, Middleware: []httpserver.MiddlewareFn{cors}},
+//	}
+func CORSMiddlewareFn(cfg CORSConfig) (MiddlewareFn, error) {
+	p, err := newCORSPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.middlewareFn, nil
+}
@@ -0,0 +1,348 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCORSPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     CORSConfig
+		wantErr bool
+	}{
+		{name: "exact", cfg: CORSConfig{AllowedOrigins: []string{"https://example.com"}}},
+		{name: "wildcard", cfg: CORSConfig{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true}},
+		{name: "any", cfg: CORSConfig{AllowedOrigins: []string{"*"}}},
+		{name: "no origins", cfg: CORSConfig{}, wantErr: true},
+		{name: "two wildcards", cfg: CORSConfig{AllowedOrigins: []string{"https://*.*.com"}}, wantErr: true},
+		{name: "any with credentials", cfg: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, wantErr: true},
+		{name: "negative max age", cfg: CORSConfig{AllowedOrigins: []string{"*"}, MaxAge: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := newCORSPolicy(tt.cfg)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidCORSConfig)
+				require.Nil(t, p)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, p)
+		})
+	}
+}
+
+func TestCorsPolicy_allowOrigin(t *testing.T) {
+	t.Parallel()
+
+	p, err := newCORSPolicy(CORSConfig{AllowedOrigins: []string{
+		"https://app.example.com",
+		"https://*.example.org",
+		"http://localhost:*",
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://app.example.com", want: true},
+		{origin: "HTTPS://APP.EXAMPLE.COM", want: true},
+		{origin: "http://app.example.com"},
+		{origin: "https://evil.com"},
+		{origin: "https://a.example.org", want: true},
+		{origin: "https://a.b.example.org", want: true},
+		{origin: "https://.example.org"},
+		{origin: "https://example.org"},
+		{origin: "https://evil.com/x.example.org"},
+		{origin: "https://user@x.example.org"},
+		{origin: "http://localhost:3000", want: true},
+		{origin: "http://localhost"},
+		{origin: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, p.allowOrigin(tt.origin))
+		})
+	}
+}
+
+func TestCORSMiddlewareFn(t *testing.T) {
+	t.Parallel()
+
+	_, err := CORSMiddlewareFn(CORSConfig{})
+	require.ErrorIs(t, err, ErrInvalidCORSConfig)
+
+	mw, err := CORSMiddlewareFn(CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders:   []string{"x-api-key"},
+		ExposedHeaders:   []string{"ratelimit-remaining", " "},
+		AllowCredentials: true,
+		MaxAge:           1500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	called := false
+	handler := mw(MiddlewareArgs{TraceIDHeaderName: "X-Request-Id"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method string, headers map[string]string) *httptest.ResponseRecorder {
+		called = false
+
+		r := httptest.NewRequest(method, "/", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		return rr
+	}
+
+	// same-origin request
+	rr := serve(http.MethodGet, nil)
+	require.True(t, called)
+	require.Empty(t, rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Empty(t, rr.Header().Get(headerVary))
+
+	// allowed cross-origin request
+	rr = serve(http.MethodGet, map[string]string{headerOrigin: "https://app.example.com"})
+	require.True(t, called)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "true", rr.Header().Get(headerAccessControlAllowCredentials))
+	require.Equal(t, "Ratelimit-Remaining", rr.Header().Get(headerAccessControlExposeHeaders))
+	require.Equal(t, headerOrigin, rr.Header().Get(headerVary))
+
+	// denied cross-origin request
+	rr = serve(http.MethodGet, map[string]string{headerOrigin: "https://evil.com"})
+	require.True(t, called)
+	require.Empty(t, rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, headerOrigin, rr.Header().Get(headerVary))
+
+	// allowed preflight
+	rr = serve(http.MethodOptions, map[string]string{
+		headerOrigin:                      "https://app.example.com",
+		headerAccessControlRequestMethod:  http.MethodPut,
+		headerAccessControlRequestHeaders: "X-API-Key",
+	})
+	require.False(t, called)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "true", rr.Header().Get(headerAccessControlAllowCredentials))
+	require.Equal(t, "GET, PUT", rr.Header().Get(headerAccessControlAllowMethods))
+	require.Equal(t, "X-API-Key", rr.Header().Get(headerAccessControlAllowHeaders))
+	require.Equal(t, "2", rr.Header().Get(headerAccessControlMaxAge))
+	require.Equal(t, []string{headerOrigin, headerAccessControlRequestMethod, headerAccessControlRequestHeaders}, rr.Header().Values(headerVary))
+
+	// denied preflights
+	for name, headers := range map[string]map[string]string{
+		"origin": {headerOrigin: "https://evil.com", headerAccessControlRequestMethod: http.MethodGet},
+		"method": {headerOrigin: "https://app.example.com", headerAccessControlRequestMethod: http.MethodDelete},
+		"header": {
+			headerOrigin:                      "https://app.example.com",
+			headerAccessControlRequestMethod:  http.MethodGet,
+			headerAccessControlRequestHeaders: "X-API-Key, X-Request-Id",
+		},
+	} {
+		rr = serve(http.MethodOptions, headers)
+		require.False(t, called, name)
+		require.Equal(t, http.StatusNoContent, rr.Code, name)
+		require.Empty(t, rr.Header().Get(headerAccessControlAllowOrigin), name)
+		require.Empty(t, rr.Header().Get(headerAccessControlAllowMethods), name)
+	}
+
+	// plain OPTIONS request
+	rr = serve(http.MethodOptions, map[string]string{headerOrigin: "https://app.example.com"})
+	require.True(t, called)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestCORSMiddlewareFn_defaults(t *testing.T) {
+	t.Parallel()
+
+	mw, err := CORSMiddlewareFn(CORSConfig{AllowedOrigins: []string{"*"}})
+	require.NoError(t, err)
+
+	handler := mw(MiddlewareArgs{TraceIDHeaderName: "X-Request-Id"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(headerOrigin, "https://any.example.net")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	require.Equal(t, "*", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Empty(t, rr.Header().Get(headerAccessControlAllowCredentials))
+	require.Empty(t, rr.Header().Get(headerVary), "a wildcard response does not vary by origin")
+
+	r = httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set(headerOrigin, "https://any.example.net")
+	r.Header.Set(headerAccessControlRequestMethod, http.MethodPatch)
+	r.Header.Set(headerAccessControlRequestHeaders, "content-type,x-request-id, authorization")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+	require.Equal(t, "*", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", rr.Header().Get(headerAccessControlAllowMethods))
+	require.Equal(t, "content-type, x-request-id, authorization", rr.Header().Get(headerAccessControlAllowHeaders))
+	require.Empty(t, rr.Header().Get(headerAccessControlMaxAge))
+}
+
+func TestCORSMiddlewareFn_anyHeader(t *testing.T) {
+	t.Parallel()
+
+	p, err := newCORSPolicy(CORSConfig{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}})
+	require.NoError(t, err)
+
+	headers, ok := p.allowHeaders("X-Anything, X-Else", "")
+	require.True(t, ok)
+	require.Equal(t, "X-Anything, X-Else", headers)
+}
+
+func TestWithCORS_router(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	require.NoError(t, WithCORS(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})(cfg))
+	require.NoError(t, WithSecurityHeaders(DefaultSecurityHeadersConfig())(cfg))
+
+	cfg.setRouter()
+	require.NotNil(t, cfg.router.GlobalOPTIONS)
+
+	binder := &routeBinder{routes: []Route{
+		{Method: http.MethodGet, Path: "/items", Handler: okHandler},
+		{Method: http.MethodDelete, Path: "/items", Handler: okHandler},
+	}}
+	require.NoError(t, loadRoutes(t.Context(), binder, cfg))
+
+	// automatic preflight, allowing the methods routed on the path
+	r := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	r.Header.Set(headerOrigin, "https://app.example.com")
+	r.Header.Set(headerAccessControlRequestMethod, http.MethodDelete)
+
+	rr := httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "DELETE, GET, OPTIONS", rr.Header().Get(headerAccessControlAllowMethods))
+	require.Equal(t, "nosniff", rr.Header().Get(headerContentTypeOptions))
+
+	// plain OPTIONS request
+	rr = httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/items", nil))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "DELETE, GET, OPTIONS", rr.Header().Get(headerAllow))
+
+	// actual request
+	r = httptest.NewRequest(http.MethodGet, "/items", nil)
+	r.Header.Set(headerOrigin, "https://app.example.com")
+
+	rr = httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "nosniff", rr.Header().Get(headerContentTypeOptions))
+
+	// error responses carry the headers too
+	r = httptest.NewRequest(http.MethodGet, "/missing", nil)
+	r.Header.Set(headerOrigin, "https://app.example.com")
+
+	rr = httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "DENY", rr.Header().Get(headerFrameOptions))
+}
+
+func TestCORSMiddlewareFn_route(t *testing.T) {
+	t.Parallel()
+
+	mw, err := CORSMiddlewareFn(CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodPut},
+	})
+	require.NoError(t, err)
+
+	cfg := defaultConfig()
+	cfg.setRouter()
+
+	binder := &routeBinder{routes: []Route{
+		{Method: http.MethodPut, Path: "/items", Handler: okHandler, Middleware: []MiddlewareFn{mw}},
+		{Method: http.MethodPut, Path: "/things", Handler: okHandler, Middleware: []MiddlewareFn{mw}},
+		{Method: http.MethodOptions, Path: "/things", Handler: okHandler, Middleware: []MiddlewareFn{mw}},
+	}}
+	require.NoError(t, loadRoutes(t.Context(), binder, cfg))
+
+	preflight := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, path, nil)
+		r.Header.Set(headerOrigin, "https://app.example.com")
+		r.Header.Set(headerAccessControlRequestMethod, http.MethodPut)
+
+		rr := httptest.NewRecorder()
+		cfg.router.ServeHTTP(rr, r)
+
+		return rr
+	}
+
+	// without an OPTIONS route the router answers on its own
+	rr := preflight("/items")
+	require.Empty(t, rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, "OPTIONS, PUT", rr.Header().Get(headerAllow))
+
+	// with an OPTIONS route the middleware answers
+	rr = preflight("/things")
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "https://app.example.com", rr.Header().Get(headerAccessControlAllowOrigin))
+	require.Equal(t, http.MethodPut, rr.Header().Get(headerAccessControlAllowMethods))
+
+	// a plain OPTIONS request reaches the route handler
+	rr = httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/things", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestWithCORS_timeout(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	require.NoError(t, WithCORS(CORSConfig{AllowedOrigins: []string{"*"}})(cfg))
+	require.NoError(t, WithRequestTimeout(time.Millisecond)(cfg))
+
+	cfg.setRouter()
+
+	binder := &routeBinder{routes: []Route{
+		{Method: http.MethodGet, Path: "/slow", Handler: func(_ http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}},
+	}}
+	require.NoError(t, loadRoutes(t.Context(), binder, cfg))
+
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	r.Header.Set(headerOrigin, "https://app.example.com")
+
+	rr := httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, r)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "*", rr.Header().Get(headerAccessControlAllowOrigin), "timeout responses must keep the CORS headers")
+}
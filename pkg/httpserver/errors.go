@@ -28,7 +28,22 @@ var (
 	// ErrUnknownDefaultRoute is returned when an unknown default route identifier is enabled.
 	ErrUnknownDefaultRoute = errors.New("unknown default route")
 
+	// ErrInvalidCORSConfig is returned when a CORS configuration is not valid.
+	ErrInvalidCORSConfig = errors.New("invalid CORS configuration")
+
 	// ErrInvalidTLSConfig is returned when a TLS configuration carries no
 	// certificate material (no Certificates, GetCertificate, or GetConfigForClient).
 	ErrInvalidTLSConfig = errors.New("invalid TLS configuration: no Certificates, GetCertificate, or GetConfigForClient set")
+
+	// ErrNoListenerFD is returned when no listener file descriptor was
+	// inherited through socket activation.
+	ErrNoListenerFD = errors.New("no inherited listener file descriptor")
+
+	// ErrListenerFile is returned when the file descriptor of the server
+	// listener cannot be duplicated.
+	ErrListenerFile = errors.New("unable to get the listener file")
+
+	// ErrUnixSocketInUse is returned when another server accepts connections
+	// on the Unix domain socket path.
+	ErrUnixSocketInUse = errors.New("unix socket address already in use")
 )
@@ -15,6 +15,9 @@ enabled selectively (or all at once) through options.
 
 When enabled, the built-in route set includes:
   - /ip: returns the service public IP (via ipify integration)
+  - /loglevel: reads (GET) and changes (PUT) the log levels at runtime
+    (only enabled explicitly, and when a controller is set via
+    [WithLogLevelController])
   - /metrics: returns metrics payload (501 by default unless replaced)
   - /ping: liveness endpoint
   - /pprof/*option: pprof profiling endpoints
@@ -36,18 +39,27 @@ When enabled, the built-in route set includes:
   - Middleware pipeline: common middleware (logger/timeout) plus global and
     per-route middleware composition, with per-route timeout override or
     opt-out ([DisableTimeout]).
+  - Browser security: optional CORS policy with automatic preflight
+    responses ([WithCORS]) and security response headers such as HSTS and
+    CSP ([WithSecurityHeaders]), also available as per-route middleware.
   - Observability: trace-id propagation hooks, HTTP data redaction, per-request
     log entries carrying the response status code and size, optional
     pprof/metrics/status routes, and net/http internal diagnostics routed to
     the structured logger.
   - Transport: plain TCP or TLS (HTTP/1.1 and HTTP/2 via ALPN) from cert/key
-    material ([WithTLSCertData]) or a custom [WithTLSConfig].
+    material ([WithTLSCertData]) or a custom [WithTLSConfig], and HTTP/2
+    without TLS ([WithH2C]) for trusted sidecar proxies.
+  - Listeners: TCP address ([WithServerAddr]), Unix domain socket
+    ([WithUnixSocket]), or an inherited file descriptor from socket activation
+    ([WithSocketActivation], [WithListenerFD]). [HTTPServer.ListenerFile]
+    hands the listener over to a child process for zero-downtime restarts.
 
 # Security
 
 The default operational routes expose service internals: /pprof/*option serves
 runtime profiles (memory layout, goroutine stacks, CPU traces), the index route
-enumerates every registered endpoint, /metrics may reveal implementation
+enumerates every registered endpoint, /loglevel changes the log verbosity
+(and so the log volume) of the service, /metrics may reveal implementation
 details, and /ip performs an outbound call to a third-party service. Enable
 these routes only on internal or administrative listeners that are not
 reachable from the public internet, or protect them with authentication
@@ -59,7 +71,6 @@ package httpserver
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -95,6 +106,7 @@ type HTTPServer struct {
 	ctx          context.Context //nolint:containedctx
 	httpServer   *http.Server
 	listener     net.Listener
+	baseListener net.Listener // listener without the TLS layer, see ListenerFile
 	startedMutex sync.Mutex
 	started      bool
 	stopped      bool // set once Shutdown has run, guarding against a late Start.
@@ -112,8 +124,9 @@ type HTTPServer struct {
 //
 // Note: the underlying httprouter may emit trailing-slash and fixed-path
 // redirects (HTTP 301) and automatic OPTIONS responses before the middleware
-// pipeline runs; those are not passed through the request logger. Supply a
-// custom router via [WithRouter] to change that behavior.
+// pipeline runs; those are not passed through the request logger, except the
+// OPTIONS responses when [WithCORS] is set. Supply a custom router via
+// [WithRouter] to change that behavior.
 //
 //nolint:contextcheck // a nil ctx is deliberately replaced with context.Background()
 func New(ctx context.Context, binder Binder, opts ...Option) (*HTTPServer, error) {
@@ -130,13 +143,15 @@ func New(ctx context.Context, binder Binder, opts ...Option) (*HTTPServer, error
 	for _, applyOpt := range opts {
 		err := applyOpt(cfg)
 		if err != nil {
+			cfg.setListener("", 0, nil) // release an inherited file descriptor
+
 			return nil, err
 		}
 	}
 
 	cfg.logger = cfg.logger.With(
 		slog.String("component", "httpserver"),
-		slog.String("addr", cfg.serverAddr),
+		slog.String("addr", cfg.listenAddr()),
 	)
 
 	cfg.httpresp = httputil.NewHTTPResp(cfg.logger)
@@ -145,10 +160,12 @@ func New(ctx context.Context, binder Binder, opts ...Option) (*HTTPServer, error
 
 	err := loadRoutes(ctx, binder, cfg)
 	if err != nil {
+		cfg.setListener("", 0, nil) // release an inherited file descriptor
+
 		return nil, err
 	}
 
-	listener, err := netListener(ctx, cfg.serverAddr, cfg.tlsConfig)
+	listener, baseListener, err := netListener(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -164,10 +181,12 @@ func New(ctx context.Context, binder Binder, opts ...Option) (*HTTPServer, error
 	// created by this package, so ALPN advertisement is controlled solely by
 	// the tls.Config NextProtos, not by net/http). This matches the net/http
 	// defaults for this configuration and shields the server from future
-	// changes to those defaults.
+	// changes to those defaults. HTTP/2 without TLS (h2c) is only accepted
+	// when explicitly enabled with WithH2C.
 	protocols := new(http.Protocols)
 	protocols.SetHTTP1(true)
 	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(cfg.h2c)
 
 	return &HTTPServer{
 			cfg: cfg,
@@ -186,6 +205,7 @@ func New(ctx context.Context, binder Binder, opts ...Option) (*HTTPServer, error
 				Protocols:         protocols,
 			},
 			listener:     listener,
+			baseListener: baseListener,
 			shutdownDone: make(chan struct{}),
 			monitorDone:  make(chan struct{}),
 			serveErr:     make(chan error, 1),
@@ -338,33 +358,6 @@ func (h *HTTPServer) serve() {
 	_ = h.Shutdown(shutdownCtx)
 }
 
-// netListener creates a network listener for the given server address and TLS
-// configuration. The TCP listener is created through net.ListenConfig so both
-// the plain and TLS paths honor the caller's context.
-func netListener(ctx context.Context, serverAddr string, tlsConfig *tls.Config) (net.Listener, error) {
-	// tls.NewListener performs no configuration validation, so replicate the
-	// tls.Listen check upfront (before binding, so nothing can leak) to reject
-	// unusable TLS configurations at startup instead of failing every
-	// handshake at runtime.
-	if tlsConfig != nil && len(tlsConfig.Certificates) == 0 &&
-		tlsConfig.GetCertificate == nil && tlsConfig.GetConfigForClient == nil {
-		return nil, fmt.Errorf("failed creating the http server address listener: %w", ErrInvalidTLSConfig)
-	}
-
-	var lc net.ListenConfig
-
-	ls, err := lc.Listen(ctx, "tcp", serverAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed creating the http server address listener: %w", err)
-	}
-
-	if tlsConfig != nil {
-		ls = tls.NewListener(ls, tlsConfig)
-	}
-
-	return ls, nil
-}
-
 // loadRoutes validates and binds the default and custom routes to the router.
 // It returns an error (instead of letting the router panic) when a route is
 // malformed, duplicated, or rejected by the underlying router.
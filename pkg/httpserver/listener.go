@@ -0,0 +1,184 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// envListenPID is the environment variable with the PID of the process
+	// the socket activation file descriptors are meant for.
+	envListenPID = "LISTEN_PID"
+
+	// envListenFDs is the environment variable with the number of file
+	// descriptors passed by socket activation.
+	envListenFDs = "LISTEN_FDS"
+
+	// listenFDsStart is the first file descriptor passed by socket activation.
+	listenFDsStart = 3
+)
+
+// fileListener is implemented by the listeners that can return a duplicate
+// of their file descriptor (*net.TCPListener and *net.UnixListener).
+type fileListener interface {
+	File() (*os.File, error)
+}
+
+// ListenerFile returns a duplicate of the file descriptor of the server
+// listener, to be handed over to a child process (e.g. via
+// exec.Cmd.ExtraFiles) that serves it with [WithListenerFD] for a
+// zero-downtime restart. The caller must close the returned file.
+//
+// Both processes accept connections until the parent is shut down: the
+// listener stays open in the child, and a Unix domain socket file is no longer
+// removed when the parent stops.
+func (h *HTTPServer) ListenerFile() (*os.File, error) {
+	fl, ok := h.baseListener.(fileListener)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrListenerFile, h.baseListener)
+	}
+
+	// The socket file now belongs to the child process as well.
+	ul, isUnix := fl.(*net.UnixListener)
+	if isUnix {
+		ul.SetUnlinkOnClose(false)
+	}
+
+	f, err := fl.File()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListenerFile, err)
+	}
+
+	return f, nil
+}
+
+// socketActivationFD returns the first file descriptor passed by socket
+// activation (systemd or compatible), as described by the LISTEN_FDS and
+// LISTEN_PID environment variables. Both variables are unset, so the child
+// processes do not take the descriptors for their own.
+func socketActivationFD() (uintptr, error) {
+	pid := os.Getenv(envListenPID)
+	fds := os.Getenv(envListenFDs)
+
+	_ = os.Unsetenv(envListenPID)
+	_ = os.Unsetenv(envListenFDs)
+
+	if pid != strconv.Itoa(os.Getpid()) {
+		return 0, fmt.Errorf("%w: %s=%q is not the current process", ErrNoListenerFD, envListenPID, pid)
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: %s=%q", ErrNoListenerFD, envListenFDs, fds)
+	}
+
+	return listenFDsStart, nil
+}
+
+// netListener creates the server listener from the configured inherited file
+// descriptor, Unix domain socket, or TCP address, and wraps it with TLS when
+// configured. It returns the wrapped listener and the underlying one. The TCP
+// and Unix listeners are created through net.ListenConfig so they honor the
+// caller's context.
+func netListener(ctx context.Context, cfg *config) (net.Listener, net.Listener, error) {
+	tlsConfig := cfg.tlsConfig
+
+	// tls.NewListener performs no configuration validation, so replicate the
+	// tls.Listen check upfront (before binding, so nothing can leak) to reject
+	// unusable TLS configurations at startup instead of failing every
+	// handshake at runtime.
+	if tlsConfig != nil && len(tlsConfig.Certificates) == 0 &&
+		tlsConfig.GetCertificate == nil && tlsConfig.GetConfigForClient == nil {
+		return nil, nil, fmt.Errorf("failed creating the http server address listener: %w", ErrInvalidTLSConfig)
+	}
+
+	var (
+		ls  net.Listener
+		err error
+	)
+
+	switch {
+	case cfg.listenerFile != nil:
+		ls, err = net.FileListener(cfg.listenerFile)
+
+		// net.FileListener works on a duplicate of the descriptor.
+		_ = cfg.listenerFile.Close()
+	case cfg.unixSocketPath != "":
+		ls, err = unixListener(ctx, cfg.unixSocketPath, cfg.unixSocketMode)
+	default:
+		var lc net.ListenConfig
+
+		ls, err = lc.Listen(ctx, "tcp", cfg.serverAddr)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating the http server address listener: %w", err)
+	}
+
+	if tlsConfig != nil {
+		return tls.NewListener(ls, tlsConfig), ls, nil
+	}
+
+	return ls, ls, nil
+}
+
+// unixListener listens on the Unix domain socket path, replacing a stale
+// socket file left by a previous process, and sets the socket file mode when
+// not zero. A socket file still accepting connections is left to its server.
+func unixListener(ctx context.Context, path string, mode os.FileMode) (net.Listener, error) {
+	fi, err := os.Lstat(path)
+	if err == nil && fi.Mode()&os.ModeSocket != 0 {
+		err = removeStaleSocket(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lc net.ListenConfig
+
+	ls, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if mode == 0 {
+		return ls, nil
+	}
+
+	err = os.Chmod(path, mode)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed setting the unix socket mode: %w", err), ls.Close())
+	}
+
+	return ls, nil
+}
+
+// removeStaleSocket removes the socket file at path when no server accepts
+// connections on it, and fails with [ErrUnixSocketInUse] otherwise.
+func removeStaleSocket(ctx context.Context, path string) error {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err == nil {
+		_ = conn.Close()
+
+		return fmt.Errorf("%w: %s", ErrUnixSocketInUse, path)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed probing the unix socket: %w", err)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("failed removing the stale unix socket: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,438 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startPingServer starts a server with the ping route and the options opts,
+// and registers its shutdown.
+func startPingServer(t *testing.T, opts ...Option) *HTTPServer {
+	t.Helper()
+
+	shutdownWG := &sync.WaitGroup{}
+
+	opts = append([]Option{
+		WithEnableDefaultRoutes(PingRoute),
+		WithShutdownWaitGroup(shutdownWG),
+		WithShutdownTimeout(1 * time.Second),
+	}, opts...)
+
+	h, err := New(t.Context(), NopBinder(), opts...)
+	require.NoError(t, err)
+
+	h.StartServer()
+
+	t.Cleanup(func() {
+		require.NoError(t, h.Shutdown(context.Background()))
+		shutdownWG.Wait()
+	})
+
+	return h
+}
+
+// getPing sends a ping request with the client c to baseURL and returns the
+// response protocol major version.
+func getPing(t *testing.T, c *http.Client, baseURL string) int {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, baseURL+pingHandlerPath, nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return resp.ProtoMajor
+}
+
+func TestNew_unixSocket(t *testing.T) {
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "hs") //nolint:usetesting // keep the socket path short
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "http.sock")
+
+	// leave a stale socket file behind
+	stale, err := net.Listen("unix", path) //nolint:noctx
+	require.NoError(t, err)
+
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	shutdownWG := &sync.WaitGroup{}
+
+	h, err := New(
+		t.Context(),
+		NopBinder(),
+		WithUnixSocket(path, 0o600),
+		WithEnableDefaultRoutes(PingRoute),
+		WithShutdownWaitGroup(shutdownWG),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "unix", h.Addr().Network())
+	require.Equal(t, path, h.Addr().String())
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	h.StartServer()
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+
+	require.Equal(t, 1, getPing(t, c, "http://unix"))
+
+	require.NoError(t, h.Shutdown(t.Context()))
+	shutdownWG.Wait()
+
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist, "the socket file must be removed on shutdown")
+}
+
+func TestNew_unixSocketErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// a regular file at the socket path is not replaced
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	h, err := New(t.Context(), NopBinder(), WithUnixSocket(path, 0))
+	require.Error(t, err)
+	require.Nil(t, h)
+
+	// missing parent directory
+	h, err = New(t.Context(), NopBinder(), WithUnixSocket(filepath.Join(dir, "missing", "s"), 0o600))
+	require.Error(t, err)
+	require.Nil(t, h)
+}
+
+func Test_unixListener_errors(t *testing.T) {
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "hs") //nolint:usetesting // keep the socket path short
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	// a stale socket in a read-only directory cannot be removed
+	ro := filepath.Join(dir, "ro")
+	require.NoError(t, os.Mkdir(ro, 0o700))
+
+	path := filepath.Join(ro, "s")
+	stale, err := net.Listen("unix", path) //nolint:noctx
+	require.NoError(t, err)
+
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	require.NoError(t, os.Chmod(ro, 0o500))
+
+	t.Cleanup(func() { _ = os.Chmod(ro, 0o700) })
+
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores the directory permissions")
+	}
+
+	_, err = unixListener(t.Context(), path, 0)
+	require.ErrorContains(t, err, "failed removing the stale unix socket")
+}
+
+func TestNew_unixSocketInUse(t *testing.T) {
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "hs") //nolint:usetesting // keep the socket path short
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "http.sock")
+
+	live, err := net.Listen("unix", path) //nolint:noctx
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = live.Close() })
+
+	h, err := New(t.Context(), NopBinder(), WithUnixSocket(path, 0))
+	require.ErrorIs(t, err, ErrUnixSocketInUse)
+	require.Nil(t, h)
+
+	// the socket of the running server is left in place
+	conn, err := net.Dial("unix", path) //nolint:noctx
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestNew_h2c(t *testing.T) {
+	t.Parallel()
+
+	h2cTransport := &http.Transport{Protocols: new(http.Protocols)}
+	h2cTransport.Protocols.SetUnencryptedHTTP2(true)
+
+	c := &http.Client{Transport: h2cTransport}
+
+	h := startPingServer(t, WithServerAddr(":0"), WithH2C())
+	require.Equal(t, 2, getPing(t, c, testBaseURL(t, h)))
+	require.Equal(t, 1, getPing(t, http.DefaultClient, testBaseURL(t, h)))
+
+	h = startPingServer(t, WithServerAddr(":0"))
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, testBaseURL(t, h)+pingHandlerPath, nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+
+	require.Error(t, err, "h2c must be disabled by default")
+}
+
+func TestHTTPServer_ListenerFile(t *testing.T) {
+	t.Parallel()
+
+	parent := startPingServer(t, WithServerAddr(":0"))
+
+	f, err := parent.ListenerFile()
+	require.NoError(t, err)
+
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// the child serves the same socket
+	child := startPingServer(t, WithListenerFD(uintptr(fd)))
+	require.Equal(t, parent.Addr().String(), child.Addr().String())
+
+	// the parent stops, the child keeps serving
+	require.NoError(t, parent.Shutdown(t.Context()))
+
+	c := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	require.Equal(t, 1, getPing(t, c, testBaseURL(t, child)))
+}
+
+func TestHTTPServer_ListenerFile_unixSocket(t *testing.T) {
+	t.Parallel()
+
+	dir, err := os.MkdirTemp("", "hs") //nolint:usetesting // keep the socket path short
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "http.sock")
+
+	parent := startPingServer(t, WithUnixSocket(path, 0))
+
+	f, err := parent.ListenerFile()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, parent.Shutdown(t.Context()))
+
+	_, err = os.Stat(path)
+	require.NoError(t, err, "the handed over socket file must not be removed")
+}
+
+func TestHTTPServer_ListenerFile_errors(t *testing.T) {
+	t.Parallel()
+
+	h := &HTTPServer{baseListener: mockListenerErr{}}
+
+	f, err := h.ListenerFile()
+	require.ErrorIs(t, err, ErrListenerFile)
+	require.Nil(t, f)
+
+	ls, err := net.Listen("tcp", "127.0.0.1:0") //nolint:noctx
+	require.NoError(t, err)
+	require.NoError(t, ls.Close())
+
+	h = &HTTPServer{baseListener: ls}
+
+	f, err = h.ListenerFile()
+	require.ErrorIs(t, err, ErrListenerFile)
+	require.Nil(t, f)
+}
+
+func TestNew_tlsListenerFile(t *testing.T) {
+	t.Parallel()
+
+	h := startPingServer(t, WithServerAddr(":0"), WithTLSConfig(&tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return nil, nil }, //nolint:nilnil
+	}))
+
+	f, err := h.ListenerFile()
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestNew_listenerFileErrors(t *testing.T) {
+	t.Parallel()
+
+	// not a socket
+	f, err := os.CreateTemp(t.TempDir(), "fd")
+	require.NoError(t, err)
+
+	h, err := New(t.Context(), NopBinder(), func(cfg *config) error {
+		cfg.setListener("", 0, f)
+
+		return nil
+	})
+	require.Error(t, err)
+	require.Nil(t, h)
+
+	// released when New fails before creating the listener
+	f, err = os.CreateTemp(t.TempDir(), "fd")
+	require.NoError(t, err)
+
+	h, err = New(t.Context(), NopBinder(), func(cfg *config) error {
+		cfg.setListener("", 0, f)
+
+		return nil
+	}, WithEnableDefaultRoutes(DefaultRoute("bogus")))
+	require.ErrorIs(t, err, ErrUnknownDefaultRoute)
+	require.Nil(t, h)
+	require.ErrorIs(t, f.Close(), os.ErrClosed)
+
+	f, err = os.CreateTemp(t.TempDir(), "fd")
+	require.NoError(t, err)
+
+	binder := &routeBinder{routes: []Route{{Method: http.MethodGet, Path: "/a"}}}
+
+	h, err = New(t.Context(), binder, func(cfg *config) error {
+		cfg.setListener("", 0, f)
+
+		return nil
+	})
+	require.ErrorIs(t, err, ErrNilRouteHandler)
+	require.Nil(t, h)
+	require.ErrorIs(t, f.Close(), os.ErrClosed)
+}
+
+func TestWithUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+
+	require.Error(t, WithUnixSocket("", 0)(cfg))
+
+	require.NoError(t, WithUnixSocket("/tmp/a.sock", 0o660)(cfg))
+	require.Equal(t, "/tmp/a.sock", cfg.unixSocketPath)
+	require.Equal(t, os.FileMode(0o660), cfg.unixSocketMode)
+	require.Equal(t, "unix:/tmp/a.sock", cfg.listenAddr())
+
+	require.NoError(t, WithServerAddr(":1234")(cfg))
+	require.Empty(t, cfg.unixSocketPath)
+	require.Equal(t, ":1234", cfg.listenAddr())
+}
+
+func TestWithListenerFD(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+
+	require.ErrorIs(t, WithListenerFD(^uintptr(0))(cfg), ErrNoListenerFD)
+	require.Nil(t, cfg.listenerFile)
+
+	f, err := os.CreateTemp(t.TempDir(), "fd")
+	require.NoError(t, err)
+
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, WithListenerFD(uintptr(fd))(cfg))
+	require.NotNil(t, cfg.listenerFile)
+	require.Equal(t, "fd:"+strconv.Itoa(fd), cfg.listenAddr())
+
+	lf := cfg.listenerFile
+
+	// replacing the listener releases the file descriptor
+	require.NoError(t, WithUnixSocket("/tmp/a.sock", 0)(cfg))
+	require.Nil(t, cfg.listenerFile)
+	require.ErrorIs(t, lf.Close(), os.ErrClosed)
+}
+
+//nolint:paralleltest // modifies the process environment
+func Test_socketActivationFD(t *testing.T) {
+	tests := []struct {
+		name    string
+		pid     string
+		fds     string
+		wantErr bool
+	}{
+		{name: "not activated", wantErr: true},
+		{name: "invalid count", fds: "x", wantErr: true},
+		{name: "no descriptors", fds: "0", wantErr: true},
+		{name: "other process", pid: "1", fds: "1", wantErr: true},
+		{name: "missing pid", fds: "1", wantErr: true},
+		{name: "activated", pid: strconv.Itoa(os.Getpid()), fds: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envListenPID, tt.pid)
+			t.Setenv(envListenFDs, tt.fds)
+
+			fd, err := socketActivationFD()
+
+			// the variables are not inherited by the child processes
+			_, ok := os.LookupEnv(envListenPID)
+			require.False(t, ok)
+
+			_, ok = os.LookupEnv(envListenFDs)
+			require.False(t, ok)
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrNoListenerFD)
+
+				// the option fails without touching the configuration
+				cfg := &config{}
+				require.ErrorIs(t, WithSocketActivation()(cfg), ErrNoListenerFD)
+				require.Nil(t, cfg.listenerFile)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, uintptr(listenFDsStart), fd)
+		})
+	}
+}
+
+func TestWithH2C(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	require.NoError(t, WithH2C()(cfg))
+	require.True(t, cfg.h2c)
+}
@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/logutil"
+)
+
+// maxLogLevelRequestBytes is the maximum size of a log level route request body.
+const maxLogLevelRequestBytes = 4 << 10
+
+// LogLevelRequest is the JSON body of a PUT request to the log level route.
+type LogLevelRequest struct {
+	// Level is the new level (see logutil.ParseLevel). An empty level with a
+	// Name removes the override of the named logger.
+	Level string `json:"level"`
+
+	// Name is the logger name (see logutil.LoggerNameKey) to override. An empty
+	// name sets the level of every logger without an override.
+	Name string `json:"name,omitempty"`
+
+	// TTL is the duration (e.g. "15m") after which the change reverts. An
+	// empty TTL makes the change permanent.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// logLevelHandler returns the log level route handler for ctl: a GET request
+// returns the logutil.LevelState, and a PUT request applies a LogLevelRequest
+// and returns the new state.
+func (c *config) logLevelHandler(ctl *logutil.LevelController) http.HandlerFunc {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				err := applyLogLevelRequest(ctl, w, r)
+				if err != nil {
+					c.httpresp.SendText(r.Context(), w, http.StatusBadRequest, err.Error())
+
+					return
+				}
+
+				c.logger.With(slog.Any("levels", ctl.State())).WarnContext(r.Context(), "log levels changed")
+			}
+
+			c.httpresp.SendJSON(r.Context(), w, http.StatusOK, ctl.State())
+		},
+	)
+}
+
+// applyLogLevelRequest decodes the LogLevelRequest of r and applies it to ctl.
+func applyLogLevelRequest(ctl *logutil.LevelController, w http.ResponseWriter, r *http.Request) error {
+	var req LogLevelRequest
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLogLevelRequestBytes))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&req)
+	if err != nil {
+		return fmt.Errorf("invalid log level request: %w", err)
+	}
+
+	var ttl time.Duration
+
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl < 0 {
+			return fmt.Errorf("invalid log level ttl: %q", req.TTL)
+		}
+	}
+
+	if req.Level == "" {
+		if req.Name == "" {
+			return errors.New("log level is required")
+		}
+
+		ctl.ResetNamedLevel(req.Name)
+
+		return nil
+	}
+
+	level, err := logutil.ParseLevel(req.Level)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if req.Name == "" {
+		return ctl.SetLevel(level, ttl) //nolint:wrapcheck
+	}
+
+	return ctl.SetNamedLevel(req.Name, level, ttl) //nolint:wrapcheck
+}
@@ -0,0 +1,192 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/logutil"
+)
+
+func Test_logLevelHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		setup      func(ctl *logutil.LevelController)
+		wantStatus int
+		wantLevel  string
+		wantOvr    map[string]string
+		wantTTL    bool
+		wantBody   string
+	}{
+		{
+			name:       "get",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLevel:  "info",
+		},
+		{
+			name:       "set level",
+			method:     http.MethodPut,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  "debug",
+		},
+		{
+			name:       "set level with ttl",
+			method:     http.MethodPut,
+			body:       `{"level":"trace","ttl":"1h"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  "trace",
+			wantTTL:    true,
+		},
+		{
+			name:       "set named level",
+			method:     http.MethodPut,
+			body:       `{"level":"error","name":"db"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  "info",
+			wantOvr:    map[string]string{"db": "error"},
+		},
+		{
+			name:   "reset named level",
+			method: http.MethodPut,
+			body:   `{"name":"db"}`,
+			setup: func(ctl *logutil.LevelController) {
+				_ = ctl.SetNamedLevel("db", logutil.LevelDebug, 0)
+				_ = ctl.SetNamedLevel("http", logutil.LevelDebug, 0)
+			},
+			wantStatus: http.StatusOK,
+			wantLevel:  "info",
+			wantOvr:    map[string]string{"http": "debug"},
+		},
+		{
+			name:       "invalid json",
+			method:     http.MethodPut,
+			body:       `{"level":`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid log level request",
+		},
+		{
+			name:       "unknown field",
+			method:     http.MethodPut,
+			body:       `{"level":"debug","other":1}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid log level request",
+		},
+		{
+			name:       "too large",
+			method:     http.MethodPut,
+			body:       `{"level":"` + strings.Repeat("a", maxLogLevelRequestBytes) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid log level request",
+		},
+		{
+			name:       "invalid ttl",
+			method:     http.MethodPut,
+			body:       `{"level":"debug","ttl":"soon"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid log level ttl",
+		},
+		{
+			name:       "negative ttl",
+			method:     http.MethodPut,
+			body:       `{"level":"debug","ttl":"-1m"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid log level ttl",
+		},
+		{
+			name:       "missing level",
+			method:     http.MethodPut,
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "log level is required",
+		},
+		{
+			name:       "invalid level",
+			method:     http.MethodPut,
+			body:       `{"level":"loud"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctl := logutil.NewLevelController(logutil.LevelInfo)
+			if tt.setup != nil {
+				tt.setup(ctl)
+			}
+
+			cfg := defaultConfig()
+			require.NoError(t, WithLogLevelController(ctl)(cfg))
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(t.Context(), tt.method, logLevelHandlerPath, strings.NewReader(tt.body))
+
+			cfg.logLevelHandlerFunc.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+
+			if tt.wantStatus != http.StatusOK {
+				require.Contains(t, rr.Body.String(), tt.wantBody)
+				require.Equal(t, logutil.LevelInfo, ctl.Level(), "a rejected request must not change the level")
+
+				return
+			}
+
+			var state logutil.LevelState
+
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &state))
+			require.Equal(t, tt.wantLevel, state.Level)
+			require.Equal(t, tt.wantTTL, state.Expires != nil)
+
+			if tt.wantTTL {
+				require.True(t, ctl.State().Expires.Equal(*state.Expires))
+			}
+
+			ovr := make(map[string]string)
+			for name, s := range state.Overrides {
+				ovr[name] = s.Level
+			}
+
+			if tt.wantOvr == nil {
+				tt.wantOvr = map[string]string{}
+			}
+
+			require.Equal(t, tt.wantOvr, ovr)
+		})
+	}
+}
+
+func Test_logLevelRoute(t *testing.T) {
+	t.Parallel()
+
+	ctl := logutil.NewLevelController(logutil.LevelInfo)
+
+	cfg := defaultConfig()
+	cfg.defaultEnabledRoutes = []DefaultRoute{LogLevelRoute}
+
+	require.NoError(t, WithLogLevelController(ctl)(cfg))
+
+	for _, r := range newDefaultRoutes(cfg) {
+		cfg.router.Handler(r.Method, r.Path, r.Handler)
+	}
+
+	rr := httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, httptest.NewRequestWithContext(t.Context(), http.MethodPut, "/loglevel", strings.NewReader(`{"level":"warning"}`)))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, logutil.LevelWarning, ctl.Level())
+
+	rr = httptest.NewRecorder()
+	cfg.router.ServeHTTP(rr, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/loglevel", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"level":"warning"}`, rr.Body.String())
+}
@@ -132,6 +132,21 @@ func RequestInjectHandler(
 	return http.HandlerFunc(fn)
 }
 
+// RequestLogger returns logger (or slog.Default() when nil) enriched with the
+// trace ID, method and path of r, the fields identifying the request in the
+// route log, for the entries logged by a middleware about r.
+func RequestLogger(logger *slog.Logger, r *http.Request) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return logger.With(
+		slog.String(traceid.DefaultLogKey, traceid.FromContext(r.Context(), "")),
+		slog.String("request_method", r.Method),
+		slog.String("request_path", r.URL.Path),
+	)
+}
+
 // requestInjectDefaults replaces nil RequestInjectHandler dependencies with
 // safe defaults. The redaction fallback must fail safe: it defaults to the
 // same redacting function used by defaultConfig, never to an identity function.
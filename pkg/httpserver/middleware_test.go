@@ -260,6 +260,25 @@ func TestRequestInjectHandler_insecureNoRedaction(t *testing.T) {
 	require.NotContains(t, outlog, redact.RedactionMarker, "nothing must be redacted")
 }
 
+func TestRequestLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	r := httptest.NewRequest(http.MethodPost, "/items?secret=1", nil)
+	r = r.WithContext(traceid.NewContext(r.Context(), "trace-1"))
+
+	RequestLogger(slog.New(slog.NewJSONHandler(&buf, nil)), r).Info("event")
+
+	out := buf.String()
+	require.Contains(t, out, `"`+traceid.DefaultLogKey+`":"trace-1"`)
+	require.Contains(t, out, `"request_method":"POST"`)
+	require.Contains(t, out, `"request_path":"/items"`)
+	require.NotContains(t, out, "secret")
+
+	require.NotNil(t, RequestLogger(nil, r))
+}
+
 func TestRedactRequestURI(t *testing.T) {
 	t.Parallel()
 
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"slices"
 	"sync"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/tecnickcom/nurago/pkg/logutil"
 )
 
 // Option configures an [HTTPServer] instance.
@@ -32,7 +34,9 @@ func WithRouter(r *httprouter.Router) Option {
 	}
 }
 
-// WithServerAddr sets the address the httpServer will bind to.
+// WithServerAddr sets the TCP address the httpServer will bind to.
+// It replaces a listener set by WithUnixSocket, WithListenerFD or
+// WithSocketActivation.
 func WithServerAddr(addr string) Option {
 	return func(cfg *config) error {
 		err := validateAddr(addr)
@@ -41,6 +45,69 @@ func WithServerAddr(addr string) Option {
 		}
 
 		cfg.serverAddr = addr
+		cfg.setListener("", 0, nil)
+
+		return nil
+	}
+}
+
+// WithUnixSocket makes the httpServer listen on the Unix domain socket at
+// path instead of a TCP address. A stale socket file at path (e.g. left by a
+// crashed process) is replaced, while a socket still accepting connections
+// makes New fail with ErrUnixSocketInUse. A non-zero mode sets the
+// permissions of the socket file (e.g. 0o660 to allow a proxy in the same
+// group). The socket file is removed on shutdown.
+func WithUnixSocket(path string, mode os.FileMode) Option {
+	return func(cfg *config) error {
+		if path == "" {
+			return errors.New("unix socket path is required")
+		}
+
+		cfg.setListener(path, mode, nil)
+
+		return nil
+	}
+}
+
+// WithListenerFD makes the httpServer serve the listening socket with the
+// inherited file descriptor fd (TCP or Unix domain), e.g. passed by a parent
+// process calling [HTTPServer.ListenerFile] for a zero-downtime restart, or by
+// a service manager. The file descriptor is closed by New.
+func WithListenerFD(fd uintptr) Option {
+	return func(cfg *config) error {
+		f := os.NewFile(fd, "listener")
+		if f == nil {
+			return fmt.Errorf("%w: invalid file descriptor %d", ErrNoListenerFD, fd)
+		}
+
+		cfg.setListener("", 0, f)
+
+		return nil
+	}
+}
+
+// WithSocketActivation makes the httpServer serve the first listening socket
+// passed by socket activation (systemd or compatible), as described by the
+// LISTEN_FDS and LISTEN_PID environment variables, which are then unset so
+// the child processes do not inherit them. It returns ErrNoListenerFD when
+// the process was not socket-activated.
+func WithSocketActivation() Option {
+	return func(cfg *config) error {
+		fd, err := socketActivationFD()
+		if err != nil {
+			return err
+		}
+
+		return WithListenerFD(fd)(cfg)
+	}
+}
+
+// WithH2C enables HTTP/2 without TLS (h2c) with prior knowledge, alongside
+// HTTP/1.1, for plain-text connections from trusted proxies (e.g. a sidecar
+// proxy terminating TLS). The "Upgrade: h2c" mechanism is not supported.
+func WithH2C() Option {
+	return func(cfg *config) error {
+		cfg.h2c = true
 
 		return nil
 	}
@@ -243,7 +310,7 @@ func WithTLSConfig(tlsConfig *tls.Config) Option {
 // An unknown route identifier returns ErrUnknownDefaultRoute.
 func WithEnableDefaultRoutes(ids ...DefaultRoute) Option {
 	return func(cfg *config) error {
-		known := allDefaultRoutes()
+		known := knownDefaultRoutes()
 
 		for _, id := range ids {
 			if !slices.Contains(known, id) {
@@ -257,7 +324,9 @@ func WithEnableDefaultRoutes(ids ...DefaultRoute) Option {
 	}
 }
 
-// WithEnableAllDefaultRoutes enables all default routes on the server.
+// WithEnableAllDefaultRoutes enables all default routes on the server, except
+// LogLevelRoute, which changes the service behavior and must be enabled
+// explicitly with WithEnableDefaultRoutes.
 func WithEnableAllDefaultRoutes() Option {
 	return func(cfg *config) error {
 		cfg.defaultEnabledRoutes = allDefaultRoutes()
@@ -293,6 +362,24 @@ func WithIPHandlerFunc(handler http.HandlerFunc) Option {
 	}
 }
 
+// WithLogLevelController sets the controller of the log levels read and
+// changed by the log level route (see LogLevelRoute), which is otherwise not
+// registered. See logutil.WithDynamicLevel.
+//
+// The route changes the service behavior: enable it only on an internal or
+// administrative listener, or protect it with authentication middleware.
+func WithLogLevelController(ctl *logutil.LevelController) Option {
+	return func(cfg *config) error {
+		if ctl == nil {
+			return errors.New("log level controller is required")
+		}
+
+		cfg.logLevelHandlerFunc = cfg.logLevelHandler(ctl)
+
+		return nil
+	}
+}
+
 // WithMetricsHandlerFunc replaces the default metrics handler function.
 func WithMetricsHandlerFunc(handler http.HandlerFunc) Option {
 	return func(cfg *config) error {
@@ -388,6 +475,36 @@ func WithMiddlewareFn(fn ...MiddlewareFn) Option {
 	}
 }
 
+// WithCORS applies the Cross-Origin Resource Sharing policy cfg to every
+// route, including the not-found, method-not-allowed and panic responses (see
+// [CORSMiddlewareFn]). The router answers the preflight requests of every
+// path without an explicit OPTIONS route, allowing (unless
+// cfg.AllowedMethods is set) the methods registered on the path. An invalid
+// configuration returns ErrInvalidCORSConfig.
+func WithCORS(cfg CORSConfig) Option {
+	return func(c *config) error {
+		p, err := newCORSPolicy(cfg)
+		if err != nil {
+			return err
+		}
+
+		c.cors = p
+
+		return nil
+	}
+}
+
+// WithSecurityHeaders sets the security headers of cfg on every response,
+// including the not-found, method-not-allowed and panic responses (see
+// [SecurityHeadersMiddlewareFn] and [DefaultSecurityHeadersConfig]).
+func WithSecurityHeaders(cfg SecurityHeadersConfig) Option {
+	return func(c *config) error {
+		c.securityHeaders = newSecurityHeaders(cfg)
+
+		return nil
+	}
+}
+
 // WithNotFoundHandlerFunc http handler called when no matching route is found.
 func WithNotFoundHandlerFunc(handler http.HandlerFunc) Option {
 	return func(cfg *config) error {
@@ -456,7 +573,7 @@ func WithoutMethodNotAllowedLogger() Option {
 // An unknown route identifier returns ErrUnknownDefaultRoute.
 func WithoutDefaultRouteLogger(routes ...DefaultRoute) Option {
 	return func(cfg *config) error {
-		known := allDefaultRoutes()
+		known := knownDefaultRoutes()
 
 		for _, route := range routes {
 			if !slices.Contains(known, route) {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/logutil"
 )
 
 func TestWithRouter(t *testing.T) {
@@ -305,6 +306,19 @@ func TestWithIPHandlerFunc(t *testing.T) {
 	require.Equal(t, reflect.ValueOf(v).Pointer(), reflect.ValueOf(cfg.ipHandlerFunc).Pointer())
 }
 
+func TestWithLogLevelController(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+
+	err := WithLogLevelController(nil)(cfg)
+	require.Error(t, err)
+
+	err = WithLogLevelController(logutil.NewLevelController(logutil.LevelInfo))(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.logLevelHandlerFunc)
+}
+
 func TestWithMetricsHandlerFunc(t *testing.T) {
 	t.Parallel()
 
@@ -498,3 +512,25 @@ func TestWithLogger(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, v, cfg.logger)
 }
+
+func TestWithCORS(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	err := WithCORS(CORSConfig{})(cfg)
+	require.ErrorIs(t, err, ErrInvalidCORSConfig)
+	require.Nil(t, cfg.cors)
+
+	err = WithCORS(CORSConfig{AllowedOrigins: []string{"*"}})(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.cors)
+}
+
+func TestWithSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	err := WithSecurityHeaders(DefaultSecurityHeadersConfig())(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.securityHeaders)
+}
@@ -18,6 +18,14 @@ const (
 	IPRoute       DefaultRoute = "ip"
 	ipHandlerPath string       = "/ip"
 
+	// LogLevelRoute is the identifier to enable the log level handler, reading
+	// (GET) and changing (PUT) the log levels at runtime. Being mutating, it is
+	// not part of WithEnableAllDefaultRoutes: it must be enabled explicitly with
+	// WithEnableDefaultRoutes, and is only registered when a controller is set
+	// with WithLogLevelController.
+	LogLevelRoute       DefaultRoute = "loglevel"
+	logLevelHandlerPath string       = "/loglevel"
+
 	// MetricsRoute is the identifier to enable the metrics handler.
 	MetricsRoute       DefaultRoute = "metrics"
 	metricsHandlerPath string       = "/metrics"
@@ -35,7 +43,8 @@ const (
 	statusHandlerPath string       = "/status"
 )
 
-// allDefaultRoutes returns a slice with all the default routes.
+// allDefaultRoutes returns a slice with the default routes enabled by
+// WithEnableAllDefaultRoutes: all of them but the mutating LogLevelRoute.
 func allDefaultRoutes() []DefaultRoute {
 	return []DefaultRoute{
 		IndexRoute,
@@ -47,6 +56,11 @@ func allDefaultRoutes() []DefaultRoute {
 	}
 }
 
+// knownDefaultRoutes returns a slice with every default route identifier.
+func knownDefaultRoutes() []DefaultRoute {
+	return append(allDefaultRoutes(), LogLevelRoute)
+}
+
 // newDefaultRoutes creates the default routes based on the configuration.
 // Repeated identifiers are de-duplicated (first occurrence wins).
 func newDefaultRoutes(cfg *config) []Route {
@@ -73,6 +87,28 @@ func newDefaultRoutes(cfg *config) []Route {
 				DisableLogger: disableLogger,
 				Description:   "Returns the public IP address of this service instance.",
 			})
+		case LogLevelRoute:
+			if cfg.logLevelHandlerFunc == nil {
+				// No controller: the route is not exposed.
+				continue
+			}
+
+			routes = append(routes,
+				Route{
+					Method:        http.MethodGet,
+					Path:          logLevelHandlerPath,
+					Handler:       cfg.logLevelHandlerFunc,
+					DisableLogger: disableLogger,
+					Description:   "Returns the log levels.",
+				},
+				Route{
+					Method:        http.MethodPut,
+					Path:          logLevelHandlerPath,
+					Handler:       cfg.logLevelHandlerFunc,
+					DisableLogger: disableLogger,
+					Description:   "Changes the log levels.",
+				},
+			)
 		case MetricsRoute:
 			routes = append(routes, Route{
 				Method:        http.MethodGet,
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/logutil"
 )
 
 func Test_newDefaultRoutes(t *testing.T) {
@@ -13,15 +14,17 @@ func Test_newDefaultRoutes(t *testing.T) {
 
 	cfg := defaultConfig()
 
-	cfg.defaultEnabledRoutes = allDefaultRoutes()
+	cfg.defaultEnabledRoutes = knownDefaultRoutes()
 	cfg.metricsHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
 	cfg.pingHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
 	cfg.pprofHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
 	cfg.statusHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
 	cfg.ipHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
+	cfg.logLevelHandlerFunc = func(_ http.ResponseWriter, _ *http.Request) {}
 
 	cfg.disableDefaultRouteLogger[IndexRoute] = true
 	cfg.disableDefaultRouteLogger[IPRoute] = true
+	cfg.disableDefaultRouteLogger[LogLevelRoute] = true
 	cfg.disableDefaultRouteLogger[MetricsRoute] = true
 	cfg.disableDefaultRouteLogger[PingRoute] = true
 	cfg.disableDefaultRouteLogger[PprofRoute] = true
@@ -34,6 +37,7 @@ func Test_newDefaultRoutes(t *testing.T) {
 		cfg.pprofHandlerFunc,
 		cfg.statusHandlerFunc,
 		cfg.ipHandlerFunc,
+		cfg.logLevelHandlerFunc,
 	}
 
 	boundCount := 0
@@ -48,5 +52,31 @@ func Test_newDefaultRoutes(t *testing.T) {
 		}
 	}
 
-	require.Equal(t, 5, boundCount)
+	require.Equal(t, 7, boundCount)
+}
+
+func Test_newDefaultRoutes_logLevel(t *testing.T) {
+	t.Parallel()
+
+	hasLogLevel := func(routes []Route) bool {
+		for _, r := range routes {
+			if r.Path == logLevelHandlerPath {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	cfg := defaultConfig()
+	require.NoError(t, WithEnableAllDefaultRoutes()(cfg))
+	require.NoError(t, WithLogLevelController(logutil.NewLevelController(logutil.LevelInfo))(cfg))
+	require.False(t, hasLogLevel(newDefaultRoutes(cfg)), "the mutating route must be enabled explicitly")
+
+	cfg = defaultConfig()
+	require.NoError(t, WithEnableDefaultRoutes(LogLevelRoute)(cfg))
+	require.False(t, hasLogLevel(newDefaultRoutes(cfg)), "the route needs a controller")
+
+	require.NoError(t, WithLogLevelController(logutil.NewLevelController(logutil.LevelInfo))(cfg))
+	require.True(t, hasLogLevel(newDefaultRoutes(cfg)))
 }
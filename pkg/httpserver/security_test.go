@@ -0,0 +1,110 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeadersMiddlewareFn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     SecurityHeadersConfig
+		tls     bool
+		proto   string
+		want    map[string]string
+		wantNot []string
+	}{
+		{
+			name: "defaults over TLS",
+			cfg:  DefaultSecurityHeadersConfig(),
+			tls:  true,
+			want: map[string]string{
+				headerStrictTransportSecurity:   "max-age=63072000; includeSubDomains",
+				headerContentTypeOptions:        "nosniff",
+				headerFrameOptions:              "DENY",
+				headerContentSecurityPolicy:     "default-src 'none'; frame-ancestors 'none'",
+				headerReferrerPolicy:            "no-referrer",
+				headerCrossOriginOpenerPolicy:   "same-origin",
+				headerCrossOriginResourcePolicy: "same-origin",
+			},
+			wantNot: []string{headerPermissionsPolicy},
+		},
+		{
+			name:    "defaults over plain HTTP",
+			cfg:     DefaultSecurityHeadersConfig(),
+			want:    map[string]string{headerContentTypeOptions: "nosniff"},
+			wantNot: []string{headerStrictTransportSecurity},
+		},
+		{
+			name: "behind a TLS proxy",
+			cfg: SecurityHeadersConfig{
+				HSTSMaxAge:        time.Hour,
+				HSTSPreload:       true,
+				PermissionsPolicy: "geolocation=()",
+			},
+			proto: "HTTPS",
+			want: map[string]string{
+				headerStrictTransportSecurity: "max-age=3600; preload",
+				headerPermissionsPolicy:       "geolocation=()",
+			},
+			wantNot: []string{headerContentTypeOptions, headerFrameOptions, headerContentSecurityPolicy},
+		},
+		{
+			name:    "empty",
+			tls:     true,
+			wantNot: []string{headerStrictTransportSecurity, headerContentTypeOptions, headerReferrerPolicy},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := SecurityHeadersMiddlewareFn(tt.cfg)(MiddlewareArgs{}, http.HandlerFunc(okHandler))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+
+			if tt.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, r)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			for k, v := range tt.want {
+				require.Equal(t, v, rr.Header().Get(k), k)
+			}
+
+			for _, k := range tt.wantNot {
+				require.Empty(t, rr.Header().Get(k), k)
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersMiddlewareFn_override(t *testing.T) {
+	t.Parallel()
+
+	handler := SecurityHeadersMiddlewareFn(DefaultSecurityHeadersConfig())(MiddlewareArgs{}, http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(headerContentSecurityPolicy, "default-src 'self'")
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, "default-src 'self'", rr.Header().Get(headerContentSecurityPolicy))
+}
@@ -21,12 +21,13 @@ const ReqTimeCtxKey = timeCtxKey("request_time")
 
 // Common HTTP headers and MIME types.
 const (
-	HeaderAuthorization = "Authorization"
-	HeaderAuthBasic     = "Basic "
-	HeaderAuthBearer    = "Bearer "
-	HeaderContentType   = "Content-Type"
-	HeaderAccept        = "Accept"
-	MimeTypeJSON        = "application/json"
+	HeaderAuthorization  = "Authorization"
+	HeaderAuthBasic      = "Basic "
+	HeaderAuthBearer     = "Bearer "
+	HeaderContentType    = "Content-Type"
+	HeaderAccept         = "Accept"
+	HeaderIdempotencyKey = "Idempotency-Key"
+	MimeTypeJSON         = "application/json"
 )
 
 // AddJSONHeaders sets application/json Accept and Content-Type headers on the request.
@@ -0,0 +1,56 @@
+package idempotency_test
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/idempotency"
+)
+
+func ExampleMiddleware_MiddlewareFn() {
+	mw, err := idempotency.New(
+		idempotency.NewMemoryStore(),
+		idempotency.WithRequired(),
+		idempotency.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	orders := 0
+
+	handler := mw.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		orders++
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, "order %d", orders)
+	}))
+
+	send := func(key, body string) {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		fmt.Printf("%d %q replayed=%q\n", rr.Code, rr.Body.String(), rr.Header().Get(idempotency.HeaderReplayed))
+	}
+
+	send("a1", `{"item":"book"}`)
+	send("a1", `{"item":"book"}`) // retry
+	send("a1", `{"item":"pen"}`)  // same key, different request
+	send("", `{"item":"pen"}`)    // missing key
+
+	// Output:
+	// 201 "order 1" replayed=""
+	// 201 "order 1" replayed="true"
+	// 422 "Unprocessable Entity\n" replayed=""
+	// 400 "Bad Request\n" replayed=""
+}
@@ -0,0 +1,163 @@
+/*
+Package idempotency provides an Idempotency-Key middleware for
+[github.com/tecnickcom/nurago/pkg/httpserver], so clients can safely retry
+the requests that are not idempotent by nature (e.g. POST) without repeating
+their side effects.
+
+A client sends a unique key with the request, in the Idempotency-Key header
+(IETF draft draft-ietf-httpapi-idempotency-key-header), and reuses it for
+every retry of the same operation. The [Middleware] records the first
+response (status, headers and body) under that key in a [Store] and:
+
+  - replays the recorded response for a repeated key, with an
+    `Idempotent-Replayed: true` header, without calling the handler again;
+  - rejects with 409 Conflict a repeated key whose first request is still in
+    flight;
+  - rejects with 422 Unprocessable Content a repeated key sent with a
+    different request (method, path, query or body), detected through a
+    SHA-256 fingerprint of the request;
+  - rejects with 400 Bad Request a malformed key, or a missing one when keys
+    are required ([WithRequired]).
+
+It exposes itself as an
+[github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareFn], to be installed
+server-wide (httpserver.WithMiddlewareFn) or on the mutating routes
+(httpserver.Route.Middleware):
+
+	mw, err := idempotency.New(idempotency.NewMemoryStore(), idempotency.WithRequired())
+	// ...
+	route.Middleware = append(route.Middleware, mw.MiddlewareFn)
+
+Only the requests with an idempotency-sensitive method (POST and PATCH by
+default, see [WithMethods]) are handled; the others pass through.
+
+# Recorded Responses
+
+A response is recorded only when it is complete and reusable: server errors
+(5xx), panics, and responses larger than the recording limit
+([WithMaxResponseSize]) release the key instead, so the client can retry the
+operation. The recorded responses are kept for a retention period
+([WithTTL]), while an in-flight lock expires after [WithLockTTL], which
+should exceed the request timeout.
+
+# Stores
+
+[MemoryStore] keeps the records in process, for single-instance services.
+[ScriptStore] keeps them on a Redis-compatible server through atomic Lua
+scripts; [github.com/tecnickcom/nurago/pkg/luascript] binds it to a
+[github.com/tecnickcom/nurago/pkg/redis] or
+[github.com/tecnickcom/nurago/pkg/valkey] client:
+
+	eval, err := luascript.Redis(rc)
+	// ...
+	store, err := idempotency.NewScriptStore(eval, idempotency.DefaultKeyPrefix)
+
+The sqlstore subpackage keeps the records in a database table.
+
+# Clients
+
+[github.com/tecnickcom/nurago/pkg/httpretrier.WithIdempotencyKey] attaches one
+generated key to every attempt of a retried request, and
+[github.com/tecnickcom/nurago/pkg/httpclient.WithIdempotencyKey] attaches a
+fresh key to each request that has none.
+*/
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrKeyRequired is reported when a request lacks the required key.
+	ErrKeyRequired = errors.New("idempotency: missing idempotency key")
+
+	// ErrInvalidKey is reported when a request carries a malformed key.
+	ErrInvalidKey = errors.New("idempotency: invalid idempotency key")
+
+	// ErrInFlight is reported when the first request with the same key is still in flight.
+	ErrInFlight = errors.New("idempotency: request with the same key in flight")
+
+	// ErrMismatch is reported when the same key is reused with a different request.
+	ErrMismatch = errors.New("idempotency: key reused with a different request")
+)
+
+// Record is the state stored under an idempotency key.
+type Record struct {
+	// Token identifies the request holding the key, so only that request can
+	// save or release it.
+	Token string `json:"token"`
+
+	// Fingerprint is the SHA-256 fingerprint of the request.
+	Fingerprint string `json:"fingerprint"`
+
+	// Status is the recorded response status code, 0 while in flight.
+	Status int `json:"status,omitempty"`
+
+	// Header contains the recorded response headers.
+	Header http.Header `json:"header,omitempty"`
+
+	// Body is the recorded response body.
+	Body []byte `json:"body,omitempty"`
+}
+
+// Completed reports whether the record holds a response, rather than an
+// in-flight lock.
+func (rec *Record) Completed() bool {
+	return rec.Status != 0
+}
+
+// Store persists the idempotency records. The implementations must be safe for
+// concurrent use and make Lock atomic.
+type Store interface {
+	// Lock stores the in-flight rec under key for ttl when key is free, and
+	// returns nil. Otherwise it returns the record already stored under key,
+	// leaving it untouched.
+	Lock(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error)
+
+	// Save replaces the in-flight record of key with the completed rec, kept
+	// for ttl, when key is still locked by rec.Token. Otherwise it does
+	// nothing.
+	Save(ctx context.Context, key string, rec *Record, ttl time.Duration) error
+
+	// Unlock deletes the in-flight record of key when key is still locked by
+	// token. Otherwise it does nothing.
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a request with method, URL
+// path and raw query, and body, as a hex string.
+func Fingerprint(method, path, rawQuery string, body []byte) string {
+	h := sha256.New()
+
+	_, _ = io.WriteString(h, method)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, path)
+	_, _ = h.Write([]byte{0})
+	_, _ = io.WriteString(h, rawQuery)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validKey reports whether key is a valid idempotency key: 1 to maxKeyLength
+// visible ASCII characters.
+func validKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+
+	for i := range len(key) {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_Completed(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, (&Record{Token: "t"}).Completed())
+	require.True(t, (&Record{Token: "t", Status: 201}).Completed())
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fp := Fingerprint("POST", "/orders", "a=1", []byte(`{"qty":1}`))
+	require.Len(t, fp, 64)
+	require.Equal(t, fp, Fingerprint("POST", "/orders", "a=1", []byte(`{"qty":1}`)))
+
+	require.NotEqual(t, fp, Fingerprint("PATCH", "/orders", "a=1", []byte(`{"qty":1}`)))
+	require.NotEqual(t, fp, Fingerprint("POST", "/orders/1", "a=1", []byte(`{"qty":1}`)))
+	require.NotEqual(t, fp, Fingerprint("POST", "/orders", "a=2", []byte(`{"qty":1}`)))
+	require.NotEqual(t, fp, Fingerprint("POST", "/orders", "a=1", []byte(`{"qty":2}`)))
+
+	// the fields are delimited, so they cannot shift into each other
+	require.NotEqual(t, Fingerprint("POST", "/a", "b", nil), Fingerprint("POST", "/ab", "", nil))
+}
+
+func TestValidKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{name: "uuid", key: "0190b5a4-7c2e-7d3a-9f0e-1b2c3d4e5f60", want: true},
+		{name: "printable", key: `a!~"z`, want: true},
+		{name: "max length", key: strings.Repeat("k", maxKeyLength), want: true},
+		{name: "empty", key: "", want: false},
+		{name: "too long", key: strings.Repeat("k", maxKeyLength+1), want: false},
+		{name: "space", key: "a b", want: false},
+		{name: "control", key: "a\tb", want: false},
+		{name: "non ascii", key: "clé", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, validKey(tt.key))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is the minimum time between two sweeps of the expired
+// [MemoryStore] entries.
+const memorySweepInterval = time.Minute
+
+// memoryEntry is a record with its expiration time.
+type memoryEntry struct {
+	rec     *Record
+	expires time.Time
+}
+
+// MemoryStore is an in-process [Store], suitable for services running as a
+// single instance. The expired records are dropped in a sweep run at most once
+// per minute.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+// NewMemoryStore returns an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		nowFn:   time.Now,
+	}
+}
+
+// Lock implements [Store].
+func (s *MemoryStore) Lock(_ context.Context, key string, rec *Record, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.nowFn()
+	s.sweep(now)
+
+	if cur, ok := s.get(key, now); ok {
+		return cur, nil
+	}
+
+	s.entries[key] = memoryEntry{rec: cloneRecord(rec), expires: now.Add(ttl)}
+
+	return nil, nil //nolint:nilnil // a nil record reports the acquired lock
+}
+
+// Save implements [Store].
+func (s *MemoryStore) Save(_ context.Context, key string, rec *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.nowFn()
+
+	if cur, ok := s.get(key, now); ok && !cur.Completed() && cur.Token == rec.Token {
+		s.entries[key] = memoryEntry{rec: cloneRecord(rec), expires: now.Add(ttl)}
+	}
+
+	return nil
+}
+
+// Unlock implements [Store].
+func (s *MemoryStore) Unlock(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, ok := s.get(key, s.nowFn()); ok && !cur.Completed() && cur.Token == token {
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+// get returns a copy of the unexpired record of key. The caller must hold the
+// lock.
+func (s *MemoryStore) get(key string, now time.Time) (*Record, bool) {
+	entry, ok := s.entries[key]
+	if !ok || !entry.expires.After(now) {
+		return nil, false
+	}
+
+	return cloneRecord(entry.rec), true
+}
+
+// sweep drops the expired entries. The caller must hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !entry.expires.After(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// cloneRecord returns a deep copy of rec, so the stored records are not shared
+// with the callers.
+func cloneRecord(rec *Record) *Record {
+	c := *rec
+	c.Header = rec.Header.Clone()
+
+	if rec.Body != nil {
+		c.Body = append([]byte(nil), rec.Body...)
+	}
+
+	return &c
+}
@@ -0,0 +1,116 @@
+package idempotency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.nowFn = func() time.Time { return now }
+
+	lock := &Record{Token: "t1", Fingerprint: "fp"}
+
+	cur, err := s.Lock(ctx, "k", lock, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+
+	// the stored record is a copy
+	lock.Fingerprint = "changed"
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t2", Fingerprint: "fp"}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, &Record{Token: "t1", Fingerprint: "fp"}, cur)
+
+	// another token can neither save nor unlock
+	require.NoError(t, s.Save(ctx, "k", &Record{Token: "t2", Fingerprint: "fp", Status: 200}, time.Hour))
+	require.NoError(t, s.Unlock(ctx, "k", "t2"))
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t3"}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "t1", cur.Token)
+	require.False(t, cur.Completed())
+
+	done := &Record{Token: "t1", Fingerprint: "fp", Status: 201, Header: http.Header{"X-Id": {"1"}}, Body: []byte("ok")}
+	require.NoError(t, s.Save(ctx, "k", done, time.Hour))
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t4"}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, done, cur)
+
+	// a completed record cannot be unlocked or saved again
+	require.NoError(t, s.Unlock(ctx, "k", "t1"))
+	require.NoError(t, s.Save(ctx, "k", &Record{Token: "t1", Status: 500}, time.Hour))
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t5"}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, done, cur)
+
+	// the completed record expires with its TTL
+	now = now.Add(time.Hour)
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t6"}, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+
+	require.NoError(t, s.Unlock(ctx, "k", "t6"))
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t7"}, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+}
+
+func TestMemoryStore_lockExpiration(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.nowFn = func() time.Time { return now }
+
+	cur, err := s.Lock(ctx, "k", &Record{Token: "t1"}, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+
+	now = now.Add(time.Minute)
+
+	// the expired lock can no longer be saved
+	require.NoError(t, s.Save(ctx, "k", &Record{Token: "t1", Status: 200}, time.Hour))
+
+	cur, err = s.Lock(ctx, "k", &Record{Token: "t2"}, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.nowFn = func() time.Time { return now }
+
+	_, err := s.Lock(ctx, "a", &Record{Token: "t"}, time.Second)
+	require.NoError(t, err)
+
+	_, err = s.Lock(ctx, "b", &Record{Token: "t"}, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, s.entries, 2)
+
+	now = now.Add(2 * memorySweepInterval)
+
+	_, err = s.Lock(ctx, "c", &Record{Token: "t"}, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, s.entries, 2)
+	require.NotContains(t, s.entries, "a")
+}
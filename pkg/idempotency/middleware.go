@@ -0,0 +1,296 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+	"github.com/tecnickcom/nurago/pkg/random"
+)
+
+const (
+	// HeaderReplayed marks a replayed response.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// DefaultTTL is the default retention of the recorded responses.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultLockTTL is the default expiration of an in-flight lock.
+	DefaultLockTTL = 5 * time.Minute
+
+	// DefaultMaxResponseSize is the default maximum size of a recorded response body.
+	DefaultMaxResponseSize = 1 << 20
+
+	// DefaultMaxRequestSize is the default maximum size of a handled request body.
+	DefaultMaxRequestSize = 1 << 20
+
+	// maxKeyLength is the maximum length of an idempotency key.
+	maxKeyLength = 255
+
+	// metricsTask is the task label of the idempotency error counters.
+	metricsTask = "idempotency"
+
+	// metricsCodeInFlight is the error counter code of a request rejected while in flight.
+	metricsCodeInFlight = "in_flight"
+
+	// metricsCodeMismatch is the error counter code of a key reused with a different request.
+	metricsCodeMismatch = "mismatch"
+
+	// metricsCodeStoreError is the error counter code of a store failure.
+	metricsCodeStoreError = "store_error"
+)
+
+// ErrNilStore is returned by [New] when the store is nil.
+var ErrNilStore = errors.New("idempotency: nil store")
+
+// ScopeFunc returns the scope of the idempotency key of a request, e.g. the
+// authenticated client, so different clients cannot collide on a key.
+type ScopeFunc func(r *http.Request) string
+
+// ErrorHandlerFunc writes the response of a rejected request with status and
+// the cause err ([ErrKeyRequired], [ErrInvalidKey], [ErrInFlight],
+// [ErrMismatch], or a store or request body error).
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// Middleware enforces the Idempotency-Key semantics on HTTP requests.
+type Middleware struct {
+	store           Store
+	header          string
+	methods         []string
+	required        bool
+	ttl             time.Duration
+	lockTTL         time.Duration
+	maxResponseSize int
+	maxRequestSize  int64
+	scopeFn         ScopeFunc
+	metrics         metrics.Client
+	logger          *slog.Logger
+	failClosed      bool
+	errorHandler    ErrorHandlerFunc
+	rnd             *random.Rnd
+}
+
+// New constructs a Middleware recording the responses in store. By default
+// the POST and PATCH requests carrying an Idempotency-Key header are handled,
+// the responses are kept for [DefaultTTL], and the rejected requests get a
+// plain status response.
+func New(store Store, opts ...Option) (*Middleware, error) {
+	if store == nil {
+		return nil, ErrNilStore
+	}
+
+	m := &Middleware{
+		store:           store,
+		header:          httputil.HeaderIdempotencyKey,
+		methods:         []string{http.MethodPost, http.MethodPatch},
+		ttl:             DefaultTTL,
+		lockTTL:         DefaultLockTTL,
+		maxResponseSize: DefaultMaxResponseSize,
+		maxRequestSize:  DefaultMaxRequestSize,
+		metrics:         &metrics.Default{},
+		logger:          slog.Default(),
+		rnd:             random.New(nil),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(m)
+	}
+
+	if m.metrics == nil {
+		m.metrics = &metrics.Default{}
+	}
+
+	if m.logger == nil {
+		m.logger = slog.Default()
+	}
+
+	if m.errorHandler == nil {
+		httpresp := httputil.NewHTTPResp(m.logger)
+		m.errorHandler = func(w http.ResponseWriter, r *http.Request, status int, _ error) {
+			httpresp.SendStatus(r.Context(), w, status)
+		}
+	}
+
+	return m, nil
+}
+
+// MiddlewareFn implements [httpserver.MiddlewareFn], applying the
+// Idempotency-Key semantics to next.
+func (m *Middleware) MiddlewareFn(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+	logger := args.Logger
+	if logger == nil {
+		logger = m.logger
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(m.methods, r.Method) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		key := r.Header.Get(m.header)
+
+		switch {
+		case key == "" && m.required:
+			m.errorHandler(w, r, http.StatusBadRequest, ErrKeyRequired)
+
+			return
+		case key == "":
+			next.ServeHTTP(w, r)
+
+			return
+		case !validKey(key):
+			m.errorHandler(w, r, http.StatusBadRequest, ErrInvalidKey)
+
+			return
+		}
+
+		body, err := readBody(w, r, m.maxRequestSize)
+		if err != nil {
+			m.errorHandler(w, r, bodyErrorStatus(err), err)
+
+			return
+		}
+
+		if m.scopeFn != nil {
+			if scope := m.scopeFn(r); scope != "" {
+				key = scope + ":" + key
+			}
+		}
+
+		lock := &Record{
+			Token:       m.rnd.UID128().Hex(),
+			Fingerprint: Fingerprint(r.Method, r.URL.Path, r.URL.RawQuery, body),
+		}
+
+		ctx := r.Context()
+
+		cur, err := m.store.Lock(ctx, key, lock, m.lockTTL)
+		if err != nil {
+			m.metrics.IncErrorCounter(metricsTask, args.Path, metricsCodeStoreError)
+			httpserver.RequestLogger(logger, r).ErrorContext(ctx, "idempotency lock failed", slog.Any("error", err))
+
+			if m.failClosed {
+				m.errorHandler(w, r, http.StatusServiceUnavailable, err)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if cur != nil {
+			m.handleExisting(w, r, args.Path, cur, lock.Fingerprint)
+
+			return
+		}
+
+		m.execute(logger, args.Path, w, r, next, key, lock)
+	})
+}
+
+// handleExisting answers a request whose key is already stored with cur.
+func (m *Middleware) handleExisting(w http.ResponseWriter, r *http.Request, route string, cur *Record, fingerprint string) {
+	switch {
+	case cur.Fingerprint != fingerprint:
+		m.metrics.IncErrorCounter(metricsTask, route, metricsCodeMismatch)
+		m.errorHandler(w, r, http.StatusUnprocessableEntity, ErrMismatch)
+	case !cur.Completed():
+		m.metrics.IncErrorCounter(metricsTask, route, metricsCodeInFlight)
+		m.errorHandler(w, r, http.StatusConflict, ErrInFlight)
+	default:
+		replay(w, cur)
+	}
+}
+
+// execute runs next holding the key lock, then records its response, or
+// releases the lock when the response cannot be reused.
+func (m *Middleware) execute(logger *slog.Logger, route string, w http.ResponseWriter, r *http.Request, next http.Handler, key string, lock *Record) {
+	// The request may be canceled by the client once the handler returns, but
+	// the outcome must still be stored.
+	ctx := context.WithoutCancel(r.Context())
+	saved := false
+
+	defer func() {
+		if saved {
+			return
+		}
+
+		if err := m.store.Unlock(ctx, key, lock.Token); err != nil {
+			httpserver.RequestLogger(logger, r).ErrorContext(ctx, "idempotency unlock failed", slog.Any("error", err))
+		}
+	}()
+
+	rw := newRecorder(w, m.maxResponseSize)
+
+	next.ServeHTTP(rw, r)
+
+	rec, ok := rw.record(lock)
+	if !ok {
+		return
+	}
+
+	if err := m.store.Save(ctx, key, rec, m.ttl); err != nil {
+		m.metrics.IncErrorCounter(metricsTask, route, metricsCodeStoreError)
+		httpserver.RequestLogger(logger, r).ErrorContext(ctx, "idempotency save failed", slog.Any("error", err))
+
+		return
+	}
+
+	saved = true
+}
+
+// replay writes the recorded response rec.
+func replay(w http.ResponseWriter, rec *Record) {
+	h := w.Header()
+
+	for k, v := range rec.Header {
+		h[k] = slices.Clone(v)
+	}
+
+	h.Set(HeaderReplayed, "true")
+	w.WriteHeader(rec.Status)
+
+	_, _ = w.Write(rec.Body)
+}
+
+// readBody reads the whole request body, up to limit bytes, and replaces it
+// with a replayable copy for the handler.
+func readBody(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+
+	_ = r.Body.Close()
+
+	if err != nil {
+		return nil, err //nolint:wrapcheck // reported to the error handler as is
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// bodyErrorStatus returns the response status of a request body read error.
+func bodyErrorStatus(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,544 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+type fakeMetrics struct {
+	metrics.Default
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *fakeMetrics) IncErrorCounter(task, operation, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[task+"/"+operation+"/"+code]++
+}
+
+// fakeStore wraps a MemoryStore, injecting errors.
+type fakeStore struct {
+	*MemoryStore
+
+	lockErr   error
+	saveErr   error
+	unlockErr error
+	unlocks   atomic.Int32
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{MemoryStore: NewMemoryStore()}
+}
+
+func (s *fakeStore) Lock(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error) {
+	if s.lockErr != nil {
+		return nil, s.lockErr
+	}
+
+	return s.MemoryStore.Lock(ctx, key, rec, ttl)
+}
+
+func (s *fakeStore) Save(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+
+	return s.MemoryStore.Save(ctx, key, rec, ttl)
+}
+
+func (s *fakeStore) Unlock(ctx context.Context, key, token string) error {
+	s.unlocks.Add(1)
+
+	if s.unlockErr != nil {
+		return s.unlockErr
+	}
+
+	return s.MemoryStore.Unlock(ctx, key, token)
+}
+
+// countingHandler counts its calls and answers 201 with a header and the
+// request body echoed.
+func countingHandler(calls *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+}
+
+func newRequest(method, key, body string) *http.Request {
+	r := httptest.NewRequest(method, "/orders", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Idempotency-Key", key)
+	}
+
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func newTestMiddleware(t *testing.T, store Store, opts ...Option) *Middleware {
+	t.Helper()
+
+	opts = append([]Option{WithLogger(slog.New(slog.DiscardHandler))}, opts...)
+
+	m, err := New(store, opts...)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	m, err := New(nil)
+	require.ErrorIs(t, err, ErrNilStore)
+	require.Nil(t, m)
+
+	m, err = New(NewMemoryStore(), WithMetrics(nil), WithLogger(nil), WithErrorHandler(nil))
+	require.NoError(t, err)
+	require.NotNil(t, m.metrics)
+	require.NotNil(t, m.logger)
+	require.NotNil(t, m.errorHandler)
+	require.Equal(t, "Idempotency-Key", m.header)
+	require.Equal(t, []string{http.MethodPost, http.MethodPatch}, m.methods)
+	require.Equal(t, int64(DefaultMaxRequestSize), m.maxRequestSize)
+}
+
+func TestMiddleware_replay(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	m := newTestMiddleware(t, NewMemoryStore())
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{Path: "/orders"}, countingHandler(&calls))
+
+	// an outer middleware header is not recorded
+	outer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+		h.ServeHTTP(w, r)
+	})
+
+	r := newRequest(http.MethodPost, "key-1", `{"qty":1}`)
+	r.Header.Set("X-Request-Id", "first")
+
+	w := serve(outer, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, `{"qty":1}`, w.Body.String())
+	require.Equal(t, "/orders/1", w.Header().Get("Location"))
+	require.Empty(t, w.Header().Get(HeaderReplayed))
+
+	r = newRequest(http.MethodPost, "key-1", `{"qty":1}`)
+	r.Header.Set("X-Request-Id", "second")
+
+	w = serve(outer, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, `{"qty":1}`, w.Body.String())
+	require.Equal(t, "/orders/1", w.Header().Get("Location"))
+	require.Equal(t, "true", w.Header().Get(HeaderReplayed))
+	require.Equal(t, "second", w.Header().Get("X-Request-Id"))
+
+	require.Equal(t, int32(1), calls.Load())
+
+	// a different key runs the handler again
+	w = serve(outer, newRequest(http.MethodPost, "key-2", `{"qty":1}`))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_passThrough(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	store := newFakeStore()
+	store.lockErr = errors.New("must not be called")
+
+	m := newTestMiddleware(t, store)
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls))
+
+	// method not handled
+	w := serve(h, newRequest(http.MethodPut, "key-1", "a"))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// no key
+	w = serve(h, newRequest(http.MethodPost, "", "a"))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_rejected(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls  atomic.Int32
+		gotErr error
+	)
+
+	m := newTestMiddleware(t, NewMemoryStore(),
+		WithRequired(),
+		WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, status int, err error) {
+			gotErr = err
+
+			w.WriteHeader(status)
+		}),
+	)
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls))
+
+	w := serve(h, newRequest(http.MethodPost, "", "a"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.ErrorIs(t, gotErr, ErrKeyRequired)
+
+	w = serve(h, newRequest(http.MethodPost, "bad key", "a"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.ErrorIs(t, gotErr, ErrInvalidKey)
+
+	require.Zero(t, calls.Load())
+}
+
+func TestMiddleware_mismatch(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	mc := &fakeMetrics{}
+	m := newTestMiddleware(t, NewMemoryStore(), WithMetrics(mc))
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{Path: "/orders"}, countingHandler(&calls))
+
+	w := serve(h, newRequest(http.MethodPost, "key-1", `{"qty":1}`))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = serve(h, newRequest(http.MethodPost, "key-1", `{"qty":2}`))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = serve(h, newRequest(http.MethodPatch, "key-1", `{"qty":1}`))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	require.Equal(t, int32(1), calls.Load())
+	require.Equal(t, map[string]int{"idempotency//orders/mismatch": 2}, mc.counts)
+}
+
+func TestMiddleware_inFlight(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	mc := &fakeMetrics{}
+	m := newTestMiddleware(t, NewMemoryStore(), WithMetrics(mc))
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{Path: "/orders"}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+
+	go func() {
+		done <- serve(h, newRequest(http.MethodPost, "key-1", "a"))
+	}()
+
+	<-started
+
+	w := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	close(release)
+
+	require.Equal(t, http.StatusAccepted, (<-done).Code)
+
+	w = serve(h, newRequest(http.MethodPost, "key-1", "a"))
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, "true", w.Header().Get(HeaderReplayed))
+
+	require.Equal(t, map[string]int{"idempotency//orders/in_flight": 1}, mc.counts)
+}
+
+func TestMiddleware_notRecorded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+		},
+		{
+			name: "too large",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("0123456789"))
+				_, _ = w.Write([]byte("0123456789"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			store := newFakeStore()
+			m := newTestMiddleware(t, store, WithMaxResponseSize(15))
+			h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				tt.handler(w, r)
+			}))
+
+			w1 := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+			w2 := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+
+			require.Equal(t, w1.Code, w2.Code)
+			require.Equal(t, w1.Body.String(), w2.Body.String())
+			require.Empty(t, w2.Header().Get(HeaderReplayed))
+			require.Equal(t, int32(2), calls.Load())
+			require.Equal(t, int32(2), store.unlocks.Load())
+		})
+	}
+}
+
+func TestMiddleware_panic(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore()
+	m := newTestMiddleware(t, store)
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		panic("boom")
+	}))
+
+	require.PanicsWithValue(t, "boom", func() {
+		serve(h, newRequest(http.MethodPost, "key-1", "a"))
+	})
+
+	require.Equal(t, int32(1), store.unlocks.Load())
+
+	cur, err := store.Lock(t.Context(), "key-1", &Record{Token: "t"}, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, cur)
+}
+
+func TestMiddleware_storeErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lock fail open", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+
+		store := newFakeStore()
+		store.lockErr = errors.New("down")
+
+		mc := &fakeMetrics{}
+		m := newTestMiddleware(t, store, WithMetrics(mc))
+		h := m.MiddlewareFn(httpserver.MiddlewareArgs{Path: "/orders"}, countingHandler(&calls))
+
+		w := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, int32(1), calls.Load())
+		require.Equal(t, map[string]int{"idempotency//orders/store_error": 1}, mc.counts)
+	})
+
+	t.Run("lock fail closed", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+
+		store := newFakeStore()
+		store.lockErr = errors.New("down")
+
+		m := newTestMiddleware(t, store, WithFailClosed())
+		h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls))
+
+		w := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Zero(t, calls.Load())
+	})
+
+	t.Run("save and unlock", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			calls  atomic.Int32
+			logBuf bytes.Buffer
+		)
+
+		store := newFakeStore()
+		store.saveErr = errors.New("save down")
+		store.unlockErr = errors.New("unlock down")
+
+		mc := &fakeMetrics{}
+		m := newTestMiddleware(t, store, WithMetrics(mc))
+		h := m.MiddlewareFn(
+			httpserver.MiddlewareArgs{Path: "/orders", Logger: slog.New(slog.NewJSONHandler(&logBuf, nil))},
+			countingHandler(&calls),
+		)
+
+		w := serve(h, newRequest(http.MethodPost, "key-1", "a"))
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, int32(1), store.unlocks.Load())
+		require.Equal(t, map[string]int{"idempotency//orders/store_error": 1}, mc.counts)
+		require.Contains(t, logBuf.String(), `"msg":"idempotency save failed"`)
+		require.Contains(t, logBuf.String(), `"msg":"idempotency unlock failed"`)
+	})
+}
+
+func TestMiddleware_body(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	m := newTestMiddleware(t, NewMemoryStore())
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls))
+
+	// the body exceeds the server limit
+	r := newRequest(http.MethodPost, "key-1", "0123456789")
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 5)
+
+	w := serve(h, r)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// the body exceeds the middleware limit
+	limited := newTestMiddleware(t, NewMemoryStore(), WithMaxRequestSize(5))
+
+	w = serve(limited.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls)), newRequest(http.MethodPost, "key-1", "0123456789"))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = serve(limited.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls)), newRequest(http.MethodPost, "key-3", "01234"))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// the body cannot be read
+	r = newRequest(http.MethodPost, "key-1", "")
+	r.Body = io.NopCloser(iotestErrReader{})
+
+	w = serve(h, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// no body
+	r = httptest.NewRequest(http.MethodPost, "/orders", nil)
+	r.Header.Set("Idempotency-Key", "key-2")
+
+	w = serve(h, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Empty(t, w.Body.String())
+
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestMiddleware_scope(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	m := newTestMiddleware(t, NewMemoryStore(),
+		WithScopeFunc(func(r *http.Request) string { return r.Header.Get("X-Client") }),
+	)
+	h := m.MiddlewareFn(httpserver.MiddlewareArgs{}, countingHandler(&calls))
+
+	for _, client := range []string{"a", "b", "", "a"} {
+		r := newRequest(http.MethodPost, "key-1", "x")
+		r.Header.Set("X-Client", client)
+
+		w := serve(h, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestMiddleware_server(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	m := newTestMiddleware(t, NewMemoryStore())
+
+	srv, err := httpserver.New(
+		t.Context(),
+		&testBinder{routes: []httpserver.Route{
+			{
+				Method:      http.MethodPost,
+				Path:        "/orders",
+				Handler:     countingHandler(&calls).ServeHTTP,
+				Description: "create order",
+				Middleware:  []httpserver.MiddlewareFn{m.MiddlewareFn},
+			},
+		}},
+		httpserver.WithServerAddr(":0"),
+		httpserver.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	srv.StartServerCtx(ctx)
+
+	for i := range 2 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+srv.Addr().String()+"/orders", strings.NewReader("x"))
+		require.NoError(t, err)
+
+		req.Header.Set("Idempotency-Key", "key-1")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, "x", string(body))
+		require.Equal(t, i == 1, resp.Header.Get(HeaderReplayed) == "true")
+	}
+
+	require.Equal(t, int32(1), calls.Load())
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+type testBinder struct {
+	routes []httpserver.Route
+}
+
+func (b *testBinder) BindHTTP(_ context.Context) []httpserver.Route {
+	return b.routes
+}
@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+// Option is a type to allow setting custom Middleware options.
+type Option func(*Middleware)
+
+// WithHeaderName sets the request header carrying the idempotency key
+// (default "Idempotency-Key"). An empty name is ignored.
+func WithHeaderName(name string) Option {
+	return func(m *Middleware) {
+		if name != "" {
+			m.header = http.CanonicalHeaderKey(name)
+		}
+	}
+}
+
+// WithMethods sets the request methods handled by the middleware (default
+// POST and PATCH). The requests with other methods pass through. An empty
+// list is ignored.
+func WithMethods(methods ...string) Option {
+	return func(m *Middleware) {
+		if len(methods) > 0 {
+			m.methods = methods
+		}
+	}
+}
+
+// WithRequired rejects with 400 Bad Request the handled requests without an
+// idempotency key, instead of letting them through.
+func WithRequired() Option {
+	return func(m *Middleware) {
+		m.required = true
+	}
+}
+
+// WithTTL sets the retention of the recorded responses (default
+// [DefaultTTL]). A non-positive value is ignored.
+func WithTTL(ttl time.Duration) Option {
+	return func(m *Middleware) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// WithLockTTL sets the expiration of an in-flight lock (default
+// [DefaultLockTTL]), after which a request whose handler never completed (e.g.
+// a crashed instance) can be retried. It should exceed the request timeout. A
+// non-positive value is ignored.
+func WithLockTTL(ttl time.Duration) Option {
+	return func(m *Middleware) {
+		if ttl > 0 {
+			m.lockTTL = ttl
+		}
+	}
+}
+
+// WithMaxResponseSize sets the maximum size of a recorded response body
+// (default [DefaultMaxResponseSize]). A larger response is sent but not
+// recorded. A non-positive value is ignored.
+func WithMaxResponseSize(size int) Option {
+	return func(m *Middleware) {
+		if size > 0 {
+			m.maxResponseSize = size
+		}
+	}
+}
+
+// WithMaxRequestSize sets the maximum size of a handled request body (default
+// [DefaultMaxRequestSize]), read in memory to fingerprint the request. A
+// larger request is rejected with 413 Request Entity Too Large. A
+// non-positive value is ignored.
+func WithMaxRequestSize(size int64) Option {
+	return func(m *Middleware) {
+		if size > 0 {
+			m.maxRequestSize = size
+		}
+	}
+}
+
+// WithScopeFunc sets the function returning the scope of the keys, e.g. the
+// `sub` claim of a verified JWT, so the keys of different clients never
+// collide.
+func WithScopeFunc(fn ScopeFunc) Option {
+	return func(m *Middleware) {
+		m.scopeFn = fn
+	}
+}
+
+// WithMetrics sets the metrics client counting the rejected requests and the
+// store failures through IncErrorCounter("idempotency", route, code), with code
+// "in_flight", "mismatch" or "store_error". A nil client disables the metrics.
+func WithMetrics(mc metrics.Client) Option {
+	return func(m *Middleware) {
+		m.metrics = mc
+	}
+}
+
+// WithLogger sets the logger used when the middleware receives none from
+// [github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareArgs], and by the
+// default error handler. A nil logger restores slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Middleware) {
+		m.logger = logger
+	}
+}
+
+// WithFailClosed rejects the requests with 503 Service Unavailable when the
+// store fails, instead of handling them without idempotency protection.
+func WithFailClosed() Option {
+	return func(m *Middleware) {
+		m.failClosed = true
+	}
+}
+
+// WithErrorHandler sets the function writing the response of a rejected
+// request, e.g. to send a JSendX body. A nil function restores the default
+// plain status response.
+func WithErrorHandler(fn ErrorHandlerFunc) Option {
+	return func(m *Middleware) {
+		m.errorHandler = fn
+	}
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeaderName(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{header: "Idempotency-Key"}
+	WithHeaderName("")(m)
+	require.Equal(t, "Idempotency-Key", m.header)
+
+	WithHeaderName("x-request-key")(m)
+	require.Equal(t, "X-Request-Key", m.header)
+}
+
+func TestWithMethods(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{methods: []string{http.MethodPost}}
+	WithMethods()(m)
+	require.Equal(t, []string{http.MethodPost}, m.methods)
+
+	WithMethods(http.MethodPost, http.MethodPut)(m)
+	require.Equal(t, []string{http.MethodPost, http.MethodPut}, m.methods)
+}
+
+func TestWithRequired(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{}
+	WithRequired()(m)
+	require.True(t, m.required)
+}
+
+func TestWithTTL(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{ttl: DefaultTTL}
+	WithTTL(0)(m)
+	require.Equal(t, DefaultTTL, m.ttl)
+
+	WithTTL(time.Hour)(m)
+	require.Equal(t, time.Hour, m.ttl)
+}
+
+func TestWithLockTTL(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{lockTTL: DefaultLockTTL}
+	WithLockTTL(-1)(m)
+	require.Equal(t, DefaultLockTTL, m.lockTTL)
+
+	WithLockTTL(time.Minute)(m)
+	require.Equal(t, time.Minute, m.lockTTL)
+}
+
+func TestWithMaxResponseSize(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{maxResponseSize: DefaultMaxResponseSize}
+	WithMaxResponseSize(0)(m)
+	require.Equal(t, DefaultMaxResponseSize, m.maxResponseSize)
+
+	WithMaxResponseSize(64)(m)
+	require.Equal(t, 64, m.maxResponseSize)
+}
+
+func TestWithMaxRequestSize(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{maxRequestSize: DefaultMaxRequestSize}
+	WithMaxRequestSize(0)(m)
+	require.Equal(t, int64(DefaultMaxRequestSize), m.maxRequestSize)
+
+	WithMaxRequestSize(64)(m)
+	require.Equal(t, int64(64), m.maxRequestSize)
+}
+
+func TestWithScopeFunc(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{}
+	WithScopeFunc(func(_ *http.Request) string { return "s" })(m)
+	require.Equal(t, "s", m.scopeFn(nil))
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	mc := &fakeMetrics{}
+	m := &Middleware{}
+	WithMetrics(mc)(m)
+	require.Equal(t, mc, m.metrics)
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	m := &Middleware{}
+	WithLogger(logger)(m)
+	require.Equal(t, logger, m.logger)
+}
+
+func TestWithFailClosed(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{}
+	WithFailClosed()(m)
+	require.True(t, m.failClosed)
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	m := &Middleware{}
+	WithErrorHandler(func(_ http.ResponseWriter, _ *http.Request, _ int, _ error) {})(m)
+	require.NotNil(t, m.errorHandler)
+}
@@ -0,0 +1,147 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/luascript"
+)
+
+// DefaultKeyPrefix is the default prefix of the keys written by a [ScriptStore].
+const DefaultKeyPrefix = "idempotency:"
+
+// lockScript stores the in-flight record ARGV[1] under KEYS[1] for ARGV[2]
+// milliseconds when the key is free, and returns false. Otherwise it returns
+// the stored record.
+const lockScript = `
+local cur = redis.call('GET', KEYS[1])
+if cur then
+  return cur
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`
+
+// saveScript replaces the in-flight record of KEYS[1] locked by the token
+// ARGV[3] with the completed record ARGV[1], kept for ARGV[2] milliseconds.
+const saveScript = `
+local cur = redis.call('GET', KEYS[1])
+if not cur then
+  return 0
+end
+local rec = cjson.decode(cur)
+if rec.token ~= ARGV[3] or (rec.status or 0) ~= 0 then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`
+
+// unlockScript deletes the in-flight record of KEYS[1] locked by the token
+// ARGV[1].
+const unlockScript = `
+local cur = redis.call('GET', KEYS[1])
+if not cur then
+  return 0
+end
+local rec = cjson.decode(cur)
+if rec.token ~= ARGV[1] or (rec.status or 0) ~= 0 then
+  return 0
+end
+return redis.call('DEL', KEYS[1])
+`
+
+var (
+	// ErrNilEval is returned by [NewScriptStore] when the script runner is nil.
+	ErrNilEval = errors.New("idempotency: nil eval function")
+
+	// ErrScriptReply is returned when a store script returns an unexpected reply.
+	ErrScriptReply = errors.New("idempotency: unexpected script reply")
+)
+
+// EvalFunc runs a Lua script on a Redis-compatible server and returns its
+// reply; [github.com/tecnickcom/nurago/pkg/luascript] builds one from a Redis
+// or Valkey client.
+type EvalFunc = luascript.EvalFunc
+
+// ScriptStore is a distributed [Store] keeping the records as JSON values on a
+// Redis-compatible server (Redis 5+ or Valkey), updated by atomic Lua scripts.
+// Every record expires with its TTL, so the store needs no cleanup.
+type ScriptStore struct {
+	eval   EvalFunc
+	prefix string
+}
+
+// NewScriptStore returns a [ScriptStore] running the scripts with eval and
+// prefixing every key with keyPrefix (e.g. [DefaultKeyPrefix]).
+func NewScriptStore(eval EvalFunc, keyPrefix string) (*ScriptStore, error) {
+	if eval == nil {
+		return nil, ErrNilEval
+	}
+
+	return &ScriptStore{
+		eval:   eval,
+		prefix: keyPrefix,
+	}, nil
+}
+
+// Lock implements [Store].
+func (s *ScriptStore) Lock(ctx context.Context, key string, rec *Record, ttl time.Duration) (*Record, error) {
+	reply, err := s.eval(ctx, lockScript, []string{s.prefix + key}, encodeRecord(rec), ttlMillis(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: unable to run the lock script: %w", err)
+	}
+
+	if reply == nil {
+		return nil, nil //nolint:nilnil // a nil record reports the acquired lock
+	}
+
+	value, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+	}
+
+	cur := &Record{}
+
+	if err := json.Unmarshal([]byte(value), cur); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScriptReply, err)
+	}
+
+	return cur, nil
+}
+
+// Save implements [Store].
+func (s *ScriptStore) Save(ctx context.Context, key string, rec *Record, ttl time.Duration) error {
+	_, err := s.eval(ctx, saveScript, []string{s.prefix + key}, encodeRecord(rec), ttlMillis(ttl), rec.Token)
+	if err != nil {
+		return fmt.Errorf("idempotency: unable to run the save script: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock implements [Store].
+func (s *ScriptStore) Unlock(ctx context.Context, key, token string) error {
+	_, err := s.eval(ctx, unlockScript, []string{s.prefix + key}, token)
+	if err != nil {
+		return fmt.Errorf("idempotency: unable to run the unlock script: %w", err)
+	}
+
+	return nil
+}
+
+// encodeRecord returns the JSON encoding of rec, which cannot fail.
+func encodeRecord(rec *Record) string {
+	data, _ := json.Marshal(rec) //nolint:errchkjson
+
+	return string(data)
+}
+
+// ttlMillis returns ttl in whole milliseconds, at least 1.
+func ttlMillis(ttl time.Duration) string {
+	return strconv.FormatInt(max(1, ttl.Milliseconds()), 10)
+}
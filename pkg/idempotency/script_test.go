@@ -0,0 +1,135 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type evalCall struct {
+	script string
+	keys   []string
+	args   []string
+}
+
+type fakeEval struct {
+	calls []evalCall
+	reply any
+	err   error
+}
+
+func (f *fakeEval) eval(_ context.Context, script string, keys []string, args ...string) (any, error) {
+	f.calls = append(f.calls, evalCall{script: script, keys: keys, args: args})
+
+	return f.reply, f.err
+}
+
+func TestNewScriptStore(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewScriptStore(nil, DefaultKeyPrefix)
+	require.ErrorIs(t, err, ErrNilEval)
+	require.Nil(t, s)
+
+	f := &fakeEval{}
+	s, err = NewScriptStore(f.eval, DefaultKeyPrefix)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+}
+
+func TestScriptStore_Lock(t *testing.T) {
+	t.Parallel()
+
+	stored := &Record{Token: "t1", Fingerprint: "fp", Status: 201, Header: http.Header{"X-Id": {"1"}}, Body: []byte("ok")}
+	storedJSON, err := json.Marshal(stored)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		reply   any
+		err     error
+		want    *Record
+		wantErr error
+	}{
+		{name: "acquired", reply: nil},
+		{name: "existing", reply: string(storedJSON), want: stored},
+		{name: "eval error", err: errors.New("down"), wantErr: errors.New("")},
+		{name: "unexpected type", reply: int64(1), wantErr: ErrScriptReply},
+		{name: "invalid json", reply: "{", wantErr: ErrScriptReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeEval{reply: tt.reply, err: tt.err}
+			s, err := NewScriptStore(f.eval, "p:")
+			require.NoError(t, err)
+
+			lock := &Record{Token: "t2", Fingerprint: "fp"}
+
+			got, err := s.Lock(t.Context(), "k", lock, 1500*time.Millisecond)
+
+			require.Len(t, f.calls, 1)
+			require.Equal(t, lockScript, f.calls[0].script)
+			require.Equal(t, []string{"p:k"}, f.calls[0].keys)
+			require.Equal(t, []string{`{"token":"t2","fingerprint":"fp"}`, "1500"}, f.calls[0].args)
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				require.Nil(t, got)
+
+				if tt.err == nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScriptStore_Save(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeEval{reply: int64(1)}
+	s, err := NewScriptStore(f.eval, DefaultKeyPrefix)
+	require.NoError(t, err)
+
+	rec := &Record{Token: "t1", Fingerprint: "fp", Status: 200}
+	require.NoError(t, s.Save(t.Context(), "k", rec, 0))
+
+	require.Len(t, f.calls, 1)
+	require.Equal(t, saveScript, f.calls[0].script)
+	require.Equal(t, []string{"idempotency:k"}, f.calls[0].keys)
+	require.Equal(t, []string{`{"token":"t1","fingerprint":"fp","status":200}`, "1", "t1"}, f.calls[0].args)
+
+	f.err = errors.New("down")
+	require.Error(t, s.Save(t.Context(), "k", rec, time.Hour))
+}
+
+func TestScriptStore_Unlock(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeEval{reply: int64(1)}
+	s, err := NewScriptStore(f.eval, DefaultKeyPrefix)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Unlock(t.Context(), "k", "t1"))
+
+	require.Len(t, f.calls, 1)
+	require.Equal(t, unlockScript, f.calls[0].script)
+	require.Equal(t, []string{"idempotency:k"}, f.calls[0].keys)
+	require.Equal(t, []string{"t1"}, f.calls[0].args)
+
+	f.err = errors.New("down")
+	require.Error(t, s.Unlock(t.Context(), "k", "t1"))
+}
@@ -0,0 +1,23 @@
+package sqlstore
+
+// Option configures the [Store].
+type Option func(*Store)
+
+// WithTable sets the name of the idempotency table (default [DefaultTable]).
+// The name is used verbatim in the queries, so it must be trusted. An empty
+// name is ignored.
+func WithTable(name string) Option {
+	return func(s *Store) {
+		if name != "" {
+			s.table = name
+		}
+	}
+}
+
+// WithDollarPlaceholders makes the queries use the numbered "$n" placeholders
+// required by PostgreSQL, instead of "?".
+func WithDollarPlaceholders() Option {
+	return func(s *Store) {
+		s.dollar = true
+	}
+}
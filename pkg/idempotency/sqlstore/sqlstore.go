@@ -0,0 +1,176 @@
+/*
+Package sqlstore implements the [github.com/tecnickcom/nurago/pkg/idempotency.Store]
+on a SQL database table, so the idempotency keys are shared by every instance
+of a service that already depends on a database.
+
+The table must exist, with the idempotency key as primary key:
+
+	CREATE TABLE idempotency_keys (
+	  idem_key    VARCHAR(512) NOT NULL PRIMARY KEY,
+	  token       VARCHAR(64)  NOT NULL,
+	  fingerprint VARCHAR(64)  NOT NULL,
+	  status      INTEGER      NOT NULL,
+	  header      TEXT         NOT NULL,
+	  body        BLOB,
+	  expires_at  BIGINT       NOT NULL
+	);
+
+Usage:
+
+	store, err := sqlstore.New(db, sqlstore.WithTable("idempotency_keys"))
+	// ...
+	mw, err := idempotency.New(store)
+
+The lock relies on the primary key: the first INSERT of a key wins, and the
+others read the stored record. The queries use the "?" placeholder by default;
+use [WithDollarPlaceholders] for PostgreSQL. The expired rows are deleted when
+their key is locked again; a periodic DELETE of the rows with expires_at in the
+past (Unix milliseconds) keeps the table small.
+*/
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/idempotency"
+)
+
+// DefaultTable is the default name of the idempotency table.
+const DefaultTable = "idempotency_keys"
+
+// ErrNilDB is returned by [New] when the database handle is nil.
+var ErrNilDB = errors.New("sqlstore: nil database")
+
+// Store is an [idempotency.Store] keeping the records in a database table.
+type Store struct {
+	db        *sql.DB
+	table     string
+	dollar    bool
+	nowFn     func() time.Time
+	qryDelete string
+	qryInsert string
+	qrySelect string
+	qrySave   string
+	qryUnlock string
+}
+
+// New returns a Store keeping the records in a table of db.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, ErrNilDB
+	}
+
+	s := &Store{
+		db:    db,
+		table: DefaultTable,
+		nowFn: time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(s)
+	}
+
+	s.qryDelete = s.bind("DELETE FROM " + s.table + " WHERE idem_key = ? AND expires_at <= ?")
+	s.qryInsert = s.bind("INSERT INTO " + s.table + " (idem_key, token, fingerprint, status, header, body, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	s.qrySelect = s.bind("SELECT token, fingerprint, status, header, body FROM " + s.table + " WHERE idem_key = ? AND expires_at > ?")
+	s.qrySave = s.bind("UPDATE " + s.table + " SET status = ?, header = ?, body = ?, expires_at = ? WHERE idem_key = ? AND token = ? AND status = 0")
+	s.qryUnlock = s.bind("DELETE FROM " + s.table + " WHERE idem_key = ? AND token = ? AND status = 0")
+
+	return s, nil
+}
+
+// Lock implements [idempotency.Store].
+func (s *Store) Lock(ctx context.Context, key string, rec *idempotency.Record, ttl time.Duration) (*idempotency.Record, error) {
+	now := s.nowFn()
+
+	if _, err := s.db.ExecContext(ctx, s.qryDelete, key, now.UnixMilli()); err != nil {
+		return nil, fmt.Errorf("sqlstore: unable to delete the expired record: %w", err)
+	}
+
+	_, errInsert := s.db.ExecContext(ctx, s.qryInsert,
+		key, rec.Token, rec.Fingerprint, rec.Status, encodeHeader(rec.Header), rec.Body, now.Add(ttl).UnixMilli())
+	if errInsert == nil {
+		return nil, nil //nolint:nilnil // a nil record reports the acquired lock
+	}
+
+	// The insert fails on a duplicate key: report the stored record.
+	cur := &idempotency.Record{}
+
+	var header string
+
+	err := s.db.QueryRowContext(ctx, s.qrySelect, key, now.UnixMilli()).
+		Scan(&cur.Token, &cur.Fingerprint, &cur.Status, &header, &cur.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("sqlstore: unable to insert the record: %w", errInsert)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("sqlstore: unable to read the record: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(header), &cur.Header); err != nil {
+		return nil, fmt.Errorf("sqlstore: unable to decode the record header: %w", err)
+	}
+
+	return cur, nil
+}
+
+// Save implements [idempotency.Store].
+func (s *Store) Save(ctx context.Context, key string, rec *idempotency.Record, ttl time.Duration) error {
+	_, err := s.db.ExecContext(ctx, s.qrySave,
+		rec.Status, encodeHeader(rec.Header), rec.Body, s.nowFn().Add(ttl).UnixMilli(), key, rec.Token)
+	if err != nil {
+		return fmt.Errorf("sqlstore: unable to save the record: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock implements [idempotency.Store].
+func (s *Store) Unlock(ctx context.Context, key, token string) error {
+	if _, err := s.db.ExecContext(ctx, s.qryUnlock, key, token); err != nil {
+		return fmt.Errorf("sqlstore: unable to unlock the record: %w", err)
+	}
+
+	return nil
+}
+
+// bind replaces the "?" placeholders of query with "$n" ones when configured.
+func (s *Store) bind(query string) string {
+	if !s.dollar {
+		return query
+	}
+
+	var b strings.Builder
+
+	n := 0
+
+	for _, c := range query {
+		if c != '?' {
+			b.WriteRune(c)
+
+			continue
+		}
+
+		n++
+
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// encodeHeader returns the JSON encoding of h, which cannot fail.
+func encodeHeader(h http.Header) string {
+	data, _ := json.Marshal(h) //nolint:errchkjson
+
+	return string(data)
+}
@@ -0,0 +1,171 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/idempotency"
+)
+
+var testNow = time.UnixMilli(1_000_000)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	s, err := New(db, opts...)
+	require.NoError(t, err)
+
+	s.nowFn = func() time.Time { return testNow }
+
+	return s, mock
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(nil)
+	require.ErrorIs(t, err, ErrNilDB)
+	require.Nil(t, s)
+
+	s, _ = newTestStore(t, WithTable(""))
+	require.Equal(t, "DELETE FROM idempotency_keys WHERE idem_key = ? AND token = ? AND status = 0", s.qryUnlock)
+
+	s, _ = newTestStore(t, WithTable("idem"), WithDollarPlaceholders())
+	require.Equal(t, "DELETE FROM idem WHERE idem_key = $1 AND token = $2 AND status = 0", s.qryUnlock)
+}
+
+func TestStore_Lock(t *testing.T) {
+	t.Parallel()
+
+	errDB := errors.New("db error")
+	rec := &idempotency.Record{Token: "t", Fingerprint: "fp"}
+	cols := []string{"token", "fingerprint", "status", "header", "body"}
+
+	tests := []struct {
+		name    string
+		setup   func(s *Store, mock sqlmock.Sqlmock)
+		want    *idempotency.Record
+		wantErr bool
+	}{
+		{
+			name: "acquired",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WithArgs("k", int64(1_000_000)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(s.qryInsert).
+					WithArgs("k", "t", "fp", 0, "null", sqlmock.AnyArg(), int64(1_060_000)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "existing",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(s.qryInsert).WillReturnError(errDB)
+				mock.ExpectQuery(s.qrySelect).WithArgs("k", int64(1_000_000)).
+					WillReturnRows(sqlmock.NewRows(cols).AddRow("o", "fp", 201, `{"X-A":["1"]}`, []byte("ok")))
+			},
+			want: &idempotency.Record{
+				Token:       "o",
+				Fingerprint: "fp",
+				Status:      http.StatusCreated,
+				Header:      http.Header{"X-A": {"1"}},
+				Body:        []byte("ok"),
+			},
+		},
+		{
+			name: "delete error",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WillReturnError(errDB)
+			},
+			wantErr: true,
+		},
+		{
+			name: "insert error",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(s.qryInsert).WillReturnError(errDB)
+				mock.ExpectQuery(s.qrySelect).WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+		{
+			name: "select error",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(s.qryInsert).WillReturnError(errDB)
+				mock.ExpectQuery(s.qrySelect).WillReturnError(errDB)
+			},
+			wantErr: true,
+		},
+		{
+			name: "header error",
+			setup: func(s *Store, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(s.qryDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(s.qryInsert).WillReturnError(errDB)
+				mock.ExpectQuery(s.qrySelect).
+					WillReturnRows(sqlmock.NewRows(cols).AddRow("o", "fp", 201, "{", nil))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, mock := newTestStore(t)
+			tt.setup(s, mock)
+
+			got, err := s.Lock(context.Background(), "k", rec, time.Minute)
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStore_Save(t *testing.T) {
+	t.Parallel()
+
+	rec := &idempotency.Record{
+		Token:  "t",
+		Status: http.StatusCreated,
+		Header: http.Header{"X-A": {"1"}},
+		Body:   []byte("ok"),
+	}
+
+	s, mock := newTestStore(t)
+
+	mock.ExpectExec(s.qrySave).
+		WithArgs(201, `{"X-A":["1"]}`, []byte("ok"), int64(1_060_000), "k", "t").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(s.qrySave).WillReturnError(errors.New("db error"))
+
+	require.NoError(t, s.Save(context.Background(), "k", rec, time.Minute))
+	require.Error(t, s.Save(context.Background(), "k", rec, time.Minute))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStore_Unlock(t *testing.T) {
+	t.Parallel()
+
+	s, mock := newTestStore(t, WithDollarPlaceholders())
+
+	mock.ExpectExec(s.qryUnlock).WithArgs("k", "t").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(s.qryUnlock).WillReturnError(errors.New("db error"))
+
+	require.NoError(t, s.Unlock(context.Background(), "k", "t"))
+	require.Error(t, s.Unlock(context.Background(), "k", "t"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,133 @@
+package idempotency
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"slices"
+)
+
+// recorder is an [http.ResponseWriter] recording the response it writes
+// through, up to a maximum body size.
+type recorder struct {
+	http.ResponseWriter
+
+	before   http.Header
+	header   http.Header
+	status   int
+	body     []byte
+	maxSize  int
+	overflow bool
+	hijacked bool
+}
+
+// newRecorder returns a recorder writing through w. The headers already set on
+// w (e.g. by the outer middleware) are not recorded unless the handler changes
+// them.
+func newRecorder(w http.ResponseWriter, maxSize int) *recorder {
+	return &recorder{
+		ResponseWriter: w,
+		before:         w.Header().Clone(),
+		maxSize:        maxSize,
+	}
+}
+
+// WriteHeader implements [http.ResponseWriter].
+func (rw *recorder) WriteHeader(status int) {
+	if rw.status != 0 {
+		return
+	}
+
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(status)
+
+		return
+	}
+
+	rw.status = status
+	rw.header = make(http.Header)
+
+	for k, v := range rw.ResponseWriter.Header() {
+		if k == "Date" || slices.Equal(rw.before[k], v) {
+			continue
+		}
+
+		rw.header[k] = slices.Clone(v)
+	}
+
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements [http.ResponseWriter].
+func (rw *recorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+
+	if !rw.overflow {
+		if len(rw.body)+n > rw.maxSize {
+			rw.overflow = true
+			rw.body = nil
+		} else {
+			rw.body = append(rw.body, b[:n]...)
+		}
+	}
+
+	return n, err //nolint:wrapcheck // the error of the underlying writer
+}
+
+// Flush implements [http.Flusher].
+func (rw *recorder) Flush() {
+	if rw.status == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements [http.Hijacker]. A hijacked response is never recorded.
+func (rw *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	rw.hijacked = true
+
+	return hj.Hijack() //nolint:wrapcheck // the error of the underlying writer
+}
+
+// Unwrap returns the underlying writer, for [http.ResponseController].
+func (rw *recorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// record returns the completed record of the response locked by lock, and
+// false when the response cannot be reused: a server error, a switched
+// protocol, a hijacked connection, or a body over the size limit.
+func (rw *recorder) record(lock *Record) (*Record, bool) {
+	if rw.hijacked || rw.overflow {
+		return nil, false
+	}
+
+	if rw.status == 0 {
+		// net/http sends an implicit 200 when the handler writes nothing.
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	if rw.status == http.StatusSwitchingProtocols || rw.status >= http.StatusInternalServerError {
+		return nil, false
+	}
+
+	return &Record{
+		Token:       lock.Token,
+		Fingerprint: lock.Fingerprint,
+		Status:      rw.status,
+		Header:      rw.header,
+		Body:        rw.body,
+	}, true
+}
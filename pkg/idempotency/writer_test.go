@@ -0,0 +1,142 @@
+package idempotency
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (hr *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	lock := &Record{Token: "t", Fingerprint: "fp"}
+
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Outer", "1")
+
+	rw := newRecorder(w, 10)
+	rw.Header().Set("Date", "Mon, 01 Jan 2026 00:00:00 GMT")
+	rw.Header().Set("X-Inner", "2")
+	rw.WriteHeader(http.StatusCreated)
+	rw.WriteHeader(http.StatusOK)
+	rw.Flush()
+
+	n, err := rw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+
+	require.Equal(t, rw.ResponseWriter, rw.Unwrap())
+	require.True(t, w.Flushed)
+
+	rec, ok := rw.record(lock)
+	require.True(t, ok)
+	require.Equal(t, &Record{
+		Token:       "t",
+		Fingerprint: "fp",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"X-Inner": {"2"}},
+		Body:        []byte("hello"),
+	}, rec)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+
+	statuses []int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.statuses = append(sr.statuses, status)
+}
+
+func TestRecorder_informational(t *testing.T) {
+	t.Parallel()
+
+	sr := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+	rw := newRecorder(sr, 10)
+	rw.WriteHeader(http.StatusEarlyHints)
+	rw.WriteHeader(http.StatusAccepted)
+
+	require.Equal(t, []int{http.StatusEarlyHints, http.StatusAccepted}, sr.statuses)
+
+	rec, ok := rw.record(&Record{Token: "t"})
+	require.True(t, ok)
+	require.Equal(t, http.StatusAccepted, rec.Status)
+}
+
+func TestRecorder_implicitStatus(t *testing.T) {
+	t.Parallel()
+
+	lock := &Record{Token: "t"}
+
+	w := httptest.NewRecorder()
+	rw := newRecorder(w, 10)
+
+	rec, ok := rw.record(lock)
+	require.True(t, ok)
+	require.Equal(t, http.StatusOK, rec.Status)
+	require.Empty(t, rec.Body)
+
+	w = httptest.NewRecorder()
+	rw = newRecorder(w, 10)
+	rw.Flush()
+
+	rec, ok = rw.record(lock)
+	require.True(t, ok)
+	require.Equal(t, http.StatusOK, rec.Status)
+}
+
+func TestRecorder_notRecorded(t *testing.T) {
+	t.Parallel()
+
+	lock := &Record{Token: "t"}
+
+	rw := newRecorder(httptest.NewRecorder(), 3)
+	_, _ = rw.Write([]byte("abcd"))
+	_, _ = rw.Write([]byte("e"))
+
+	_, ok := rw.record(lock)
+	require.False(t, ok)
+
+	rw = newRecorder(httptest.NewRecorder(), 3)
+	rw.WriteHeader(http.StatusSwitchingProtocols)
+
+	_, ok = rw.record(lock)
+	require.False(t, ok)
+
+	rw = newRecorder(httptest.NewRecorder(), 3)
+	rw.WriteHeader(http.StatusInternalServerError)
+
+	_, ok = rw.record(lock)
+	require.False(t, ok)
+}
+
+func TestRecorder_Hijack(t *testing.T) {
+	t.Parallel()
+
+	rw := newRecorder(httptest.NewRecorder(), 10)
+
+	_, _, err := rw.Hijack()
+	require.ErrorIs(t, err, http.ErrNotSupported)
+	require.False(t, rw.hijacked)
+
+	rw = newRecorder(&hijackRecorder{httptest.NewRecorder()}, 10)
+
+	_, _, err = rw.Hijack()
+	require.NoError(t, err)
+
+	_, ok := rw.record(&Record{Token: "t"})
+	require.False(t, ok)
+}
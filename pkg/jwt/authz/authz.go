@@ -0,0 +1,269 @@
+/*
+Package authz provides declarative, scope- and role-based authorization on top
+of the verified [github.com/tecnickcom/nurago/pkg/jwt.Claims].
+
+Instead of repeating role checks in every handler after
+[github.com/tecnickcom/nurago/pkg/jwt.ClaimsFromContext], routes declare the
+[Policy] they require:
+
+	az, err := authz.New(auth, authz.WithAppInfo(appInfo))
+	// ...
+	routes := []httpserver.Route{
+	    {Method: http.MethodGet, Path: "/orders", Handler: listOrders},
+	    {Method: http.MethodPost, Path: "/orders", Handler: createOrder},
+	    {Method: http.MethodDelete, Path: "/orders/:id", Handler: deleteOrder},
+	}
+
+	routes, err = az.Apply(routes, authz.PolicyTable{
+	    authz.RouteKey(http.MethodGet, "/orders"):        authz.AnyScope("orders:read", "orders:admin"),
+	    authz.RouteKey(http.MethodPost, "/orders"):       authz.AllScopes("orders:write"),
+	    authz.RouteKey(http.MethodDelete, "/orders/:id"): authz.AnyRole("admin"),
+	})
+
+The middleware returned by [Authorizer.Require] (and its [Authorizer.RequireScopes]
+and [Authorizer.RequireAnyRole] shorthands) is an
+[github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareFn], so it can also be
+listed directly in [github.com/tecnickcom/nurago/pkg/httpserver.Route.Middleware].
+
+# Behavior
+
+The middleware reuses the claims verified by an upstream
+[github.com/tecnickcom/nurago/pkg/jwt.JWT.Middleware] when present, and
+otherwise authenticates the bearer token itself through the configured
+[Authenticator], sharing the verified claims downstream in either case.
+
+  - A missing or invalid token gets a 401 with the RFC 6750 Bearer challenge.
+  - A valid token that does not satisfy the policy gets a 403 with the
+    `insufficient_scope` challenge, listing the scopes the policy names.
+
+Both responses use the JSendX envelope of
+[github.com/tecnickcom/nurago/pkg/httputil/jsendx]. Each denial is logged at
+warning level through the route logger with the request trace ID, the policy,
+and the claim set that was denied (subject, token and session IDs, scopes,
+roles, issuer, and audience; never the token itself).
+*/
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/jwt"
+)
+
+const (
+	// headerWWWAuthenticate is the RFC 7235 challenge header.
+	headerWWWAuthenticate = "WWW-Authenticate"
+
+	// challengeBearer is the RFC 6750 challenge sent when no bearer token was presented.
+	challengeBearer = "Bearer"
+
+	// challengeInvalidToken is the RFC 6750 challenge sent when a presented
+	// bearer token was rejected.
+	challengeInvalidToken = `Bearer error="invalid_token"` //nolint:gosec // RFC 6750 challenge value, not a credential
+
+	// challengeInsufficientScope is the RFC 6750 §3.1 challenge sent when a
+	// valid token lacks the required grants.
+	challengeInsufficientScope = `Bearer error="insufficient_scope"`
+)
+
+var (
+	// ErrNilAuthenticator is returned by [New] when the authenticator is nil.
+	ErrNilAuthenticator = errors.New("authz: nil authenticator")
+
+	// ErrUnknownRoute is returned by [Authorizer.Apply] when a policy table
+	// entry matches no route, which would otherwise leave the intended route
+	// unprotected (e.g. after a typo or a path change).
+	ErrUnknownRoute = errors.New("authz: policy for an unknown route")
+)
+
+// Authenticator verifies the bearer token of a request. It is implemented by
+// [jwt.JWT].
+type Authenticator interface {
+	Authenticate(r *http.Request) (*jwt.Claims, error)
+}
+
+// PolicyTable maps the routes, keyed by [RouteKey], to their policies.
+type PolicyTable map[string]Policy
+
+// RouteKey returns the [PolicyTable] key of a route: the method and the path
+// exactly as registered in [httpserver.Route] (e.g. "DELETE /orders/:id").
+func RouteKey(method, path string) string {
+	return method + " " + path
+}
+
+// Authorizer builds authorization middleware.
+type Authorizer struct {
+	authenticator Authenticator
+	appInfo       *jsendx.AppInfo
+	logger        *slog.Logger
+	jsx           *jsendx.JSXResp
+}
+
+// New constructs an Authorizer authenticating the requests not already
+// authenticated upstream with authenticator (typically a [*jwt.JWT]).
+func New(authenticator Authenticator, opts ...Option) (*Authorizer, error) {
+	if authenticator == nil {
+		return nil, ErrNilAuthenticator
+	}
+
+	a := &Authorizer{
+		authenticator: authenticator,
+		appInfo:       &jsendx.AppInfo{},
+		logger:        slog.Default(),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(a)
+	}
+
+	if a.logger == nil {
+		a.logger = slog.Default()
+	}
+
+	a.jsx = jsendx.NewJSXResp(httputil.NewHTTPResp(a.logger))
+
+	return a, nil
+}
+
+// Require returns a middleware admitting only the requests whose verified
+// claims satisfy policy.
+func (a *Authorizer) Require(policy Policy) httpserver.MiddlewareFn {
+	return func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+		return a.handler(args.Logger, policy, next)
+	}
+}
+
+// RequireScopes returns a middleware admitting only the tokens granting every
+// one of scopes. It is a shorthand for Require(AllScopes(scopes...)).
+func (a *Authorizer) RequireScopes(scopes ...string) httpserver.MiddlewareFn {
+	return a.Require(AllScopes(scopes...))
+}
+
+// RequireAnyRole returns a middleware admitting only the tokens granting at
+// least one of roles. It is a shorthand for Require(AnyRole(roles...)).
+func (a *Authorizer) RequireAnyRole(roles ...string) httpserver.MiddlewareFn {
+	return a.Require(AnyRole(roles...))
+}
+
+// Apply returns a copy of routes in which every route listed in table runs the
+// middleware of its policy ahead of its own middleware. Routes absent from the
+// table are left unchanged. It fails with [ErrUnknownRoute] when a table entry
+// matches no route.
+func (a *Authorizer) Apply(routes []httpserver.Route, table PolicyTable) ([]httpserver.Route, error) {
+	out := slices.Clone(routes)
+	used := make(map[string]bool, len(table))
+
+	for i, route := range out {
+		key := RouteKey(route.Method, route.Path)
+
+		policy, ok := table[key]
+		if !ok {
+			continue
+		}
+
+		used[key] = true
+		out[i].Middleware = append([]httpserver.MiddlewareFn{a.Require(policy)}, route.Middleware...)
+	}
+
+	var unknown []string
+
+	for key := range table {
+		if !used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoute, strings.Join(unknown, ", "))
+	}
+
+	return out, nil
+}
+
+// handler wraps next with the authentication and policy checks.
+func (a *Authorizer) handler(logger *slog.Logger, policy Policy, next http.Handler) http.Handler {
+	if logger == nil {
+		logger = a.logger
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims, ok := jwt.ClaimsFromContext(ctx)
+		if !ok {
+			var err error
+
+			claims, err = a.authenticator.Authenticate(r)
+			if err != nil {
+				a.sendUnauthorized(w, r, httpserver.RequestLogger(logger, r), err)
+
+				return
+			}
+
+			r = r.WithContext(jwt.ContextWithClaims(ctx, claims))
+		}
+
+		if !policy.Allow(claims) {
+			a.sendForbidden(w, r, httpserver.RequestLogger(logger, r), policy, claims)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sendUnauthorized writes the 401 response of a failed authentication.
+func (a *Authorizer) sendUnauthorized(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
+	challenge := challengeInvalidToken
+	if errors.Is(err, jwt.ErrMissingAuthHeader) || errors.Is(err, jwt.ErrMissingToken) {
+		challenge = challengeBearer
+	}
+
+	w.Header().Set(headerWWWAuthenticate, challenge)
+	a.jsx.Send(r.Context(), w, http.StatusUnauthorized, a.appInfo, "unauthorized")
+	logger.WarnContext(r.Context(), "unauthenticated request", slog.Any("error", err))
+}
+
+// sendForbidden writes the 403 response of a denied policy and logs the
+// denied claim set.
+func (a *Authorizer) sendForbidden(w http.ResponseWriter, r *http.Request, logger *slog.Logger, policy Policy, claims *jwt.Claims) {
+	challenge := challengeInsufficientScope
+	if len(policy.scopes) > 0 {
+		challenge += `, scope="` + strings.Join(policy.scopes, " ") + `"`
+	}
+
+	w.Header().Set(headerWWWAuthenticate, challenge)
+	a.jsx.Send(r.Context(), w, http.StatusForbidden, a.appInfo, "forbidden")
+	logger.WarnContext(r.Context(), "authorization denied",
+		slog.String("policy", policy.String()),
+		claimsAttr(claims),
+	)
+}
+
+// claimsAttr returns the authorization-relevant claims as a log group.
+func claimsAttr(claims *jwt.Claims) slog.Attr {
+	if claims == nil {
+		return slog.Group("claims")
+	}
+
+	return slog.Group("claims",
+		slog.String("sub", claims.Subject),
+		slog.String("jti", claims.ID),
+		slog.String("sid", claims.SessionID),
+		slog.Any("scope", []string(claims.Scope)),
+		slog.Any("roles", claims.Roles),
+		slog.String("iss", claims.Issuer),
+		slog.Any("aud", []string(claims.Audience)),
+	)
+}
@@ -0,0 +1,290 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+	"github.com/tecnickcom/nurago/pkg/jwt"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func testGrants(_ context.Context, username string) ([]string, []string, error) {
+	switch username {
+	case "admin":
+		return []string{"orders:read", "orders:write"}, []string{"admin"}, nil
+	case "reader":
+		return []string{"orders:read"}, nil, nil
+	}
+
+	return nil, nil, nil
+}
+
+func newTestJWT(t *testing.T) *jwt.JWT {
+	t.Helper()
+
+	auth, err := jwt.New(testKey, func(_, _ string) (bool, error) { return true, nil }, jwt.WithGrantsFn(testGrants))
+	require.NoError(t, err)
+
+	return auth
+}
+
+func issue(t *testing.T, auth *jwt.JWT, username string) string {
+	t.Helper()
+
+	token, err := auth.IssueToken(username)
+	require.NoError(t, err)
+
+	return token
+}
+
+// serve runs a request with the optional bearer token through handler.
+func serve(t *testing.T, handler http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequestWithContext(t.Context(), method, path, nil)
+	req = req.WithContext(traceid.NewContext(req.Context(), "trace-123"))
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+// okHandler records the claims it receives.
+func okHandler(got **jwt.Claims) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got, _ = jwt.ClaimsFromContext(r.Context())
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	a, err := New(nil)
+	require.ErrorIs(t, err, ErrNilAuthenticator)
+	require.Nil(t, a)
+
+	a, err = New(newTestJWT(t), WithLogger(nil), WithAppInfo(nil))
+	require.NoError(t, err)
+	require.NotNil(t, a.logger)
+	require.NotNil(t, a.appInfo)
+	require.NotNil(t, a.jsx)
+}
+
+//nolint:gocognit
+func TestRequire(t *testing.T) {
+	t.Parallel()
+
+	auth := newTestJWT(t)
+
+	tests := []struct {
+		name      string
+		mw        func(a *Authorizer) httpserver.MiddlewareFn
+		username  string
+		rawToken  string
+		status    int
+		challenge string
+	}{
+		{
+			name:      "missing token",
+			mw:        func(a *Authorizer) httpserver.MiddlewareFn { return a.Require(Authenticated()) },
+			status:    http.StatusUnauthorized,
+			challenge: "Bearer",
+		},
+		{
+			name:      "invalid token",
+			mw:        func(a *Authorizer) httpserver.MiddlewareFn { return a.Require(Authenticated()) },
+			rawToken:  "not.a.token",
+			status:    http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token"`,
+		},
+		{
+			name:     "authenticated",
+			mw:       func(a *Authorizer) httpserver.MiddlewareFn { return a.Require(Authenticated()) },
+			username: "nobody",
+			status:   http.StatusOK,
+		},
+		{
+			name:     "scopes granted",
+			mw:       func(a *Authorizer) httpserver.MiddlewareFn { return a.RequireScopes("orders:read", "orders:write") },
+			username: "admin",
+			status:   http.StatusOK,
+		},
+		{
+			name:      "scopes denied",
+			mw:        func(a *Authorizer) httpserver.MiddlewareFn { return a.RequireScopes("orders:read", "orders:write") },
+			username:  "reader",
+			status:    http.StatusForbidden,
+			challenge: `Bearer error="insufficient_scope", scope="orders:read orders:write"`,
+		},
+		{
+			name:     "role granted",
+			mw:       func(a *Authorizer) httpserver.MiddlewareFn { return a.RequireAnyRole("admin", "support") },
+			username: "admin",
+			status:   http.StatusOK,
+		},
+		{
+			name:      "role denied",
+			mw:        func(a *Authorizer) httpserver.MiddlewareFn { return a.RequireAnyRole("admin") },
+			username:  "reader",
+			status:    http.StatusForbidden,
+			challenge: `Bearer error="insufficient_scope"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logs bytes.Buffer
+
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+			a, err := New(auth, WithAppInfo(&jsendx.AppInfo{ProgramName: "test"}))
+			require.NoError(t, err)
+
+			token := tt.rawToken
+			if tt.username != "" {
+				token = issue(t, auth, tt.username)
+			}
+
+			var got *jwt.Claims
+
+			args := httpserver.MiddlewareArgs{Logger: logger}
+			handler := httpserver.ApplyMiddleware(args, okHandler(&got), tt.mw(a))
+
+			rr := serve(t, handler, http.MethodGet, "/orders", token)
+			require.Equal(t, tt.status, rr.Code)
+			require.Equal(t, tt.challenge, rr.Header().Get("WWW-Authenticate"))
+
+			if tt.status == http.StatusOK {
+				require.NotNil(t, got, "the verified claims are shared downstream")
+				require.Equal(t, tt.username, got.Subject)
+
+				return
+			}
+
+			require.Nil(t, got)
+
+			var resp jsendx.Response
+
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tt.status, resp.Code)
+			require.Equal(t, "test", resp.Program)
+
+			var entry map[string]any
+
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+			require.Equal(t, "trace-123", entry[traceid.DefaultLogKey])
+			require.Equal(t, "/orders", entry["request_path"])
+
+			if tt.status == http.StatusForbidden {
+				require.Equal(t, "authorization denied", entry["msg"])
+				require.NotEmpty(t, entry["policy"])
+
+				claims, ok := entry["claims"].(map[string]any)
+				require.True(t, ok)
+				require.Equal(t, tt.username, claims["sub"])
+				require.NotEmpty(t, claims["jti"])
+				require.Equal(t, []any{"orders:read"}, claims["scope"])
+			}
+		})
+	}
+}
+
+func TestRequireReusesUpstreamClaims(t *testing.T) {
+	t.Parallel()
+
+	auth := newTestJWT(t)
+
+	a, err := New(auth)
+	require.NoError(t, err)
+
+	var got *jwt.Claims
+
+	inner := httpserver.ApplyMiddleware(httpserver.MiddlewareArgs{}, okHandler(&got), a.RequireAnyRole("admin"))
+
+	rr := serve(t, auth.Middleware(inner), http.MethodGet, "/", issue(t, auth, "admin"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, got.HasRole("admin"))
+
+	// Claims placed upstream are trusted without re-authentication.
+	stub := &jwt.Claims{Subject: "stub", Roles: []string{"admin"}}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(jwt.ContextWithClaims(r.Context(), stub)))
+	})
+
+	rr = serve(t, handler, http.MethodGet, "/", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Same(t, stub, got)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	auth := newTestJWT(t)
+
+	a, err := New(auth)
+	require.NoError(t, err)
+
+	var order []string
+
+	routeMW := func(_ httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "route")
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	routes := []httpserver.Route{
+		{Method: http.MethodGet, Path: "/public"},
+		{Method: http.MethodPost, Path: "/orders", Middleware: []httpserver.MiddlewareFn{routeMW}},
+	}
+
+	_, err = a.Apply(routes, PolicyTable{
+		RouteKey(http.MethodPost, "/orders"):  AllScopes("orders:write"),
+		RouteKey(http.MethodPost, "/ordres"):  AllScopes("orders:write"),
+		RouteKey(http.MethodGet, "/missing"):  Authenticated(),
+		RouteKey(http.MethodGet, "/public"):   Authenticated(),
+		RouteKey(http.MethodDelete, "/other"): Authenticated(),
+	})
+	require.ErrorIs(t, err, ErrUnknownRoute)
+	require.ErrorContains(t, err, "DELETE /other, GET /missing, POST /ordres")
+
+	out, err := a.Apply(routes, PolicyTable{
+		RouteKey(http.MethodPost, "/orders"): AllScopes("orders:write"),
+	})
+	require.NoError(t, err)
+	require.Empty(t, out[0].Middleware)
+	require.Len(t, out[1].Middleware, 2)
+	require.Len(t, routes[1].Middleware, 1, "the input routes are not modified")
+
+	handler := httpserver.ApplyMiddleware(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		order = append(order, "handler")
+	}), out[1].Middleware...)
+
+	rr := serve(t, handler, http.MethodPost, "/orders", issue(t, auth, "reader"))
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Empty(t, order, "the policy runs ahead of the route middleware")
+
+	rr = serve(t, handler, http.MethodPost, "/orders", issue(t, auth, "admin"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, []string{"route", "handler"}, order)
+}
@@ -0,0 +1,77 @@
+package authz_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/jwt"
+	"github.com/tecnickcom/nurago/pkg/jwt/authz"
+)
+
+// Example protects a route with a scope policy: a reader token is refused with
+// 403, while a writer token reaches the handler.
+func Example() {
+	grants := func(_ context.Context, username string) ([]string, []string, error) {
+		if username == "writer" {
+			return []string{"orders:read", "orders:write"}, nil, nil
+		}
+
+		return []string{"orders:read"}, nil, nil
+	}
+
+	auth, err := jwt.New(
+		[]byte("0123456789abcdef0123456789abcdef"),
+		func(_, _ string) (bool, error) { return false, nil },
+		jwt.WithGrantsFn(grants),
+	)
+	if err != nil {
+		fmt.Println(err)
+
+		return
+	}
+
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	az, err := authz.New(auth, authz.WithLogger(discard))
+	if err != nil {
+		fmt.Println(err)
+
+		return
+	}
+
+	createOrder := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := httpserver.ApplyMiddleware(
+		httpserver.MiddlewareArgs{Logger: discard},
+		createOrder,
+		az.RequireScopes("orders:write"),
+	)
+
+	for _, user := range []string{"reader", "writer"} {
+		token, err := auth.IssueToken(user)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		fmt.Println(user, rr.Code)
+	}
+
+	// Output:
+	// reader 403
+	// writer 201
+}
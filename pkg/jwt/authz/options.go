@@ -0,0 +1,28 @@
+package authz
+
+import (
+	"log/slog"
+
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+)
+
+// Option is a type to allow setting custom authorizer options.
+type Option func(*Authorizer)
+
+// WithAppInfo sets the application metadata of the JSendX responses.
+func WithAppInfo(info *jsendx.AppInfo) Option {
+	return func(a *Authorizer) {
+		if info != nil {
+			a.appInfo = info
+		}
+	}
+}
+
+// WithLogger sets the logger used when the middleware receives none from
+// [github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareArgs], and by the
+// response writer. A nil logger restores slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(a *Authorizer) {
+		a.logger = logger
+	}
+}
@@ -0,0 +1,30 @@
+package authz
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httputil/jsendx"
+)
+
+func TestWithAppInfo(t *testing.T) {
+	t.Parallel()
+
+	a := &Authorizer{}
+	info := &jsendx.AppInfo{ProgramName: "test"}
+	WithAppInfo(info)(a)
+	require.Equal(t, info, a.appInfo)
+
+	WithAppInfo(nil)(a)
+	require.Equal(t, info, a.appInfo)
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	a := &Authorizer{}
+	l := slog.Default()
+	WithLogger(l)(a)
+	require.Equal(t, l, a.logger)
+}
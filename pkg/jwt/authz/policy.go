@@ -0,0 +1,157 @@
+package authz
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/jwt"
+)
+
+// Policy kinds, as named in the policy descriptions.
+const (
+	kindScopes = "scopes"
+	kindRoles  = "roles"
+)
+
+// Policy is an authorization rule evaluated against verified token claims.
+//
+// The zero value denies every request, and so does every constructor given an
+// empty list, so a misconfigured policy fails closed.
+type Policy struct {
+	name   string
+	scopes []string // scopes named by the policy, advertised in the 403 challenge
+	allow  func(claims *jwt.Claims) bool
+}
+
+// NewPolicy returns a custom policy admitting the claims for which allow
+// returns true. The name identifies the policy in the logs.
+func NewPolicy(name string, allow func(claims *jwt.Claims) bool) Policy {
+	return Policy{name: name, allow: allow}
+}
+
+// Allow reports whether the policy admits claims. Nil claims are never admitted.
+func (p Policy) Allow(claims *jwt.Claims) bool {
+	if p.allow == nil || claims == nil {
+		return false
+	}
+
+	return p.allow(claims)
+}
+
+// String returns the policy description used in the logs.
+func (p Policy) String() string {
+	if p.name == "" {
+		return "deny"
+	}
+
+	return p.name
+}
+
+// Authenticated admits any request carrying a valid token.
+func Authenticated() Policy {
+	return Policy{
+		name:  "authenticated",
+		allow: func(_ *jwt.Claims) bool { return true },
+	}
+}
+
+// AllScopes admits tokens granting every one of scopes.
+func AllScopes(scopes ...string) Policy {
+	return listPolicy(kindScopes, true, scopes, (*jwt.Claims).HasScope)
+}
+
+// AnyScope admits tokens granting at least one of scopes.
+func AnyScope(scopes ...string) Policy {
+	return listPolicy(kindScopes, false, scopes, (*jwt.Claims).HasScope)
+}
+
+// AllRoles admits tokens granting every one of roles.
+func AllRoles(roles ...string) Policy {
+	return listPolicy(kindRoles, true, roles, (*jwt.Claims).HasRole)
+}
+
+// AnyRole admits tokens granting at least one of roles.
+func AnyRole(roles ...string) Policy {
+	return listPolicy(kindRoles, false, roles, (*jwt.Claims).HasRole)
+}
+
+// AllOf admits the requests admitted by every one of policies.
+func AllOf(policies ...Policy) Policy {
+	return combine(true, policies)
+}
+
+// AnyOf admits the requests admitted by at least one of policies.
+func AnyOf(policies ...Policy) Policy {
+	return combine(false, policies)
+}
+
+// listPolicy builds a policy requiring all (or any) of values, as tested by has.
+func listPolicy(kind string, all bool, values []string, has func(*jwt.Claims, string) bool) Policy {
+	if len(values) == 0 {
+		return Policy{}
+	}
+
+	values = slices.Clone(values)
+
+	p := Policy{
+		name: kind + "(" + modeName(all) + ": " + strings.Join(values, " ") + ")",
+		allow: func(claims *jwt.Claims) bool {
+			return matches(all, values, func(v string) bool { return has(claims, v) })
+		},
+	}
+
+	if kind == kindScopes {
+		p.scopes = values
+	}
+
+	return p
+}
+
+// combine builds a policy requiring all (or any) of policies, advertising the
+// union of their scopes.
+func combine(all bool, policies []Policy) Policy {
+	if len(policies) == 0 {
+		return Policy{}
+	}
+
+	policies = slices.Clone(policies)
+	names := make([]string, 0, len(policies))
+
+	var scopes []string
+
+	for _, p := range policies {
+		names = append(names, p.String())
+
+		for _, s := range p.scopes {
+			if !slices.Contains(scopes, s) {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+
+	return Policy{
+		name:   modeName(all) + "(" + strings.Join(names, ", ") + ")",
+		scopes: scopes,
+		allow: func(claims *jwt.Claims) bool {
+			return matches(all, policies, func(p Policy) bool { return p.Allow(claims) })
+		},
+	}
+}
+
+// matches reports whether all (or any) of items satisfy fn.
+func matches[T any](all bool, items []T, fn func(T) bool) bool {
+	if all {
+		return !slices.ContainsFunc(items, func(v T) bool { return !fn(v) })
+	}
+
+	return slices.ContainsFunc(items, fn)
+}
+
+// modeName names the all/any combination mode in policy descriptions.
+func modeName(all bool) string {
+	if all {
+		return "all"
+	}
+
+	return "any"
+}
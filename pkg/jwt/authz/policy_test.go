@@ -0,0 +1,91 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/jwt"
+)
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+
+	claims := &jwt.Claims{
+		Scope: jwt.Scopes{"orders:read", "orders:write"},
+		Roles: []string{"support"},
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   bool
+		desc   string
+	}{
+		{name: "zero value denies", policy: Policy{}, want: false, desc: "deny"},
+		{name: "authenticated", policy: Authenticated(), want: true, desc: "authenticated"},
+		{name: "all scopes granted", policy: AllScopes("orders:read", "orders:write"), want: true, desc: "scopes(all: orders:read orders:write)"},
+		{name: "all scopes missing one", policy: AllScopes("orders:read", "orders:admin"), want: false},
+		{name: "any scope granted", policy: AnyScope("orders:admin", "orders:read"), want: true, desc: "scopes(any: orders:admin orders:read)"},
+		{name: "any scope missing", policy: AnyScope("orders:admin"), want: false},
+		{name: "empty scopes deny", policy: AllScopes(), want: false, desc: "deny"},
+		{name: "all roles granted", policy: AllRoles("support"), want: true, desc: "roles(all: support)"},
+		{name: "all roles missing one", policy: AllRoles("support", "admin"), want: false},
+		{name: "any role granted", policy: AnyRole("admin", "support"), want: true},
+		{name: "any role missing", policy: AnyRole("admin"), want: false},
+		{name: "empty roles deny", policy: AnyRole(), want: false},
+		{
+			name:   "all of",
+			policy: AllOf(AnyScope("orders:read"), AnyRole("support")),
+			want:   true,
+			desc:   "all(scopes(any: orders:read), roles(any: support))",
+		},
+		{name: "all of denied", policy: AllOf(AnyScope("orders:read"), AnyRole("admin")), want: false},
+		{name: "any of", policy: AnyOf(AnyRole("admin"), AnyScope("orders:write")), want: true},
+		{name: "any of denied", policy: AnyOf(AnyRole("admin"), AnyScope("orders:admin")), want: false},
+		{name: "empty all of denies", policy: AllOf(), want: false},
+		{name: "empty any of denies", policy: AnyOf(), want: false},
+		{
+			name:   "custom",
+			policy: NewPolicy("support-only", func(c *jwt.Claims) bool { return c.HasRole("support") }),
+			want:   true,
+			desc:   "support-only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.policy.Allow(claims))
+			require.False(t, tt.policy.Allow(nil), "nil claims are never admitted")
+
+			if tt.desc != "" {
+				require.Equal(t, tt.desc, tt.policy.String())
+			}
+		})
+	}
+}
+
+func TestPolicyScopesUnion(t *testing.T) {
+	t.Parallel()
+
+	p := AnyOf(AllScopes("a", "b"), AnyRole("admin"), AnyScope("b", "c"))
+	require.Equal(t, []string{"a", "b", "c"}, p.scopes)
+	require.Empty(t, AnyRole("admin").scopes)
+}
+
+func TestPolicyCopiesArguments(t *testing.T) {
+	t.Parallel()
+
+	scopes := []string{"orders:read"}
+	p := AllScopes(scopes...)
+	scopes[0] = "orders:admin"
+
+	require.True(t, p.Allow(&jwt.Claims{Scope: jwt.Scopes{"orders:read"}}))
+
+	policies := []Policy{Authenticated()}
+	all := AllOf(policies...)
+	policies[0] = Policy{}
+
+	require.True(t, all.Allow(&jwt.Claims{}))
+}
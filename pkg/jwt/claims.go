@@ -4,11 +4,14 @@ package jwt
 // the JSON encodings of the RFC 7519 claim value types.
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -106,8 +109,46 @@ func (a *Audience) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Scopes is the RFC 8693 §4.2 `scope` claim: the access-token scopes. It
+// marshals as a single space-delimited JSON string, as the RFC mandates, and
+// also unmarshals from an array of strings, as issued by some identity
+// providers.
+type Scopes []string
+
+// MarshalJSON encodes the scopes as one space-delimited string.
+func (s Scopes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(s, " ")) //nolint:wrapcheck // marshaling a string never fails
+}
+
+// UnmarshalJSON decodes either a space-delimited JSON string or an array of strings.
+func (s *Scopes) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var joined string
+
+		err := json.Unmarshal(data, &joined)
+		if err != nil {
+			return fmt.Errorf("jwt: invalid scope: %w", err)
+		}
+
+		*s = strings.Fields(joined)
+
+		return nil
+	}
+
+	var values []string
+
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("jwt: invalid scope: %w", err)
+	}
+
+	*s = values
+
+	return nil
+}
+
 // Claims holds the JWT payload: the RFC 7519 registered claims plus the
-// package-specific `username` and `auth_time` claims.
+// package-specific `username`, `auth_time`, `sid`, `scope` and `roles` claims.
 type Claims struct {
 	// Issuer is the `iss` (Issuer) claim. See https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.1
 	Issuer string `json:"iss,omitempty"`
@@ -139,19 +180,46 @@ type Claims struct {
 	// renewals, so [WithMaxSessionLifetime] can bound the total session length.
 	// It follows the OpenID Connect `auth_time` claim (OIDC Core §2).
 	AuthTime *NumericDate `json:"auth_time,omitempty"`
+
+	// SessionID identifies the login session and is preserved across renewals
+	// and refresh-token rotations, so a whole session can be revoked at once.
+	// It follows the OpenID Connect `sid` claim (OIDC Front-Channel Logout §3).
+	SessionID string `json:"sid,omitempty"`
+
+	// Scope lists the granted scopes (the RFC 8693 §4.2 `scope` claim), as set
+	// by the [GrantsFn] at issuance.
+	Scope Scopes `json:"scope,omitempty"`
+
+	// Roles lists the granted roles (the RFC 9068 §2.2.3.1 `roles` claim), as set
+	// by the [GrantsFn] at issuance.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// HasScope reports whether the claims grant scope.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scope, scope)
+}
+
+// HasRole reports whether the claims grant role.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
 }
 
 // newClaims builds fresh token claims for username, with expiration, issue, and
 // not-before times relative to the current time and a new unique token ID. The
-// authTime is preserved across renewals; when nil (a fresh login) it defaults to
-// the current time.
-func (c *JWT) newClaims(username string, authTime *NumericDate) *Claims {
+// authTime and sessionID are preserved across renewals; when nil or empty (a
+// fresh login) they default to the current time and a new session ID.
+func (c *JWT) newClaims(username string, authTime *NumericDate, sessionID string) *Claims {
 	tnow := time.Now().UTC()
 
 	if authTime == nil {
 		authTime = NewNumericDate(tnow)
 	}
 
+	if sessionID == "" {
+		sessionID = c.rnd.UUIDv7().String()
+	}
+
 	exp := tnow.Add(c.expirationTime)
 
 	// Never mint a token that outlives the absolute session cap measured from the
@@ -173,6 +241,25 @@ func (c *JWT) newClaims(username string, authTime *NumericDate) *Claims {
 		Subject:   username,                // sub: the authenticated principal
 		Audience:  Audience(c.audience),    // aud
 		Username:  username,
-		AuthTime:  authTime, // auth_time: original login, preserved across renewals
+		AuthTime:  authTime,  // auth_time: original login, preserved across renewals
+		SessionID: sessionID, // sid: login session, preserved across renewals
 	}
 }
+
+// applyGrants sets the scopes and roles of claims from the configured
+// [GrantsFn], if any.
+func (c *JWT) applyGrants(ctx context.Context, claims *Claims) error {
+	if c.grantsFn == nil {
+		return nil
+	}
+
+	scopes, roles, err := c.grantsFn(ctx, claims.Username)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrGrants, err)
+	}
+
+	claims.Scope = Scopes(slices.Clone(scopes))
+	claims.Roles = slices.Clone(roles)
+
+	return nil
+}
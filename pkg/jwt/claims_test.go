@@ -98,6 +98,44 @@ func TestAudienceMarshalsAsArray(t *testing.T) {
 	require.JSONEq(t, `["one"]`, string(b))
 }
 
+func TestScopesJSON(t *testing.T) {
+	t.Parallel()
+
+	// RFC 8693 §4.2: scopes travel as one space-delimited string.
+	b, err := json.Marshal(Scopes{"read", "write"})
+	require.NoError(t, err)
+	require.JSONEq(t, `"read write"`, string(b))
+
+	var s Scopes
+
+	require.NoError(t, s.UnmarshalJSON([]byte(`" read  write "`)))
+	require.Equal(t, Scopes{"read", "write"}, s)
+
+	// The array form used by some identity providers.
+	require.NoError(t, s.UnmarshalJSON([]byte(`["a","b"]`)))
+	require.Equal(t, Scopes{"a", "b"}, s)
+
+	require.Error(t, s.UnmarshalJSON([]byte(`"unterminated`)))
+	require.Error(t, s.UnmarshalJSON([]byte(`123`)))
+
+	// An empty scope is omitted from the claims.
+	b, err = json.Marshal(&Claims{Username: "u"})
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "scope")
+	require.NotContains(t, string(b), "roles")
+}
+
+func TestClaimsHasScopeAndRole(t *testing.T) {
+	t.Parallel()
+
+	c := &Claims{Scope: Scopes{"read"}, Roles: []string{"admin"}}
+
+	require.True(t, c.HasScope("read"))
+	require.False(t, c.HasScope("write"))
+	require.True(t, c.HasRole("admin"))
+	require.False(t, c.HasRole("user"))
+}
+
 func TestNewClaimsClampsExpToSessionLifetime(t *testing.T) {
 	t.Parallel()
 
@@ -132,7 +170,7 @@ func TestNewClaimsClampsExpToSessionLifetime(t *testing.T) {
 			}
 
 			before := time.Now().UTC()
-			claims := c.newClaims("clamp-user", authTime)
+			claims := c.newClaims("clamp-user", authTime, "")
 			after := time.Now().UTC()
 
 			require.NotNil(t, claims.ExpiresAt)
@@ -172,10 +210,10 @@ func TestNewClaimsClampedTokenStillValidates(t *testing.T) {
 	// one second in the future.
 	authTime := NewNumericDate(time.Now().UTC().Add(-5*time.Minute + time.Second))
 
-	token, err := c.signToken(c.newClaims("boundary-user", authTime))
+	token, err := c.signToken(c.newClaims("boundary-user", authTime, ""))
 	require.NoError(t, err)
 
-	claims, err := c.parseToken(token)
+	claims, err := c.parseToken(t.Context(), token)
 	require.NoError(t, err, "a token clamped to the cap must still validate right after issue")
 	require.True(t, claims.ExpiresAt.After(time.Now()))
 }
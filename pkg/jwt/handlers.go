@@ -51,8 +51,15 @@ type Credentials struct {
 // the verified claims.
 type claimsCtxKey struct{}
 
+// ContextWithClaims returns a copy of ctx carrying claims, for retrieval via
+// [ClaimsFromContext]. It lets other authentication middleware (e.g. the authz
+// subpackage) share the verified claims the same way [JWT.Middleware] does.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsCtxKey{}, claims)
+}
+
 // ClaimsFromContext returns the verified claims stored in ctx by
-// [JWT.Middleware], reporting whether they were present.
+// [JWT.Middleware] or [ContextWithClaims], reporting whether they were present.
 func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
 	claims, ok := ctx.Value(claimsCtxKey{}).(*Claims)
 
@@ -63,7 +70,8 @@ func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
 //
 // It expects a JSON body with username/password (capped at the configured max
 // body size), verifies them via [VerifyCredentialsFn], and replies with a token
-// on success. The caller is responsible for restricting the HTTP method. Its
+// on success, or with a [TokenPair] when refresh tokens are enabled
+// ([WithRefreshTokens]). The caller is responsible for restricting the HTTP method. Its
 // 401 response carries no WWW-Authenticate challenge: credentials travel in the
 // JSON body, not in an HTTP authentication scheme.
 func (c *JWT) LoginHandler(w http.ResponseWriter, r *http.Request) {
@@ -71,37 +79,16 @@ func (c *JWT) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	// Guard against a nil Body (handlers can be invoked directly, e.g. in tests);
-	// http.MaxBytesReader would otherwise panic on the first read/close.
-	if r.Body == nil {
-		r.Body = http.NoBody
-	}
-
-	r.Body = http.MaxBytesReader(w, r.Body, c.maxBodyBytes)
-
-	defer func() {
-		cerr := r.Body.Close()
-		if cerr != nil {
-			c.logger.ErrorContext(ctx, "error closing request body", slog.Any("error", cerr))
-		}
-	}()
-
-	dec := json.NewDecoder(r.Body)
-	dec.DisallowUnknownFields()
-
-	err := dec.Decode(&creds)
-	if err != nil {
-		c.sendDecodeError(ctx, w, err)
+	// A verification-only instance has neither a signing key nor (necessarily)
+	// a credential verifier: refuse before touching either.
+	if !c.canSign() {
+		c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to sign the JWT token")
+		c.logger.ErrorContext(ctx, "JWT login on a verification-only instance", slog.Any("error", ErrNoSigningKey))
 
 		return
 	}
 
-	// Reject trailing data after the JSON object (strictness parity with
-	// DisallowUnknownFields): a well-formed request carries exactly one value.
-	if dec.More() {
-		c.sendResponseFn(ctx, w, http.StatusBadRequest, "invalid request body")
-		c.logger.WarnContext(ctx, "trailing data after JWT login body")
-
+	if !c.decodeBody(w, r, &creds) {
 		return
 	}
 
@@ -125,7 +112,22 @@ func (c *JWT) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c.sendTokenResponse(w, r, c.newClaims(creds.Username, nil))
+	claims := c.newClaims(creds.Username, nil, "")
+
+	err = c.applyGrants(ctx, claims)
+	if err != nil {
+		c.sendIssueError(ctx, w, claims, err)
+
+		return
+	}
+
+	if c.refreshStore != nil {
+		c.sendTokenPair(w, r, claims)
+
+		return
+	}
+
+	c.sendTokenResponse(w, r, claims)
 }
 
 // RenewHandler renews a valid token when it is close to expiration.
@@ -134,10 +136,10 @@ func (c *JWT) LoginHandler(w http.ResponseWriter, r *http.Request) {
 // configured by renewTime, or past the configured maximum session lifetime. The
 // caller is responsible for restricting the HTTP method.
 //
-// Renewal does not invalidate the presented token: being stateless, it stays
-// valid until its own expiration. Likewise, when the maximum session lifetime
-// is exceeded only the renewal is refused; the presented token still authorizes
-// requests until it expires.
+// Renewal does not invalidate the presented token: it stays valid until its own
+// expiration unless revoked through [JWT.LogoutHandler]. Likewise, when the
+// maximum session lifetime is exceeded only the renewal is refused; the
+// presented token still authorizes requests until it expires.
 func (c *JWT) RenewHandler(w http.ResponseWriter, r *http.Request) {
 	claims, err := c.checkToken(r)
 	if err != nil {
@@ -175,9 +177,11 @@ func (c *JWT) RenewHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Issue a token with fresh registered claims (exp, iat, nbf, jti) but the
-	// preserved session start, so the renewed token extends expiration without
-	// resetting the session clock.
-	renewed := c.newClaims(claims.Username, sessionStart)
+	// preserved session start and ID, so the renewed token extends expiration
+	// without resetting the session clock or escaping a session revocation.
+	renewed := c.newClaims(claims.Username, sessionStart, claims.SessionID)
+	renewed.Scope = claims.Scope
+	renewed.Roles = claims.Roles
 
 	// A renewal can yield a token that, after exp is truncated to whole seconds at
 	// signing, is already expired. Refuse it rather than returning 200 with a
@@ -250,7 +254,7 @@ func (c *JWT) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsCtxKey{}, claims)))
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
 	})
 }
 
@@ -272,7 +276,50 @@ func (c *JWT) sendTokenResponse(w http.ResponseWriter, r *http.Request, claims *
 	c.sendResponseFn(r.Context(), w, http.StatusOK, signedToken)
 }
 
-// sendDecodeError maps a login body decode failure to an HTTP response. An
+// decodeBody strictly decodes the JSON request body into v: the body is capped
+// at the configured max body size, and unknown fields or trailing data are
+// rejected. On failure it writes the error response and returns false.
+func (c *JWT) decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	ctx := r.Context()
+
+	// Guard against a nil Body (handlers can be invoked directly, e.g. in tests);
+	// http.MaxBytesReader would otherwise panic on the first read/close.
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, c.maxBodyBytes)
+
+	defer func() {
+		cerr := r.Body.Close()
+		if cerr != nil {
+			c.logger.ErrorContext(ctx, "error closing request body", slog.Any("error", cerr))
+		}
+	}()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err != nil {
+		c.sendDecodeError(ctx, w, err)
+
+		return false
+	}
+
+	// Reject trailing data after the JSON object (strictness parity with
+	// DisallowUnknownFields): a well-formed request carries exactly one value.
+	if dec.More() {
+		c.sendResponseFn(ctx, w, http.StatusBadRequest, "invalid request body")
+		c.logger.WarnContext(ctx, "trailing data after JWT request body")
+
+		return false
+	}
+
+	return true
+}
+
+// sendDecodeError maps a request body decode failure to an HTTP response. An
 // oversize body is reported as 413; any other decode error as a generic 400. The
 // parser detail is kept in the server log only, so nothing internal is leaked.
 func (c *JWT) sendDecodeError(ctx context.Context, w http.ResponseWriter, err error) {
@@ -284,7 +331,7 @@ func (c *JWT) sendDecodeError(ctx context.Context, w http.ResponseWriter, err er
 	}
 
 	c.sendResponseFn(ctx, w, status, msg)
-	c.logger.WarnContext(ctx, "invalid JWT login body", slog.Any("error", err))
+	c.logger.WarnContext(ctx, "invalid JWT request body", slog.Any("error", err))
 }
 
 // writeUnauthorized writes a 401 response carrying the RFC 6750 Bearer
@@ -321,5 +368,5 @@ func (c *JWT) checkToken(r *http.Request) (*Claims, error) {
 		return &Claims{}, ErrMissingToken
 	}
 
-	return c.parseToken(signedToken)
+	return c.parseToken(r.Context(), signedToken)
 }
@@ -327,10 +327,10 @@ func TestRenewHandlerIssuesFreshToken(t *testing.T) {
 	// The renewed token must not be a re-signed copy of the old one.
 	require.NotEqual(t, string(oldToken), string(newToken))
 
-	oldClaims, err := c.parseToken(string(oldToken))
+	oldClaims, err := c.parseToken(t.Context(), string(oldToken))
 	require.NoError(t, err)
 
-	newClaims, err := c.parseToken(string(newToken))
+	newClaims, err := c.parseToken(t.Context(), string(newToken))
 	require.NoError(t, err)
 
 	require.True(t, newClaims.ExpiresAt.After(oldClaims.ExpiresAt.Time), "renewed token must expire later than the original")
@@ -462,7 +462,7 @@ func TestRenewHandlerClampsExpToSessionLifetime(t *testing.T) {
 
 	renewed, _ := io.ReadAll(resp.Body)
 
-	claims, err := c.parseToken(string(renewed))
+	claims, err := c.parseToken(t.Context(), string(renewed))
 	require.NoError(t, err)
 
 	require.LessOrEqual(t, claims.ExpiresAt.Unix(), authTime.Add(maxLifetime).Unix(),
@@ -754,6 +754,16 @@ func TestMiddleware(t *testing.T) {
 	require.Equal(t, challengeBearer, rr2.Header().Get(headerWWWAuthenticate))
 }
 
+func TestContextWithClaims(t *testing.T) {
+	t.Parallel()
+
+	claims := &Claims{Username: "test-name"}
+
+	got, ok := ClaimsFromContext(ContextWithClaims(t.Context(), claims))
+	require.True(t, ok)
+	require.Same(t, claims, got)
+}
+
 func TestClaimsFromContextMissing(t *testing.T) {
 	t.Parallel()
 
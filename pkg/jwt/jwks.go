@@ -0,0 +1,563 @@
+package jwt
+
+// This file contains the RFC 7517 JSON Web Key Set support: the JWK encoding of
+// the asymmetric public keys, the JWKS publisher handler, and the JWKS consumer
+// key set with single-flight refresh.
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/sfcache"
+)
+
+const (
+	// MimeTypeJWKSet is the RFC 7517 §8.5 media type of a JWK Set document.
+	MimeTypeJWKSet = "application/jwk-set+json"
+
+	// DefaultJWKSTimeout is the default timeout of a JWKS fetch.
+	DefaultJWKSTimeout = 10 * time.Second
+
+	// DefaultJWKSRefreshInterval is the default time a fetched JWKS document is
+	// used before it is fetched again.
+	DefaultJWKSRefreshInterval = 5 * time.Minute
+
+	// DefaultJWKSMinRefreshInterval is the default minimum time between two
+	// refreshes forced by tokens carrying an unknown `kid`.
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+
+	// DefaultJWKSMaxStale is the default time past its refresh interval the
+	// last fetched JWKS document keeps serving stale keys.
+	DefaultJWKSMaxStale = time.Hour
+
+	// DefaultJWKSMaxBodyBytes caps the size of a fetched JWKS document. A set of
+	// a few RSA-4096 keys is well under 16 KiB.
+	DefaultJWKSMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+	// jwksCacheControl lets clients and proxies cache the published JWKS for
+	// the default consumer refresh interval.
+	jwksCacheControl = "public, max-age=300"
+
+	// jwkUseSig is the RFC 7517 §4.2 `use` value of a signature key.
+	jwkUseSig = "sig"
+)
+
+// JWKS errors.
+var (
+	// ErrInvalidJWK is returned when a JWK cannot be converted to or from a
+	// supported public key.
+	ErrInvalidJWK = errors.New("jwt: invalid JWK")
+
+	// ErrInvalidJWKS is returned when a fetched JWKS document cannot be used: a
+	// non-200 response, an oversize or malformed body, or no usable key.
+	ErrInvalidJWKS = errors.New("jwt: invalid JWKS document")
+
+	// ErrInvalidJWKSOptions is returned by [NewJWKSKeySet] for an invalid URL
+	// or setting.
+	ErrInvalidJWKSOptions = errors.New("jwt: invalid JWKS key set options")
+)
+
+// HTTPClient is the minimal HTTP transport contract used by [JWKSKeySet].
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// JWK is an RFC 7517 JSON Web Key holding an RSA, EC or OKP (Ed25519) public
+// key. Private key members are never encoded, and are ignored when decoding.
+type JWK struct {
+	KeyType string `json:"kty"`
+	Use     string `json:"use,omitempty"`
+	Alg     string `json:"alg,omitempty"`
+	KeyID   string `json:"kid,omitempty"`
+	Curve   string `json:"crv,omitempty"`
+	N       string `json:"n,omitempty"`
+	E       string `json:"e,omitempty"`
+	X       string `json:"x,omitempty"`
+	Y       string `json:"y,omitempty"`
+}
+
+// JWKSet is an RFC 7517 §5 JWK Set document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK encodes a verification key as a JWK with `use` set to "sig".
+func NewJWK(key VerificationKey) (JWK, error) {
+	jwk := JWK{Use: jwkUseSig, Alg: key.Alg, KeyID: key.KeyID}
+
+	switch k := key.Key.(type) {
+	case *rsa.PublicKey:
+		if k == nil || k.N == nil || k.E <= 0 {
+			return jwk, fmt.Errorf("%w: incomplete RSA key", ErrInvalidJWK)
+		}
+
+		jwk.KeyType = "RSA"
+		jwk.N = b64(k.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		point, err := k.Bytes()
+		if err != nil {
+			return jwk, fmt.Errorf("%w: %w", ErrInvalidJWK, err)
+		}
+
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = k.Params().Name
+		jwk.X = b64(point[1 : 1+size])
+		jwk.Y = b64(point[1+size:])
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return jwk, fmt.Errorf("%w: invalid Ed25519 key size", ErrInvalidJWK)
+		}
+
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = b64(k)
+	default:
+		return jwk, fmt.Errorf("%w: unsupported key type %T", ErrInvalidJWK, key.Key)
+	}
+
+	return jwk, nil
+}
+
+// VerificationKey decodes the JWK into a verification key. Keys whose `use` is
+// set to anything other than "sig" are rejected.
+func (k JWK) VerificationKey() (VerificationKey, error) {
+	vk := VerificationKey{KeyID: k.KeyID, Alg: k.Alg}
+
+	if k.Use != "" && k.Use != jwkUseSig {
+		return vk, fmt.Errorf("%w: not a signature key (use %q)", ErrInvalidJWK, k.Use)
+	}
+
+	var err error
+
+	switch k.KeyType {
+	case "RSA":
+		vk.Key, err = k.rsaKey()
+	case "EC":
+		vk.Key, err = k.ecdsaKey()
+	case "OKP":
+		vk.Key, err = k.ed25519Key()
+	default:
+		err = fmt.Errorf("%w: unsupported key type %q", ErrInvalidJWK, k.KeyType)
+	}
+
+	return vk, err
+}
+
+// rsaKey decodes the RSA modulus and exponent (RFC 7518 §6.3.1).
+func (k JWK) rsaKey() (*rsa.PublicKey, error) {
+	n, err := unb64(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("%w: invalid RSA modulus", ErrInvalidJWK)
+	}
+
+	e, err := unb64(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("%w: invalid RSA exponent", ErrInvalidJWK)
+	}
+
+	exp := new(big.Int).SetBytes(e).Int64()
+	if exp < 3 || exp%2 == 0 {
+		return nil, fmt.Errorf("%w: invalid RSA exponent", ErrInvalidJWK)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp)}, nil
+}
+
+// ecdsaKey decodes an EC point; the coordinates must have the full length of
+// the curve (RFC 7518 §6.2.1.2).
+func (k JWK) ecdsaKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+
+	switch k.Curve {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWK, k.Curve)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+
+	x, xerr := unb64(k.X)
+	y, yerr := unb64(k.Y)
+
+	if xerr != nil || yerr != nil || len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("%w: invalid EC coordinates", ErrInvalidJWK)
+	}
+
+	pub, err := ecdsa.ParseUncompressedPublicKey(curve, slices.Concat([]byte{4}, x, y))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWK, err)
+	}
+
+	return pub, nil
+}
+
+// ed25519Key decodes an OKP Ed25519 key (RFC 8037 §2).
+func (k JWK) ed25519Key() (ed25519.PublicKey, error) {
+	if k.Curve != "Ed25519" {
+		return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWK, k.Curve)
+	}
+
+	x, err := unb64(k.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrInvalidJWK)
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
+// b64 encodes data as unpadded base64url.
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// unb64 strictly decodes unpadded base64url.
+func unb64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.Strict().DecodeString(s) //nolint:wrapcheck // wrapped by the callers
+}
+
+// initJWKS precomputes the JWKS document of the static public keys served by
+// [JWT.JWKSHandler]. Keys without an explicit algorithm are published with the
+// configured one, so consumers can pin it too.
+func (c *JWT) initJWKS() error {
+	set := JWKSet{Keys: make([]JWK, 0, len(c.publicKeys))}
+
+	for _, key := range c.publicKeys {
+		if key.Alg == "" {
+			key.Alg = c.signingMethod.Alg()
+		}
+
+		jwk, err := NewJWK(key)
+		if err != nil {
+			return err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("jwt: unable to encode JWKS: %w", err)
+	}
+
+	c.jwks = data
+
+	return nil
+}
+
+// JWKSHandler publishes the public keys of the instance (the signing key and
+// any [WithVerificationKeys]) as an RFC 7517 JWK Set, typically routed at
+// /.well-known/jwks.json so that third parties can verify issued tokens without
+// sharing a secret. HMAC instances publish an empty set. The caller is
+// responsible for restricting the HTTP method.
+func (c *JWT) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Set(httputil.HeaderContentType, MimeTypeJWKSet)
+	h.Set("Cache-Control", jwksCacheControl)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	_, err := w.Write(c.jwks)
+	if err != nil {
+		c.logger.ErrorContext(r.Context(), "error writing JWKS response", slog.Any("error", err))
+	}
+}
+
+// jwksSnapshot is one fetched JWKS document.
+type jwksSnapshot struct {
+	keys      []VerificationKey
+	fetchedAt time.Time
+}
+
+// hasKeyID reports whether the snapshot holds a key with the given ID.
+func (s *jwksSnapshot) hasKeyID(kid string) bool {
+	return slices.ContainsFunc(s.keys, func(k VerificationKey) bool { return k.KeyID == kid })
+}
+
+// JWKSKeySet is a [KeySet] reading the public keys of a remote JWKS endpoint,
+// such as an identity provider's jwks_uri.
+//
+// The document is cached for the refresh interval, and concurrent callers share
+// a single in-flight fetch (see github.com/tecnickcom/nurago/pkg/sfcache). A
+// token carrying an unknown `kid` forces a refresh, at most once per minimum
+// refresh interval, so newly rotated keys are picked up immediately. Keys that
+// cannot be decoded are skipped.
+//
+// Within the max stale window past the refresh interval, the last fetched keys
+// are served at once while the document is refreshed in the background, so an
+// unavailable provider does not delay the verifications. A failed background
+// refresh is retried at most once per minimum refresh interval.
+type JWKSKeySet struct {
+	url                string
+	httpClient         HTTPClient
+	timeout            time.Duration
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	maxStale           time.Duration
+	cache              *sfcache.Cache[string, *jwksSnapshot]
+	last               atomic.Pointer[jwksSnapshot] // last successfully fetched document
+	mux                sync.Mutex                   // guards forcedAt, refreshing and failedAt
+	forcedAt           time.Time                    // time of the last refresh forced by an unknown kid
+	refreshing         bool                         // a background refresh is running
+	failedAt           time.Time                    // time of the last failed background refresh
+}
+
+// NewJWKSKeySet constructs a [KeySet] fetching the JWKS document at jwksURL,
+// which must be an absolute http or https URL. Nothing is fetched until the
+// first token needs a key.
+func NewJWKSKeySet(jwksURL string, opts ...JWKSOption) (*JWKSKeySet, error) {
+	ks := &JWKSKeySet{
+		url:                jwksURL,
+		timeout:            DefaultJWKSTimeout,
+		refreshInterval:    DefaultJWKSRefreshInterval,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		maxStale:           DefaultJWKSMaxStale,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(ks)
+	}
+
+	err := ks.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if ks.httpClient == nil {
+		ks.httpClient = &http.Client{Timeout: ks.timeout}
+	}
+
+	ks.cache = sfcache.New(ks.fetch, sfcache.Config{Size: 1, TTL: ks.refreshInterval})
+
+	return ks, nil
+}
+
+// validate checks the JWKS URL and durations.
+func (ks *JWKSKeySet) validate() error {
+	parsed, err := url.ParseRequestURI(ks.url)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: invalid JWKS URL %q", ErrInvalidJWKSOptions, ks.url)
+	}
+
+	switch {
+	case ks.timeout <= 0:
+		return fmt.Errorf("%w: timeout must be positive", ErrInvalidJWKSOptions)
+	case ks.refreshInterval <= 0:
+		return fmt.Errorf("%w: refresh interval must be positive", ErrInvalidJWKSOptions)
+	case ks.minRefreshInterval < 0:
+		return fmt.Errorf("%w: min refresh interval must not be negative", ErrInvalidJWKSOptions)
+	case ks.maxStale < 0:
+		return fmt.Errorf("%w: max stale must not be negative", ErrInvalidJWKSOptions)
+	}
+
+	return nil
+}
+
+// VerificationKeys returns the keys of the JWKS document, refreshing it first
+// when kid is not among them and no refresh was forced within the minimum
+// refresh interval.
+func (ks *JWKSKeySet) VerificationKeys(ctx context.Context, kid string) ([]VerificationKey, error) {
+	snap, err := ks.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if kid == "" || snap.hasKeyID(kid) {
+		return snap.keys, nil
+	}
+
+	// The provider may have rotated in a key published after the last fetch.
+	if ks.allowForcedRefresh(snap) {
+		ks.cache.Remove(ks.url)
+	}
+
+	// Reload even when the refresh was forced by another caller, to pick up
+	// (or coalesce onto) its fetch.
+	snap, err = ks.lookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return snap.keys, nil
+}
+
+// allowForcedRefresh reports whether an unknown kid may force a refresh of snap,
+// and records the refresh when it does.
+func (ks *JWKSKeySet) allowForcedRefresh(snap *jwksSnapshot) bool {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+
+	now := time.Now()
+	if now.Sub(snap.fetchedAt) < ks.minRefreshInterval || now.Sub(ks.forcedAt) < ks.minRefreshInterval {
+		return false
+	}
+
+	ks.forcedAt = now
+
+	return true
+}
+
+// load returns the last fetched JWKS document while it is fresh. Past the
+// refresh interval it is still returned within the max stale window, and
+// refreshed in the background; otherwise the document is fetched.
+func (ks *JWKSKeySet) load(ctx context.Context) (*jwksSnapshot, error) {
+	last := ks.last.Load()
+	if last != nil {
+		age := time.Since(last.fetchedAt)
+
+		if age <= ks.refreshInterval {
+			return last, nil
+		}
+
+		if ks.maxStale > 0 && age <= ks.refreshInterval+ks.maxStale {
+			ks.refreshStale(ctx)
+			return last, nil
+		}
+	}
+
+	return ks.lookup(ctx)
+}
+
+// refreshStale refreshes the JWKS document in the background, unless a refresh
+// is already running or the last one failed within the minimum refresh
+// interval.
+func (ks *JWKSKeySet) refreshStale(ctx context.Context) {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+
+	if ks.refreshing || time.Since(ks.failedAt) < ks.minRefreshInterval {
+		return
+	}
+
+	ks.refreshing = true
+
+	go func() {
+		_, err := ks.cache.Lookup(context.WithoutCancel(ctx), ks.url)
+
+		ks.mux.Lock()
+		defer ks.mux.Unlock()
+
+		ks.refreshing = false
+
+		if err != nil {
+			ks.failedAt = time.Now()
+		}
+	}()
+}
+
+// lookup returns the cached JWKS document, fetching it when needed, and falls
+// back to the last fetched one within the max stale window when fetching fails.
+func (ks *JWKSKeySet) lookup(ctx context.Context) (*jwksSnapshot, error) {
+	snap, err := ks.cache.Lookup(ctx, ks.url)
+	if err == nil {
+		return snap, nil
+	}
+
+	last := ks.last.Load()
+	if last != nil && time.Since(last.fetchedAt) <= ks.refreshInterval+ks.maxStale {
+		return last, nil
+	}
+
+	return nil, err //nolint:wrapcheck // the fetch error is already descriptive
+}
+
+// fetch downloads and decodes the JWKS document.
+//
+//nolint:nonamedreturns
+func (ks *JWKSKeySet) fetch(ctx context.Context, jwksURL string) (snap *jwksSnapshot, err error) {
+	ctx, cancel := context.WithTimeout(ctx, ks.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: build JWKS request: %w", err)
+	}
+
+	req.Header.Set(httputil.HeaderAccept, MimeTypeJWKSet+", "+httputil.MimeTypeJSON)
+
+	resp, err := ks.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: JWKS request failed: %w", err)
+	}
+
+	if resp.Body == nil {
+		return nil, fmt.Errorf("%w: nil response body", ErrInvalidJWKS)
+	}
+
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, DefaultJWKSMaxBodyBytes))
+
+		return nil, fmt.Errorf("%w: unexpected status code %d", ErrInvalidJWKS, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, DefaultJWKSMaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("jwt: failed reading JWKS body: %w", err)
+	}
+
+	if int64(len(body)) > DefaultJWKSMaxBodyBytes {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrInvalidJWKS, DefaultJWKSMaxBodyBytes)
+	}
+
+	snap, err = decodeJWKS(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.last.Store(snap)
+
+	return snap, nil
+}
+
+// decodeJWKS decodes a JWKS document, keeping the keys that decode.
+func decodeJWKS(body []byte) (*jwksSnapshot, error) {
+	var set JWKSet
+
+	err := json.NewDecoder(bytes.NewReader(body)).Decode(&set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	snap := &jwksSnapshot{keys: make([]VerificationKey, 0, len(set.Keys)), fetchedAt: time.Now()}
+
+	for _, jwk := range set.Keys {
+		key, kerr := jwk.VerificationKey()
+		if kerr == nil {
+			snap.keys = append(snap.keys, key)
+		}
+	}
+
+	if len(snap.keys) == 0 {
+		return nil, fmt.Errorf("%w: no usable key", ErrInvalidJWKS)
+	}
+
+	return snap, nil
+}
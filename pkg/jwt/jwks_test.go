@@ -0,0 +1,438 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// jwksServer serves the JWKS of a swappable issuer and counts the fetches.
+type jwksServer struct {
+	*httptest.Server
+
+	mux     sync.Mutex
+	handler http.HandlerFunc
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, handler http.HandlerFunc) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{handler: handler}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+
+		s.mux.Lock()
+		h := s.handler
+		s.mux.Unlock()
+
+		h(w, r)
+	}))
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) set(handler http.HandlerFunc) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.handler = handler
+}
+
+func TestJWKSHandler(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodES256)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	c, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodES256),
+		WithSigningKey("current", signer),
+		WithVerificationKeys(VerificationKey{KeyID: "previous", Key: other.Public()}),
+	)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	c.JWKSHandler(rr, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, MimeTypeJWKSet, rr.Header().Get("Content-Type"))
+
+	var set JWKSet
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, "current", set.Keys[0].KeyID)
+	require.Equal(t, "previous", set.Keys[1].KeyID)
+	require.Equal(t, "ES256", set.Keys[0].Alg)
+	require.Equal(t, "sig", set.Keys[0].Use)
+	require.NotContains(t, rr.Body.String(), `"d"`)
+
+	// An HMAC instance publishes an empty set.
+	h, err := New(testKey, testVerify)
+	require.NoError(t, err)
+
+	rr = httptest.NewRecorder()
+	h.JWKSHandler(rr, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+	require.JSONEq(t, `{"keys":[]}`, rr.Body.String())
+
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range []SigningMethod{SigningMethodRS256, SigningMethodES256, SigningMethodES384, SigningMethodES512, SigningMethodEdDSA} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			t.Parallel()
+
+			pub := testSigner(t, method).Public()
+
+			jwk, err := NewJWK(VerificationKey{KeyID: "k", Key: pub, Alg: method.Alg()})
+			require.NoError(t, err)
+
+			data, err := json.Marshal(jwk)
+			require.NoError(t, err)
+
+			var decoded JWK
+			require.NoError(t, json.Unmarshal(data, &decoded))
+
+			vk, err := decoded.VerificationKey()
+			require.NoError(t, err)
+			require.Equal(t, "k", vk.KeyID)
+			require.Equal(t, method.Alg(), vk.Alg)
+			require.NoError(t, checkKeyType(method, vk.Key))
+			require.True(t, vk.Key.(interface{ Equal(x crypto.PublicKey) bool }).Equal(pub)) //nolint:forcetypeassert
+		})
+	}
+}
+
+func TestJWKErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewJWK(VerificationKey{Key: "not-a-key"})
+	require.ErrorIs(t, err, ErrInvalidJWK)
+
+	_, err = NewJWK(VerificationKey{Key: &rsa.PublicKey{}})
+	require.ErrorIs(t, err, ErrInvalidJWK)
+
+	_, err = NewJWK(VerificationKey{Key: []byte("short")})
+	require.ErrorIs(t, err, ErrInvalidJWK)
+
+	tests := []struct {
+		name string
+		jwk  JWK
+	}{
+		{"encryption key", JWK{KeyType: "OKP", Use: "enc"}},
+		{"unknown key type", JWK{KeyType: "oct"}},
+		{"RSA bad modulus", JWK{KeyType: "RSA", N: "!!", E: "AQAB"}},
+		{"RSA bad exponent", JWK{KeyType: "RSA", N: "AQAB", E: "AAAAAAAB"}},
+		{"RSA even exponent", JWK{KeyType: "RSA", N: "AQAB", E: "Ag"}},
+		{"EC unknown curve", JWK{KeyType: "EC", Curve: "P-192"}},
+		{"EC short coordinates", JWK{KeyType: "EC", Curve: "P-256", X: "AQAB", Y: "AQAB"}},
+		{"EC point not on curve", JWK{KeyType: "EC", Curve: "P-256", X: b64(make([]byte, 32)), Y: b64(make([]byte, 32))}},
+		{"OKP unknown curve", JWK{KeyType: "OKP", Curve: "X25519"}},
+		{"OKP bad key", JWK{KeyType: "OKP", Curve: "Ed25519", X: "AQAB"}},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.jwk.VerificationKey()
+		require.ErrorIs(t, err, ErrInvalidJWK, tt.name)
+	}
+}
+
+func TestNewJWKSKeySetValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		url  string
+		opts []JWKSOption
+	}{
+		{"empty URL", "", nil},
+		{"bad scheme", "ftp://example.com/jwks", nil},
+		{"missing host", "https:///jwks", nil},
+		{"zero timeout", "https://example.com/jwks", []JWKSOption{WithJWKSTimeout(0)}},
+		{"zero refresh", "https://example.com/jwks", []JWKSOption{WithJWKSRefreshInterval(0)}},
+		{"negative min refresh", "https://example.com/jwks", []JWKSOption{WithJWKSMinRefreshInterval(-1)}},
+		{"negative max stale", "https://example.com/jwks", []JWKSOption{WithJWKSMaxStale(-1)}},
+	}
+
+	for _, tt := range tests {
+		ks, err := NewJWKSKeySet(tt.url, tt.opts...)
+		require.ErrorIs(t, err, ErrInvalidJWKSOptions, tt.name)
+		require.Nil(t, ks)
+	}
+
+	ks, err := NewJWKSKeySet("https://example.com/jwks", WithJWKSHTTPClient(nil))
+	require.NoError(t, err)
+	require.NotNil(t, ks.httpClient)
+}
+
+func TestJWKSKeySetRotation(t *testing.T) {
+	t.Parallel()
+
+	keyA := testSigner(t, SigningMethodRS256)
+
+	issuerA, err := New(nil, testVerify, WithSigningMethod(SigningMethodRS256), WithSigningKey("a", keyA))
+	require.NoError(t, err)
+
+	srv := newJWKSServer(t, issuerA.JWKSHandler)
+
+	ks, err := NewJWKSKeySet(srv.URL, WithJWKSMinRefreshInterval(0))
+	require.NoError(t, err)
+
+	c, err := New(nil, nil, WithSigningMethod(SigningMethodRS256), WithKeySet(ks))
+	require.NoError(t, err)
+
+	tokenA, err := issuerA.IssueToken("user")
+	require.NoError(t, err)
+
+	// Concurrent first use shares a single fetch.
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			_, verr := c.VerifyTokenContext(t.Context(), tokenA)
+			require.NoError(t, verr)
+		})
+	}
+
+	wg.Wait()
+	require.Equal(t, int32(1), srv.fetches.Load())
+
+	// The provider rotates to key B, still publishing key A.
+	keyB, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuerB, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodRS256),
+		WithSigningKey("b", keyB),
+		WithVerificationKeys(VerificationKey{KeyID: "a", Key: keyA.Public()}),
+	)
+	require.NoError(t, err)
+
+	srv.set(issuerB.JWKSHandler)
+
+	tokenB, err := issuerB.IssueToken("user")
+	require.NoError(t, err)
+
+	// The unknown kid forces a refresh that picks up key B.
+	_, err = c.VerifyToken(tokenB)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), srv.fetches.Load())
+
+	_, err = c.VerifyToken(tokenA)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), srv.fetches.Load())
+}
+
+func TestJWKSKeySetRefreshThrottle(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodEdDSA)
+
+	issuer, err := New(nil, testVerify, WithSigningMethod(SigningMethodEdDSA), WithSigningKey("a", signer))
+	require.NoError(t, err)
+
+	srv := newJWKSServer(t, issuer.JWKSHandler)
+
+	ks, err := NewJWKSKeySet(srv.URL, WithJWKSMinRefreshInterval(time.Hour))
+	require.NoError(t, err)
+
+	for range 5 {
+		keys, kerr := ks.VerificationKeys(t.Context(), "unknown")
+		require.NoError(t, kerr)
+		require.Len(t, keys, 1)
+	}
+
+	require.Equal(t, int32(1), srv.fetches.Load())
+}
+
+func TestJWKSKeySetStale(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodEdDSA)
+
+	issuer, err := New(nil, testVerify, WithSigningMethod(SigningMethodEdDSA), WithSigningKey("a", signer))
+	require.NoError(t, err)
+
+	srv := newJWKSServer(t, issuer.JWKSHandler)
+
+	ks, err := NewJWKSKeySet(srv.URL,
+		WithJWKSRefreshInterval(time.Millisecond),
+		WithJWKSMaxStale(time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = ks.VerificationKeys(t.Context(), "a")
+	require.NoError(t, err)
+
+	srv.set(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	time.Sleep(5 * time.Millisecond)
+
+	keys, err := ks.VerificationKeys(t.Context(), "a")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Eventually(t, func() bool { return srv.fetches.Load() > 1 }, time.Second, time.Millisecond)
+
+	// Without a stale window the failure surfaces.
+	strict, err := NewJWKSKeySet(srv.URL, WithJWKSMaxStale(0))
+	require.NoError(t, err)
+
+	_, err = strict.VerificationKeys(t.Context(), "a")
+	require.ErrorIs(t, err, ErrInvalidJWKS)
+}
+
+func TestJWKSKeySetStaleNoWait(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodEdDSA)
+
+	issuer, err := New(nil, testVerify, WithSigningMethod(SigningMethodEdDSA), WithSigningKey("a", signer))
+	require.NoError(t, err)
+
+	srv := newJWKSServer(t, issuer.JWKSHandler)
+
+	ks, err := NewJWKSKeySet(srv.URL,
+		WithJWKSTimeout(5*time.Second),
+		WithJWKSRefreshInterval(time.Millisecond),
+		WithJWKSMinRefreshInterval(time.Hour),
+		WithJWKSMaxStale(time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = ks.VerificationKeys(t.Context(), "a")
+	require.NoError(t, err)
+
+	// The provider hangs until the fetch times out.
+	hang := make(chan struct{})
+	t.Cleanup(func() { close(hang) })
+
+	srv.set(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hang:
+		case <-r.Context().Done():
+		}
+	})
+
+	time.Sleep(5 * time.Millisecond)
+
+	start := time.Now()
+
+	for range 5 {
+		keys, kerr := ks.VerificationKeys(t.Context(), "a")
+		require.NoError(t, kerr)
+		require.Len(t, keys, 1)
+	}
+
+	require.Less(t, time.Since(start), time.Second, "the stale keys must be served without waiting for the fetch")
+
+	// A single background refresh runs at a time.
+	require.Eventually(t, func() bool { return srv.fetches.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int32(2), srv.fetches.Load())
+}
+
+func TestJWKSKeySetStaleRetryThrottle(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodEdDSA)
+
+	issuer, err := New(nil, testVerify, WithSigningMethod(SigningMethodEdDSA), WithSigningKey("a", signer))
+	require.NoError(t, err)
+
+	srv := newJWKSServer(t, issuer.JWKSHandler)
+
+	ks, err := NewJWKSKeySet(srv.URL,
+		WithJWKSRefreshInterval(time.Millisecond),
+		WithJWKSMinRefreshInterval(time.Hour),
+		WithJWKSMaxStale(time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = ks.VerificationKeys(t.Context(), "a")
+	require.NoError(t, err)
+
+	srv.set(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = ks.VerificationKeys(t.Context(), "a")
+	require.NoError(t, err)
+
+	// Once the background refresh has failed, it is not retried at once.
+	require.Eventually(t, func() bool {
+		ks.mux.Lock()
+		defer ks.mux.Unlock()
+
+		return !ks.failedAt.IsZero()
+	}, time.Second, time.Millisecond)
+
+	for range 5 {
+		_, err = ks.VerificationKeys(t.Context(), "a")
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(2), srv.fetches.Load())
+}
+
+func TestJWKSKeySetFetchErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"bad status", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) }},
+		{"bad JSON", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("{")) }},
+		{"no usable keys", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`))
+		}},
+		{"oversize body", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(strings.Repeat(" ", int(DefaultJWKSMaxBodyBytes)+1)))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newJWKSServer(t, tt.handler)
+
+			ks, err := NewJWKSKeySet(srv.URL)
+			require.NoError(t, err)
+
+			_, err = ks.VerificationKeys(t.Context(), "")
+			require.ErrorIs(t, err, ErrInvalidJWKS)
+		})
+	}
+
+	// Transport failure.
+	srv := newJWKSServer(t, nil)
+	srv.Close()
+
+	ks, err := NewJWKSKeySet(srv.URL)
+	require.NoError(t, err)
+
+	_, err = ks.VerificationKeys(t.Context(), "")
+	require.Error(t, err)
+}
@@ -8,6 +8,9 @@ The API is compatible with net/http:
 
   - [JWT.LoginHandler]: validates credentials and issues signed JWTs.
   - [JWT.RenewHandler]: renews a valid token only when it is close to expiry.
+  - [JWT.RefreshHandler]: exchanges a single-use refresh token for a new
+    access and refresh token pair.
+  - [JWT.LogoutHandler]: revokes the presented token and its whole session.
   - [JWT.IsAuthorized]: validates bearer tokens for protected handlers.
   - [JWT.Middleware]: wraps a handler, injecting verified claims into the
     request context for retrieval via [ClaimsFromContext].
@@ -17,6 +20,8 @@ The API is compatible with net/http:
     caller has verified the user's identity by its own means.
   - [JWT.VerifyToken]: validates a raw token string that arrived over any
     transport (WebSocket messages, queue payloads, gRPC metadata).
+  - [JWT.JWKSHandler]: publishes the asymmetric public keys as a JWK Set, so
+    third parties can verify issued tokens without sharing a secret.
 
 Credential verification is delegated to a caller-provided
 [VerifyCredentialsFn], so the package is agnostic to the password-hashing
@@ -26,15 +31,60 @@ scheme; use the OWASP-compliant github.com/tecnickcom/nurago/pkg/passwordhash
 # Implementation
 
 Tokens are RFC 7515 compact JWS with RFC 7519 claims, signed with HMAC-SHA2
-(RFC 7518 §3.2: HS256, HS384 or HS512). The implementation is self-contained on
-the Go standard library. Restricting the surface to
-symmetric HMAC makes the classic JWT attacks (alg=none, asymmetric-to-HMAC
-confusion) structurally impossible: the accepted algorithm is pinned and the
-signature is verified before the claims payload is ever decoded. A `crit` header
-(RFC 7515 §4.1.11) or a duplicated header parameter is rejected; other unknown
-JOSE header parameters (typ, kid, ...) are ignored. The `exp` and `nbf` time
-claims are validated (with optional leeway); `iat` is decoded but not validated,
-as only a key holder could forge it.
+(RFC 7518 §3.2: HS256, HS384 or HS512), RSASSA-PKCS1-v1_5 (RFC 7518 §3.3:
+RS256, RS384 or RS512), ECDSA (RFC 7518 §3.4: ES256, ES384 or ES512) or Ed25519
+(RFC 8037: EdDSA). The implementation is self-contained on the Go standard
+library. Exactly one algorithm is accepted per instance, which makes the
+classic JWT attacks (alg=none, asymmetric-to-HMAC confusion) structurally
+impossible: the accepted algorithm is pinned, every key is checked against it
+(type, curve and minimum size) before use, and the signature is verified
+before the claims payload is ever decoded. A `crit` header (RFC 7515 §4.1.11)
+or a duplicated header parameter is rejected; the `kid` parameter only narrows
+which asymmetric keys are tried, and other unknown JOSE header parameters
+(typ, ...) are ignored. The `exp` and `nbf` time claims are validated (with
+optional leeway); `iat` is decoded but not validated, as only a key holder
+could forge it.
+
+# Asymmetric Keys and JWKS
+
+With an asymmetric signing method the HMAC key passed to [New] must be nil.
+Tokens are signed with the private key set by [WithSigningKey], whose key ID is
+carried in the `kid` header. Further public keys are accepted from
+[WithVerificationKeys] (previous signing keys during a rotation, or keys of
+other issuers) and from a dynamic [KeySet] set by [WithKeySet]. An instance
+without a signing key only verifies tokens, for example those minted by an
+identity provider:
+
+	keys, err := jwt.NewJWKSKeySet("https://idp.example.com/.well-known/jwks.json")
+	// ...
+	auth, err := jwt.New(nil, nil,
+	    jwt.WithSigningMethod(jwt.SigningMethodRS256),
+	    jwt.WithKeySet(keys),
+	)
+
+[JWKSKeySet] caches the fetched JWK Set, coalesces concurrent fetches into one,
+refreshes it early (rate limited) when a token carries an unknown `kid`, and
+keeps serving the last keys for a bounded time while the endpoint is down.
+[JWT.JWKSHandler] publishes the static public keys for such consumers.
+
+# Refresh Tokens and Revocation
+
+Tokens are stateless by default. [WithRevocationStore] adds a server-side
+denylist consulted on every verification for the token ID (`jti`) and the
+session ID (`sid`, shared by every token of a login session), which
+[JWT.LogoutHandler] populates. [WithRefreshTokens] additionally makes the login
+return a [TokenPair] whose opaque refresh token is exchanged, exactly once, at
+[JWT.RefreshHandler] for a new pair. A refresh token presented a second time
+reveals that it leaked, so the whole session is revoked (RFC 9700 §4.14.2).
+
+[MemoryStore] serves a single instance; [ScriptStore] shares the state
+between instances through atomic Lua scripts on Redis or Valkey, bound by
+[github.com/tecnickcom/nurago/pkg/luascript].
+
+	store := jwt.NewMemoryStore()
+	auth, err := jwt.New(key, verifyFn,
+	    jwt.WithRefreshTokens(store, 24*time.Hour),
+	)
 
 # Authentication Flow
 
@@ -55,8 +105,10 @@ By default the package issues short-lived HMAC-signed tokens with:
   - token size cap: [DefaultMaxTokenBytes]
 
 Issued tokens include standard registered claims (`exp`, `iat`, `nbf`, `jti`,
-`sub`) and an `auth_time` claim recording the original login. They support
-optional `iss` and `aud` via options. The `sub` (Subject) claim is set to the
+`sub`), an `auth_time` claim recording the original login and a `sid` claim
+identifying the login session. They support optional `iss` and `aud` via
+options, and `scope` and `roles` claims from a [GrantsFn], which the authz
+subpackage turns into declarative per-route authorization policies. The `sub` (Subject) claim is set to the
 authenticated username. When `iss` and/or `aud` are configured, they are also
 enforced during verification: a token missing them, or carrying different
 values, is rejected.
@@ -69,15 +121,25 @@ Functional options allow custom behavior without replacing core handlers:
     [WithAuthorizationHeader], [WithSigningMethod], [WithMaxBodyBytes],
     [WithMaxTokenBytes])
   - session controls ([WithMaxSessionLifetime], [WithClockSkewLeeway])
-  - key rotation ([WithPreviousKeys])
-  - claim metadata ([WithClaimIssuer], [WithClaimAudience])
+  - server-side sessions ([WithRevocationStore], [WithRefreshTokens],
+    [WithSendTokenPairFn])
+  - key rotation ([WithPreviousKeys], [WithVerificationKeys])
+  - asymmetric keys ([WithSigningKey], [WithVerificationKeys], [WithKeySet])
+  - claim metadata ([WithClaimIssuer], [WithClaimAudience], [WithGrantsFn])
   - logger customization ([WithLogger])
 
 # Security Notes
 
-  - Only HMAC signing methods (HS256/HS384/HS512) are supported: the same
-    symmetric key both signs and verifies. Keep it secret, and at least as long
-    as the signing method's hash output (enforced by [New]).
+  - With HMAC signing methods (HS256/HS384/HS512) the same symmetric key both
+    signs and verifies. Keep it secret, and at least as long as the signing
+    method's hash output (enforced by [New]). Prefer an asymmetric method when
+    tokens must be verified by parties that must not be able to mint them.
+  - RSA keys must be at least 2048 bits, and ECDSA keys must lie on the curve
+    mandated by the method (P-256, P-384 or P-521); keys from a [KeySet] that
+    do not qualify are ignored.
+  - To rotate an asymmetric key, sign with the new key while listing the old
+    public key in [WithVerificationKeys] (or keep publishing it in the JWKS),
+    and drop it once the rotation window has elapsed.
   - To rotate the signing key without invalidating outstanding sessions, deploy
     the new key while listing the old one in [WithPreviousKeys], then drop the
     old key once the rotation window (expiration time plus renew window) has
@@ -89,11 +151,14 @@ Functional options allow custom behavior without replacing core handlers:
   - Use HTTPS so bearer tokens are never exposed in transit.
   - The handlers do not restrict the HTTP method; the caller is responsible for
     routing login to POST and protected endpoints appropriately.
-  - Tokens are stateless: there is no server-side revocation before `exp`, and
-    renewing a token does not invalidate the previous one, which stays valid
-    until its own expiration. Configure short expiration windows appropriate
-    for your threat model, and bound how long a session may be kept alive by
-    renewals with [WithMaxSessionLifetime].
+  - Without a [RevocationStore] tokens are stateless: there is no server-side
+    revocation before `exp`, and renewing a token does not invalidate the
+    previous one, which stays valid until its own expiration. Configure short
+    expiration windows appropriate for your threat model, and bound how long a
+    session may be kept alive by renewals with [WithMaxSessionLifetime].
+  - With a [RevocationStore] every verification costs a store lookup, and fails
+    closed ([ErrRevocationCheck]) while the store is unavailable. Refresh
+    tokens are stored only as SHA-256 digests.
   - The package does not rate-limit or lock out repeated failed logins;
     brute-force protection (rate limiting, lockout, CAPTCHA) must be layered by
     the caller.
@@ -106,6 +171,7 @@ package jwt
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -188,11 +254,44 @@ var (
 
 	// ErrInvalidClockSkewLeeway is returned when the clock-skew leeway is negative.
 	ErrInvalidClockSkewLeeway = errors.New("jwt: clock skew leeway must not be negative")
+
+	// ErrUnexpectedKey is returned when HMAC key material (the key argument of
+	// [New] or [WithPreviousKeys]) is given for an asymmetric signing method.
+	ErrUnexpectedKey = errors.New("jwt: unexpected HMAC key for an asymmetric signing method")
+
+	// ErrKeyTypeMismatch is returned when an asymmetric key does not match the
+	// signing method (e.g. an RSA key for ES256, or a P-384 key for ES256), or
+	// when asymmetric keys are configured for an HMAC signing method.
+	ErrKeyTypeMismatch = errors.New("jwt: key type does not match the signing method")
+
+	// ErrNoVerificationKey is returned when an asymmetric signing method has no
+	// signing key, no verification keys, and no key set.
+	ErrNoVerificationKey = errors.New("jwt: no verification key configured")
+
+	// ErrDuplicateKeyID is returned when two static asymmetric keys share a key ID.
+	ErrDuplicateKeyID = errors.New("jwt: duplicate key ID")
+
+	// ErrShortRefreshLifetime is returned when refresh tokens are enabled with a
+	// lifetime shorter than one second.
+	ErrShortRefreshLifetime = errors.New("jwt: refresh token lifetime must be at least one second")
 )
 
+// ErrGrants is returned when the [GrantsFn] fails while issuing a token.
+var ErrGrants = errors.New("jwt: unable to load the user grants")
+
 // SendResponseFn is the type of function used to send back the HTTP responses.
 type SendResponseFn func(ctx context.Context, w http.ResponseWriter, statusCode int, data string)
 
+// SendTokenPairFn is the type of function used to send back the access and
+// refresh token pair issued when refresh tokens are enabled.
+type SendTokenPairFn func(ctx context.Context, w http.ResponseWriter, statusCode int, pair *TokenPair)
+
+// GrantsFn returns the scopes and roles to embed in the tokens issued for
+// username. It is called at login and on every refresh-token exchange, so
+// changed grants take effect at the next refresh; renewals carry the grants of
+// the presented token over. An error fails the issuance with a 500 response.
+type GrantsFn func(ctx context.Context, username string) (scopes, roles []string, err error)
+
 // VerifyCredentialsFn verifies a username/password pair against the user store.
 //
 // It returns:
@@ -224,7 +323,17 @@ type JWT struct {
 	maxTokenBytes       int                 // Maximum accepted compact-JWS token size in bytes.
 	sendResponseFn      SendResponseFn      // Response function used to send back the HTTP responses.
 	verifyCredentialsFn VerifyCredentialsFn // Function used to verify user credentials.
-	signingMethod       SigningMethod       // HMAC signing method.
+	signingMethod       SigningMethod       // Signing method.
+	signer              crypto.Signer       // Asymmetric signing key (nil for HMAC or verification-only instances).
+	keyID               string              // The `kid` header parameter of issued asymmetric tokens.
+	publicKeys          []VerificationKey   // Static asymmetric verification keys (signing key first, when set).
+	keySet              KeySet              // Dynamic asymmetric verification key source (e.g. a remote JWKS).
+	jwks                []byte              // Precomputed JWKS document of the static public keys.
+	revocationStore     RevocationStore     // Denylist of revoked token and session IDs (nil = stateless).
+	refreshStore        RefreshTokenStore   // Refresh-token sessions (nil = refresh tokens disabled).
+	refreshLifetime     time.Duration       // Lifetime of each issued refresh token.
+	sendTokenPairFn     SendTokenPairFn     // Response function used to send back the token pairs.
+	grantsFn            GrantsFn            // Source of the `scope` and `roles` claims (nil = none).
 	authorizationHeader string
 	issuer              string             // the `iss` (Issuer) claim. See https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.1
 	audience            []string           // the `aud` (Audience) claim. See https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.3
@@ -250,6 +359,7 @@ func defaultJWT() *JWT {
 	}
 
 	cfg.sendResponseFn = cfg.defaultSendResponse
+	cfg.sendTokenPairFn = cfg.defaultSendTokenPair
 
 	return cfg
 }
@@ -263,6 +373,11 @@ func defaultJWT() *JWT {
 //
 // The signing key and any previous keys are copied, so later mutation of the
 // caller's buffers does not affect the instance.
+//
+// With an asymmetric signing method the HMAC key must be nil: the private key
+// is set with [WithSigningKey] and further public keys with
+// [WithVerificationKeys] or [WithKeySet]. Without a signing key the instance
+// only verifies tokens, verifyFn may be nil, and the issuing handlers fail.
 func New(key []byte, verifyFn VerifyCredentialsFn, opts ...Option) (*JWT, error) {
 	c := defaultJWT()
 	c.key = key
@@ -280,6 +395,12 @@ func New(key []byte, verifyFn VerifyCredentialsFn, opts ...Option) (*JWT, error)
 	}
 
 	c.initTokenConfig()
+
+	err = c.initJWKS()
+	if err != nil {
+		return nil, err
+	}
+
 	c.httpresp = httputil.NewHTTPResp(c.logger)
 
 	return c, nil
@@ -300,6 +421,15 @@ func (c *JWT) normalizeOptionals() {
 	if c.authorizationHeader == "" {
 		c.authorizationHeader = DefaultAuthorizationHeader
 	}
+
+	if c.sendTokenPairFn == nil {
+		c.sendTokenPairFn = c.defaultSendTokenPair
+	}
+
+	// The refresh store doubles as the denylist unless another one is set.
+	if c.revocationStore == nil && c.refreshStore != nil {
+		c.revocationStore = c.refreshStore
+	}
 }
 
 // validate checks that the configuration is complete and safe.
@@ -308,8 +438,8 @@ func (c *JWT) validate() error {
 		invalid bool
 		err     error
 	}{
-		{len(c.key) == 0, ErrEmptyKey},
-		{c.verifyCredentialsFn == nil, ErrNilVerifyFn},
+		{c.signingMethod.family() == familyHMAC && len(c.key) == 0, ErrEmptyKey},
+		{c.verifyCredentialsFn == nil && c.canSign(), ErrNilVerifyFn},
 		{c.signingMethod.Alg() == "", ErrInvalidSigningMethod},
 		{c.expirationTime <= 0, ErrInvalidExpirationTime},
 		{c.expirationTime > 0 && c.expirationTime < time.Second, ErrShortExpirationTime},
@@ -319,6 +449,7 @@ func (c *JWT) validate() error {
 		{c.maxSessionLifetime < 0, ErrInvalidMaxSessionLifetime},
 		{c.maxSessionLifetime > 0 && c.maxSessionLifetime < time.Second, ErrShortMaxSessionLifetime},
 		{c.clockSkewLeeway < 0, ErrInvalidClockSkewLeeway},
+		{c.refreshStore != nil && c.refreshLifetime < time.Second, ErrShortRefreshLifetime},
 	}
 
 	for _, check := range checks {
@@ -327,6 +458,14 @@ func (c *JWT) validate() error {
 		}
 	}
 
+	if c.signingMethod.family() != familyHMAC {
+		return c.validateAsymmetricKeys()
+	}
+
+	if c.signer != nil || len(c.publicKeys) > 0 || c.keySet != nil {
+		return fmt.Errorf("%w: %s does not use public keys", ErrKeyTypeMismatch, c.signingMethod.Alg())
+	}
+
 	// Every HMAC key must be at least as long as the hash output (RFC 7518
 	// §3.2): the signing key and any previous verification keys alike.
 	minLen := c.signingMethod.hashSize()
@@ -347,3 +486,8 @@ func (c *JWT) validate() error {
 func (c *JWT) defaultSendResponse(ctx context.Context, w http.ResponseWriter, statusCode int, data string) {
 	c.httpresp.SendText(ctx, w, statusCode, data)
 }
+
+// defaultSendTokenPair writes JSON token-pair responses via httputil.HTTPResp.
+func (c *JWT) defaultSendTokenPair(ctx context.Context, w http.ResponseWriter, statusCode int, pair *TokenPair) {
+	c.httpresp.SendJSON(ctx, w, statusCode, pair)
+}
@@ -65,7 +65,7 @@ func FuzzCheckToken(f *testing.F) {
 		f.Fatal(err)
 	}
 
-	validToken, err := c.signToken(c.newClaims("fuzz-user", nil))
+	validToken, err := c.signToken(c.newClaims("fuzz-user", nil, ""))
 	if err != nil {
 		f.Fatal(err)
 	}
@@ -145,6 +145,19 @@ func TestNew(t *testing.T) {
 			opts:     []Option{WithClockSkewLeeway(-1)},
 			wantErr:  ErrInvalidClockSkewLeeway,
 		},
+		{
+			name:     "failure with sub-second refresh token lifetime",
+			key:      testKey,
+			verifyFn: testVerify,
+			opts:     []Option{WithRefreshTokens(NewMemoryStore(), 500*time.Millisecond)},
+			wantErr:  ErrShortRefreshLifetime,
+		},
+		{
+			name:     "success with refresh tokens",
+			key:      testKey,
+			verifyFn: testVerify,
+			opts:     []Option{WithRefreshTokens(NewMemoryStore(), time.Hour)},
+		},
 	}
 
 	for _, tt := range tests {
@@ -191,11 +204,13 @@ func TestNormalizeOptionals(t *testing.T) {
 		WithLogger(nil),
 		WithSendResponseFn(nil),
 		WithAuthorizationHeader(""),
+		WithSendTokenPairFn(nil),
 	)
 	require.NotNil(t, c)
 	require.NoError(t, err)
 	require.NotNil(t, c.logger)
 	require.NotNil(t, c.sendResponseFn)
+	require.NotNil(t, c.sendTokenPairFn)
 	require.Equal(t, DefaultAuthorizationHeader, c.authorizationHeader)
 
 	rr := httptest.NewRecorder()
@@ -0,0 +1,287 @@
+package jwt
+
+// This file contains the asymmetric key material: the RSA, ECDSA and Ed25519
+// signature primitives, the verification keys and their lookup by `kid`, and
+// the key-type checks that pin each key to the configured signing method.
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// minRSAKeyBits is the minimum RSA modulus size accepted for signing and
+// verification (RFC 7518 §3.3).
+const minRSAKeyBits = 2048
+
+// ErrNoSigningKey is returned when a token must be issued by an instance that
+// only verifies tokens: an asymmetric signing method configured without
+// [WithSigningKey].
+var ErrNoSigningKey = errors.New("jwt: no signing key configured")
+
+// VerificationKey is an asymmetric public key accepted to verify token
+// signatures.
+type VerificationKey struct {
+	// KeyID is the RFC 7515 `kid` identifying the key. A token carrying a kid is
+	// only checked against keys with the same KeyID, or without one.
+	KeyID string
+
+	// Key is the public key: *rsa.PublicKey, *ecdsa.PublicKey or
+	// ed25519.PublicKey, matching the configured signing method.
+	Key crypto.PublicKey
+
+	// Alg optionally restricts the key to a single RFC 7518 algorithm (the JWK
+	// `alg` member). Empty accepts the key for the configured signing method.
+	Alg string
+}
+
+// KeySet is a dynamic source of asymmetric verification keys, such as a remote
+// JWKS endpoint ([JWKSKeySet]). It is consulted, after the static keys, for
+// tokens whose signature none of the static keys verified.
+type KeySet interface {
+	// VerificationKeys returns the candidate keys for a token carrying kid
+	// (empty when the token has none). An implementation may refresh its keys
+	// when kid is unknown, and must be safe for concurrent use.
+	VerificationKeys(ctx context.Context, kid string) ([]VerificationKey, error)
+}
+
+// ecdsaSignature is the ASN.1 DER form of an ECDSA signature, as produced by
+// crypto.Signer implementations.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// canSign reports whether the instance holds a key able to issue tokens.
+func (c *JWT) canSign() bool {
+	return c.signingMethod.family() == familyHMAC || c.signer != nil
+}
+
+// validateAsymmetricKeys checks the key configuration of an asymmetric signing
+// method: no HMAC key material, at least one source of verification keys, every
+// static key matching the method, and unique key IDs.
+func (c *JWT) validateAsymmetricKeys() error {
+	if len(c.key) > 0 || len(c.previousKeys) > 0 {
+		return fmt.Errorf("%w: HMAC keys are not used by %s", ErrUnexpectedKey, c.signingMethod.Alg())
+	}
+
+	if c.signer == nil && len(c.publicKeys) == 0 && c.keySet == nil {
+		return ErrNoVerificationKey
+	}
+
+	seen := make(map[string]struct{}, len(c.publicKeys)+1)
+
+	if c.signer != nil {
+		err := checkKeyType(c.signingMethod, c.signer.Public())
+		if err != nil {
+			return fmt.Errorf("signing key: %w", err)
+		}
+
+		seen[c.keyID] = struct{}{}
+	}
+
+	for i, key := range c.publicKeys {
+		err := checkKeyType(c.signingMethod, key.Key)
+		if err != nil {
+			return fmt.Errorf("verification key %d: %w", i, err)
+		}
+
+		if _, dup := seen[key.KeyID]; dup {
+			return fmt.Errorf("%w: %q", ErrDuplicateKeyID, key.KeyID)
+		}
+
+		seen[key.KeyID] = struct{}{}
+	}
+
+	return nil
+}
+
+// checkKeyType verifies that pub is usable with the asymmetric method m: an RSA
+// key of at least [minRSAKeyBits], an ECDSA key on the curve mandated by the
+// method, or an Ed25519 key.
+func checkKeyType(m SigningMethod, pub crypto.PublicKey) error {
+	switch m.family() {
+	case familyRSA:
+		k, ok := pub.(*rsa.PublicKey)
+		if !ok || k == nil || k.N == nil {
+			return fmt.Errorf("%w: %s requires an RSA key", ErrKeyTypeMismatch, m.Alg())
+		}
+
+		if k.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("%w: RSA key is %d bits, need at least %d", ErrWeakKey, k.N.BitLen(), minRSAKeyBits)
+		}
+	case familyECDSA:
+		k, ok := pub.(*ecdsa.PublicKey)
+		if !ok || k == nil || k.Curve != m.curve() {
+			return fmt.Errorf("%w: %s requires an ECDSA key on %s", ErrKeyTypeMismatch, m.Alg(), m.curve().Params().Name)
+		}
+	case familyEdDSA:
+		k, ok := pub.(ed25519.PublicKey)
+		if !ok || len(k) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: %s requires an Ed25519 key", ErrKeyTypeMismatch, m.Alg())
+		}
+	default:
+		return fmt.Errorf("%w: %s does not use public keys", ErrKeyTypeMismatch, m.Alg())
+	}
+
+	return nil
+}
+
+// signAsymmetric signs signingInput with signer using the asymmetric method m,
+// returning the signature in its JWS form (RFC 7518 §3.3-3.4, RFC 8037 §3.1).
+func signAsymmetric(m SigningMethod, signer crypto.Signer, signingInput string) ([]byte, error) {
+	if signer == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	if m.family() == familyEdDSA {
+		sig, err := signer.Sign(rand.Reader, []byte(signingInput), crypto.Hash(0))
+		if err != nil {
+			return nil, fmt.Errorf("jwt: unable to sign token: %w", err)
+		}
+
+		return sig, nil
+	}
+
+	hashNew := m.hashNew()
+	if hashNew == nil {
+		return nil, ErrInvalidSigningMethod
+	}
+
+	digest := hashNew()
+	_, _ = digest.Write([]byte(signingInput)) // hash.Hash.Write never returns an error
+
+	sig, err := signer.Sign(rand.Reader, digest.Sum(nil), hashOpts(m))
+	if err != nil {
+		return nil, fmt.Errorf("jwt: unable to sign token: %w", err)
+	}
+
+	if m.family() != familyECDSA {
+		return sig, nil
+	}
+
+	return ecdsaToJWS(m, sig)
+}
+
+// hashOpts returns the crypto.Hash identifier matching the method digest.
+func hashOpts(m SigningMethod) crypto.Hash {
+	switch m.hashSize() {
+	case crypto.SHA384.Size():
+		return crypto.SHA384
+	case crypto.SHA512.Size():
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+// ecdsaToJWS converts an ASN.1 DER ECDSA signature into the fixed-size R||S
+// concatenation required by RFC 7518 §3.4.
+func ecdsaToJWS(m SigningMethod, der []byte) ([]byte, error) {
+	var parsed ecdsaSignature
+
+	rest, err := asn1.Unmarshal(der, &parsed)
+	if err != nil || len(rest) > 0 || parsed.R == nil || parsed.S == nil {
+		return nil, errors.New("jwt: invalid ECDSA signature encoding")
+	}
+
+	size := ecdsaCoordSize(m)
+	if parsed.R.BitLen() > size*8 || parsed.S.BitLen() > size*8 {
+		return nil, errors.New("jwt: ECDSA signature does not fit the curve size")
+	}
+
+	out := make([]byte, 2*size)
+	parsed.R.FillBytes(out[:size])
+	parsed.S.FillBytes(out[size:])
+
+	return out, nil
+}
+
+// ecdsaCoordSize returns the byte length of a coordinate (and of each signature
+// half) on the curve of the ECDSA method m.
+func ecdsaCoordSize(m SigningMethod) int {
+	return (m.curve().Params().BitSize + 7) / 8
+}
+
+// verifyWithKey reports whether sig is a valid signature of signingInput under
+// pub with the asymmetric method m. A key of the wrong type or size never
+// verifies, so a key can only be used with the pinned algorithm.
+func verifyWithKey(m SigningMethod, pub crypto.PublicKey, signingInput string, sig []byte) bool {
+	if checkKeyType(m, pub) != nil {
+		return false
+	}
+
+	if m.family() == familyEdDSA {
+		return ed25519.Verify(pub.(ed25519.PublicKey), []byte(signingInput), sig) //nolint:forcetypeassert // checked above
+	}
+
+	digest := m.hashNew()()
+	_, _ = digest.Write([]byte(signingInput)) // hash.Hash.Write never returns an error
+	sum := digest.Sum(nil)
+
+	if m.family() == familyRSA {
+		return rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), hashOpts(m), sum, sig) == nil //nolint:forcetypeassert // checked above
+	}
+
+	size := ecdsaCoordSize(m)
+	if len(sig) != 2*size {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+
+	return ecdsa.Verify(pub.(*ecdsa.PublicKey), sum, r, s) //nolint:forcetypeassert // checked above
+}
+
+// verifyAsymmetric checks sig against the static verification keys matching
+// kid and, when none verifies, against the keys returned by the configured
+// [KeySet].
+func (c *JWT) verifyAsymmetric(ctx context.Context, kid, signingInput string, sig []byte) error {
+	if c.verifyWithKeys(c.publicKeys, kid, signingInput, sig) {
+		return nil
+	}
+
+	if c.keySet == nil {
+		return ErrInvalidSignature
+	}
+
+	keys, err := c.keySet.VerificationKeys(ctx, kid)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeySetUnavailable, err)
+	}
+
+	if c.verifyWithKeys(keys, kid, signingInput, sig) {
+		return nil
+	}
+
+	return ErrInvalidSignature
+}
+
+// verifyWithKeys reports whether sig verifies under any of keys that matches
+// the token kid and the configured algorithm.
+func (c *JWT) verifyWithKeys(keys []VerificationKey, kid, signingInput string, sig []byte) bool {
+	alg := c.signingMethod.Alg()
+
+	for _, key := range keys {
+		if kid != "" && key.KeyID != "" && key.KeyID != kid {
+			continue
+		}
+
+		if key.Alg != "" && key.Alg != alg {
+			continue
+		}
+
+		if verifyWithKey(c.signingMethod, key.Key, signingInput, sig) {
+			return true
+		}
+	}
+
+	return false
+}
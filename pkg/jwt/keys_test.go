@@ -0,0 +1,431 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigners caches one generated key per asymmetric method, as RSA key
+// generation is slow.
+var testSigners sync.Map //nolint:gochecknoglobals
+
+// testSigner returns a private key suitable for the asymmetric method m.
+func testSigner(t *testing.T, m SigningMethod) crypto.Signer {
+	t.Helper()
+
+	if v, ok := testSigners.Load(m); ok {
+		return v.(crypto.Signer) //nolint:forcetypeassert
+	}
+
+	var (
+		signer crypto.Signer
+		err    error
+	)
+
+	switch m.family() {
+	case familyRSA:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case familyECDSA:
+		signer, err = ecdsa.GenerateKey(m.curve(), rand.Reader)
+	default:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+
+	require.NoError(t, err)
+
+	v, _ := testSigners.LoadOrStore(m, signer)
+
+	return v.(crypto.Signer) //nolint:forcetypeassert
+}
+
+// asymmetricMethods lists every supported asymmetric signing method.
+var asymmetricMethods = []SigningMethod{ //nolint:gochecknoglobals
+	SigningMethodRS256, SigningMethodRS384, SigningMethodRS512,
+	SigningMethodES256, SigningMethodES384, SigningMethodES512,
+	SigningMethodEdDSA,
+}
+
+// stubKeySet is a static [KeySet] that records the requested key IDs.
+type stubKeySet struct {
+	keys []VerificationKey
+	err  error
+	kids []string
+}
+
+func (s *stubKeySet) VerificationKeys(_ context.Context, kid string) ([]VerificationKey, error) {
+	s.kids = append(s.kids, kid)
+
+	return s.keys, s.err
+}
+
+func TestAsymmetricRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range asymmetricMethods {
+		t.Run(method.Alg(), func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(nil, testVerify,
+				WithSigningMethod(method),
+				WithSigningKey("kid-1", testSigner(t, method)),
+			)
+			require.NoError(t, err)
+
+			token, err := c.IssueToken("round-trip-user")
+			require.NoError(t, err)
+
+			claims, err := c.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, "round-trip-user", claims.Subject)
+
+			kid, err := c.checkHeader(strings.Split(token, ".")[0])
+			require.NoError(t, err)
+			require.Equal(t, "kid-1", kid)
+
+			// A verification-only instance holding just the public key accepts it.
+			v, err := New(nil, nil,
+				WithSigningMethod(method),
+				WithVerificationKeys(VerificationKey{KeyID: "kid-1", Key: testSigner(t, method).Public()}),
+			)
+			require.NoError(t, err)
+
+			_, err = v.VerifyToken(token)
+			require.NoError(t, err)
+
+			_, err = v.IssueToken("nobody")
+			require.ErrorIs(t, err, ErrNoSigningKey)
+
+			// A tampered signature is rejected.
+			_, err = v.VerifyToken(token[:len(token)-4] + "AAAA")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAsymmetricCrossValidationWithReferenceLibrary(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range asymmetricMethods {
+		t.Run(method.Alg(), func(t *testing.T) {
+			t.Parallel()
+
+			signer := testSigner(t, method)
+
+			c, err := New(nil, testVerify,
+				WithSigningMethod(method),
+				WithSigningKey("kid-1", signer),
+			)
+			require.NoError(t, err)
+
+			token, err := c.IssueToken("interop-user")
+			require.NoError(t, err)
+
+			refClaims := jwtv5.MapClaims{}
+			_, err = jwtv5.ParseWithClaims(
+				token,
+				refClaims,
+				func(_ *jwtv5.Token) (any, error) { return signer.Public(), nil },
+				jwtv5.WithValidMethods([]string{method.Alg()}),
+			)
+			require.NoError(t, err, "reference library must accept our token")
+			require.Equal(t, "interop-user", refClaims["sub"])
+
+			refToken := jwtv5.NewWithClaims(jwtv5.GetSigningMethod(method.Alg()), jwtv5.MapClaims{
+				"sub": "ref-user",
+				"exp": jwtv5.NewNumericDate(time.Now().Add(time.Minute)),
+			})
+			refToken.Header["kid"] = "kid-1"
+
+			signed, err := refToken.SignedString(signer)
+			require.NoError(t, err)
+
+			claims, err := c.VerifyToken(signed)
+			require.NoError(t, err, "our parser must accept the reference library token")
+			require.Equal(t, "ref-user", claims.Subject)
+		})
+	}
+}
+
+func TestAsymmetricAlgorithmPinning(t *testing.T) {
+	t.Parallel()
+
+	es256, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodES256),
+		WithSigningKey("ec", testSigner(t, SigningMethodES256)),
+	)
+	require.NoError(t, err)
+
+	rs256, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodRS256),
+		WithSigningKey("rsa", testSigner(t, SigningMethodRS256)),
+	)
+	require.NoError(t, err)
+
+	hs256, err := New(testKey, testVerify)
+	require.NoError(t, err)
+
+	rsToken, err := rs256.IssueToken("user")
+	require.NoError(t, err)
+
+	_, err = es256.VerifyToken(rsToken)
+	require.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+
+	_, err = hs256.VerifyToken(rsToken)
+	require.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+
+	// An ES256 instance that also trusts an RSA key (e.g. published by a key
+	// set) never uses it: keys only verify with the pinned algorithm.
+	mixed, err := New(nil, nil,
+		WithSigningMethod(SigningMethodRS256),
+		WithKeySet(&stubKeySet{keys: []VerificationKey{
+			{KeyID: "rsa", Key: testSigner(t, SigningMethodES256).Public()},
+			{KeyID: "rsa", Key: testSigner(t, SigningMethodRS256).Public(), Alg: "RS512"},
+		}}),
+	)
+	require.NoError(t, err)
+
+	_, err = mixed.VerifyToken(rsToken)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestAsymmetricKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey := testSigner(t, SigningMethodES256)
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	oldIssuer, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodES256),
+		WithSigningKey("old", oldKey),
+	)
+	require.NoError(t, err)
+
+	oldToken, err := oldIssuer.IssueToken("user")
+	require.NoError(t, err)
+
+	c, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodES256),
+		WithSigningKey("new", newKey),
+		WithVerificationKeys(VerificationKey{KeyID: "old", Key: oldKey.Public()}),
+	)
+	require.NoError(t, err)
+
+	_, err = c.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, err := c.IssueToken("user")
+	require.NoError(t, err)
+
+	_, err = c.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// The old issuer does not know the new key.
+	_, err = oldIssuer.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestAsymmetricKeySet(t *testing.T) {
+	t.Parallel()
+
+	signer := testSigner(t, SigningMethodEdDSA)
+
+	issuer, err := New(nil, testVerify,
+		WithSigningMethod(SigningMethodEdDSA),
+		WithSigningKey("idp-1", signer),
+	)
+	require.NoError(t, err)
+
+	token, err := issuer.IssueToken("user")
+	require.NoError(t, err)
+
+	ks := &stubKeySet{keys: []VerificationKey{{KeyID: "idp-1", Key: signer.Public()}}}
+
+	c, err := New(nil, nil, WithSigningMethod(SigningMethodEdDSA), WithKeySet(ks))
+	require.NoError(t, err)
+
+	_, err = c.VerifyTokenContext(t.Context(), token)
+	require.NoError(t, err)
+	require.Equal(t, []string{"idp-1"}, ks.kids)
+
+	ks.err = errors.New("idp down")
+
+	_, err = c.VerifyToken(token)
+	require.ErrorIs(t, err, ErrKeySetUnavailable)
+
+	ks.err = nil
+	ks.keys = nil
+
+	_, err = c.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestNewAsymmetricValidation(t *testing.T) {
+	t.Parallel()
+
+	ecKey := testSigner(t, SigningMethodES256)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024) //nolint:gosec // deliberately weak
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      []byte
+		verifyFn VerifyCredentialsFn
+		opts     []Option
+		wantErr  error
+	}{
+		{
+			name:     "HMAC key with asymmetric method",
+			key:      testKey,
+			verifyFn: testVerify,
+			opts:     []Option{WithSigningMethod(SigningMethodES256), WithSigningKey("k", ecKey)},
+			wantErr:  ErrUnexpectedKey,
+		},
+		{
+			name:     "previous HMAC keys with asymmetric method",
+			verifyFn: testVerify,
+			opts:     []Option{WithSigningMethod(SigningMethodES256), WithSigningKey("k", ecKey), WithPreviousKeys(testKey)},
+			wantErr:  ErrUnexpectedKey,
+		},
+		{
+			name:    "no keys at all",
+			opts:    []Option{WithSigningMethod(SigningMethodES256)},
+			wantErr: ErrNoVerificationKey,
+		},
+		{
+			name:    "signing key without verify function",
+			opts:    []Option{WithSigningMethod(SigningMethodES256), WithSigningKey("k", ecKey)},
+			wantErr: ErrNilVerifyFn,
+		},
+		{
+			name:     "wrong curve",
+			verifyFn: testVerify,
+			opts:     []Option{WithSigningMethod(SigningMethodES256), WithSigningKey("k", p384)},
+			wantErr:  ErrKeyTypeMismatch,
+		},
+		{
+			name:     "wrong key type",
+			verifyFn: testVerify,
+			opts:     []Option{WithSigningMethod(SigningMethodRS256), WithSigningKey("k", ecKey)},
+			wantErr:  ErrKeyTypeMismatch,
+		},
+		{
+			name:     "weak RSA key",
+			verifyFn: testVerify,
+			opts:     []Option{WithSigningMethod(SigningMethodRS256), WithSigningKey("k", weakRSA)},
+			wantErr:  ErrWeakKey,
+		},
+		{
+			name: "invalid verification key",
+			opts: []Option{
+				WithSigningMethod(SigningMethodES256),
+				WithVerificationKeys(VerificationKey{KeyID: "k", Key: p384.Public()}),
+			},
+			wantErr: ErrKeyTypeMismatch,
+		},
+		{
+			name:     "duplicate key ID",
+			verifyFn: testVerify,
+			opts: []Option{
+				WithSigningMethod(SigningMethodES256),
+				WithSigningKey("k", ecKey),
+				WithVerificationKeys(VerificationKey{KeyID: "k", Key: ecKey.Public()}),
+			},
+			wantErr: ErrDuplicateKeyID,
+		},
+		{
+			name:     "asymmetric keys with HMAC method",
+			key:      testKey,
+			verifyFn: testVerify,
+			opts:     []Option{WithVerificationKeys(VerificationKey{Key: ecKey.Public()})},
+			wantErr:  ErrKeyTypeMismatch,
+		},
+		{
+			name: "verification only with key set",
+			opts: []Option{WithSigningMethod(SigningMethodES256), WithKeySet(&stubKeySet{})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := New(tt.key, tt.verifyFn, tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, c)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, c)
+		})
+	}
+}
+
+func TestSignAsymmetricErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := signAsymmetric(SigningMethodES256, nil, "input")
+	require.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, err = signAsymmetric(SigningMethod(99), testSigner(t, SigningMethodES256), "input")
+	require.ErrorIs(t, err, ErrInvalidSigningMethod)
+
+	_, err = signAsymmetric(SigningMethodES256, failingSigner{testSigner(t, SigningMethodES256)}, "input")
+	require.Error(t, err)
+
+	_, err = signAsymmetric(SigningMethodEdDSA, failingSigner{testSigner(t, SigningMethodEdDSA)}, "input")
+	require.Error(t, err)
+
+	_, err = ecdsaToJWS(SigningMethodES256, []byte("not-asn1"))
+	require.Error(t, err)
+
+	require.False(t, verifyWithKey(SigningMethodES256, testSigner(t, SigningMethodES256).Public(), "input", []byte("short")))
+}
+
+// failingSigner wraps a signer whose Sign always fails.
+type failingSigner struct {
+	crypto.Signer
+}
+
+func (failingSigner) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("sign failure")
+}
+
+func TestLoginHandlerVerificationOnly(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(nil, nil,
+		WithSigningMethod(SigningMethodEdDSA),
+		WithVerificationKeys(VerificationKey{Key: testSigner(t, SigningMethodEdDSA).Public()}),
+	)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/login", strings.NewReader(`{}`))
+	c.LoginHandler(rr, req)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
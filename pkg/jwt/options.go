@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"crypto"
 	"log/slog"
 	"time"
 )
@@ -96,9 +97,12 @@ func WithAuthorizationHeader(authorizationHeader string) Option {
 	}
 }
 
-// WithSigningMethod sets the HMAC signing algorithm ([SigningMethodHS256],
-// [SigningMethodHS384] or [SigningMethodHS512]); any other value is rejected by
-// [New]. The default is [SigningMethodHS256].
+// WithSigningMethod sets the signing algorithm: HMAC ([SigningMethodHS256],
+// [SigningMethodHS384], [SigningMethodHS512]), RSA ([SigningMethodRS256],
+// [SigningMethodRS384], [SigningMethodRS512]), ECDSA ([SigningMethodES256],
+// [SigningMethodES384], [SigningMethodES512]) or [SigningMethodEdDSA]; any other
+// value is rejected by [New]. The default is [SigningMethodHS256]. Only tokens
+// declaring exactly this algorithm are accepted, whatever keys are configured.
 func WithSigningMethod(signingMethod SigningMethod) Option {
 	return func(c *JWT) {
 		c.signingMethod = signingMethod
@@ -119,6 +123,82 @@ func WithPreviousKeys(previousKeys ...[]byte) Option {
 	}
 }
 
+// WithSigningKey sets the private key and its key ID (`kid`) used to sign tokens
+// with an asymmetric signing method: an *rsa.PrivateKey for RS*, an
+// *ecdsa.PrivateKey on the matching curve for ES*, or an ed25519.PrivateKey for
+// EdDSA. Any crypto.Signer backed by such a key (e.g. a KMS or HSM signer) is
+// accepted. Its public key is accepted for verification and published by
+// [JWT.JWKSHandler]. RSA keys must be at least 2048 bits. An empty kid omits the
+// header parameter, which is discouraged once more than one key is in use.
+func WithSigningKey(kid string, key crypto.Signer) Option {
+	return func(c *JWT) {
+		c.keyID = kid
+		c.signer = key
+	}
+}
+
+// WithVerificationKeys registers additional asymmetric public keys accepted for
+// verification: the keys of other issuers, or previous signing keys during a
+// rotation window. They are published by [JWT.JWKSHandler] alongside the
+// signing key. Each key must match the signing method, and non-empty key IDs
+// must be unique, as enforced by [New]. The slice is copied.
+func WithVerificationKeys(keys ...VerificationKey) Option {
+	return func(c *JWT) {
+		c.publicKeys = append([]VerificationKey(nil), keys...)
+	}
+}
+
+// WithKeySet sets a dynamic source of asymmetric verification keys, such as a
+// [JWKSKeySet] reading an identity provider's JWKS. It is consulted only when
+// no static key verifies a token. Its keys are not republished by
+// [JWT.JWKSHandler].
+func WithKeySet(keySet KeySet) Option {
+	return func(c *JWT) {
+		c.keySet = keySet
+	}
+}
+
+// WithRevocationStore sets the denylist consulted on every verification for the
+// token ID (`jti`) and session ID (`sid`), which makes tokens revocable before
+// their expiration (see [JWT.LogoutHandler]). Verification fails closed when the
+// store is unavailable. A nil store (the default, unless [WithRefreshTokens] is
+// set) keeps tokens stateless.
+func WithRevocationStore(store RevocationStore) Option {
+	return func(c *JWT) {
+		c.revocationStore = store
+	}
+}
+
+// WithRefreshTokens enables refresh tokens: [JWT.LoginHandler] and
+// [JWT.RefreshHandler] return a [TokenPair] whose opaque refresh token, valid
+// for lifetime (capped by [WithMaxSessionLifetime]), can be exchanged once for
+// a new pair. The store also serves as the [RevocationStore] unless one is set
+// with [WithRevocationStore]. The lifetime must be at least one second when the
+// store is not nil.
+func WithRefreshTokens(store RefreshTokenStore, lifetime time.Duration) Option {
+	return func(c *JWT) {
+		c.refreshStore = store
+		c.refreshLifetime = lifetime
+	}
+}
+
+// WithSendTokenPairFn overrides how the token pairs issued with refresh tokens
+// enabled are written. A nil function restores the default JSON responder.
+func WithSendTokenPairFn(sendTokenPairFn SendTokenPairFn) Option {
+	return func(c *JWT) {
+		c.sendTokenPairFn = sendTokenPairFn
+	}
+}
+
+// WithGrantsFn sets the function providing the `scope` and `roles` claims of
+// issued tokens, which authorization layers (see the authz subpackage) check.
+// A nil function (the default) issues tokens without grants.
+func WithGrantsFn(grantsFn GrantsFn) Option {
+	return func(c *JWT) {
+		c.grantsFn = grantsFn
+	}
+}
+
 // WithClaimIssuer sets the `iss` (Issuer) JWT claim. An empty string (the
 // default) disables both issuing and enforcing the claim.
 // See https://datatracker.ietf.org/doc/html/rfc7519#section-4.1.1
@@ -147,3 +227,53 @@ func WithLogger(logger *slog.Logger) Option {
 		c.logger = logger
 	}
 }
+
+// JWKSOption is the type of functions that configure a [JWKSKeySet].
+type JWKSOption func(ks *JWKSKeySet)
+
+// WithJWKSHTTPClient overrides the HTTP client used to fetch the JWKS document.
+// A nil client restores a default client bounded by the fetch timeout.
+func WithJWKSHTTPClient(httpClient HTTPClient) JWKSOption {
+	return func(ks *JWKSKeySet) {
+		ks.httpClient = httpClient
+	}
+}
+
+// WithJWKSTimeout sets the timeout of each JWKS fetch. It must be positive; see
+// [DefaultJWKSTimeout] for the default.
+func WithJWKSTimeout(timeout time.Duration) JWKSOption {
+	return func(ks *JWKSKeySet) {
+		ks.timeout = timeout
+	}
+}
+
+// WithJWKSRefreshInterval sets how long a fetched JWKS document is used before
+// it is fetched again. It must be positive; see [DefaultJWKSRefreshInterval]
+// for the default.
+func WithJWKSRefreshInterval(refreshInterval time.Duration) JWKSOption {
+	return func(ks *JWKSKeySet) {
+		ks.refreshInterval = refreshInterval
+	}
+}
+
+// WithJWKSMinRefreshInterval sets the minimum time between two refreshes forced
+// by a token carrying an unknown `kid`, so tokens with random key IDs cannot
+// turn into a fetch storm against the provider, and between two attempts of a
+// failing background refresh. It must not be negative; see
+// [DefaultJWKSMinRefreshInterval] for the default.
+func WithJWKSMinRefreshInterval(minRefreshInterval time.Duration) JWKSOption {
+	return func(ks *JWKSKeySet) {
+		ks.minRefreshInterval = minRefreshInterval
+	}
+}
+
+// WithJWKSMaxStale sets how long past its refresh interval the last fetched
+// JWKS document keeps serving stale keys: within this window the verification
+// uses them at once and refreshes the document in the background, or falls
+// back to them when the refresh fails. Zero disables serving stale keys and a
+// negative value is rejected. See [DefaultJWKSMaxStale] for the default.
+func WithJWKSMaxStale(maxStale time.Duration) JWKSOption {
+	return func(ks *JWKSKeySet) {
+		ks.maxStale = maxStale
+	}
+}
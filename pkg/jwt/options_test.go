@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"context"
+	"crypto/ed25519"
 	"log/slog"
 	"net/http"
 	"testing"
@@ -91,6 +92,113 @@ func TestWithPreviousKeys(t *testing.T) {
 	require.Equal(t, [][]byte{keyA, keyB}, c.previousKeys)
 }
 
+func TestWithSigningKey(t *testing.T) {
+	t.Parallel()
+
+	c := &JWT{}
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	WithSigningKey("kid-1", priv)(c)
+	require.Equal(t, "kid-1", c.keyID)
+	require.Equal(t, priv, c.signer)
+}
+
+func TestWithVerificationKeys(t *testing.T) {
+	t.Parallel()
+
+	c := &JWT{}
+	keys := []VerificationKey{{KeyID: "a"}, {KeyID: "b"}}
+	WithVerificationKeys(keys...)(c)
+	require.Equal(t, keys, c.publicKeys)
+
+	keys[0].KeyID = "changed"
+	require.Equal(t, "a", c.publicKeys[0].KeyID)
+}
+
+func TestWithKeySet(t *testing.T) {
+	t.Parallel()
+
+	c := &JWT{}
+	ks := &JWKSKeySet{}
+	WithKeySet(ks)(c)
+	require.Equal(t, ks, c.keySet)
+}
+
+func TestJWKSOptions(t *testing.T) {
+	t.Parallel()
+
+	ks := &JWKSKeySet{}
+	hc := &http.Client{}
+
+	WithJWKSHTTPClient(hc)(ks)
+	WithJWKSTimeout(3 * time.Second)(ks)
+	WithJWKSRefreshInterval(5 * time.Second)(ks)
+	WithJWKSMinRefreshInterval(7 * time.Second)(ks)
+	WithJWKSMaxStale(11 * time.Second)(ks)
+
+	require.Equal(t, hc, ks.httpClient)
+	require.Equal(t, 3*time.Second, ks.timeout)
+	require.Equal(t, 5*time.Second, ks.refreshInterval)
+	require.Equal(t, 7*time.Second, ks.minRefreshInterval)
+	require.Equal(t, 11*time.Second, ks.maxStale)
+}
+
+func TestWithRevocationStore(t *testing.T) {
+	t.Parallel()
+
+	c := &JWT{}
+	store := NewMemoryStore()
+	WithRevocationStore(store)(c)
+	require.Equal(t, store, c.revocationStore)
+}
+
+func TestWithRefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+
+	c, err := New(testKey, testVerify, WithRefreshTokens(store, time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, store, c.refreshStore)
+	require.Equal(t, time.Hour, c.refreshLifetime)
+	require.Equal(t, store, c.revocationStore, "the refresh store doubles as the revocation store")
+
+	other := NewMemoryStore()
+
+	c, err = New(testKey, testVerify, WithRefreshTokens(store, time.Hour), WithRevocationStore(other))
+	require.NoError(t, err)
+	require.Equal(t, other, c.revocationStore)
+}
+
+func TestWithSendTokenPairFn(t *testing.T) {
+	t.Parallel()
+
+	var called bool
+
+	c, err := New(testKey, testVerify,
+		WithRefreshTokens(NewMemoryStore(), time.Hour),
+		WithSendTokenPairFn(func(_ context.Context, w http.ResponseWriter, statusCode int, _ *TokenPair) {
+			called = true
+
+			w.WriteHeader(statusCode)
+		}),
+	)
+	require.NoError(t, err)
+
+	status, _ := serveTest(t, c.LoginHandler, `{"username":"test-name", "password":"test-name"}`, "")
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, called)
+}
+
+func TestWithGrantsFn(t *testing.T) {
+	t.Parallel()
+
+	c := &JWT{}
+	WithGrantsFn(testGrants)(c)
+	require.NotNil(t, c.grantsFn)
+}
+
 func TestWithClaimIssuer(t *testing.T) {
 	t.Parallel()
 
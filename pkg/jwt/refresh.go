@@ -0,0 +1,319 @@
+package jwt
+
+// This file contains the stateful session layer: refresh-token issuance and
+// rotation with reuse detection, and revocation on logout.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// refreshTokenBytes is the entropy, in bytes, of an opaque refresh token.
+	refreshTokenBytes = 32
+
+	// tokenTypeBearer is the RFC 6749 §7.1 `token_type` of issued access tokens.
+	tokenTypeBearer = "Bearer"
+)
+
+// Session configuration errors, logged by the handlers that need the missing store.
+var (
+	// ErrRefreshDisabled names the event when [JWT.RefreshHandler] is invoked
+	// without [WithRefreshTokens].
+	ErrRefreshDisabled = errors.New("jwt: refresh tokens are not enabled")
+
+	// ErrRevocationDisabled names the event when [JWT.LogoutHandler] is invoked
+	// without a [RevocationStore].
+	ErrRevocationDisabled = errors.New("jwt: no revocation store configured")
+)
+
+// TokenPair is the access and refresh token pair issued when refresh tokens are
+// enabled. Its JSON form follows the RFC 6749 §5.1 access token response.
+type TokenPair struct {
+	// AccessToken is the signed JWT to send as a bearer token.
+	AccessToken string `json:"access_token"`
+
+	// TokenType is always "Bearer".
+	TokenType string `json:"token_type"`
+
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int64 `json:"expires_in"`
+
+	// RefreshToken is the opaque single-use token to send to [JWT.RefreshHandler].
+	RefreshToken string `json:"refresh_token"` //nolint:gosec // response field, not a hardcoded credential
+}
+
+// refreshRequest is the JSON body accepted by [JWT.RefreshHandler].
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"` //nolint:gosec // request field, not a hardcoded credential
+}
+
+// RefreshHandler exchanges a refresh token for a new [TokenPair].
+//
+// It expects a JSON body with the `refresh_token` member (capped at the
+// configured max body size). Each refresh token is single use: the exchange
+// rotates it, and the new pair continues the same session (`sid` and
+// `auth_time` are preserved, so [WithMaxSessionLifetime] still applies).
+// Presenting an already rotated token is treated as theft: the whole session is
+// revoked, so neither the legitimate client nor the attacker can continue it.
+// The caller is responsible for restricting the HTTP method.
+func (c *JWT) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if c.refreshStore == nil || !c.canSign() {
+		c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to refresh the JWT token")
+		c.logger.ErrorContext(ctx, "JWT refresh on an instance that cannot issue refresh tokens", slog.Any("error", ErrRefreshDisabled))
+
+		return
+	}
+
+	var req refreshRequest
+
+	if !c.decodeBody(w, r, &req) {
+		return
+	}
+
+	session, err := c.redeemRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		c.sendRefreshError(ctx, w, session, err)
+
+		return
+	}
+
+	claims := c.newClaims(session.Username, NewNumericDate(session.AuthTime), session.SessionID)
+
+	err = c.applyGrants(ctx, claims)
+	if err != nil {
+		c.sendIssueError(ctx, w, claims, err)
+
+		return
+	}
+
+	if c.expiredOnIssue(claims.ExpiresAt) {
+		c.sendRefreshError(ctx, w, session, ErrSessionExpired)
+
+		return
+	}
+
+	c.sendTokenPair(w, r, claims)
+}
+
+// LogoutHandler revokes the presented bearer token and its whole session.
+//
+// The token ID (`jti`) is denylisted until the token expires, and the session
+// ID (`sid`) long enough to outlive every access and refresh token issued for
+// the session, so tokens obtained through earlier renewals or refreshes are
+// rejected too. It requires a [RevocationStore] ([WithRevocationStore] or
+// [WithRefreshTokens]). The caller is responsible for restricting the HTTP
+// method.
+func (c *JWT) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if c.revocationStore == nil {
+		c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to revoke the JWT token")
+		c.logger.ErrorContext(ctx, "JWT logout without a revocation store", slog.Any("error", ErrRevocationDisabled))
+
+		return
+	}
+
+	claims, err := c.checkToken(r)
+	if err != nil {
+		c.writeUnauthorized(w, r, err)
+		c.logger.WarnContext(ctx, "invalid JWT token",
+			slog.String("username", claims.Username),
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	err = c.revokeToken(ctx, claims)
+	if err != nil {
+		c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to revoke the JWT token")
+		c.logger.ErrorContext(ctx, "unable to revoke the JWT token",
+			slog.String("username", claims.Username),
+			slog.Any("error", err),
+		)
+
+		return
+	}
+
+	c.sendResponseFn(ctx, w, http.StatusOK, "logged out")
+}
+
+// redeemRefreshToken consumes the refresh token and returns its session once
+// it is known to be unexpired and not revoked. On reuse it revokes the session.
+func (c *JWT) redeemRefreshToken(ctx context.Context, token string) (RefreshSession, error) {
+	if token == "" {
+		return RefreshSession{}, ErrRefreshTokenNotFound
+	}
+
+	session, err := c.refreshStore.ConsumeRefreshToken(ctx, refreshTokenID(token))
+	if errors.Is(err, ErrRefreshTokenReused) {
+		rerr := c.revokeSession(ctx, session.SessionID)
+		if rerr != nil {
+			c.logger.ErrorContext(ctx, "unable to revoke the JWT session of a reused refresh token",
+				slog.String("username", session.Username),
+				slog.Any("error", rerr),
+			)
+		}
+
+		return session, err
+	}
+
+	if err != nil {
+		return session, err
+	}
+
+	// Stores expire entries on their own clock: check again on ours.
+	if !time.Now().Before(session.ExpiresAt) {
+		return session, ErrRefreshTokenNotFound
+	}
+
+	revoked, err := c.revocationStore.IsRevoked(ctx, session.SessionID)
+	if err != nil {
+		return session, fmt.Errorf("%w: %w", ErrRevocationCheck, err)
+	}
+
+	if revoked {
+		return session, ErrTokenRevoked
+	}
+
+	return session, nil
+}
+
+// sendRefreshError maps a refresh failure to an HTTP response: 401 for an
+// unknown, expired, reused or revoked refresh token and 500 for a store
+// failure. Like the login 401, it carries no WWW-Authenticate challenge.
+func (c *JWT) sendRefreshError(ctx context.Context, w http.ResponseWriter, session RefreshSession, err error) {
+	attrs := []any{slog.String("username", session.Username), slog.Any("error", err)}
+
+	switch {
+	case errors.Is(err, ErrRefreshTokenReused):
+		c.sendResponseFn(ctx, w, http.StatusUnauthorized, "invalid refresh token")
+		c.logger.WarnContext(ctx, "JWT refresh token reused: session revoked", attrs...)
+	case errors.Is(err, ErrRefreshTokenNotFound),
+		errors.Is(err, ErrTokenRevoked),
+		errors.Is(err, ErrSessionExpired):
+		c.sendResponseFn(ctx, w, http.StatusUnauthorized, "invalid refresh token")
+		c.logger.WarnContext(ctx, "invalid JWT refresh token", attrs...)
+	default:
+		c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to refresh the JWT token")
+		c.logger.ErrorContext(ctx, "unable to redeem the JWT refresh token", attrs...)
+	}
+}
+
+// sendTokenPair signs claims, issues a refresh token for the same session, and
+// writes the token pair. If either step fails, it returns a 500 response with a
+// generic error message.
+func (c *JWT) sendTokenPair(w http.ResponseWriter, r *http.Request, claims *Claims) {
+	ctx := r.Context()
+
+	accessToken, err := c.signToken(claims)
+	if err != nil {
+		c.sendIssueError(ctx, w, claims, err)
+
+		return
+	}
+
+	refreshToken, err := c.issueRefreshToken(ctx, claims)
+	if err != nil {
+		c.sendIssueError(ctx, w, claims, err)
+
+		return
+	}
+
+	c.sendTokenPairFn(ctx, w, http.StatusOK, &TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    max(0, int64(time.Until(claims.ExpiresAt.Time)/time.Second)),
+		RefreshToken: refreshToken,
+	})
+}
+
+// sendIssueError writes the generic 500 response of a failed token issuance.
+func (c *JWT) sendIssueError(ctx context.Context, w http.ResponseWriter, claims *Claims, err error) {
+	c.sendResponseFn(ctx, w, http.StatusInternalServerError, "unable to sign the JWT token")
+	c.logger.ErrorContext(ctx, "unable to issue the JWT token",
+		slog.String("username", claims.Username),
+		slog.Any("error", err),
+	)
+}
+
+// issueRefreshToken generates an opaque refresh token for the session of
+// claims and stores its session under the token digest. The token lifetime is
+// capped by the maximum session lifetime, when configured.
+func (c *JWT) issueRefreshToken(ctx context.Context, claims *Claims) (string, error) {
+	raw, err := c.rnd.RandomBytes(refreshTokenBytes)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the refresh token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	authTime := claims.AuthTime.Time
+	exp := time.Now().Add(c.refreshLifetime)
+
+	if c.maxSessionLifetime > 0 {
+		if hard := authTime.Add(c.maxSessionLifetime); hard.Before(exp) {
+			exp = hard
+		}
+	}
+
+	err = c.refreshStore.SaveRefreshToken(ctx, refreshTokenID(token), RefreshSession{
+		Username:  claims.Username,
+		SessionID: claims.SessionID,
+		AuthTime:  authTime,
+		ExpiresAt: exp,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to store the refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// revokeToken denylists the token ID until the token expires and its session
+// ID until every token of the session has expired.
+func (c *JWT) revokeToken(ctx context.Context, claims *Claims) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		err := c.revocationStore.Revoke(ctx, claims.ID, claims.ExpiresAt.Add(c.clockSkewLeeway))
+		if err != nil {
+			return fmt.Errorf("unable to revoke the token ID: %w", err)
+		}
+	}
+
+	return c.revokeSession(ctx, claims.SessionID)
+}
+
+// revokeSession denylists a session ID for long enough to outlive both the
+// access and the refresh tokens issued for it from now on.
+func (c *JWT) revokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	until := time.Now().Add(max(c.expirationTime, c.refreshLifetime) + c.clockSkewLeeway)
+
+	err := c.revocationStore.Revoke(ctx, sessionID, until)
+	if err != nil {
+		return fmt.Errorf("unable to revoke the session ID: %w", err)
+	}
+
+	return nil
+}
+
+// refreshTokenID returns the store key of a refresh token: the hex SHA-256
+// digest, so a store leak does not disclose usable tokens.
+func refreshTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
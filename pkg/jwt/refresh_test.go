@@ -0,0 +1,489 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// faultyStore wraps a MemoryStore, failing the operations with a set error.
+type faultyStore struct {
+	*MemoryStore
+
+	revokeErr    error
+	isRevokedErr error
+	saveErr      error
+	consumeErr   error
+}
+
+func (s *faultyStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	if s.revokeErr != nil {
+		return s.revokeErr
+	}
+
+	return s.MemoryStore.Revoke(ctx, id, until)
+}
+
+func (s *faultyStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	if s.isRevokedErr != nil {
+		return false, s.isRevokedErr
+	}
+
+	return s.MemoryStore.IsRevoked(ctx, id)
+}
+
+func (s *faultyStore) SaveRefreshToken(ctx context.Context, id string, session RefreshSession) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+
+	return s.MemoryStore.SaveRefreshToken(ctx, id, session)
+}
+
+func (s *faultyStore) ConsumeRefreshToken(ctx context.Context, id string) (RefreshSession, error) {
+	if s.consumeErr != nil {
+		return RefreshSession{}, s.consumeErr
+	}
+
+	return s.MemoryStore.ConsumeRefreshToken(ctx, id)
+}
+
+// serveTest invokes handler with the given body and bearer token (either may
+// be empty) and returns the response status and body.
+func serveTest(t *testing.T, handler http.HandlerFunc, body, token string) (int, string) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(body))
+
+	if token != "" {
+		req.Header.Set(DefaultAuthorizationHeader, "Bearer "+token)
+	}
+
+	handler(rr, req)
+
+	resp := rr.Result()
+
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err, "error closing resp.Body")
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(data)
+}
+
+// loginPair logs in through c and decodes the returned token pair.
+func loginPair(t *testing.T, c *JWT) *TokenPair {
+	t.Helper()
+
+	status, body := serveTest(t, c.LoginHandler, `{"username":"test-name", "password":"test-name"}`, "")
+	require.Equal(t, http.StatusOK, status, body)
+
+	return decodePair(t, body)
+}
+
+func decodePair(t *testing.T, body string) *TokenPair {
+	t.Helper()
+
+	pair := &TokenPair{}
+	require.NoError(t, json.Unmarshal([]byte(body), pair))
+	require.Equal(t, "Bearer", pair.TokenType)
+	require.NotEmpty(t, pair.AccessToken)
+	require.NotEmpty(t, pair.RefreshToken)
+
+	return pair
+}
+
+func refreshBody(token string) string {
+	return `{"refresh_token":"` + token + `"}`
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey, testVerify, WithRefreshTokens(NewMemoryStore(), time.Hour))
+	require.NoError(t, err)
+
+	first := loginPair(t, c)
+	require.Positive(t, first.ExpiresIn)
+	require.LessOrEqual(t, first.ExpiresIn, int64(DefaultExpirationTime/time.Second))
+
+	firstClaims, err := c.VerifyToken(first.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, firstClaims.SessionID)
+
+	status, body := serveTest(t, c.RefreshHandler, refreshBody(first.RefreshToken), "")
+	require.Equal(t, http.StatusOK, status, body)
+
+	second := decodePair(t, body)
+	require.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	secondClaims, err := c.VerifyToken(second.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, firstClaims.SessionID, secondClaims.SessionID)
+	require.Equal(t, firstClaims.AuthTime.Unix(), secondClaims.AuthTime.Unix())
+	require.NotEqual(t, firstClaims.ID, secondClaims.ID)
+
+	// Replaying the rotated token revokes the whole session.
+	status, body = serveTest(t, c.RefreshHandler, refreshBody(first.RefreshToken), "")
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, "invalid refresh token", body)
+
+	status, _ = serveTest(t, c.RefreshHandler, refreshBody(second.RefreshToken), "")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	_, err = c.VerifyToken(second.AccessToken)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = c.VerifyToken(first.AccessToken)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+
+	// A new login opens a new, unaffected session.
+	third := loginPair(t, c)
+
+	_, err = c.VerifyToken(third.AccessToken)
+	require.NoError(t, err)
+}
+
+func TestRefreshTokenLifetimeCappedBySession(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+
+	c, err := New(testKey, testVerify,
+		WithRefreshTokens(store, 24*time.Hour),
+		WithMaxSessionLifetime(time.Hour),
+	)
+	require.NoError(t, err)
+
+	pair := loginPair(t, c)
+
+	session, err := store.ConsumeRefreshToken(t.Context(), refreshTokenID(pair.RefreshToken))
+	require.NoError(t, err)
+	require.WithinDuration(t, session.AuthTime.Add(time.Hour), session.ExpiresAt, time.Second)
+}
+
+//nolint:gocognit
+func TestRefreshHandlerErrors(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("store down")
+
+	tests := []struct {
+		name         string
+		body         string
+		store        *faultyStore
+		noRefresh    bool
+		revokeFirst  bool
+		breakSigning bool
+		status       int
+		want         string
+	}{
+		{
+			name:      "fails without refresh tokens",
+			body:      refreshBody("x"),
+			noRefresh: true,
+			status:    http.StatusInternalServerError,
+			want:      "unable to refresh the JWT token",
+		},
+		{
+			name:   "fails with invalid body",
+			body:   `{"refresh_token":1}`,
+			status: http.StatusBadRequest,
+			want:   "invalid request body",
+		},
+		{
+			name:   "fails with empty token",
+			body:   refreshBody(""),
+			status: http.StatusUnauthorized,
+			want:   "invalid refresh token",
+		},
+		{
+			name:   "fails with unknown token",
+			body:   refreshBody("unknown"),
+			status: http.StatusUnauthorized,
+			want:   "invalid refresh token",
+		},
+		{
+			name:   "fails with store error",
+			body:   refreshBody("unknown"),
+			store:  &faultyStore{MemoryStore: NewMemoryStore(), consumeErr: storeErr},
+			status: http.StatusInternalServerError,
+			want:   "unable to refresh the JWT token",
+		},
+		{
+			name:   "fails with revocation check error",
+			store:  &faultyStore{MemoryStore: NewMemoryStore(), isRevokedErr: storeErr},
+			status: http.StatusInternalServerError,
+			want:   "unable to refresh the JWT token",
+		},
+		{
+			name:        "fails with revoked session",
+			revokeFirst: true,
+			status:      http.StatusUnauthorized,
+			want:        "invalid refresh token",
+		},
+		{
+			name:   "fails with save error",
+			store:  &faultyStore{MemoryStore: NewMemoryStore(), saveErr: storeErr},
+			status: http.StatusInternalServerError,
+			want:   "unable to sign the JWT token",
+		},
+		{
+			name:         "fails with signing error",
+			breakSigning: true,
+			status:       http.StatusInternalServerError,
+			want:         "unable to sign the JWT token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := tt.store
+			if store == nil {
+				store = &faultyStore{MemoryStore: NewMemoryStore()}
+			}
+
+			var opts []Option
+			if !tt.noRefresh {
+				opts = append(opts, WithRefreshTokens(store, time.Hour))
+			}
+
+			c, err := New(testKey, testVerify, opts...)
+			require.NoError(t, err)
+
+			body := tt.body
+			if body == "" {
+				// Seed a valid refresh token straight into the store, so store
+				// faults only hit the refresh path.
+				token := "seeded-token"
+				require.NoError(t, store.MemoryStore.SaveRefreshToken(t.Context(), refreshTokenID(token), RefreshSession{
+					Username:  "test-name",
+					SessionID: "seeded-sid",
+					AuthTime:  time.Now(),
+					ExpiresAt: time.Now().Add(time.Hour),
+				}))
+
+				body = refreshBody(token)
+			}
+
+			if tt.revokeFirst {
+				require.NoError(t, store.Revoke(t.Context(), "seeded-sid", time.Now().Add(time.Hour)))
+			}
+
+			if tt.breakSigning {
+				// White-box: a tiny token cap makes signing fail after redemption.
+				c.maxTokenBytes = 8
+			}
+
+			status, got := serveTest(t, c.RefreshHandler, body, "")
+			require.Equal(t, tt.status, status)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey, testVerify, WithRefreshTokens(NewMemoryStore(), time.Hour))
+	require.NoError(t, err)
+
+	pair := loginPair(t, c)
+
+	status, body := serveTest(t, c.RefreshHandler, refreshBody(pair.RefreshToken), "")
+	require.Equal(t, http.StatusOK, status)
+
+	rotated := decodePair(t, body)
+
+	status, _ = serveTest(t, c.LogoutHandler, "", "")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	status, body = serveTest(t, c.LogoutHandler, "", rotated.AccessToken)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "logged out", body)
+
+	// Every token of the session is rejected, including the pre-rotation one.
+	for _, token := range []string{pair.AccessToken, rotated.AccessToken} {
+		_, err = c.VerifyToken(token)
+		require.ErrorIs(t, err, ErrTokenRevoked)
+	}
+
+	status, _ = serveTest(t, c.RefreshHandler, refreshBody(rotated.RefreshToken), "")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = serveTest(t, c.LogoutHandler, "", rotated.AccessToken)
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestLogoutHandlerErrors(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey, testVerify)
+	require.NoError(t, err)
+
+	token, err := c.IssueToken("test-name")
+	require.NoError(t, err)
+
+	status, body := serveTest(t, c.LogoutHandler, "", token)
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, "unable to revoke the JWT token", body)
+
+	store := &faultyStore{MemoryStore: NewMemoryStore(), revokeErr: errors.New("store down")}
+
+	c, err = New(testKey, testVerify, WithRevocationStore(store))
+	require.NoError(t, err)
+
+	token, err = c.IssueToken("test-name")
+	require.NoError(t, err)
+
+	status, body = serveTest(t, c.LogoutHandler, "", token)
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, "unable to revoke the JWT token", body)
+
+	// A token without a session ID is revoked by its token ID alone.
+	c, err = New(testKey, testVerify, WithRevocationStore(NewMemoryStore()))
+	require.NoError(t, err)
+
+	claims := c.newClaims("test-name", nil, "")
+	claims.SessionID = ""
+
+	token, err = c.signToken(claims)
+	require.NoError(t, err)
+
+	status, _ = serveTest(t, c.LogoutHandler, "", token)
+	require.Equal(t, http.StatusOK, status)
+
+	_, err = c.VerifyToken(token)
+	require.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestRevocationCheckFailsClosed(t *testing.T) {
+	t.Parallel()
+
+	store := &faultyStore{MemoryStore: NewMemoryStore(), isRevokedErr: errors.New("store down")}
+
+	c, err := New(testKey, testVerify, WithRevocationStore(store))
+	require.NoError(t, err)
+
+	token, err := c.IssueToken("test-name")
+	require.NoError(t, err)
+
+	_, err = c.VerifyToken(token)
+	require.ErrorIs(t, err, ErrRevocationCheck)
+
+	status, _ := serveTest(t, c.Middleware(http.NotFoundHandler()).ServeHTTP, "", token)
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestRenewHandlerPreservesSessionID(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey, testVerify, WithRenewTime(DefaultExpirationTime))
+	require.NoError(t, err)
+
+	token, err := c.IssueToken("test-name")
+	require.NoError(t, err)
+
+	status, renewed := serveTest(t, c.RenewHandler, "", token)
+	require.Equal(t, http.StatusOK, status)
+
+	before, err := c.VerifyToken(token)
+	require.NoError(t, err)
+
+	after, err := c.VerifyToken(renewed)
+	require.NoError(t, err)
+	require.NotEmpty(t, after.SessionID)
+	require.Equal(t, before.SessionID, after.SessionID)
+}
+
+func testGrants(_ context.Context, username string) ([]string, []string, error) {
+	if username == "broken" {
+		return nil, nil, errors.New("directory down")
+	}
+
+	return []string{"orders:read", "orders:write"}, []string{"admin"}, nil
+}
+
+func TestGrants(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(testKey, testVerify,
+		WithGrantsFn(testGrants),
+		WithRefreshTokens(NewMemoryStore(), time.Hour),
+		WithRenewTime(DefaultExpirationTime),
+	)
+	require.NoError(t, err)
+
+	pair := loginPair(t, c)
+
+	claims, err := c.VerifyToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, Scopes{"orders:read", "orders:write"}, claims.Scope)
+	require.Equal(t, []string{"admin"}, claims.Roles)
+
+	status, body := serveTest(t, c.RefreshHandler, refreshBody(pair.RefreshToken), "")
+	require.Equal(t, http.StatusOK, status)
+
+	claims, err = c.VerifyToken(decodePair(t, body).AccessToken)
+	require.NoError(t, err)
+	require.True(t, claims.HasScope("orders:write"))
+
+	status, renewed := serveTest(t, c.RenewHandler, "", pair.AccessToken)
+	require.Equal(t, http.StatusOK, status)
+
+	claims, err = c.VerifyToken(renewed)
+	require.NoError(t, err)
+	require.True(t, claims.HasRole("admin"))
+
+	token, err := c.IssueToken("test-name")
+	require.NoError(t, err)
+
+	claims, err = c.VerifyToken(token)
+	require.NoError(t, err)
+	require.True(t, claims.HasScope("orders:read"))
+
+	_, err = c.IssueTokenContext(t.Context(), "broken")
+	require.ErrorIs(t, err, ErrGrants)
+}
+
+func TestGrantsErrors(t *testing.T) {
+	t.Parallel()
+
+	verifyAny := func(_, _ string) (bool, error) { return true, nil }
+
+	c, err := New(testKey, verifyAny, WithGrantsFn(testGrants))
+	require.NoError(t, err)
+
+	status, body := serveTest(t, c.LoginHandler, `{"username":"broken", "password":"x"}`, "")
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, "unable to sign the JWT token", body)
+
+	store := NewMemoryStore()
+
+	c, err = New(testKey, verifyAny, WithGrantsFn(testGrants), WithRefreshTokens(store, time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveRefreshToken(t.Context(), refreshTokenID("rt"), RefreshSession{
+		Username:  "broken",
+		SessionID: "sid",
+		AuthTime:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	status, _ = serveTest(t, c.RefreshHandler, refreshBody("rt"), "")
+	require.Equal(t, http.StatusInternalServerError, status)
+}
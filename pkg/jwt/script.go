@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/luascript"
+)
+
+// DefaultKeyPrefix is the default prefix of the keys written by a [ScriptStore].
+const DefaultKeyPrefix = "jwt:"
+
+// revokeScript marks KEYS[1] as revoked for ARGV[1] milliseconds, unless it
+// is already revoked for longer, and returns 1 when the entry was extended.
+const revokeScript = `
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl == -1 or ttl >= tonumber(ARGV[1]) then
+  return 0
+end
+redis.call('SET', KEYS[1], '1', 'PX', ARGV[1])
+return 1
+`
+
+// isRevokedScript returns 1 when KEYS[1] is revoked, 0 otherwise.
+const isRevokedScript = `
+return redis.call('EXISTS', KEYS[1])
+`
+
+// saveRefreshScript stores the session ARGV[1] under KEYS[1] for ARGV[2]
+// milliseconds.
+const saveRefreshScript = `
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`
+
+// consumeRefreshScript returns false when the session KEYS[1] is missing.
+// Otherwise it marks it as used with KEYS[2], kept as long as the session, and
+// returns the session with 1 for the first use, or 0 for a reuse.
+const consumeRefreshScript = `
+local cur = redis.call('GET', KEYS[1])
+if not cur then
+  return false
+end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+  ttl = 1
+end
+if redis.call('SET', KEYS[2], '1', 'PX', ttl, 'NX') then
+  return {cur, 1}
+end
+return {cur, 0}
+`
+
+// ScriptStore key types.
+const (
+	keyRevoked     = "revoked:"
+	keyRefresh     = "refresh:"
+	keyRefreshUsed = "refresh-used:"
+)
+
+var (
+	// ErrNilEval is returned by [NewScriptStore] when the script runner is nil.
+	ErrNilEval = errors.New("jwt: nil eval function")
+
+	// ErrScriptReply is returned when a store script returns an unexpected reply.
+	ErrScriptReply = errors.New("jwt: unexpected script reply")
+)
+
+// EvalFunc runs a Lua script on a Redis-compatible server and returns its
+// reply; [github.com/tecnickcom/nurago/pkg/luascript] builds one from a Redis
+// or Valkey client.
+type EvalFunc = luascript.EvalFunc
+
+var _ RefreshTokenStore = (*ScriptStore)(nil)
+
+// ScriptStore is a distributed [RefreshTokenStore] (and [RevocationStore])
+// keeping the denylist and the refresh sessions on a Redis-compatible server
+// (Redis 5+ or Valkey), updated by atomic Lua scripts, so they are shared by
+// every instance of a service.
+//
+// Every entry expires with the token it describes, so the store needs no
+// cleanup. Under the key prefix:
+//
+//   - `revoked:<id>` marks a revoked token or session ID;
+//   - `refresh:{<digest>}` holds the JSON-encoded refresh session;
+//   - `refresh-used:{<digest>}` marks a consumed refresh token. It is written
+//     with SET NX by the script reading the session, so exactly one of several
+//     concurrent exchanges of the same token succeeds and every other one is
+//     reported as a reuse. The hash tag keeps both keys in the same cluster
+//     slot.
+type ScriptStore struct {
+	eval   EvalFunc
+	prefix string
+}
+
+// NewScriptStore returns a [ScriptStore] running the scripts with eval and
+// prefixing every key with keyPrefix (e.g. [DefaultKeyPrefix]).
+func NewScriptStore(eval EvalFunc, keyPrefix string) (*ScriptStore, error) {
+	if eval == nil {
+		return nil, ErrNilEval
+	}
+
+	return &ScriptStore{
+		eval:   eval,
+		prefix: keyPrefix,
+	}, nil
+}
+
+// Revoke denylists id until the given time. Revoking an id again only ever
+// extends its entry, and a past time is a no-op.
+func (s *ScriptStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+
+	_, err := s.eval(ctx, revokeScript, []string{s.prefix + keyRevoked + id}, ttlMillis(ttl))
+	if err != nil {
+		return fmt.Errorf("jwt: unable to run the revoke script: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether id is currently denylisted.
+func (s *ScriptStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	reply, err := s.eval(ctx, isRevokedScript, []string{s.prefix + keyRevoked + id})
+	if err != nil {
+		return false, fmt.Errorf("jwt: unable to run the revocation check script: %w", err)
+	}
+
+	n, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+	}
+
+	return n > 0, nil
+}
+
+// SaveRefreshToken stores session under id until session.ExpiresAt.
+func (s *ScriptStore) SaveRefreshToken(ctx context.Context, id string, session RefreshSession) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("jwt: unable to encode the refresh session: %w", err)
+	}
+
+	_, err = s.eval(ctx, saveRefreshScript, []string{s.refreshKey(keyRefresh, id)}, string(data), ttlMillis(ttl))
+	if err != nil {
+		return fmt.Errorf("jwt: unable to run the save refresh script: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken marks the token under id as used and returns its session.
+func (s *ScriptStore) ConsumeRefreshToken(ctx context.Context, id string) (RefreshSession, error) {
+	var session RefreshSession
+
+	keys := []string{s.refreshKey(keyRefresh, id), s.refreshKey(keyRefreshUsed, id)}
+
+	reply, err := s.eval(ctx, consumeRefreshScript, keys)
+	if err != nil {
+		return session, fmt.Errorf("jwt: unable to run the consume refresh script: %w", err)
+	}
+
+	if reply == nil {
+		return session, ErrRefreshTokenNotFound
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return session, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+	}
+
+	data, ok := values[0].(string)
+	first, okFirst := values[1].(int64)
+
+	if !ok || !okFirst {
+		return session, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+	}
+
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return RefreshSession{}, fmt.Errorf("%w: %w", ErrScriptReply, err)
+	}
+
+	// An entry outliving its session on the server clock is treated as expired.
+	if !time.Now().Before(session.ExpiresAt) {
+		return session, ErrRefreshTokenNotFound
+	}
+
+	if first == 0 {
+		return session, ErrRefreshTokenReused
+	}
+
+	return session, nil
+}
+
+// refreshKey returns the key of the given type of the refresh token id, with
+// a hash tag keeping the keys of a token in the same cluster slot.
+func (s *ScriptStore) refreshKey(typ, id string) string {
+	return s.prefix + typ + "{" + id + "}"
+}
+
+// ttlMillis returns ttl in whole milliseconds, at least 1.
+func ttlMillis(ttl time.Duration) string {
+	return strconv.FormatInt(max(1, ttl.Milliseconds()), 10)
+}
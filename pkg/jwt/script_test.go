@@ -0,0 +1,294 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/luascript"
+)
+
+type evalCall struct {
+	script string
+	keys   []string
+	args   []string
+}
+
+type fakeEval struct {
+	calls []evalCall
+	reply any
+	err   error
+}
+
+func (f *fakeEval) eval(_ context.Context, script string, keys []string, args ...string) (any, error) {
+	f.calls = append(f.calls, evalCall{script: script, keys: keys, args: args})
+
+	return f.reply, f.err
+}
+
+// fakeServer emulates the revocation scripts of a Redis-compatible server.
+type fakeServer struct {
+	mux    sync.Mutex
+	expiry map[string]time.Time
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{expiry: make(map[string]time.Time)}
+}
+
+func (f *fakeServer) run(script string, keys []string, args []string) (any, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	now := time.Now()
+	exp, ok := f.expiry[keys[0]]
+	live := ok && now.Before(exp)
+
+	switch script {
+	case revokeScript:
+		ms, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		until := now.Add(time.Duration(ms) * time.Millisecond)
+		if live && !until.After(exp) {
+			return int64(0), nil
+		}
+
+		f.expiry[keys[0]] = until
+
+		return int64(1), nil
+	case isRevokedScript:
+		if live {
+			return int64(1), nil
+		}
+
+		return int64(0), nil
+	}
+
+	return nil, errors.New("unsupported script")
+}
+
+// fakeRedis exposes fakeServer as a pkg/redis client.
+type fakeRedis struct {
+	*fakeServer
+}
+
+func (f fakeRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	vals := make([]string, len(args))
+	for i, a := range args {
+		vals[i] = fmt.Sprint(a)
+	}
+
+	return f.run(script, keys, vals)
+}
+
+// fakeValkey exposes fakeServer as a pkg/valkey client.
+type fakeValkey struct {
+	*fakeServer
+}
+
+func (f fakeValkey) Eval(_ context.Context, script string, keys []string, args ...string) (any, error) {
+	return f.run(script, keys, args)
+}
+
+func TestNewScriptStore(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewScriptStore(nil, DefaultKeyPrefix)
+	require.ErrorIs(t, err, ErrNilEval)
+	require.Nil(t, s)
+
+	f := &fakeEval{}
+	s, err = NewScriptStore(f.eval, DefaultKeyPrefix)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+}
+
+func TestScriptStore_Revoke(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeEval{reply: int64(1)}
+	s, err := NewScriptStore(f.eval, "app:")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Revoke(t.Context(), "past", time.Now().Add(-time.Second)))
+	require.Empty(t, f.calls)
+
+	require.NoError(t, s.Revoke(t.Context(), "id", time.Now().Add(time.Hour)))
+	require.Len(t, f.calls, 1)
+	require.Equal(t, revokeScript, f.calls[0].script)
+	require.Equal(t, []string{"app:revoked:id"}, f.calls[0].keys)
+	require.Len(t, f.calls[0].args, 1)
+
+	f.err = errors.New("down")
+	require.Error(t, s.Revoke(t.Context(), "id", time.Now().Add(time.Hour)))
+}
+
+func TestScriptStore_RevokeExtendOnly(t *testing.T) {
+	t.Parallel()
+
+	redisEval, err := luascript.Redis(fakeRedis{newFakeServer()})
+	require.NoError(t, err)
+
+	valkeyEval, err := luascript.Valkey(fakeValkey{newFakeServer()})
+	require.NoError(t, err)
+
+	for name, eval := range map[string]EvalFunc{"redis": redisEval, "valkey": valkeyEval} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := NewScriptStore(eval, DefaultKeyPrefix)
+			require.NoError(t, err)
+
+			testRevokeExtendOnly(t, s)
+		})
+	}
+}
+
+func TestScriptStore_IsRevoked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		reply   any
+		err     error
+		want    bool
+		wantErr error
+	}{
+		{name: "revoked", reply: int64(1), want: true},
+		{name: "not revoked", reply: int64(0)},
+		{name: "eval error", err: errors.New("down"), wantErr: errors.New("")},
+		{name: "unexpected type", reply: "1", wantErr: ErrScriptReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeEval{reply: tt.reply, err: tt.err}
+			s, err := NewScriptStore(f.eval, DefaultKeyPrefix)
+			require.NoError(t, err)
+
+			got, err := s.IsRevoked(t.Context(), "id")
+
+			require.Len(t, f.calls, 1)
+			require.Equal(t, isRevokedScript, f.calls[0].script)
+			require.Equal(t, []string{"jwt:revoked:id"}, f.calls[0].keys)
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+
+				if tt.err == nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScriptStore_SaveRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	f := &fakeEval{reply: int64(1)}
+	s, err := NewScriptStore(f.eval, DefaultKeyPrefix)
+	require.NoError(t, err)
+
+	session := RefreshSession{Username: "alice", SessionID: "sid", ExpiresAt: time.Now().Add(time.Hour)}
+
+	// An already expired session is not stored.
+	require.NoError(t, s.SaveRefreshToken(t.Context(), "rt", RefreshSession{ExpiresAt: time.Now().Add(-time.Second)}))
+	require.Empty(t, f.calls)
+
+	require.NoError(t, s.SaveRefreshToken(t.Context(), "rt", session))
+	require.Len(t, f.calls, 1)
+	require.Equal(t, saveRefreshScript, f.calls[0].script)
+	require.Equal(t, []string{"jwt:refresh:{rt}"}, f.calls[0].keys)
+	require.Len(t, f.calls[0].args, 2)
+
+	var got RefreshSession
+
+	require.NoError(t, json.Unmarshal([]byte(f.calls[0].args[0]), &got))
+	require.Equal(t, session.Username, got.Username)
+
+	f.err = errors.New("down")
+	require.Error(t, s.SaveRefreshToken(t.Context(), "rt", session))
+}
+
+func TestScriptStore_ConsumeRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	session := RefreshSession{
+		Username:  "alice",
+		SessionID: "sid",
+		AuthTime:  time.Now().UTC().Truncate(time.Second),
+		ExpiresAt: time.Now().UTC().Add(time.Hour).Truncate(time.Second),
+	}
+
+	sessionJSON, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		reply   any
+		err     error
+		want    RefreshSession
+		wantErr error
+	}{
+		{name: "first use", reply: []any{string(sessionJSON), int64(1)}, want: session},
+		{name: "reuse", reply: []any{string(sessionJSON), int64(0)}, want: session, wantErr: ErrRefreshTokenReused},
+		{name: "not found", reply: nil, wantErr: ErrRefreshTokenNotFound},
+		{
+			name:    "expired on the server clock",
+			reply:   []any{`{"exp":"2000-01-01T00:00:00Z"}`, int64(1)},
+			want:    RefreshSession{ExpiresAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+			wantErr: ErrRefreshTokenNotFound,
+		},
+		{name: "eval error", err: errors.New("down"), wantErr: errors.New("")},
+		{name: "unexpected type", reply: "x", wantErr: ErrScriptReply},
+		{name: "unexpected length", reply: []any{string(sessionJSON)}, wantErr: ErrScriptReply},
+		{name: "unexpected values", reply: []any{int64(1), int64(1)}, wantErr: ErrScriptReply},
+		{name: "invalid json", reply: []any{"{", int64(1)}, wantErr: ErrScriptReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeEval{reply: tt.reply, err: tt.err}
+			s, err := NewScriptStore(f.eval, DefaultKeyPrefix)
+			require.NoError(t, err)
+
+			got, err := s.ConsumeRefreshToken(t.Context(), "rt")
+
+			require.Len(t, f.calls, 1)
+			require.Equal(t, consumeRefreshScript, f.calls[0].script)
+			require.Equal(t, []string{"jwt:refresh:{rt}", "jwt:refresh-used:{rt}"}, f.calls[0].keys)
+			require.Equal(t, tt.want, got)
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+
+				if tt.err == nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
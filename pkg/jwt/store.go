@@ -0,0 +1,181 @@
+package jwt
+
+// This file contains the server-side token state: the revocation (denylist)
+// and refresh-token store contracts and their in-memory implementation.
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is the minimum time between two passes of [MemoryStore]
+// reclaiming expired entries.
+const memorySweepInterval = time.Minute
+
+// Store errors returned by [RefreshTokenStore.ConsumeRefreshToken].
+var (
+	// ErrRefreshTokenNotFound is returned when a refresh token is unknown or expired.
+	ErrRefreshTokenNotFound = errors.New("jwt: refresh token not found")
+
+	// ErrRefreshTokenReused is returned when a refresh token that was already
+	// rotated is presented again, which means that more than one party holds it.
+	ErrRefreshTokenReused = errors.New("jwt: refresh token reused")
+)
+
+// RevocationStore is a denylist of revoked token IDs (the `jti` claim) and
+// session IDs (the `sid` claim), consulted on every verification.
+//
+// Entries only need to outlive the tokens they revoke, so implementations
+// should expire them at the given time. Implementations must be safe for
+// concurrent use.
+type RevocationStore interface {
+	// Revoke denylists id until the given time. Revoking an id again must
+	// never shorten its entry.
+	Revoke(ctx context.Context, id string, until time.Time) error
+
+	// IsRevoked reports whether id is currently denylisted.
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// RefreshSession is the server-side state of a refresh token.
+type RefreshSession struct {
+	// Username is the authenticated principal.
+	Username string `json:"username"`
+
+	// SessionID is the `sid` claim shared by every token of the login session.
+	SessionID string `json:"sid"`
+
+	// AuthTime is the time of the original login.
+	AuthTime time.Time `json:"auth_time"`
+
+	// ExpiresAt is the time after which the refresh token is no longer accepted.
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// RefreshTokenStore persists refresh-token sessions for rotation and reuse
+// detection. It also acts as the [RevocationStore] of the instance unless
+// another one is set with [WithRevocationStore].
+//
+// Refresh tokens are never stored: the id is a SHA-256 digest of the opaque
+// token handed to the client. Implementations must be safe for concurrent use.
+type RefreshTokenStore interface {
+	RevocationStore
+
+	// SaveRefreshToken stores session under id until session.ExpiresAt.
+	SaveRefreshToken(ctx context.Context, id string, session RefreshSession) error
+
+	// ConsumeRefreshToken atomically marks the token under id as used and
+	// returns its session. It returns [ErrRefreshTokenNotFound] when the token
+	// is unknown or expired, and the session together with
+	// [ErrRefreshTokenReused] when the token was already consumed. Consumed
+	// tokens must be remembered until they expire, so reuse stays detectable.
+	ConsumeRefreshToken(ctx context.Context, id string) (RefreshSession, error)
+}
+
+// memoryRefresh is a refresh session held by [MemoryStore].
+type memoryRefresh struct {
+	session RefreshSession
+	used    bool
+}
+
+// MemoryStore is an in-memory [RefreshTokenStore] (and [RevocationStore]).
+//
+// Its state is local to the process and lost on restart: use it for tests and
+// single-instance services, and a shared store (e.g. [ScriptStore]) when
+// several instances serve the same users.
+type MemoryStore struct {
+	mux       sync.Mutex
+	revoked   map[string]time.Time
+	refresh   map[string]*memoryRefresh
+	lastSweep time.Time
+}
+
+// NewMemoryStore constructs an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		revoked:   make(map[string]time.Time),
+		refresh:   make(map[string]*memoryRefresh),
+		lastSweep: time.Now(),
+	}
+}
+
+// Revoke denylists id until the given time. Revoking an id again only ever
+// extends its entry.
+func (s *MemoryStore) Revoke(_ context.Context, id string, until time.Time) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.sweep()
+
+	if until.After(s.revoked[id]) {
+		s.revoked[id] = until
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether id is currently denylisted.
+func (s *MemoryStore) IsRevoked(_ context.Context, id string) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	until, ok := s.revoked[id]
+
+	return ok && time.Now().Before(until), nil
+}
+
+// SaveRefreshToken stores session under id until session.ExpiresAt.
+func (s *MemoryStore) SaveRefreshToken(_ context.Context, id string, session RefreshSession) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.sweep()
+
+	s.refresh[id] = &memoryRefresh{session: session}
+
+	return nil
+}
+
+// ConsumeRefreshToken marks the token under id as used and returns its session.
+func (s *MemoryStore) ConsumeRefreshToken(_ context.Context, id string) (RefreshSession, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	entry, ok := s.refresh[id]
+	if !ok || !time.Now().Before(entry.session.ExpiresAt) {
+		return RefreshSession{}, ErrRefreshTokenNotFound
+	}
+
+	if entry.used {
+		return entry.session, ErrRefreshTokenReused
+	}
+
+	entry.used = true
+
+	return entry.session, nil
+}
+
+// sweep reclaims the expired entries, at most once per [memorySweepInterval].
+// The caller must hold the lock.
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for id, until := range s.revoked {
+		if !now.Before(until) {
+			delete(s.revoked, id)
+		}
+	}
+
+	for id, entry := range s.refresh {
+		if !now.Before(entry.session.ExpiresAt) {
+			delete(s.refresh, id)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreRevocation(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	ctx := t.Context()
+
+	revoked, err := s.IsRevoked(ctx, "id")
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	require.NoError(t, s.Revoke(ctx, "id", time.Now().Add(time.Hour)))
+
+	revoked, err = s.IsRevoked(ctx, "id")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// A shorter revocation never shortens an existing one.
+	require.NoError(t, s.Revoke(ctx, "id", time.Now().Add(-time.Hour)))
+
+	revoked, err = s.IsRevoked(ctx, "id")
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	require.NoError(t, s.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+
+	revoked, err = s.IsRevoked(ctx, "expired")
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestMemoryStoreRevokeExtendOnly(t *testing.T) {
+	t.Parallel()
+
+	testRevokeExtendOnly(t, NewMemoryStore())
+}
+
+// testRevokeExtendOnly checks that revoking an id again only ever extends its
+// entry.
+func testRevokeExtendOnly(t *testing.T, s RevocationStore) {
+	t.Helper()
+
+	ctx := t.Context()
+
+	require.NoError(t, s.Revoke(ctx, "long", time.Now().Add(time.Hour)))
+	require.NoError(t, s.Revoke(ctx, "long", time.Now().Add(50*time.Millisecond)))
+
+	require.NoError(t, s.Revoke(ctx, "short", time.Now().Add(50*time.Millisecond)))
+	require.NoError(t, s.Revoke(ctx, "short", time.Now().Add(time.Hour)))
+
+	require.NoError(t, s.Revoke(ctx, "expiring", time.Now().Add(50*time.Millisecond)))
+
+	time.Sleep(100 * time.Millisecond)
+
+	for id, want := range map[string]bool{"long": true, "short": true, "expiring": false} {
+		revoked, err := s.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, want, revoked, id)
+	}
+}
+
+func TestMemoryStoreRefresh(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	ctx := t.Context()
+
+	session := RefreshSession{
+		Username:  "test-name",
+		SessionID: "sid",
+		AuthTime:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	_, err := s.ConsumeRefreshToken(ctx, "missing")
+	require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+
+	require.NoError(t, s.SaveRefreshToken(ctx, "rt", session))
+
+	got, err := s.ConsumeRefreshToken(ctx, "rt")
+	require.NoError(t, err)
+	require.Equal(t, session, got)
+
+	got, err = s.ConsumeRefreshToken(ctx, "rt")
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+	require.Equal(t, session, got)
+
+	session.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, s.SaveRefreshToken(ctx, "expired", session))
+
+	_, err = s.ConsumeRefreshToken(ctx, "expired")
+	require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	ctx := t.Context()
+
+	require.NoError(t, s.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+	require.NoError(t, s.Revoke(ctx, "live", time.Now().Add(time.Hour)))
+	require.NoError(t, s.SaveRefreshToken(ctx, "expired", RefreshSession{ExpiresAt: time.Now().Add(-time.Second)}))
+	require.NoError(t, s.SaveRefreshToken(ctx, "live", RefreshSession{ExpiresAt: time.Now().Add(time.Hour)}))
+
+	// Within the sweep interval nothing is reclaimed.
+	require.Len(t, s.revoked, 2)
+	require.Len(t, s.refresh, 2)
+
+	s.mux.Lock()
+	s.lastSweep = time.Now().Add(-memorySweepInterval)
+	s.mux.Unlock()
+
+	require.NoError(t, s.Revoke(ctx, "other", time.Now().Add(time.Hour)))
+
+	require.Len(t, s.revoked, 2)
+	require.Contains(t, s.revoked, "live")
+	require.Contains(t, s.revoked, "other")
+	require.Len(t, s.refresh, 1)
+	require.Contains(t, s.refresh, "live")
+}
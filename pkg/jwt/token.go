@@ -1,10 +1,12 @@
 package jwt
 
-// This file contains the RFC 7515 compact JWS wire format: the HMAC signing
+// This file contains the RFC 7515 compact JWS wire format: the signing
 // methods, token serialization/signing, and parsing/verification/validation.
 
 import (
 	"bytes"
+	"context"
+	"crypto/elliptic"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -19,7 +21,7 @@ import (
 	"time"
 )
 
-// Supported HMAC signing methods.
+// Supported signing methods.
 const (
 	// SigningMethodHS256 is HMAC using SHA-256 (the default).
 	SigningMethodHS256 SigningMethod = iota
@@ -29,6 +31,39 @@ const (
 
 	// SigningMethodHS512 is HMAC using SHA-512.
 	SigningMethodHS512
+
+	// SigningMethodRS256 is RSASSA-PKCS1-v1_5 using SHA-256.
+	SigningMethodRS256
+
+	// SigningMethodRS384 is RSASSA-PKCS1-v1_5 using SHA-384.
+	SigningMethodRS384
+
+	// SigningMethodRS512 is RSASSA-PKCS1-v1_5 using SHA-512.
+	SigningMethodRS512
+
+	// SigningMethodES256 is ECDSA using P-256 and SHA-256.
+	SigningMethodES256
+
+	// SigningMethodES384 is ECDSA using P-384 and SHA-384.
+	SigningMethodES384
+
+	// SigningMethodES512 is ECDSA using P-521 and SHA-512.
+	SigningMethodES512
+
+	// SigningMethodEdDSA is EdDSA using Ed25519 (RFC 8037).
+	SigningMethodEdDSA
+)
+
+// keyFamily groups the signing methods by the kind of key they use.
+type keyFamily uint8
+
+// Key families of the supported signing methods.
+const (
+	familyInvalid keyFamily = iota
+	familyHMAC
+	familyRSA
+	familyECDSA
+	familyEdDSA
 )
 
 // Token parsing and validation errors returned by [JWT.Authenticate].
@@ -62,6 +97,10 @@ var (
 	// under any of the accepted keys.
 	ErrInvalidSignature = errors.New("jwt: invalid token signature")
 
+	// ErrKeySetUnavailable is returned when the configured [KeySet] could not
+	// provide the verification keys (e.g. the remote JWKS endpoint is down).
+	ErrKeySetUnavailable = errors.New("jwt: verification key set unavailable")
+
 	// ErrMissingExpiration is returned when a parsed token lacks an expiration time.
 	ErrMissingExpiration = errors.New("jwt: token missing expiration time")
 
@@ -77,17 +116,27 @@ var (
 	// ErrInvalidAudience is returned when the token audience does not include
 	// every configured audience.
 	ErrInvalidAudience = errors.New("jwt: invalid token audience")
+
+	// ErrTokenRevoked is returned when the token, or its session, was revoked.
+	ErrTokenRevoked = errors.New("jwt: token revoked")
+
+	// ErrRevocationCheck is returned when the [RevocationStore] could not be
+	// consulted; the token is rejected rather than accepted unchecked.
+	ErrRevocationCheck = errors.New("jwt: unable to check token revocation")
 )
 
-// SigningMethod selects the HMAC-SHA2 algorithm used to sign and verify tokens.
+// SigningMethod selects the algorithm used to sign and verify tokens.
 //
-// Only symmetric HMAC methods (RFC 7518 §3.2) are supported: the same secret
-// key both signs and verifies. The zero value is [SigningMethodHS256].
+// Symmetric HMAC methods (RFC 7518 §3.2) use the same secret key to sign and
+// verify. Asymmetric RSA (RFC 7518 §3.3), ECDSA (RFC 7518 §3.4) and EdDSA
+// (RFC 8037) methods sign with a private key and verify with the matching
+// public keys, which can be published as a JWKS. The zero value is
+// [SigningMethodHS256].
 type SigningMethod uint8
 
-// Alg returns the RFC 7518 `alg` header parameter value for the method
-// ("HS256", "HS384" or "HS512"), or an empty string when the method is not one
-// of the supported constants.
+// Alg returns the RFC 7518 `alg` header parameter value for the method (e.g.
+// "HS256", "RS256", "ES256" or "EdDSA"), or an empty string when the method is
+// not one of the supported constants.
 func (m SigningMethod) Alg() string {
 	switch m {
 	case SigningMethodHS256:
@@ -96,50 +145,111 @@ func (m SigningMethod) Alg() string {
 		return "HS384"
 	case SigningMethodHS512:
 		return "HS512"
+	case SigningMethodRS256:
+		return "RS256"
+	case SigningMethodRS384:
+		return "RS384"
+	case SigningMethodRS512:
+		return "RS512"
+	case SigningMethodES256:
+		return "ES256"
+	case SigningMethodES384:
+		return "ES384"
+	case SigningMethodES512:
+		return "ES512"
+	case SigningMethodEdDSA:
+		return "EdDSA"
 	default:
 		return ""
 	}
 }
 
+// family returns the kind of key used by the method, or familyInvalid when the
+// method is not one of the supported constants.
+func (m SigningMethod) family() keyFamily {
+	switch m {
+	case SigningMethodHS256, SigningMethodHS384, SigningMethodHS512:
+		return familyHMAC
+	case SigningMethodRS256, SigningMethodRS384, SigningMethodRS512:
+		return familyRSA
+	case SigningMethodES256, SigningMethodES384, SigningMethodES512:
+		return familyECDSA
+	case SigningMethodEdDSA:
+		return familyEdDSA
+	default:
+		return familyInvalid
+	}
+}
+
 // hashNew returns the constructor of the hash backing the method, or nil when
-// the method is not one of the supported constants.
+// the method is not one of the supported constants or (EdDSA) signs the message
+// without pre-hashing.
 func (m SigningMethod) hashNew() func() hash.Hash {
 	switch m {
-	case SigningMethodHS256:
+	case SigningMethodHS256, SigningMethodRS256, SigningMethodES256:
 		return sha256.New
-	case SigningMethodHS384:
+	case SigningMethodHS384, SigningMethodRS384, SigningMethodES384:
 		return sha512.New384
-	case SigningMethodHS512:
+	case SigningMethodHS512, SigningMethodRS512, SigningMethodES512:
 		return sha512.New
 	default:
 		return nil
 	}
 }
 
-// hashSize returns the hash output size in bytes, which is also the minimum
-// signing key length per RFC 7518 §3.2, or 0 when the method is invalid.
+// hashSize returns the hash output size in bytes, which for HMAC methods is also
+// the minimum signing key length per RFC 7518 §3.2, or 0 when the method is
+// invalid or does not pre-hash.
 func (m SigningMethod) hashSize() int {
 	switch m {
-	case SigningMethodHS256:
+	case SigningMethodHS256, SigningMethodRS256, SigningMethodES256:
 		return sha256.Size
-	case SigningMethodHS384:
+	case SigningMethodHS384, SigningMethodRS384, SigningMethodES384:
 		return sha512.Size384
-	case SigningMethodHS512:
+	case SigningMethodHS512, SigningMethodRS512, SigningMethodES512:
 		return sha512.Size
 	default:
 		return 0
 	}
 }
 
+// curve returns the elliptic curve mandated by an ECDSA method (RFC 7518 §3.4),
+// or nil for any other method.
+func (m SigningMethod) curve() elliptic.Curve {
+	switch m {
+	case SigningMethodES256:
+		return elliptic.P256()
+	case SigningMethodES384:
+		return elliptic.P384()
+	case SigningMethodES512:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
 // IssueToken signs and returns a fresh token for username, using the same claim
-// recipe as the login flow (exp, iat, nbf, jti, sub, iss, aud, auth_time).
+// recipe as the login flow (exp, iat, nbf, jti, sub, iss, aud, auth_time, sid,
+// and the scope and roles from the [GrantsFn]).
 //
 // It is the issuance counterpart to [JWT.Authenticate]: use it to mint a token
 // outside the HTTP login flow (test fixtures, CLI tools, service-to-service
 // calls, WebSocket handshakes) once the caller has verified the user's identity
 // by its own means. It does not verify credentials.
 func (c *JWT) IssueToken(username string) (string, error) {
-	return c.signToken(c.newClaims(username, nil))
+	return c.IssueTokenContext(context.Background(), username)
+}
+
+// IssueTokenContext is like [JWT.IssueToken] but passes ctx to the [GrantsFn].
+func (c *JWT) IssueTokenContext(ctx context.Context, username string) (string, error) {
+	claims := c.newClaims(username, nil, "")
+
+	err := c.applyGrants(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	return c.signToken(claims)
 }
 
 // VerifyToken parses, verifies, and validates a raw compact-JWS token string,
@@ -151,8 +261,17 @@ func (c *JWT) IssueToken(username string) (string, error) {
 // branching; do not echo it to clients (it may reveal token-validation
 // internals). The returned claims are never nil; they are populated only when
 // the token signature verified.
+//
+// A [KeySet] configured via [WithKeySet] is queried without a deadline; use
+// [JWT.VerifyTokenContext] to bound or cancel that lookup.
 func (c *JWT) VerifyToken(tokenString string) (*Claims, error) {
-	return c.parseToken(tokenString)
+	return c.parseToken(context.Background(), tokenString)
+}
+
+// VerifyTokenContext is [JWT.VerifyToken] with a context that bounds any
+// verification key lookup through the configured [KeySet].
+func (c *JWT) VerifyTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
+	return c.parseToken(ctx, tokenString)
 }
 
 // initTokenConfig precomputes the encoded JOSE header for the configured
@@ -166,10 +285,26 @@ func (c *JWT) initTokenConfig() {
 		c.previousKeys[i] = bytes.Clone(key)
 	}
 
-	c.encodedHeader = base64.RawURLEncoding.EncodeToString(
-		[]byte(`{"alg":"` + c.signingMethod.Alg() + `","typ":"JWT"}`),
-	)
-	c.verifyKeys = append([][]byte{c.key}, c.previousKeys...)
+	header := `{"alg":"` + c.signingMethod.Alg() + `","typ":"JWT"`
+	if c.keyID != "" {
+		kid, _ := json.Marshal(c.keyID) // marshaling a string never fails
+		header += `,"kid":` + string(kid)
+	}
+
+	c.encodedHeader = base64.RawURLEncoding.EncodeToString([]byte(header + "}"))
+
+	if c.signingMethod.family() == familyHMAC {
+		c.verifyKeys = append([][]byte{c.key}, c.previousKeys...)
+
+		return
+	}
+
+	// The public half of the signing key is always accepted, ahead of the
+	// additional verification keys.
+	if c.signer != nil {
+		own := VerificationKey{KeyID: c.keyID, Key: c.signer.Public()}
+		c.publicKeys = append([]VerificationKey{own}, c.publicKeys...)
+	}
 }
 
 // signToken serializes claims and signs them as an RFC 7515 compact JWS
@@ -182,7 +317,7 @@ func (c *JWT) signToken(claims *Claims) (string, error) {
 
 	signingInput := c.encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
 
-	sig, err := c.computeSignature(signingInput, c.key)
+	sig, err := c.sign(signingInput)
 	if err != nil {
 		return "", err
 	}
@@ -199,6 +334,18 @@ func (c *JWT) signToken(claims *Claims) (string, error) {
 	return token, nil
 }
 
+// sign returns the signature of signingInput under the configured signing key.
+func (c *JWT) sign(signingInput string) ([]byte, error) {
+	switch c.signingMethod.family() {
+	case familyHMAC:
+		return c.computeSignature(signingInput, c.key)
+	case familyInvalid:
+		return nil, ErrInvalidSigningMethod
+	default:
+		return signAsymmetric(c.signingMethod, c.signer, signingInput)
+	}
+}
+
 // computeSignature returns the HMAC of signingInput under key using the
 // configured signing method.
 func (c *JWT) computeSignature(signingInput string, key []byte) ([]byte, error) {
@@ -213,12 +360,12 @@ func (c *JWT) computeSignature(signingInput string, key []byte) ([]byte, error)
 	return mac.Sum(nil), nil
 }
 
-// parseToken parses a compact JWS, verifies its signature against the accepted
-// keys, and validates the claims. The signature is verified BEFORE the claims
-// payload is decoded, so attacker-controlled JSON never reaches the decoder.
-// The returned claims are never nil; they are populated only when the token
-// signature verified.
-func (c *JWT) parseToken(tokenString string) (*Claims, error) {
+// parseToken parses a compact JWS, verifies its signature against the
+// accepted keys, validates the claims, and checks the revocation store. The
+// signature is verified BEFORE the claims payload is decoded, so
+// attacker-controlled JSON never reaches the decoder. The returned claims are
+// never nil; they are populated only when the token signature verified.
+func (c *JWT) parseToken(ctx context.Context, tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	// Bound the input before any decoding: the JOSE header must be base64- and
@@ -238,7 +385,7 @@ func (c *JWT) parseToken(tokenString string) (*Claims, error) {
 		return claims, ErrMalformedToken
 	}
 
-	err := c.checkHeader(headerSeg)
+	kid, err := c.checkHeader(headerSeg)
 	if err != nil {
 		return claims, err
 	}
@@ -249,8 +396,10 @@ func (c *JWT) parseToken(tokenString string) (*Claims, error) {
 	}
 
 	signingInput := tokenString[:len(headerSeg)+1+len(payloadSeg)]
-	if !c.verifySignature(signingInput, sig) {
-		return claims, ErrInvalidSignature
+
+	err = c.verifyTokenSignature(ctx, kid, signingInput, sig)
+	if err != nil {
+		return claims, err
 	}
 
 	payload, err := base64.RawURLEncoding.Strict().DecodeString(payloadSeg)
@@ -263,45 +412,52 @@ func (c *JWT) parseToken(tokenString string) (*Claims, error) {
 		return claims, fmt.Errorf("%w: invalid claims payload: %w", ErrMalformedToken, err)
 	}
 
-	return claims, c.validateClaims(claims)
+	err = c.validateClaims(claims)
+	if err != nil {
+		return claims, err
+	}
+
+	return claims, c.checkRevoked(ctx, claims)
 }
 
 // joseHeader holds the JOSE header parameters this package inspects: the signing
-// algorithm and the presence of a critical-extensions parameter.
+// algorithm, the key ID, and the presence of a critical-extensions parameter.
 type joseHeader struct {
 	alg  string
+	kid  string
 	crit json.RawMessage
 }
 
-// checkHeader decodes the JOSE header segment and enforces the configured
-// signing algorithm, rejecting alg=none and algorithm-confusion tokens. A `crit`
-// parameter (RFC 7515 §4.1.11) or a duplicate member name is rejected; all other
-// parameters (typ, kid, ...) are ignored, since with a pinned symmetric
-// algorithm and mandatory signature verification they cannot alter how a token
-// is processed.
-func (c *JWT) checkHeader(headerSeg string) error {
+// checkHeader decodes the JOSE header segment, enforces the configured signing
+// algorithm, rejecting alg=none and algorithm-confusion tokens, and returns the
+// `kid` parameter (empty when absent). A `crit` parameter (RFC 7515 §4.1.11) or
+// a duplicate member name is rejected; all other parameters (typ, ...) are
+// ignored, since with a pinned algorithm and mandatory signature verification
+// they cannot alter how a token is processed. The kid only narrows which
+// asymmetric keys are tried: it never selects the algorithm.
+func (c *JWT) checkHeader(headerSeg string) (string, error) {
 	headerJSON, err := base64.RawURLEncoding.Strict().DecodeString(headerSeg)
 	if err != nil {
-		return fmt.Errorf("%w: invalid header encoding: %w", ErrMalformedToken, err)
+		return "", fmt.Errorf("%w: invalid header encoding: %w", ErrMalformedToken, err)
 	}
 
 	header, err := decodeJOSEHeader(headerJSON)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// RFC 7515 §4.1.11: a JWS carrying critical extensions the recipient does not
 	// understand MUST be rejected. This package understands none, so any crit
 	// member, including "crit": null and the invalid empty array, is fatal.
 	if len(header.crit) > 0 {
-		return ErrUnsupportedCritHeader
+		return "", ErrUnsupportedCritHeader
 	}
 
 	if header.alg != c.signingMethod.Alg() {
-		return fmt.Errorf("%w: %q", ErrUnexpectedSigningMethod, header.alg)
+		return "", fmt.Errorf("%w: %q", ErrUnexpectedSigningMethod, header.alg)
 	}
 
-	return nil
+	return header.kid, nil
 }
 
 // decodeJOSEHeader parses the JOSE header JSON in a single strict pass. It
@@ -379,6 +535,11 @@ func (h *joseHeader) readMember(dec *json.Decoder, seen map[string]struct{}) err
 		if err != nil {
 			return fmt.Errorf("%w: invalid alg: %w", ErrMalformedToken, err)
 		}
+	case "kid":
+		err = json.Unmarshal(value, &h.kid)
+		if err != nil {
+			return fmt.Errorf("%w: invalid kid: %w", ErrMalformedToken, err)
+		}
 	case "crit":
 		h.crit = value
 	}
@@ -386,6 +547,21 @@ func (h *joseHeader) readMember(dec *json.Decoder, seen map[string]struct{}) err
 	return nil
 }
 
+// verifyTokenSignature checks sig over signingInput with the keys accepted for
+// the configured signing method: the HMAC keys, or the asymmetric keys matching
+// kid (see [JWT.verifyAsymmetric]).
+func (c *JWT) verifyTokenSignature(ctx context.Context, kid, signingInput string, sig []byte) error {
+	if c.signingMethod.family() == familyHMAC {
+		if !c.verifySignature(signingInput, sig) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return c.verifyAsymmetric(ctx, kid, signingInput, sig)
+}
+
 // verifySignature reports whether sig is a valid signature of signingInput
 // under any of the accepted verification keys (the current signing key plus any
 // configured previous keys). Each comparison is constant-time.
@@ -437,6 +613,31 @@ func (c *JWT) validateClaims(claims *Claims) error {
 	return nil
 }
 
+// checkRevoked consults the revocation store, when configured, for the token ID
+// and the session ID. It fails closed: a store error rejects the token.
+func (c *JWT) checkRevoked(ctx context.Context, claims *Claims) error {
+	if c.revocationStore == nil {
+		return nil
+	}
+
+	for _, id := range []string{claims.ID, claims.SessionID} {
+		if id == "" {
+			continue
+		}
+
+		revoked, err := c.revocationStore.IsRevoked(ctx, id)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRevocationCheck, err)
+		}
+
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	return nil
+}
+
 // expiredOnIssue reports whether exp, once truncated to whole seconds as it will
 // be at signing ([NumericDate.MarshalJSON]), already fails the verification
 // expiry check (RFC 7519 §4.1.4 with the configured leeway). It lets issuance
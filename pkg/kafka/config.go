@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/tecnickcom/nurago/pkg/retrier"
 )
 
 const (
@@ -24,6 +25,18 @@ const (
 	// a signed 32-bit integer and panics inside NewReader when it is out of
 	// range, so the bound is enforced at construction time instead.
 	maxSessionTimeout = math.MaxInt32 * time.Millisecond
+
+	// defaultCommitInterval is the default maximum time Consumer.Run waits
+	// before committing the offsets of the processed messages.
+	defaultCommitInterval = time.Second
+
+	// defaultCommitBatchSize is the default number of processed messages
+	// after which Consumer.Run commits their offsets.
+	defaultCommitBatchSize = 100
+
+	// defaultErrorsBuffer is the default capacity of the async mode Errors()
+	// channel.
+	defaultErrorsBuffer = 256
 )
 
 // config holds configuration options for the Kafka client.
@@ -36,6 +49,17 @@ type config struct {
 	requiredAcks      kafka.RequiredAcks
 	batchSize         int
 	batchTimeout      time.Duration
+	batchBytes        int64
+	compression       kafka.Compression
+	async             bool
+	errorsBuffer      int
+	deliveryReportFn  DeliveryReportFunc
+	concurrency       int
+	commitInterval    time.Duration
+	commitBatchSize   int
+	retrier           *retrier.Retrier
+	deadLetter        *Producer
+	shutdownSignalCh  chan struct{}
 	reader            KReader
 	writer            KWriter
 	checkFn           checkBrokerFn
@@ -51,6 +75,10 @@ func defaultConfig() *config {
 		balancer:          &kafka.Hash{},
 		requiredAcks:      kafka.RequireAll,
 		batchTimeout:      defaultBatchTimeout,
+		errorsBuffer:      defaultErrorsBuffer,
+		concurrency:       1,
+		commitInterval:    defaultCommitInterval,
+		commitBatchSize:   defaultCommitBatchSize,
 	}
 }
 
@@ -114,7 +142,29 @@ func (c *config) validateConsumer(brokers []string, topic string) error {
 		return err
 	}
 
-	return validateSessionTimeout(c.sessionTimeout)
+	err = validateSessionTimeout(c.sessionTimeout)
+	if err != nil {
+		return err
+	}
+
+	return c.validateRun()
+}
+
+// validateRun checks the Consumer.Run configuration values.
+func (c *config) validateRun() error {
+	if c.concurrency < 1 {
+		return fmt.Errorf("concurrency out of range (%d): %w", c.concurrency, ErrInvalidOptions)
+	}
+
+	if c.commitInterval <= 0 {
+		return fmt.Errorf("commit interval out of range (%s): %w", c.commitInterval, ErrInvalidOptions)
+	}
+
+	if c.commitBatchSize < 1 {
+		return fmt.Errorf("commit batch size out of range (%d): %w", c.commitBatchSize, ErrInvalidOptions)
+	}
+
+	return nil
 }
 
 // validateProducer checks the producer constructor arguments and the
@@ -145,6 +195,18 @@ func (c *config) validateProducer(brokers []string, topic string) error {
 		return fmt.Errorf("batch timeout out of range (%s): %w", c.batchTimeout, ErrInvalidOptions)
 	}
 
+	if c.batchBytes < 0 {
+		return fmt.Errorf("negative batch bytes (%d): %w", c.batchBytes, ErrInvalidOptions)
+	}
+
+	if c.compression < 0 || c.compression > kafka.Zstd {
+		return fmt.Errorf("invalid compression codec (%d): %w", c.compression, ErrInvalidOptions)
+	}
+
+	if c.errorsBuffer < 0 {
+		return fmt.Errorf("negative errors buffer (%d): %w", c.errorsBuffer, ErrInvalidOptions)
+	}
+
 	if c.requiredAcks != kafka.RequireNone && c.requiredAcks != kafka.RequireOne && c.requiredAcks != kafka.RequireAll {
 		return fmt.Errorf("invalid required acks (%d): %w", c.requiredAcks, ErrInvalidOptions)
 	}
@@ -28,6 +28,7 @@ type Consumer struct {
 	client  KReader
 	checkFn checkBrokerFn
 	brokers []string
+	groupID string
 }
 
 // NewConsumer constructs a Kafka consumer for a topic and consumer group with optional tuning.
@@ -76,6 +77,7 @@ func NewConsumer(brokers []string, topic, groupID string, opts ...Option) (*Cons
 		client:  client,
 		checkFn: checkFn,
 		brokers: slices.Clone(brokers),
+		groupID: groupID,
 	}, nil
 }
 
@@ -143,6 +143,36 @@ func Test_NewConsumer(t *testing.T) {
 			groupID:   "",
 			wantErrIs: ErrInvalidOptions,
 		},
+		{
+			name:    "zero concurrency",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			groupID: "one",
+			options: []Option{
+				WithConcurrency(0),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
+		{
+			name:    "zero commit interval",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			groupID: "one",
+			options: []Option{
+				WithCommitInterval(0),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
+		{
+			name:    "zero commit batch size",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			groupID: "one",
+			options: []Option{
+				WithCommitBatchSize(0),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
 		{
 			name:    "empty topic rejected even with injected reader",
 			brokers: []string{"url1:9092"},
@@ -16,6 +16,10 @@ var (
 	// ErrNilDecodeFunc is returned by NewConsumer when the message decode function is nil.
 	ErrNilDecodeFunc = errors.New("kafka: nil message decode function")
 
+	// ErrInvalidMessage is returned by SendMessage and SendBatch when a
+	// message has a negative explicit partition.
+	ErrInvalidMessage = errors.New("kafka: invalid message")
+
 	// ErrConsumerClosed is returned by Receive, FetchMessage, and ReceiveData
 	// after the consumer has been closed with Close.
 	ErrConsumerClosed = errors.New("kafka: consumer closed")
@@ -6,16 +6,31 @@ Built on github.com/segmentio/kafka-go, it exposes a producer/consumer
 interface:
 
   - [NewProducer] + [Producer.Send] / [Producer.SendData]
+  - [NewProducer] + [Producer.SendMessage] / [Producer.SendBatch]
   - [NewConsumer] + [Consumer.Receive] / [Consumer.ReceiveData]
   - [NewConsumer] + [Consumer.FetchMessage] / [Consumer.CommitMessages]
+  - [NewConsumer] + [Consumer.Run]
 
 Functional options tune the session timeout, start offset, required acks,
-batching, and custom codecs.
+batching, compression, and custom codecs.
+
+# Producing Messages
+
+[Producer.SendMessage] and [Producer.SendBatch] publish [Message] values with
+a key, headers, and an optional explicit partition; [Producer.DataMessage]
+encodes a payload into a Message. The writes are batched per partition up to
+[WithBatchSize] messages or [WithBatchBytes] bytes, waiting at most
+[WithBatchTimeout] (the linger time) for a batch to fill, and the batches are
+compressed with the [WithCompression] codec.
+
+By default the send methods wait for the brokers. With [WithAsync] they return
+once the messages are queued: the outcome of each write is reported to the
+[WithDeliveryReport] function and the failures on [Producer.Errors].
 
 # Delivery Semantics
 
-Producer writes wait for broker acknowledgment from the full in-sync replica
-set by default (kafka.RequireAll); tune this with [WithRequiredAcks].
+Producer writes are acknowledged by the full in-sync replica set by default
+(kafka.RequireAll); tune this with [WithRequiredAcks].
 
 On the consumer side, when a consumer group is configured:
 
@@ -27,6 +42,14 @@ On the consumer side, when a consumer group is configured:
     offset is committed only when the caller explicitly acknowledges the
     message after successful processing.
 
+[Consumer.Run] is a managed at-least-once loop over FetchMessage and
+CommitMessages: it processes the messages with a [HandlerFunc] on up to
+[WithConcurrency] workers, preserving the order within each partition, and
+commits the offsets in batches after the processing. Failing messages are
+retried with the [WithRetrier] retrier and then routed to the
+[WithDeadLetterProducer] topic, and the loop stops when the
+[WithShutdownSignalChan] channel (e.g. the bootstrap one) is closed.
+
 When no consumer group is configured (empty groupID), offsets are never
 committed: [Consumer.Receive] and [Consumer.FetchMessage] behave identically,
 reading always starts from the earliest available offset, and
@@ -41,13 +64,17 @@ Typed payload methods use configurable codec hooks:
 
 Both defaults use github.com/tecnickcom/nurago/pkg/encode. Replace them via
 [WithMessageEncodeFunc] and [WithMessageDecodeFunc] to add custom wire formats,
-encryption, compression, or schema validation.
+encryption, compression, or schema validation. The
+github.com/tecnickcom/nurago/pkg/codec package provides JSON, Protobuf and Avro
+codecs with schema-registry support, readable by non-Go consumers: pass the
+Encode and Decode methods of a codec to these options.
 
 # Errors
 
 Configuration problems are reported at construction time with errors matching
 the exported sentinels [ErrInvalidOptions], [ErrNilEncodeFunc], and
-[ErrNilDecodeFunc]. After [Consumer.Close], the receive methods return errors
+[ErrNilDecodeFunc]; an invalid message is rejected with an error matching
+[ErrInvalidMessage]. After [Consumer.Close], the receive methods return errors
 matching [ErrConsumerClosed]. Match the sentinels with errors.Is.
 */
 package kafka
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/tecnickcom/nurago/pkg/retrier"
 )
 
 // Option configures Kafka producer/consumer behavior.
@@ -104,6 +105,143 @@ func WithBatchTimeout(t time.Duration) Option {
 	}
 }
 
+// WithBatchBytes sets the maximum size in bytes of a message batch sent to
+// the brokers. If not set (or set to 0), the kafka-go library default (1MB) is
+// used; a larger message is rejected by the write. Negative values are
+// rejected by NewProducer with an error matching ErrInvalidOptions.
+//
+// This option is producer-only and has no effect on a Consumer; passing it to
+// NewConsumer is silently ignored.
+func WithBatchBytes(size int64) Option {
+	return func(c *config) {
+		c.batchBytes = size
+	}
+}
+
+// WithCompression sets the codec compressing the message batches:
+// kafka.Gzip, kafka.Snappy, kafka.Lz4 or kafka.Zstd. The batches are not
+// compressed by default. Any other non-zero value is rejected by NewProducer
+// with an error matching ErrInvalidOptions.
+//
+// This option is producer-only and has no effect on a Consumer; passing it to
+// NewConsumer is silently ignored.
+func WithCompression(codec kafka.Compression) Option {
+	return func(c *config) {
+		c.compression = codec
+	}
+}
+
+// WithAsync enables the async mode of the Producer: the send methods return
+// as soon as the messages are queued, without waiting for the brokers, so
+// they only fail on invalid or encoding errors. The outcome of the writes is
+// reported to the WithDeliveryReport function, and the failures on the
+// Producer.Errors() channel as *DeliveryError values. errorsBuffer is the
+// capacity of that channel (0 means 256); the errors are dropped when it is
+// full. Close flushes the queued messages.
+//
+// This option is producer-only and has no effect on a Consumer; passing it to
+// NewConsumer is silently ignored.
+func WithAsync(errorsBuffer int) Option {
+	return func(c *config) {
+		c.async = true
+
+		if errorsBuffer != 0 {
+			c.errorsBuffer = errorsBuffer
+		}
+	}
+}
+
+// WithDeliveryReport sets a function called with the outcome of each batch of
+// messages written to a partition, in both the sync and async modes. It is
+// called from the writer goroutines, so it must be safe for concurrent use
+// and should return quickly: Close waits for the pending calls.
+//
+// This option is producer-only and has no effect on a Consumer; passing it to
+// NewConsumer is silently ignored.
+func WithDeliveryReport(fn DeliveryReportFunc) Option {
+	return func(c *config) {
+		c.deliveryReportFn = fn
+	}
+}
+
+// WithConcurrency sets the number of workers processing the messages in
+// Consumer.Run. The messages of a partition are always processed in order by
+// the same worker, so the effective parallelism is also bounded by the number
+// of assigned partitions. Defaults to 1; values below 1 are rejected by
+// NewConsumer with an error matching ErrInvalidOptions.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// WithCommitInterval sets the maximum time Consumer.Run waits before
+// committing the offsets of the processed messages. Defaults to 1 second;
+// non-positive values are rejected by NewConsumer with an error matching
+// ErrInvalidOptions.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithCommitInterval(t time.Duration) Option {
+	return func(c *config) {
+		c.commitInterval = t
+	}
+}
+
+// WithCommitBatchSize sets the number of processed messages after which
+// Consumer.Run commits their offsets without waiting for the commit interval.
+// Defaults to 100; values below 1 are rejected by NewConsumer with an error
+// matching ErrInvalidOptions.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithCommitBatchSize(size int) Option {
+	return func(c *config) {
+		c.commitBatchSize = size
+	}
+}
+
+// WithRetrier sets the retrier running the Consumer.Run handler: a failing
+// message is retried according to its attempts, delays and retry predicate
+// before being considered poison. By default the handler runs once.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithRetrier(r *retrier.Retrier) Option {
+	return func(c *config) {
+		c.retrier = r
+	}
+}
+
+// WithDeadLetterProducer sets the producer of the dead-letter topic: the poison
+// messages of Consumer.Run, whose handler still fails after the retries, are
+// published to it with the HeaderDeadLetter* headers and then acknowledged. A
+// synchronous producer is recommended, as an async one acknowledges the
+// messages before their delivery. By default a poison message stops Run.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithDeadLetterProducer(p *Producer) Option {
+	return func(c *config) {
+		c.deadLetter = p
+	}
+}
+
+// WithShutdownSignalChan sets the shared channel used to signal a shutdown,
+// such as the one passed to bootstrap.WithShutdownSignalChan: Consumer.Run
+// stops when the channel is closed.
+//
+// This option is consumer-only and has no effect on a Producer; passing it to
+// NewProducer is silently ignored.
+func WithShutdownSignalChan(ch chan struct{}) Option {
+	return func(c *config) {
+		c.shutdownSignalCh = ch
+	}
+}
+
 // WithMessageEncodeFunc overrides DefaultMessageEncodeFunc for SendData() serialization.
 func WithMessageEncodeFunc(f TEncodeFunc) Option {
 	return func(c *config) {
@@ -141,8 +279,10 @@ func WithKafkaReader(r KReader) Option {
 // implementation), primarily for testing.
 //
 // When a writer is injected, NewProducer does not construct a kafka.Writer:
-// the balancer, required acks, and batch settings have no effect (their
-// values are still validated). The brokers and topic arguments remain
+// the balancer, required acks, batch, compression, and async settings have no
+// effect (their values are still validated), nor the explicit message
+// partitions and the delivery report function. The Errors() channel of the
+// async mode is still created, but nothing is reported on it. The brokers and topic arguments remain
 // required (and validated) because HealthCheck probes them and the topic
 // appears in encode error messages; injecting a writer does NOT mock
 // HealthCheck, which still performs real network I/O unless WithBrokerCheckFunc
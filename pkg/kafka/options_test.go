@@ -7,6 +7,7 @@ import (
 
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/retrier"
 )
 
 func Test_WithSessionTimeout(t *testing.T) {
@@ -66,6 +67,102 @@ func Test_WithBatchTimeout(t *testing.T) {
 	require.Equal(t, v, cfg.batchTimeout)
 }
 
+func Test_WithBatchBytes(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithBatchBytes(2048)(cfg)
+	require.Equal(t, int64(2048), cfg.batchBytes)
+}
+
+func Test_WithCompression(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithCompression(kafka.Snappy)(cfg)
+	require.Equal(t, kafka.Snappy, cfg.compression)
+}
+
+func Test_WithAsync(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	WithAsync(0)(cfg)
+	require.True(t, cfg.async)
+	require.Equal(t, defaultErrorsBuffer, cfg.errorsBuffer)
+
+	WithAsync(7)(cfg)
+	require.Equal(t, 7, cfg.errorsBuffer)
+}
+
+func Test_WithDeliveryReport(t *testing.T) {
+	t.Parallel()
+
+	called := false
+
+	cfg := &config{}
+	WithDeliveryReport(func(_ []kafka.Message, _ error) { called = true })(cfg)
+	require.NotNil(t, cfg.deliveryReportFn)
+
+	cfg.deliveryReportFn(nil, nil)
+	require.True(t, called)
+}
+
+func Test_WithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithConcurrency(4)(cfg)
+	require.Equal(t, 4, cfg.concurrency)
+}
+
+func Test_WithCommitInterval(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithCommitInterval(time.Millisecond * 250)(cfg)
+	require.Equal(t, time.Millisecond*250, cfg.commitInterval)
+}
+
+func Test_WithCommitBatchSize(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{}
+	WithCommitBatchSize(10)(cfg)
+	require.Equal(t, 10, cfg.commitBatchSize)
+}
+
+func Test_WithRetrier(t *testing.T) {
+	t.Parallel()
+
+	r, err := retrier.New()
+	require.NoError(t, err)
+
+	cfg := &config{}
+	WithRetrier(r)(cfg)
+	require.Same(t, r, cfg.retrier)
+}
+
+func Test_WithDeadLetterProducer(t *testing.T) {
+	t.Parallel()
+
+	p := &Producer{}
+
+	cfg := &config{}
+	WithDeadLetterProducer(p)(cfg)
+	require.Same(t, p, cfg.deadLetter)
+}
+
+func Test_WithShutdownSignalChan(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan struct{})
+
+	cfg := &config{}
+	WithShutdownSignalChan(ch)(cfg)
+	require.Equal(t, ch, cfg.shutdownSignalCh)
+}
+
 func Test_WithMessageEncodeFunc(t *testing.T) {
 	t.Parallel()
 
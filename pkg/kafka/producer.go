@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/tecnickcom/nurago/pkg/encode"
@@ -18,13 +19,59 @@ type KWriter interface {
 	Close() error
 }
 
+// Message is a Kafka message to publish with [Producer.SendMessage] or
+// [Producer.SendBatch].
+type Message struct {
+	// Key is the message key. The default kafka.Hash balancer assigns the
+	// messages with the same key to the same partition.
+	Key []byte
+
+	// Value is the message payload.
+	Value []byte
+
+	// Headers are the message headers.
+	Headers []kafka.Header
+
+	// Partition is the explicit target partition, bypassing the balancer. It
+	// must exist in the topic and cannot be negative. Nil lets the balancer
+	// choose the partition.
+	Partition *int
+}
+
+// DeliveryReportFunc is called with the outcome of each batch of messages
+// written to a partition: err is nil when the brokers acknowledged the
+// messages, which carry the topic, partition, offset and time of the write.
+type DeliveryReportFunc func(msgs []kafka.Message, err error)
+
+// DeliveryError is the error reported on [Producer.Errors] when an
+// asynchronous write fails.
+type DeliveryError struct {
+	// Messages are the messages that were not delivered.
+	Messages []kafka.Message
+
+	// Err is the write error.
+	Err error
+}
+
+// Error returns the write error message with the number of failed messages.
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("cannot deliver %d message(s) to Kafka: %v", len(e.Messages), e.Err)
+}
+
+// Unwrap returns the write error.
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
 // Producer publishes messages to a Kafka topic with pluggable encoding.
 type Producer struct {
-	cfg     *config
-	client  KWriter
-	checkFn checkBrokerFn
-	brokers []string
-	topic   string
+	cfg       *config
+	client    KWriter
+	checkFn   checkBrokerFn
+	brokers   []string
+	topic     string
+	errors    chan error
+	closeOnce sync.Once
 }
 
 // NewProducer constructs a Kafka producer for a topic with optional tuning (encoding, balancing).
@@ -40,13 +87,19 @@ type Producer struct {
 // partition; use WithBalancer to choose a different strategy (for example a round-robin
 // balancer) to spread keyless messages across partitions.
 //
+// Messages with an explicit Partition (see [Message]) bypass the balancer.
+//
 // Broker acknowledgment defaults to kafka.RequireAll, so Send()/SendData() return an error
 // when the write is not acknowledged by the full in-sync replica set; use WithRequiredAcks
 // to trade durability for throughput.
 //
 // The batch flush timeout defaults to 10ms (instead of the kafka-go 1s default) to keep the
 // latency of synchronous per-message Send() calls low; use WithBatchTimeout and
-// WithBatchSize to tune batching behavior.
+// WithBatchSize to tune batching behavior, and WithCompression to compress the
+// batches.
+//
+// With WithAsync the writes do not wait for the brokers: the outcome is
+// reported to the WithDeliveryReport function and the failures on Errors().
 //
 // The consumer-only options WithSessionTimeout and WithFirstOffset are accepted for API
 // compatibility on the shared Option type but have no effect on a Producer.
@@ -62,37 +115,56 @@ func NewProducer(brokers []string, topic string, opts ...Option) (*Producer, err
 		return nil, err
 	}
 
-	client := cfg.writer
-
-	if client == nil {
-		client = &kafka.Writer{
-			Addr:         kafka.TCP(brokers...),
-			Topic:        topic,
-			Balancer:     cfg.balancer,
-			RequiredAcks: cfg.requiredAcks,
-			BatchSize:    cfg.batchSize,
-			BatchTimeout: cfg.batchTimeout,
-		}
-	}
-
 	checkFn := cfg.checkFn
 	if checkFn == nil {
 		checkFn = defaultCheckBroker(topic)
 	}
 
-	return &Producer{
+	p := &Producer{
 		cfg:     cfg,
-		client:  client,
+		client:  cfg.writer,
 		checkFn: checkFn,
 		brokers: slices.Clone(brokers),
 		topic:   topic,
-	}, nil
+	}
+
+	if cfg.async {
+		p.errors = make(chan error, cfg.errorsBuffer)
+	}
+
+	if p.client == nil {
+		balancer := cfg.balancer
+		if balancer == nil {
+			balancer = &kafka.RoundRobin{} // the kafka.Writer default
+		}
+
+		p.client = &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &partitionBalancer{Balancer: balancer},
+			RequiredAcks: cfg.requiredAcks,
+			BatchSize:    cfg.batchSize,
+			BatchBytes:   cfg.batchBytes,
+			BatchTimeout: cfg.batchTimeout,
+			Compression:  cfg.compression,
+			Async:        cfg.async,
+			Completion:   p.complete,
+		}
+	}
+
+	return p, nil
 }
 
-// Close releases Producer's resources and closes the broker connection.
-// It is safe to call multiple times.
+// Close flushes the pending messages, releases Producer's resources and
+// closes the broker connection. In async mode it then closes the Errors()
+// channel. It is safe to call multiple times.
 func (p *Producer) Close() error {
 	err := p.client.Close()
+
+	if p.errors != nil {
+		p.closeOnce.Do(func() { close(p.errors) })
+	}
+
 	if err != nil {
 		return fmt.Errorf("cannot close the Kafka producer: %w", err)
 	}
@@ -100,14 +172,29 @@ func (p *Producer) Close() error {
 	return nil
 }
 
+// Errors returns the channel reporting the failed writes of the async mode
+// (see WithAsync) as *DeliveryError values. The channel is buffered: the
+// errors are dropped when it is full, so drain it or rely on the
+// WithDeliveryReport function instead. It is closed by Close, and it is nil
+// when the async mode is disabled.
+func (p *Producer) Errors() <-chan error {
+	return p.errors
+}
+
 // Send publishes a raw byte message to the Kafka topic.
 func (p *Producer) Send(ctx context.Context, msg []byte) error {
-	err := p.client.WriteMessages(
-		ctx,
-		kafka.Message{
-			Value: msg,
-		},
-	)
+	return p.SendMessage(ctx, Message{Value: msg})
+}
+
+// SendMessage publishes a message with its key, headers and optional explicit
+// partition to the Kafka topic.
+func (p *Producer) SendMessage(ctx context.Context, msg Message) error {
+	kmsg, err := msg.kafkaMessage()
+	if err != nil {
+		return err
+	}
+
+	err = p.client.WriteMessages(ctx, kmsg)
 	if err != nil {
 		return fmt.Errorf("cannot send a message to Kafka: %w", err)
 	}
@@ -115,6 +202,34 @@ func (p *Producer) Send(ctx context.Context, msg []byte) error {
 	return nil
 }
 
+// SendBatch publishes the messages to the Kafka topic with a single write,
+// batched per partition. In sync mode a failure is a kafka.WriteErrors
+// (matched with errors.As) carrying the error of each message, nil for the
+// delivered ones.
+func (p *Producer) SendBatch(ctx context.Context, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	kmsgs := make([]kafka.Message, len(msgs))
+
+	for i := range msgs {
+		kmsg, err := msgs[i].kafkaMessage()
+		if err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+
+		kmsgs[i] = kmsg
+	}
+
+	err := p.client.WriteMessages(ctx, kmsgs...)
+	if err != nil {
+		return fmt.Errorf("cannot send %d messages to Kafka: %w", len(msgs), err)
+	}
+
+	return nil
+}
+
 // HealthCheck verifies broker reachability by probing each configured broker
 // until one succeeds. When every broker fails, the individual probe errors
 // are joined into the returned error.
@@ -135,10 +250,80 @@ func DefaultMessageEncodeFunc(_ context.Context, data any) ([]byte, error) {
 
 // SendData encodes the data argument and publishes the result to the Kafka topic using the configured encoder.
 func (p *Producer) SendData(ctx context.Context, data any) error {
-	message, err := p.cfg.messageEncodeFunc(ctx, data)
+	msg, err := p.DataMessage(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	return p.SendMessage(ctx, msg)
+}
+
+// DataMessage returns a Message whose Value is the data argument encoded with
+// the configured encoder, so the key, headers and partition can be set before
+// sending it with SendMessage or SendBatch.
+func (p *Producer) DataMessage(ctx context.Context, data any) (Message, error) {
+	value, err := p.cfg.messageEncodeFunc(ctx, data)
 	if err != nil {
-		return fmt.Errorf("cannot encode message data for topic %s: %w", p.topic, err)
+		return Message{}, fmt.Errorf("cannot encode message data for topic %s: %w", p.topic, err)
+	}
+
+	return Message{Value: value}, nil
+}
+
+// complete is the kafka.Writer completion function: it calls the delivery
+// report function and, in async mode, reports the failures on Errors().
+func (p *Producer) complete(msgs []kafka.Message, err error) {
+	if p.cfg.deliveryReportFn != nil {
+		p.cfg.deliveryReportFn(msgs, err)
+	}
+
+	if err == nil || p.errors == nil {
+		return
+	}
+
+	select {
+	case p.errors <- &DeliveryError{Messages: msgs, Err: err}:
+	default: // the buffer is full: the error is dropped
+	}
+}
+
+// kafkaMessage returns the kafka-go message of m. The partition of a message
+// without an explicit one is set to anyPartition for partitionBalancer.
+func (m *Message) kafkaMessage() (kafka.Message, error) {
+	partition := anyPartition
+
+	if m.Partition != nil {
+		partition = *m.Partition
+
+		if partition < 0 {
+			return kafka.Message{}, fmt.Errorf("negative partition (%d): %w", partition, ErrInvalidMessage)
+		}
+	}
+
+	return kafka.Message{
+		Key:       m.Key,
+		Value:     m.Value,
+		Headers:   m.Headers,
+		Partition: partition,
+	}, nil
+}
+
+// anyPartition is the kafka.Message partition of the messages assigned by the
+// balancer. The kafka.Writer ignores the partition of the written messages.
+const anyPartition = -1
+
+// partitionBalancer is a kafka.Balancer honoring the explicit message
+// partitions, and delegating the other messages to the wrapped balancer.
+type partitionBalancer struct {
+	kafka.Balancer
+}
+
+// Balance returns the explicit partition of msg, if any, or the one chosen by
+// the wrapped balancer.
+func (b *partitionBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	if msg.Partition != anyPartition {
+		return msg.Partition
 	}
 
-	return p.Send(ctx, message)
+	return b.Balancer.Balance(msg, partitions...)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -51,6 +52,9 @@ func Test_NewProducer(t *testing.T) {
 		expRequiredAcks kafka.RequiredAcks
 		expBatchSize    int
 		expBatchTimeout time.Duration
+		expBatchBytes   int64
+		expCompression  kafka.Compression
+		expAsync        bool
 		injected        bool
 		wantErrIs       error
 	}{
@@ -78,6 +82,22 @@ func Test_NewProducer(t *testing.T) {
 			expBatchSize:    53,
 			expBatchTimeout: time.Millisecond * 21,
 		},
+		{
+			name:    "success with compression and async options",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			options: []Option{
+				WithBatchBytes(4096),
+				WithCompression(kafka.Zstd),
+				WithAsync(0),
+				WithBalancer(nil),
+			},
+			expRequiredAcks: kafka.RequireAll,
+			expBatchTimeout: defaultBatchTimeout,
+			expBatchBytes:   4096,
+			expCompression:  kafka.Zstd,
+			expAsync:        true,
+		},
 		{
 			name:    "success with injected writer",
 			brokers: []string{"url1:9092"},
@@ -141,6 +161,33 @@ func Test_NewProducer(t *testing.T) {
 			},
 			wantErrIs: ErrInvalidOptions,
 		},
+		{
+			name:    "negative batch bytes",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			options: []Option{
+				WithBatchBytes(-1),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
+		{
+			name:    "invalid compression",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			options: []Option{
+				WithCompression(kafka.Compression(9)),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
+		{
+			name:    "negative errors buffer",
+			brokers: []string{"url1:9092"},
+			topic:   "topic1",
+			options: []Option{
+				WithAsync(-1),
+			},
+			wantErrIs: ErrInvalidOptions,
+		},
 		{
 			name:    "invalid required acks",
 			brokers: []string{"url1:9092"},
@@ -174,6 +221,12 @@ func Test_NewProducer(t *testing.T) {
 				require.Equal(t, tt.expRequiredAcks, writer.RequiredAcks)
 				require.Equal(t, tt.expBatchSize, writer.BatchSize)
 				require.Equal(t, tt.expBatchTimeout, writer.BatchTimeout)
+				require.Equal(t, tt.expBatchBytes, writer.BatchBytes)
+				require.Equal(t, tt.expCompression, writer.Compression)
+				require.Equal(t, tt.expAsync, writer.Async)
+				require.Equal(t, tt.expAsync, producer.Errors() != nil)
+				require.IsType(t, &partitionBalancer{}, writer.Balancer)
+				require.NotNil(t, writer.Completion)
 			}
 
 			require.NoError(t, producer.Close())
@@ -301,3 +354,201 @@ func Test_Producer_Close_idempotent(t *testing.T) {
 	require.NoError(t, producer.Close())
 	require.Equal(t, 2, calls)
 }
+
+func TestSendMessage(t *testing.T) {
+	t.Parallel()
+
+	var got []kafka.Message
+
+	producer := newTestProducer(t, produceMock{
+		writeMessages: func(_ context.Context, msgs ...kafka.Message) error {
+			got = msgs
+
+			return nil
+		},
+	})
+
+	headers := []kafka.Header{{Key: "h", Value: []byte("v")}}
+
+	err := producer.SendMessage(t.Context(), Message{Key: []byte("k"), Value: []byte("v"), Headers: headers})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, []byte("k"), got[0].Key)
+	require.Equal(t, []byte("v"), got[0].Value)
+	require.Equal(t, headers, got[0].Headers)
+	require.Equal(t, anyPartition, got[0].Partition)
+
+	err = producer.SendMessage(t.Context(), Message{Value: []byte("v"), Partition: new(3)})
+	require.NoError(t, err)
+	require.Equal(t, 3, got[0].Partition)
+
+	err = producer.SendMessage(t.Context(), Message{Value: []byte("v"), Partition: new(-1)})
+	require.ErrorIs(t, err, ErrInvalidMessage)
+
+	producer = newTestProducer(t, produceMock{
+		writeMessages: func(_ context.Context, _ ...kafka.Message) error { return errors.New("error WriteMessages") },
+	})
+	err = producer.SendMessage(t.Context(), Message{Value: []byte("v")})
+	require.ErrorContains(t, err, "cannot send a message to Kafka")
+}
+
+func TestSendBatch(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+
+	var got []kafka.Message
+
+	producer := newTestProducer(t, produceMock{
+		writeMessages: func(_ context.Context, msgs ...kafka.Message) error {
+			calls++
+			got = msgs
+
+			return nil
+		},
+	})
+
+	require.NoError(t, producer.SendBatch(t.Context(), nil))
+	require.Zero(t, calls)
+
+	err := producer.SendBatch(t.Context(), []Message{
+		{Key: []byte("a"), Value: []byte("1")},
+		{Key: []byte("b"), Value: []byte("2"), Partition: new(0)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Len(t, got, 2)
+	require.Equal(t, anyPartition, got[0].Partition)
+	require.Equal(t, 0, got[1].Partition)
+
+	err = producer.SendBatch(t.Context(), []Message{{Value: []byte("1")}, {Partition: new(-2)}})
+	require.ErrorIs(t, err, ErrInvalidMessage)
+	require.ErrorContains(t, err, "message 1")
+	require.Equal(t, 1, calls)
+
+	werr := kafka.WriteErrors{nil, errors.New("failed")}
+
+	producer = newTestProducer(t, produceMock{
+		writeMessages: func(_ context.Context, _ ...kafka.Message) error { return werr },
+	})
+	err = producer.SendBatch(t.Context(), []Message{{Value: []byte("1")}, {Value: []byte("2")}})
+	require.ErrorContains(t, err, "cannot send 2 messages to Kafka")
+
+	var target kafka.WriteErrors
+
+	require.ErrorAs(t, err, &target)
+	require.Equal(t, 1, target.Count())
+}
+
+func TestDataMessage(t *testing.T) {
+	t.Parallel()
+
+	producer, err := NewProducer(
+		[]string{"url1:9092"},
+		"topic1",
+		WithKafkaWriter(produceMock{}),
+		WithMessageEncodeFunc(func(_ context.Context, data any) ([]byte, error) {
+			s, ok := data.(string)
+			if !ok {
+				return nil, errors.New("not a string")
+			}
+
+			return []byte(s), nil
+		}),
+	)
+	require.NoError(t, err)
+
+	msg, err := producer.DataMessage(t.Context(), "payload")
+	require.NoError(t, err)
+	require.Equal(t, Message{Value: []byte("payload")}, msg)
+
+	_, err = producer.DataMessage(t.Context(), 1)
+	require.ErrorContains(t, err, "cannot encode message data for topic topic1")
+}
+
+func Test_partitionBalancer(t *testing.T) {
+	t.Parallel()
+
+	b := &partitionBalancer{Balancer: kafka.BalancerFunc(func(_ kafka.Message, partitions ...int) int {
+		return partitions[len(partitions)-1]
+	})}
+
+	require.Equal(t, 2, b.Balance(kafka.Message{Partition: anyPartition}, 0, 1, 2))
+	require.Equal(t, 0, b.Balance(kafka.Message{Partition: 0}, 0, 1, 2))
+	require.Equal(t, 1, b.Balance(kafka.Message{Partition: 1}, 0, 1, 2))
+}
+
+func Test_Producer_async(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		reports []error
+	)
+
+	producer, err := NewProducer(
+		[]string{"url1:9092"},
+		"topic1",
+		WithAsync(1),
+		WithDeliveryReport(func(_ []kafka.Message, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			reports = append(reports, err)
+		}),
+	)
+	require.NoError(t, err)
+
+	writer, ok := producer.client.(*kafka.Writer)
+	require.True(t, ok)
+
+	msgs := []kafka.Message{{Value: []byte("1")}}
+	errWrite := errors.New("write error")
+
+	writer.Completion(msgs, nil)
+	writer.Completion(msgs, errWrite)
+	writer.Completion(msgs, errWrite) // dropped: the errors buffer is full
+
+	require.Equal(t, []error{nil, errWrite, errWrite}, reports)
+
+	require.NoError(t, producer.Close())
+	require.NoError(t, producer.Close())
+
+	var errs []error
+
+	for err := range producer.Errors() {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], errWrite)
+
+	var derr *DeliveryError
+
+	require.ErrorAs(t, errs[0], &derr)
+	require.Equal(t, msgs, derr.Messages)
+	require.EqualError(t, derr, "cannot deliver 1 message(s) to Kafka: write error")
+}
+
+func Test_Producer_sync_deliveryReport(t *testing.T) {
+	t.Parallel()
+
+	called := false
+
+	producer, err := NewProducer(
+		[]string{"url1:9092"},
+		"topic1",
+		WithDeliveryReport(func(_ []kafka.Message, _ error) { called = true }),
+	)
+	require.NoError(t, err)
+	require.Nil(t, producer.Errors())
+
+	writer, ok := producer.client.(*kafka.Writer)
+	require.True(t, ok)
+
+	// without an Errors() channel, a failed write is only reported.
+	writer.Completion(nil, errors.New("write error"))
+	require.True(t, called)
+
+	require.NoError(t, producer.Close())
+}
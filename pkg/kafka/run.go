@@ -0,0 +1,292 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers of the messages routed to the dead-letter topic by [Consumer.Run].
+const (
+	// HeaderDeadLetterError carries the processing error message.
+	HeaderDeadLetterError = "x-dead-letter-error"
+
+	// HeaderDeadLetterTopic carries the topic of the original message.
+	HeaderDeadLetterTopic = "x-dead-letter-topic"
+
+	// HeaderDeadLetterPartition carries the partition of the original message.
+	HeaderDeadLetterPartition = "x-dead-letter-partition"
+
+	// HeaderDeadLetterOffset carries the offset of the original message.
+	HeaderDeadLetterOffset = "x-dead-letter-offset"
+)
+
+// runQueueSize is the number of fetched messages buffered for each Run worker.
+const runQueueSize = 64
+
+// HandlerFunc processes a message fetched by [Consumer.Run]. Returning nil
+// acknowledges the message, so its offset can be committed.
+type HandlerFunc func(ctx context.Context, msg kafka.Message) error
+
+// Run fetches the messages and processes them with handler until ctx ends,
+// the WithShutdownSignalChan channel is closed, or an unrecoverable error
+// occurs.
+//
+// Delivery semantics: at-least-once. The messages of a partition are
+// processed in order, one at a time, by the same worker; up to
+// WithConcurrency workers run in parallel. The offsets are committed only
+// after the messages are processed, in batches (see WithCommitInterval and
+// WithCommitBatchSize), and once more before returning. A failing handler is
+// retried with the WithRetrier retrier; when it still fails, the message is
+// routed to the WithDeadLetterProducer topic with the HeaderDeadLetter*
+// headers and acknowledged, or, without a dead-letter producer, Run stops
+// and returns the error. Without a consumer group no offset is committed.
+//
+// On a stop, the handler context is canceled and the messages whose
+// processing did not complete are left uncommitted, so they are redelivered.
+// Run returns nil after a stop by ctx or by the shutdown signal, otherwise
+// the first error, for example one matching ErrConsumerClosed when the
+// Consumer is closed while running. Run must not be called concurrently with
+// the other receive methods.
+func (c *Consumer) Run(ctx context.Context, handler HandlerFunc) error {
+	commitCtx := context.WithoutCancel(ctx)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.cfg.shutdownSignalCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	r := &runner{
+		c:       c,
+		handler: handler,
+		cancel:  cancel,
+		commits: make(chan kafka.Message, runQueueSize),
+	}
+
+	return r.run(ctx, commitCtx)
+}
+
+// runner is the state of a Consumer.Run call.
+type runner struct {
+	c       *Consumer
+	handler HandlerFunc
+	cancel  context.CancelFunc
+	commits chan kafka.Message
+
+	mu  sync.Mutex
+	err error
+}
+
+// run starts the workers and the committer, and dispatches the fetched
+// messages until ctx ends.
+func (r *runner) run(ctx, commitCtx context.Context) error {
+	queues := make([]chan kafka.Message, r.c.cfg.concurrency)
+
+	var workers sync.WaitGroup
+
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, runQueueSize)
+
+		workers.Go(func() { r.work(ctx, queues[i]) })
+	}
+
+	committed := make(chan struct{})
+
+	go func() {
+		defer close(committed)
+
+		r.commit(commitCtx)
+	}()
+
+	r.dispatch(ctx, queues)
+
+	for _, q := range queues {
+		close(q)
+	}
+
+	workers.Wait()
+	close(r.commits)
+	<-committed
+
+	return r.err
+}
+
+// dispatch fetches the messages and queues each one to the worker of its
+// partition, until ctx ends or the fetch fails.
+func (r *runner) dispatch(ctx context.Context, queues []chan kafka.Message) {
+	for {
+		msg, err := r.c.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.fail(err)
+			}
+
+			return
+		}
+
+		select {
+		case queues[msg.Partition%len(queues)] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work processes the queued messages in order and sends the processed ones to
+// the committer. After a stop the remaining messages are skipped.
+func (r *runner) work(ctx context.Context, queue <-chan kafka.Message) {
+	for msg := range queue {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		err := r.process(ctx, msg)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.fail(err)
+			}
+
+			continue
+		}
+
+		r.commits <- msg
+	}
+}
+
+// process runs the handler with the configured retrier, and routes the message
+// to the dead-letter topic when it still fails.
+func (r *runner) process(ctx context.Context, msg kafka.Message) error {
+	err := r.handle(ctx, msg)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	if r.c.cfg.deadLetter == nil {
+		return fmt.Errorf("cannot process the Kafka message (partition %d, offset %d): %w", msg.Partition, msg.Offset, err)
+	}
+
+	err = r.c.cfg.deadLetter.SendMessage(ctx, deadLetterMessage(msg, err))
+	if err != nil {
+		return fmt.Errorf("cannot route the Kafka message (partition %d, offset %d) to the dead-letter topic: %w", msg.Partition, msg.Offset, err)
+	}
+
+	return nil
+}
+
+// handle runs the handler, retried by the configured retrier, if any.
+func (r *runner) handle(ctx context.Context, msg kafka.Message) error {
+	if r.c.cfg.retrier == nil {
+		return r.handler(ctx, msg)
+	}
+
+	return r.c.cfg.retrier.Run(ctx, func(ctx context.Context) error { //nolint:wrapcheck
+		return r.handler(ctx, msg)
+	})
+}
+
+// commit commits the offsets of the processed messages received from
+// r.commits in batches, and the remaining ones when the channel is closed.
+// After a commit error the messages are drained without committing.
+func (r *runner) commit(ctx context.Context) {
+	ticker := time.NewTicker(r.c.cfg.commitInterval)
+	defer ticker.Stop()
+
+	pending := make(map[int]kafka.Message)
+	count := 0
+	broken := r.c.groupID == "" // the offsets cannot be committed
+
+	flush := func() {
+		if count == 0 {
+			return
+		}
+
+		err := r.c.commitLatest(ctx, pending)
+		if err != nil {
+			broken = true
+
+			r.fail(err)
+		}
+
+		clear(pending)
+
+		count = 0
+	}
+
+	for {
+		select {
+		case msg, ok := <-r.commits:
+			if !ok {
+				flush()
+				return
+			}
+
+			if broken {
+				continue
+			}
+
+			pending[msg.Partition] = msg // the messages of a partition arrive in order
+			count++
+
+			if count >= r.c.cfg.commitBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// fail records the first error and stops the run.
+func (r *runner) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+
+	r.cancel()
+}
+
+// commitLatest commits the offsets of the latest processed message of each
+// partition, bounded by the session timeout.
+func (c *Consumer) commitLatest(ctx context.Context, latest map[int]kafka.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.sessionTimeout)
+	defer cancel()
+
+	msgs := make([]kafka.Message, 0, len(latest))
+	for _, msg := range latest {
+		msgs = append(msgs, msg)
+	}
+
+	return c.CommitMessages(ctx, msgs...)
+}
+
+// deadLetterMessage returns the message routed to the dead-letter topic for
+// msg: the same key, value and headers, plus the HeaderDeadLetter* headers.
+func deadLetterMessage(msg kafka.Message, err error) Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+4)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterError, Value: []byte(err.Error())},
+		kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+
+	return Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
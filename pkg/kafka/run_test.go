@@ -0,0 +1,345 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/retrier"
+)
+
+// runReader is a KReader serving msgs, then fetchErr if set, or blocking until
+// the context ends. It records the committed messages.
+type runReader struct {
+	mu        sync.Mutex
+	msgs      []kafka.Message
+	fetchErr  error
+	commitErr error
+	committed []kafka.Message
+}
+
+func (r *runReader) ReadMessage(_ context.Context) (kafka.Message, error) {
+	return kafka.Message{}, errors.New("unexpected ReadMessage")
+}
+
+func (r *runReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+
+	if len(r.msgs) > 0 {
+		msg := r.msgs[0]
+		r.msgs = r.msgs[1:]
+		r.mu.Unlock()
+
+		return msg, nil
+	}
+
+	r.mu.Unlock()
+
+	if r.fetchErr != nil {
+		return kafka.Message{}, r.fetchErr
+	}
+
+	<-ctx.Done()
+
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *runReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.commitErr != nil {
+		return r.commitErr
+	}
+
+	r.committed = append(r.committed, msgs...)
+
+	return nil
+}
+
+func (r *runReader) Close() error {
+	return nil
+}
+
+// latest returns the highest committed offset of each partition.
+func (r *runReader) latest() map[int]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := make(map[int]int64)
+
+	for _, msg := range r.committed {
+		if off, ok := latest[msg.Partition]; !ok || msg.Offset > off {
+			latest[msg.Partition] = msg.Offset
+		}
+	}
+
+	return latest
+}
+
+// testRunMessages returns n messages for each of the given partitions,
+// interleaved.
+func testRunMessages(n int, partitions ...int) []kafka.Message {
+	msgs := make([]kafka.Message, 0, n*len(partitions))
+
+	for off := range n {
+		for _, p := range partitions {
+			msgs = append(msgs, kafka.Message{Topic: "topic1", Partition: p, Offset: int64(off), Value: []byte{byte(off)}})
+		}
+	}
+
+	return msgs
+}
+
+func newRunConsumer(t *testing.T, reader *runReader, groupID string, opts ...Option) *Consumer {
+	t.Helper()
+
+	consumer, err := NewConsumer([]string{"url1:9092"}, "topic1", groupID, append(opts, WithKafkaReader(reader))...)
+	require.NoError(t, err)
+
+	return consumer
+}
+
+func TestConsumer_Run(t *testing.T) {
+	t.Parallel()
+
+	reader := &runReader{msgs: testRunMessages(5, 0, 1, 2)}
+	shutdown := make(chan struct{})
+
+	consumer := newRunConsumer(t, reader, "group1",
+		WithConcurrency(2),
+		WithCommitBatchSize(4),
+		WithShutdownSignalChan(shutdown),
+	)
+
+	var (
+		mu      sync.Mutex
+		handled = make(map[int][]int64)
+		total   int
+	)
+
+	err := consumer.Run(t.Context(), func(_ context.Context, msg kafka.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled[msg.Partition] = append(handled[msg.Partition], msg.Offset)
+
+		total++
+		if total == 15 {
+			close(shutdown)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	for p := range 3 {
+		require.Equal(t, []int64{0, 1, 2, 3, 4}, handled[p], "partition %d", p)
+	}
+
+	require.Equal(t, map[int]int64{0: 4, 1: 4, 2: 4}, reader.latest())
+}
+
+func TestConsumer_Run_commitInterval(t *testing.T) {
+	t.Parallel()
+
+	reader := &runReader{msgs: testRunMessages(2, 0)}
+
+	consumer := newRunConsumer(t, reader, "group1", WithCommitInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() {
+		done <- consumer.Run(ctx, func(_ context.Context, _ kafka.Message) error { return nil })
+	}()
+
+	require.Eventually(t, func() bool {
+		return reader.latest()[0] == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestConsumer_Run_retry(t *testing.T) {
+	t.Parallel()
+
+	r, err := retrier.New(retrier.WithAttempts(3), retrier.WithDelay(time.Millisecond))
+	require.NoError(t, err)
+
+	reader := &runReader{msgs: testRunMessages(1, 0)}
+	shutdown := make(chan struct{})
+
+	consumer := newRunConsumer(t, reader, "group1", WithRetrier(r), WithShutdownSignalChan(shutdown))
+
+	attempts := 0
+
+	err = consumer.Run(t.Context(), func(_ context.Context, _ kafka.Message) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+
+		close(shutdown)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, map[int]int64{0: 0}, reader.latest())
+}
+
+func TestConsumer_Run_deadLetter(t *testing.T) {
+	t.Parallel()
+
+	var dlq []kafka.Message
+
+	dlqProducer := newTestProducer(t, produceMock{
+		writeMessages: func(_ context.Context, msgs ...kafka.Message) error {
+			dlq = append(dlq, msgs...)
+
+			return nil
+		},
+	})
+
+	msgs := testRunMessages(3, 0)
+	msgs[1].Key = []byte("k")
+	msgs[1].Headers = []kafka.Header{{Key: "h", Value: []byte("v")}}
+
+	reader := &runReader{msgs: msgs}
+	shutdown := make(chan struct{})
+
+	consumer := newRunConsumer(t, reader, "group1", WithDeadLetterProducer(dlqProducer), WithShutdownSignalChan(shutdown))
+
+	err := consumer.Run(t.Context(), func(_ context.Context, msg kafka.Message) error {
+		switch msg.Offset {
+		case 1:
+			return errors.New("poison")
+		case 2:
+			close(shutdown)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[int]int64{0: 2}, reader.latest())
+
+	require.Len(t, dlq, 1)
+	require.Equal(t, []byte("k"), dlq[0].Key)
+	require.Equal(t, []byte{1}, dlq[0].Value)
+	require.Equal(t, []kafka.Header{
+		{Key: "h", Value: []byte("v")},
+		{Key: HeaderDeadLetterError, Value: []byte("poison")},
+		{Key: HeaderDeadLetterTopic, Value: []byte("topic1")},
+		{Key: HeaderDeadLetterPartition, Value: []byte("0")},
+		{Key: HeaderDeadLetterOffset, Value: []byte("1")},
+	}, dlq[0].Headers)
+}
+
+func TestConsumer_Run_errors(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name       string
+		reader     *runReader
+		deadLetter *Producer
+		wantErr    string
+		wantErrIs  error
+		wantLatest map[int]int64
+	}{
+		{
+			name:       "poison message",
+			reader:     &runReader{msgs: testRunMessages(3, 0)},
+			wantErr:    "cannot process the Kafka message (partition 0, offset 1)",
+			wantErrIs:  errFail,
+			wantLatest: map[int]int64{0: 0},
+		},
+		{
+			name:   "dead-letter failure",
+			reader: &runReader{msgs: testRunMessages(3, 0)},
+			deadLetter: newTestProducer(t, produceMock{
+				writeMessages: func(_ context.Context, _ ...kafka.Message) error { return errFail },
+			}),
+			wantErr:    "cannot route the Kafka message (partition 0, offset 1) to the dead-letter topic",
+			wantErrIs:  errFail,
+			wantLatest: map[int]int64{0: 0},
+		},
+		{
+			name:       "fetch error",
+			reader:     &runReader{fetchErr: errFail},
+			wantErr:    "cannot fetch a message from Kafka",
+			wantErrIs:  errFail,
+			wantLatest: map[int]int64{},
+		},
+		{
+			name:       "closed consumer",
+			reader:     &runReader{fetchErr: io.EOF},
+			wantErrIs:  ErrConsumerClosed,
+			wantLatest: map[int]int64{},
+		},
+		{
+			name:       "commit error",
+			reader:     &runReader{msgs: testRunMessages(1, 0), commitErr: errFail},
+			wantErr:    "cannot commit Kafka messages",
+			wantErrIs:  errFail,
+			wantLatest: map[int]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			consumer := newRunConsumer(t, tt.reader, "group1",
+				WithCommitBatchSize(1),
+				WithDeadLetterProducer(tt.deadLetter),
+			)
+
+			err := consumer.Run(t.Context(), func(_ context.Context, msg kafka.Message) error {
+				if msg.Offset == 1 {
+					return errFail
+				}
+
+				return nil
+			})
+			require.ErrorIs(t, err, tt.wantErrIs)
+			require.ErrorContains(t, err, tt.wantErr)
+			require.Equal(t, tt.wantLatest, tt.reader.latest())
+		})
+	}
+}
+
+func TestConsumer_Run_noGroup(t *testing.T) {
+	t.Parallel()
+
+	reader := &runReader{msgs: testRunMessages(2, 0), commitErr: errors.New("no group")}
+
+	consumer := newRunConsumer(t, reader, "", WithCommitBatchSize(1))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	handled := 0
+
+	err := consumer.Run(ctx, func(_ context.Context, _ kafka.Message) error {
+		handled++
+		if handled == 2 {
+			cancel()
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, handled)
+	require.Empty(t, reader.latest())
+}
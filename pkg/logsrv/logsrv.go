@@ -202,10 +202,10 @@ func NewHandler(cfg *logutil.Config) slog.Handler {
 		cfg = logutil.DefaultConfig()
 	}
 
-	// FormatNone with no hook has nothing to write and no side effect to fire, so a
+	// FormatNone with no hook and no export has nothing to write and no side effect to fire, so a
 	// zero-cost DiscardHandler (Enabled == false) is used instead of running the full
 	// zerolog encode path into io.Discard on every record.
-	if cfg.Format == logutil.FormatNone && cfg.HookFn == nil {
+	if cfg.Format == logutil.FormatNone && cfg.HookFn == nil && cfg.OTel == nil {
 		return slog.DiscardHandler
 	}
 
@@ -233,14 +233,36 @@ func NewHandler(cfg *logutil.Config) slog.Handler {
 	// level (Error, Info, ...) leaves the output untouched.
 	zl := zerolog.New(zerolog.SyncWriter(ew)).Level(zerolog.TraceLevel)
 
+	// A LevelController decides the enabled levels at runtime, in the level handler installed below.
+	minLevel := cfg.Level
+	if cfg.LevelController != nil {
+		minLevel = logutil.MinLevel
+	}
+
 	var h slog.Handler = &zerologHandler{
 		logger:    zl,
 		out:       ew,
 		traceIDFn: cfg.TraceIDFn,
-		minLevel:  cfg.Level,
+		minLevel:  minLevel,
 		source:    cfg.Source,
 	}
 
+	// The export replaces (or, with Tee, flanks) the zerolog handler, as logutil does.
+	if cfg.OTel != nil {
+		oh := logutil.NewSlogOTelHandler(*cfg.OTel, minLevel)
+
+		if cfg.OTel.Tee {
+			h = slog.NewMultiHandler(h, oh)
+		} else {
+			h = oh
+		}
+	}
+
+	// The redaction handler goes below the common attributes, so they are redacted too, once.
+	if cfg.Redaction != nil {
+		h = logutil.NewSlogRedactHandler(h, *cfg.Redaction)
+	}
+
 	// Bake the common attributes into the zerolog context once, so they are serialized
 	// a single time and memcpy'd per record rather than re-encoded on every line.
 	h = h.WithAttrs(cfg.CommonAttr)
@@ -253,5 +275,13 @@ func NewHandler(cfg *logutil.Config) slog.Handler {
 		h = logutil.NewSlogHookHandler(h, cfg.HookFn)
 	}
 
+	if cfg.Sampling != nil {
+		h = logutil.NewSlogSamplingHandler(h, *cfg.Sampling)
+	}
+
+	if cfg.LevelController != nil {
+		h = logutil.NewSlogLevelHandler(h, cfg.LevelController, logutil.LoggerName(cfg.CommonAttr))
+	}
+
 	return h
 }
@@ -2,6 +2,7 @@ package logsrv
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
@@ -11,10 +12,12 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/logutil"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -564,3 +567,137 @@ func TestNewLogger_Source(t *testing.T) {
 
 	require.Contains(t, out.String(), `"source":`, "source location must be present when enabled")
 }
+
+func TestNewLogger_LevelController(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cfg, err := logutil.NewConfig(
+		logutil.WithOutWriter(&buf),
+		logutil.WithLevel(logutil.LevelInfo),
+		logutil.WithDynamicLevel(),
+	)
+	require.NoError(t, err)
+
+	l := NewLogger(cfg)
+	db := l.With(slog.String(logutil.LoggerNameKey, "db"))
+
+	l.Debug("root-hidden")
+	db.Debug("db-hidden")
+
+	require.NoError(t, cfg.LevelController.SetNamedLevel("db", logutil.LevelDebug, 0))
+
+	l.Debug("root-still-hidden")
+	db.Debug("db-shown")
+
+	require.NoError(t, cfg.LevelController.SetLevel(logutil.LevelTrace, 0))
+
+	l.Log(t.Context(), logutil.LevelTrace, "root-trace")
+
+	out := buf.String()
+	require.NotContains(t, out, "hidden")
+	require.Contains(t, out, `"message":"db-shown"`)
+	require.Contains(t, out, `"message":"root-trace"`)
+}
+
+func TestNewLogger_Sampling(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf     bytes.Buffer
+		dropped int
+	)
+
+	cfg, err := logutil.NewConfig(
+		logutil.WithOutWriter(&buf),
+		logutil.WithSampling(logutil.SamplingConfig{
+			Interval: time.Hour,
+			Rule:     logutil.SamplingRule{First: 2},
+			DropFn:   func(_ logutil.LogLevel, _ string) { dropped++ },
+		}),
+	)
+	require.NoError(t, err)
+
+	l := NewLogger(cfg)
+
+	for range 5 {
+		l.Error("hot")
+	}
+
+	require.Equal(t, 2, strings.Count(buf.String(), `"message":"hot"`))
+	require.Equal(t, 3, dropped)
+}
+
+func TestNewLogger_Redaction(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cfg, err := logutil.NewConfig(
+		logutil.WithOutWriter(&buf),
+		logutil.WithCommonAttr(slog.String("api_key", "common-secret")),
+		logutil.WithRedaction(logutil.RedactConfig{Keys: []string{"api_key", "pin"}}),
+	)
+	require.NoError(t, err)
+
+	l := NewLogger(cfg)
+	l.Info("login", "pin", 1234, "dsn", "postgres://user:hunter2@db/app")
+
+	out := buf.String()
+	require.Contains(t, out, `"api_key":"***"`)
+	require.Contains(t, out, `"pin":"***"`)
+	require.NotContains(t, out, "common-secret")
+	require.NotContains(t, out, "hunter2")
+}
+
+// otelRecordingExporter is an sdklog.Exporter keeping the exported record bodies.
+type otelRecordingExporter struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (e *otelRecordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range records {
+		e.bodies = append(e.bodies, r.Body().AsString())
+	}
+
+	return nil
+}
+
+func (*otelRecordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (*otelRecordingExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func TestNewLogger_OTel(t *testing.T) {
+	t.Parallel()
+
+	for _, tee := range []bool{false, true} {
+		var buf bytes.Buffer
+
+		exp := &otelRecordingExporter{}
+		lp := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+
+		cfg, err := logutil.NewConfig(
+			logutil.WithOutWriter(&buf),
+			logutil.WithLevel(logutil.LevelWarning),
+			logutil.WithOTel(logutil.OTelConfig{LoggerProvider: lp, Tee: tee}),
+		)
+		require.NoError(t, err)
+
+		l := NewLogger(cfg)
+		l.Info("disabled")
+		l.Warn("exported")
+
+		require.Equal(t, []string{"exported"}, exp.bodies)
+		require.Equal(t, tee, strings.Contains(buf.String(), `"message":"exported"`))
+		require.NotContains(t, buf.String(), "disabled")
+	}
+}
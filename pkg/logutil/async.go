@@ -0,0 +1,366 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy is the behavior of an [AsyncWriter] whose buffer is full.
+type OverflowPolicy int8
+
+// Overflow policies.
+const (
+	// OverflowBlock blocks the write until the buffer has room, so no record
+	// is lost but a stalled output stalls the callers.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest discards the oldest buffered record.
+	OverflowDropOldest
+
+	// OverflowDropNewest discards the record being written.
+	OverflowDropNewest
+)
+
+// defaultAsyncBufferSize is the number of records buffered by an AsyncWriter
+// when AsyncWriterConfig.BufferSize is zero.
+const defaultAsyncBufferSize = 1024
+
+// Flusher is implemented by the writers buffering their output (e.g.
+// bufio.Writer), flushed periodically and on close by an [AsyncWriter].
+type Flusher interface {
+	Flush() error
+}
+
+// AsyncWriterConfig configures an [AsyncWriter].
+type AsyncWriterConfig struct {
+	// BufferSize is the number of records (writes) buffered. Zero means 1024.
+	BufferSize int
+
+	// Policy is the behavior when the buffer is full.
+	Policy OverflowPolicy
+
+	// FlushInterval is the period the output is flushed at, when it
+	// implements [Flusher]. Zero flushes it only on Flush and Close.
+	FlushInterval time.Duration
+
+	// DropFn, when set, is called for every dropped record, e.g. to count them
+	// with a metrics client. It must not block.
+	DropFn func()
+}
+
+// Validate reports whether the configuration is usable.
+func (c *AsyncWriterConfig) Validate() error {
+	if c.BufferSize < 0 {
+		return errors.New("negative async writer buffer size")
+	}
+
+	if c.Policy < OverflowBlock || c.Policy > OverflowDropNewest {
+		return fmt.Errorf("invalid async writer overflow policy: %d", c.Policy)
+	}
+
+	if c.FlushInterval < 0 {
+		return errors.New("negative async writer flush interval")
+	}
+
+	return nil
+}
+
+// AsyncWriter is an io.Writer that writes to another one in a background
+// goroutine, through a bounded buffer, so a slow or blocked output does not
+// stall the loggers. Each Write is buffered as a record (a copy of p) and the
+// full buffer is handled by the [OverflowPolicy].
+//
+// Close must be called to write the buffered records on exit (see
+// [Config.Close]); the writes following it are synchronous, unless Close timed
+// out on a stalled output, in which case they are dropped.
+type AsyncWriter struct {
+	out      io.Writer
+	cfg      AsyncWriterConfig
+	dropped  atomic.Uint64
+	wake     chan struct{}
+	flushReq chan chan error
+	stop     chan struct{}
+	done     chan struct{}
+	gaveUp   chan struct{} // closed when a Close times out
+	stopOnce sync.Once
+	upOnce   sync.Once
+	wmu      sync.Mutex // serializes the writes to out
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    [][]byte
+	head    int
+	count   int
+	closed  bool
+	err     error // first write error since the last Flush
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to out, which must be valid
+// (see [AsyncWriterConfig.Validate]), and starts its background goroutine.
+func NewAsyncWriter(out io.Writer, cfg AsyncWriterConfig) *AsyncWriter {
+	if cfg.BufferSize == 0 {
+		cfg.BufferSize = defaultAsyncBufferSize
+	}
+
+	w := &AsyncWriter{
+		out:      out,
+		cfg:      cfg,
+		wake:     make(chan struct{}, 1),
+		flushReq: make(chan chan error),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		gaveUp:   make(chan struct{}),
+		ring:     make([][]byte, cfg.BufferSize),
+	}
+
+	w.notFull = sync.NewCond(&w.mu)
+
+	go w.run()
+
+	return w
+}
+
+// Write buffers a copy of p, or writes it synchronously once the writer is
+// closed. It never returns the output errors, reported by Flush instead.
+// After a Close that timed out, p is dropped until the buffered records are
+// written, so a stalled output does not block the callers.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+
+	for w.cfg.Policy == OverflowBlock && w.count == len(w.ring) && !w.closed {
+		w.notFull.Wait()
+	}
+
+	if w.closed {
+		w.mu.Unlock()
+
+		return w.writeSync(p)
+	}
+
+	full := w.count == len(w.ring)
+
+	if full && w.cfg.Policy == OverflowDropNewest {
+		w.mu.Unlock()
+		w.drop()
+
+		return len(p), nil
+	}
+
+	if full { // OverflowDropOldest
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+		w.count--
+	}
+
+	w.ring[(w.head+w.count)%len(w.ring)] = bytes.Clone(p)
+	w.count++
+
+	w.mu.Unlock()
+
+	if full {
+		w.drop()
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns the number of records dropped by the overflow policy.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush waits until the records buffered before the call are written and the
+// output flushed (see [Flusher]), and returns the first output error since
+// the previous Flush.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+
+	select {
+	case w.flushReq <- reply:
+	case <-w.done:
+		return w.takeErr()
+	case <-ctx.Done():
+		return fmt.Errorf("async writer flush: %w", ctx.Err())
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("async writer flush: %w", ctx.Err())
+	}
+}
+
+// Close writes the buffered records, flushes the output and stops the
+// background goroutine, waiting up to the ctx deadline. The following writes
+// are synchronous. It returns the first output error since the last Flush.
+//
+// When ctx ends first, the background goroutine keeps writing the buffered
+// records, and the writes are dropped (see [AsyncWriter.Dropped]) until it is
+// done.
+func (w *AsyncWriter) Close(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		w.notFull.Broadcast()
+		close(w.stop)
+	})
+
+	select {
+	case <-w.done:
+		return w.takeErr()
+	case <-ctx.Done():
+		w.upOnce.Do(func() { close(w.gaveUp) })
+
+		return fmt.Errorf("async writer close: %w", ctx.Err())
+	}
+}
+
+// run writes the buffered records until the writer is closed.
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	var tick <-chan time.Time
+
+	if w.cfg.FlushInterval > 0 {
+		ticker := time.NewTicker(w.cfg.FlushInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.wake:
+			w.drain()
+		case <-tick:
+			w.drain()
+			w.flushOut()
+		case reply := <-w.flushReq:
+			w.drain()
+			w.flushOut()
+
+			reply <- w.takeErr()
+		case <-w.stop:
+			w.drain()
+			w.flushOut()
+
+			return
+		}
+	}
+}
+
+// drain writes the buffered records, one batch at a time.
+func (w *AsyncWriter) drain() {
+	var batch [][]byte
+
+	for {
+		w.mu.Lock()
+
+		batch = batch[:0]
+
+		for ; w.count > 0; w.count-- {
+			batch = append(batch, w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+		}
+
+		w.mu.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+
+		w.notFull.Broadcast()
+
+		w.wmu.Lock()
+
+		for _, p := range batch {
+			_, err := w.out.Write(p)
+			w.setErr(err)
+		}
+
+		w.wmu.Unlock()
+	}
+}
+
+// writeSync writes p to the output once the buffered records are written, or
+// drops it while a timed-out Close leaves them being written.
+func (w *AsyncWriter) writeSync(p []byte) (int, error) {
+	select {
+	case <-w.done:
+	default:
+		select {
+		case <-w.done:
+		case <-w.gaveUp:
+			w.drop()
+
+			return len(p), nil
+		}
+	}
+
+	w.wmu.Lock()
+	defer w.wmu.Unlock()
+
+	return w.out.Write(p) //nolint:wrapcheck
+}
+
+// flushOut flushes the output when it implements Flusher.
+func (w *AsyncWriter) flushOut() {
+	f, ok := w.out.(Flusher)
+	if !ok {
+		return
+	}
+
+	w.wmu.Lock()
+	defer w.wmu.Unlock()
+
+	w.setErr(f.Flush())
+}
+
+// drop counts a dropped record.
+func (w *AsyncWriter) drop() {
+	w.dropped.Add(1)
+
+	if w.cfg.DropFn != nil {
+		w.cfg.DropFn()
+	}
+}
+
+// setErr records err when it is the first output error since the last Flush.
+func (w *AsyncWriter) setErr(err error) {
+	if err == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// takeErr returns and clears the recorded output error.
+func (w *AsyncWriter) takeErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.err
+	w.err = nil
+
+	return err
+}
@@ -0,0 +1,477 @@
+package logutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter is an io.Writer whose writes block until the gate is opened,
+// announcing each one on started.
+type gatedWriter struct {
+	gate    chan struct{}
+	started chan struct{}
+
+	mu    sync.Mutex
+	lines []string
+	err   error
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{
+		gate:    make(chan struct{}),
+		started: make(chan struct{}, 64),
+	}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.lines = append(w.lines, string(p))
+
+	return len(p), nil
+}
+
+func (w *gatedWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.lines...)
+}
+
+func TestAsyncWriterConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     AsyncWriterConfig
+		wantErr bool
+	}{
+		{
+			name: "zero",
+		},
+		{
+			name: "valid",
+			cfg:  AsyncWriterConfig{BufferSize: 8, Policy: OverflowDropNewest, FlushInterval: time.Second},
+		},
+		{
+			name:    "negative buffer size",
+			cfg:     AsyncWriterConfig{BufferSize: -1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid policy",
+			cfg:     AsyncWriterConfig{Policy: 3},
+			wantErr: true,
+		},
+		{
+			name:    "negative flush interval",
+			cfg:     AsyncWriterConfig{FlushInterval: -time.Second},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAsyncWriter_WriteFlushClose(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+	close(out.gate)
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{})
+	require.Len(t, w.ring, defaultAsyncBufferSize)
+
+	p := []byte("a")
+
+	n, err := w.Write(p)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	p[0] = 'x' // the buffered record is a copy
+
+	_, _ = w.Write([]byte("b"))
+
+	require.NoError(t, w.Flush(t.Context()))
+	require.Equal(t, []string{"a", "b"}, out.written())
+
+	_, _ = w.Write([]byte("c"))
+
+	require.NoError(t, w.Close(t.Context()))
+	require.NoError(t, w.Close(t.Context()), "close must be idempotent")
+	require.NoError(t, w.Flush(t.Context()))
+	require.Equal(t, []string{"a", "b", "c"}, out.written())
+
+	// After Close the writes are synchronous.
+	n, err = w.Write([]byte("d"))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"a", "b", "c", "d"}, out.written())
+}
+
+func TestAsyncWriter_Overflow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		want        []string
+		wantDropped uint64
+	}{
+		{
+			name:        "drop newest",
+			policy:      OverflowDropNewest,
+			want:        []string{"a", "b", "c"},
+			wantDropped: 1,
+		},
+		{
+			name:        "drop oldest",
+			policy:      OverflowDropOldest,
+			want:        []string{"a", "c", "d"},
+			wantDropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var dropFn atomic.Int64
+
+			out := newGatedWriter()
+
+			w := NewAsyncWriter(out, AsyncWriterConfig{
+				BufferSize: 2,
+				Policy:     tt.policy,
+				DropFn:     func() { dropFn.Add(1) },
+			})
+
+			// The first record is taken by the background goroutine, blocked in the output.
+			_, _ = w.Write([]byte("a"))
+			<-out.started
+
+			for _, s := range []string{"b", "c", "d"} {
+				_, err := w.Write([]byte(s))
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.wantDropped, w.Dropped())
+			require.Equal(t, int64(tt.wantDropped), dropFn.Load()) //nolint:gosec
+
+			close(out.gate)
+
+			require.NoError(t, w.Close(t.Context()))
+			require.Equal(t, tt.want, out.written())
+		})
+	}
+}
+
+func TestAsyncWriter_OverflowBlock(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{BufferSize: 1, Policy: OverflowBlock})
+
+	_, _ = w.Write([]byte("a"))
+	<-out.started
+
+	_, _ = w.Write([]byte("b"))
+
+	written := make(chan struct{})
+
+	go func() {
+		defer close(written)
+
+		_, _ = w.Write([]byte("c"))
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("the write must block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(out.gate)
+	<-written
+
+	require.NoError(t, w.Close(t.Context()))
+	require.Equal(t, []string{"a", "b", "c"}, out.written())
+	require.Zero(t, w.Dropped())
+}
+
+func TestAsyncWriter_CloseUnblocksWriters(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{BufferSize: 1})
+
+	_, _ = w.Write([]byte("a"))
+	<-out.started
+
+	_, _ = w.Write([]byte("b"))
+
+	written := make(chan struct{})
+
+	go func() {
+		defer close(written)
+
+		_, _ = w.Write([]byte("c"))
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	// The output is blocked: Close times out, but the writers are released.
+	require.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+
+	close(out.gate)
+	<-written
+
+	require.NoError(t, w.Close(t.Context()))
+
+	// The write released after the timed-out Close is dropped, unless it
+	// came after the output recovered.
+	got := out.written()
+	require.Subset(t, got, []string{"a", "b"})
+	require.Equal(t, uint64(3), uint64(len(got))+w.Dropped())
+}
+
+func TestAsyncWriter_WriteAfterCloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{})
+
+	_, _ = w.Write([]byte("a"))
+	<-out.started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+
+	// The output is still stalled: the writes must not block.
+	n, err := w.Write([]byte("b"))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, uint64(1), w.Dropped())
+
+	close(out.gate)
+	require.NoError(t, w.Close(t.Context()))
+
+	// Once the buffered records are written, the writes are synchronous again.
+	_, err = w.Write([]byte("c"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, out.written())
+}
+
+func TestAsyncWriter_FlushTimeout(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{})
+
+	_, _ = w.Write([]byte("a"))
+	<-out.started
+
+	// The background goroutine is busy: the request is not taken.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, w.Flush(ctx), context.DeadlineExceeded)
+
+	close(out.gate)
+	require.NoError(t, w.Close(t.Context()))
+}
+
+// blockingFlusher is an io.Writer whose Flush blocks until release is closed.
+type blockingFlusher struct {
+	release chan struct{}
+}
+
+func (*blockingFlusher) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (f *blockingFlusher) Flush() error {
+	<-f.release
+
+	return nil
+}
+
+func TestAsyncWriter_FlushReplyTimeout(t *testing.T) {
+	t.Parallel()
+
+	out := &blockingFlusher{release: make(chan struct{})}
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{})
+
+	// The request is taken, but the output flush blocks the reply.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, w.Flush(ctx), context.DeadlineExceeded)
+
+	close(out.release)
+	require.NoError(t, w.Close(t.Context()))
+}
+
+func TestAsyncWriter_Errors(t *testing.T) {
+	t.Parallel()
+
+	out := newGatedWriter()
+	out.err = errors.New("write error")
+	close(out.gate)
+
+	w := NewAsyncWriter(out, AsyncWriterConfig{})
+
+	_, err := w.Write([]byte("a"))
+	require.NoError(t, err, "the output errors are reported by Flush")
+
+	_, _ = w.Write([]byte("b"))
+
+	require.EqualError(t, w.Flush(t.Context()), "write error")
+	require.NoError(t, w.Flush(t.Context()), "the error is reported once")
+
+	_, _ = w.Write([]byte("c"))
+
+	require.EqualError(t, w.Close(t.Context()), "write error")
+
+	_, err = w.Write([]byte("d"))
+	require.EqualError(t, err, "write error")
+}
+
+// syncBuffer is a thread-safe bytes.Buffer.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p) //nolint:wrapcheck
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestAsyncWriter_FlushInterval(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+
+	bw := bufio.NewWriter(&out)
+
+	w := NewAsyncWriter(bw, AsyncWriterConfig{FlushInterval: 5 * time.Millisecond})
+
+	_, _ = w.Write([]byte("a\n"))
+
+	require.Eventually(t, func() bool {
+		return out.String() == "a\n"
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, w.Close(t.Context()))
+}
+
+func TestAsyncWriter_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+
+	w := NewAsyncWriter(&out, AsyncWriterConfig{BufferSize: 4})
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_, _ = w.Write([]byte("x\n"))
+			}
+		})
+	}
+
+	wg.Wait()
+
+	require.NoError(t, w.Close(t.Context()))
+	require.Equal(t, 800, strings.Count(out.String(), "x\n"))
+}
+
+func TestConfig_AsyncWriter(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+
+	cfg, err := NewConfig(
+		WithAsyncWriter(AsyncWriterConfig{BufferSize: 16}),
+		WithOutWriter(&out), // the option order does not matter
+	)
+	require.NoError(t, err)
+
+	aw, ok := cfg.Out.(*AsyncWriter)
+	require.True(t, ok)
+	require.Same(t, &out, aw.out)
+
+	l := cfg.SlogLogger()
+	l.Info("async")
+
+	require.NoError(t, cfg.Close(t.Context()))
+	require.Contains(t, out.String(), `"msg":"async"`)
+
+	l.Info("sync")
+	require.Contains(t, out.String(), `"msg":"sync"`)
+
+	require.NoError(t, DefaultConfig().Close(t.Context()), "a synchronous output is not closed")
+}
+
+func TestWithAsyncWriter(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+
+	require.Error(t, WithAsyncWriter(AsyncWriterConfig{BufferSize: -1})(cfg))
+	require.Nil(t, cfg.asyncWriter)
+
+	ac := AsyncWriterConfig{BufferSize: 10, Policy: OverflowDropOldest}
+
+	require.NoError(t, WithAsyncWriter(ac)(cfg))
+	require.Equal(t, &ac, cfg.asyncWriter)
+}
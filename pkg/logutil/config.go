@@ -1,6 +1,8 @@
 package logutil
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
@@ -13,14 +15,34 @@ type Attr = slog.Attr
 type TraceIDFunc func() string
 
 // Config holds common logger parameters.
+//
+// When LevelController is set, it decides the enabled levels at runtime and
+// Level is only its initial value (see [WithDynamicLevel]). When Sampling is
+// set, the records are sampled (see [NewSlogSamplingHandler]). When Redaction
+// is set, the attribute values are redacted (see [NewSlogRedactHandler]). When
+// OTel is set, the records are exported as OpenTelemetry log records (see
+// [NewSlogOTelHandler]).
 type Config struct {
-	Out        io.Writer
-	Format     LogFormat
-	Level      LogLevel
-	CommonAttr []Attr
-	HookFn     HookFunc
-	TraceIDFn  TraceIDFunc
-	Source     bool
+	Out             io.Writer
+	Format          LogFormat
+	Level           LogLevel
+	LevelController *LevelController
+	Sampling        *SamplingConfig
+	Redaction       *RedactConfig
+	OTel            *OTelConfig
+	CommonAttr      []Attr
+	HookFn          HookFunc
+	TraceIDFn       TraceIDFunc
+	Source          bool
+
+	// fileWriter, set by WithFileOutput, makes NewConfig set Out to a FileWriter.
+	fileWriter *FileWriterConfig
+
+	// file is the FileWriter opened by NewConfig, closed by Close.
+	file *FileWriter
+
+	// asyncWriter, set by WithAsyncWriter, makes NewConfig wrap Out in an AsyncWriter.
+	asyncWriter *AsyncWriterConfig
 }
 
 // DefaultConfig returns a pre-initialized Config with stderr output, JSON format, info level, and empty trace ID.
@@ -55,9 +77,41 @@ func NewConfig(opts ...Option) (*Config, error) {
 		}
 	}
 
+	if cfg.fileWriter != nil {
+		fw, err := NewFileWriter(*cfg.fileWriter)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Out = fw
+		cfg.file = fw
+	}
+
+	if cfg.asyncWriter != nil {
+		cfg.Out = NewAsyncWriter(cfg.OutWriter(), *cfg.asyncWriter)
+	}
+
 	return cfg, nil
 }
 
+// Close writes the records buffered by an [AsyncWriter] output (see WithAsyncWriter) and stops it,
+// waiting up to the ctx deadline, then closes the log file opened by NewConfig (see WithFileOutput).
+// It is a no-op for any other output. The loggers remain usable, but their following records are
+// discarded with a log file output, which fails with os.ErrClosed once closed.
+func (c *Config) Close(ctx context.Context) error {
+	var err error
+
+	if aw, ok := c.Out.(*AsyncWriter); ok {
+		err = aw.Close(ctx)
+	}
+
+	if c.file != nil {
+		err = errors.Join(err, c.file.Close())
+	}
+
+	return err
+}
+
 // SlogDefaultLogger constructs a slog.Logger from Config settings and installs it as the process default.
 //
 // As a side effect of slog.SetDefault, this also redirects the standard library log
@@ -90,10 +144,10 @@ func (c *Config) OutWriter() io.Writer {
 
 // SlogHandler constructs a slog.Handler from Config settings with optional hook interception.
 func (c *Config) SlogHandler() slog.Handler {
-	// FormatNone with no hook has nothing to write and no side effect to fire, so a
-	// zero-cost DiscardHandler (Enabled == false) is used instead of encoding every
-	// record into io.Discard.
-	if c.Format == FormatNone && c.HookFn == nil {
+	// FormatNone with no hook and no export has nothing to write and no side effect to fire, so
+	// a zero-cost DiscardHandler (Enabled == false) is used instead of encoding every record into
+	// io.Discard.
+	if c.Format == FormatNone && c.HookFn == nil && c.OTel == nil {
 		return slog.DiscardHandler
 	}
 
@@ -104,7 +158,7 @@ func (c *Config) SlogHandler() slog.Handler {
 	// of slog's precomputed-attribute fast paths; it is the cost of the extended-severity
 	// labels the package models.
 	opt := &slog.HandlerOptions{
-		Level:       c.Level,
+		Level:       c.handlerLevel(),
 		AddSource:   c.Source,
 		ReplaceAttr: replaceLevelName,
 	}
@@ -127,6 +181,18 @@ func (c *Config) SlogHandler() slog.Handler {
 		h = slog.NewJSONHandler(out, opt)
 	}
 
+	// The export replaces (or, with Tee, flanks) the output handler, so every wrapper below applies
+	// to the exported records too.
+	if c.OTel != nil {
+		oh := NewSlogOTelHandler(*c.OTel, opt.Level)
+
+		if c.OTel.Tee {
+			h = slog.NewMultiHandler(h, oh)
+		} else {
+			h = oh
+		}
+	}
+
 	// The common attributes are preformatted into the handler once, before the trace wrapper is
 	// installed: applying them through the wrapper instead would record them as a derivation the
 	// wrapper has to replay on every record of a grouped logger (to keep the trace ID at the root),
@@ -138,6 +204,14 @@ func (c *Config) SlogHandler() slog.Handler {
 	// year it cannot write) would corrupt every line the handler ever writes (see slogSanitizeHandler).
 	// Nothing above can filter them, since they never pass through it again.
 	common, _ := sanitizeAttrs(c.CommonAttr)
+
+	var redactor *attrRedactor
+
+	if c.Redaction != nil {
+		redactor = newAttrRedactor(*c.Redaction)
+		common, _ = redactor.attrs(common)
+	}
+
 	h = h.WithAttrs(common)
 
 	if c.TraceIDFn != nil {
@@ -152,9 +226,44 @@ func (c *Config) SlogHandler() slog.Handler {
 	// wrapper at all) is protected just the same.
 	h = newSlogSanitizeHandler(h)
 
+	// The redaction handler goes above the sanitizing one, which drops the typed-nil errors it leaves
+	// untouched, and below the hook, so every derived logger is redacted.
+	if redactor != nil {
+		h = &SlogRedactHandler{Handler: h, r: redactor}
+	}
+
 	if c.HookFn != nil {
 		h = NewSlogHookHandler(h, c.HookFn)
 	}
 
+	// The sampling handler goes above the hook, so a dropped record is reported by Sampling.DropFn only.
+	if c.Sampling != nil {
+		h = NewSlogSamplingHandler(h, *c.Sampling)
+	}
+
+	// The level handler is outermost, so a disabled record is dropped before any other work.
+	if c.LevelController != nil {
+		h = NewSlogLevelHandler(h, c.LevelController, LoggerName(common))
+	}
+
 	return h
 }
+
+// handlerLevel returns the level of the handler built from the Config: Level, or MinLevel when the
+// LevelController decides the enabled levels.
+func (c *Config) handlerLevel() LogLevel {
+	if c.LevelController != nil {
+		return MinLevel
+	}
+
+	return c.Level
+}
+
+// setLevel sets the minimum log level, on the LevelController too when set.
+func (c *Config) setLevel(l LogLevel) {
+	c.Level = l
+
+	if c.LevelController != nil {
+		_ = c.LevelController.SetLevel(l, 0) // l is validated by the caller
+	}
+}
@@ -0,0 +1,476 @@
+package logutil
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultFileMode is the mode of the log files when FileWriterConfig.Mode is zero.
+	defaultFileMode fs.FileMode = 0o640
+
+	// backupTimeFormat is the timestamp of the rotated file names (name-<timestamp>.ext).
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+
+	// compressSuffix is the extension of the compressed rotated files.
+	compressSuffix = ".gz"
+)
+
+// FileWriterConfig configures a [FileWriter].
+type FileWriterConfig struct {
+	// Path is the log file path. It is required.
+	Path string
+
+	// Mode is the mode of the created files. Zero means 0640.
+	Mode fs.FileMode
+
+	// MaxSize is the size in bytes a file is rotated at. Zero disables the
+	// rotation by size.
+	MaxSize int64
+
+	// RotateEvery is the period a file is rotated at, aligned to the multiples
+	// of the period since the zero time (e.g. at midnight UTC for 24h). Zero
+	// disables the rotation by time.
+	RotateEvery time.Duration
+
+	// MaxBackups is the number of rotated files retained. Zero retains all.
+	MaxBackups int
+
+	// MaxAge is the age the rotated files are removed at. Zero retains all.
+	MaxAge time.Duration
+
+	// Compress enables the gzip compression of the rotated files.
+	Compress bool
+
+	// ReopenOnSignal reopens the file on SIGHUP (see [FileWriter.Reopen]),
+	// for compatibility with logrotate.
+	ReopenOnSignal bool
+}
+
+// Validate reports whether the configuration is usable.
+func (c *FileWriterConfig) Validate() error {
+	if c.Path == "" {
+		return errors.New("empty log file path")
+	}
+
+	if c.MaxSize < 0 || c.RotateEvery < 0 || c.MaxBackups < 0 || c.MaxAge < 0 {
+		return errors.New("negative log file rotation setting")
+	}
+
+	return nil
+}
+
+// FileWriter is an io.Writer appending to a log file, rotated by size and by
+// time. A rotated file is renamed with its rotation time (name-<time>.ext) and
+// optionally compressed, and the old ones are removed, in a background
+// goroutine.
+//
+// It is safe for concurrent use. After Close, the writes, rotations and reopens
+// fail with os.ErrClosed.
+type FileWriter struct {
+	cfg   FileWriterConfig
+	nowFn func() time.Time
+
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+	nextRotate time.Time
+	closed     bool // Close was called: the background goroutine is stopped
+
+	millCh   chan struct{}
+	millDone chan struct{}
+	millOnce sync.Once
+	stopSig  func()
+}
+
+// NewFileWriter opens (or creates) the log file configured by cfg, which must
+// be valid (see [FileWriterConfig.Validate]), creating its directory if
+// missing.
+func NewFileWriter(cfg FileWriterConfig) (*FileWriter, error) {
+	if cfg.Mode == 0 {
+		cfg.Mode = defaultFileMode
+	}
+
+	w := &FileWriter{
+		cfg:      cfg,
+		nowFn:    time.Now,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+		stopSig:  func() {},
+	}
+
+	err := w.open()
+	if err != nil {
+		return nil, err
+	}
+
+	w.setNextRotate()
+
+	go w.mill()
+
+	if cfg.ReopenOnSignal {
+		w.stopSig = w.reopenOn(syscall.SIGHUP)
+	}
+
+	return w, nil
+}
+
+// Write appends p to the log file, rotating it first when due.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	if w.file == nil {
+		err := w.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if w.rotationDue(len(p)) {
+		err := w.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err //nolint:wrapcheck
+}
+
+// Rotate rotates the log file now.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	if w.file == nil {
+		err := w.open()
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.rotate()
+}
+
+// Reopen closes and reopens the log file by path, e.g. after an external tool
+// (logrotate) renamed it.
+func (w *FileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+
+	return w.open()
+}
+
+// Close stops the signal handling and closes the log file, waiting for the
+// pending compression and removal of the rotated files.
+func (w *FileWriter) Close() error {
+	w.stopSig()
+
+	w.millOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.millCh)
+	})
+
+	<-w.millDone
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closeFile()
+}
+
+// reopenOn reopens the file on every sig, until the returned function is called.
+func (w *FileWriter) reopenOn(sig os.Signal) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, sig)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = w.Reopen() // a failure is retried by the next Write
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// rotationDue reports whether the file must be rotated before writing n bytes.
+// An empty file is never rotated. It must be called with the lock held.
+func (w *FileWriter) rotationDue(n int) bool {
+	if w.size == 0 {
+		w.setNextRotate()
+
+		return false
+	}
+
+	if w.cfg.MaxSize > 0 && w.size+int64(n) > w.cfg.MaxSize {
+		return true
+	}
+
+	return w.cfg.RotateEvery > 0 && !w.nowFn().Before(w.nextRotate)
+}
+
+// setNextRotate sets the next rotation time from now. It must be called with
+// the lock held.
+func (w *FileWriter) setNextRotate() {
+	if w.cfg.RotateEvery > 0 {
+		w.nextRotate = w.nowFn().Truncate(w.cfg.RotateEvery).Add(w.cfg.RotateEvery)
+	}
+}
+
+// open opens the log file for appending. It must be called with the lock held.
+func (w *FileWriter) open() error {
+	err := os.MkdirAll(filepath.Dir(w.cfg.Path), 0o750)
+	if err != nil {
+		return fmt.Errorf("failed creating the log file directory: %w", err)
+	}
+
+	f, err := os.OpenFile(w.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.cfg.Mode)
+	if err != nil {
+		return fmt.Errorf("failed opening the log file: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed reading the log file size: %w", err), f.Close())
+	}
+
+	w.file = f
+	w.size = fi.Size()
+
+	return nil
+}
+
+// closeFile closes the log file, if open. It must be called with the lock held.
+func (w *FileWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	if err != nil {
+		return fmt.Errorf("failed closing the log file: %w", err)
+	}
+
+	return nil
+}
+
+// rotate renames the log file with the current time, opens a new one and
+// signals the background goroutine. It must be called with the lock held.
+func (w *FileWriter) rotate() error {
+	err := w.closeFile()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(w.cfg.Path, w.backupName())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.Join(fmt.Errorf("failed renaming the log file: %w", err), w.open())
+	}
+
+	err = w.open()
+	if err != nil {
+		return err
+	}
+
+	w.setNextRotate()
+
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backupName returns an unused rotated file name for the current time.
+func (w *FileWriter) backupName() string {
+	dir, prefix, ext := w.nameParts()
+
+	for t := w.nowFn().UTC(); ; t = t.Add(time.Millisecond) {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+
+		_, err := os.Lstat(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			_, err = os.Lstat(name + compressSuffix)
+		}
+
+		if errors.Is(err, fs.ErrNotExist) {
+			return name
+		}
+	}
+}
+
+// nameParts splits the file path into its directory, the prefix of the rotated
+// file names and the extension.
+func (w *FileWriter) nameParts() (string, string, string) {
+	dir, name := filepath.Split(w.cfg.Path)
+	ext := filepath.Ext(name)
+
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// logBackup is a rotated file.
+type logBackup struct {
+	path string
+	time time.Time
+}
+
+// mill compresses and removes the rotated files after each rotation, until Close.
+func (w *FileWriter) mill() {
+	defer close(w.millDone)
+
+	for range w.millCh {
+		_ = w.millRun() // best effort: retried after the next rotation
+	}
+}
+
+// millRun compresses the rotated files (when enabled) and removes the ones in
+// excess of MaxBackups or older than MaxAge.
+func (w *FileWriter) millRun() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	if w.cfg.Compress {
+		for i, b := range backups {
+			if strings.HasSuffix(b.path, compressSuffix) {
+				continue
+			}
+
+			err := compressFile(b.path, w.cfg.Mode)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			backups[i].path += compressSuffix
+		}
+	}
+
+	cutoff := w.nowFn().Add(-w.cfg.MaxAge)
+
+	for i, b := range backups { // newest first
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || (w.cfg.MaxAge > 0 && b.time.Before(cutoff)) {
+			errs = append(errs, os.Remove(b.path))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups returns the rotated files, newest first.
+func (w *FileWriter) backups() ([]logBackup, error) {
+	dir, prefix, ext := w.nameParts()
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed reading the log directory: %w", err)
+	}
+
+	var backups []logBackup
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], compressSuffix), ext)
+
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue // not a rotated file
+		}
+
+		backups = append(backups, logBackup{path: filepath.Join(dir, name), time: t})
+	}
+
+	slices.SortFunc(backups, func(a, b logBackup) int {
+		return b.time.Compare(a.time)
+	})
+
+	return backups, nil
+}
+
+// compressFile replaces the file at path with its gzip compression (path.gz).
+func compressFile(path string, mode fs.FileMode) error {
+	src, err := os.Open(path) //nolint:gosec // path of a rotated log file
+	if err != nil {
+		return fmt.Errorf("failed opening the rotated log file: %w", err)
+	}
+
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed creating the compressed log file: %w", err)
+	}
+
+	gz := gzip.NewWriter(dst)
+
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+
+	err = errors.Join(err, dst.Close())
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed compressing the rotated log file: %w", err), os.Remove(path+compressSuffix))
+	}
+
+	return os.Remove(path) //nolint:wrapcheck
+}
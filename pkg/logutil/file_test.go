@@ -0,0 +1,410 @@
+package logutil
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// logDirFiles returns the sorted names of the files in dir.
+func logDirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// readLogFile returns the content of a log file, decompressing a gzip one.
+func readLogFile(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path) //nolint:gosec
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, f.Close()) }()
+
+	var r io.Reader = f
+
+	if strings.HasSuffix(path, compressSuffix) {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+
+		r = gz
+	}
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(b)
+}
+
+// fakeClock is a settable time source.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newTestFileWriter returns a FileWriter using clock, closed at the end of the test.
+func newTestFileWriter(t *testing.T, cfg FileWriterConfig, clock *fakeClock) *FileWriter {
+	t.Helper()
+
+	w, err := NewFileWriter(cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = w.Close() })
+
+	w.mu.Lock()
+	w.nowFn = clock.Now
+	w.setNextRotate()
+	w.mu.Unlock()
+
+	return w
+}
+
+func TestFileWriterConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     FileWriterConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg:  FileWriterConfig{Path: "a.log", MaxSize: 1, RotateEvery: time.Hour, MaxBackups: 1, MaxAge: time.Hour},
+		},
+		{
+			name:    "empty path",
+			wantErr: true,
+		},
+		{
+			name:    "negative max size",
+			cfg:     FileWriterConfig{Path: "a.log", MaxSize: -1},
+			wantErr: true,
+		},
+		{
+			name:    "negative max age",
+			cfg:     FileWriterConfig{Path: "a.log", MaxAge: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewFileWriter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "app.log")
+
+	w, err := NewFileWriter(FileWriterConfig{Path: path})
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close(), "close must be idempotent")
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, defaultFileMode, fi.Mode().Perm())
+
+	// An existing file is appended to, and its size counted.
+	w, err = NewFileWriter(FileWriterConfig{Path: path, Mode: 0o600})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), w.size)
+
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// The file is not reopened after Close.
+	_, err = w.Write([]byte("third\n"))
+	require.ErrorIs(t, err, os.ErrClosed)
+	require.ErrorIs(t, w.Rotate(), os.ErrClosed)
+	require.ErrorIs(t, w.Reopen(), os.ErrClosed)
+	require.Nil(t, w.file)
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "first\nsecond\n", readLogFile(t, filepath.Join(dir, "sub", logDirFiles(t, filepath.Join(dir, "sub"))[0])))
+}
+
+func TestNewFileWriter_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, err := NewFileWriter(FileWriterConfig{Path: filepath.Join(file, "app.log")})
+	require.ErrorContains(t, err, "failed creating the log file directory")
+
+	_, err = NewFileWriter(FileWriterConfig{Path: dir})
+	require.ErrorContains(t, err, "failed opening the log file")
+}
+
+func TestFileWriter_RotateBySize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	w := newTestFileWriter(t, FileWriterConfig{Path: path, MaxSize: 10}, clock)
+
+	for _, s := range []string{"aaaa\n", "bbbb\n", "cccc\n", strings.Repeat("x", 20) + "\n"} {
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	// Same time: the names of the rotated files are made unique.
+	require.Equal(t, []string{
+		"app-2026-01-02T03-04-05.000.log",
+		"app-2026-01-02T03-04-05.001.log",
+		"app.log",
+	}, logDirFiles(t, dir))
+
+	require.Equal(t, "aaaa\nbbbb\n", readLogFile(t, filepath.Join(dir, "app-2026-01-02T03-04-05.000.log")))
+	require.Equal(t, "cccc\n", readLogFile(t, filepath.Join(dir, "app-2026-01-02T03-04-05.001.log")))
+	require.Equal(t, strings.Repeat("x", 20)+"\n", readLogFile(t, path), "a write larger than MaxSize fills a file")
+}
+
+func TestFileWriter_RotateByTime(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app")
+	clock := &fakeClock{now: time.Date(2026, 1, 2, 23, 0, 0, 0, time.UTC)}
+
+	w := newTestFileWriter(t, FileWriterConfig{Path: path, RotateEvery: 24 * time.Hour}, clock)
+
+	_, _ = w.Write([]byte("day1\n"))
+
+	clock.Add(30 * time.Minute)
+
+	_, _ = w.Write([]byte("day1\n"))
+
+	clock.Add(time.Hour) // past midnight
+
+	_, _ = w.Write([]byte("day2\n"))
+
+	clock.Add(48 * time.Hour) // the file is rotated on the next write
+
+	require.NoError(t, w.Reopen())
+
+	_, _ = w.Write([]byte("day5\n"))
+
+	require.NoError(t, os.Truncate(path, 0))
+	require.NoError(t, w.Reopen())
+
+	clock.Add(48 * time.Hour) // an empty file is not rotated
+
+	_, _ = w.Write([]byte("day7\n"))
+
+	require.NoError(t, w.Close())
+
+	require.Equal(t, []string{"app", "app-2026-01-03T00-30-00.000", "app-2026-01-05T00-30-00.000"}, logDirFiles(t, dir))
+	require.Equal(t, "day1\nday1\n", readLogFile(t, filepath.Join(dir, "app-2026-01-03T00-30-00.000")))
+	require.Equal(t, "day2\n", readLogFile(t, filepath.Join(dir, "app-2026-01-05T00-30-00.000")))
+	require.Equal(t, "day7\n", readLogFile(t, path))
+}
+
+func TestFileWriter_CompressAndRetention(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := &fakeClock{now: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)}
+
+	// Unrelated files are left alone.
+	for _, name := range []string{"other.log", "app-notatime.log", "app-2026-01-01T00-00-00.000.log.d"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "app-2020-01-01T00-00-00.000.log"), 0o750))
+
+	// A backup older than MaxAge is removed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-2026-01-01T00-00-00.000.log.gz"), nil, 0o600))
+
+	w := newTestFileWriter(t, FileWriterConfig{
+		Path:       path,
+		MaxBackups: 2,
+		MaxAge:     7 * 24 * time.Hour,
+		Compress:   true,
+	}, clock)
+
+	for i := range 4 {
+		_, _ = w.Write([]byte(strings.Repeat("x", i+1)))
+
+		require.NoError(t, w.Rotate())
+
+		// Wait for the background goroutine, one rotation at a time.
+		require.Eventually(t, func() bool {
+			for _, name := range logDirFiles(t, dir) {
+				if strings.HasPrefix(name, "app-2026-01-1") && !strings.HasSuffix(name, compressSuffix) {
+					return false
+				}
+			}
+
+			return true
+		}, time.Second, time.Millisecond)
+
+		clock.Add(time.Hour)
+	}
+
+	require.NoError(t, w.Close())
+
+	require.Equal(t, []string{
+		"app-2020-01-01T00-00-00.000.log",
+		"app-2026-01-01T00-00-00.000.log.d",
+		"app-2026-01-10T02-00-00.000.log.gz",
+		"app-2026-01-10T03-00-00.000.log.gz",
+		"app-notatime.log",
+		"app.log",
+		"other.log",
+	}, logDirFiles(t, dir))
+
+	require.Equal(t, "xxxx", readLogFile(t, filepath.Join(dir, "app-2026-01-10T03-00-00.000.log.gz")))
+}
+
+func Test_compressFile_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	require.ErrorContains(t, compressFile(filepath.Join(dir, "missing"), 0o600), "failed opening")
+
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.WriteFile(src, []byte("x"), 0o600))
+	require.NoError(t, os.Mkdir(src+compressSuffix, 0o750))
+
+	require.ErrorContains(t, compressFile(src, 0o600), "failed creating")
+}
+
+func TestFileWriter_millRunMissingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	w := newTestFileWriter(t, FileWriterConfig{Path: filepath.Join(dir, "app.log")}, &fakeClock{})
+
+	require.NoError(t, os.RemoveAll(dir))
+	require.ErrorContains(t, w.millRun(), "failed reading the log directory")
+}
+
+func TestFileWriter_Reopen(t *testing.T) { //nolint:paralleltest // sends SIGHUP to the process.
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := NewFileWriter(FileWriterConfig{Path: path, ReopenOnSignal: true})
+	require.NoError(t, err)
+
+	_, _ = w.Write([]byte("before\n"))
+
+	// logrotate renames the file, then sends SIGHUP.
+	require.NoError(t, os.Rename(path, path+".1"))
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	_, _ = w.Write([]byte("after\n"))
+
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "before\n", readLogFile(t, path+".1"))
+	require.Equal(t, "after\n", readLogFile(t, path))
+}
+
+func TestConfig_FileOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	cfg, err := NewConfig(
+		WithFileOutput(FileWriterConfig{Path: path}),
+		WithAsyncWriter(AsyncWriterConfig{}),
+	)
+	require.NoError(t, err)
+
+	aw, ok := cfg.Out.(*AsyncWriter)
+	require.True(t, ok)
+	require.Same(t, cfg.file, aw.out)
+
+	cfg.SlogLogger().Info("to file")
+
+	require.NoError(t, cfg.Close(t.Context()))
+	require.Contains(t, readLogFile(t, path), `"msg":"to file"`)
+
+	_, err = NewConfig(WithFileOutput(FileWriterConfig{Path: dir}))
+	require.Error(t, err)
+}
+
+func TestWithFileOutput(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+
+	require.Error(t, WithFileOutput(FileWriterConfig{})(cfg))
+	require.Nil(t, cfg.fileWriter)
+
+	fc := FileWriterConfig{Path: "app.log", MaxSize: 1 << 20}
+
+	require.NoError(t, WithFileOutput(fc)(cfg))
+	require.Equal(t, &fc, cfg.fileWriter)
+}
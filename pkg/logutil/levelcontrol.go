@@ -0,0 +1,343 @@
+package logutil
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoggerNameKey is the root attribute key naming a logger (e.g. the component
+// of a logger derived with logger.With(slog.String("component", "httpserver"))),
+// used to select its level override in a [LevelController].
+const LoggerNameKey = "component"
+
+// MinLevel is the lowest representable level. The handlers gated by a
+// [LevelController] are built with it, so the controller alone decides which
+// records are enabled.
+const MinLevel LogLevel = math.MinInt
+
+// errInvalidLevel is returned when an unknown log level is set.
+var errInvalidLevel = errors.New("invalid log level")
+
+// LevelController changes the log levels at runtime: the level of every logger
+// (backed by a slog.LevelVar) and the level overrides of the named loggers (see
+// [LoggerNameKey]). A level set with a TTL reverts automatically when it
+// expires, so a temporarily raised verbosity cannot be forgotten.
+//
+// It is safe for concurrent use. The zero value is not usable; create it with
+// [NewLevelController].
+type LevelController struct {
+	level     slog.LevelVar
+	overrides atomic.Pointer[map[string]LogLevel] // copy-on-write, read on every log call
+
+	mu            sync.Mutex
+	levelRevert   *levelRevert
+	overrideRvrts map[string]*levelRevert
+	nowFn         func() time.Time
+}
+
+// levelRevert is a pending automatic revert of a level.
+type levelRevert struct {
+	timer   *time.Timer
+	expires time.Time
+	level   LogLevel // level restored on expiry
+	restore bool     // whether level is restored (false removes the override)
+}
+
+// LevelSetting is the state of a level in a [LevelState].
+type LevelSetting struct {
+	// Level is the level name (see [LevelName]).
+	Level string `json:"level"`
+
+	// Expires is the time the level reverts, if set with a TTL.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// LevelState is a snapshot of the levels of a [LevelController].
+type LevelState struct {
+	LevelSetting
+
+	// Overrides are the level overrides by logger name.
+	Overrides map[string]LevelSetting `json:"overrides,omitempty"`
+}
+
+// NewLevelController returns a LevelController with the given initial level and
+// no overrides.
+func NewLevelController(level LogLevel) *LevelController {
+	c := &LevelController{
+		overrideRvrts: make(map[string]*levelRevert),
+		nowFn:         time.Now,
+	}
+
+	c.level.Set(level)
+	c.overrides.Store(&map[string]LogLevel{})
+
+	return c
+}
+
+// Level returns the level of the loggers without an override. It implements
+// slog.Leveler.
+func (c *LevelController) Level() LogLevel {
+	return c.level.Level()
+}
+
+// NamedLevel returns the effective level of the logger named name: its
+// override if any, otherwise [LevelController.Level].
+func (c *LevelController) NamedLevel(name string) LogLevel {
+	if name != "" {
+		if l, ok := (*c.overrides.Load())[name]; ok {
+			return l
+		}
+	}
+
+	return c.level.Level()
+}
+
+// SetLevel sets the level of the loggers without an override. A positive ttl
+// reverts the change when it expires, restoring the level in force before the
+// first of consecutive temporary changes; zero makes it permanent.
+func (c *LevelController) SetLevel(level LogLevel, ttl time.Duration) error {
+	if !ValidLevel(level) {
+		return errInvalidLevel
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.level.Level()
+
+	if r := c.levelRevert; r != nil {
+		r.timer.Stop()
+
+		prev = r.level
+		c.levelRevert = nil
+	}
+
+	c.level.Set(level)
+
+	if ttl > 0 {
+		c.levelRevert = c.newRevert(ttl, prev, true, func(r *levelRevert) {
+			if c.levelRevert == r {
+				c.level.Set(r.level)
+				c.levelRevert = nil
+			}
+		})
+	}
+
+	return nil
+}
+
+// SetNamedLevel sets the level override of the logger named name. A positive
+// ttl reverts the change when it expires, restoring the override in force (or
+// its absence) before the first of consecutive temporary changes; zero makes
+// it permanent.
+func (c *LevelController) SetNamedLevel(name string, level LogLevel, ttl time.Duration) error {
+	if name == "" {
+		return errors.New("empty logger name")
+	}
+
+	if !ValidLevel(level) {
+		return errInvalidLevel
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, restore := (*c.overrides.Load())[name]
+
+	if r := c.overrideRvrts[name]; r != nil {
+		r.timer.Stop()
+
+		prev, restore = r.level, r.restore
+		delete(c.overrideRvrts, name)
+	}
+
+	c.storeOverride(name, level, true)
+
+	if ttl > 0 {
+		c.overrideRvrts[name] = c.newRevert(ttl, prev, restore, func(r *levelRevert) {
+			if c.overrideRvrts[name] == r {
+				c.storeOverride(name, r.level, r.restore)
+				delete(c.overrideRvrts, name)
+			}
+		})
+	}
+
+	return nil
+}
+
+// ResetNamedLevel removes the level override of the logger named name,
+// including any pending revert.
+func (c *LevelController) ResetNamedLevel(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r := c.overrideRvrts[name]; r != nil {
+		r.timer.Stop()
+		delete(c.overrideRvrts, name)
+	}
+
+	c.storeOverride(name, 0, false)
+}
+
+// State returns a snapshot of the levels and their expiry times.
+func (c *LevelController) State() LevelState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := LevelState{
+		LevelSetting: LevelSetting{
+			Level:   LevelName(c.level.Level()),
+			Expires: c.levelRevert.expiry(),
+		},
+	}
+
+	overrides := *c.overrides.Load()
+	if len(overrides) == 0 {
+		return state
+	}
+
+	state.Overrides = make(map[string]LevelSetting, len(overrides))
+
+	for name, level := range overrides {
+		state.Overrides[name] = LevelSetting{
+			Level:   LevelName(level),
+			Expires: c.overrideRvrts[name].expiry(),
+		}
+	}
+
+	return state
+}
+
+// newRevert schedules fn, called with the lock held, to revert a level change
+// after ttl. It must be called with the lock held.
+func (c *LevelController) newRevert(ttl time.Duration, level LogLevel, restore bool, fn func(r *levelRevert)) *levelRevert {
+	r := &levelRevert{
+		expires: c.nowFn().Add(ttl),
+		level:   level,
+		restore: restore,
+	}
+
+	r.timer = time.AfterFunc(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		fn(r)
+	})
+
+	return r
+}
+
+// storeOverride sets (set == true) or removes the override of name. It must be
+// called with the lock held.
+func (c *LevelController) storeOverride(name string, level LogLevel, set bool) {
+	overrides := maps.Clone(*c.overrides.Load())
+
+	if set {
+		overrides[name] = level
+	} else {
+		delete(overrides, name)
+	}
+
+	c.overrides.Store(&overrides)
+}
+
+// expiry returns the expiry time of the pending revert r, or nil.
+func (r *levelRevert) expiry() *time.Time {
+	if r == nil {
+		return nil
+	}
+
+	t := r.expires
+
+	return &t
+}
+
+// LoggerName returns the logger name carried by the root attributes attrs (the
+// last string [LoggerNameKey] attribute), or an empty string.
+func LoggerName(attrs []Attr) string {
+	var name string
+
+	for _, a := range attrs {
+		if a.Key == LoggerNameKey && a.Value.Kind() == slog.KindString {
+			name = a.Value.String()
+		}
+	}
+
+	return name
+}
+
+// SlogLevelHandler is a slog.Handler that enables the records by the levels of
+// a [LevelController], selecting the override by the logger name set with
+// WithAttrs (see [LoggerNameKey]).
+type SlogLevelHandler struct {
+	slog.Handler
+
+	ctl     *LevelController
+	name    string
+	grouped bool
+}
+
+// NewSlogLevelHandler wraps h so its records are enabled by the levels of ctl,
+// for the logger named name (e.g. [LoggerName] of the attributes already
+// applied to h). The level of h must not be higher than the levels set on ctl
+// (see [MinLevel]). A nil h falls back to the handler of the current
+// slog.Default, captured now.
+func NewSlogLevelHandler(h slog.Handler, ctl *LevelController, name string) *SlogLevelHandler {
+	if h == nil {
+		h = slog.Default().Handler()
+	}
+
+	return &SlogLevelHandler{
+		Handler: h,
+		ctl:     ctl,
+		name:    name,
+	}
+}
+
+// Enabled reports whether level is enabled for the logger name by the
+// controller and by the underlying handler.
+func (h *SlogLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.ctl.NamedLevel(h.name) && h.Handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new SlogLevelHandler whose underlying handler carries the
+// given attributes. A root-level [LoggerNameKey] attribute renames the logger.
+func (h *SlogLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	name := h.name
+	if !h.grouped {
+		name = cmp.Or(LoggerName(attrs), name)
+	}
+
+	return &SlogLevelHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		ctl:     h.ctl,
+		name:    name,
+		grouped: h.grouped,
+	}
+}
+
+// WithGroup returns a new SlogLevelHandler whose underlying handler opens the
+// given group. Attributes added under a group do not rename the logger.
+func (h *SlogLevelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogLevelHandler{
+		Handler: h.Handler.WithGroup(name),
+		ctl:     h.ctl,
+		name:    h.name,
+		grouped: true,
+	}
+}
@@ -0,0 +1,303 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLevelController(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelWarning)
+
+	require.Equal(t, LevelWarning, c.Level())
+	require.Equal(t, LevelWarning, c.NamedLevel("any"))
+	require.Equal(t, LevelState{LevelSetting: LevelSetting{Level: "warning"}}, c.State())
+}
+
+func TestLevelController_SetLevel(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	require.ErrorIs(t, c.SetLevel(-16, 0), errInvalidLevel)
+	require.Equal(t, LevelInfo, c.Level())
+
+	require.NoError(t, c.SetLevel(LevelError, 0))
+	require.Equal(t, LevelError, c.Level())
+	require.Nil(t, c.State().Expires)
+}
+
+func TestLevelController_SetLevelTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	c := NewLevelController(LevelInfo)
+	c.nowFn = func() time.Time { return now }
+
+	require.NoError(t, c.SetLevel(LevelDebug, time.Hour))
+	require.Equal(t, LevelDebug, c.Level())
+
+	state := c.State()
+	require.Equal(t, "debug", state.Level)
+	require.NotNil(t, state.Expires)
+	require.Equal(t, now.Add(time.Hour), *state.Expires)
+
+	// A consecutive temporary change reverts to the level before the first one.
+	require.NoError(t, c.SetLevel(LevelTrace, 10*time.Millisecond))
+	require.Equal(t, LevelTrace, c.Level())
+
+	require.Eventually(t, func() bool {
+		return c.Level() == LevelInfo
+	}, time.Second, time.Millisecond)
+
+	require.Nil(t, c.State().Expires)
+}
+
+func TestLevelController_SetLevelPermanentCancelsRevert(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	require.NoError(t, c.SetLevel(LevelDebug, 10*time.Millisecond))
+	require.NoError(t, c.SetLevel(LevelError, 0))
+
+	time.Sleep(50 * time.Millisecond)
+
+	require.Equal(t, LevelError, c.Level())
+	require.Nil(t, c.State().Expires)
+}
+
+func TestLevelController_SetNamedLevel(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	require.Error(t, c.SetNamedLevel("", LevelDebug, 0))
+	require.ErrorIs(t, c.SetNamedLevel("db", -16, 0), errInvalidLevel)
+
+	require.NoError(t, c.SetNamedLevel("db", LevelDebug, 0))
+	require.Equal(t, LevelDebug, c.NamedLevel("db"))
+	require.Equal(t, LevelInfo, c.NamedLevel("http"))
+	require.Equal(t, LevelInfo, c.NamedLevel(""))
+
+	require.NoError(t, c.SetLevel(LevelError, 0))
+	require.Equal(t, LevelDebug, c.NamedLevel("db"), "an override is independent of the level")
+
+	require.Equal(t, LevelState{
+		LevelSetting: LevelSetting{Level: "error"},
+		Overrides:    map[string]LevelSetting{"db": {Level: "debug"}},
+	}, c.State())
+
+	c.ResetNamedLevel("db")
+	require.Equal(t, LevelError, c.NamedLevel("db"))
+	require.Nil(t, c.State().Overrides)
+}
+
+func TestLevelController_SetNamedLevelTTL(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	// A temporary override without a previous one is removed on expiry.
+	require.NoError(t, c.SetNamedLevel("db", LevelDebug, time.Hour))
+	require.NoError(t, c.SetNamedLevel("db", LevelTrace, 10*time.Millisecond))
+	require.NotNil(t, c.State().Overrides["db"].Expires)
+
+	require.Eventually(t, func() bool {
+		return c.State().Overrides == nil
+	}, time.Second, time.Millisecond)
+
+	// A temporary override restores the previous permanent one on expiry.
+	require.NoError(t, c.SetNamedLevel("http", LevelWarning, 0))
+	require.NoError(t, c.SetNamedLevel("http", LevelDebug, 10*time.Millisecond))
+	require.Equal(t, LevelDebug, c.NamedLevel("http"))
+
+	require.Eventually(t, func() bool {
+		return c.NamedLevel("http") == LevelWarning
+	}, time.Second, time.Millisecond)
+
+	require.Nil(t, c.State().Overrides["http"].Expires)
+}
+
+func TestLevelController_ResetNamedLevelCancelsRevert(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	require.NoError(t, c.SetNamedLevel("db", LevelWarning, 0))
+	require.NoError(t, c.SetNamedLevel("db", LevelDebug, 10*time.Millisecond))
+
+	c.ResetNamedLevel("db")
+	c.ResetNamedLevel("unknown")
+
+	time.Sleep(50 * time.Millisecond)
+
+	require.Equal(t, LevelInfo, c.NamedLevel("db"), "a reset override must not be restored")
+}
+
+func TestLevelController_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	c := NewLevelController(LevelInfo)
+
+	var wg sync.WaitGroup
+
+	for i := range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_ = c.SetLevel(LevelDebug, time.Millisecond)
+				_ = c.SetNamedLevel("db", LevelError, time.Duration(i)*time.Millisecond)
+				_ = c.NamedLevel("db")
+				_ = c.State()
+
+				c.ResetNamedLevel("db")
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestLoggerName(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, LoggerName(nil))
+	require.Empty(t, LoggerName([]Attr{slog.Int(LoggerNameKey, 1)}))
+	require.Equal(t, "b", LoggerName([]Attr{
+		slog.String(LoggerNameKey, "a"),
+		slog.String("x", "y"),
+		slog.String(LoggerNameKey, "b"),
+	}))
+}
+
+func TestNewSlogLevelHandler_NilHandler(t *testing.T) { //nolint:paralleltest // mutates the slog default.
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+
+	defer slog.SetDefault(prev)
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	h := NewSlogLevelHandler(nil, NewLevelController(LevelInfo), "")
+
+	require.NotPanics(t, func() { slog.New(h).Info("m") })
+	require.Contains(t, buf.String(), `"msg":"m"`)
+}
+
+func TestSlogLevelHandler_Enabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	ctl := NewLevelController(LevelInfo)
+	inner := slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: LevelDebug})
+
+	h := NewSlogLevelHandler(inner, ctl, "db")
+
+	require.True(t, h.Enabled(ctx, LevelInfo))
+	require.False(t, h.Enabled(ctx, LevelDebug))
+
+	require.NoError(t, ctl.SetNamedLevel("db", LevelTrace, 0))
+	require.True(t, h.Enabled(ctx, LevelDebug))
+	require.False(t, h.Enabled(ctx, LevelTrace), "the underlying handler level still applies")
+}
+
+func TestSlogLevelHandler_WithAttrs(t *testing.T) {
+	t.Parallel()
+
+	ctl := NewLevelController(LevelError)
+	require.NoError(t, ctl.SetNamedLevel("db", LevelDebug, 0))
+
+	var buf bytes.Buffer
+
+	h := NewSlogLevelHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: MinLevel}), ctl, "")
+
+	require.Same(t, h, h.WithAttrs(nil))
+	require.Same(t, h, h.WithGroup(""))
+
+	root := slog.New(h)
+	root.Debug("root")
+
+	db := root.With(slog.String(LoggerNameKey, "db"))
+	db.Debug("db")
+	db.With("k", "v").Debug("db-derived")
+
+	// A nested logger name attribute does not rename the logger.
+	grouped := root.WithGroup("g").With(slog.String(LoggerNameKey, "db"))
+	grouped.Debug("grouped")
+	db.WithGroup("g").With(slog.String(LoggerNameKey, "other")).Debug("db-grouped")
+
+	out := buf.String()
+	require.NotContains(t, out, `"msg":"root"`)
+	require.Contains(t, out, `"msg":"db"`)
+	require.Contains(t, out, `"msg":"db-derived"`)
+	require.NotContains(t, out, `"msg":"grouped"`)
+	require.Contains(t, out, `"msg":"db-grouped"`)
+}
+
+func TestConfig_SlogHandlerLevelController(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cfg, err := NewConfig(
+		WithOutWriter(&buf),
+		WithLevel(LevelInfo),
+		WithDynamicLevel(),
+		WithCommonAttr(slog.String(LoggerNameKey, "svc")),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.LevelController)
+
+	l := cfg.SlogLogger()
+
+	l.Debug("hidden")
+	require.Empty(t, buf.String())
+
+	require.NoError(t, cfg.LevelController.SetNamedLevel("svc", LevelDebug, 0))
+	l.DebugContext(context.Background(), "shown")
+	require.Contains(t, buf.String(), `"msg":"shown"`)
+	require.Contains(t, buf.String(), `"level":"debug"`)
+}
+
+func TestWithDynamicLevel(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Level: LevelWarning}
+
+	require.NoError(t, WithDynamicLevel()(cfg))
+	require.NotNil(t, cfg.LevelController)
+	require.Equal(t, LevelWarning, cfg.LevelController.Level())
+
+	ctl := cfg.LevelController
+
+	require.NoError(t, WithDynamicLevel()(cfg))
+	require.Same(t, ctl, cfg.LevelController, "an existing controller must be kept")
+
+	require.NoError(t, WithLevel(LevelDebug)(cfg))
+	require.Equal(t, LevelDebug, ctl.Level())
+
+	require.NoError(t, WithLevelStr("error")(cfg))
+	require.Equal(t, LevelError, ctl.Level())
+}
+
+func TestWithLevelController(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+
+	require.Error(t, WithLevelController(nil)(cfg))
+
+	ctl := NewLevelController(LevelInfo)
+
+	require.NoError(t, WithLevelController(ctl)(cfg))
+	require.Same(t, ctl, cfg.LevelController)
+}
@@ -9,6 +9,16 @@ It wraps slog with a composable configuration model:
   - [ParseLevel]/[ParseFormat] and [ValidLevel]/[ValidFormat] convert and
     validate runtime configuration values.
   - [NewSlogHookHandler] allows interception of log messages via [HookFunc].
+  - [NewSlogSamplingHandler] samples and collapses repeated records (see
+    [WithSampling]).
+  - [NewSlogRedactHandler] redacts the sensitive attribute values with
+    pkg/redact (see [WithRedaction]).
+  - [NewSlogOTelHandler] exports the records as OpenTelemetry log records,
+    correlated with the spans (see [WithOTel]).
+  - [NewFileWriter] writes to a log file rotated by size and time (see
+    [WithFileOutput]).
+  - [NewAsyncWriter] decouples the loggers from a slow output (see
+    [WithAsyncWriter] and [Config.Close]).
   - [NewSlogWriter] and [NewLogFromSlog] bridge standard log.Logger output
     into slog.
 
@@ -47,6 +47,38 @@ func isNilWriter(w io.Writer) bool {
 	}
 }
 
+// WithFileOutput makes NewConfig write to a rotating log file (see
+// [NewFileWriter]) instead of the output writer. Config.Close must be called on
+// exit to close it.
+func WithFileOutput(fc FileWriterConfig) Option {
+	return func(cfg *Config) error {
+		err := fc.Validate()
+		if err != nil {
+			return err
+		}
+
+		cfg.fileWriter = &fc
+
+		return nil
+	}
+}
+
+// WithAsyncWriter makes NewConfig wrap the output writer in an [AsyncWriter],
+// so a slow or blocked output does not stall the loggers. Config.Close must be
+// called on exit to write the buffered records.
+func WithAsyncWriter(ac AsyncWriterConfig) Option {
+	return func(cfg *Config) error {
+		err := ac.Validate()
+		if err != nil {
+			return err
+		}
+
+		cfg.asyncWriter = &ac
+
+		return nil
+	}
+}
+
 // WithFormat overrides the log output format (JSON, console, or discard).
 func WithFormat(f LogFormat) Option {
 	return func(cfg *Config) error {
@@ -81,7 +113,7 @@ func WithLevel(l LogLevel) Option {
 			return errors.New("invalid log level")
 		}
 
-		cfg.Level = l
+		cfg.setLevel(l)
 
 		return nil
 	}
@@ -95,7 +127,76 @@ func WithLevelStr(l string) Option {
 			return err
 		}
 
-		cfg.Level = ll
+		cfg.setLevel(ll)
+
+		return nil
+	}
+}
+
+// WithDynamicLevel enables the runtime level changes: a [LevelController]
+// initialized with the configured level is set in Config.LevelController, to
+// be exposed to an operator (e.g. via the httpserver log level route).
+func WithDynamicLevel() Option {
+	return func(cfg *Config) error {
+		if cfg.LevelController == nil {
+			cfg.LevelController = NewLevelController(cfg.Level)
+		}
+
+		return nil
+	}
+}
+
+// WithSampling enables the sampling of the records with the same level and
+// message (see [NewSlogSamplingHandler]), so a hot path cannot flood the log
+// pipeline.
+func WithSampling(sc SamplingConfig) Option {
+	return func(cfg *Config) error {
+		err := sc.Validate()
+		if err != nil {
+			return err
+		}
+
+		cfg.Sampling = &sc
+
+		return nil
+	}
+}
+
+// WithRedaction enables the redaction of the sensitive attribute values (see
+// [NewSlogRedactHandler]), common attributes included.
+func WithRedaction(rc RedactConfig) Option {
+	return func(cfg *Config) error {
+		err := rc.Validate()
+		if err != nil {
+			return err
+		}
+
+		cfg.Redaction = &rc
+
+		return nil
+	}
+}
+
+// WithOTel exports the records as OpenTelemetry log records (see
+// [NewSlogOTelHandler]), instead of writing them to the output, or in addition
+// to it with OTelConfig.Tee.
+func WithOTel(oc OTelConfig) Option {
+	return func(cfg *Config) error {
+		cfg.OTel = &oc
+		return nil
+	}
+}
+
+// WithLevelController sets the LevelController deciding the enabled levels at
+// runtime, e.g. to share it between configurations. Its level is updated by
+// the following WithLevel or WithLevelStr options.
+func WithLevelController(ctl *LevelController) Option {
+	return func(cfg *Config) error {
+		if ctl == nil {
+			return errors.New("nil level controller")
+		}
+
+		cfg.LevelController = ctl
 
 		return nil
 	}
@@ -0,0 +1,162 @@
+package logutil
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+// DefaultOTelScope is the OpenTelemetry instrumentation scope of the log
+// records when OTelConfig.Name is empty.
+const DefaultOTelScope = "github.com/tecnickcom/nurago/pkg/logutil"
+
+// otelSeverityOffset is the offset the otelslog bridge adds to a slog level to
+// get an OpenTelemetry severity (slog.LevelDebug to log.SeverityDebug).
+const otelSeverityOffset = LogLevel(log.SeverityDebug) - LevelDebug
+
+// OTelConfig configures the export of the records as OpenTelemetry log records
+// (see [NewSlogOTelHandler]).
+type OTelConfig struct {
+	// LoggerProvider creates the OpenTelemetry logger. Nil means the global
+	// provider (see go.opentelemetry.io/otel/log/global), e.g. the one
+	// installed by the metrics/opentel client.
+	LoggerProvider log.LoggerProvider
+
+	// Name is the instrumentation scope of the records. Empty means
+	// DefaultOTelScope.
+	Name string
+
+	// Tee writes the records to the configured output (Config.Out) too. When
+	// false, they are only exported.
+	Tee bool
+}
+
+// NewSlogOTelHandler returns a slog.Handler exporting the records enabled at
+// level as OpenTelemetry log records, through the logger provider configured
+// by oc. The trace and span IDs of the context passed to the logger (e.g. with
+// slog.Logger.InfoContext) are attached to the records by the provider, so the
+// backend can correlate the logs with the spans.
+//
+// The attributes are converted as documented by the otelslog bridge, while the
+// syslog-style levels map to the closest OpenTelemetry severities (see
+// OTelSeverity), with their names as severity text. A nil level enables every
+// level.
+func NewSlogOTelHandler(oc OTelConfig, level slog.Leveler) slog.Handler {
+	lp := oc.LoggerProvider
+	if lp == nil {
+		lp = global.GetLoggerProvider()
+	}
+
+	name := oc.Name
+	if name == "" {
+		name = DefaultOTelScope
+	}
+
+	var h slog.Handler = otelslog.NewHandler(name, otelslog.WithLoggerProvider(&otelLevelLoggerProvider{lp: lp}))
+
+	if level != nil {
+		h = &slogMinLevelHandler{Handler: h, level: level}
+	}
+
+	return h
+}
+
+// OTelSeverity returns the OpenTelemetry severity of a log level: TRACE, DEBUG,
+// INFO, INFO2 (Notice), WARN, ERROR, FATAL (Critical), FATAL2 (Alert) and
+// FATAL4 (Emergency). A level between two known ones maps as the lower one.
+func OTelSeverity(l LogLevel) log.Severity {
+	switch {
+	case l >= LevelEmergency:
+		return log.SeverityFatal4
+	case l >= LevelAlert:
+		return log.SeverityFatal2
+	case l >= LevelCritical:
+		return log.SeverityFatal
+	case l >= LevelError:
+		return log.SeverityError
+	case l >= LevelWarning:
+		return log.SeverityWarn
+	case l >= LevelNotice:
+		return log.SeverityInfo2
+	case l >= LevelInfo:
+		return log.SeverityInfo
+	case l >= LevelDebug:
+		return log.SeverityDebug
+	default:
+		return log.SeverityTrace
+	}
+}
+
+// otelLevelLoggerProvider is a log.LoggerProvider whose loggers map the
+// severities set by the otelslog bridge, the slog levels plus a fixed offset,
+// to the ones of the syslog-style levels (see OTelSeverity).
+type otelLevelLoggerProvider struct {
+	embedded.LoggerProvider
+
+	lp log.LoggerProvider
+}
+
+// Logger returns the logger of the wrapped provider, with the severities mapped.
+func (p *otelLevelLoggerProvider) Logger(name string, opts ...log.LoggerOption) log.Logger {
+	return &otelLevelLogger{Logger: p.lp.Logger(name, opts...)}
+}
+
+// otelLevelLogger is a log.Logger mapping the severities of the otelslog bridge.
+type otelLevelLogger struct {
+	log.Logger
+}
+
+// Emit emits the record with the severity and severity text of its level.
+func (l *otelLevelLogger) Emit(ctx context.Context, record log.Record) {
+	level := LogLevel(record.Severity()) - otelSeverityOffset
+
+	record.SetSeverity(OTelSeverity(level))
+	record.SetSeverityText(LevelName(level))
+
+	l.Logger.Emit(ctx, record)
+}
+
+// Enabled reports whether the wrapped logger is enabled at the mapped severity.
+func (l *otelLevelLogger) Enabled(ctx context.Context, param log.EnabledParameters) bool {
+	param.Severity = OTelSeverity(LogLevel(param.Severity) - otelSeverityOffset)
+
+	return l.Logger.Enabled(ctx, param)
+}
+
+// slogMinLevelHandler is a slog.Handler enabled only at or above a level, as
+// the standard library handlers are with slog.HandlerOptions.Level.
+type slogMinLevelHandler struct {
+	slog.Handler
+
+	level slog.Leveler
+}
+
+// Enabled reports whether l is at or above the minimum level and the wrapped
+// handler is enabled.
+func (h *slogMinLevelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.level.Level() && h.Handler.Enabled(ctx, l)
+}
+
+// WithAttrs returns a new slogMinLevelHandler whose wrapped handler carries
+// the given attributes. An empty attribute list returns the receiver.
+func (h *slogMinLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	return &slogMinLevelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+// WithGroup returns a new slogMinLevelHandler whose wrapped handler opens the
+// given group. An empty group name returns the receiver.
+func (h *slogMinLevelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogMinLevelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
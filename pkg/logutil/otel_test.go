@@ -0,0 +1,261 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingExporter is an sdklog.Exporter keeping the exported records.
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+
+	return nil
+}
+
+func (*recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (*recordingExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (e *recordingExporter) exported() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]sdklog.Record(nil), e.records...)
+}
+
+// newTestLoggerProvider returns a logger provider exporting synchronously to exp.
+func newTestLoggerProvider(exp sdklog.Exporter) *sdklog.LoggerProvider {
+	return sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp)))
+}
+
+// recordAttrs returns the attributes of r as strings.
+func recordAttrs(r *sdklog.Record) map[string]string {
+	attrs := map[string]string{}
+
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+
+	return attrs
+}
+
+func testSpanContext(t *testing.T) context.Context {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+
+	spanID, err := trace.SpanIDFromHex("0102030405060708")
+	require.NoError(t, err)
+
+	return trace.ContextWithSpanContext(t.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestNewSlogOTelHandler(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+
+	h := NewSlogOTelHandler(OTelConfig{LoggerProvider: newTestLoggerProvider(exp)}, LevelInfo)
+
+	require.Same(t, h, h.WithAttrs(nil))
+	require.Same(t, h, h.WithGroup(""))
+
+	l := slog.New(h).With("component", "test").WithGroup("g")
+
+	ctx := testSpanContext(t)
+
+	l.DebugContext(ctx, "disabled")
+	l.ErrorContext(ctx, "exported", "k", "v")
+
+	records := exp.exported()
+	require.Len(t, records, 1)
+
+	r := records[0]
+	require.Equal(t, "exported", r.Body().AsString())
+	require.Equal(t, log.SeverityError, r.Severity())
+	require.Equal(t, DefaultOTelScope, r.InstrumentationScope().Name)
+	require.Equal(t, trace.SpanContextFromContext(ctx).TraceID(), r.TraceID())
+	require.Equal(t, trace.SpanContextFromContext(ctx).SpanID(), r.SpanID())
+	require.Equal(t, "test", recordAttrs(&r)["component"])
+	require.Contains(t, recordAttrs(&r)["g"], "k")
+}
+
+func TestNewSlogOTelHandler_Defaults(t *testing.T) { //nolint:paralleltest // mutates the global logger provider.
+	exp := &recordingExporter{}
+
+	prev := global.GetLoggerProvider()
+
+	defer global.SetLoggerProvider(prev)
+
+	global.SetLoggerProvider(newTestLoggerProvider(exp))
+
+	l := slog.New(NewSlogOTelHandler(OTelConfig{Name: "scope"}, nil))
+	l.Log(t.Context(), LevelTrace, "trace")
+
+	records := exp.exported()
+	require.Len(t, records, 1)
+	require.Equal(t, "scope", records[0].InstrumentationScope().Name)
+	require.Equal(t, log.SeverityTrace1, records[0].Severity())
+}
+
+func TestOTelSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level LogLevel
+		want  log.Severity
+	}{
+		{level: LevelEmergency + 1, want: log.SeverityFatal4},
+		{level: LevelEmergency, want: log.SeverityFatal4},
+		{level: LevelAlert, want: log.SeverityFatal2},
+		{level: LevelCritical, want: log.SeverityFatal},
+		{level: LevelError, want: log.SeverityError},
+		{level: LevelWarning + 1, want: log.SeverityWarn},
+		{level: LevelWarning, want: log.SeverityWarn},
+		{level: LevelNotice, want: log.SeverityInfo2},
+		{level: LevelInfo, want: log.SeverityInfo},
+		{level: LevelDebug, want: log.SeverityDebug},
+		{level: LevelTrace, want: log.SeverityTrace},
+		{level: MinLevel, want: log.SeverityTrace},
+	}
+
+	for _, tt := range tests {
+		t.Run(LevelName(tt.level), func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, OTelSeverity(tt.level))
+		})
+	}
+}
+
+func TestNewSlogOTelHandler_Severity(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+
+	l := slog.New(NewSlogOTelHandler(OTelConfig{LoggerProvider: newTestLoggerProvider(exp)}, MinLevel))
+
+	for _, level := range []LogLevel{LevelEmergency, LevelCritical, LevelNotice, LevelDebug} {
+		l.Log(t.Context(), level, "m")
+	}
+
+	records := exp.exported()
+	require.Len(t, records, 4)
+
+	got := make([]string, 0, len(records))
+
+	for _, r := range records {
+		got = append(got, r.Severity().String()+"/"+r.SeverityText())
+	}
+
+	require.Equal(t, []string{"FATAL4/emergency", "FATAL/critical", "INFO2/notice", "DEBUG/debug"}, got)
+}
+
+func TestConfig_SlogHandlerOTel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		format     LogFormat
+		tee        bool
+		wantOutput bool
+	}{
+		{
+			name:   "export only",
+			format: FormatJSON,
+		},
+		{
+			name:       "tee",
+			format:     FormatJSON,
+			tee:        true,
+			wantOutput: true,
+		},
+		{
+			name:   "no output format",
+			format: FormatNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			exp := &recordingExporter{}
+
+			cfg, err := NewConfig(
+				WithOutWriter(&buf),
+				WithFormat(tt.format),
+				WithLevel(LevelWarning),
+				WithCommonAttr(slog.String("program", "test")),
+				WithRedaction(RedactConfig{Keys: []string{"pin"}}),
+				WithOTel(OTelConfig{LoggerProvider: newTestLoggerProvider(exp), Tee: tt.tee}),
+			)
+			require.NoError(t, err)
+
+			l := cfg.SlogLogger()
+
+			ctx := testSpanContext(t)
+
+			l.InfoContext(ctx, "disabled")
+			l.WarnContext(ctx, "enabled", "pin", 1234)
+
+			records := exp.exported()
+			require.Len(t, records, 1)
+			require.Equal(t, "enabled", records[0].Body().AsString())
+			require.Equal(t, trace.SpanContextFromContext(ctx).TraceID(), records[0].TraceID())
+
+			attrs := recordAttrs(&records[0])
+			require.Equal(t, "test", attrs["program"])
+			require.Equal(t, "***", attrs["pin"])
+
+			if tt.wantOutput {
+				require.Contains(t, buf.String(), `"msg":"enabled"`)
+				require.NotContains(t, buf.String(), "disabled")
+			} else {
+				require.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestWithOTel(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+
+	oc := OTelConfig{Name: "scope", Tee: true}
+
+	require.NoError(t, WithOTel(oc)(cfg))
+	require.Equal(t, &oc, cfg.OTel)
+}
@@ -0,0 +1,226 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/redact"
+)
+
+// RedactConfig configures a [SlogRedactHandler].
+type RedactConfig struct {
+	// Redactor redacts the attribute values. Nil means redact.Default().
+	Redactor *redact.Redactor
+
+	// Keys are the attribute keys whose values are always replaced by Marker,
+	// matched case-insensitively at any depth, in addition to the keys the
+	// Redactor classifies as sensitive (see redact.Redactor.SensitiveKey). The
+	// value of a Keys group is replaced as a whole.
+	Keys []string
+
+	// Marker replaces the values of the sensitive keys. Empty means the marker
+	// of the Redactor.
+	Marker string
+}
+
+// Validate reports whether the configuration is usable.
+func (c *RedactConfig) Validate() error {
+	if slices.Contains(c.Keys, "") {
+		return errors.New("empty redaction key")
+	}
+
+	return nil
+}
+
+// attrRedactor is the redaction shared by a SlogRedactHandler and the handlers
+// derived from it.
+type attrRedactor struct {
+	re     *redact.Redactor
+	keys   []string
+	marker slog.Value
+}
+
+// newAttrRedactor returns the attrRedactor configured by cfg.
+func newAttrRedactor(cfg RedactConfig) *attrRedactor {
+	re := cfg.Redactor
+	if re == nil {
+		re = redact.Default()
+	}
+
+	marker := cfg.Marker
+	if marker == "" {
+		marker = re.Marker()
+	}
+
+	return &attrRedactor{
+		re:     re,
+		keys:   slices.Clone(cfg.Keys),
+		marker: slog.StringValue(marker),
+	}
+}
+
+// attrs returns attrs redacted, and whether any of them changed. The input is
+// never modified.
+func (r *attrRedactor) attrs(attrs []Attr) ([]Attr, bool) {
+	var out []Attr
+
+	for i, a := range attrs {
+		ra, changed := r.attr(a)
+		if changed && out == nil {
+			out = slices.Clone(attrs)
+		}
+
+		if out != nil {
+			out[i] = ra
+		}
+	}
+
+	if out == nil {
+		return attrs, false
+	}
+
+	return out, true
+}
+
+// attr returns a redacted: the marker for a denied key, or for a sensitive key
+// of a non-group value, otherwise its resolved value with the strings, byte
+// slices and error messages redacted, groups included. It reports whether the
+// attribute changed.
+func (r *attrRedactor) attr(a Attr) (Attr, bool) {
+	if r.denied(a.Key) {
+		return slog.Attr{Key: a.Key, Value: r.marker}, true
+	}
+
+	v := a.Value.Resolve()
+	changed := a.Value.Kind() == slog.KindLogValuer
+
+	if v.Kind() != slog.KindGroup && a.Key != "" && r.re.SensitiveKey(a.Key) {
+		return slog.Attr{Key: a.Key, Value: r.marker}, true
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case slog.KindString:
+		s := v.String()
+		if rs := r.re.String(s); rs != s {
+			v, changed = slog.StringValue(rs), true
+		}
+	case slog.KindGroup:
+		if attrs, ok := r.attrs(v.Group()); ok {
+			v, changed = slog.GroupValue(attrs...), true
+		}
+	case slog.KindAny:
+		if rv, ok := r.any(v.Any()); ok {
+			v, changed = rv, true
+		}
+	}
+
+	return slog.Attr{Key: a.Key, Value: v}, changed
+}
+
+// any returns the redaction of a byte slice or an error, and whether it
+// changed. A typed-nil error is left to the sanitizing handler.
+func (r *attrRedactor) any(x any) (slog.Value, bool) {
+	switch x := x.(type) {
+	case []byte:
+		if rb := r.re.Bytes(x); !bytes.Equal(rb, x) {
+			return slog.AnyValue(rb), true
+		}
+	case error:
+		if nilPointerError(x) {
+			return slog.Value{}, false
+		}
+
+		s := x.Error()
+		if rs := r.re.String(s); rs != s {
+			return slog.StringValue(rs), true
+		}
+	}
+
+	return slog.Value{}, false
+}
+
+// denied reports whether the value of key is always replaced by the marker.
+func (r *attrRedactor) denied(key string) bool {
+	return slices.ContainsFunc(r.keys, func(k string) bool {
+		return strings.EqualFold(k, key)
+	})
+}
+
+// SlogRedactHandler is a slog.Handler that replaces the values of the
+// sensitive attribute keys, and redacts the sensitive data of the other
+// attribute values (strings, byte slices, error messages, nested groups and
+// the LogValuer results) with a [redact.Redactor], so the secrets are kept out
+// of the output whatever the callers log. The record message is not redacted.
+//
+// The attributes added with WithAttrs are redacted once, when added.
+type SlogRedactHandler struct {
+	slog.Handler
+
+	r *attrRedactor
+}
+
+// NewSlogRedactHandler wraps h with the redaction configured by cfg, which
+// must be valid (see [RedactConfig.Validate]). A nil h falls back to the
+// handler of the current slog.Default, captured now.
+func NewSlogRedactHandler(h slog.Handler, cfg RedactConfig) *SlogRedactHandler {
+	if h == nil {
+		h = slog.Default().Handler()
+	}
+
+	return &SlogRedactHandler{
+		Handler: h,
+		r:       newAttrRedactor(cfg),
+	}
+}
+
+// Handle passes the record to the underlying handler with its attributes
+// redacted, rebuilding it only when an attribute changes.
+func (h *SlogRedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := make([]Attr, 0, record.NumAttrs())
+
+	record.Attrs(func(a Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	attrs, changed := h.r.attrs(attrs)
+	if changed {
+		// The record may be shared with other handlers: rebuild it.
+		record = slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+		record.AddAttrs(attrs...)
+	}
+
+	return h.Handler.Handle(ctx, record) //nolint:wrapcheck
+}
+
+// WithAttrs returns a new SlogRedactHandler whose underlying handler carries
+// the given attributes, redacted. An empty attribute list returns the receiver.
+func (h *SlogRedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	attrs, _ = h.r.attrs(attrs)
+
+	return &SlogRedactHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		r:       h.r,
+	}
+}
+
+// WithGroup returns a new SlogRedactHandler whose underlying handler opens the
+// given group. An empty group name returns the receiver.
+func (h *SlogRedactHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogRedactHandler{
+		Handler: h.Handler.WithGroup(name),
+		r:       h.r,
+	}
+}
@@ -0,0 +1,363 @@
+package logutil
+
+import (
+	"context"
+	"errors"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RepeatedKey is the record attribute key carrying the number of identical
+// records (same level and message) dropped by a [SlogSamplingHandler] since the
+// previous one it passed.
+const RepeatedKey = "repeated"
+
+const (
+	// samplingBuckets is the number of lock stripes of a sampler: the counters
+	// are spread among them by the hash of their level and message.
+	samplingBuckets = 4096
+
+	// samplingBucketKeys is the maximum number of counters of a bucket, so the
+	// memory is bounded whatever the messages.
+	samplingBucketKeys = 16
+)
+
+// SamplingRule is the sampling of the records with the same level and message
+// in each [SamplingConfig.Interval].
+//
+// The first First records pass, then one every Thereafter (none when zero).
+// For example, {First: 1} collapses identical records to one per interval,
+// and {First: 10, Thereafter: 100} passes the first ten, then 1-in-100. The
+// zero value passes every record.
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SamplingConfig configures a [SlogSamplingHandler].
+type SamplingConfig struct {
+	// Interval is the period the records are counted over. It is required.
+	Interval time.Duration
+
+	// Rule is the sampling of the levels without a LevelRules entry.
+	Rule SamplingRule
+
+	// LevelRules are the sampling rules by level. A zero SamplingRule exempts
+	// a level from the sampling.
+	LevelRules map[LogLevel]SamplingRule
+
+	// DropFn, when set, is called for every dropped record, e.g. to count
+	// them with a metrics client.
+	DropFn HookFunc
+}
+
+// Validate reports whether the configuration is usable.
+func (c *SamplingConfig) Validate() error {
+	if c.Interval <= 0 {
+		return errors.New("sampling interval must be positive")
+	}
+
+	if !c.Rule.valid() {
+		return errors.New("invalid sampling rule")
+	}
+
+	for _, r := range c.LevelRules {
+		if !r.valid() {
+			return errors.New("invalid sampling level rule")
+		}
+	}
+
+	return nil
+}
+
+// valid reports whether the rule has no negative value.
+func (r SamplingRule) valid() bool {
+	return r.First >= 0 && r.Thereafter >= 0
+}
+
+// passes reports whether the n-th (1-based) record of an interval passes.
+func (r SamplingRule) passes(n int) bool {
+	if n <= r.First {
+		return true
+	}
+
+	return r.Thereafter > 0 && (n-r.First-1)%r.Thereafter == 0
+}
+
+// samplingKey identifies the records sampled together.
+type samplingKey struct {
+	level   LogLevel
+	message string
+}
+
+// samplingCounter counts the records of a key in the current interval.
+type samplingCounter struct {
+	resetAt time.Time
+	n       int
+	dropped uint64 // dropped since the last passed record
+}
+
+// samplingBucket holds the counters of the keys sharing a hash stripe.
+type samplingBucket struct {
+	mu       sync.Mutex
+	counters map[samplingKey]*samplingCounter
+}
+
+// samplingSummary is the number of records of a key dropped and not yet
+// reported by a passed record, when its counter is discarded.
+type samplingSummary struct {
+	key     samplingKey
+	dropped uint64
+}
+
+// sampler is the state shared by a SlogSamplingHandler and the handlers
+// derived from it.
+type sampler struct {
+	cfg     SamplingConfig
+	handler slog.Handler // root handler receiving the summary records
+	seed    maphash.Seed
+	nowFn   func() time.Time
+	sweepAt atomic.Int64 // UnixNano time of the next sweep
+	buckets [samplingBuckets]samplingBucket
+}
+
+// sample counts a record of key at now, and reports whether it passes together
+// with the number of records of the key dropped before it. The summary of a
+// counter evicted to make room for the key is returned, if any.
+func (s *sampler) sample(rule SamplingRule, key samplingKey, now time.Time) (bool, uint64, *samplingSummary) {
+	var h maphash.Hash
+
+	h.SetSeed(s.seed)
+	_ = h.WriteByte(byte(key.level))
+	_, _ = h.WriteString(key.message)
+
+	b := &s.buckets[h.Sum64()%samplingBuckets]
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var evicted *samplingSummary
+
+	c, ok := b.counters[key]
+	if !ok {
+		if b.counters == nil {
+			b.counters = make(map[samplingKey]*samplingCounter)
+		}
+
+		if len(b.counters) >= samplingBucketKeys {
+			evicted = b.evict()
+		}
+
+		c = &samplingCounter{}
+		b.counters[key] = c
+	}
+
+	if !now.Before(c.resetAt) {
+		c.resetAt = now.Add(s.cfg.Interval)
+		c.n = 0
+	}
+
+	c.n++
+
+	if !rule.passes(c.n) {
+		c.dropped++
+		return false, 0, evicted
+	}
+
+	dropped := c.dropped
+	c.dropped = 0
+
+	return true, dropped, evicted
+}
+
+// evict removes the counter of the bucket with the oldest interval, and
+// returns its summary when it has unreported dropped records.
+//
+// NOTE: it must be called with the bucket lock held.
+func (b *samplingBucket) evict() *samplingSummary {
+	var (
+		oldest samplingKey
+		found  *samplingCounter
+	)
+
+	for k, c := range b.counters {
+		if found == nil || c.resetAt.Before(found.resetAt) {
+			oldest, found = k, c
+		}
+	}
+
+	delete(b.counters, oldest)
+
+	if found.dropped == 0 {
+		return nil
+	}
+
+	return &samplingSummary{key: oldest, dropped: found.dropped}
+}
+
+// sweep removes, at most once per interval, the counters of the keys without
+// records for a whole interval, and returns the summaries of those with
+// unreported dropped records.
+func (s *sampler) sweep(now time.Time) []samplingSummary {
+	at := s.sweepAt.Load()
+	if now.UnixNano() < at || !s.sweepAt.CompareAndSwap(at, now.Add(s.cfg.Interval).UnixNano()) {
+		return nil
+	}
+
+	idle := now.Add(-s.cfg.Interval)
+
+	var summaries []samplingSummary
+
+	for i := range s.buckets {
+		b := &s.buckets[i]
+
+		b.mu.Lock()
+
+		for k, c := range b.counters {
+			if c.resetAt.After(idle) {
+				continue
+			}
+
+			delete(b.counters, k)
+
+			if c.dropped > 0 {
+				summaries = append(summaries, samplingSummary{key: k, dropped: c.dropped})
+			}
+		}
+
+		b.mu.Unlock()
+	}
+
+	return summaries
+}
+
+// report passes a summary record for each summary to the root handler: a
+// record with the level and message of the key, and the number of dropped
+// records as the [RepeatedKey] attribute.
+func (s *sampler) report(ctx context.Context, now time.Time, summaries ...samplingSummary) error {
+	var errs []error
+
+	for _, sum := range summaries {
+		record := slog.NewRecord(now, sum.key.level, sum.key.message, 0)
+		record.AddAttrs(slog.Uint64(RepeatedKey, sum.dropped))
+
+		errs = append(errs, s.handler.Handle(ctx, record))
+	}
+
+	return errors.Join(errs...)
+}
+
+// SlogSamplingHandler is a slog.Handler that samples the records with the same
+// level and message (see [SamplingRule]), so a hot path cannot flood the log
+// pipeline. The first record passed after some were dropped carries their
+// number (see [RepeatedKey]). When a key has no records for a whole interval,
+// or its counter is evicted to bound the memory, the number of its dropped
+// records not yet reported is passed in a summary record with the same level
+// and message, without the attributes of the dropped records.
+//
+// The counters are shared by the handlers derived with WithAttrs and WithGroup:
+// the records of every logger derived from the same handler are sampled
+// together.
+type SlogSamplingHandler struct {
+	slog.Handler
+
+	s *sampler
+}
+
+// NewSlogSamplingHandler wraps h with the sampling configured by cfg, which
+// must be valid (see [SamplingConfig.Validate]). A nil h falls back to the
+// handler of the current slog.Default, captured now.
+func NewSlogSamplingHandler(h slog.Handler, cfg SamplingConfig) *SlogSamplingHandler {
+	if h == nil {
+		h = slog.Default().Handler()
+	}
+
+	return &SlogSamplingHandler{
+		Handler: h,
+		s: &sampler{
+			cfg:     cfg,
+			handler: h,
+			seed:    maphash.MakeSeed(),
+			nowFn:   time.Now,
+		},
+	}
+}
+
+// Handle passes the record to the underlying handler when it is sampled,
+// adding the [RepeatedKey] attribute when identical records were dropped
+// before it, and otherwise calls the DropFn. The attribute nests in the groups
+// open on the handler, as the record attributes do. The pending summary
+// records are passed first.
+func (h *SlogSamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	rule, ok := h.s.cfg.LevelRules[record.Level]
+	if !ok {
+		rule = h.s.cfg.Rule
+	}
+
+	if rule == (SamplingRule{}) {
+		return h.Handler.Handle(ctx, record) //nolint:wrapcheck
+	}
+
+	now := h.s.nowFn()
+
+	summaries := h.s.sweep(now)
+
+	pass, dropped, evicted := h.s.sample(rule, samplingKey{level: record.Level, message: record.Message}, now)
+	if evicted != nil {
+		summaries = append(summaries, *evicted)
+	}
+
+	serr := h.s.report(ctx, now, summaries...)
+
+	if !pass {
+		if h.s.cfg.DropFn != nil {
+			h.s.cfg.DropFn(record.Level, record.Message)
+		}
+
+		return serr
+	}
+
+	if dropped > 0 {
+		// The record may be shared with other handlers: add to a copy.
+		record = record.Clone()
+		record.AddAttrs(slog.Uint64(RepeatedKey, dropped))
+	}
+
+	err := h.Handler.Handle(ctx, record)
+	if serr != nil {
+		return errors.Join(serr, err)
+	}
+
+	return err //nolint:wrapcheck
+}
+
+// WithAttrs returns a new SlogSamplingHandler whose underlying handler carries
+// the given attributes, sharing the sampling counters. An empty attribute list
+// returns the receiver.
+func (h *SlogSamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	return &SlogSamplingHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		s:       h.s,
+	}
+}
+
+// WithGroup returns a new SlogSamplingHandler whose underlying handler opens
+// the given group, sharing the sampling counters. An empty group name returns
+// the receiver.
+func (h *SlogSamplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogSamplingHandler{
+		Handler: h.Handler.WithGroup(name),
+		s:       h.s,
+	}
+}
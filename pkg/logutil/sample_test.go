@@ -0,0 +1,420 @@
+package logutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/maphash"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     SamplingConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg: SamplingConfig{
+				Interval:   time.Second,
+				Rule:       SamplingRule{First: 1},
+				LevelRules: map[LogLevel]SamplingRule{LevelError: {}},
+			},
+		},
+		{
+			name:    "missing interval",
+			cfg:     SamplingConfig{Rule: SamplingRule{First: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "negative rule",
+			cfg:     SamplingConfig{Interval: time.Second, Rule: SamplingRule{First: -1}},
+			wantErr: true,
+		},
+		{
+			name: "negative level rule",
+			cfg: SamplingConfig{
+				Interval:   time.Second,
+				LevelRules: map[LogLevel]SamplingRule{LevelError: {Thereafter: -1}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSamplingRule_passes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rule SamplingRule
+		want string // pass (1) or drop (0) for the records 1 to 12
+	}{
+		{name: "first only", rule: SamplingRule{First: 2}, want: "110000000000"},
+		{name: "thereafter only", rule: SamplingRule{Thereafter: 5}, want: "100001000010"},
+		{name: "first and thereafter", rule: SamplingRule{First: 3, Thereafter: 4}, want: "111100010001"},
+		{name: "every one", rule: SamplingRule{First: 1, Thereafter: 1}, want: "111111111111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got strings.Builder
+
+			for n := 1; n <= 12; n++ {
+				if tt.rule.passes(n) {
+					got.WriteByte('1')
+				} else {
+					got.WriteByte('0')
+				}
+			}
+
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewSlogSamplingHandler_NilHandler(t *testing.T) { //nolint:paralleltest // mutates the slog default.
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+
+	defer slog.SetDefault(prev)
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	h := NewSlogSamplingHandler(nil, SamplingConfig{Interval: time.Second, Rule: SamplingRule{First: 1}})
+
+	require.NotPanics(t, func() { slog.New(h).Info("m") })
+	require.Contains(t, buf.String(), `"msg":"m"`)
+}
+
+// decodeLines decodes the JSON lines of buf.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+
+	for line := range strings.Lines(buf.String()) {
+		var m map[string]any
+
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+
+		lines = append(lines, m)
+	}
+
+	return lines
+}
+
+func TestSlogSamplingHandler_Handle(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf     bytes.Buffer
+		dropped atomic.Int64
+	)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	h := NewSlogSamplingHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: LevelDebug}), SamplingConfig{
+		Interval: time.Minute,
+		Rule:     SamplingRule{First: 2, Thereafter: 3},
+		LevelRules: map[LogLevel]SamplingRule{
+			LevelError: {},         // exempt
+			LevelDebug: {First: 1}, // collapse
+		},
+		DropFn: func(level LogLevel, message string) {
+			require.NotEqual(t, LevelError, level)
+			require.Contains(t, []string{"hot", "debug"}, message)
+			dropped.Add(1)
+		},
+	})
+	h.s.nowFn = func() time.Time { return now }
+
+	l := slog.New(h)
+
+	for range 10 {
+		l.Info("hot")
+		l.Error("exempt")
+		l.Debug("debug")
+	}
+
+	l.Info("cold")
+
+	// A new interval passes the first record again, with the count of the dropped ones.
+	now = now.Add(time.Minute)
+
+	l.Debug("debug")
+	l.Info("hot")
+
+	count := map[string]int{}
+	repeated := map[string][]float64{}
+
+	for _, m := range decodeLines(t, &buf) {
+		msg := m["msg"].(string) //nolint:forcetypeassert
+
+		count[msg]++
+
+		if r, ok := m[RepeatedKey]; ok {
+			repeated[msg] = append(repeated[msg], r.(float64)) //nolint:forcetypeassert
+		}
+	}
+
+	// hot: records 1, 2, 3, 6 and 9 of the first interval, then the first of the second.
+	require.Equal(t, map[string]int{"hot": 6, "cold": 1, "exempt": 10, "debug": 2}, count)
+	require.Equal(t, map[string][]float64{
+		"hot":   {2, 2, 1},
+		"debug": {9},
+	}, repeated)
+	require.Equal(t, int64(5+9), dropped.Load())
+}
+
+// collidingMessages returns n distinct messages of level sharing the sampler
+// bucket of the first one.
+func collidingMessages(s *sampler, level LogLevel, n int) []string {
+	bucket := func(msg string) uint64 {
+		var h maphash.Hash
+
+		h.SetSeed(s.seed)
+		_ = h.WriteByte(byte(level))
+		_, _ = h.WriteString(msg)
+
+		return h.Sum64() % samplingBuckets
+	}
+
+	msgs := []string{"m0"}
+	want := bucket(msgs[0])
+
+	for i := 1; len(msgs) < n; i++ {
+		msg := "m" + strconv.Itoa(i)
+		if bucket(msg) == want {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs
+}
+
+func TestSlogSamplingHandler_ExactKeys(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	h := NewSlogSamplingHandler(slog.NewJSONHandler(&buf, nil), SamplingConfig{
+		Interval: time.Hour,
+		Rule:     SamplingRule{First: 1},
+	})
+
+	l := slog.New(h)
+	msgs := collidingMessages(h.s, LevelInfo, 2)
+
+	// Keys sharing a bucket, or a message, are counted separately.
+	for range 3 {
+		l.Info(msgs[0])
+		l.Info(msgs[1])
+		l.Warn(msgs[1])
+	}
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 3)
+	require.Equal(t, msgs[0], lines[0]["msg"])
+	require.Equal(t, msgs[1], lines[1]["msg"])
+	require.Equal(t, "WARN", lines[2]["level"])
+}
+
+func TestSlogSamplingHandler_EvictionSummary(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	h := NewSlogSamplingHandler(slog.NewJSONHandler(&buf, nil), SamplingConfig{
+		Interval: time.Hour,
+		Rule:     SamplingRule{First: 1},
+	})
+	h.s.nowFn = func() time.Time { return now }
+
+	l := slog.New(h)
+	msgs := collidingMessages(h.s, LevelInfo, samplingBucketKeys+1)
+
+	for range 3 {
+		l.Info(msgs[0], "k", "v")
+	}
+
+	// Filling the bucket evicts the counter with the oldest interval.
+	for _, msg := range msgs[1:] {
+		now = now.Add(time.Second)
+
+		l.Info(msg)
+	}
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, samplingBucketKeys+2)
+
+	summary := lines[len(lines)-2]
+	require.Equal(t, msgs[0], summary["msg"])
+	require.InDelta(t, 2, summary[RepeatedKey], 0)
+	require.NotContains(t, summary, "k")
+	require.Equal(t, msgs[samplingBucketKeys], lines[len(lines)-1]["msg"])
+}
+
+func TestSlogSamplingHandler_IdleSummary(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	h := NewSlogSamplingHandler(slog.NewJSONHandler(&buf, nil), SamplingConfig{
+		Interval: time.Minute,
+		Rule:     SamplingRule{First: 1},
+	})
+	h.s.nowFn = func() time.Time { return now }
+
+	l := slog.New(h)
+
+	for range 4 {
+		l.Info("hot")
+	}
+
+	// The dropped records of a key idle for a whole interval are reported by
+	// the next sweep, triggered by any record.
+	now = now.Add(2 * time.Minute)
+
+	l.Info("other")
+	l.Info("hot")
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 4)
+	require.Equal(t, "hot", lines[1]["msg"])
+	require.InDelta(t, 3, lines[1][RepeatedKey], 0)
+	require.Equal(t, "other", lines[2]["msg"])
+	require.Equal(t, "hot", lines[3]["msg"])
+	require.NotContains(t, lines[3], RepeatedKey)
+
+	// The sweeps run at most once per interval.
+	require.Nil(t, h.s.sweep(now.Add(30*time.Second)))
+}
+
+func TestSlogSamplingHandler_DerivedShareCounters(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	h := NewSlogSamplingHandler(slog.NewJSONHandler(&buf, nil), SamplingConfig{
+		Interval: time.Hour,
+		Rule:     SamplingRule{First: 1},
+	})
+
+	require.Same(t, h, h.WithAttrs(nil))
+	require.Same(t, h, h.WithGroup(""))
+
+	l := slog.New(h)
+
+	l.Info("m")
+	l.With("k", "v").Info("m")
+	l.WithGroup("g").Info("m")
+	l.WithGroup("g").Info("other", "a", 1)
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+	require.Equal(t, "m", lines[0]["msg"])
+	require.Equal(t, map[string]any{"a": float64(1)}, lines[1]["g"])
+}
+
+func TestSlogSamplingHandler_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		passed  atomic.Int64
+		dropped atomic.Int64
+	)
+
+	h := NewSlogSamplingHandler(
+		NewSlogHookHandler(slog.NewJSONHandler(io.Discard, nil), func(_ LogLevel, _ string) { passed.Add(1) }),
+		SamplingConfig{
+			Interval: time.Hour,
+			Rule:     SamplingRule{First: 10, Thereafter: 10},
+			DropFn:   func(_ LogLevel, _ string) { dropped.Add(1) },
+		},
+	)
+
+	l := slog.New(h)
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				l.Info("m")
+			}
+		})
+	}
+
+	wg.Wait()
+
+	// 800 records: the first 10, then 1-in-10 of the remaining 790.
+	require.Equal(t, int64(10+79), passed.Load())
+	require.Equal(t, int64(800-10-79), dropped.Load())
+}
+
+func TestConfig_SlogHandlerSampling(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cfg, err := NewConfig(
+		WithOutWriter(&buf),
+		WithSampling(SamplingConfig{Interval: time.Hour, Rule: SamplingRule{First: 1}}),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Sampling)
+
+	l := cfg.SlogLogger()
+
+	for range 3 {
+		l.Warn("again")
+	}
+
+	require.Equal(t, 1, strings.Count(buf.String(), `"msg":"again"`))
+}
+
+func TestWithSampling(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+
+	require.Error(t, WithSampling(SamplingConfig{})(cfg))
+	require.Nil(t, cfg.Sampling)
+
+	sc := SamplingConfig{Interval: time.Second, Rule: SamplingRule{First: 5}}
+
+	require.NoError(t, WithSampling(sc)(cfg))
+	require.Equal(t, &sc, cfg.Sampling)
+}
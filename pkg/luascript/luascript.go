@@ -0,0 +1,68 @@
+/*
+Package luascript binds the stores running atomic Lua scripts on a
+Redis-compatible server, such as
+[github.com/tecnickcom/nurago/pkg/ratelimit.ScriptStore],
+[github.com/tecnickcom/nurago/pkg/idempotency.ScriptStore] and
+[github.com/tecnickcom/nurago/pkg/jwt.ScriptStore], to the
+[github.com/tecnickcom/nurago/pkg/redis] and
+[github.com/tecnickcom/nurago/pkg/valkey] clients.
+
+A store takes an [EvalFunc], built from either client:
+
+	rc, err := redis.New(ctx, srvOpts)
+	// ...
+	eval, err := luascript.Redis(rc)
+	// ...
+	store, err := ratelimit.NewScriptStore(eval, ratelimit.DefaultKeyPrefix)
+
+The scripts use the Redis 5 commands, so they run on Redis 5 or later and on
+every Valkey release.
+*/
+package luascript
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNilClient is returned by [Redis] and [Valkey] when the client is nil.
+var ErrNilClient = errors.New("luascript: nil client")
+
+// EvalFunc runs a Lua script on a Redis-compatible server and returns its
+// reply: an int64, a string, a []any of replies, or nil for a nil reply.
+type EvalFunc func(ctx context.Context, script string, keys []string, args ...string) (any, error)
+
+// RedisClient is the subset of [github.com/tecnickcom/nurago/pkg/redis.Client] used by [Redis].
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// ValkeyClient is the subset of [github.com/tecnickcom/nurago/pkg/valkey.Client] used by [Valkey].
+type ValkeyClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...string) (any, error)
+}
+
+// Redis returns an [EvalFunc] running the scripts on a Redis client.
+func Redis(client RedisClient) (EvalFunc, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+
+	return func(ctx context.Context, script string, keys []string, args ...string) (any, error) {
+		vals := make([]any, len(args))
+		for i, a := range args {
+			vals[i] = a
+		}
+
+		return client.Eval(ctx, script, keys, vals...) //nolint:wrapcheck // wrapped by the store
+	}, nil
+}
+
+// Valkey returns an [EvalFunc] running the scripts on a Valkey client.
+func Valkey(client ValkeyClient) (EvalFunc, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+
+	return client.Eval, nil
+}
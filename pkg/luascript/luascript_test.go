@@ -0,0 +1,65 @@
+package luascript
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type redisClient struct {
+	args []any
+}
+
+func (c *redisClient) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	c.args = args
+
+	return fmt.Sprint(script, keys), nil
+}
+
+type valkeyClient struct {
+	args []string
+}
+
+func (c *valkeyClient) Eval(_ context.Context, script string, keys []string, args ...string) (any, error) {
+	c.args = args
+
+	return fmt.Sprint(script, keys), nil
+}
+
+func TestRedis(t *testing.T) {
+	t.Parallel()
+
+	eval, err := Redis(nil)
+	require.ErrorIs(t, err, ErrNilClient)
+	require.Nil(t, eval)
+
+	rc := &redisClient{}
+
+	eval, err = Redis(rc)
+	require.NoError(t, err)
+
+	reply, err := eval(t.Context(), "script", []string{"k"}, "a", "1")
+	require.NoError(t, err)
+	require.Equal(t, "script[k]", reply)
+	require.Equal(t, []any{"a", "1"}, rc.args)
+}
+
+func TestValkey(t *testing.T) {
+	t.Parallel()
+
+	eval, err := Valkey(nil)
+	require.ErrorIs(t, err, ErrNilClient)
+	require.Nil(t, eval)
+
+	vc := &valkeyClient{}
+
+	eval, err = Valkey(vc)
+	require.NoError(t, err)
+
+	reply, err := eval(t.Context(), "script", []string{"k"}, "a", "1")
+	require.NoError(t, err)
+	require.Equal(t, "script[k]", reply)
+	require.Equal(t, []string{"a", "1"}, vc.args)
+}
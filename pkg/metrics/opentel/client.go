@@ -16,12 +16,16 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -31,6 +35,7 @@ import (
 
 const (
 	traceBatchTimeoutSec = 5
+	logExportIntervalSec = 1
 	metricIntervalSec    = 60
 
 	// shutdownTimeoutSec bounds the context-free [Client.Close] so a hung
@@ -93,6 +98,13 @@ type TraceProviderFunc = func(ctx context.Context, res *sdkresource.Resource) (*
 // them.
 type MetricProviderFunc = func(ctx context.Context, res *sdkresource.Resource) (*sdkmetric.MeterProvider, error)
 
+// LoggerProviderFunc is a function that returns an SDK logger provider.
+//
+// It returns an error so custom implementations can surface exporter or
+// provider construction failures instead of panicking or silently swallowing
+// them.
+type LoggerProviderFunc = func(ctx context.Context, res *sdkresource.Resource) (*sdklog.LoggerProvider, error)
+
 // Client is an OpenTelemetry-backed implementation of the shared metrics
 // interface.
 //
@@ -105,6 +117,8 @@ type Client struct {
 	tracerProvider      *sdktrace.TracerProvider
 	meterProviderFn     MetricProviderFunc
 	meterProvider       *sdkmetric.MeterProvider
+	loggerProviderFn    LoggerProviderFunc
+	loggerProvider      *sdklog.LoggerProvider
 	mu                  sync.Mutex // guards shutdownFuncs
 	shutdownFuncs       []TShutdownFuncs
 	collectorErrorLevel metric.Int64Counter
@@ -122,9 +136,9 @@ type Client struct {
 // The same attributes can also be supplied through OTEL_RESOURCE_ATTRIBUTES
 // using keys: service.name, service.version, deployment.environment.name.
 //
-// New installs process-global OpenTelemetry providers (tracer, meter,
-// propagator) on success, so at most one opentel [Client] should be created per
-// process; constructing a second one overwrites the global providers.
+// New installs process-global OpenTelemetry providers (tracer, meter, logger,
+// propagator) on success, so at most one opentel [Client] should be created
+// per process; constructing a second one overwrites the global providers.
 func New(ctx context.Context, name, version string, opts ...Option) (*Client, error) {
 	c := initClient()
 
@@ -220,6 +234,13 @@ func (c *Client) InstrumentRoundTripper(next http.RoundTripper) http.RoundTrippe
 	)
 }
 
+// LoggerProvider returns the OpenTelemetry logger provider, also installed as
+// the global one, exporting the records of the logutil and logsrv loggers
+// configured with logutil.WithOTel.
+func (c *Client) LoggerProvider() *sdklog.LoggerProvider {
+	return c.loggerProvider
+}
+
 // MetricsHandlerFunc returns a minimal health-style handler.
 //
 // OpenTelemetry metrics are exported by configured exporters, so this endpoint
@@ -343,6 +364,19 @@ func (c *Client) set(ctx context.Context, name, version string) error {
 	c.meterProvider = meterProvider
 	c.appendShutdown(meterProvider.Shutdown)
 
+	// logger provider
+	if c.loggerProviderFn == nil {
+		c.loggerProviderFn = DefaultLoggerProvider
+	}
+
+	loggerProvider, err := c.loggerProviderFn(ctx, c.res)
+	if err != nil {
+		return fmt.Errorf("failed to create the logger provider: %w", err)
+	}
+
+	c.loggerProvider = loggerProvider
+	c.appendShutdown(loggerProvider.Shutdown)
+
 	meter := newMeter(meterProvider)
 	cel, erra := setInt64Counter(meter, NameLogLevel, descLogLevel, unitLogRecord)
 	cec, errb := setInt64Counter(meter, NameErrorCode, descErrorCode, unitError)
@@ -361,6 +395,7 @@ func (c *Client) set(ctx context.Context, name, version string) error {
 	otel.SetTextMapPropagator(c.propagator)
 	otel.SetTracerProvider(c.tracerProvider)
 	otel.SetMeterProvider(c.meterProvider)
+	global.SetLoggerProvider(c.loggerProvider)
 
 	return nil
 }
@@ -566,6 +601,48 @@ func DefaultMeterProvider(ctx context.Context, res *sdkresource.Resource) (*sdkm
 	return DefaultMeterProviderStdout(ctx, res)
 }
 
+// DefaultLoggerProviderWithExporter provides a default logger provider for exp.
+func DefaultLoggerProviderWithExporter(res *sdkresource.Resource, exp sdklog.Exporter) *sdklog.LoggerProvider {
+	return sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(
+			sdklog.NewBatchProcessor(
+				exp,
+				sdklog.WithExportInterval(logExportIntervalSec*time.Second),
+			),
+		),
+		sdklog.WithResource(res),
+	)
+}
+
+// DefaultLoggerProviderStdout provides a default STDOUT OpenTelemetry Logger Provider.
+func DefaultLoggerProviderStdout(_ context.Context, res *sdkresource.Resource) (*sdklog.LoggerProvider, error) {
+	exp, err := stdoutlog.New()
+
+	return DefaultLoggerProviderWithExporter(res, exp), err
+}
+
+// DefaultLoggerProviderOTLP provides a default OTLP OpenTelemetry Logger Provider.
+// The endpoint is defined by (in order of priority):
+//   - OTEL_EXPORTER_OTLP_LOGS_ENDPOINT
+//   - OTEL_EXPORTER_OTLP_ENDPOINT
+//   - "localhost:4318"
+func DefaultLoggerProviderOTLP(ctx context.Context, res *sdkresource.Resource) (*sdklog.LoggerProvider, error) {
+	exp, err := otlploghttp.New(ctx)
+
+	return DefaultLoggerProviderWithExporter(res, exp), err
+}
+
+// DefaultLoggerProvider provides a default OpenTelemetry Logger Provider.
+// If neither OTEL_EXPORTER_OTLP_LOGS_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT are defined,
+// the default STDOUT provider is returned.
+func DefaultLoggerProvider(ctx context.Context, res *sdkresource.Resource) (*sdklog.LoggerProvider, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+		return DefaultLoggerProviderOTLP(ctx, res)
+	}
+
+	return DefaultLoggerProviderStdout(ctx, res)
+}
+
 // TraceID returns the trace ID associated with ctx.
 func TraceID(ctx context.Context) string {
 	spanCtx := trace.SpanContextFromContext(ctx)
@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -38,6 +39,7 @@ func TestNew(t *testing.T) {
 			opts: []Option{
 				WithTracerProviderFn(DefaultTracerProviderStdout),
 				WithMeterProviderFn(DefaultMeterProviderStdout),
+				WithLoggerProviderFn(DefaultLoggerProviderStdout),
 			},
 			wantErr: false,
 		},
@@ -46,6 +48,7 @@ func TestNew(t *testing.T) {
 			opts: []Option{
 				WithTracerProviderFn(DefaultTracerProviderOTLP),
 				WithMeterProviderFn(DefaultMeterProviderOTLP),
+				WithLoggerProviderFn(DefaultLoggerProviderOTLP),
 			},
 			wantErr:      false,
 			wantCloseErr: true,
@@ -55,6 +58,7 @@ func TestNew(t *testing.T) {
 			opts: []Option{
 				WithTracerProviderFn(DefaultTracerProvider),
 				WithMeterProviderFn(DefaultMeterProvider),
+				WithLoggerProviderFn(DefaultLoggerProvider),
 			},
 			wantErr:      false,
 			wantCloseErr: false,
@@ -64,10 +68,12 @@ func TestNew(t *testing.T) {
 			opts: []Option{
 				WithTracerProviderFn(DefaultTracerProvider),
 				WithMeterProviderFn(DefaultMeterProvider),
+				WithLoggerProviderFn(DefaultLoggerProvider),
 			},
 			setEnvFn: func() {
 				t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "localhost:64000")
 				t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "localhost:64000")
+				t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "localhost:64000")
 			},
 			wantErr:      false,
 			wantCloseErr: false,
@@ -111,6 +117,15 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "fails when logger provider construction errors",
+			opts: []Option{
+				WithLoggerProviderFn(func(context.Context, *sdkresource.Resource) (*sdklog.LoggerProvider, error) {
+					return nil, errors.New("logger boom")
+				}),
+			},
+			wantErr: true,
+		},
 		{
 			name: "fails when meter provider construction errors",
 			opts: []Option{
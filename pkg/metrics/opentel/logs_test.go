@@ -0,0 +1,117 @@
+package opentel
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/logutil"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+	collogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpLogsReceiver is an in-process OTLP/HTTP logs receiver stub.
+type otlpLogsReceiver struct {
+	mu      sync.Mutex
+	records []*logspb.LogRecord
+}
+
+func (rcv *otlpLogsReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &collogs.ExportLogsServiceRequest{}
+
+	err = proto.Unmarshal(body, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rcv.mu.Lock()
+
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			rcv.records = append(rcv.records, sl.GetLogRecords()...)
+		}
+	}
+
+	rcv.mu.Unlock()
+
+	resp, _ := proto.Marshal(&collogs.ExportLogsServiceResponse{})
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
+
+func (rcv *otlpLogsReceiver) received() []*logspb.LogRecord {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return append([]*logspb.LogRecord(nil), rcv.records...)
+}
+
+func TestDefaultLoggerProviderWithExporter_OTLP(t *testing.T) {
+	t.Parallel()
+
+	rcv := &otlpLogsReceiver{}
+
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	exp, err := otlploghttp.New(t.Context(), otlploghttp.WithEndpointURL(srv.URL+"/v1/logs"))
+	require.NoError(t, err)
+
+	lp := DefaultLoggerProviderWithExporter(DefaultSDKResource(t.Context(), "nurago-test", "0.0.0-1"), exp)
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	ctx := ContextWithSpanContext(t.Context(), traceID, spanID)
+
+	cfg, err := logutil.NewConfig(
+		logutil.WithOTel(logutil.OTelConfig{LoggerProvider: lp}),
+		logutil.WithLevel(logutil.LevelInfo),
+	)
+	require.NoError(t, err)
+
+	l := cfg.SlogLogger()
+	l.DebugContext(ctx, "disabled")
+	l.ErrorContext(ctx, "correlated", slog.String("k", "v"))
+
+	require.NoError(t, lp.Shutdown(context.WithoutCancel(t.Context())))
+
+	records := rcv.received()
+	require.Len(t, records, 1)
+
+	r := records[0]
+	require.Equal(t, "correlated", r.GetBody().GetStringValue())
+	require.Equal(t, "error", r.GetSeverityText())
+	require.Equal(t, hex.EncodeToString(traceID[:]), hex.EncodeToString(r.GetTraceId()))
+	require.Equal(t, hex.EncodeToString(spanID[:]), hex.EncodeToString(r.GetSpanId()))
+}
+
+func TestNew_installsLoggerProvider(t *testing.T) { //nolint:paralleltest // installs the global providers.
+	c, err := New(t.Context(), "nurago-test", "0.0.0-1")
+	require.NoError(t, err)
+
+	defer func() {
+		err := c.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NotNil(t, c.LoggerProvider())
+	require.Equal(t, c.LoggerProvider(), global.GetLoggerProvider())
+}
@@ -1,6 +1,7 @@
 /*
 Package opentel implements [github.com/tecnickcom/nurago/pkg/metrics.Client]
-using OpenTelemetry for both metrics and tracing.
+using OpenTelemetry for metrics and tracing, and provides the logger provider
+exporting the logs.
 
 It provides an OpenTelemetry-backed client with interface-compatible
 instrumentation hooks:
@@ -11,7 +12,7 @@ instrumentation hooks:
   - log-level and error-taxonomy counters
 
 At startup, [New] configures and registers global OpenTelemetry providers
-(tracer, meter, propagator), creates default counters, and records shutdown
+(tracer, meter, logger, propagator), creates default counters, and records shutdown
 functions so callers can terminate exporters via [Client.Close] or
 [Client.CloseCtx].
 
@@ -22,6 +23,10 @@ By default, exporter selection is environment-driven:
     OTEL_EXPORTER_OTLP_ENDPOINT is set; otherwise stdout exporter.
   - Metrics: OTLP/HTTP when OTEL_EXPORTER_OTLP_METRICS_ENDPOINT or
     OTEL_EXPORTER_OTLP_ENDPOINT is set; otherwise stdout exporter.
+  - Logs: OTLP/HTTP when OTEL_EXPORTER_OTLP_LOGS_ENDPOINT or
+    OTEL_EXPORTER_OTLP_ENDPOINT is set; otherwise stdout exporter. Only the
+    loggers configured with logutil.WithOTel export their records, correlated
+    with the spans of the context they are given.
 
 Resource attributes are resolved from explicit parameters and environment,
 including OTEL_SERVICE_NAME, OTEL_SERVICE_VERSION,
@@ -34,6 +34,15 @@ func WithMeterProviderFn(fn MetricProviderFunc) Option {
 	}
 }
 
+// WithLoggerProviderFn overrides logger provider construction (exporter,
+// batching strategy, resource binding).
+func WithLoggerProviderFn(fn LoggerProviderFunc) Option {
+	return func(c *Client) error {
+		c.loggerProviderFn = fn
+		return nil
+	}
+}
+
 // WithPropagator overrides the default context propagator used for cross-
 // service trace propagation.
 func WithPropagator(p propagation.TextMapPropagator) Option {
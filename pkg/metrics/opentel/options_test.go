@@ -36,6 +36,16 @@ func TestWithMeterProviderFn(t *testing.T) {
 	require.NotNil(t, c.meterProviderFn)
 }
 
+func TestWithLoggerProviderFn(t *testing.T) {
+	t.Parallel()
+
+	c := initClient()
+	opt := DefaultLoggerProviderOTLP
+	err := WithLoggerProviderFn(opt)(c)
+	require.NoError(t, err)
+	require.NotNil(t, c.loggerProviderFn)
+}
+
 func TestWithPropagator(t *testing.T) {
 	t.Parallel()
 
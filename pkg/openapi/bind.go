@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+// DriftKind is the kind of a mismatch between the routes and the document.
+type DriftKind int
+
+const (
+	// DriftUndocumentedRoute is a route without a matching operation.
+	DriftUndocumentedRoute DriftKind = iota + 1
+
+	// DriftMissingRoute is an operation without a matching route.
+	DriftMissingRoute
+)
+
+// String returns the description of the drift kind.
+func (k DriftKind) String() string {
+	switch k {
+	case DriftUndocumentedRoute:
+		return "route not documented"
+	case DriftMissingRoute:
+		return "operation without route"
+	default:
+		return "unknown"
+	}
+}
+
+// Drift is a mismatch between the routes and the document.
+type Drift struct {
+	// Kind is the kind of mismatch.
+	Kind DriftKind
+
+	// Method is the HTTP method of the route or operation.
+	Method string
+
+	// Path is the path of the route or operation.
+	Path string
+}
+
+// String returns the description of the drift.
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: %s %s", d.Kind, d.Method, d.Path)
+}
+
+var (
+	// specParamRegexp matches a path template parameter (e.g. "{id}").
+	specParamRegexp = regexp.MustCompile(`\{([^/{}]+)\}`)
+
+	// routeParamRegexp matches a router path parameter (e.g. ":id" or "*path").
+	routeParamRegexp = regexp.MustCompile(`(^|/)[:*][^/]*`)
+)
+
+// Bind returns a copy of routes with the validation middleware appended to
+// the middleware of every route matching an operation, so it runs right
+// before the handler. The router path parameters (":id", "*path") match the
+// template parameters ("{id}") at the same position, whatever their names.
+//
+// In strict mode it fails with [ErrUndocumentedRoute] when a route has no
+// matching operation; otherwise such routes are returned unchanged.
+func (s *Spec) Bind(routes []httpserver.Route) ([]httpserver.Route, error) {
+	out := slices.Clone(routes)
+
+	var errs []error
+
+	for i, route := range out {
+		op := s.find(route.Method, routePathKey(route.Path))
+		if op == nil {
+			if s.strict {
+				errs = append(errs, fmt.Errorf("%w: %s %s", ErrUndocumentedRoute, route.Method, route.Path))
+			}
+
+			continue
+		}
+
+		out[i].Middleware = append(slices.Clone(route.Middleware), s.middlewareFn(op))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Drift returns the routes without a matching operation, followed by the
+// operations without a matching route.
+func (s *Spec) Drift(routes []httpserver.Route) []Drift {
+	var drift []Drift
+
+	bound := make(map[*operation]bool, len(s.ops))
+
+	for _, route := range routes {
+		op := s.find(route.Method, routePathKey(route.Path))
+		if op == nil {
+			drift = append(drift, Drift{Kind: DriftUndocumentedRoute, Method: route.Method, Path: route.Path})
+
+			continue
+		}
+
+		bound[op] = true
+	}
+
+	for _, op := range s.ops {
+		if !bound[op] {
+			drift = append(drift, Drift{Kind: DriftMissingRoute, Method: op.method, Path: op.path})
+		}
+	}
+
+	return drift
+}
+
+// find returns the operation with method and the normalized path key, or nil.
+func (s *Spec) find(method, key string) *operation {
+	for _, op := range s.ops {
+		if op.method == method && op.key == key {
+			return op
+		}
+	}
+
+	return nil
+}
+
+// middlewareFn returns the [httpserver.MiddlewareFn] validating the messages
+// of op.
+func (s *Spec) middlewareFn(op *operation) httpserver.MiddlewareFn {
+	return func(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+		logger := args.Logger
+		if logger == nil {
+			logger = s.logger
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := s.validateRequest(op, pathParamValues(op, r.URL.Path), r); err != nil {
+				s.errorHandler(w, r, requestErrorStatus(err), err)
+
+				return
+			}
+
+			if !s.strict {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			rec := newRecorder()
+
+			next.ServeHTTP(rec, r)
+
+			if err := s.validateResponse(op, rec.status(), rec.header, rec.body.Bytes()); err != nil {
+				logger.With(
+					slog.String(traceid.DefaultLogKey, traceid.FromContext(r.Context(), "")),
+					slog.String("request_method", r.Method),
+					slog.String("request_path", r.URL.Path),
+				).ErrorContext(r.Context(), "openapi response validation failed", slog.Any("errors", Messages(err)))
+
+				s.errorHandler(w, r, http.StatusInternalServerError, err)
+
+				return
+			}
+
+			rec.writeTo(w)
+		})
+	}
+}
+
+// requestErrorStatus returns the response status of the request validation
+// error err.
+func requestErrorStatus(err error) int {
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// specPathKey returns the path template with anonymous parameters, e.g.
+// "/users/{}".
+func specPathKey(path string) string {
+	return specParamRegexp.ReplaceAllString(path, "{}")
+}
+
+// routePathKey returns the router path with anonymous parameters, e.g.
+// "/users/{}" for "/users/:id".
+func routePathKey(path string) string {
+	return routeParamRegexp.ReplaceAllString(path, "$1{}")
+}
+
+// pathRegexp returns the regular expression matching the URL paths of the
+// path template, capturing the parameter values.
+func pathRegexp(path string) *regexp.Regexp {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	last := 0
+
+	for _, loc := range specParamRegexp.FindAllStringIndex(path, -1) {
+		b.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		b.WriteString("([^/]+)")
+
+		last = loc[1]
+	}
+
+	b.WriteString(regexp.QuoteMeta(path[last:]))
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
+
+// pathParamNames returns the parameter names of the path template, in order.
+func pathParamNames(path string) []string {
+	matches := specParamRegexp.FindAllStringSubmatch(path, -1)
+	names := make([]string, len(matches))
+
+	for i, m := range matches {
+		names[i] = m[1]
+	}
+
+	return names
+}
+
+// pathParamValues returns the parameter values of the URL path for op, or nil
+// when it does not match the path template.
+func pathParamValues(op *operation, path string) []string {
+	m := op.pathRe.FindStringSubmatch(path)
+	if m == nil {
+		return nil
+	}
+
+	return m[1:]
+}
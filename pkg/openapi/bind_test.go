@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+)
+
+func testRoutes() []httpserver.Route {
+	h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	return []httpserver.Route{
+		{Method: http.MethodGet, Path: "/pets", Handler: h},
+		{Method: http.MethodPost, Path: "/pets", Handler: h, Middleware: []httpserver.MiddlewareFn{nil}},
+		{Method: http.MethodGet, Path: "/pets/:petid", Handler: h},
+		{Method: http.MethodGet, Path: "/files/*path", Handler: h},
+	}
+}
+
+func TestSpec_Bind(t *testing.T) {
+	t.Parallel()
+
+	s := loadTestSpec(t)
+	routes := testRoutes()
+
+	got, err := s.Bind(routes)
+	require.NoError(t, err)
+	require.Len(t, got, len(routes))
+
+	require.Len(t, got[0].Middleware, 1)
+	require.Len(t, got[1].Middleware, 2)
+	require.Nil(t, got[1].Middleware[0])
+	require.Len(t, got[2].Middleware, 1)
+	require.Empty(t, got[3].Middleware)
+
+	require.Len(t, routes[1].Middleware, 1, "the input routes must not be modified")
+	require.Empty(t, routes[0].Middleware)
+
+	s = loadTestSpec(t, WithStrict())
+
+	got, err = s.Bind(routes)
+	require.ErrorIs(t, err, ErrUndocumentedRoute)
+	require.ErrorContains(t, err, "GET /files/*path")
+	require.Nil(t, got)
+}
+
+func TestSpec_Drift(t *testing.T) {
+	t.Parallel()
+
+	s := loadTestSpec(t)
+
+	got := s.Drift(testRoutes())
+
+	want := []Drift{
+		{Kind: DriftUndocumentedRoute, Method: http.MethodGet, Path: "/files/*path"},
+		{Kind: DriftMissingRoute, Method: http.MethodGet, Path: "/ping"},
+		{Kind: DriftMissingRoute, Method: http.MethodDelete, Path: "/pets/{id}"},
+	}
+
+	require.ElementsMatch(t, want, got)
+	require.Equal(t, want[0], got[0])
+}
+
+func TestDrift_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "route not documented: GET /a", Drift{Kind: DriftUndocumentedRoute, Method: "GET", Path: "/a"}.String())
+	require.Equal(t, "operation without route: GET /a", Drift{Kind: DriftMissingRoute, Method: "GET", Path: "/a"}.String())
+	require.Equal(t, "unknown", DriftKind(0).String())
+}
+
+func TestSpec_middlewareFn(t *testing.T) {
+	t.Parallel()
+
+	petHandler := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		contentType string
+		body        string
+		handler     http.Handler
+		wantStatus  int
+		wantBody    string
+		wantLog     string
+	}{
+		{
+			name:        "valid",
+			contentType: "application/json",
+			body:        `{"name": "rex"}`,
+			handler:     petHandler(`{"name": 1}`),
+			wantStatus:  http.StatusCreated,
+			wantBody:    `{"name": 1}`,
+		},
+		{
+			name:        "invalid request",
+			contentType: "application/json",
+			body:        `{"name": 1}`,
+			handler:     petHandler(`{}`),
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `{"errors":["body.name must be of type string"]}`,
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "application/xml",
+			body:        `<pet/>`,
+			handler:     petHandler(`{}`),
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantBody:    `{"errors":["openapi: unsupported media type: \"application/xml\""]}`,
+		},
+		{
+			name:        "request too large",
+			opts:        []Option{WithMaxBodySize(2)},
+			contentType: "application/json",
+			body:        `{"name": "rex"}`,
+			handler:     petHandler(`{}`),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "strict valid",
+			opts:        []Option{WithStrict()},
+			contentType: "application/json",
+			body:        `{"name": "rex"}`,
+			handler:     petHandler(`{"id": 1, "name": "rex"}`),
+			wantStatus:  http.StatusCreated,
+			wantBody:    `{"id": 1, "name": "rex"}`,
+		},
+		{
+			name:        "strict invalid response",
+			opts:        []Option{WithStrict()},
+			contentType: "application/json",
+			body:        `{"name": "rex"}`,
+			handler:     petHandler(`{"name": "rex"}`),
+			wantStatus:  http.StatusInternalServerError,
+			wantBody:    `{"errors":["body.id is required"]}`,
+			wantLog:     "openapi response validation failed",
+		},
+		{
+			name:        "custom error handler",
+			contentType: "application/json",
+			body:        `{}`,
+			opts: []Option{WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, status int, err error) {
+				http.Error(w, strings.Join(Messages(err), ";"), status)
+			})},
+			handler:    petHandler(`{}`),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "body.name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logBuf bytes.Buffer
+
+			s := loadTestSpec(t, tt.opts...)
+			op := s.find(http.MethodPost, "/pets")
+			require.NotNil(t, op)
+
+			args := httpserver.MiddlewareArgs{Logger: slog.New(slog.NewJSONHandler(&logBuf, nil))}
+			h := s.middlewareFn(op)(args, tt.handler)
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/pets", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			h.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.wantStatus, rr.Code)
+
+			if tt.wantBody != "" {
+				require.Equal(t, tt.wantBody, strings.TrimSpace(rr.Body.String()))
+			}
+
+			if tt.wantLog != "" {
+				require.Contains(t, logBuf.String(), tt.wantLog)
+			} else {
+				require.Empty(t, logBuf.String())
+			}
+		})
+	}
+}
+
+func TestSpec_middlewareFn_defaultLogger(t *testing.T) {
+	t.Parallel()
+
+	var logBuf bytes.Buffer
+
+	s := loadTestSpec(t, WithStrict(), WithLogger(slog.New(slog.NewJSONHandler(&logBuf, nil))))
+	op := s.find(http.MethodGet, "/ping")
+	require.NotNil(t, op)
+
+	h := s.middlewareFn(op)(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("KO"))
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/ping", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Contains(t, logBuf.String(), "body must be one of OK")
+}
+
+func Test_requestErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, http.StatusBadRequest, requestErrorStatus(errors.New("x")))
+	require.Equal(t, http.StatusUnsupportedMediaType, requestErrorStatus(errors.Join(errors.New("x"), ErrUnsupportedMediaType)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, requestErrorStatus(errors.Join(errors.New("x"), &http.MaxBytesError{Limit: 1})))
+}
+
+func Test_pathKeys(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "/users/{}/items/{}", specPathKey("/users/{id}/items/{item_id}"))
+	require.Equal(t, "/users/{}/items/{}", routePathKey("/users/:id/items/*path"))
+	require.Equal(t, "{}", routePathKey(":a"))
+
+	op := &operation{pathRe: pathRegexp("/users/{id}.json")}
+	require.Equal(t, []string{"12"}, pathParamValues(op, "/users/12.json"))
+	require.Nil(t, pathParamValues(op, "/users/12xjson"))
+	require.Equal(t, []string{"id", "item_id"}, pathParamNames("/users/{id}/items/{item_id}"))
+}
@@ -0,0 +1,328 @@
+package openapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"github.com/tecnickcom/nurago/pkg/validator"
+)
+
+// Validation tags specific to the schemas, translated by [ErrorTemplates].
+const (
+	tagType       = "schema_type"
+	tagPattern    = "schema_pattern"
+	tagMultipleOf = "schema_multipleof"
+	tagAdditional = "schema_additional"
+	tagAnyOf      = "schema_anyof"
+	tagOneOf      = "schema_oneof"
+	tagNot        = "schema_not"
+	tagFalse      = "schema_false"
+	tagFormat     = "schema_format"
+	tagKeyword    = "schema_keyword"
+)
+
+// Kinds of the validated values, as expected by the validator error templates.
+const (
+	kindArray  = "Array"
+	kindBool   = "Bool"
+	kindFloat  = "Float64"
+	kindInt    = "Int"
+	kindMap    = "Map"
+	kindString = "String"
+)
+
+// formatTag is the validation tag, with its parameter, reporting an invalid
+// string format.
+type formatTag struct {
+	tag   string
+	param string
+}
+
+// formatTags are the validation tags of the string formats reported with a
+// standard validator tag; the others use the schema_format tag.
+var formatTags = map[string]formatTag{
+	"date-time": {tag: "datetime_rfc3339"},
+	"date":      {tag: "datetime", param: time.DateOnly},
+	"email":     {tag: "email"},
+	"uuid":      {tag: "uuid"},
+	"uri":       {tag: "uri"},
+	"ipv4":      {tag: "ipv4"},
+	"ipv6":      {tag: "ipv6"},
+	"hostname":  {tag: "hostname_rfc1123"},
+	"byte":      {tag: "base64"},
+}
+
+// checker collects the validation failures of a message.
+type checker struct {
+	v        *validator.Validator
+	response bool
+	errs     []error
+}
+
+// fail records a failure of the value at namespace ns.
+func (c *checker) fail(ns, tag, param, kind string, value any) {
+	fullTag := tag
+	if param != "" {
+		fullTag += "=" + param
+	}
+
+	field := ns
+	if i := strings.LastIndexByte(ns, '.'); i >= 0 {
+		field = ns[i+1:]
+	}
+
+	c.errs = append(c.errs, c.v.Translate(&validator.Error{
+		Tag:             tag,
+		Param:           param,
+		FullTag:         fullTag,
+		Namespace:       ns,
+		StructNamespace: ns,
+		Field:           field,
+		StructField:     field,
+		Kind:            kind,
+		Value:           value,
+	}))
+}
+
+// failure is a validation failure of the value at the path.
+type failure struct {
+	path  []string
+	tag   string
+	param string
+	kind  string
+}
+
+// validate checks the JSON value v, at namespace ns, against the schema s.
+func (c *checker) validate(s *schema, ns string, v any) {
+	sch := s.compiled(c.response)
+	if sch == nil {
+		return
+	}
+
+	var verr *jsonschema.ValidationError
+	if !errors.As(sch.Validate(v), &verr) {
+		return
+	}
+
+	var fails []failure
+
+	for _, leaf := range leafErrors(verr) {
+		fails = append(fails, toFailures(leaf)...)
+	}
+
+	slices.SortStableFunc(fails, func(a, b failure) int { return comparePaths(a.path, b.path) })
+
+	for _, f := range fails {
+		fns, fv := locate(ns, v, f.path)
+		vkind := f.kind
+
+		if vkind == "" && f.tag != "required" && f.tag != tagType {
+			vkind = valueKind(fv)
+		}
+
+		c.fail(fns, f.tag, f.param, vkind, fv)
+	}
+}
+
+// leafErrors returns the failed keywords of the validation error err,
+// without the errors grouping them.
+func leafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	switch err.ErrorKind.(type) {
+	case *kind.Schema, *kind.Group, *kind.Reference, *kind.AllOf:
+		var leaves []*jsonschema.ValidationError
+
+		for _, cause := range err.Causes {
+			leaves = append(leaves, leafErrors(cause)...)
+		}
+
+		return leaves
+	default:
+		return []*jsonschema.ValidationError{err}
+	}
+}
+
+// toFailures returns the failures of the failed keyword err.
+//
+//nolint:cyclop,funlen // one case per keyword
+func toFailures(err *jsonschema.ValidationError) []failure {
+	path := err.InstanceLocation
+	one := func(tag, param, vkind string) []failure {
+		return []failure{{path: path, tag: tag, param: param, kind: vkind}}
+	}
+
+	switch k := err.ErrorKind.(type) {
+	case *kind.Type:
+		return one(tagType, typeNames(k.Want), "")
+	case *kind.Enum:
+		return one("oneof", joinValues(k.Want), "")
+	case *kind.Const:
+		return one("eq", fmt.Sprint(k.Want), "")
+	case *kind.Format:
+		if f, ok := formatTags[k.Want]; ok {
+			return one(f.tag, f.param, kindString)
+		}
+
+		return one(tagFormat, k.Want, "")
+	case *kind.MinLength:
+		return one("min", strconv.Itoa(k.Want), kindString)
+	case *kind.MaxLength:
+		return one("max", strconv.Itoa(k.Want), kindString)
+	case *kind.Pattern:
+		return one(tagPattern, k.Want, kindString)
+	case *kind.Minimum:
+		return one("gte", formatRat(k.Want), "")
+	case *kind.Maximum:
+		return one("lte", formatRat(k.Want), "")
+	case *kind.ExclusiveMinimum:
+		return one("gt", formatRat(k.Want), "")
+	case *kind.ExclusiveMaximum:
+		return one("lt", formatRat(k.Want), "")
+	case *kind.MultipleOf:
+		return one(tagMultipleOf, formatRat(k.Want), "")
+	case *kind.MinItems:
+		return one("min", strconv.Itoa(k.Want), kindArray)
+	case *kind.MaxItems:
+		return one("max", strconv.Itoa(k.Want), kindArray)
+	case *kind.UniqueItems:
+		return one("unique", "", kindArray)
+	case *kind.MinProperties:
+		return one("min", strconv.Itoa(k.Want), kindMap)
+	case *kind.MaxProperties:
+		return one("max", strconv.Itoa(k.Want), kindMap)
+	case *kind.Required:
+		return propertyFailures(path, k.Missing, "required")
+	case *kind.AdditionalProperties:
+		return propertyFailures(path, k.Properties, tagAdditional)
+	case *kind.AnyOf:
+		return one(tagAnyOf, "", "")
+	case *kind.OneOf:
+		return one(tagOneOf, "", "")
+	case *kind.Not:
+		return one(tagNot, "", "")
+	case *kind.FalseSchema:
+		return one(tagFalse, "", "")
+	default:
+		return one(tagKeyword, strings.Join(k.KeywordPath(), "/"), "")
+	}
+}
+
+// propertyFailures returns a failure with tag for each property of the object
+// at path.
+func propertyFailures(path, props []string, tag string) []failure {
+	fails := make([]failure, len(props))
+
+	for i, name := range props {
+		fails[i] = failure{path: append(slices.Clip(path), name), tag: tag}
+	}
+
+	return fails
+}
+
+// locate returns the namespace and the value at the path in the value v at
+// namespace ns.
+func locate(ns string, v any, path []string) (string, any) {
+	for _, name := range path {
+		switch val := v.(type) {
+		case []any:
+			ns += "[" + name + "]"
+
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(val) {
+				v = nil
+
+				continue
+			}
+
+			v = val[i]
+		case map[string]any:
+			ns += "." + name
+			v = val[name]
+		default:
+			ns += "." + name
+			v = nil
+		}
+	}
+
+	return ns, v
+}
+
+// comparePaths compares the value paths a and b, the array indexes by value.
+func comparePaths(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		x, xerr := strconv.Atoi(a[i])
+		y, yerr := strconv.Atoi(b[i])
+
+		if xerr == nil && yerr == nil {
+			if r := cmp.Compare(x, y); r != 0 {
+				return r
+			}
+
+			continue
+		}
+
+		if r := strings.Compare(a[i], b[i]); r != 0 {
+			return r
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// typeNames returns the list of the types, without null unless it is the
+// only one.
+func typeNames(types []string) string {
+	names := slices.DeleteFunc(slices.Clone(types), func(t string) bool { return t == typeNull })
+	if len(names) == 0 {
+		names = types
+	}
+
+	return strings.Join(names, ",")
+}
+
+// joinValues returns the space separated list of the values.
+func joinValues(values []any) string {
+	vals := make([]string, len(values))
+	for i, e := range values {
+		vals[i] = fmt.Sprint(e)
+	}
+
+	return strings.Join(vals, " ")
+}
+
+// valueKind returns the kind of v, as expected by the error templates.
+func valueKind(v any) string {
+	switch val := v.(type) {
+	case string:
+		return kindString
+	case bool:
+		return kindBool
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return kindInt
+		}
+
+		return kindFloat
+	case []any:
+		return kindArray
+	case map[string]any:
+		return kindMap
+	default:
+		return ""
+	}
+}
+
+// formatRat returns the shortest decimal representation of r.
+func formatRat(r *big.Rat) string {
+	f, _ := r.Float64()
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func compileSchema(t *testing.T, schemaYAML string, response bool) *schema {
+	t.Helper()
+
+	s := &schema{}
+	require.NoError(t, yaml.Unmarshal([]byte(schemaYAML), s))
+
+	defs := map[string]*schema{"S": s}
+
+	r := &schemaResolver{defs: defs, seen: make(map[*schema]bool)}
+	require.NoError(t, r.resolve(s))
+	require.NoError(t, (&schemaCompiler{defs: defs, response: response}).compile([]*schema{s}))
+
+	return s
+}
+
+func validateValue(t *testing.T, schemaYAML, value string, response bool) []string {
+	t.Helper()
+
+	s := compileSchema(t, schemaYAML, response)
+
+	v, err := decodeJSON([]byte(value))
+	require.NoError(t, err)
+
+	val, err := defaultValidator()
+	require.NoError(t, err)
+
+	c := &checker{v: val, response: response}
+	c.validate(s, "body", v)
+
+	return Messages(errors.Join(c.errs...))
+}
+
+func TestChecker_validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		schema   string
+		value    string
+		response bool
+		want     []string
+	}{
+		{name: "no schema constraints", schema: `{}`, value: `{"a": [1, "x", null]}`},
+		{name: "null allowed by type list", schema: `{type: [string, "null"]}`, value: `null`},
+		{name: "null allowed by nullable", schema: `{type: string, nullable: true}`, value: `null`},
+		{name: "null allowed by enum", schema: `{type: string, enum: [a, null]}`, value: `null`},
+		{name: "null allowed by nullable enum", schema: `{type: string, enum: [a], nullable: true}`, value: `null`},
+		{name: "null rejected", schema: `{type: string}`, value: `null`, want: []string{"body must be of type string"}},
+		{name: "type mismatch", schema: `{type: integer}`, value: `"1"`, want: []string{"body must be of type integer"}},
+		{name: "nullable type mismatch", schema: `{type: integer, nullable: true}`, value: `"1"`, want: []string{"body must be of type integer"}},
+		{name: "integer", schema: `{type: integer}`, value: `10`},
+		{name: "integral float is integer", schema: `{type: integer}`, value: `1.0`},
+		{name: "large integral number", schema: `{type: integer}`, value: `1e30`},
+		{name: "fraction is not integer", schema: `{type: integer}`, value: `1.5`, want: []string{"body must be of type integer"}},
+		{name: "integer is number", schema: `{type: number}`, value: `1`},
+		{name: "boolean", schema: `{type: boolean}`, value: `true`},
+		{name: "enum", schema: `{enum: [1, two]}`, value: `1.0`},
+		{name: "date enum", schema: `{enum: [2026-01-02]}`, value: `"2026-01-02"`},
+		{name: "enum mismatch", schema: `{enum: [1, two]}`, value: `"three"`, want: []string{"body must be one of 1 two"}},
+		{name: "const", schema: `{const: {a: [1]}}`, value: `{"a": [1]}`},
+		{name: "const mismatch", schema: `{const: x}`, value: `"y"`, want: []string{"body must be equal to x"}},
+		{
+			name:   "string constraints",
+			schema: `{type: string, minLength: 3, maxLength: 4, pattern: '^[a-z]+$'}`,
+			value:  `"ab1"`,
+			want:   []string{"body must match the pattern '^[a-z]+$'"},
+		},
+		{name: "string too short", schema: `{type: string, minLength: 3}`, value: `"àb"`, want: []string{"body must be at least 3 characters in length"}},
+		{name: "string too long", schema: `{type: string, maxLength: 1}`, value: `"ab"`, want: []string{"body must be a maximum of 1 characters in length"}},
+		{name: "unknown format", schema: `{type: string, format: custom}`, value: `"x"`},
+		{name: "other format", schema: `{format: duration}`, value: `"1h"`, want: []string{"body must be a valid duration"}},
+		{name: "date-time", schema: `{format: date-time}`, value: `"2026-01-02T03:04:05Z"`},
+		{name: "invalid date-time", schema: `{format: date-time}`, value: `"2026-01-02"`, want: []string{"body is not a valid RFC-3339 datetime format"}},
+		{name: "date", schema: `{format: date}`, value: `"2026-01-02"`},
+		{name: "invalid date", schema: `{format: date}`, value: `"02/01/2026"`, want: []string{"body does not match the 2006-01-02 format"}},
+		{name: "email", schema: `{format: email}`, value: `"a@example.com"`},
+		{name: "invalid email", schema: `{format: email}`, value: `"A <a@example.com>"`, want: []string{"body must be a valid email address"}},
+		{name: "uuid", schema: `{format: uuid}`, value: `"0190a6d4-8b31-7cf2-9d6b-3c1b1e3a5f00"`},
+		{name: "invalid uuid", schema: `{format: uuid}`, value: `"x"`, want: []string{"body must be a valid UUID"}},
+		{name: "uri", schema: `{format: uri}`, value: `"https://example.com/a"`},
+		{name: "invalid uri", schema: `{format: uri}`, value: `"/a"`, want: []string{"body must be a valid URI"}},
+		{name: "ipv4", schema: `{format: ipv4}`, value: `"10.0.0.1"`},
+		{name: "invalid ipv4", schema: `{format: ipv4}`, value: `"::1"`, want: []string{"body must be a valid IPv4 address"}},
+		{name: "ipv6", schema: `{format: ipv6}`, value: `"::1"`},
+		{name: "invalid ipv6", schema: `{format: ipv6}`, value: `"10.0.0.1"`, want: []string{"body must be a valid IPv6 address"}},
+		{name: "hostname", schema: `{format: hostname}`, value: `"api.example.com"`},
+		{name: "invalid hostname", schema: `{format: hostname}`, value: `"a_b"`, want: []string{"body must be a valid hostname as per RFC 1123"}},
+		{name: "byte not string", schema: `{format: byte}`, value: `1`},
+		{name: "byte", schema: `{format: byte}`, value: `"aGVsbG8="`},
+		{name: "invalid byte", schema: `{format: byte}`, value: `"!"`, want: []string{"body must be a valid Base64 string"}},
+		{name: "minimum", schema: `{type: integer, minimum: 2}`, value: `1`, want: []string{"body must be 2 or greater"}},
+		{name: "maximum", schema: `{type: number, maximum: 2.5}`, value: `2.6`, want: []string{"body must be 2.5 or less"}},
+		{name: "exclusive minimum flag", schema: `{minimum: 2, exclusiveMinimum: true}`, value: `2`, want: []string{"body must be greater than 2"}},
+		{name: "exclusive maximum flag", schema: `{maximum: 2, exclusiveMaximum: true}`, value: `2`, want: []string{"body must be less than 2"}},
+		{name: "exclusive minimum value", schema: `{exclusiveMinimum: 2}`, value: `2`, want: []string{"body must be greater than 2"}},
+		{name: "exclusive maximum value", schema: `{exclusiveMaximum: 2}`, value: `3`, want: []string{"body must be less than 2"}},
+		{name: "inclusive minimum flag", schema: `{minimum: 2, exclusiveMinimum: false}`, value: `2`},
+		{name: "within bounds", schema: `{minimum: 1, exclusiveMaximum: 2}`, value: `1.5`},
+		{name: "multiple of", schema: `{multipleOf: 0.1}`, value: `0.3`},
+		{name: "not multiple of", schema: `{multipleOf: 2}`, value: `3`, want: []string{"body must be a multiple of 2"}},
+		{name: "large number", schema: `{type: number}`, value: `1e400`},
+		{
+			name:   "array constraints",
+			schema: `{type: array, minItems: 3, uniqueItems: true, items: {type: integer}}`,
+			value:  `[1, "a", 1.0]`,
+			want:   []string{"body must contain unique values", "body[1] must be of type integer"},
+		},
+		{
+			name:   "nested arrays",
+			schema: `{items: {type: array, items: {type: integer}}}`,
+			value:  `[[1, "a"], [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, "b"], "c"]`,
+			want: []string{
+				"body[0][1] must be of type integer",
+				"body[1][10] must be of type integer",
+				"body[2] must be of type array",
+			},
+		},
+		{name: "array too short", schema: `{minItems: 2}`, value: `[1]`, want: []string{"body must contain at least 2 items"}},
+		{name: "array too long", schema: `{maxItems: 1}`, value: `[1, 2]`, want: []string{"body must contain at maximum 1 items"}},
+		{
+			name:   "object constraints",
+			schema: `{type: object, required: [a, b], additionalProperties: false, properties: {a: {type: string}}}`,
+			value:  `{"a": 1, "c": true}`,
+			want: []string{
+				"body.a must be of type string",
+				"body.b is required",
+				"body.c is not an allowed property",
+			},
+		},
+		{
+			name:   "additional properties schema",
+			schema: `{additionalProperties: {type: integer}}`,
+			value:  `{"a": 1, "b": "x"}`,
+			want:   []string{"body.b must be of type integer"},
+		},
+		{name: "too few properties", schema: `{minProperties: 1}`, value: `{}`, want: []string{"body must contain at least 1 items"}},
+		{name: "too many properties", schema: `{maxProperties: 0}`, value: `{"a": 1}`, want: []string{"body must contain at maximum 0 items"}},
+		{
+			name:   "read only not required in request",
+			schema: `{required: [id], properties: {id: {readOnly: true}}}`,
+			value:  `{}`,
+		},
+		{
+			name:     "read only required in response",
+			schema:   `{required: [id], properties: {id: {readOnly: true}}}`,
+			value:    `{}`,
+			response: true,
+			want:     []string{"body.id is required"},
+		},
+		{
+			name:   "read only reference not required in request",
+			schema: `{readOnly: true, required: [self], properties: {self: {$ref: '#/components/schemas/S'}}}`,
+			value:  `{}`,
+		},
+		{
+			name:     "write only not required in response",
+			schema:   `{required: [pw], properties: {pw: {writeOnly: true}}}`,
+			value:    `{}`,
+			response: true,
+		},
+		{
+			name:   "all of",
+			schema: `{allOf: [{required: [a]}, {required: [b]}]}`,
+			value:  `{"a": 1}`,
+			want:   []string{"body.b is required"},
+		},
+		{name: "false schema", schema: `{properties: {a: false}}`, value: `{"a": 1}`, want: []string{"body.a is not allowed"}},
+		{name: "other keyword", schema: `{contains: {type: integer}}`, value: `["a"]`, want: []string{"body must satisfy the contains keyword"}},
+		{name: "any of", schema: `{anyOf: [{type: string}, {type: integer}]}`, value: `1`},
+		{
+			name:   "any of mismatch",
+			schema: `{anyOf: [{type: string}, {type: integer}]}`,
+			value:  `true`,
+			want:   []string{"body must match at least one of the allowed schemas"},
+		},
+		{
+			name:   "one of ambiguous",
+			schema: `{oneOf: [{type: number}, {type: integer}]}`,
+			value:  `1`,
+			want:   []string{"body must match exactly one of the allowed schemas"},
+		},
+		{
+			name:   "not",
+			schema: `{not: {type: string}}`,
+			value:  `"x"`,
+			want:   []string{"body must not match the disallowed schema"},
+		},
+		{
+			name:   "recursive reference",
+			schema: `{type: object, properties: {child: {$ref: '#/components/schemas/S'}, name: {type: string}}}`,
+			value:  `{"child": {"child": {"name": 1}}}`,
+			want:   []string{"body.child.child.name must be of type string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := validateValue(t, tt.schema, tt.value, tt.response)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_decodeJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := decodeJSON([]byte(`{`))
+	require.ErrorIs(t, err, ErrInvalidBody)
+
+	_, err = decodeJSON([]byte(`{} {}`))
+	require.ErrorIs(t, err, ErrInvalidBody)
+}
+
+func Test_schema_deref(t *testing.T) {
+	t.Parallel()
+
+	s := &schema{}
+	s.ref = s
+
+	require.Same(t, s, s.deref())
+}
+
+func Test_valueKind(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, kindBool, valueKind(true))
+	require.Equal(t, kindInt, valueKind(json.Number("1")))
+	require.Equal(t, kindFloat, valueKind(json.Number("1.5")))
+	require.Empty(t, valueKind(nil))
+}
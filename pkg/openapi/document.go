@@ -0,0 +1,494 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRefDepth is the maximum length of a reference chain.
+const maxRefDepth = 32
+
+// document is the subset of an OpenAPI document used for the validation.
+type document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components components           `yaml:"components"`
+}
+
+// components holds the reusable objects of the document.
+type components struct {
+	Schemas       map[string]*schema      `yaml:"schemas"`
+	Parameters    map[string]*parameter   `yaml:"parameters"`
+	RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+	Responses     map[string]*response    `yaml:"responses"`
+	Headers       map[string]*header      `yaml:"headers"`
+}
+
+// pathItem describes the operations of a path.
+type pathItem struct {
+	Parameters []*parameter    `yaml:"parameters"`
+	Get        *operationModel `yaml:"get"`
+	Put        *operationModel `yaml:"put"`
+	Post       *operationModel `yaml:"post"`
+	Delete     *operationModel `yaml:"delete"`
+	Options    *operationModel `yaml:"options"`
+	Head       *operationModel `yaml:"head"`
+	Patch      *operationModel `yaml:"patch"`
+	Trace      *operationModel `yaml:"trace"`
+}
+
+// operationModel describes an operation of a path.
+type operationModel struct {
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   map[string]*response `yaml:"responses"`
+}
+
+// parameter describes a path, query, header or cookie parameter.
+type parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Style    string  `yaml:"style"`
+	Explode  *bool   `yaml:"explode"`
+	Schema   *schema `yaml:"schema"`
+}
+
+// requestBody describes the body of a request.
+type requestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*mediaType `yaml:"content"`
+}
+
+// mediaType describes the content of a body with a media type.
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+// response describes a response of an operation.
+type response struct {
+	Ref     string                `yaml:"$ref"`
+	Headers map[string]*header    `yaml:"headers"`
+	Content map[string]*mediaType `yaml:"content"`
+}
+
+// header describes a response header.
+type header struct {
+	Ref      string  `yaml:"$ref"`
+	Required bool    `yaml:"required"`
+	Schema   *schema `yaml:"schema"`
+}
+
+// operation is a resolved operation, ready for the validation.
+type operation struct {
+	method    string
+	path      string
+	key       string
+	pathRe    *regexp.Regexp
+	pathNames []string
+	params    []*parameter
+	body      *requestBody
+	responses map[string]*response
+}
+
+// operations resolves the references of the document and returns its
+// operations.
+func (d *document) operations() ([]*operation, error) {
+	if err := d.resolveSchemas(); err != nil {
+		return nil, err
+	}
+
+	var ops []*operation
+
+	for path, item := range d.Paths {
+		if item == nil {
+			continue
+		}
+
+		itemParams, err := d.resolveParams(item.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		for method, om := range item.operations() {
+			op, err := d.operation(method, path, itemParams, om)
+			if err != nil {
+				return nil, err
+			}
+
+			ops = append(ops, op)
+		}
+	}
+
+	sortOperations(ops)
+
+	return ops, nil
+}
+
+// operation returns the resolved operation om of method and path, inheriting
+// the path-level parameters itemParams.
+func (d *document) operation(method, path string, itemParams []*parameter, om *operationModel) (*operation, error) {
+	opParams, err := d.resolveParams(om.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &operation{
+		method:    method,
+		path:      path,
+		key:       specPathKey(path),
+		pathRe:    pathRegexp(path),
+		pathNames: pathParamNames(path),
+		params:    mergeParams(itemParams, opParams),
+		responses: make(map[string]*response, len(om.Responses)),
+	}
+
+	if om.RequestBody != nil {
+		op.body, err = resolveRef(om.RequestBody, func(b *requestBody) string { return b.Ref }, d.Components.RequestBodies, "requestBodies")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for code, resp := range om.Responses {
+		if resp == nil {
+			continue
+		}
+
+		r, err := resolveRef(resp, func(r *response) string { return r.Ref }, d.Components.Responses, "responses")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := d.resolveHeaders(r); err != nil {
+			return nil, err
+		}
+
+		op.responses[strings.ToUpper(code)] = r
+	}
+
+	return op, nil
+}
+
+// operations returns the operations of the path item by HTTP method.
+func (p *pathItem) operations() map[string]*operationModel {
+	all := map[string]*operationModel{
+		http.MethodGet:     p.Get,
+		http.MethodPut:     p.Put,
+		http.MethodPost:    p.Post,
+		http.MethodDelete:  p.Delete,
+		http.MethodOptions: p.Options,
+		http.MethodHead:    p.Head,
+		http.MethodPatch:   p.Patch,
+		http.MethodTrace:   p.Trace,
+	}
+
+	for method, op := range all {
+		if op == nil {
+			delete(all, method)
+		}
+	}
+
+	return all
+}
+
+// resolveParams resolves the references of params, dropping the nil entries.
+func (d *document) resolveParams(params []*parameter) ([]*parameter, error) {
+	out := make([]*parameter, 0, len(params))
+
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+
+		rp, err := resolveRef(p, func(p *parameter) string { return p.Ref }, d.Components.Parameters, "parameters")
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, rp)
+	}
+
+	return out, nil
+}
+
+// resolveHeaders resolves the header references of the response r.
+func (d *document) resolveHeaders(r *response) error {
+	for name, h := range r.Headers {
+		if h == nil {
+			delete(r.Headers, name)
+
+			continue
+		}
+
+		rh, err := resolveRef(h, func(h *header) string { return h.Ref }, d.Components.Headers, "headers")
+		if err != nil {
+			return err
+		}
+
+		r.Headers[name] = rh
+	}
+
+	return nil
+}
+
+// resolveSchemas links every schema reference of the document to its target
+// and compiles the schemas.
+func (d *document) resolveSchemas() error {
+	r := &schemaResolver{
+		defs: d.Components.Schemas,
+		seen: make(map[*schema]bool),
+	}
+
+	for _, s := range d.Components.Schemas {
+		if err := r.resolve(s); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range d.Components.Parameters {
+		if err := r.add(p.schema(), false); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range d.Components.Headers {
+		if err := r.add(h.schema(), true); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range d.Components.RequestBodies {
+		if err := r.addContent(b.content(), false); err != nil {
+			return err
+		}
+	}
+
+	for _, resp := range d.Components.Responses {
+		if err := r.addResponse(resp); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range d.Paths {
+		if err := r.addPathItem(item); err != nil {
+			return err
+		}
+	}
+
+	if err := (&schemaCompiler{defs: r.defs}).compile(r.requests); err != nil {
+		return err
+	}
+
+	return (&schemaCompiler{defs: r.defs, response: true}).compile(r.responses)
+}
+
+// schema returns the schema of the parameter p, which may be nil.
+func (p *parameter) schema() *schema {
+	if p == nil {
+		return nil
+	}
+
+	return p.Schema
+}
+
+// schema returns the schema of the header h, which may be nil.
+func (h *header) schema() *schema {
+	if h == nil {
+		return nil
+	}
+
+	return h.Schema
+}
+
+// content returns the content of the body b, which may be nil.
+func (b *requestBody) content() map[string]*mediaType {
+	if b == nil {
+		return nil
+	}
+
+	return b.Content
+}
+
+// schemaResolver links the schema references to their targets, and collects
+// the schemas validating the requests and the responses.
+type schemaResolver struct {
+	defs      map[string]*schema
+	seen      map[*schema]bool
+	requests  []*schema
+	responses []*schema
+}
+
+// addPathItem adds the schemas of the path item.
+func (r *schemaResolver) addPathItem(item *pathItem) error {
+	if item == nil {
+		return nil
+	}
+
+	for _, p := range item.Parameters {
+		if err := r.add(p.schema(), false); err != nil {
+			return err
+		}
+	}
+
+	for _, om := range item.operations() {
+		for _, p := range om.Parameters {
+			if err := r.add(p.schema(), false); err != nil {
+				return err
+			}
+		}
+
+		if err := r.addContent(om.RequestBody.content(), false); err != nil {
+			return err
+		}
+
+		for _, resp := range om.Responses {
+			if err := r.addResponse(resp); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addResponse adds the schemas of the response.
+func (r *schemaResolver) addResponse(resp *response) error {
+	if resp == nil {
+		return nil
+	}
+
+	for _, h := range resp.Headers {
+		if err := r.add(h.schema(), true); err != nil {
+			return err
+		}
+	}
+
+	return r.addContent(resp.Content, true)
+}
+
+// addContent adds the schemas of the media types of content.
+func (r *schemaResolver) addContent(content map[string]*mediaType, response bool) error {
+	for _, mt := range content {
+		if mt == nil {
+			continue
+		}
+
+		if err := r.add(mt.Schema, response); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add adds the schema s validating a response, or a request.
+func (r *schemaResolver) add(s *schema, response bool) error {
+	if s == nil {
+		return nil
+	}
+
+	if response {
+		r.responses = append(r.responses, s)
+	} else {
+		r.requests = append(r.requests, s)
+	}
+
+	return r.resolve(s)
+}
+
+// resolve links the references of s and of its items.
+func (r *schemaResolver) resolve(s *schema) error {
+	if s == nil || r.seen[s] {
+		return nil
+	}
+
+	r.seen[s] = true
+
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return err
+		}
+
+		target, ok := r.defs[name]
+		if !ok || target == nil {
+			return fmt.Errorf("%w: %q", ErrInvalidRef, s.Ref)
+		}
+
+		s.ref = target
+	}
+
+	return r.resolve(s.Items)
+}
+
+// resolveRef returns the object referenced by v, following the reference
+// chain in defs.
+func resolveRef[T any](v *T, ref func(*T) string, defs map[string]*T, kind string) (*T, error) {
+	for range maxRefDepth {
+		r := ref(v)
+		if r == "" {
+			return v, nil
+		}
+
+		name, err := refName(r, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		next, ok := defs[name]
+		if !ok || next == nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRef, r)
+		}
+
+		v = next
+	}
+
+	return nil, fmt.Errorf("%w: reference chain too long", ErrInvalidRef)
+}
+
+// refName returns the component name of the local reference ref to a
+// component of kind.
+func refName(ref, kind string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRef, ref)
+	}
+
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name), nil
+}
+
+// mergeParams returns the path-level parameters overridden by the operation
+// ones with the same name and location.
+func mergeParams(itemParams, opParams []*parameter) []*parameter {
+	out := make([]*parameter, 0, len(itemParams)+len(opParams))
+
+	for _, ip := range itemParams {
+		overridden := false
+
+		for _, op := range opParams {
+			if op.Name == ip.Name && op.In == ip.In {
+				overridden = true
+
+				break
+			}
+		}
+
+		if !overridden {
+			out = append(out, ip)
+		}
+	}
+
+	return append(out, opParams...)
+}
+
+// decodeNode decodes n into v, reporting whether it succeeded.
+func decodeNode(n *yaml.Node, v any) bool {
+	return n.Decode(v) == nil
+}
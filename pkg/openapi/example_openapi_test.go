@@ -0,0 +1,105 @@
+package openapi_test
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/openapi"
+)
+
+const exampleSpec = `
+openapi: 3.1.0
+info:
+  title: example
+  version: 1.0.0
+paths:
+  /users/{id}:
+    put:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            minimum: 1
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  maxLength: 8
+                email:
+                  type: string
+                  format: email
+      responses:
+        '204':
+          description: updated
+`
+
+func ExampleSpec_Bind() {
+	spec, err := openapi.Load([]byte(exampleSpec))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	routes, err := spec.Bind([]httpserver.Route{
+		{
+			Method: http.MethodPut,
+			Path:   "/users/:id",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mw := routes[0].Middleware[0]
+	handler := mw(httpserver.MiddlewareArgs{}, routes[0].Handler)
+
+	send := func(path, body string) {
+		r := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		fmt.Println(strings.TrimSpace(fmt.Sprintf("%d %s", rr.Code, rr.Body.String())))
+	}
+
+	send("/users/1", `{"name": "alice"}`)
+	send("/users/0", `{"name": "bartholomew", "email": "bart"}`)
+
+	// Output:
+	// 204
+	// 400 {"errors":["path.id must be 1 or greater","body.email must be a valid email address","body.name must be a maximum of 8 characters in length"]}
+}
+
+func ExampleSpec_Drift() {
+	spec, err := openapi.Load([]byte(exampleSpec))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	drift := spec.Drift([]httpserver.Route{
+		{Method: http.MethodGet, Path: "/users/:id"},
+	})
+
+	for _, d := range drift {
+		fmt.Println(d)
+	}
+
+	// Output:
+	// route not documented: GET /users/:id
+	// operation without route: PUT /users/{id}
+}
@@ -0,0 +1,211 @@
+/*
+Package openapi validates the requests and responses of
+[github.com/tecnickcom/nurago/pkg/httpserver] routes against an OpenAPI 3
+document, so the handlers cannot silently drift from the published API
+specification.
+
+[Load] (or [LoadFile]) parses an OpenAPI 3.x document in YAML or JSON, and
+[Spec.Bind] attaches a validation middleware to each route matching an
+operation of the document:
+
+	spec, err := openapi.LoadFile("openapi_public.yaml")
+	// ...
+	routes, err = spec.Bind(routes)
+
+The middleware validates the path, query, header and cookie parameters and
+the JSON request body against their schemas before calling the handler. A
+request failing the validation gets a 400 Bad Request (415 Unsupported Media
+Type for an undocumented content type) listing every failure.
+
+Each failure is a [github.com/tecnickcom/nurago/pkg/validator.Error], with a
+namespace locating the value (e.g. "query.limit" or "body.items[0].name"),
+and the message formatted by the validator error templates, as for the
+struct validation. [Messages] returns the messages of a validation error.
+
+# Strict Mode
+
+[WithStrict] is meant for tests and staging environments:
+
+  - [Spec.Bind] fails with [ErrUndocumentedRoute] for a route without a
+    matching operation;
+  - the responses are buffered and validated too (status, headers and body),
+    and an invalid response is logged and replaced with a 500 Internal Server
+    Error listing the failures.
+
+[Spec.Drift] reports both the routes missing from the document and the
+documented operations without a route, and [Spec.ValidateRequest] and
+[Spec.ValidateResponse] validate the messages of handlers tested in isolation.
+
+# Supported Features
+
+The schemas are compiled and validated as JSON Schema 2020-12 by
+[github.com/santhosh-tekuri/jsonschema/v6], the library also validating the
+JSON payloads of [github.com/tecnickcom/nurago/pkg/codec]. The OpenAPI 3.0
+dialect is converted: the nullable flag (or a null enum value) allows the
+null type, and the boolean exclusiveMinimum and exclusiveMaximum make the
+minimum and maximum exclusive. A required readOnly property can be omitted
+in a request, and a required writeOnly property in a response.
+
+The formats are asserted: date-time, date, email, uuid, uri, ipv4, ipv6,
+hostname and byte are reported with the standard validator tags, the other
+formats known to the library (e.g. duration) with the schema_format tag, and
+the unknown ones are ignored. References are supported within the document
+(#/components/schemas/...); external references are rejected by [Load].
+*/
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnsupportedVersion is returned by [Load] for a document that is not OpenAPI 3.x.
+	ErrUnsupportedVersion = errors.New("openapi: unsupported OpenAPI version")
+
+	// ErrInvalidRef is returned by [Load] for a reference that cannot be resolved.
+	ErrInvalidRef = errors.New("openapi: invalid reference")
+
+	// ErrOperationNotFound is returned when no operation matches a request.
+	ErrOperationNotFound = errors.New("openapi: operation not found")
+
+	// ErrUnsupportedMediaType is reported for a content type not documented by the operation.
+	ErrUnsupportedMediaType = errors.New("openapi: unsupported media type")
+
+	// ErrInvalidBody is reported for a body that is not valid JSON.
+	ErrInvalidBody = errors.New("openapi: invalid JSON body")
+
+	// ErrUndocumentedStatus is reported for a response status not documented by the operation.
+	ErrUndocumentedStatus = errors.New("openapi: undocumented response status")
+
+	// ErrUndocumentedRoute is returned by [Spec.Bind] in strict mode for a route without operation.
+	ErrUndocumentedRoute = errors.New("openapi: route not documented")
+)
+
+// ErrorHandlerFunc writes the response of a request failing the validation
+// with status and the validation error err.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// Spec is a loaded OpenAPI document validating the HTTP messages of its
+// operations. It is safe for concurrent use.
+type Spec struct {
+	ops          []*operation
+	validator    *validator.Validator
+	logger       *slog.Logger
+	strict       bool
+	maxBodySize  int64
+	errorHandler ErrorHandlerFunc
+}
+
+// Load parses the OpenAPI 3.x document data, in YAML or JSON.
+func Load(data []byte, opts ...Option) (*Spec, error) {
+	doc := &document{}
+
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("openapi: unable to parse the document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, doc.OpenAPI)
+	}
+
+	ops, err := doc.operations()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spec{
+		ops:         ops,
+		logger:      slog.Default(),
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(s)
+	}
+
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+
+	if s.validator == nil {
+		s.validator, err = defaultValidator()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.errorHandler == nil {
+		httpresp := httputil.NewHTTPResp(s.logger)
+		s.errorHandler = func(w http.ResponseWriter, r *http.Request, status int, err error) {
+			httpresp.SendJSON(r.Context(), w, status, map[string][]string{"errors": Messages(err)})
+		}
+	}
+
+	return s, nil
+}
+
+// LoadFile parses the OpenAPI 3.x document stored in the file at path.
+func LoadFile(path string, opts ...Option) (*Spec, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the path is provided by the application
+	if err != nil {
+		return nil, fmt.Errorf("openapi: unable to read the document: %w", err)
+	}
+
+	return Load(data, opts...)
+}
+
+// Messages returns the messages of the failures joined in the validation
+// error err.
+func Messages(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []string{err.Error()}
+	}
+
+	var msgs []string
+
+	for _, e := range joined.Unwrap() {
+		msgs = append(msgs, Messages(e)...)
+	}
+
+	return msgs
+}
+
+// defaultValidator returns the validator formatting the errors with the
+// default validator templates and [ErrorTemplates].
+func defaultValidator() (*validator.Validator, error) {
+	v, err := validator.New(
+		validator.WithErrorTemplates(validator.ErrorTemplates()),
+		validator.WithErrorTemplates(ErrorTemplates()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: unable to create the validator: %w", err)
+	}
+
+	return v, nil
+}
+
+// sortOperations sorts ops by path and method, for a stable report order.
+func sortOperations(ops []*operation) {
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+
+		return ops[i].method < ops[j].method
+	})
+}
@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSpecFile = "testdata/petstore.yaml"
+
+func loadTestSpec(t *testing.T, opts ...Option) *Spec {
+	t.Helper()
+
+	s, err := LoadFile(testSpecFile, opts...)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+		errIs   error
+	}{
+		{
+			name:    "invalid yaml",
+			data:    "openapi: [",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported version",
+			data:    `swagger: "2.0"`,
+			wantErr: true,
+			errIs:   ErrUnsupportedVersion,
+		},
+		{
+			name: "json document",
+			data: `{"openapi": "3.0.3", "paths": {"/a": {"get": {"responses": {"200": {"description": "ok"}}}}}}`,
+		},
+		{
+			name: "external schema ref",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      $ref: 'other.yaml#/components/schemas/B'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing schema ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing parameter ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    parameters:
+      - $ref: '#/components/parameters/Missing'
+    get: {}
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing operation parameter ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing request body ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing response ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    get:
+      responses:
+        '200':
+          $ref: '#/components/responses/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing header ref",
+			data: `
+openapi: 3.0.0
+paths:
+  /a:
+    get:
+      responses:
+        '200':
+          headers:
+            X-A:
+              $ref: '#/components/headers/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "circular parameter ref",
+			data: `
+openapi: 3.0.0
+components:
+  parameters:
+    A:
+      $ref: '#/components/parameters/A'
+paths:
+  /a:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/A'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "missing nested schema ref",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      properties:
+        b:
+          $ref: '#/components/schemas/Missing'
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+		{
+			name: "external metaschema",
+			data: `
+openapi: 3.1.0
+components:
+  schemas:
+    A:
+      $schema: 'https://example.com/schema'
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid pattern",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      type: string
+      pattern: '['
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid type",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      type: {a: b}
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid exclusive bound",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      exclusiveMinimum: abc
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid additional properties",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      additionalProperties: [1]
+`,
+			wantErr: true,
+		},
+		{
+			name: "resolved components",
+			data: `
+openapi: 3.0.0
+components:
+  schemas:
+    A:
+      $ref: '#/components/schemas/B'
+    B:
+      type: object
+      properties:
+        self:
+          $ref: '#/components/schemas/A'
+  parameters:
+    P:
+      name: p
+      in: query
+      schema:
+        $ref: '#/components/schemas/B'
+  headers:
+    H:
+      schema:
+        $ref: '#/components/schemas/B'
+  requestBodies:
+    R:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/B'
+        text/plain: ~
+  responses:
+    S:
+      headers:
+        X-A:
+          $ref: '#/components/headers/H'
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/a~1b'
+paths:
+  /a: ~
+`,
+			wantErr: true,
+			errIs:   ErrInvalidRef,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := Load([]byte(tt.data))
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.NotNil(t, s)
+
+				return
+			}
+
+			require.Error(t, err)
+			require.Nil(t, s)
+
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	s := loadTestSpec(t, WithLogger(nil))
+	require.NotNil(t, s.logger)
+	require.NotNil(t, s.validator)
+	require.NotNil(t, s.errorHandler)
+	require.Len(t, s.ops, 5)
+
+	s, err := LoadFile("testdata/missing.yaml")
+	require.Error(t, err)
+	require.Nil(t, s)
+}
+
+func TestMessages(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, Messages(nil))
+	require.Equal(t, []string{"a"}, Messages(errors.New("a")))
+
+	err := errors.Join(errors.New("a"), errors.Join(errors.New("b"), errors.New("c")))
+	require.Equal(t, []string{"a", "b", "c"}, Messages(err))
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	s := loadTestSpec(t)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/pets", nil)
+
+	s.errorHandler(rr, req, http.StatusBadRequest, errors.Join(errors.New("a"), errors.New("b")))
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.JSONEq(t, `{"errors":["a","b"]}`, rr.Body.String())
+}
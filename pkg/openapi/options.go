@@ -0,0 +1,56 @@
+package openapi
+
+import (
+	"log/slog"
+
+	"github.com/tecnickcom/nurago/pkg/validator"
+)
+
+// DefaultMaxBodySize is the default maximum size of a validated request body.
+const DefaultMaxBodySize = 10 << 20
+
+// Option configures the [Spec].
+type Option func(*Spec)
+
+// WithValidator sets the validator formatting the validation errors. It must
+// include the [ErrorTemplates] to translate the schema specific failures.
+// The default validator uses the validator error templates.
+func WithValidator(v *validator.Validator) Option {
+	return func(s *Spec) {
+		s.validator = v
+	}
+}
+
+// WithLogger sets the logger of the response validation failures, used when
+// the route has no logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Spec) {
+		s.logger = logger
+	}
+}
+
+// WithStrict enables the strict mode: [Spec.Bind] rejects the undocumented
+// routes, and the responses are validated too.
+func WithStrict() Option {
+	return func(s *Spec) {
+		s.strict = true
+	}
+}
+
+// WithMaxBodySize sets the maximum size of a validated request body (default
+// [DefaultMaxBodySize]); a larger body gets a 413 Request Entity Too Large. A
+// non-positive size disables the limit.
+func WithMaxBodySize(size int64) Option {
+	return func(s *Spec) {
+		s.maxBodySize = size
+	}
+}
+
+// WithErrorHandler sets the function writing the response of an invalid
+// request (or of an invalid response in strict mode). The default handler
+// sends a JSON object with the list of failure messages in "errors".
+func WithErrorHandler(fn ErrorHandlerFunc) Option {
+	return func(s *Spec) {
+		s.errorHandler = fn
+	}
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/validator"
+)
+
+func TestWithValidator(t *testing.T) {
+	t.Parallel()
+
+	v, err := validator.New()
+	require.NoError(t, err)
+
+	s := &Spec{}
+	WithValidator(v)(s)
+	require.Same(t, v, s.validator)
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	l := slog.Default()
+
+	s := &Spec{}
+	WithLogger(l)(s)
+	require.Same(t, l, s.logger)
+}
+
+func TestWithStrict(t *testing.T) {
+	t.Parallel()
+
+	s := &Spec{}
+	WithStrict()(s)
+	require.True(t, s.strict)
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	s := &Spec{}
+	WithMaxBodySize(7)(s)
+	require.Equal(t, int64(7), s.maxBodySize)
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	var called bool
+
+	s := &Spec{}
+	WithErrorHandler(func(_ http.ResponseWriter, _ *http.Request, _ int, _ error) { called = true })(s)
+	require.NotNil(t, s.errorHandler)
+
+	s.errorHandler(nil, nil, 0, nil)
+	require.True(t, called)
+}
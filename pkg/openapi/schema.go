@@ -0,0 +1,503 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// JSON schema type names.
+const (
+	typeArray   = "array"
+	typeBoolean = "boolean"
+	typeInteger = "integer"
+	typeNull    = "null"
+	typeNumber  = "number"
+	typeObject  = "object"
+	typeString  = "string"
+)
+
+// schemaURL is the base location of the compiled schema resources.
+const schemaURL = "openapi:///"
+
+// Keywords holding a subschema, a list of subschemas, or a map of subschemas.
+var (
+	schemaKeywords = []string{
+		"items", "additionalItems", "additionalProperties", "contains", "propertyNames",
+		"not", "if", "then", "else", "unevaluatedItems", "unevaluatedProperties",
+	}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "dependentSchemas", "$defs", "definitions"}
+)
+
+// schema is a JSON schema of the document. The validation uses its JSON
+// value, compiled for the requests and the responses; the type and the items
+// are decoded to parse the parameters.
+type schema struct {
+	Ref   string      `yaml:"$ref"`
+	Type  schemaTypes `yaml:"type"`
+	Items *schema     `yaml:"items"`
+
+	doc      map[string]any
+	ref      *schema
+	request  *jsonschema.Schema
+	response *jsonschema.Schema
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *schema) UnmarshalYAML(n *yaml.Node) error {
+	type plain schema
+
+	if err := n.Decode((*plain)(s)); err != nil {
+		return fmt.Errorf("openapi: invalid schema: %w", err)
+	}
+
+	v, err := nodeValue(n)
+	if err != nil {
+		return err
+	}
+
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("openapi: invalid schema at line %d", n.Line)
+	}
+
+	s.doc = doc
+
+	return nil
+}
+
+// schemaTypes is the schema type, a single name or a list (OpenAPI 3.1).
+type schemaTypes []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *schemaTypes) UnmarshalYAML(n *yaml.Node) error {
+	var name string
+	if decodeNode(n, &name) {
+		*t = schemaTypes{name}
+
+		return nil
+	}
+
+	var names []string
+	if err := n.Decode(&names); err != nil {
+		return fmt.Errorf("openapi: invalid schema type: %w", err)
+	}
+
+	*t = names
+
+	return nil
+}
+
+// deref returns the schema referenced by s, or s.
+func (s *schema) deref() *schema {
+	for range maxRefDepth {
+		if s == nil || s.ref == nil {
+			return s
+		}
+
+		s = s.ref
+	}
+
+	return s
+}
+
+// primaryType returns the first non-null type of s, or an empty string.
+func (s *schema) primaryType() string {
+	for _, t := range s.Type {
+		if t != typeNull {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// compiled returns the schema compiled for the responses, or for the
+// requests.
+func (s *schema) compiled(response bool) *jsonschema.Schema {
+	if response {
+		return s.response
+	}
+
+	return s.request
+}
+
+// nodeValue returns the JSON value of the YAML node n.
+func nodeValue(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return nodeValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := nodeValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			m[n.Content[i].Value] = v
+		}
+
+		return m, nil
+	case yaml.SequenceNode:
+		list := make([]any, len(n.Content))
+
+		for i, item := range n.Content {
+			v, err := nodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			list[i] = v
+		}
+
+		return list, nil
+	default:
+		if n.ShortTag() == "!!timestamp" {
+			return n.Value, nil
+		}
+
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, fmt.Errorf("openapi: invalid schema value: %w", err)
+		}
+
+		return v, nil
+	}
+}
+
+// mapSubschemas returns a copy of the schema doc with each direct subschema
+// replaced by its value returned by fn.
+func mapSubschemas(doc map[string]any, fn func(v any) (any, error)) (map[string]any, error) {
+	out := maps.Clone(doc)
+
+	var err error
+
+	for _, kw := range schemaKeywords {
+		if sub, ok := out[kw]; ok {
+			if out[kw], err = fn(sub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, kw := range schemaListKeywords {
+		list, ok := out[kw].([]any)
+		if !ok {
+			continue
+		}
+
+		subs := make([]any, len(list))
+		for i, sub := range list {
+			if subs[i], err = fn(sub); err != nil {
+				return nil, err
+			}
+		}
+
+		out[kw] = subs
+	}
+
+	for _, kw := range schemaMapKeywords {
+		m, ok := out[kw].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		subs := make(map[string]any, len(m))
+		for name, sub := range m {
+			if subs[name], err = fn(sub); err != nil {
+				return nil, err
+			}
+		}
+
+		out[kw] = subs
+	}
+
+	return out, nil
+}
+
+// schemaCompiler compiles the schemas of the document, for the requests or
+// for the responses.
+type schemaCompiler struct {
+	defs     map[string]*schema
+	response bool
+}
+
+// compile compiles the schemas roots and the component schemas. The schemas
+// reference the components with a #/components/schemas/ pointer, so they
+// share a resource with them.
+func (c *schemaCompiler) compile(roots []*schema) error {
+	names := slices.Sorted(maps.Keys(c.defs))
+	defs := make(map[string]any, len(names))
+
+	for _, name := range names {
+		if c.defs[name] == nil {
+			continue
+		}
+
+		doc, err := c.transform(c.defs[name].doc)
+		if err != nil {
+			return err
+		}
+
+		defs[name] = doc
+	}
+
+	docs := make([]any, len(roots))
+
+	for i, s := range roots {
+		doc, err := c.transform(s.doc)
+		if err != nil {
+			return err
+		}
+
+		docs[i] = doc
+	}
+
+	loc := schemaURL + "request.json"
+	if c.response {
+		loc = schemaURL + "response.json"
+	}
+
+	compiler := newCompiler()
+
+	err := compiler.AddResource(loc, map[string]any{
+		"components": map[string]any{"schemas": defs},
+		"roots":      docs,
+	})
+	if err != nil {
+		return fmt.Errorf("openapi: unable to load the schemas: %w", err)
+	}
+
+	for _, name := range names {
+		if c.defs[name] == nil {
+			continue
+		}
+
+		ptr := strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+		if _, err := compiler.Compile(loc + "#/components/schemas/" + url.PathEscape(ptr)); err != nil {
+			return fmt.Errorf("openapi: invalid schema %q: %w", name, err)
+		}
+	}
+
+	for i, s := range roots {
+		sch, err := compiler.Compile(loc + "#/roots/" + strconv.Itoa(i))
+		if err != nil {
+			return fmt.Errorf("openapi: invalid schema: %w", err)
+		}
+
+		if c.response {
+			s.response = sch
+		} else {
+			s.request = sch
+		}
+	}
+
+	return nil
+}
+
+// transform returns the schema v, with its subschemas, converted from the
+// OpenAPI dialect to JSON Schema 2020-12. It checks the references.
+func (c *schemaCompiler) transform(v any) (any, error) {
+	doc, ok := v.(map[string]any)
+	if !ok {
+		return v, nil
+	}
+
+	if ref, ok := doc["$ref"].(string); ok {
+		if _, err := c.target(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := mapSubschemas(doc, c.transform)
+	if err != nil {
+		return nil, err
+	}
+
+	allowNull(doc)
+	exclusiveBound(doc, "exclusiveMinimum", "minimum")
+	exclusiveBound(doc, "exclusiveMaximum", "maximum")
+	c.dropRequired(doc)
+
+	return doc, nil
+}
+
+// target returns the component schema referenced by ref.
+func (c *schemaCompiler) target(ref string) (*schema, error) {
+	name, err := refName(ref, "schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	s := c.defs[name]
+	if s == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRef, ref)
+	}
+
+	return s, nil
+}
+
+// dropRequired removes from the required properties of doc the ones that can
+// be omitted: the readOnly properties in a request, and the writeOnly ones in
+// a response.
+func (c *schemaCompiler) dropRequired(doc map[string]any) {
+	required, _ := doc["required"].([]any)
+	props, _ := doc["properties"].(map[string]any)
+
+	if len(required) == 0 || len(props) == 0 {
+		return
+	}
+
+	flag := "readOnly"
+	if c.response {
+		flag = "writeOnly"
+	}
+
+	kept := make([]any, 0, len(required))
+
+	for _, name := range required {
+		if n, ok := name.(string); ok && c.hasFlag(props[n], flag) {
+			continue
+		}
+
+		kept = append(kept, name)
+	}
+
+	doc["required"] = kept
+}
+
+// hasFlag reports whether the boolean keyword of the schema v, or of the
+// schema it references, is set.
+func (c *schemaCompiler) hasFlag(v any, keyword string) bool {
+	for range maxRefDepth {
+		doc, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if on, _ := doc[keyword].(bool); on {
+			return true
+		}
+
+		ref, ok := doc["$ref"].(string)
+		if !ok {
+			return false
+		}
+
+		s, err := c.target(ref)
+		if err != nil {
+			return false
+		}
+
+		v = s.doc
+	}
+
+	return false
+}
+
+// allowNull adds the null type to the schema doc flagged as nullable
+// (OpenAPI 3.0), or listing null in its enum.
+func allowNull(doc map[string]any) {
+	nullable, _ := doc["nullable"].(bool)
+	enum, hasEnum := doc["enum"].([]any)
+
+	if !nullable && !slices.Contains(enum, nil) {
+		return
+	}
+
+	switch t := doc["type"].(type) {
+	case string:
+		if t != typeNull {
+			doc["type"] = []any{t, typeNull}
+		}
+	case []any:
+		if !slices.Contains(t, any(typeNull)) {
+			doc["type"] = append(slices.Clone(t), typeNull)
+		}
+	}
+
+	if hasEnum && !slices.Contains(enum, nil) {
+		doc["enum"] = append(slices.Clone(enum), nil)
+	}
+}
+
+// exclusiveBound converts the exclusive flag of the bound keyword (OpenAPI
+// 3.0) to the exclusive bound value (OpenAPI 3.1).
+func exclusiveBound(doc map[string]any, exclusive, bound string) {
+	flag, ok := doc[exclusive].(bool)
+	if !ok {
+		return
+	}
+
+	delete(doc, exclusive)
+
+	if b, ok := doc[bound]; ok && flag {
+		doc[exclusive] = b
+		delete(doc, bound)
+	}
+}
+
+// newCompiler returns a JSON Schema 2020-12 compiler asserting the formats.
+// The external resources are rejected, as [Load] does for the references.
+func newCompiler() *jsonschema.Compiler {
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	c.RegisterFormat(&jsonschema.Format{Name: "byte", Validate: validateBase64})
+	c.UseLoader(noLoader{})
+
+	return c
+}
+
+// noLoader is a [jsonschema.URLLoader] rejecting every resource.
+type noLoader struct{}
+
+// Load implements jsonschema.URLLoader.
+func (noLoader) Load(loc string) (any, error) {
+	return nil, fmt.Errorf("%w: %q", ErrInvalidRef, loc)
+}
+
+// validateBase64 validates the byte format: a base64 encoded string.
+func validateBase64(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+		return fmt.Errorf("invalid base64 string: %w", err)
+	}
+
+	return nil
+}
+
+// decodeJSON decodes the single JSON value data, keeping the numbers as
+// [json.Number].
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBody, err.Error())
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("%w: unexpected data after the JSON value", ErrInvalidBody)
+	}
+
+	return v, nil
+}
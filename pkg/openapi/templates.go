@@ -0,0 +1,20 @@
+package openapi
+
+// ErrorTemplates returns the error message templates of the validation tags
+// specific to the schemas, for
+// [github.com/tecnickcom/nurago/pkg/validator.WithErrorTemplates]. The other
+// failures use the standard validator tags (e.g. required, min, max, oneof).
+func ErrorTemplates() map[string]string {
+	return map[string]string{
+		tagType:       `{{.Namespace}} must be of type {{.Param}}`,
+		tagPattern:    `{{.Namespace}} must match the pattern '{{.Param}}'`,
+		tagMultipleOf: `{{.Namespace}} must be a multiple of {{.Param}}`,
+		tagAdditional: `{{.Namespace}} is not an allowed property`,
+		tagAnyOf:      `{{.Namespace}} must match at least one of the allowed schemas`,
+		tagOneOf:      `{{.Namespace}} must match exactly one of the allowed schemas`,
+		tagNot:        `{{.Namespace}} must not match the disallowed schema`,
+		tagFalse:      `{{.Namespace}} is not allowed`,
+		tagFormat:     `{{.Namespace}} must be a valid {{.Param}}`,
+		tagKeyword:    `{{.Namespace}} must satisfy the {{.Param}} keyword`,
+	}
+}
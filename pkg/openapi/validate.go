@@ -0,0 +1,363 @@
+package openapi
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+)
+
+// Parameter locations.
+const (
+	inPath   = "path"
+	inQuery  = "query"
+	inHeader = "header"
+	inCookie = "cookie"
+)
+
+// ValidateRequest validates r against the operation matching its method and
+// URL path, and returns the validation failures joined with errors.Join, or
+// [ErrOperationNotFound]. The request body is restored for the next reader.
+func (s *Spec) ValidateRequest(r *http.Request) error {
+	op, params := s.match(r.Method, r.URL.Path)
+	if op == nil {
+		return fmt.Errorf("%w: %s %s", ErrOperationNotFound, r.Method, r.URL.Path)
+	}
+
+	return s.validateRequest(op, params, r)
+}
+
+// ValidateResponse validates the response with status, header and body to
+// the request r against the operation matching the request method and URL
+// path, and returns the validation failures joined with errors.Join, or
+// [ErrOperationNotFound].
+func (s *Spec) ValidateResponse(r *http.Request, status int, header http.Header, body []byte) error {
+	op, _ := s.match(r.Method, r.URL.Path)
+	if op == nil {
+		return fmt.Errorf("%w: %s %s", ErrOperationNotFound, r.Method, r.URL.Path)
+	}
+
+	return s.validateResponse(op, status, header, body)
+}
+
+// match returns the operation matching method and the URL path, with its
+// path parameter values. The operation with the fewest path parameters wins.
+func (s *Spec) match(method, path string) (*operation, []string) {
+	var (
+		best   *operation
+		values []string
+	)
+
+	for _, op := range s.ops {
+		if op.method != method {
+			continue
+		}
+
+		m := op.pathRe.FindStringSubmatch(path)
+		if m == nil || (best != nil && len(m)-1 >= len(values)) {
+			continue
+		}
+
+		best, values = op, m[1:]
+	}
+
+	return best, values
+}
+
+// validateRequest validates r against op, with the path parameter values
+// pathValues.
+func (s *Spec) validateRequest(op *operation, pathValues []string, r *http.Request) error {
+	c := &checker{v: s.validator}
+	query := r.URL.Query()
+
+	for _, p := range op.params {
+		switch p.In {
+		case inPath:
+			c.checkPathParam(p, op.pathNames, pathValues)
+		case inQuery:
+			vals, ok := query[p.Name]
+			c.checkParam(p, vals, ok)
+		case inHeader:
+			vals := r.Header.Values(p.Name)
+			c.checkParam(p, vals, len(vals) > 0)
+		case inCookie:
+			ck, err := r.Cookie(p.Name)
+			if err != nil {
+				c.checkParam(p, nil, false)
+			} else {
+				c.checkParam(p, []string{ck.Value}, true)
+			}
+		}
+	}
+
+	if err := s.checkRequestBody(c, op, r); err != nil {
+		c.errs = append(c.errs, err)
+	}
+
+	return errors.Join(c.errs...)
+}
+
+// checkRequestBody validates the body of r against op. It returns the errors
+// preventing the validation.
+func (s *Spec) checkRequestBody(c *checker, op *operation, r *http.Request) error {
+	if op.body == nil || r.Body == nil || r.Body == http.NoBody {
+		if op.body != nil && op.body.Required {
+			c.fail("body", "required", "", "", nil)
+		}
+
+		return nil
+	}
+
+	if s.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, s.maxBodySize)
+	}
+
+	data, err := io.ReadAll(r.Body)
+
+	_ = r.Body.Close()
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if err != nil {
+		return fmt.Errorf("openapi: unable to read the request body: %w", err)
+	}
+
+	if len(data) == 0 {
+		if op.body.Required {
+			c.fail("body", "required", "", "", nil)
+		}
+
+		return nil
+	}
+
+	return c.checkContent(op.body.Content, r.Header.Get(httputil.HeaderContentType), data)
+}
+
+// validateResponse validates the response with status, header and body
+// against op.
+func (s *Spec) validateResponse(op *operation, status int, header http.Header, body []byte) error {
+	resp := op.response(status)
+	if resp == nil {
+		return fmt.Errorf("%w: %d", ErrUndocumentedStatus, status)
+	}
+
+	c := &checker{v: s.validator, response: true}
+
+	for name, h := range resp.Headers {
+		if strings.EqualFold(name, httputil.HeaderContentType) {
+			continue
+		}
+
+		vals := header.Values(name)
+		c.checkParam(&parameter{Name: name, In: inHeader, Required: h.Required, Schema: h.Schema}, vals, len(vals) > 0)
+	}
+
+	if len(body) > 0 {
+		if err := c.checkContent(resp.Content, header.Get(httputil.HeaderContentType), body); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+
+	return errors.Join(c.errs...)
+}
+
+// response returns the response of op documented for status, or nil.
+func (op *operation) response(status int) *response {
+	code := strconv.Itoa(status)
+
+	for _, key := range []string{code, code[:1] + "XX", "DEFAULT"} {
+		if r, ok := op.responses[key]; ok {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// checkContent validates the body data, with the content type contentType,
+// against the documented content. It returns the errors preventing the
+// validation.
+func (c *checker) checkContent(content map[string]*mediaType, contentType string, data []byte) error {
+	mt, mtName := findMediaType(content, contentType)
+	if mt == nil {
+		return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+	}
+
+	if mt.Schema == nil {
+		return nil
+	}
+
+	if isJSONMediaType(mtName) {
+		v, err := decodeJSON(data)
+		if err != nil {
+			return err
+		}
+
+		c.validate(mt.Schema, "body", v)
+
+		return nil
+	}
+
+	if mt.Schema.deref().primaryType() == typeString {
+		c.validate(mt.Schema, "body", string(data))
+	}
+
+	return nil
+}
+
+// findMediaType returns the media type of content matching contentType (the
+// exact type, then the type range, e.g. "text/*", then "*/*"), with the name of
+// the request content type, or of the matched range when unknown.
+func findMediaType(content map[string]*mediaType, contentType string) (*mediaType, string) {
+	name, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		name = ""
+	}
+
+	candidates := []string{"*/*"}
+	if name != "" {
+		major, _, _ := strings.Cut(name, "/")
+		candidates = []string{name, major + "/*", "*/*"}
+	}
+
+	for _, cand := range candidates {
+		for key, mt := range content {
+			if mt != nil && strings.EqualFold(mediaTypeName(key), cand) {
+				return mt, cmp.Or(name, cand)
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// mediaTypeName returns the media type name of key, without parameters.
+func mediaTypeName(key string) string {
+	name, _, _ := strings.Cut(key, ";")
+
+	return strings.TrimSpace(name)
+}
+
+// isJSONMediaType reports whether the media type name is JSON.
+func isJSONMediaType(name string) bool {
+	name = strings.ToLower(name)
+
+	return name == "application/json" || strings.HasSuffix(name, "+json")
+}
+
+// checkPathParam validates the path parameter p, whose value is at the same
+// position in values as its name in names.
+func (c *checker) checkPathParam(p *parameter, names, values []string) {
+	for i, name := range names {
+		if name != p.Name || i >= len(values) {
+			continue
+		}
+
+		v, err := url.PathUnescape(values[i])
+		if err != nil {
+			v = values[i]
+		}
+
+		c.checkParam(p, []string{v}, true)
+
+		return
+	}
+}
+
+// checkParam validates the raw values of the parameter p, present in the
+// request or not.
+func (c *checker) checkParam(p *parameter, values []string, present bool) {
+	ns := p.In + "." + p.Name
+
+	if !present {
+		if p.Required || p.In == inPath {
+			c.fail(ns, "required", "", "", nil)
+		}
+
+		return
+	}
+
+	s := p.Schema.deref()
+	if s == nil {
+		return
+	}
+
+	switch s.primaryType() {
+	case typeObject:
+		// Object parameters (e.g. deepObject) are not validated.
+		return
+	case typeArray:
+		items := splitParam(p, values)
+		arr := make([]any, len(items))
+
+		for i, item := range items {
+			arr[i] = parseScalar(s.Items.deref(), item)
+		}
+
+		c.validate(p.Schema, ns, arr)
+	default:
+		c.validate(p.Schema, ns, parseScalar(s, values[0]))
+	}
+}
+
+// splitParam returns the items of the array parameter p with the raw values.
+func splitParam(p *parameter, values []string) []string {
+	explode := p.In == inQuery || p.In == inCookie
+	if p.Explode != nil {
+		explode = *p.Explode
+	}
+
+	if p.In == inQuery && explode && (p.Style == "" || p.Style == "form") {
+		return values
+	}
+
+	sep := ","
+
+	switch p.Style {
+	case "spaceDelimited":
+		sep = " "
+	case "pipeDelimited":
+		sep = "|"
+	}
+
+	var items []string
+
+	for _, v := range values {
+		for item := range strings.SplitSeq(v, sep) {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+
+	return items
+}
+
+// parseScalar converts the raw parameter value raw to the JSON value of the
+// schema s type. A value that cannot be converted is returned as a string, so
+// it fails the type check.
+func parseScalar(s *schema, raw string) any {
+	if s == nil {
+		return raw
+	}
+
+	switch s.primaryType() {
+	case typeInteger, typeNumber:
+		if _, err := strconv.ParseFloat(raw, 64); err == nil && json.Valid([]byte(raw)) {
+			return json.Number(raw)
+		}
+	case typeBoolean:
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+
+	return raw
+}
@@ -0,0 +1,446 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_ValidateRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		header      http.Header
+		cookie      *http.Cookie
+		body        string
+		maxBodySize int64
+		want        []string
+		errIs       error
+	}{
+		{
+			name:   "valid query",
+			method: http.MethodGet,
+			target: "/pets?limit=10&tags=a&tags=b",
+			header: http.Header{"X-Request-Id": {"0190a6d4-8b31-7cf2-9d6b-3c1b1e3a5f00"}},
+		},
+		{
+			name:   "invalid query",
+			method: http.MethodGet,
+			target: "/pets?limit=0&tags=a&tags=b&tags=c",
+			header: http.Header{"X-Request-Id": {"123"}},
+			want: []string{
+				"query.limit must be 1 or greater",
+				"query.tags must contain at maximum 2 items",
+				"header.X-Request-ID must be a valid UUID",
+			},
+		},
+		{
+			name:   "non numeric query",
+			method: http.MethodGet,
+			target: "/pets?limit=ten",
+			want:   []string{"query.limit must be of type integer"},
+		},
+		{
+			name:   "valid body",
+			method: http.MethodPost,
+			target: "/pets",
+			header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			body:   `{"name": "rex", "tag": null}`,
+		},
+		{
+			name:   "invalid body",
+			method: http.MethodPost,
+			target: "/pets",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"name": "", "tag": "A", "age": 3}`,
+			want: []string{
+				"body.age is not an allowed property",
+				"body.name must be at least 1 characters in length",
+				"body.tag must match the pattern '^[a-z]+$'",
+			},
+		},
+		{
+			name:   "missing body",
+			method: http.MethodPost,
+			target: "/pets",
+			want:   []string{"body is required"},
+		},
+		{
+			name:   "empty body",
+			method: http.MethodPost,
+			target: "/pets",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   " ",
+			want:   []string{"openapi: invalid JSON body: EOF"},
+			errIs:  ErrInvalidBody,
+		},
+		{
+			name:   "malformed body",
+			method: http.MethodPost,
+			target: "/pets",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"name":`,
+			errIs:  ErrInvalidBody,
+		},
+		{
+			name:   "unsupported media type",
+			method: http.MethodPost,
+			target: "/pets",
+			header: http.Header{"Content-Type": {"text/plain"}},
+			body:   "rex",
+			errIs:  ErrUnsupportedMediaType,
+		},
+		{
+			name:        "body too large",
+			method:      http.MethodPost,
+			target:      "/pets",
+			header:      http.Header{"Content-Type": {"application/json"}},
+			body:        `{"name": "rex"}`,
+			maxBodySize: 4,
+			want:        []string{"openapi: unable to read the request body: http: request body too large"},
+		},
+		{
+			name:   "valid path and cookie",
+			method: http.MethodGet,
+			target: "/pets/12",
+			cookie: &http.Cookie{Name: "session", Value: "abcd"},
+		},
+		{
+			name:   "invalid path and cookie",
+			method: http.MethodGet,
+			target: "/pets/a%20b",
+			cookie: &http.Cookie{Name: "session", Value: "ab"},
+			want: []string{
+				"path.id must be of type integer",
+				"cookie.session must be at least 3 characters in length",
+			},
+		},
+		{
+			name:   "operation not found",
+			method: http.MethodPut,
+			target: "/pets",
+			errIs:  ErrOperationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := loadTestSpec(t, WithMaxBodySize(tt.maxBodySize))
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+
+			req := httptest.NewRequestWithContext(t.Context(), tt.method, tt.target, body)
+			if tt.header != nil {
+				req.Header = tt.header
+			}
+
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			err := s.ValidateRequest(req)
+
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+			}
+
+			if tt.want != nil {
+				require.Equal(t, tt.want, Messages(err))
+			}
+
+			if tt.errIs == nil && tt.want == nil {
+				require.NoError(t, err)
+			}
+
+			if tt.body != "" && tt.maxBodySize == 0 {
+				got, rerr := io.ReadAll(req.Body)
+				require.NoError(t, rerr)
+				require.Equal(t, tt.body, string(got))
+			}
+		})
+	}
+}
+
+func TestSpec_ValidateResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		header http.Header
+		body   string
+		want   []string
+		errIs  error
+	}{
+		{
+			name:   "valid list",
+			method: http.MethodGet,
+			target: "/pets",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"application/json"}, "X-Total": {"1"}},
+			body:   `[{"id": 1, "name": "rex"}]`,
+		},
+		{
+			name:   "invalid list",
+			method: http.MethodGet,
+			target: "/pets",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `[{"name": "rex"}]`,
+			want: []string{
+				"header.X-Total is required",
+				"body[0].id is required",
+			},
+		},
+		{
+			name:   "invalid header",
+			method: http.MethodGet,
+			target: "/pets",
+			status: http.StatusOK,
+			header: http.Header{"X-Total": {"many"}},
+			want:   []string{"header.X-Total must be of type integer"},
+		},
+		{
+			name:   "status range",
+			method: http.MethodPost,
+			target: "/pets",
+			status: http.StatusConflict,
+			header: http.Header{"Content-Type": {"application/problem+json"}},
+			body:   `[]`,
+			errIs:  ErrUnsupportedMediaType,
+		},
+		{
+			name:   "status range body",
+			method: http.MethodPost,
+			target: "/pets",
+			status: http.StatusConflict,
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `[]`,
+			want:   []string{"body must be of type object"},
+		},
+		{
+			name:   "default status",
+			method: http.MethodPost,
+			target: "/pets",
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "undocumented status",
+			method: http.MethodGet,
+			target: "/pets/1",
+			status: http.StatusNotFound,
+			errIs:  ErrUndocumentedStatus,
+		},
+		{
+			name:   "text body",
+			method: http.MethodGet,
+			target: "/ping",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			body:   "OK",
+		},
+		{
+			name:   "invalid text body",
+			method: http.MethodGet,
+			target: "/ping",
+			status: http.StatusOK,
+			header: http.Header{"Content-Type": {"text/plain"}},
+			body:   "KO",
+			want:   []string{"body must be one of OK"},
+		},
+		{
+			name:   "operation not found",
+			method: http.MethodGet,
+			target: "/users",
+			status: http.StatusOK,
+			errIs:  ErrOperationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := loadTestSpec(t)
+			req := httptest.NewRequestWithContext(t.Context(), tt.method, tt.target, nil)
+
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			err := s.ValidateResponse(req, tt.status, header, []byte(tt.body))
+
+			if tt.errIs != nil {
+				require.ErrorIs(t, err, tt.errIs)
+			}
+
+			if tt.want != nil {
+				require.Equal(t, tt.want, Messages(err))
+			}
+
+			if tt.errIs == nil && tt.want == nil {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_findMediaType(t *testing.T) {
+	t.Parallel()
+
+	text := &mediaType{}
+	anyType := &mediaType{}
+	content := map[string]*mediaType{
+		"application/json; charset=utf-8": nil,
+		"text/*":                          text,
+		"*/*":                             anyType,
+	}
+
+	mt, name := findMediaType(content, "text/csv")
+	require.Same(t, text, mt)
+	require.Equal(t, "text/csv", name)
+
+	mt, name = findMediaType(content, "application/json")
+	require.Same(t, anyType, mt)
+	require.Equal(t, "application/json", name)
+
+	mt, name = findMediaType(content, "")
+	require.Same(t, anyType, mt)
+	require.Equal(t, "*/*", name)
+
+	mt, name = findMediaType(map[string]*mediaType{"text/plain": text}, ";")
+	require.Nil(t, mt)
+	require.Empty(t, name)
+}
+
+func Test_checker_checkContent(t *testing.T) {
+	t.Parallel()
+
+	val, err := defaultValidator()
+	require.NoError(t, err)
+
+	c := &checker{v: val}
+
+	require.NoError(t, c.checkContent(map[string]*mediaType{"*/*": {}}, "", []byte("x")))
+	require.NoError(t, c.checkContent(map[string]*mediaType{"*/*": {Schema: &schema{Type: schemaTypes{typeInteger}}}}, "", []byte("x")))
+	require.Empty(t, c.errs)
+}
+
+func Test_checker_checkParam(t *testing.T) {
+	t.Parallel()
+
+	explode := false
+	noExplode := &explode
+
+	tests := []struct {
+		name    string
+		param   *parameter
+		values  []string
+		present bool
+		want    []string
+	}{
+		{
+			name:  "missing path parameter",
+			param: &parameter{Name: "id", In: inPath},
+			want:  []string{"path.id is required"},
+		},
+		{
+			name:  "missing optional parameter",
+			param: &parameter{Name: "q", In: inQuery},
+		},
+		{
+			name:    "no schema",
+			param:   &parameter{Name: "q", In: inQuery},
+			values:  []string{"x"},
+			present: true,
+		},
+		{
+			name:    "object parameter",
+			param:   &parameter{Name: "q", In: inQuery, Schema: compileSchema(t, `{type: object, required: [a]}`, false)},
+			values:  []string{"x"},
+			present: true,
+		},
+		{
+			name:    "boolean parameter",
+			param:   &parameter{Name: "q", In: inQuery, Schema: compileSchema(t, `{type: boolean}`, false)},
+			values:  []string{"true"},
+			present: true,
+		},
+		{
+			name:    "invalid boolean parameter",
+			param:   &parameter{Name: "q", In: inQuery, Schema: compileSchema(t, `{type: boolean}`, false)},
+			values:  []string{"yes"},
+			present: true,
+			want:    []string{"query.q must be of type boolean"},
+		},
+		{
+			name:    "form array",
+			param:   &parameter{Name: "q", In: inQuery, Explode: noExplode, Schema: compileSchema(t, `{type: array, items: {type: integer}}`, false)},
+			values:  []string{"1, 2,x"},
+			present: true,
+			want:    []string{"query.q[2] must be of type integer"},
+		},
+		{
+			name:    "space delimited array",
+			param:   &parameter{Name: "q", In: inQuery, Style: "spaceDelimited", Schema: compileSchema(t, `{type: array, maxItems: 1}`, false)},
+			values:  []string{"a b"},
+			present: true,
+			want:    []string{"query.q must contain at maximum 1 items"},
+		},
+		{
+			name:    "pipe delimited array",
+			param:   &parameter{Name: "q", In: inQuery, Style: "pipeDelimited", Schema: compileSchema(t, `{type: array, minItems: 3}`, false)},
+			values:  []string{"a|b"},
+			present: true,
+			want:    []string{"query.q must contain at least 3 items"},
+		},
+		{
+			name:    "simple header array",
+			param:   &parameter{Name: "X-Q", In: inHeader, Schema: compileSchema(t, `{type: array, items: {type: number}}`, false)},
+			values:  []string{"1.5,2"},
+			present: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			val, err := defaultValidator()
+			require.NoError(t, err)
+
+			c := &checker{v: val}
+			c.checkParam(tt.param, tt.values, tt.present)
+
+			var got []string
+			for _, e := range c.errs {
+				got = append(got, e.Error())
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseScalar(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "1", parseScalar(nil, "1"))
+	require.Equal(t, "0x10", parseScalar(&schema{Type: schemaTypes{typeInteger}}, "0x10"))
+	require.Equal(t, "Inf", parseScalar(&schema{Type: schemaTypes{typeNumber}}, "Inf"))
+}
@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/httputil"
+)
+
+// recorder buffers a response, so it can be validated before being sent.
+type recorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// newRecorder returns an empty recorder.
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header)}
+}
+
+// Header implements http.ResponseWriter.
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+// WriteHeader implements http.ResponseWriter, keeping the first status.
+func (rec *recorder) WriteHeader(status int) {
+	if rec.code == 0 {
+		rec.code = status
+	}
+}
+
+// Write implements http.ResponseWriter. Like net/http, it detects the
+// content type of the first write when it is not set.
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+
+	if rec.body.Len() == 0 && len(b) > 0 && rec.header.Get(httputil.HeaderContentType) == "" {
+		rec.header.Set(httputil.HeaderContentType, http.DetectContentType(b))
+	}
+
+	return rec.body.Write(b) //nolint:wrapcheck // bytes.Buffer writes never fail
+}
+
+// status returns the recorded status, 200 when not set.
+func (rec *recorder) status() int {
+	if rec.code == 0 {
+		return http.StatusOK
+	}
+
+	return rec.code
+}
+
+// writeTo sends the recorded response to w.
+func (rec *recorder) writeTo(w http.ResponseWriter) {
+	h := w.Header()
+
+	for k, v := range rec.header {
+		h[k] = v
+	}
+
+	w.WriteHeader(rec.status())
+
+	_, _ = w.Write(rec.body.Bytes())
+}
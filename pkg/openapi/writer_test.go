@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	require.Equal(t, http.StatusOK, rec.status())
+
+	rec.Header().Set("X-A", "1")
+	rec.WriteHeader(http.StatusAccepted)
+	rec.WriteHeader(http.StatusTeapot)
+
+	n, err := rec.Write([]byte("<html></html>"))
+	require.NoError(t, err)
+	require.Equal(t, 13, n)
+
+	_, err = rec.Write([]byte("more"))
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusAccepted, rec.status())
+	require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	rr := httptest.NewRecorder()
+	rec.writeTo(rr)
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Equal(t, "1", rr.Header().Get("X-A"))
+	require.Equal(t, "<html></html>more", rr.Body.String())
+}
+
+func TestRecorder_contentType(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	rec.Header().Set("Content-Type", "application/json")
+
+	_, err := rec.Write([]byte("{}"))
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, rec.status())
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+}
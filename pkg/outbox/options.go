@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+// Option configures the [Outbox].
+type Option func(*Outbox)
+
+// WithTable sets the name of the outbox table (default [DefaultTable]). The
+// name is used verbatim in the queries, so it must be trusted. An empty name is
+// ignored.
+func WithTable(name string) Option {
+	return func(o *Outbox) {
+		if name != "" {
+			o.table = name
+		}
+	}
+}
+
+// WithDollarPlaceholders makes the queries use the numbered "$n" placeholders
+// required by PostgreSQL, instead of "?".
+func WithDollarPlaceholders() Option {
+	return func(o *Outbox) {
+		o.dollar = true
+	}
+}
+
+// RelayOption configures the [Relay].
+type RelayOption func(*Relay)
+
+// WithInterval sets the pause between two relay runs (default 1 second).
+// Non-positive values are ignored.
+func WithInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		if interval > 0 {
+			r.interval = interval
+		}
+	}
+}
+
+// WithJitter sets the maximum random jitter added to the interval (default
+// 100 milliseconds), spreading the runs of several instances. Negative values
+// are ignored.
+func WithJitter(jitter time.Duration) RelayOption {
+	return func(r *Relay) {
+		if jitter >= 0 {
+			r.jitter = jitter
+		}
+	}
+}
+
+// WithRunTimeout sets the timeout of a relay run (default 30 seconds).
+// Non-positive values are ignored.
+func WithRunTimeout(timeout time.Duration) RelayOption {
+	return func(r *Relay) {
+		if timeout > 0 {
+			r.timeout = timeout
+		}
+	}
+}
+
+// WithBatchSize sets the maximum number of messages published (or failed) by a
+// relay run, and read by each query (default 100). Non-positive values are
+// ignored.
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		if size > 0 {
+			r.batchSize = size
+		}
+	}
+}
+
+// WithMaxAttempts sets the number of failed publishing attempts after which a
+// message is marked as dead. Zero (the default) retries the messages forever,
+// so a failing message blocks the following ones with the same key.
+// Negative values are ignored.
+func WithMaxAttempts(attempts int) RelayOption {
+	return func(r *Relay) {
+		if attempts >= 0 {
+			r.maxAttempts = attempts
+		}
+	}
+}
+
+// WithRetention makes each relay run delete the messages sent more than
+// retention ago. Zero (the default) keeps them. Negative values are ignored.
+func WithRetention(retention time.Duration) RelayOption {
+	return func(r *Relay) {
+		if retention >= 0 {
+			r.retention = retention
+		}
+	}
+}
+
+// WithLocker sets the distributed lock, acquired with key by each relay run,
+// so a single instance relays at a time. A *mysqllock.MySQLLock is a Locker.
+// The run is skipped when another instance holds the lock.
+func WithLocker(locker Locker, key string) RelayOption {
+	return func(r *Relay) {
+		r.locker = locker
+		r.lockKey = key
+	}
+}
+
+// WithMetrics sets the metrics client counting the failures through
+// IncErrorCounter("outbox", operation, code): the operation is the message
+// topic with code "publish_error" or "dead", or "relay" with code
+// "store_error" or "lock_error". A nil client disables the metrics.
+func WithMetrics(m metrics.Client) RelayOption {
+	return func(r *Relay) {
+		r.metrics = m
+	}
+}
+
+// WithLogger sets the logger of the relay run errors (default
+// slog.Default()). A nil logger restores slog.Default().
+func WithLogger(logger *slog.Logger) RelayOption {
+	return func(r *Relay) {
+		r.logger = logger
+	}
+}
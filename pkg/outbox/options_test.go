@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+func TestWithTable(t *testing.T) {
+	t.Parallel()
+
+	o := &Outbox{table: DefaultTable}
+
+	WithTable("")(o)
+	require.Equal(t, DefaultTable, o.table)
+
+	WithTable("events")(o)
+	require.Equal(t, "events", o.table)
+}
+
+func TestWithDollarPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	o := &Outbox{}
+	WithDollarPlaceholders()(o)
+	require.True(t, o.dollar)
+}
+
+func TestRelayOptions(t *testing.T) {
+	t.Parallel()
+
+	r := &Relay{}
+
+	WithInterval(0)(r)
+	WithJitter(-1)(r)
+	WithRunTimeout(0)(r)
+	WithBatchSize(0)(r)
+	WithMaxAttempts(-1)(r)
+	WithRetention(-1)(r)
+	require.Equal(t, &Relay{}, r)
+
+	m := &metrics.Default{}
+	l := &fakeLocker{}
+	logger := slog.Default()
+
+	WithInterval(time.Second)(r)
+	WithJitter(time.Millisecond)(r)
+	WithRunTimeout(time.Minute)(r)
+	WithBatchSize(10)(r)
+	WithMaxAttempts(5)(r)
+	WithRetention(time.Hour)(r)
+	WithLocker(l, "key")(r)
+	WithMetrics(m)(r)
+	WithLogger(logger)(r)
+
+	require.Equal(t, &Relay{
+		interval:    time.Second,
+		jitter:      time.Millisecond,
+		timeout:     time.Minute,
+		batchSize:   10,
+		maxAttempts: 5,
+		retention:   time.Hour,
+		locker:      l,
+		lockKey:     "key",
+		metrics:     m,
+		logger:      logger,
+	}, r)
+}
@@ -0,0 +1,334 @@
+/*
+Package outbox implements the transactional outbox pattern: the messages to
+publish are stored in a database table by the same transaction updating the
+business data, and a relay publishes them afterwards to kafka, sqs, or any
+other [Publisher]. Either both the update and the message are committed, or
+neither is.
+
+The table must exist, with an auto-increment primary key:
+
+	CREATE TABLE outbox (
+	  id            BIGINT        NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	  aggregate_key VARCHAR(255)  NOT NULL,
+	  topic         VARCHAR(255)  NOT NULL,
+	  payload       BLOB          NOT NULL,
+	  headers       TEXT          NOT NULL,
+	  status        INTEGER       NOT NULL,
+	  attempts      INTEGER       NOT NULL,
+	  last_error    VARCHAR(1024) NOT NULL,
+	  created_at    BIGINT        NOT NULL,
+	  updated_at    BIGINT        NOT NULL,
+	  INDEX outbox_status_id (status, id)
+	);
+
+The status column is 0 for the pending messages, 1 for the sent ones and 2 for
+the dead ones.
+
+# Enqueueing
+
+[Outbox.Enqueue] inserts the messages with the transaction of a
+github.com/tecnickcom/nurago/pkg/sqltransaction or
+github.com/tecnickcom/nurago/pkg/sqlxtransaction ExecFunc:
+
+	ob, err := outbox.New(db)
+	// ...
+	err = sqltransaction.Exec(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+	    // ... update the order ...
+	    return ob.Enqueue(ctx, tx, outbox.Message{
+	        Key:     orderID,
+	        Topic:   "orders",
+	        Payload: event,
+	    })
+	})
+
+# Relaying
+
+A [Relay] periodically (github.com/tecnickcom/nurago/pkg/periodic) reads the
+pending messages in insertion order, publishes them and marks them as sent:
+
+	relay, err := outbox.NewRelay(ob, outbox.Router{
+	    "orders":   outbox.KafkaPublisher(producer),
+	    "invoices": outbox.SQSPublisher(queue),
+	},
+	    outbox.WithLocker(mysqllock.New(db), "outbox-relay"),
+	    outbox.WithMetrics(metricsClient),
+	)
+	// ...
+	relay.Start(ctx)
+	defer relay.Stop()
+
+The relay behaves as follows:
+
+  - Delivery is at-least-once: a message published but not marked as sent (a
+    crash, a database failure) is published again, so the consumers must be
+    idempotent.
+  - The messages with the same aggregate key are published in insertion order:
+    after a failure, the following messages of the key wait for the failed one
+    to be retried on the next run, while the other keys proceed: the relay
+    reads past the messages of the blocked keys.
+  - A failed message is retried on each run. With [WithMaxAttempts], a message
+    failing too many times is marked as dead and no longer blocks its key.
+  - With [WithLocker] (e.g. a github.com/tecnickcom/nurago/pkg/mysqllock lock),
+    only the instance holding the lock relays on each run, so several service
+    instances can run a relay.
+  - The failures are counted with
+    [github.com/tecnickcom/nurago/pkg/metrics.Client.IncErrorCounter] and
+    logged.
+
+The kafka producers must be synchronous (the default): an asynchronous producer
+reports a message as sent before its delivery.
+
+The queries use the "?" placeholder by default; use [WithDollarPlaceholders]
+for PostgreSQL.
+*/
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultTable is the default name of the outbox table.
+const DefaultTable = "outbox"
+
+// maxErrorLen is the size of the last_error column.
+const maxErrorLen = 1024
+
+// Message status values stored in the outbox table; the sent messages have
+// status 1.
+const (
+	statusPending = 0
+	statusDead    = 2
+)
+
+var (
+	// ErrNilDB is returned by [New] when the database handle is nil, and by
+	// [NewRelay] when the outbox is nil.
+	ErrNilDB = errors.New("outbox: nil database")
+
+	// ErrInvalidOptions is returned by [NewRelay] for an invalid configuration.
+	ErrInvalidOptions = errors.New("outbox: invalid options")
+
+	// ErrNilPublisher is returned by [NewRelay] when the publisher is nil.
+	ErrNilPublisher = errors.New("outbox: nil publisher")
+
+	// ErrInvalidMessage is returned by [Outbox.Enqueue] for a message without
+	// topic.
+	ErrInvalidMessage = errors.New("outbox: invalid message")
+
+	// ErrUnknownTopic is returned by a [Router] for a topic without publisher.
+	ErrUnknownTopic = errors.New("outbox: unknown topic")
+)
+
+// Execer executes a statement, like *sql.Tx and *sqlx.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Message is an outbox message.
+type Message struct {
+	// ID is the outbox table ID, set by the relay.
+	ID int64
+
+	// Key is the aggregate key: the messages with the same key are published
+	// in order. It is the kafka message key.
+	Key string
+
+	// Topic is the destination of the message, used by a [Router].
+	Topic string
+
+	// Payload is the message content.
+	Payload []byte
+
+	// Headers are the optional message headers.
+	Headers map[string]string
+
+	// Attempts is the number of previous failed publishing attempts, set by
+	// the relay.
+	Attempts int
+}
+
+// Outbox stores the messages in a database table.
+type Outbox struct {
+	db     *sql.DB
+	table  string
+	dollar bool
+	nowFn  func() time.Time
+
+	qryInsert  string
+	qryPending string
+	qrySent    string
+	qryFailed  string
+	qryPurge   string
+}
+
+// New returns an Outbox storing the messages in a table of db.
+func New(db *sql.DB, opts ...Option) (*Outbox, error) {
+	if db == nil {
+		return nil, ErrNilDB
+	}
+
+	o := &Outbox{
+		db:    db,
+		table: DefaultTable,
+		nowFn: time.Now,
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(o)
+	}
+
+	o.qryInsert = o.bind("INSERT INTO " + o.table + " (aggregate_key, topic, payload, headers, status, attempts, last_error, created_at, updated_at) VALUES (?, ?, ?, ?, 0, 0, '', ?, ?)")
+	o.qryPending = o.bind("SELECT id, aggregate_key, topic, payload, headers, attempts FROM " + o.table + " WHERE status = 0 AND id > ? ORDER BY id LIMIT ?")
+	o.qrySent = o.bind("UPDATE " + o.table + " SET status = 1, updated_at = ? WHERE id = ? AND status = 0")
+	o.qryFailed = o.bind("UPDATE " + o.table + " SET status = ?, attempts = ?, last_error = ?, updated_at = ? WHERE id = ? AND status = 0")
+	o.qryPurge = o.bind("DELETE FROM " + o.table + " WHERE status = 1 AND updated_at < ?")
+
+	return o, nil
+}
+
+// Enqueue inserts the messages in the outbox table with tx, the transaction
+// updating the business data. The messages are published by a [Relay] after
+// the transaction commits.
+func (o *Outbox) Enqueue(ctx context.Context, tx Execer, msgs ...Message) error {
+	now := o.nowFn().UnixMilli()
+
+	for i := range msgs {
+		m := &msgs[i]
+
+		if m.Topic == "" {
+			return fmt.Errorf("message %d: missing topic: %w", i, ErrInvalidMessage)
+		}
+
+		_, err := tx.ExecContext(ctx, o.qryInsert, m.Key, m.Topic, m.Payload, encodeHeaders(m.Headers), now, now)
+		if err != nil {
+			return fmt.Errorf("outbox: unable to insert message %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// pending returns up to limit pending messages with an ID greater than
+// afterID, in insertion order.
+func (o *Outbox) pending(ctx context.Context, afterID int64, limit int) (msgs []Message, err error) { //nolint:nonamedreturns
+	rows, err := o.db.QueryContext(ctx, o.qryPending, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: unable to read the pending messages: %w", err)
+	}
+
+	defer func() { err = errors.Join(err, rows.Close()) }()
+
+	for rows.Next() {
+		var (
+			m       Message
+			headers string
+		)
+
+		err = rows.Scan(&m.ID, &m.Key, &m.Topic, &m.Payload, &headers, &m.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("outbox: unable to scan a pending message: %w", err)
+		}
+
+		err = json.Unmarshal([]byte(headers), &m.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("outbox: unable to decode the headers of message %d: %w", m.ID, err)
+		}
+
+		msgs = append(msgs, m)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("outbox: unable to read the pending messages: %w", err)
+	}
+
+	return msgs, nil
+}
+
+// markSent marks a message as sent.
+func (o *Outbox) markSent(ctx context.Context, id int64) error {
+	_, err := o.db.ExecContext(ctx, o.qrySent, o.nowFn().UnixMilli(), id)
+	if err != nil {
+		return fmt.Errorf("outbox: unable to mark message %d as sent: %w", id, err)
+	}
+
+	return nil
+}
+
+// markFailed records a failed publishing attempt of a message, and marks it as
+// dead when dead is true.
+func (o *Outbox) markFailed(ctx context.Context, m *Message, cause error, dead bool) error {
+	status := statusPending
+	if dead {
+		status = statusDead
+	}
+
+	_, err := o.db.ExecContext(ctx, o.qryFailed,
+		status, m.Attempts+1, truncate(cause.Error(), maxErrorLen), o.nowFn().UnixMilli(), m.ID)
+	if err != nil {
+		return fmt.Errorf("outbox: unable to record the failure of message %d: %w", m.ID, err)
+	}
+
+	return nil
+}
+
+// purge deletes the messages sent before the given time.
+func (o *Outbox) purge(ctx context.Context, before time.Time) error {
+	_, err := o.db.ExecContext(ctx, o.qryPurge, before.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("outbox: unable to purge the sent messages: %w", err)
+	}
+
+	return nil
+}
+
+// bind replaces the "?" placeholders of query with "$n" ones when configured.
+func (o *Outbox) bind(query string) string {
+	if !o.dollar {
+		return query
+	}
+
+	var b strings.Builder
+
+	n := 0
+
+	for _, c := range query {
+		if c != '?' {
+			b.WriteRune(c)
+			continue
+		}
+
+		n++
+
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return b.String()
+}
+
+// encodeHeaders returns the JSON encoding of h, which cannot fail.
+func encodeHeaders(h map[string]string) string {
+	data, _ := json.Marshal(h) //nolint:errchkjson
+	return string(data)
+}
+
+// truncate returns s truncated to n bytes, without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.UnixMilli(1_000_000)
+
+var pendingCols = []string{"id", "aggregate_key", "topic", "payload", "headers", "attempts"}
+
+func newTestOutbox(t *testing.T, opts ...Option) (*Outbox, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	o, err := New(db, opts...)
+	require.NoError(t, err)
+
+	o.nowFn = func() time.Time { return testNow }
+
+	return o, mock
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	o, err := New(nil)
+	require.ErrorIs(t, err, ErrNilDB)
+	require.Nil(t, o)
+
+	o, _ = newTestOutbox(t, WithTable(""))
+	require.Equal(t, "UPDATE outbox SET status = 1, updated_at = ? WHERE id = ? AND status = 0", o.qrySent)
+
+	o, _ = newTestOutbox(t, WithTable("events_outbox"), WithDollarPlaceholders())
+	require.Equal(t, "UPDATE events_outbox SET status = 1, updated_at = $1 WHERE id = $2 AND status = 0", o.qrySent)
+}
+
+func TestOutbox_Enqueue(t *testing.T) {
+	t.Parallel()
+
+	errDB := errors.New("db error")
+
+	msgs := []Message{
+		{Key: "order-1", Topic: "orders", Payload: []byte("created"), Headers: map[string]string{"type": "created"}},
+		{Key: "order-1", Topic: "orders", Payload: []byte("paid")},
+	}
+
+	tests := []struct {
+		name    string
+		msgs    []Message
+		setup   func(o *Outbox, mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "success",
+			msgs: msgs,
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(o.qryInsert).
+					WithArgs("order-1", "orders", []byte("created"), `{"type":"created"}`, int64(1_000_000), int64(1_000_000)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(o.qryInsert).
+					WithArgs("order-1", "orders", []byte("paid"), "null", int64(1_000_000), int64(1_000_000)).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+			},
+		},
+		{
+			name: "no messages",
+			setup: func(_ *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+			},
+		},
+		{
+			name: "missing topic",
+			msgs: []Message{{Key: "k"}},
+			setup: func(_ *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+			},
+			wantErr: ErrInvalidMessage,
+		},
+		{
+			name: "insert error",
+			msgs: msgs,
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(o.qryInsert).WillReturnError(errDB)
+			},
+			wantErr: errDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, mock := newTestOutbox(t)
+			tt.setup(o, mock)
+
+			tx, err := o.db.Begin()
+			require.NoError(t, err)
+
+			err = o.Enqueue(t.Context(), tx, tt.msgs...)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutbox_pending(t *testing.T) {
+	t.Parallel()
+
+	errDB := errors.New("db error")
+
+	tests := []struct {
+		name    string
+		setup   func(o *Outbox, mock sqlmock.Sqlmock)
+		want    []Message
+		wantErr bool
+	}{
+		{
+			name: "success",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(5), 10).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), `{"h":"v"}`, 0).
+					AddRow(2, "b", "orders", []byte("p2"), "null", 3))
+			},
+			want: []Message{
+				{ID: 1, Key: "a", Topic: "orders", Payload: []byte("p1"), Headers: map[string]string{"h": "v"}},
+				{ID: 2, Key: "b", Topic: "orders", Payload: []byte("p2"), Attempts: 3},
+			},
+		},
+		{
+			name: "query error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnError(errDB)
+			},
+			wantErr: true,
+		},
+		{
+			name: "scan error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow("x", "a", "orders", []byte("p1"), "null", 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "headers error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "{", 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "rows error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+					RowError(0, errDB))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, mock := newTestOutbox(t)
+			tt.setup(o, mock)
+
+			got, err := o.pending(t.Context(), 5, 10)
+			require.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutbox_markFailed(t *testing.T) {
+	t.Parallel()
+
+	o, mock := newTestOutbox(t)
+
+	long := strings.Repeat("é", maxErrorLen)
+
+	mock.ExpectExec(o.qryFailed).
+		WithArgs(statusDead, 3, long[:maxErrorLen], int64(1_000_000), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(o.qryFailed).WillReturnError(errors.New("db error"))
+
+	m := &Message{ID: 7, Attempts: 2}
+
+	require.NoError(t, o.markFailed(t.Context(), m, errors.New(long), true))
+	require.Error(t, o.markFailed(t.Context(), m, errors.New("x"), false))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_truncate(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "abc", truncate("abc", 3))
+	require.Equal(t, "ab", truncate("abc", 2))
+	require.Equal(t, "a", truncate("aé", 2))
+	require.Equal(t, "aé", truncate("aéb", 3))
+}
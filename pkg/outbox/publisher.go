@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	nkafka "github.com/tecnickcom/nurago/pkg/kafka"
+)
+
+// Publisher publishes the outbox messages.
+type Publisher interface {
+	// Publish publishes the message, returning nil only once it is delivered.
+	Publish(ctx context.Context, m *Message) error
+}
+
+// PublisherFunc is a function implementing [Publisher].
+type PublisherFunc func(ctx context.Context, m *Message) error
+
+// Publish calls f(ctx, m).
+func (f PublisherFunc) Publish(ctx context.Context, m *Message) error {
+	return f(ctx, m)
+}
+
+// Router is a [Publisher] dispatching the messages to the publisher of their
+// topic. A message with another topic fails with an error matching
+// [ErrUnknownTopic], and it is retried like any other failure.
+type Router map[string]Publisher
+
+// Publish publishes the message with the publisher of its topic.
+func (r Router) Publish(ctx context.Context, m *Message) error {
+	p := r[m.Topic]
+	if p == nil {
+		return fmt.Errorf("%w %q", ErrUnknownTopic, m.Topic)
+	}
+
+	return p.Publish(ctx, m) //nolint:wrapcheck
+}
+
+// KafkaSender sends the kafka messages, like the
+// github.com/tecnickcom/nurago/pkg/kafka Producer.
+type KafkaSender interface {
+	SendMessage(ctx context.Context, msg nkafka.Message) error
+}
+
+// KafkaPublisher returns a [Publisher] sending the messages with p, ignoring
+// their topic: the aggregate key is the kafka message key, so the default
+// hash balancer keeps the messages of a key in order in one partition.
+func KafkaPublisher(p KafkaSender) Publisher {
+	return PublisherFunc(func(ctx context.Context, m *Message) error {
+		msg := nkafka.Message{Value: m.Payload}
+
+		if m.Key != "" {
+			msg.Key = []byte(m.Key)
+		}
+
+		for _, k := range slices.Sorted(maps.Keys(m.Headers)) {
+			msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: []byte(m.Headers[k])})
+		}
+
+		return p.SendMessage(ctx, msg) //nolint:wrapcheck
+	})
+}
+
+// SQSSender sends the SQS messages, like the
+// github.com/tecnickcom/nurago/pkg/sqs Client.
+type SQSSender interface {
+	Send(ctx context.Context, message string) error
+}
+
+// SQSPublisher returns a [Publisher] sending the message payloads with c,
+// ignoring their topic, key and headers.
+func SQSPublisher(c SQSSender) Publisher {
+	return PublisherFunc(func(ctx context.Context, m *Message) error {
+		return c.Send(ctx, string(m.Payload)) //nolint:wrapcheck
+	})
+}
+
+// SQSFIFOSender sends the SQS FIFO messages with a deduplication ID, like the
+// github.com/tecnickcom/nurago/pkg/sqs Client.
+type SQSFIFOSender interface {
+	SendWithDeduplicationID(ctx context.Context, message, dedupID string) error
+}
+
+// SQSFIFOPublisher returns a [Publisher] sending the message payloads to a
+// FIFO queue with c, ignoring their topic, key and headers. The deduplication
+// ID is derived from the message ID, so the queue drops the messages published
+// again within its deduplication interval.
+func SQSFIFOPublisher(c SQSFIFOSender) Publisher {
+	return PublisherFunc(func(ctx context.Context, m *Message) error {
+		return c.SendWithDeduplicationID(ctx, string(m.Payload), "outbox-"+strconv.FormatInt(m.ID, 10)) //nolint:wrapcheck
+	})
+}
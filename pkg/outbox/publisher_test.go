@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	nkafka "github.com/tecnickcom/nurago/pkg/kafka"
+)
+
+type fakeKafkaSender struct {
+	msgs []nkafka.Message
+	err  error
+}
+
+func (s *fakeKafkaSender) SendMessage(_ context.Context, msg nkafka.Message) error {
+	s.msgs = append(s.msgs, msg)
+	return s.err
+}
+
+type fakeSQSSender struct {
+	bodies   []string
+	dedupIDs []string
+	err      error
+}
+
+func (s *fakeSQSSender) Send(_ context.Context, message string) error {
+	s.bodies = append(s.bodies, message)
+	return s.err
+}
+
+func (s *fakeSQSSender) SendWithDeduplicationID(_ context.Context, message, dedupID string) error {
+	s.bodies = append(s.bodies, message)
+	s.dedupIDs = append(s.dedupIDs, dedupID)
+
+	return s.err
+}
+
+func TestRouter_Publish(t *testing.T) {
+	t.Parallel()
+
+	errSend := errors.New("send error")
+
+	var got []string
+
+	r := Router{
+		"orders": PublisherFunc(func(_ context.Context, m *Message) error {
+			got = append(got, m.Key)
+			return nil
+		}),
+		"failing": PublisherFunc(func(_ context.Context, _ *Message) error {
+			return errSend
+		}),
+	}
+
+	require.NoError(t, r.Publish(t.Context(), &Message{Key: "a", Topic: "orders"}))
+	require.ErrorIs(t, r.Publish(t.Context(), &Message{Topic: "failing"}), errSend)
+	require.ErrorIs(t, r.Publish(t.Context(), &Message{Topic: "other"}), ErrUnknownTopic)
+	require.Equal(t, []string{"a"}, got)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeKafkaSender{}
+	p := KafkaPublisher(s)
+
+	require.NoError(t, p.Publish(t.Context(), &Message{
+		Key:     "order-1",
+		Topic:   "orders",
+		Payload: []byte("created"),
+		Headers: map[string]string{"b": "2", "a": "1"},
+	}))
+	require.NoError(t, p.Publish(t.Context(), &Message{Topic: "orders", Payload: []byte("no key")}))
+
+	require.Equal(t, []nkafka.Message{
+		{
+			Key:     []byte("order-1"),
+			Value:   []byte("created"),
+			Headers: []kafka.Header{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2")}},
+		},
+		{Value: []byte("no key")},
+	}, s.msgs)
+
+	s.err = errors.New("send error")
+	require.ErrorIs(t, p.Publish(t.Context(), &Message{Topic: "orders"}), s.err)
+}
+
+func TestSQSPublisher(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSQSSender{}
+
+	require.NoError(t, SQSPublisher(s).Publish(t.Context(), &Message{ID: 3, Payload: []byte("body")}))
+	require.Equal(t, []string{"body"}, s.bodies)
+	require.Empty(t, s.dedupIDs)
+
+	s.err = errors.New("send error")
+	require.ErrorIs(t, SQSPublisher(s).Publish(t.Context(), &Message{}), s.err)
+}
+
+func TestSQSFIFOPublisher(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSQSSender{}
+
+	require.NoError(t, SQSFIFOPublisher(s).Publish(t.Context(), &Message{ID: 42, Payload: []byte("body")}))
+	require.Equal(t, []string{"body"}, s.bodies)
+	require.Equal(t, []string{"outbox-42"}, s.dedupIDs)
+}
@@ -0,0 +1,281 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/metrics"
+	"github.com/tecnickcom/nurago/pkg/mysqllock"
+	"github.com/tecnickcom/nurago/pkg/periodic"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultJitter    = 100 * time.Millisecond
+	defaultTimeout   = 30 * time.Second
+	defaultBatchSize = 100
+
+	// lockTimeout is the wait for the relay lock held by another instance.
+	lockTimeout = 100 * time.Millisecond
+
+	// metricsTask is the task label of the outbox error counters.
+	metricsTask = "outbox"
+
+	// metricsOpRelay is the operation label of the relay failures.
+	metricsOpRelay = "relay"
+
+	// metricsCodePublishError is the error counter code of a publishing failure.
+	metricsCodePublishError = "publish_error"
+
+	// metricsCodeDead is the error counter code of a message marked as dead.
+	metricsCodeDead = "dead"
+
+	// metricsCodeStoreError is the error counter code of a database failure.
+	metricsCodeStoreError = "store_error"
+
+	// metricsCodeLockError is the error counter code of a lock failure.
+	metricsCodeLockError = "lock_error"
+)
+
+// Locker acquires a distributed lock, like *mysqllock.MySQLLock.
+type Locker interface {
+	Acquire(ctx context.Context, key string, timeout time.Duration, opts ...mysqllock.AcquireOption) (mysqllock.ReleaseFunc, error)
+}
+
+// Relay publishes the pending outbox messages.
+type Relay struct {
+	outbox      *Outbox
+	publisher   Publisher
+	interval    time.Duration
+	jitter      time.Duration
+	timeout     time.Duration
+	batchSize   int
+	maxAttempts int
+	retention   time.Duration
+	locker      Locker
+	lockKey     string
+	metrics     metrics.Client
+	logger      *slog.Logger
+	periodic    *periodic.Periodic
+}
+
+// NewRelay returns a Relay publishing the messages of ob with pub.
+func NewRelay(ob *Outbox, pub Publisher, opts ...RelayOption) (*Relay, error) {
+	if ob == nil {
+		return nil, ErrNilDB
+	}
+
+	if pub == nil {
+		return nil, ErrNilPublisher
+	}
+
+	r := &Relay{
+		outbox:    ob,
+		publisher: pub,
+		interval:  defaultInterval,
+		jitter:    defaultJitter,
+		timeout:   defaultTimeout,
+		batchSize: defaultBatchSize,
+		metrics:   &metrics.Default{},
+		logger:    slog.Default(),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(r)
+	}
+
+	if r.locker != nil && r.lockKey == "" {
+		return nil, fmt.Errorf("empty lock key: %w", ErrInvalidOptions)
+	}
+
+	if r.metrics == nil {
+		r.metrics = &metrics.Default{}
+	}
+
+	if r.logger == nil {
+		r.logger = slog.Default()
+	}
+
+	p, err := periodic.New(r.interval, r.jitter, r.timeout, r.run)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+
+	r.periodic = p
+
+	return r, nil
+}
+
+// Start starts relaying the messages in a background goroutine, until ctx is
+// canceled or Stop is called.
+func (r *Relay) Start(ctx context.Context) {
+	r.periodic.Start(ctx)
+}
+
+// Stop stops relaying the messages and waits for the current run to complete.
+func (r *Relay) Stop() {
+	r.periodic.Stop()
+}
+
+// RunOnce publishes up to the batch size of pending messages, skipping those
+// of the keys blocked by a failure, and returns the number of messages sent.
+// With a locker, nothing is published while another instance holds the lock.
+// The publishing failures are recorded and joined in the returned error; a
+// database failure stops the run.
+//
+//nolint:nonamedreturns
+func (r *Relay) RunOnce(ctx context.Context) (sent int, err error) {
+	if r.locker == nil {
+		return r.relay(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	release, err := r.locker.Acquire(ctx, r.lockKey, lockTimeout,
+		mysqllock.WithLostLockHandler(func(error) { cancel() }))
+	if errors.Is(err, mysqllock.ErrTimeout) {
+		return 0, nil // another instance is relaying
+	}
+
+	if err != nil {
+		r.metrics.IncErrorCounter(metricsTask, metricsOpRelay, metricsCodeLockError)
+		return 0, fmt.Errorf("outbox: unable to acquire the relay lock: %w", err)
+	}
+
+	defer func() {
+		rerr := release()
+		if rerr != nil {
+			err = errors.Join(err, fmt.Errorf("outbox: unable to release the relay lock: %w", rerr))
+		}
+	}()
+
+	return r.relay(ctx)
+}
+
+// run is the periodic task: it relays the messages until a batch is not full.
+func (r *Relay) run(ctx context.Context) {
+	for {
+		sent, err := r.RunOnce(ctx)
+		if ctx.Err() != nil {
+			return // stopped
+		}
+
+		if err != nil {
+			r.logger.ErrorContext(ctx, "outbox relay failed", slog.Any("error", err))
+			return
+		}
+
+		if sent < r.batchSize {
+			return
+		}
+	}
+}
+
+// relay publishes a batch of pending messages. The messages following a
+// failure with the same key are skipped, and the pending messages are read
+// page by page past them, so a blocked key cannot fill the batch and stall the
+// other keys.
+func (r *Relay) relay(ctx context.Context) (int, error) {
+	var (
+		sent, done int
+		afterID    int64
+		errs       []error
+	)
+
+	blocked := make(map[string]struct{})
+
+pages:
+	for done < r.batchSize {
+		msgs, err := r.outbox.pending(ctx, afterID, r.batchSize)
+		if err != nil {
+			return sent, errors.Join(append(errs, r.storeError(err))...)
+		}
+
+		for i := range msgs {
+			m := &msgs[i]
+			afterID = m.ID
+
+			if _, ok := blocked[m.Key]; ok {
+				continue // keep the order of the key after a failure
+			}
+
+			if ctx.Err() != nil {
+				break pages
+			}
+
+			perr := r.publisher.Publish(ctx, m)
+			if perr == nil {
+				err = r.outbox.markSent(ctx, m.ID)
+				if err != nil {
+					return sent, errors.Join(append(errs, r.storeError(err))...)
+				}
+
+				sent++
+			} else {
+				if ctx.Err() != nil {
+					break pages // interrupted, not a failed attempt
+				}
+
+				blocked[m.Key] = struct{}{}
+
+				err = r.fail(ctx, m, perr)
+				if err != nil {
+					return sent, errors.Join(append(errs, err)...)
+				}
+
+				errs = append(errs, fmt.Errorf("outbox: unable to publish message %d: %w", m.ID, perr))
+			}
+
+			done++
+			if done == r.batchSize {
+				break pages
+			}
+		}
+
+		if len(msgs) < r.batchSize {
+			break // no more pending messages
+		}
+	}
+
+	if ctx.Err() != nil {
+		return sent, errors.Join(append(errs, fmt.Errorf("outbox: relay interrupted: %w", ctx.Err()))...)
+	}
+
+	if r.retention > 0 {
+		err := r.outbox.purge(ctx, r.outbox.nowFn().Add(-r.retention))
+		if err != nil {
+			errs = append(errs, r.storeError(err))
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// fail records a failed publishing attempt of m, marking it as dead after the
+// maximum number of attempts.
+func (r *Relay) fail(ctx context.Context, m *Message, cause error) error {
+	r.metrics.IncErrorCounter(metricsTask, m.Topic, metricsCodePublishError)
+
+	dead := r.maxAttempts > 0 && m.Attempts+1 >= r.maxAttempts
+
+	err := r.outbox.markFailed(ctx, m, cause, dead)
+	if err != nil {
+		return r.storeError(err)
+	}
+
+	if dead {
+		r.metrics.IncErrorCounter(metricsTask, m.Topic, metricsCodeDead)
+	}
+
+	return nil
+}
+
+// storeError counts and returns a database failure.
+func (r *Relay) storeError(err error) error {
+	r.metrics.IncErrorCounter(metricsTask, metricsOpRelay, metricsCodeStoreError)
+	return err
+}
@@ -0,0 +1,401 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+	"github.com/tecnickcom/nurago/pkg/mysqllock"
+)
+
+type fakeMetrics struct {
+	metrics.Default
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *fakeMetrics) IncErrorCounter(task, operation, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[task+"/"+operation+"/"+code]++
+}
+
+type fakeLocker struct {
+	err        error
+	releaseErr error
+	key        string
+	released   bool
+}
+
+func (l *fakeLocker) Acquire(_ context.Context, key string, _ time.Duration, _ ...mysqllock.AcquireOption) (mysqllock.ReleaseFunc, error) {
+	l.key = key
+
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	return func() error {
+		l.released = true
+		return l.releaseErr
+	}, nil
+}
+
+// fakePublisher records the published message IDs and fails the messages
+// listed in fail.
+type fakePublisher struct {
+	fail map[int64]error
+	ids  []int64
+}
+
+func (p *fakePublisher) Publish(_ context.Context, m *Message) error {
+	if err := p.fail[m.ID]; err != nil {
+		return err
+	}
+
+	p.ids = append(p.ids, m.ID)
+
+	return nil
+}
+
+func TestNewRelay(t *testing.T) {
+	t.Parallel()
+
+	o, _ := newTestOutbox(t)
+	pub := &fakePublisher{}
+
+	r, err := NewRelay(nil, pub)
+	require.ErrorIs(t, err, ErrNilDB)
+	require.Nil(t, r)
+
+	r, err = NewRelay(o, nil)
+	require.ErrorIs(t, err, ErrNilPublisher)
+	require.Nil(t, r)
+
+	r, err = NewRelay(o, pub, WithLocker(&fakeLocker{}, ""))
+	require.ErrorIs(t, err, ErrInvalidOptions)
+	require.Nil(t, r)
+
+	r, err = NewRelay(o, pub, WithMetrics(nil), WithLogger(nil))
+	require.NoError(t, err)
+	require.NotNil(t, r.metrics)
+	require.NotNil(t, r.logger)
+	require.Equal(t, defaultBatchSize, r.batchSize)
+}
+
+func TestRelay_RunOnce(t *testing.T) {
+	t.Parallel()
+
+	errDB := errors.New("db error")
+	errSend := errors.New("send error")
+
+	tests := []struct {
+		name       string
+		opts       []RelayOption
+		fail       map[int64]error
+		setup      func(o *Outbox, mock sqlmock.Sqlmock)
+		wantSent   int
+		wantIDs    []int64
+		wantErr    error
+		wantCounts map[string]int
+	}{
+		{
+			name: "success",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(0), defaultBatchSize).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+					AddRow(2, "b", "orders", []byte("p2"), "null", 0))
+				mock.ExpectExec(o.qrySent).WithArgs(int64(1_000_000), int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(o.qrySent).WithArgs(int64(1_000_000), int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantSent: 2,
+			wantIDs:  []int64{1, 2},
+		},
+		{
+			name: "no pending messages",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols))
+			},
+		},
+		{
+			name: "failure blocks the key",
+			fail: map[int64]error{1: errSend},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+					AddRow(2, "a", "orders", []byte("p2"), "null", 0).
+					AddRow(3, "b", "orders", []byte("p3"), "null", 0))
+				mock.ExpectExec(o.qryFailed).
+					WithArgs(statusPending, 1, "send error", int64(1_000_000), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(o.qrySent).WithArgs(int64(1_000_000), int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantSent:   1,
+			wantIDs:    []int64{3},
+			wantErr:    errSend,
+			wantCounts: map[string]int{"outbox/orders/publish_error": 1},
+		},
+		{
+			name: "blocked key longer than the batch",
+			opts: []RelayOption{WithBatchSize(2)},
+			fail: map[int64]error{1: errSend},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(0), 2).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 4).
+					AddRow(2, "a", "orders", []byte("p2"), "null", 0))
+				mock.ExpectExec(o.qryFailed).
+					WithArgs(statusPending, 5, "send error", int64(1_000_000), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(2), 2).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(3, "a", "orders", []byte("p3"), "null", 0).
+					AddRow(4, "a", "orders", []byte("p4"), "null", 0))
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(4), 2).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(5, "b", "orders", []byte("p5"), "null", 0).
+					AddRow(6, "c", "orders", []byte("p6"), "null", 0))
+				mock.ExpectExec(o.qrySent).WithArgs(int64(1_000_000), int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantSent:   1,
+			wantIDs:    []int64{5},
+			wantErr:    errSend,
+			wantCounts: map[string]int{"outbox/orders/publish_error": 1},
+		},
+		{
+			name: "pending error on a later page",
+			opts: []RelayOption{WithBatchSize(2)},
+			fail: map[int64]error{1: errSend},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(0), 2).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+					AddRow(2, "a", "orders", []byte("p2"), "null", 0))
+				mock.ExpectExec(o.qryFailed).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(o.qryPending).WithArgs(int64(2), 2).WillReturnError(errDB)
+			},
+			wantErr:    errDB,
+			wantCounts: map[string]int{"outbox/orders/publish_error": 1, "outbox/relay/store_error": 1},
+		},
+		{
+			name: "dead message",
+			opts: []RelayOption{WithMaxAttempts(3)},
+			fail: map[int64]error{1: errSend},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 2))
+				mock.ExpectExec(o.qryFailed).
+					WithArgs(statusDead, 3, "send error", int64(1_000_000), int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr:    errSend,
+			wantCounts: map[string]int{"outbox/orders/publish_error": 1, "outbox/orders/dead": 1},
+		},
+		{
+			name: "pending error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnError(errDB)
+			},
+			wantErr:    errDB,
+			wantCounts: map[string]int{"outbox/relay/store_error": 1},
+		},
+		{
+			name: "mark sent error",
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+					AddRow(2, "b", "orders", []byte("p2"), "null", 0))
+				mock.ExpectExec(o.qrySent).WillReturnError(errDB)
+			},
+			wantIDs:    []int64{1},
+			wantErr:    errDB,
+			wantCounts: map[string]int{"outbox/relay/store_error": 1},
+		},
+		{
+			name: "mark failed error",
+			fail: map[int64]error{1: errSend},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+					AddRow(1, "a", "orders", []byte("p1"), "null", 0))
+				mock.ExpectExec(o.qryFailed).WillReturnError(errDB)
+			},
+			wantErr:    errDB,
+			wantCounts: map[string]int{"outbox/orders/publish_error": 1, "outbox/relay/store_error": 1},
+		},
+		{
+			name: "retention",
+			opts: []RelayOption{WithRetention(time.Second)},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols))
+				mock.ExpectExec(o.qryPurge).WithArgs(int64(999_000)).WillReturnResult(sqlmock.NewResult(0, 5))
+			},
+		},
+		{
+			name: "retention error",
+			opts: []RelayOption{WithRetention(time.Second)},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols))
+				mock.ExpectExec(o.qryPurge).WillReturnError(errDB)
+			},
+			wantErr:    errDB,
+			wantCounts: map[string]int{"outbox/relay/store_error": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, mock := newTestOutbox(t)
+			tt.setup(o, mock)
+
+			pub := &fakePublisher{fail: tt.fail}
+			m := &fakeMetrics{}
+
+			r, err := NewRelay(o, pub, append(tt.opts, WithMetrics(m))...)
+			require.NoError(t, err)
+
+			sent, err := r.RunOnce(t.Context())
+			require.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tt.wantSent, sent)
+			require.Equal(t, tt.wantIDs, pub.ids)
+			require.Equal(t, tt.wantCounts, m.counts)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRelay_RunOnce_locker(t *testing.T) {
+	t.Parallel()
+
+	errLock := errors.New("lock error")
+
+	tests := []struct {
+		name       string
+		locker     *fakeLocker
+		setup      func(o *Outbox, mock sqlmock.Sqlmock)
+		wantErr    error
+		wantCounts map[string]int
+	}{
+		{
+			name:   "acquired",
+			locker: &fakeLocker{},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols))
+			},
+		},
+		{
+			name:   "held by another instance",
+			locker: &fakeLocker{err: mysqllock.ErrTimeout},
+		},
+		{
+			name:       "acquire error",
+			locker:     &fakeLocker{err: errLock},
+			wantErr:    errLock,
+			wantCounts: map[string]int{"outbox/relay/lock_error": 1},
+		},
+		{
+			name:   "release error",
+			locker: &fakeLocker{releaseErr: mysqllock.ErrLockLost},
+			setup: func(o *Outbox, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols))
+			},
+			wantErr: mysqllock.ErrLockLost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, mock := newTestOutbox(t)
+			if tt.setup != nil {
+				tt.setup(o, mock)
+			}
+
+			m := &fakeMetrics{}
+
+			r, err := NewRelay(o, &fakePublisher{}, WithLocker(tt.locker, "outbox-relay"), WithMetrics(m))
+			require.NoError(t, err)
+
+			sent, err := r.RunOnce(t.Context())
+			require.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			}
+
+			require.Zero(t, sent)
+			require.Equal(t, "outbox-relay", tt.locker.key)
+			require.Equal(t, tt.locker.err == nil, tt.locker.released)
+			require.Equal(t, tt.wantCounts, m.counts)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRelay_RunOnce_canceled(t *testing.T) {
+	t.Parallel()
+
+	o, mock := newTestOutbox(t)
+
+	mock.ExpectQuery(o.qryPending).WillReturnRows(sqlmock.NewRows(pendingCols).
+		AddRow(1, "a", "orders", []byte("p1"), "null", 0).
+		AddRow(2, "b", "orders", []byte("p2"), "null", 0))
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	pub := PublisherFunc(func(ctx context.Context, _ *Message) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	r, err := NewRelay(o, pub)
+	require.NoError(t, err)
+
+	sent, err := r.RunOnce(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, sent)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelay_StartStop(t *testing.T) {
+	t.Parallel()
+
+	o, mock := newTestOutbox(t)
+
+	// The first run drains two full batches, the second one fails.
+	mock.ExpectQuery(o.qryPending).WithArgs(int64(0), 1).WillReturnRows(sqlmock.NewRows(pendingCols).
+		AddRow(1, "a", "orders", []byte("p1"), "null", 0))
+	mock.ExpectExec(o.qrySent).WithArgs(int64(1_000_000), int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(o.qryPending).WithArgs(int64(0), 1).WillReturnRows(sqlmock.NewRows(pendingCols))
+	mock.ExpectQuery(o.qryPending).WithArgs(int64(0), 1).WillReturnError(errors.New("db error"))
+
+	pub := &fakePublisher{}
+
+	r, err := NewRelay(o, pub, WithBatchSize(1), WithInterval(time.Millisecond), WithJitter(0),
+		WithLogger(slog.New(slog.DiscardHandler)))
+	require.NoError(t, err)
+
+	r.Start(t.Context())
+
+	require.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, time.Millisecond)
+
+	r.Stop()
+
+	require.Equal(t, []int64{1}, pub.ids)
+}
@@ -0,0 +1,39 @@
+package ratelimit_test
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/ratelimit"
+)
+
+func ExampleLimiter_MiddlewareFn() {
+	limiter, err := ratelimit.New(
+		ratelimit.NewMemoryStore(),
+		ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 60, Period: time.Minute, Burst: 2},
+		ratelimit.WithKeyFunc(ratelimit.KeyByIP()),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	handler := limiter.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for range 3 {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		fmt.Printf("%d remaining=%s retry-after=%q\n", rr.Code, rr.Header().Get(ratelimit.HeaderRemaining), rr.Header().Get(ratelimit.HeaderRetryAfter))
+	}
+
+	// Output:
+	// 200 remaining=1 retry-after=""
+	// 200 remaining=0 retry-after=""
+	// 429 remaining=0 retry-after="1"
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/tecnickcom/nurago/pkg/jwt"
+)
+
+// ipv6PrefixBits is the length of the network IPv6 clients are grouped by, as
+// a single subscriber is usually assigned a whole /64.
+const ipv6PrefixBits = 64
+
+// KeyFunc returns the key a request is accounted to. An empty key exempts the
+// request from the limit.
+type KeyFunc func(r *http.Request) string
+
+// KeyByIP returns a [KeyFunc] keying the requests by the address of the peer
+// (http.Request.RemoteAddr). IPv6 clients are grouped by /64 network.
+func KeyByIP() KeyFunc {
+	return func(r *http.Request) string {
+		return ipKey(r.RemoteAddr)
+	}
+}
+
+// KeyByForwardedIP returns a [KeyFunc] keying the requests by the client
+// address reported through the X-Forwarded-For header by trustedProxies
+// reverse proxies: the address trustedProxies positions from the end of the
+// list, which the client cannot forge. It falls back to the peer address when
+// trustedProxies is not positive or the header lists too few addresses.
+func KeyByForwardedIP(trustedProxies int) KeyFunc {
+	return func(r *http.Request) string {
+		if trustedProxies < 1 {
+			return ipKey(r.RemoteAddr)
+		}
+
+		var hops []string
+
+		for _, v := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+
+		if len(hops) < trustedProxies {
+			return ipKey(r.RemoteAddr)
+		}
+
+		return ipKey(strings.TrimSpace(hops[len(hops)-trustedProxies]))
+	}
+}
+
+// KeyBySubject returns a [KeyFunc] keying the authenticated requests by the
+// subject of the verified JWT claims in the request context (see
+// [jwt.ClaimsFromContext]), so the limiter must run after the authentication
+// middleware. The other requests are keyed by fallback, or exempted when it
+// is nil.
+func KeyBySubject(fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		if claims, ok := jwt.ClaimsFromContext(r.Context()); ok && claims.Subject != "" {
+			return "sub:" + claims.Subject
+		}
+
+		if fallback == nil {
+			return ""
+		}
+
+		return fallback(r)
+	}
+}
+
+// ipKey returns the key of an "ip[:port]" address. Unparsable addresses are
+// kept verbatim so they are still limited.
+func ipKey(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return "ip:" + addr
+	}
+
+	ip = ip.Unmap()
+
+	if ip.Is6() {
+		prefix, _ := ip.Prefix(ipv6PrefixBits) //nolint:errcheck // cannot fail for a valid IPv6 address
+
+		return "ip:" + prefix.String()
+	}
+
+	return "ip:" + ip.String()
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/jwt"
+)
+
+func TestKeyByIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{name: "ipv4", addr: "192.0.2.1:1234", want: "ip:192.0.2.1"},
+		{name: "ipv4 without port", addr: "192.0.2.1", want: "ip:192.0.2.1"},
+		{name: "ipv4-mapped ipv6", addr: "[::ffff:192.0.2.1]:1234", want: "ip:192.0.2.1"},
+		{name: "ipv6", addr: "[2001:db8:1:2:3:4:5:6]:1234", want: "ip:2001:db8:1:2::/64"},
+		{name: "unparsable", addr: "pipe", want: "ip:pipe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.addr
+
+			require.Equal(t, tt.want, KeyByIP()(r))
+		})
+	}
+}
+
+func TestKeyByForwardedIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		proxies int
+		headers []string
+		want    string
+	}{
+		{name: "no proxies", proxies: 0, headers: []string{"198.51.100.1"}, want: "ip:192.0.2.1"},
+		{name: "no header", proxies: 1, want: "ip:192.0.2.1"},
+		{name: "one proxy", proxies: 1, headers: []string{"203.0.113.9, 198.51.100.1"}, want: "ip:198.51.100.1"},
+		{name: "two proxies", proxies: 2, headers: []string{"203.0.113.9, 198.51.100.1", "198.51.100.2"}, want: "ip:198.51.100.1"},
+		{name: "too few hops", proxies: 3, headers: []string{"198.51.100.1"}, want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+
+			for _, h := range tt.headers {
+				r.Header.Add("X-Forwarded-For", h)
+			}
+
+			require.Equal(t, tt.want, KeyByForwardedIP(tt.proxies)(r))
+		})
+	}
+}
+
+func TestKeyBySubject(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+
+	require.Empty(t, KeyBySubject(nil)(r))
+	require.Equal(t, "ip:192.0.2.1", KeyBySubject(KeyByIP())(r))
+
+	claims := &jwt.Claims{}
+	claims.Subject = "alice"
+	r = r.WithContext(jwt.ContextWithClaims(r.Context(), claims))
+
+	require.Equal(t, "sub:alice", KeyBySubject(KeyByIP())(r))
+}
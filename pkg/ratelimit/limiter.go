@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/httputil"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+const (
+	// DefaultName is the default limiter name (see [WithName]).
+	DefaultName = "http"
+
+	// metricsTask is the task label of the limiter error counters.
+	metricsTask = "ratelimit"
+
+	// metricsCodeRejected is the error counter code of a rejected request.
+	metricsCodeRejected = "rejected"
+
+	// metricsCodeStoreError is the error counter code of a store failure.
+	metricsCodeStoreError = "store_error"
+)
+
+// Rate-limit response headers.
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// ErrNilStore is returned by [New] when the store is nil.
+var ErrNilStore = errors.New("ratelimit: nil store")
+
+// Limiter enforces a [Limit] on HTTP requests.
+type Limiter struct {
+	store         Store
+	limit         Limit
+	policy        string
+	keyFn         KeyFunc
+	name          string
+	metrics       metrics.Client
+	logger        *slog.Logger
+	failClosed    bool
+	rejectHandler http.Handler
+	httpresp      *httputil.HTTPResp
+}
+
+// New constructs a Limiter applying limit through store. By default the
+// requests are keyed by peer address ([KeyByIP]) and rejected with a plain 429
+// Too Many Requests response.
+func New(store Store, limit Limit, opts ...Option) (*Limiter, error) {
+	if store == nil {
+		return nil, ErrNilStore
+	}
+
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+
+	l := &Limiter{
+		store:   store,
+		limit:   limit,
+		policy:  policyHeader(limit),
+		keyFn:   KeyByIP(),
+		name:    DefaultName,
+		metrics: &metrics.Default{},
+		logger:  slog.Default(),
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(l)
+	}
+
+	if l.keyFn == nil {
+		l.keyFn = KeyByIP()
+	}
+
+	if l.metrics == nil {
+		l.metrics = &metrics.Default{}
+	}
+
+	if l.logger == nil {
+		l.logger = slog.Default()
+	}
+
+	l.httpresp = httputil.NewHTTPResp(l.logger)
+
+	return l, nil
+}
+
+// Allow accounts one request to key, namespaced by the limiter name, and
+// reports the outcome.
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.store.Allow(ctx, l.name+":"+key, l.limit) //nolint:wrapcheck // the stores wrap their errors
+}
+
+// MiddlewareFn implements [httpserver.MiddlewareFn], limiting the requests to
+// next.
+func (l *Limiter) MiddlewareFn(args httpserver.MiddlewareArgs, next http.Handler) http.Handler {
+	logger := args.Logger
+	if logger == nil {
+		logger = l.logger
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := l.keyFn(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ctx := r.Context()
+
+		res, err := l.Allow(ctx, key)
+		if err != nil {
+			l.metrics.IncErrorCounter(metricsTask, l.name, metricsCodeStoreError)
+			httpserver.RequestLogger(logger, r).ErrorContext(ctx, "rate limit check failed",
+				slog.String("limiter", l.name),
+				slog.Any("error", err),
+			)
+
+			if l.failClosed {
+				l.httpresp.SendStatus(ctx, w, http.StatusServiceUnavailable)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		l.setHeaders(w.Header(), res)
+
+		if !res.Allowed {
+			l.metrics.IncErrorCounter(metricsTask, l.name, metricsCodeRejected)
+			httpserver.RequestLogger(logger, r).WarnContext(ctx, "rate limit exceeded",
+				slog.String("limiter", l.name),
+				slog.String("ratelimit_key", key),
+				slog.Duration("retry_after", res.RetryAfter),
+			)
+
+			w.Header().Set(HeaderRetryAfter, strconv.FormatInt(max(1, ceilSeconds(res.RetryAfter)), 10))
+			l.reject(w, r)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject writes the response of a rejected request.
+func (l *Limiter) reject(w http.ResponseWriter, r *http.Request) {
+	if l.rejectHandler != nil {
+		l.rejectHandler.ServeHTTP(w, r)
+
+		return
+	}
+
+	l.httpresp.SendStatus(r.Context(), w, http.StatusTooManyRequests)
+}
+
+// setHeaders sets the RateLimit-* headers of res.
+func (l *Limiter) setHeaders(h http.Header, res Result) {
+	h.Set(HeaderLimit, strconv.Itoa(res.Limit))
+	h.Set(HeaderRemaining, strconv.Itoa(max(0, res.Remaining)))
+	h.Set(HeaderReset, strconv.FormatInt(ceilSeconds(res.Reset), 10))
+	h.Set(HeaderPolicy, l.policy)
+}
+
+// policyHeader returns the RateLimit-Policy header value of limit: the
+// requests admitted per window (in seconds), with the token-bucket burst.
+func policyHeader(limit Limit) string {
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, max(1, ceilSeconds(limit.Period)))
+
+	if limit.Algorithm == TokenBucket {
+		policy += ";burst=" + strconv.Itoa(limit.quota())
+	}
+
+	return policy
+}
+
+// ceilSeconds returns d in whole seconds, rounded up.
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+
+	return int64((d + time.Second - 1) / time.Second)
+}
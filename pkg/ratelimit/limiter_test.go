@@ -0,0 +1,223 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/httpserver"
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+type fakeStore struct {
+	res Result
+	err error
+	key string
+}
+
+func (s *fakeStore) Allow(_ context.Context, key string, _ Limit) (Result, error) {
+	s.key = key
+
+	return s.res, s.err
+}
+
+type fakeMetrics struct {
+	metrics.Default
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *fakeMetrics) IncErrorCounter(task, operation, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+
+	m.counts[task+"/"+operation+"/"+code]++
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	l, err := New(nil, Limit{Requests: 1, Period: time.Second})
+	require.ErrorIs(t, err, ErrNilStore)
+	require.Nil(t, l)
+
+	l, err = New(NewMemoryStore(), Limit{})
+	require.ErrorIs(t, err, ErrInvalidLimit)
+	require.Nil(t, l)
+
+	l, err = New(
+		NewMemoryStore(),
+		Limit{Requests: 1, Period: time.Second},
+		WithKeyFunc(nil),
+		WithMetrics(nil),
+		WithLogger(nil),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, l.keyFn)
+	require.NotNil(t, l.metrics)
+	require.NotNil(t, l.logger)
+	require.NotNil(t, l.httpresp)
+	require.Equal(t, DefaultName, l.name)
+	require.Equal(t, "1;w=1;burst=1", l.policy)
+}
+
+func TestLimiter_MiddlewareFn(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+
+	l, err := New(
+		NewMemoryStore(),
+		Limit{Algorithm: SlidingWindow, Requests: 2, Period: time.Minute},
+		WithName("api"),
+		WithMetrics(m),
+	)
+	require.NoError(t, err)
+
+	h := l.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		h.ServeHTTP(rr, r)
+
+		return rr
+	}
+
+	for i := range 2 {
+		rr := serve()
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "2", rr.Header().Get(HeaderLimit))
+		require.Equal(t, []string{"1", "0"}[i], rr.Header().Get(HeaderRemaining))
+		require.NotEmpty(t, rr.Header().Get(HeaderReset))
+		require.Equal(t, "2;w=60", rr.Header().Get(HeaderPolicy))
+		require.Empty(t, rr.Header().Get(HeaderRetryAfter))
+	}
+
+	rr := serve()
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "0", rr.Header().Get(HeaderRemaining))
+	require.NotEmpty(t, rr.Header().Get(HeaderRetryAfter))
+	require.Equal(t, 1, m.counts["ratelimit/api/rejected"])
+}
+
+func TestLimiter_MiddlewareFn_headers(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{res: Result{
+		Limit:      5,
+		Remaining:  -1,
+		Reset:      1500 * time.Millisecond,
+		RetryAfter: time.Millisecond,
+	}}
+
+	called := false
+
+	l, err := New(
+		store,
+		Limit{Requests: 5, Period: 90 * time.Second, Burst: 2},
+		WithKeyFunc(func(_ *http.Request) string { return "k" }),
+		WithRejectHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			called = true
+
+			w.WriteHeader(http.StatusTeapot)
+		})),
+	)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	l.MiddlewareFn(httpserver.MiddlewareArgs{Logger: slog.Default()}, http.NotFoundHandler()).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.True(t, called)
+	require.Equal(t, http.StatusTeapot, rr.Code)
+	require.Equal(t, "http:k", store.key)
+	require.Equal(t, "5", rr.Header().Get(HeaderLimit))
+	require.Equal(t, "0", rr.Header().Get(HeaderRemaining))
+	require.Equal(t, "2", rr.Header().Get(HeaderReset))
+	require.Equal(t, "5;w=90;burst=2", rr.Header().Get(HeaderPolicy))
+	require.Equal(t, "1", rr.Header().Get(HeaderRetryAfter))
+}
+
+func TestLimiter_MiddlewareFn_exempt(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeStore{err: errors.New("unexpected call")}
+
+	l, err := New(store, Limit{Requests: 1, Period: time.Second}, WithKeyFunc(KeyBySubject(nil)))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	l.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Empty(t, store.key)
+	require.Empty(t, rr.Header().Get(HeaderLimit))
+}
+
+func TestLimiter_MiddlewareFn_storeError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantStatus int
+	}{
+		{
+			name:       "fail open",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "fail closed",
+			opts:       []Option{WithFailClosed()},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &fakeMetrics{}
+			opts := append([]Option{WithMetrics(m)}, tt.opts...)
+
+			l, err := New(&fakeStore{err: errors.New("down")}, Limit{Requests: 1, Period: time.Second}, opts...)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			l.MiddlewareFn(httpserver.MiddlewareArgs{}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, tt.wantStatus, rr.Code)
+			require.Empty(t, rr.Header().Get(HeaderLimit))
+			require.Equal(t, 1, m.counts["ratelimit/http/store_error"])
+		})
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, int64(0), ceilSeconds(-time.Second))
+	require.Equal(t, int64(0), ceilSeconds(0))
+	require.Equal(t, int64(1), ceilSeconds(time.Nanosecond))
+	require.Equal(t, int64(1), ceilSeconds(time.Second))
+	require.Equal(t, int64(2), ceilSeconds(1001*time.Millisecond))
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is the minimum time between two sweeps of the expired
+// [MemoryStore] entries.
+const memorySweepInterval = time.Minute
+
+// windowEntry is a [SlidingWindow] state with its expiration time.
+type windowEntry struct {
+	state   windowState
+	expires time.Time
+}
+
+// MemoryStore is an in-process [Store], suitable for services running as a
+// single instance. The state of each key is dropped once it no longer affects
+// the limit, in a sweep run at most once per minute.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]time.Time // token-bucket theoretical arrival times
+	windows   map[string]windowEntry
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+// NewMemoryStore returns an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]time.Time),
+		windows: make(map[string]windowEntry),
+		nowFn:   time.Now,
+	}
+}
+
+// Allow implements [Store].
+func (s *MemoryStore) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.nowFn()
+	s.sweep(now)
+
+	if limit.Algorithm == SlidingWindow {
+		res, st := slidingWindow(now, s.windows[key].state, limit)
+		s.windows[key] = windowEntry{
+			state:   st,
+			expires: time.UnixMicro((st.window + 2) * limit.Period.Microseconds()),
+		}
+
+		return res, nil
+	}
+
+	res, tat := tokenBucket(now, s.buckets[key], limit)
+	s.buckets[key] = tat
+
+	return res, nil
+}
+
+// sweep drops the expired entries. The caller must hold the lock.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, tat := range s.buckets {
+		if !tat.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+
+	for key, entry := range s.windows {
+		if !entry.expires.After(now) {
+			delete(s.windows, key)
+		}
+	}
+}
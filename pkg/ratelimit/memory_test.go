@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Allow(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	now := time.Unix(1000, 0)
+	s.nowFn = func() time.Time { return now }
+
+	bucket := Limit{Requests: 2, Period: time.Second}
+	window := Limit{Algorithm: SlidingWindow, Requests: 1, Period: time.Second}
+
+	for range 2 {
+		res, err := s.Allow(t.Context(), "a", bucket)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+
+	res, err := s.Allow(t.Context(), "a", bucket)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	res, err = s.Allow(t.Context(), "b", bucket)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "keys must be limited independently")
+
+	res, err = s.Allow(t.Context(), "a", window)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "algorithms must not share their state")
+
+	res, err = s.Allow(t.Context(), "a", window)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	_, err = s.Allow(t.Context(), "a", Limit{})
+	require.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	now := time.Unix(1000, 0)
+	s.nowFn = func() time.Time { return now }
+
+	_, err := s.Allow(t.Context(), "bucket", Limit{Requests: 1, Period: time.Second})
+	require.NoError(t, err)
+
+	_, err = s.Allow(t.Context(), "window", Limit{Algorithm: SlidingWindow, Requests: 1, Period: time.Second})
+	require.NoError(t, err)
+
+	require.Len(t, s.buckets, 1)
+	require.Len(t, s.windows, 1)
+
+	now = now.Add(memorySweepInterval)
+	s.sweep(now)
+
+	require.Empty(t, s.buckets)
+	require.Empty(t, s.windows)
+
+	// recent sweep: nothing is checked
+	s.buckets["stale"] = time.Time{}
+	s.sweep(now.Add(time.Second))
+	require.Len(t, s.buckets, 1)
+}
+
+func TestMemoryStore_concurrent(t *testing.T) {
+	t.Parallel()
+
+	s := NewMemoryStore()
+	limit := Limit{Requests: 50, Period: time.Hour}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+
+	for range 100 {
+		wg.Go(func() {
+			res, err := s.Allow(t.Context(), "k", limit)
+			if err == nil && res.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+
+	require.Equal(t, 50, allowed)
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/tecnickcom/nurago/pkg/metrics"
+)
+
+// Option is a type to allow setting custom limiter options.
+type Option func(*Limiter)
+
+// WithKeyFunc sets the function selecting the key each request is accounted
+// to. A nil function restores the default [KeyByIP].
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(l *Limiter) {
+		l.keyFn = fn
+	}
+}
+
+// WithName sets the limiter name (default [DefaultName]). It namespaces the
+// store keys, so limiters sharing a store must have distinct names, and it is
+// the operation label of the metrics.
+func WithName(name string) Option {
+	return func(l *Limiter) {
+		if name != "" {
+			l.name = name
+		}
+	}
+}
+
+// WithMetrics sets the metrics client counting the rejected requests and the
+// store failures through IncErrorCounter("ratelimit", name, code), with code
+// "rejected" or "store_error". A nil client disables the metrics.
+func WithMetrics(m metrics.Client) Option {
+	return func(l *Limiter) {
+		l.metrics = m
+	}
+}
+
+// WithLogger sets the logger used when the middleware receives none from
+// [github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareArgs], and by the
+// response writer. A nil logger restores slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *Limiter) {
+		l.logger = logger
+	}
+}
+
+// WithFailClosed rejects the requests with 503 Service Unavailable when the
+// store fails, instead of letting them through.
+func WithFailClosed() Option {
+	return func(l *Limiter) {
+		l.failClosed = true
+	}
+}
+
+// WithRejectHandler sets the handler writing the response of a rejected
+// request, e.g. to send a JSendX body. The RateLimit-* and Retry-After headers
+// are already set when it is called; it should send a 429 status.
+func WithRejectHandler(h http.Handler) Option {
+	return func(l *Limiter) {
+		l.rejectHandler = h
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithKeyFunc(t *testing.T) {
+	t.Parallel()
+
+	l := &Limiter{}
+	WithKeyFunc(func(_ *http.Request) string { return "x" })(l)
+	require.Equal(t, "x", l.keyFn(nil))
+}
+
+func TestWithName(t *testing.T) {
+	t.Parallel()
+
+	l := &Limiter{name: DefaultName}
+	WithName("")(l)
+	require.Equal(t, DefaultName, l.name)
+
+	WithName("login")(l)
+	require.Equal(t, "login", l.name)
+}
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := &fakeMetrics{}
+	l := &Limiter{}
+	WithMetrics(m)(l)
+	require.Equal(t, m, l.metrics)
+}
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	l := &Limiter{}
+	WithLogger(logger)(l)
+	require.Equal(t, logger, l.logger)
+}
+
+func TestWithFailClosed(t *testing.T) {
+	t.Parallel()
+
+	l := &Limiter{}
+	WithFailClosed()(l)
+	require.True(t, l.failClosed)
+}
+
+func TestWithRejectHandler(t *testing.T) {
+	t.Parallel()
+
+	h := http.NotFoundHandler()
+	l := &Limiter{}
+	WithRejectHandler(h)(l)
+	require.NotNil(t, l.rejectHandler)
+}
@@ -0,0 +1,253 @@
+/*
+Package ratelimit provides pluggable HTTP rate limiting for
+[github.com/tecnickcom/nurago/pkg/httpserver], protecting a service from noisy
+clients.
+
+A [Limiter] enforces one [Limit] per client key through a [Store] and exposes
+itself as an [github.com/tecnickcom/nurago/pkg/httpserver.MiddlewareFn], so it
+can be installed server-wide (httpserver.WithMiddlewareFn) or per route
+(httpserver.Route.Middleware):
+
+	limiter, err := ratelimit.New(
+	    ratelimit.NewMemoryStore(),
+	    ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Requests: 100, Period: time.Minute, Burst: 20},
+	    ratelimit.WithKeyFunc(ratelimit.KeyBySubject(ratelimit.KeyByIP())),
+	    ratelimit.WithMetrics(metricsClient),
+	)
+	// ...
+	route.Middleware = append(route.Middleware, limiter.MiddlewareFn)
+
+# Algorithms
+
+  - [TokenBucket] admits bursts of up to Burst requests and refills at a
+    steady Requests per Period. It is implemented as the Generic Cell Rate
+    Algorithm (GCRA), which keeps a single timestamp per key.
+  - [SlidingWindow] admits up to Requests per Period measured over a window
+    that slides with time, approximated by weighting the previous fixed
+    window's count by its overlap with the sliding one. It keeps two counters
+    per key and avoids the double bursts allowed at fixed-window boundaries.
+
+# Keys
+
+The [KeyFunc] selects the client a request is accounted to: [KeyByIP] (the
+peer address, grouping IPv6 clients by /64 network), [KeyByForwardedIP] (the
+client address behind trusted reverse proxies), [KeyBySubject] (the `sub`
+claim of a verified JWT), or any custom function. An empty key exempts the
+request.
+
+# Stores
+
+[MemoryStore] keeps the state in process, for single-instance services.
+[ScriptStore] runs the algorithms atomically as Lua scripts on a
+Redis-compatible server, sharing the limits between the instances of a
+service; [github.com/tecnickcom/nurago/pkg/luascript] binds it to a
+[github.com/tecnickcom/nurago/pkg/redis] or
+[github.com/tecnickcom/nurago/pkg/valkey] client:
+
+	eval, err := luascript.Valkey(vc)
+	// ...
+	store, err := ratelimit.NewScriptStore(eval, ratelimit.DefaultKeyPrefix)
+
+# Responses
+
+Every limited response carries the RateLimit-Limit, RateLimit-Remaining,
+RateLimit-Reset and RateLimit-Policy headers (IETF draft
+draft-ietf-httpapi-ratelimit-headers). A rejected request gets a 429 Too Many
+Requests with a Retry-After header (RFC 9110 §10.2.3). Rejections and store
+failures are counted through
+[github.com/tecnickcom/nurago/pkg/metrics.Client.IncErrorCounter]. A store
+failure lets the request through (fail open) unless [WithFailClosed] is set.
+*/
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Algorithm selects the rate-limiting algorithm of a [Limit].
+type Algorithm uint8
+
+const (
+	// TokenBucket admits bursts of up to Burst requests, refilled at Requests per Period.
+	TokenBucket Algorithm = iota
+
+	// SlidingWindow admits up to Requests per sliding Period.
+	SlidingWindow
+)
+
+// ErrInvalidLimit is returned when a [Limit] is not valid.
+var ErrInvalidLimit = errors.New("ratelimit: invalid limit")
+
+// Limit defines the rate admitted for each key.
+type Limit struct {
+	// Algorithm is the rate-limiting algorithm (default [TokenBucket]).
+	Algorithm Algorithm
+
+	// Requests is the number of requests admitted per Period.
+	Requests int
+
+	// Period is the time span the Requests are admitted over.
+	Period time.Duration
+
+	// Burst is the [TokenBucket] capacity: the number of requests that can be
+	// admitted at once after a quiet period. Zero means Requests. It is
+	// ignored by [SlidingWindow].
+	Burst int
+}
+
+// quota returns the maximum number of requests admitted at once.
+func (l Limit) quota() int {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Requests
+}
+
+// interval returns the [TokenBucket] refill time of one request.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Validate checks that the limit is usable.
+func (l Limit) Validate() error {
+	switch {
+	case l.Algorithm > SlidingWindow:
+		return fmt.Errorf("%w: unknown algorithm %d", ErrInvalidLimit, l.Algorithm)
+	case l.Requests < 1:
+		return fmt.Errorf("%w: requests must be positive", ErrInvalidLimit)
+	case l.Period < time.Millisecond:
+		return fmt.Errorf("%w: period must be at least one millisecond", ErrInvalidLimit)
+	case l.Burst < 0:
+		return fmt.Errorf("%w: burst must not be negative", ErrInvalidLimit)
+	case l.interval() < time.Microsecond:
+		return fmt.Errorf("%w: more than one request per microsecond", ErrInvalidLimit)
+	}
+
+	return nil
+}
+
+// Result is the outcome of a rate-limit check.
+type Result struct {
+	// Allowed reports whether the request is admitted.
+	Allowed bool
+
+	// Limit is the maximum number of requests admitted at once.
+	Limit int
+
+	// Remaining is the number of further requests admitted right now.
+	Remaining int
+
+	// Reset is the time until the quota is fully available again.
+	Reset time.Duration
+
+	// RetryAfter is, for a rejected request, the time until a request would be
+	// admitted. It is zero for admitted requests.
+	RetryAfter time.Duration
+}
+
+// Store applies a [Limit] to keys. Implementations must perform each check
+// atomically and be safe for concurrent use.
+type Store interface {
+	// Allow accounts one request to key under limit and reports the outcome.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// tokenBucket applies the GCRA form of the token bucket. tat is the
+// theoretical arrival time stored for the key (zero when none); it returns the
+// result and the new tat, which only advances for admitted requests.
+func tokenBucket(now, tat time.Time, limit Limit) (Result, time.Time) {
+	interval := limit.interval()
+	burst := limit.quota()
+	capacity := interval * time.Duration(burst)
+
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	diff := newTAT.Sub(now)
+
+	if diff > capacity {
+		return Result{
+			Limit:      burst,
+			Reset:      tat.Sub(now),
+			RetryAfter: diff - capacity,
+		}, tat
+	}
+
+	return Result{
+		Allowed:   true,
+		Limit:     burst,
+		Remaining: int((capacity - diff) / interval),
+		Reset:     diff,
+	}, newTAT
+}
+
+// windowState holds the [SlidingWindow] counters of a key.
+type windowState struct {
+	window int64 // index of the current fixed window
+	cur    int   // requests admitted in the current fixed window
+	prev   int   // requests admitted in the previous fixed window
+}
+
+// slidingWindow applies the sliding-window counter. It returns the result and
+// the updated state, which only counts admitted requests.
+func slidingWindow(now time.Time, st windowState, limit Limit) (Result, windowState) {
+	period := limit.Period.Microseconds()
+	nowUs := now.UnixMicro()
+	window := nowUs / period
+	elapsed := nowUs - window*period
+
+	if st.window != window {
+		if st.window == window-1 {
+			st.prev = st.cur
+		} else {
+			st.prev = 0
+		}
+
+		st.window = window
+		st.cur = 0
+	}
+
+	remain := period - elapsed // time left in the current fixed window
+	count := float64(st.prev)*float64(remain)/float64(period) + float64(st.cur)
+	reset := time.Duration(remain) * time.Microsecond
+
+	if count+1 > float64(limit.Requests) {
+		return Result{
+			Limit:      limit.Requests,
+			Reset:      reset,
+			RetryAfter: windowRetryAfter(st, remain, period, limit.Requests),
+		}, st
+	}
+
+	st.cur++
+
+	return Result{
+		Allowed:   true,
+		Limit:     limit.Requests,
+		Remaining: int(float64(limit.Requests) - count - 1),
+		Reset:     reset,
+	}, st
+}
+
+// windowRetryAfter returns the time until the weighted count leaves room for
+// one more request: within the current fixed window as the previous window's
+// weight decays, or else within the next one as the current window's does.
+func windowRetryAfter(st windowState, remain, period int64, requests int) time.Duration {
+	var wait float64
+
+	if st.cur+1 <= requests {
+		// prev > 0 here, otherwise the request would have been admitted.
+		wait = float64(remain) - float64(requests-st.cur-1)*float64(period)/float64(st.prev)
+	} else {
+		wait = float64(remain) + float64(period) - float64(requests-1)*float64(period)/float64(st.cur)
+	}
+
+	return time.Duration(max(1, int64(math.Ceil(wait)))) * time.Microsecond
+}
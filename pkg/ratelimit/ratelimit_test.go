@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimit_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		limit   Limit
+		wantErr bool
+	}{
+		{
+			name:  "token bucket",
+			limit: Limit{Requests: 10, Period: time.Second, Burst: 5},
+		},
+		{
+			name:  "sliding window",
+			limit: Limit{Algorithm: SlidingWindow, Requests: 10, Period: time.Minute},
+		},
+		{
+			name:    "unknown algorithm",
+			limit:   Limit{Algorithm: 9, Requests: 10, Period: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "zero requests",
+			limit:   Limit{Period: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "short period",
+			limit:   Limit{Requests: 1, Period: time.Microsecond},
+			wantErr: true,
+		},
+		{
+			name:    "negative burst",
+			limit:   Limit{Requests: 1, Period: time.Second, Burst: -1},
+			wantErr: true,
+		},
+		{
+			name:    "too fast",
+			limit:   Limit{Requests: 2000, Period: time.Millisecond},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.limit.Validate()
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLimit)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLimit_quota(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 10, Limit{Requests: 10}.quota())
+	require.Equal(t, 3, Limit{Requests: 10, Burst: 3}.quota())
+	require.Equal(t, 10, Limit{Algorithm: SlidingWindow, Requests: 10, Burst: 3}.quota())
+}
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	limit := Limit{Requests: 10, Period: time.Second, Burst: 3} // one token every 100ms
+	now := time.Unix(1000, 0)
+
+	var (
+		res Result
+		tat time.Time
+	)
+
+	for i := range 3 {
+		res, tat = tokenBucket(now, tat, limit)
+		require.True(t, res.Allowed)
+		require.Equal(t, 3, res.Limit)
+		require.Equal(t, 2-i, res.Remaining)
+		require.Equal(t, time.Duration(i+1)*100*time.Millisecond, res.Reset)
+		require.Zero(t, res.RetryAfter)
+	}
+
+	res, next := tokenBucket(now, tat, limit)
+	require.False(t, res.Allowed)
+	require.Equal(t, tat, next, "a rejected request must not consume a token")
+	require.Zero(t, res.Remaining)
+	require.Equal(t, 300*time.Millisecond, res.Reset)
+	require.Equal(t, 100*time.Millisecond, res.RetryAfter)
+
+	res, _ = tokenBucket(now.Add(res.RetryAfter), tat, limit)
+	require.True(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+
+	res, _ = tokenBucket(now.Add(time.Hour), tat, limit)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Remaining, "the bucket must not fill beyond the burst")
+}
+
+func TestSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	limit := Limit{Algorithm: SlidingWindow, Requests: 4, Period: 10 * time.Second}
+	start := time.Unix(1000, 0) // start of a fixed window
+
+	var (
+		res Result
+		st  windowState
+	)
+
+	for i := range 4 {
+		res, st = slidingWindow(start, st, limit)
+		require.True(t, res.Allowed)
+		require.Equal(t, 4, res.Limit)
+		require.Equal(t, 3-i, res.Remaining)
+		require.Equal(t, 10*time.Second, res.Reset)
+	}
+
+	res, st = slidingWindow(start.Add(time.Second), st, limit)
+	require.False(t, res.Allowed)
+	require.Equal(t, 4, st.cur, "a rejected request must not be counted")
+	require.Equal(t, 9*time.Second, res.Reset)
+	// the next window admits one request once prev*(1-e/P) <= 3, at e = 2.5s
+	require.Equal(t, 11500*time.Millisecond, res.RetryAfter)
+
+	// quarter of the next window: 4*0.75 = 3 weighted requests
+	res, st = slidingWindow(start.Add(12500*time.Millisecond), st, limit)
+	require.True(t, res.Allowed)
+	require.Zero(t, res.Remaining)
+	require.Equal(t, 4, st.prev)
+	require.Equal(t, 1, st.cur)
+
+	// 4*0.6 + 1 = 3.4: the request is rejected until the weight decays to 2
+	res, _ = slidingWindow(start.Add(14*time.Second), st, limit)
+	require.False(t, res.Allowed)
+	require.Equal(t, 6*time.Second, res.Reset)
+	require.Equal(t, time.Second, res.RetryAfter)
+
+	res, _ = slidingWindow(start.Add(15*time.Second), st, limit)
+	require.True(t, res.Allowed)
+
+	res, st = slidingWindow(start.Add(time.Minute), st, limit)
+	require.True(t, res.Allowed)
+	require.Equal(t, 3, res.Remaining)
+	require.Zero(t, st.prev, "a stale window must be forgotten")
+}
+
+func TestWindowRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	// current window full: wait for the next one plus the decay of cur
+	got := windowRetryAfter(windowState{cur: 2}, 5, 10, 2)
+	require.Equal(t, 10*time.Microsecond, got)
+
+	// never less than one microsecond
+	got = windowRetryAfter(windowState{cur: 0, prev: 1}, 1, 10, 1)
+	require.Equal(t, time.Microsecond, got)
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/luascript"
+)
+
+// DefaultKeyPrefix is the default prefix of the keys written by a [ScriptStore].
+const DefaultKeyPrefix = "ratelimit:"
+
+// tokenBucketScript applies the GCRA token bucket to KEYS[1], holding the
+// theoretical arrival time in microseconds. ARGV: interval (µs), burst.
+// The server clock is used, so the instances of a service need not agree on
+// the time.
+const tokenBucketScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local capacity = interval * tonumber(ARGV[2])
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+local newtat = tat + interval
+local diff = newtat - now
+if diff > capacity then
+  return {0, 0, tat - now, diff - capacity}
+end
+redis.call('SET', KEYS[1], string.format('%.0f', newtat), 'PX', math.ceil(diff / 1000))
+return {1, math.floor((capacity - diff) / interval), diff, 0}
+`
+
+// slidingWindowScript applies the sliding-window counter to the KEYS[1] hash,
+// holding the current fixed-window index (w) and the current (cur) and
+// previous (prev) window counts. ARGV: period (µs), requests.
+const slidingWindowScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local period = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local window = math.floor(now / period)
+local remain = period - (now - window * period)
+local st = redis.call('HMGET', KEYS[1], 'w', 'cur', 'prev')
+local w = tonumber(st[1] or '-1')
+local cur = tonumber(st[2] or '0')
+local prev = tonumber(st[3] or '0')
+if w ~= window then
+  if w == window - 1 then
+    prev = cur
+  else
+    prev = 0
+  end
+  cur = 0
+end
+local count = prev * remain / period + cur
+if count + 1 > limit then
+  local wait
+  if cur + 1 <= limit then
+    wait = remain - (limit - cur - 1) * period / prev
+  else
+    wait = remain + period - (limit - 1) * period / cur
+  end
+  return {0, 0, remain, math.max(1, math.ceil(wait))}
+end
+redis.call('HSET', KEYS[1], 'w', string.format('%.0f', window), 'cur', cur + 1, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], math.ceil((remain + period) / 1000))
+return {1, math.floor(limit - count - 1), remain, 0}
+`
+
+var (
+	// ErrNilEval is returned by [NewScriptStore] when the script runner is nil.
+	ErrNilEval = errors.New("ratelimit: nil eval function")
+
+	// ErrScriptReply is returned when a rate-limit script returns an unexpected reply.
+	ErrScriptReply = errors.New("ratelimit: unexpected script reply")
+)
+
+// EvalFunc runs a Lua script on a Redis-compatible server and returns its
+// reply; [github.com/tecnickcom/nurago/pkg/luascript] builds one from a Redis
+// or Valkey client.
+type EvalFunc = luascript.EvalFunc
+
+// ScriptStore is a distributed [Store] running the rate-limit algorithms as
+// atomic Lua scripts on a Redis-compatible server (Redis 5+ or Valkey).
+// Every key expires as soon as it no longer affects the limit.
+type ScriptStore struct {
+	eval   EvalFunc
+	prefix string
+}
+
+// NewScriptStore returns a [ScriptStore] running the scripts with eval and
+// prefixing every key with keyPrefix (e.g. [DefaultKeyPrefix]).
+func NewScriptStore(eval EvalFunc, keyPrefix string) (*ScriptStore, error) {
+	if eval == nil {
+		return nil, ErrNilEval
+	}
+
+	return &ScriptStore{
+		eval:   eval,
+		prefix: keyPrefix,
+	}, nil
+}
+
+// Allow implements [Store].
+func (s *ScriptStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	script, kind, window := tokenBucketScript, "tb:", limit.interval()
+
+	if limit.Algorithm == SlidingWindow {
+		script, kind, window = slidingWindowScript, "sw:", limit.Period
+	}
+
+	args := []string{
+		strconv.FormatInt(window.Microseconds(), 10),
+		strconv.Itoa(limit.quota()),
+	}
+
+	reply, err := s.eval(ctx, script, []string{s.prefix + kind + key}, args...)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: unable to run the script: %w", err)
+	}
+
+	return parseScriptReply(reply, limit.quota())
+}
+
+// parseScriptReply decodes the {allowed, remaining, reset, retry_after} reply
+// of the scripts, with times in microseconds.
+func parseScriptReply(reply any, quota int) (Result, error) {
+	values, ok := reply.([]any)
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+	}
+
+	nums := make([]int64, len(values))
+
+	for i, v := range values {
+		n, ok := v.(int64)
+		if !ok {
+			return Result{}, fmt.Errorf("%w: %v", ErrScriptReply, reply)
+		}
+
+		nums[i] = n
+	}
+
+	return Result{
+		Allowed:    nums[0] == 1,
+		Limit:      quota,
+		Remaining:  int(nums[1]),
+		Reset:      time.Duration(nums[2]) * time.Microsecond,
+		RetryAfter: time.Duration(nums[3]) * time.Microsecond,
+	}, nil
+}
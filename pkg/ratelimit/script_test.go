@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScriptStore(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewScriptStore(nil, DefaultKeyPrefix)
+	require.ErrorIs(t, err, ErrNilEval)
+	require.Nil(t, s)
+}
+
+func TestScriptStore_Allow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		limit      Limit
+		reply      any
+		evalErr    error
+		wantScript string
+		wantKey    string
+		wantArgs   []string
+		want       Result
+		wantErr    error
+	}{
+		{
+			name:       "token bucket",
+			limit:      Limit{Requests: 10, Period: time.Second, Burst: 4},
+			reply:      []any{int64(1), int64(3), int64(100000), int64(0)},
+			wantScript: tokenBucketScript,
+			wantKey:    "rl:tb:k",
+			wantArgs:   []string{"100000", "4"},
+			want:       Result{Allowed: true, Limit: 4, Remaining: 3, Reset: 100 * time.Millisecond},
+		},
+		{
+			name:       "sliding window",
+			limit:      Limit{Algorithm: SlidingWindow, Requests: 10, Period: time.Minute},
+			reply:      []any{int64(0), int64(0), int64(2000000), int64(1500)},
+			wantScript: slidingWindowScript,
+			wantKey:    "rl:sw:k",
+			wantArgs:   []string{"60000000", "10"},
+			want:       Result{Limit: 10, Reset: 2 * time.Second, RetryAfter: 1500 * time.Microsecond},
+		},
+		{
+			name:    "invalid limit",
+			limit:   Limit{},
+			wantErr: ErrInvalidLimit,
+		},
+		{
+			name:    "eval error",
+			limit:   Limit{Requests: 1, Period: time.Second},
+			evalErr: errors.New("down"),
+			wantErr: errors.New("ratelimit: unable to run the script: down"),
+		},
+		{
+			name:    "not a list",
+			limit:   Limit{Requests: 1, Period: time.Second},
+			reply:   "OK",
+			wantErr: ErrScriptReply,
+		},
+		{
+			name:    "short list",
+			limit:   Limit{Requests: 1, Period: time.Second},
+			reply:   []any{int64(1)},
+			wantErr: ErrScriptReply,
+		},
+		{
+			name:    "not a number",
+			limit:   Limit{Requests: 1, Period: time.Second},
+			reply:   []any{int64(1), "x", int64(0), int64(0)},
+			wantErr: ErrScriptReply,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			eval := func(_ context.Context, script string, keys []string, args ...string) (any, error) {
+				if tt.wantScript != "" {
+					require.Equal(t, tt.wantScript, script)
+					require.Equal(t, []string{tt.wantKey}, keys)
+					require.Equal(t, tt.wantArgs, args)
+				}
+
+				return tt.reply, tt.evalErr
+			}
+
+			if tt.evalErr != nil {
+				eval = func(_ context.Context, _ string, _ []string, _ ...string) (any, error) {
+					return nil, tt.evalErr
+				}
+			}
+
+			s, err := NewScriptStore(eval, "rl:")
+			require.NoError(t, err)
+
+			got, err := s.Allow(t.Context(), "k", tt.limit)
+
+			switch {
+			case tt.evalErr != nil:
+				require.EqualError(t, err, tt.wantErr.Error())
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
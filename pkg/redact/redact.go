@@ -11,7 +11,9 @@ zero-configuration instance that suits most callers:
 
 The entry points are [Redactor.String], [Redactor.Bytes], [Redactor.AppendTo],
 [Redactor.BytesToString] and [Redactor.Pooled]; they all run the same redaction
-engine and differ only in input and output handling.
+engine and differ only in input and output handling. [Redactor.SensitiveKey]
+exposes the key classification to the producers of structured data, such as the
+logutil redaction handler, whose field names never reach the engine as text.
 
 # Custom Redactors
 
@@ -109,6 +109,20 @@ func (re *Redactor) BytesToString(b []byte) string {
 	return out
 }
 
+// SensitiveKey reports whether the values of a field named key are redacted,
+// by the same classification the engine applies to the header, JSON,
+// URL-encoded and XML keys (honoring [WithExtraTokens] and [WithoutTokens]).
+// It lets a structured-data producer, such as a logger, redact the fields the
+// engine cannot see as text.
+func (re *Redactor) SensitiveKey(key string) bool {
+	return re.usableRedactor().isSensitiveKey([]byte(key))
+}
+
+// Marker returns the redaction marker of this instance (see [WithMarker]).
+func (re *Redactor) Marker() string {
+	return string(re.usableRedactor().marker)
+}
+
 // usableRedactor returns a Redactor safe to run: for a [New]-built instance it
 // is the receiver unchanged, and for the zero value it is a defensive copy with
 // the nil marker and key memo filled in. The copy is not mutated in place, so a
@@ -292,3 +292,27 @@ func TestRedactorPooledNilConsumer(t *testing.T) {
 		Default().Pooled([]byte("password=SECRET"), nil)
 	})
 }
+
+func TestRedactorSensitiveKey(t *testing.T) {
+	t.Parallel()
+
+	re := New(WithExtraTokens("floof"), WithoutTokens("session"))
+
+	for _, k := range []string{"password", "apiKey", "X-Auth-Token", "client_secret", "userFloof"} {
+		require.Truef(t, re.SensitiveKey(k), "expected %q to be sensitive", k)
+	}
+
+	for _, k := range []string{"user", "reference", "session", "content-security-policy", ""} {
+		require.Falsef(t, re.SensitiveKey(k), "expected %q not to be sensitive", k)
+	}
+
+	require.True(t, (&Redactor{}).SensitiveKey("password"), "the zero value must not panic")
+}
+
+func TestRedactorMarker(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, RedactionMarker, Default().Marker())
+	require.Equal(t, "[x]", New(WithMarker("[x]")).Marker())
+	require.Equal(t, RedactionMarker, (&Redactor{}).Marker())
+}
@@ -41,6 +41,10 @@ var (
 	// ErrSubscriptionClosed is returned by Receive and ReceiveData after the
 	// subscription message channel has been closed (e.g. on Close).
 	ErrSubscriptionClosed = errors.New("redis: subscription closed")
+
+	// ErrUnsupported is returned when the injected [RClient] lacks the
+	// go-redis method needed by the call (e.g. Eval).
+	ErrUnsupported = errors.New("redis: operation not supported by the client")
 )
 
 // TEncodeFunc is the type of function used to replace the default message encoding function used by SendData() and SetData().
@@ -72,6 +76,13 @@ type RClient interface {
 	Subscribe(ctx context.Context, channels ...string) *libredis.PubSub
 }
 
+// rEvalClient is the optional go-redis call used by [Client.Eval]. It is not
+// part of [RClient], so the existing implementations of that interface keep
+// satisfying it.
+type rEvalClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) *libredis.Cmd
+}
+
 // RPubSub defines the go-redis Pub/Sub calls used by [Client].
 type RPubSub interface {
 	Channel(opts ...libredis.ChannelOption) <-chan *libredis.Message
@@ -222,6 +233,30 @@ func (c *Client) Get(ctx context.Context, key string, value any) error {
 	return nil
 }
 
+// Eval runs a Lua script on the server with the given keys and arguments and
+// returns its reply: an int64, a string, a []any of replies, or nil for a nil
+// reply. The script runs atomically, so it can implement read-modify-write
+// operations (e.g. rate-limit counters) without races between clients. keys
+// must list every key the script accesses, as required by Redis Cluster. It
+// returns ErrUnsupported when the injected RClient has no Eval method.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	rc, ok := c.rclient.(rEvalClient)
+	if !ok {
+		return nil, fmt.Errorf("cannot evaluate script: %w", ErrUnsupported)
+	}
+
+	res, err := rc.Eval(ctx, script, keys, args...).Result()
+	if err != nil {
+		if errors.Is(err, libredis.Nil) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot evaluate script: %w", err)
+	}
+
+	return res, nil
+}
+
 // Del deletes key from the datastore.
 func (c *Client) Del(ctx context.Context, key string) error {
 	err := c.rclient.Del(ctx, key).Err()
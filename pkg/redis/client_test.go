@@ -162,6 +162,7 @@ func TestNew_withoutSubscription(t *testing.T) {
 type redisClientMock struct {
 	closeFn     func() error
 	delFn       func(ctx context.Context, keys ...string) *libredis.IntCmd
+	evalFn      func(ctx context.Context, script string, keys []string, args ...any) *libredis.Cmd
 	getFn       func(ctx context.Context, key string) *libredis.StringCmd
 	pingFn      func(ctx context.Context) *libredis.StatusCmd
 	publishFn   func(ctx context.Context, channel string, message any) *libredis.IntCmd
@@ -181,6 +182,10 @@ func (m redisClientMock) Del(ctx context.Context, keys ...string) *libredis.IntC
 	return m.delFn(ctx, keys...)
 }
 
+func (m redisClientMock) Eval(ctx context.Context, script string, keys []string, args ...any) *libredis.Cmd {
+	return m.evalFn(ctx, script, keys, args...)
+}
+
 func (m redisClientMock) Get(ctx context.Context, key string) *libredis.StringCmd {
 	return m.getFn(ctx, key)
 }
@@ -449,6 +454,66 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		rClientMock RClient
+		want        any
+		wantErr     bool
+	}{
+		{
+			name: "success",
+			rClientMock: redisClientMock{evalFn: func(ctx context.Context, _ string, keys []string, args ...any) *libredis.Cmd {
+				return libredis.NewCmdResult([]any{int64(len(keys)), int64(len(args))}, nil)
+			}},
+			want: []any{int64(1), int64(2)},
+		},
+		{
+			name: "nil reply",
+			rClientMock: redisClientMock{evalFn: func(_ context.Context, _ string, _ []string, _ ...any) *libredis.Cmd {
+				return libredis.NewCmdResult(nil, libredis.Nil)
+			}},
+			want: nil,
+		},
+		{
+			name: "error",
+			rClientMock: redisClientMock{evalFn: func(_ context.Context, _ string, _ []string, _ ...any) *libredis.Cmd {
+				return libredis.NewCmdResult(nil, errors.New("test error"))
+			}},
+			wantErr: true,
+		},
+		{
+			name:        "unsupported",
+			rClientMock: struct{ RClient }{redisClientMock{}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli := newTestClient(t, tt.rClientMock, nil)
+
+			got, err := cli.Eval(t.Context(), "return 1", []string{"key_1"}, "a", 1)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				if _, ok := tt.rClientMock.(rEvalClient); !ok {
+					require.ErrorIs(t, err, ErrUnsupported)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestGet(t *testing.T) {
 	t.Parallel()
 
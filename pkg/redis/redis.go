@@ -34,7 +34,9 @@ and a variadic list of [Option] values:
     [Client.ReceiveData] apply the same codec and return the channel name with
     the decoded value.
   - [WithMessageEncodeFunc] and [WithMessageDecodeFunc] replace the default
-    codec.
+    codec; the codec.StringEncodeFunc and codec.StringDecodeFunc adapters
+    of github.com/tecnickcom/nurago/pkg/codec plug in its schema-aware JSON,
+    Protobuf and Avro codecs.
   - [Client.HealthCheck] sends a PING and returns a wrapped error on failure.
   - A missing key surfaces as [ErrKeyNotFound]; other configuration and
     subscription states surface as the exported Err values, all matchable with
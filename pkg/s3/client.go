@@ -1,31 +1,52 @@
 package s3
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // S3 is the minimal AWS SDK S3 API surface required by [Client].
 //
 //nolint:dupl // the test mock necessarily mirrors this method set
 type S3 interface {
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
 	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
 }
 
 // Client wraps AWS SDK S3 operations for a single target bucket.
 type Client struct {
 	s3         S3
 	bucketName string
+
+	// presigner signs the presigned URLs, nil when the S3 implementation is not the SDK client.
+	presigner *s3.PresignClient
+
+	// partSize is the size in bytes of the multipart upload parts.
+	partSize int64
+
+	// uploadConcurrency is the number of parts uploaded in parallel.
+	uploadConcurrency int
 }
 
 // New builds a client for bucketName using configured AWS credentials and S3 options.
@@ -33,7 +54,8 @@ type Client struct {
 // bucketName must not be empty; a cheap check runs before any AWS configuration
 // is loaded, so misconfiguration fails fast with [ErrEmptyBucketName]. A custom
 // client can be supplied with [WithS3Client], in which case no AWS configuration
-// is loaded.
+// is loaded. Invalid multipart options surface [ErrInvalidPartSize] or
+// [ErrInvalidUploadConcurrency].
 //
 // The returned Client is safe for concurrent use.
 func New(ctx context.Context, bucketName string, opts ...Option) (*Client, error) {
@@ -51,12 +73,26 @@ func New(ctx context.Context, bucketName string, opts ...Option) (*Client, error
 		client = s3.NewFromConfig(cfg.awsConfig, cfg.srvOptFns...)
 	}
 
+	var presigner *s3.PresignClient
+
+	if sdk, ok := client.(*s3.Client); ok {
+		presigner = s3.NewPresignClient(sdk)
+	}
+
 	return &Client{
-		s3:         client,
-		bucketName: bucketName,
+		s3:                client,
+		bucketName:        bucketName,
+		presigner:         presigner,
+		partSize:          cfg.partSize,
+		uploadConcurrency: cfg.uploadConcurrency,
 	}, nil
 }
 
+// Bucket returns the name of the bucket the client operates on.
+func (c *Client) Bucket() string {
+	return c.bucketName
+}
+
 // Object contains metadata and body stream for a downloaded S3 object.
 //
 // The caller owns the underlying body stream and MUST call [Object.Close] when
@@ -66,8 +102,10 @@ type Object struct {
 	key           string
 	contentType   string
 	contentLength int64
+	contentRange  string
 	etag          string
 	lastModified  time.Time
+	metadata      map[string]string
 	body          io.ReadCloser
 }
 
@@ -93,6 +131,17 @@ func (o *Object) ContentLength() int64 {
 	return o.contentLength
 }
 
+// ContentRange returns the Content-Range of a ranged download (see
+// [WithRange]), e.g. "bytes 0-99/1234", or "" for a full download.
+func (o *Object) ContentRange() string {
+	return o.contentRange
+}
+
+// Metadata returns the user-defined metadata of the object, if any.
+func (o *Object) Metadata() map[string]string {
+	return o.metadata
+}
+
 // ETag returns the object's entity tag, or "" when the response did not carry one.
 func (o *Object) ETag() string {
 	return o.etag
@@ -142,20 +191,23 @@ func (c *Client) Delete(ctx context.Context, key string) error {
 }
 
 // Get fetches an object by key and returns an [Object] with its streaming body.
+// The options download a byte range ([WithRange]) or make the request
+// conditional ([WithIfMatch], [WithIfNoneMatch], [WithIfModifiedSince],
+// [WithIfUnmodifiedSince]).
 //
-// It returns [ErrEmptyKey] when key is empty, and [ErrEmptyObjectBody] when the
-// response carries no body stream.
-func (c *Client) Get(ctx context.Context, key string) (*Object, error) {
-	if key == "" {
-		return nil, ErrEmptyKey
+// It returns [ErrEmptyKey] when key is empty, [ErrInvalidRange] for an invalid
+// range, and [ErrEmptyObjectBody] when the response carries no body stream.
+// A missing object matches [ErrObjectNotFound], and a failed condition
+// [ErrNotModified] or [ErrPreconditionFailed].
+func (c *Client) Get(ctx context.Context, key string, opts ...GetOption) (*Object, error) {
+	input, err := c.getInput(key, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := c.s3.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(c.bucketName),
-		Key:    aws.String(key),
-	})
+	resp, err := c.s3.GetObject(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get s3 object: %w", err)
+		return nil, fmt.Errorf("cannot get s3 object: %w", statusError(err))
 	}
 
 	// The AWS SDK never returns a nil output (or nil Body) on success, but an
@@ -169,13 +221,91 @@ func (c *Client) Get(ctx context.Context, key string) (*Object, error) {
 		key:           key,
 		contentType:   aws.ToString(resp.ContentType),
 		contentLength: aws.ToInt64(resp.ContentLength),
+		contentRange:  aws.ToString(resp.ContentRange),
 		etag:          aws.ToString(resp.ETag),
 		lastModified:  aws.ToTime(resp.LastModified),
+		metadata:      resp.Metadata,
 		body:          resp.Body,
 	}, nil
 }
 
-// ObjectInfo describes a single object returned by [Client.ListObjects].
+// Head returns the metadata of the object identified by key, without
+// downloading it.
+//
+// It returns [ErrEmptyKey] when key is empty, and an error matching
+// [ErrObjectNotFound] when the object does not exist.
+func (c *Client) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+
+	resp, err := c.s3.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(c.bucketName), Key: aws.String(key)})
+	if err != nil {
+		return nil, fmt.Errorf("cannot head s3 object: %w", statusError(err))
+	}
+
+	// The AWS SDK never returns a nil output on success, but an injected S3
+	// client can: guard against it.
+	if resp == nil {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(resp.ContentLength),
+		LastModified: aws.ToTime(resp.LastModified),
+		ETag:         aws.ToString(resp.ETag),
+		StorageClass: string(resp.StorageClass),
+		ContentType:  aws.ToString(resp.ContentType),
+		Metadata:     resp.Metadata,
+	}, nil
+}
+
+// Copy copies the object srcKey to dstKey within the bucket, server-side.
+// Without options the metadata and tags of the source are kept; a content
+// type or metadata option ([WithContentType], [WithMetadata]) replaces the
+// metadata, and [WithTags] replaces the tags. A single copy is limited to
+// objects up to 5 GiB by S3.
+//
+// It returns [ErrEmptyKey] when a key is empty, and an error matching
+// [ErrObjectNotFound] when the source does not exist.
+func (c *Client) Copy(ctx context.Context, srcKey, dstKey string, opts ...PutOption) error {
+	if srcKey == "" || dstKey == "" {
+		return ErrEmptyKey
+	}
+
+	pc := newPutConfig(opts)
+
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(c.bucketName),
+		Key:                  aws.String(dstKey),
+		CopySource:           aws.String(url.PathEscape(c.bucketName) + "/" + escapeKey(srcKey)),
+		ContentType:          pc.contentTypePtr(),
+		Metadata:             pc.metadata,
+		ServerSideEncryption: pc.sse,
+		SSEKMSKeyId:          pc.kmsKeyIDPtr(),
+		StorageClass:         pc.storageClass,
+		Tagging:              pc.tagging(),
+	}
+
+	if pc.contentType != "" || pc.metadata != nil {
+		input.MetadataDirective = types.MetadataDirectiveReplace
+	}
+
+	if pc.tags != nil {
+		input.TaggingDirective = types.TaggingDirectiveReplace
+	}
+
+	_, err := c.s3.CopyObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("cannot copy s3 object: %w", statusError(err))
+	}
+
+	return nil
+}
+
+// ObjectInfo describes a single object returned by [Client.Objects],
+// [Client.ListObjects] or [Client.Head].
 type ObjectInfo struct {
 	// Key is the object key.
 	Key string
@@ -188,16 +318,31 @@ type ObjectInfo struct {
 
 	// ETag is the object's entity tag, or "" when unset.
 	ETag string
+
+	// StorageClass is the object's storage class, or "" when unset.
+	StorageClass string
+
+	// ContentType is the object's Content-Type; it is only set by [Client.Head].
+	ContentType string
+
+	// Metadata is the object's user-defined metadata; it is only set by [Client.Head].
+	Metadata map[string]string
+
+	// IsPrefix reports whether the entry is a common prefix grouping the keys
+	// that share it up to the [WithDelimiter] delimiter; only Key is set.
+	IsPrefix bool
 }
 
 // ListKeys returns object keys matching prefix; an empty prefix lists all keys in the bucket.
 // It transparently paginates over ListObjectsV2 results, so all matching keys are
-// returned even when they exceed the per-request AWS limit (1000 keys).
+// returned even when they exceed the per-request AWS limit (1000 keys). With
+// [WithDelimiter], the common prefixes are returned as keys too.
 //
 // It is a thin projection over [Client.ListObjects]; use ListObjects when the
-// per-object size, last-modified time, or ETag is also needed.
-func (c *Client) ListKeys(ctx context.Context, prefix string) ([]string, error) {
-	objects, err := c.ListObjects(ctx, prefix)
+// per-object size, last-modified time, or ETag is also needed. All the keys are
+// held in memory: use [Client.Keys] to iterate over large listings.
+func (c *Client) ListKeys(ctx context.Context, prefix string, opts ...ListOption) ([]string, error) {
+	objects, err := c.ListObjects(ctx, prefix, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -214,69 +359,35 @@ func (c *Client) ListKeys(ctx context.Context, prefix string) ([]string, error)
 // all objects in the bucket. Like [Client.ListKeys], it transparently paginates
 // over ListObjectsV2 results, so all matching objects are returned even when they
 // exceed the per-request AWS limit (1000 objects).
-func (c *Client) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
-	input := &s3.ListObjectsV2Input{Bucket: aws.String(c.bucketName), Prefix: aws.String(prefix)}
+//
+// It collects [Client.Objects] into a slice, so all the objects are held in
+// memory: use Objects to iterate over large listings.
+func (c *Client) ListObjects(ctx context.Context, prefix string, opts ...ListOption) ([]ObjectInfo, error) {
 	list := make([]ObjectInfo, 0)
 
-	for {
-		l, err := c.s3.ListObjectsV2(ctx, input)
+	for obj, err := range c.Objects(ctx, prefix, opts...) {
 		if err != nil {
-			return nil, fmt.Errorf("cannot list s3 objects: %w", err)
+			return nil, err
 		}
 
-		list = appendObjects(list, l)
-
-		next := nextContinuationToken(l, input.ContinuationToken)
-		if next == nil {
-			break
-		}
-
-		input.ContinuationToken = next
+		list = append(list, obj)
 	}
 
 	return list, nil
 }
 
-// appendObjects appends the objects contained in a ListObjectsV2 page to dst.
-// A nil page (which an injected client can return on success) contributes nothing.
-func appendObjects(dst []ObjectInfo, page *s3.ListObjectsV2Output) []ObjectInfo {
-	if page == nil {
-		return dst
-	}
-
-	for _, obj := range page.Contents {
-		dst = append(dst, ObjectInfo{
-			Key:          aws.ToString(obj.Key),
-			Size:         aws.ToInt64(obj.Size),
-			LastModified: aws.ToTime(obj.LastModified),
-			ETag:         aws.ToString(obj.ETag),
-		})
-	}
-
-	return dst
-}
-
-// nextContinuationToken returns the token to fetch the next page, or nil when
-// pagination should stop: on a nil or non-truncated page, a missing token, or a
-// token that did not advance (which guards against a non-conformant endpoint
-// that keeps reporting IsTruncated with an unchanging token and would loop forever).
-func nextContinuationToken(page *s3.ListObjectsV2Output, current *string) *string {
-	if page == nil || !aws.ToBool(page.IsTruncated) || page.NextContinuationToken == nil {
-		return nil
-	}
-
-	if aws.ToString(page.NextContinuationToken) == aws.ToString(current) {
-		return nil
-	}
-
-	return page.NextContinuationToken
-}
-
-// Put uploads reader content to key in the configured bucket.
+// Put uploads reader content to key in the configured bucket. The options set
+// the content type, metadata, server-side encryption, storage class and tags.
+//
+// Content shorter than the part size ([WithPartSize]) is uploaded with a
+// single PutObject request. Longer content, or a stream of unknown length, is
+// uploaded with a multipart upload, sending up to [WithUploadConcurrency]
+// parts in parallel; on failure the multipart upload is aborted, so no partial
+// object or orphaned parts are left.
 //
 // It returns [ErrEmptyKey] when key is empty, before any upstream call is made.
 // A nil reader uploads an empty (zero-byte) object.
-func (c *Client) Put(ctx context.Context, key string, reader io.Reader) error {
+func (c *Client) Put(ctx context.Context, key string, reader io.Reader, opts ...PutOption) error {
 	if key == "" {
 		return ErrEmptyKey
 	}
@@ -285,7 +396,29 @@ func (c *Client) Put(ctx context.Context, key string, reader io.Reader) error {
 		reader = http.NoBody
 	}
 
-	_, err := c.s3.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(c.bucketName), Key: aws.String(key), Body: reader})
+	pc := newPutConfig(opts)
+
+	first, err := io.ReadAll(io.LimitReader(reader, c.partSize))
+	if err != nil {
+		return fmt.Errorf("cannot read s3 object data: %w", err)
+	}
+
+	if int64(len(first)) == c.partSize {
+		return c.putMultipart(ctx, key, reader, first, pc)
+	}
+
+	_, err = c.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(c.bucketName),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(first),
+		ContentLength:        aws.Int64(int64(len(first))),
+		ContentType:          pc.contentTypePtr(),
+		Metadata:             pc.metadata,
+		ServerSideEncryption: pc.sse,
+		SSEKMSKeyId:          pc.kmsKeyIDPtr(),
+		StorageClass:         pc.storageClass,
+		Tagging:              pc.tagging(),
+	})
 	if err != nil {
 		return fmt.Errorf("cannot put s3 object: %w", err)
 	}
@@ -315,3 +448,63 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 
 	return nil
 }
+
+// getInput builds the GetObject input of key with the download options.
+func (c *Client) getInput(key string, opts []GetOption) (*s3.GetObjectInput, error) {
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+
+	gc := &getConfig{}
+
+	for _, apply := range opts {
+		apply(gc)
+	}
+
+	rng, err := gc.rangeHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3.GetObjectInput{
+		Bucket:            aws.String(c.bucketName),
+		Key:               aws.String(key),
+		Range:             rng,
+		IfMatch:           optString(gc.ifMatch),
+		IfNoneMatch:       optString(gc.ifNoneMatch),
+		IfModifiedSince:   optTime(gc.ifModifiedSince),
+		IfUnmodifiedSince: optTime(gc.ifUnmodifiedSince),
+	}, nil
+}
+
+// statusError wraps err with the sentinel error matching its HTTP status code:
+// [ErrObjectNotFound], [ErrNotModified] or [ErrPreconditionFailed].
+func statusError(err error) error {
+	var re interface{ HTTPStatusCode() int }
+
+	if !errors.As(err, &re) {
+		return err
+	}
+
+	switch re.HTTPStatusCode() {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrObjectNotFound, err)
+	case http.StatusNotModified:
+		return fmt.Errorf("%w: %w", ErrNotModified, err)
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	default:
+		return err
+	}
+}
+
+// escapeKey URL-encodes each segment of an object key, keeping the slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -3,9 +3,13 @@ package s3
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -29,6 +33,7 @@ func TestNew(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, got)
 	require.Equal(t, "name", got.bucketName)
+	require.Equal(t, "name", got.Bucket())
 
 	got, err = New(
 		t.Context(),
@@ -55,17 +60,44 @@ func TestNew(t *testing.T) {
 }
 
 type s3mock struct {
-	delFn  func(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
-	getFn  func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
-	headFn func(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
-	listFn func(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
-	putFn  func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	abortFn      func(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	completeFn   func(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	copyFn       func(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	createFn     func(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	delFn        func(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	delManyFn    func(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	getFn        func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	headFn       func(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	headObjectFn func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	listFn       func(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	putFn        func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	uploadPartFn func(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+}
+
+func (s s3mock) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return s.abortFn(ctx, params, optFns...)
+}
+
+func (s s3mock) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return s.completeFn(ctx, params, optFns...)
+}
+
+func (s s3mock) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return s.copyFn(ctx, params, optFns...)
+}
+
+func (s s3mock) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return s.createFn(ctx, params, optFns...)
 }
 
 func (s s3mock) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
 	return s.delFn(ctx, params, optFns...)
 }
 
+func (s s3mock) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	return s.delManyFn(ctx, params, optFns...)
+}
+
 func (s s3mock) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	return s.getFn(ctx, params, optFns...)
 }
@@ -74,6 +106,10 @@ func (s s3mock) HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optF
 	return s.headFn(ctx, params, optFns...)
 }
 
+func (s s3mock) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return s.headObjectFn(ctx, params, optFns...)
+}
+
 func (s s3mock) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
 	return s.listFn(ctx, params, optFns...)
 }
@@ -82,6 +118,10 @@ func (s s3mock) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns
 	return s.putFn(ctx, params, optFns...)
 }
 
+func (s s3mock) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return s.uploadPartFn(ctx, params, optFns...)
+}
+
 func TestS3Client_DeleteObject(t *testing.T) {
 	t.Parallel()
 
@@ -546,6 +586,13 @@ func TestS3Client_EmptyKey(t *testing.T) {
 	require.Nil(t, obj)
 
 	require.ErrorIs(t, cli.Put(ctx, "", nil), ErrEmptyKey)
+
+	info, err := cli.Head(ctx, "")
+	require.ErrorIs(t, err, ErrEmptyKey)
+	require.Nil(t, info)
+
+	require.ErrorIs(t, cli.Copy(ctx, "", "k1"), ErrEmptyKey)
+	require.ErrorIs(t, cli.Copy(ctx, "k1", ""), ErrEmptyKey)
 }
 
 func TestS3Client_HealthCheck(t *testing.T) {
@@ -610,3 +657,277 @@ func TestObject_BucketKey(t *testing.T) {
 	require.Equal(t, "bucket", obj.Bucket())
 	require.Equal(t, "k1", obj.Key())
 }
+
+func TestObject_ContentRangeMetadata(t *testing.T) {
+	t.Parallel()
+
+	obj := &Object{contentRange: "bytes 0-3/10", metadata: map[string]string{"a": "b"}}
+
+	require.Equal(t, "bytes 0-3/10", obj.ContentRange())
+	require.Equal(t, map[string]string{"a": "b"}, obj.Metadata())
+}
+
+func TestS3Client_Get_options(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var input *s3.GetObjectInput
+
+	mock := s3mock{getFn: func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		input = params
+
+		return &s3.GetObjectOutput{
+			Body:         io.NopCloser(strings.NewReader("test")),
+			ContentRange: aws.String("bytes 2-5/10"),
+			Metadata:     map[string]string{"owner": "test"},
+		}, nil
+	}}
+
+	cli, err := New(t.Context(), "bucket", WithS3Client(mock))
+	require.NoError(t, err)
+
+	got, err := cli.Get(t.Context(), "k1",
+		WithRange(2, 5),
+		WithIfMatch(`"abc"`),
+		WithIfNoneMatch(`"def"`),
+		WithIfModifiedSince(since),
+		WithIfUnmodifiedSince(since.Add(time.Hour)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "bytes 2-5/10", got.ContentRange())
+	require.Equal(t, map[string]string{"owner": "test"}, got.Metadata())
+	require.Equal(t, &s3.GetObjectInput{
+		Bucket:            aws.String("bucket"),
+		Key:               aws.String("k1"),
+		Range:             aws.String("bytes=2-5"),
+		IfMatch:           aws.String(`"abc"`),
+		IfNoneMatch:       aws.String(`"def"`),
+		IfModifiedSince:   aws.Time(since),
+		IfUnmodifiedSince: aws.Time(since.Add(time.Hour)),
+	}, input)
+
+	_, err = cli.Get(t.Context(), "k1", WithRange(5, -1))
+	require.NoError(t, err)
+	require.Equal(t, "bytes=5-", aws.ToString(input.Range))
+
+	_, err = cli.Get(t.Context(), "k1", WithRange(5, 2))
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = cli.Get(t.Context(), "k1", WithRange(-1, 2))
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
+
+// statusErr is an error carrying an HTTP status code, like the AWS SDK
+// response errors.
+type statusErr int
+
+func (e statusErr) Error() string       { return "status " + strconv.Itoa(int(e)) }
+func (e statusErr) HTTPStatusCode() int { return int(e) }
+
+func TestS3Client_Head(t *testing.T) {
+	t.Parallel()
+
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    S3
+		want    *ObjectInfo
+		wantErr error
+	}{
+		{
+			name: "success",
+			mock: s3mock{headObjectFn: func(_ context.Context, params *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+				if aws.ToString(params.Key) != "k1" {
+					return nil, errors.New("unexpected key")
+				}
+
+				return &s3.HeadObjectOutput{
+					ContentLength: aws.Int64(8),
+					ContentType:   aws.String("text/plain"),
+					ETag:          aws.String(`"abc123"`),
+					LastModified:  aws.Time(testTime),
+					Metadata:      map[string]string{"owner": "test"},
+					StorageClass:  types.StorageClassStandardIa,
+				}, nil
+			}},
+			want: &ObjectInfo{
+				Key:          "k1",
+				Size:         8,
+				LastModified: testTime,
+				ETag:         `"abc123"`,
+				StorageClass: "STANDARD_IA",
+				ContentType:  "text/plain",
+				Metadata:     map[string]string{"owner": "test"},
+			},
+		},
+		{
+			name: "not found",
+			mock: s3mock{headObjectFn: func(_ context.Context, _ *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+				return nil, statusErr(http.StatusNotFound)
+			}},
+			wantErr: ErrObjectNotFound,
+		},
+		{
+			name: "nil response",
+			mock: s3mock{headObjectFn: func(_ context.Context, _ *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+				return nil, nil //nolint:nilnil
+			}},
+			wantErr: ErrObjectNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli, err := New(t.Context(), "bucket", WithS3Client(tt.mock))
+			require.NoError(t, err)
+
+			got, err := cli.Head(t.Context(), "k1")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestS3Client_Copy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		srcKey  string
+		opts    []PutOption
+		mockErr error
+		want    *s3.CopyObjectInput
+		wantErr error
+	}{
+		{
+			name:   "keep metadata",
+			srcKey: "dir/a b+c.txt",
+			want: &s3.CopyObjectInput{
+				Bucket:     aws.String("bucket"),
+				Key:        aws.String("dst"),
+				CopySource: aws.String("bucket/dir/a%20b+c.txt"),
+			},
+		},
+		{
+			name:   "replace metadata and tags",
+			srcKey: "src",
+			opts: []PutOption{
+				WithContentType("text/plain"),
+				WithMetadata(map[string]string{"owner": "test"}),
+				WithServerSideEncryption(types.ServerSideEncryptionAwsKms, "key-id"),
+				WithStorageClass(types.StorageClassGlacier),
+				WithTags(map[string]string{"env": "dev", "team": "a&b"}),
+			},
+			want: &s3.CopyObjectInput{
+				Bucket:               aws.String("bucket"),
+				Key:                  aws.String("dst"),
+				CopySource:           aws.String("bucket/src"),
+				ContentType:          aws.String("text/plain"),
+				Metadata:             map[string]string{"owner": "test"},
+				MetadataDirective:    types.MetadataDirectiveReplace,
+				ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+				SSEKMSKeyId:          aws.String("key-id"),
+				StorageClass:         types.StorageClassGlacier,
+				Tagging:              aws.String("env=dev&team=a%26b"),
+				TaggingDirective:     types.TaggingDirectiveReplace,
+			},
+		},
+		{
+			name:    "source not found",
+			srcKey:  "src",
+			mockErr: statusErr(http.StatusNotFound),
+			wantErr: ErrObjectNotFound,
+		},
+		{
+			name:    "empty key",
+			wantErr: ErrEmptyKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var input *s3.CopyObjectInput
+
+			mock := s3mock{copyFn: func(_ context.Context, params *s3.CopyObjectInput, _ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+				input = params
+				return &s3.CopyObjectOutput{}, tt.mockErr
+			}}
+
+			cli, err := New(t.Context(), "bucket", WithS3Client(mock))
+			require.NoError(t, err)
+
+			err = cli.Copy(t.Context(), tt.srcKey, "dst", tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, input)
+		})
+	}
+}
+
+func TestS3Client_PutObject_options(t *testing.T) {
+	t.Parallel()
+
+	var input *s3.PutObjectInput
+
+	mock := s3mock{putFn: func(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+		input = params
+		return &s3.PutObjectOutput{}, nil
+	}}
+
+	cli, err := New(t.Context(), "bucket", WithS3Client(mock))
+	require.NoError(t, err)
+
+	err = cli.Put(t.Context(), "k1", strings.NewReader("data"),
+		WithContentType("text/plain"),
+		WithMetadata(map[string]string{"owner": "test"}),
+		WithServerSideEncryption(types.ServerSideEncryptionAes256, ""),
+		WithStorageClass(types.StorageClassStandardIa),
+		WithTags(map[string]string{"env": "dev"}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), aws.ToInt64(input.ContentLength))
+	require.Equal(t, "text/plain", aws.ToString(input.ContentType))
+	require.Equal(t, map[string]string{"owner": "test"}, input.Metadata)
+	require.Equal(t, types.ServerSideEncryptionAes256, input.ServerSideEncryption)
+	require.Nil(t, input.SSEKMSKeyId)
+	require.Equal(t, types.StorageClassStandardIa, input.StorageClass)
+	require.Equal(t, "env=dev", aws.ToString(input.Tagging))
+
+	data, err := io.ReadAll(input.Body)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+
+	err = cli.Put(t.Context(), "k1", iotest.ErrReader(errors.New("read error")))
+	require.ErrorContains(t, err, "read error")
+}
+
+func Test_statusError(t *testing.T) {
+	t.Parallel()
+
+	plain := errors.New("plain")
+
+	require.ErrorIs(t, statusError(statusErr(http.StatusNotFound)), ErrObjectNotFound)
+	require.ErrorIs(t, statusError(statusErr(http.StatusNotModified)), ErrNotModified)
+	require.ErrorIs(t, statusError(statusErr(http.StatusPreconditionFailed)), ErrPreconditionFailed)
+	require.Equal(t, statusErr(http.StatusForbidden), statusError(statusErr(http.StatusForbidden)))
+	require.Equal(t, plain, statusError(plain))
+
+	err := statusError(fmt.Errorf("wrapped: %w", statusErr(http.StatusNotFound)))
+	require.ErrorIs(t, err, ErrObjectNotFound)
+	require.ErrorAs(t, err, new(statusErr))
+}
@@ -3,17 +3,44 @@ package s3
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
 )
 
+const (
+	// MinPartSize is the minimum size in bytes of a multipart upload part
+	// (5 MiB), as required by S3 for all the parts but the last.
+	MinPartSize = 5 << 20
+
+	// MaxPartSize is the maximum size in bytes of a multipart upload part (5 GiB).
+	MaxPartSize = 5 << 30
+
+	// DefaultPartSize is the default size in bytes of a multipart upload part (8 MiB).
+	DefaultPartSize = 8 << 20
+
+	// DefaultUploadConcurrency is the default number of parts of a multipart
+	// upload sent in parallel.
+	DefaultUploadConcurrency = 4
+
+	// MaxListPageSize is the maximum number of entries of a listing request.
+	MaxListPageSize = 1000
+
+	// MaxDeleteBatchSize is the maximum number of keys of a batch delete request.
+	MaxDeleteBatchSize = 1000
+)
+
 // cfg stores AWS and service-specific settings used to construct an S3 client.
 type cfg struct {
-	awsConfig aws.Config
-	awsOpts   awsopt.Options
-	srvOptFns []SrvOptionFunc
-	s3Client  S3
+	awsConfig         aws.Config
+	awsOpts           awsopt.Options
+	srvOptFns         []SrvOptionFunc
+	s3Client          S3
+	partSize          int64
+	uploadConcurrency int
 }
 
 // loadConfig applies options and resolves aws.Config for S3 client construction.
@@ -23,12 +50,23 @@ type cfg struct {
 // it would only add latency and a spurious failure mode (e.g. EC2 IMDS probing
 // in an isolated/unit-test environment).
 func loadConfig(ctx context.Context, opts ...Option) (*cfg, error) {
-	c := &cfg{}
+	c := &cfg{
+		partSize:          DefaultPartSize,
+		uploadConcurrency: DefaultUploadConcurrency,
+	}
 
 	for _, apply := range opts {
 		apply(c)
 	}
 
+	if c.partSize < MinPartSize || c.partSize > MaxPartSize {
+		return nil, ErrInvalidPartSize
+	}
+
+	if c.uploadConcurrency < 1 {
+		return nil, ErrInvalidUploadConcurrency
+	}
+
 	if c.s3Client != nil {
 		// The injected client replaces the SDK client, so awsConfig and
 		// srvOptFns are never consumed: skip the (potentially slow or failing)
@@ -45,3 +83,102 @@ func loadConfig(ctx context.Context, opts ...Option) (*cfg, error) {
 
 	return c, nil
 }
+
+// putConfig stores the object settings of an upload or copy.
+type putConfig struct {
+	contentType  string
+	metadata     map[string]string
+	sse          types.ServerSideEncryption
+	kmsKeyID     string
+	storageClass types.StorageClass
+	tags         map[string]string
+}
+
+// newPutConfig applies the upload options.
+func newPutConfig(opts []PutOption) *putConfig {
+	pc := &putConfig{}
+
+	for _, apply := range opts {
+		apply(pc)
+	}
+
+	return pc
+}
+
+// contentTypePtr returns the content type, or nil when unset.
+func (pc *putConfig) contentTypePtr() *string {
+	return optString(pc.contentType)
+}
+
+// kmsKeyIDPtr returns the KMS key ID, or nil when unset.
+func (pc *putConfig) kmsKeyIDPtr() *string {
+	return optString(pc.kmsKeyID)
+}
+
+// tagging returns the tags encoded as URL query parameters, or nil when unset.
+func (pc *putConfig) tagging() *string {
+	if len(pc.tags) == 0 {
+		return nil
+	}
+
+	v := make(url.Values, len(pc.tags))
+
+	for key, value := range pc.tags {
+		v.Set(key, value)
+	}
+
+	return aws.String(v.Encode())
+}
+
+// getConfig stores the byte range and conditions of a download.
+type getConfig struct {
+	rangeSet          bool
+	rangeStart        int64
+	rangeEnd          int64
+	ifMatch           string
+	ifNoneMatch       string
+	ifModifiedSince   time.Time
+	ifUnmodifiedSince time.Time
+}
+
+// rangeHeader returns the HTTP Range header value, or nil when unset.
+func (gc *getConfig) rangeHeader() (*string, error) {
+	if !gc.rangeSet {
+		return nil, nil //nolint:nilnil
+	}
+
+	if gc.rangeStart < 0 || (gc.rangeEnd >= 0 && gc.rangeEnd < gc.rangeStart) {
+		return nil, ErrInvalidRange
+	}
+
+	if gc.rangeEnd < 0 {
+		return aws.String(fmt.Sprintf("bytes=%d-", gc.rangeStart)), nil
+	}
+
+	return aws.String(fmt.Sprintf("bytes=%d-%d", gc.rangeStart, gc.rangeEnd)), nil
+}
+
+// listConfig stores the parameters of a listing.
+type listConfig struct {
+	delimiter  string
+	startAfter string
+	pageSize   int
+}
+
+// optString returns a pointer to s, or nil when s is empty.
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return aws.String(s)
+}
+
+// optTime returns a pointer to t, or nil when t is the zero time.
+func optTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return aws.Time(t)
+}
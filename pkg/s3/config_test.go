@@ -31,3 +31,68 @@ func Test_loadConfig(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, got)
 }
+
+func Test_loadConfig_validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "valid",
+			opts: []Option{WithPartSize(MaxPartSize), WithUploadConcurrency(1)},
+		},
+		{
+			name:    "part size too small",
+			opts:    []Option{WithPartSize(MinPartSize - 1)},
+			wantErr: ErrInvalidPartSize,
+		},
+		{
+			name:    "part size too large",
+			opts:    []Option{WithPartSize(MaxPartSize + 1)},
+			wantErr: ErrInvalidPartSize,
+		},
+		{
+			name:    "invalid upload concurrency",
+			opts:    []Option{WithUploadConcurrency(0)},
+			wantErr: ErrInvalidUploadConcurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append([]Option{WithS3Client(s3mock{})}, tt.opts...)
+
+			got, err := loadConfig(t.Context(), opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, got.partSize, int64(MinPartSize))
+			require.Positive(t, got.uploadConcurrency)
+		})
+	}
+}
+
+func Test_getConfig_rangeHeader(t *testing.T) {
+	t.Parallel()
+
+	rng, err := (&getConfig{}).rangeHeader()
+	require.NoError(t, err)
+	require.Nil(t, rng)
+
+	rng, err = (&getConfig{rangeSet: true, rangeStart: 0, rangeEnd: 0}).rangeHeader()
+	require.NoError(t, err)
+	require.Equal(t, "bytes=0-0", *rng)
+}
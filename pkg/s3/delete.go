@@ -0,0 +1,155 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DeleteKeyError is the failure to delete a key with [Client.DeleteMany].
+type DeleteKeyError struct {
+	// Key is the object key.
+	Key string
+
+	// Code is the S3 error code, empty when the whole request failed.
+	Code string
+
+	// Err is the failure.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DeleteKeyError) Error() string {
+	return fmt.Sprintf("key %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DeleteKeyError) Unwrap() error {
+	return e.Err
+}
+
+// DeleteError reports the keys that [Client.DeleteMany] failed to delete, in
+// input order; the other keys were deleted. Retrieve it with errors.As.
+type DeleteError struct {
+	Entries []*DeleteKeyError
+}
+
+// Error implements the error interface.
+func (e *DeleteError) Error() string {
+	msgs := make([]string, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		msgs[i] = entry.Error()
+	}
+
+	return fmt.Sprintf("s3: %d keys not deleted: %s", len(e.Entries), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the failures of the keys.
+func (e *DeleteError) Unwrap() []error {
+	errs := make([]error, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		errs[i] = entry
+	}
+
+	return errs
+}
+
+// Keys returns the keys that were not deleted, e.g. to retry them.
+func (e *DeleteError) Keys() []string {
+	keys := make([]string, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		keys[i] = entry.Key
+	}
+
+	return keys
+}
+
+// DeleteMany removes the objects by key with DeleteObjects requests of up to
+// [MaxDeleteBatchSize] keys. As with [Client.Delete], a missing key is not an
+// error. All the keys must be non-empty ([ErrEmptyKey]), otherwise nothing is
+// deleted.
+//
+// When some keys fail, the others are still deleted and a [DeleteError]
+// reports the failed ones.
+func (c *Client) DeleteMany(ctx context.Context, keys []string) error {
+	for i, key := range keys {
+		if key == "" {
+			return fmt.Errorf("key %d: %w", i, ErrEmptyKey)
+		}
+	}
+
+	var failed []*DeleteKeyError
+
+	for chunk := range slices.Chunk(keys, MaxDeleteBatchSize) {
+		failed = append(failed, c.deleteChunk(ctx, chunk)...)
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &DeleteError{Entries: failed}
+}
+
+// deleteChunk deletes up to MaxDeleteBatchSize keys with a DeleteObjects
+// request, and returns the failed keys in input order.
+func (c *Client) deleteChunk(ctx context.Context, keys []string) []*DeleteKeyError {
+	objects := make([]types.ObjectIdentifier, len(keys))
+
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+
+	resp, err := c.s3.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(c.bucketName),
+		Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+	})
+	if err != nil {
+		err = fmt.Errorf("cannot delete s3 objects: %w", err)
+		failed := make([]*DeleteKeyError, len(keys))
+
+		for i, key := range keys {
+			failed[i] = &DeleteKeyError{Key: key, Err: err}
+		}
+
+		return failed
+	}
+
+	// The AWS SDK never returns a nil output on success, but an injected S3
+	// client can: treat it as a success without errors.
+	if resp == nil || len(resp.Errors) == 0 {
+		return nil
+	}
+
+	errs := make(map[string]types.Error, len(resp.Errors))
+
+	for _, e := range resp.Errors {
+		errs[aws.ToString(e.Key)] = e
+	}
+
+	var failed []*DeleteKeyError
+
+	for _, key := range keys {
+		e, ok := errs[key]
+		if !ok {
+			continue
+		}
+
+		failed = append(failed, &DeleteKeyError{
+			Key:  key,
+			Code: aws.ToString(e.Code),
+			Err:  errors.New(aws.ToString(e.Message)),
+		})
+	}
+
+	return failed
+}
@@ -0,0 +1,141 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	many := make([]string, MaxDeleteBatchSize+2)
+	for i := range many {
+		many[i] = "key" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name       string
+		keys       []string
+		mockFn     func(params *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error)
+		wantChunks []int
+		wantKeys   []string
+		wantCodes  []string
+		wantErr    error
+	}{
+		{
+			name: "no keys",
+		},
+		{
+			name:       "success",
+			keys:       many,
+			wantChunks: []int{MaxDeleteBatchSize, 2},
+		},
+		{
+			name:       "nil response",
+			keys:       []string{"a"},
+			mockFn:     func(*s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) { return nil, nil }, //nolint:nilnil
+			wantChunks: []int{1},
+		},
+		{
+			name: "key errors",
+			keys: []string{"a", "b", "c", "d"},
+			mockFn: func(*s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				return &s3.DeleteObjectsOutput{Errors: []types.Error{
+					{Key: aws.String("d"), Code: aws.String("InternalError"), Message: aws.String("internal error")},
+					{Key: aws.String("b"), Code: aws.String("AccessDenied"), Message: aws.String("access denied")},
+				}}, nil
+			},
+			wantChunks: []int{4},
+			wantKeys:   []string{"b", "d"},
+			wantCodes:  []string{"AccessDenied", "InternalError"},
+		},
+		{
+			name: "request error",
+			keys: many,
+			mockFn: func(params *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				if len(params.Delete.Objects) == 2 {
+					return nil, errors.New("request error")
+				}
+
+				return &s3.DeleteObjectsOutput{}, nil
+			},
+			wantChunks: []int{MaxDeleteBatchSize, 2},
+			wantKeys:   many[MaxDeleteBatchSize:],
+			wantCodes:  []string{"", ""},
+		},
+		{
+			name:    "empty key",
+			keys:    []string{"a", ""},
+			wantErr: ErrEmptyKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var chunks []int
+
+			mock := s3mock{delManyFn: func(_ context.Context, params *s3.DeleteObjectsInput, _ ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+				require.Equal(t, "bucket", aws.ToString(params.Bucket))
+				require.True(t, aws.ToBool(params.Delete.Quiet))
+
+				chunks = append(chunks, len(params.Delete.Objects))
+
+				if tt.mockFn != nil {
+					return tt.mockFn(params)
+				}
+
+				return &s3.DeleteObjectsOutput{}, nil
+			}}
+
+			cli, err := New(t.Context(), "bucket", WithS3Client(mock))
+			require.NoError(t, err)
+
+			err = cli.DeleteMany(t.Context(), tt.keys)
+			require.Equal(t, tt.wantChunks, chunks)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			if tt.wantKeys == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var derr *DeleteError
+
+			require.ErrorAs(t, err, &derr)
+			require.Equal(t, tt.wantKeys, derr.Keys())
+
+			for i, entry := range derr.Entries {
+				require.Equal(t, tt.wantCodes[i], entry.Code)
+				require.ErrorContains(t, err, entry.Error())
+			}
+		})
+	}
+}
+
+func TestDeleteError(t *testing.T) {
+	t.Parallel()
+
+	errReq := errors.New("request error")
+
+	err := &DeleteError{Entries: []*DeleteKeyError{
+		{Key: "a", Code: "AccessDenied", Err: errors.New("access denied")},
+		{Key: "b", Err: errReq},
+	}}
+
+	require.EqualError(t, err, `s3: 2 keys not deleted: key "a": access denied; key "b": request error`)
+	require.ErrorIs(t, err, errReq)
+	require.Equal(t, []string{"a", "b"}, err.Keys())
+}
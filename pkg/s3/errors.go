@@ -7,14 +7,56 @@ var (
 	// ErrEmptyBucketName is returned by New when bucketName is empty.
 	ErrEmptyBucketName = errors.New("s3: bucket name must not be empty")
 
-	// ErrEmptyKey is returned by Get, Put and Delete when key is empty, before
-	// any upstream call is made.
+	// ErrEmptyKey is returned by Get, Put, Delete, DeleteMany, Head, Copy,
+	// PresignGet and PresignPut when a key is empty, before any upstream call
+	// is made.
 	ErrEmptyKey = errors.New("s3: object key must not be empty")
 
 	// ErrEmptyObjectBody is returned by Get when the response carries no body
 	// stream (a nil response or a nil Body).
 	ErrEmptyObjectBody = errors.New("s3: object response has no body")
 
+	// ErrObjectNotFound is matched by the errors of Get, Head and Copy when
+	// the object does not exist.
+	ErrObjectNotFound = errors.New("s3: object not found")
+
+	// ErrNotModified is matched by the Get error when a WithIfNoneMatch or
+	// WithIfModifiedSince condition is not met.
+	ErrNotModified = errors.New("s3: object not modified")
+
+	// ErrPreconditionFailed is matched by the Get error when a WithIfMatch or
+	// WithIfUnmodifiedSince condition is not met.
+	ErrPreconditionFailed = errors.New("s3: object precondition failed")
+
+	// ErrInvalidRange is returned by Get and PresignGet for a negative range
+	// start or a range end before the start.
+	ErrInvalidRange = errors.New("s3: invalid byte range")
+
+	// ErrInvalidPartSize is returned by New when the WithPartSize part size is
+	// outside the MinPartSize..MaxPartSize range.
+	ErrInvalidPartSize = errors.New("s3: invalid multipart upload part size")
+
+	// ErrInvalidUploadConcurrency is returned by New when the
+	// WithUploadConcurrency value is not positive.
+	ErrInvalidUploadConcurrency = errors.New("s3: the upload concurrency must be positive")
+
+	// ErrTooManyParts is returned by Put when a streamed object needs more
+	// than 10000 parts; increase WithPartSize.
+	ErrTooManyParts = errors.New("s3: the object exceeds the maximum number of multipart upload parts")
+
+	// ErrPresignNotSupported is returned by PresignGet and PresignPut when the
+	// client was created with a WithS3Client implementation that is not the
+	// AWS SDK client.
+	ErrPresignNotSupported = errors.New("s3: presigning requires the AWS SDK S3 client")
+
+	// ErrInvalidExpiration is returned by PresignGet and PresignPut when the
+	// expiration is not between 1 second and 7 days.
+	ErrInvalidExpiration = errors.New("s3: the presigned URL expiration must be between 1 second and 7 days")
+
+	// ErrInvalidPageSize is returned by the listing methods when the
+	// WithPageSize value is negative or above MaxListPageSize.
+	ErrInvalidPageSize = errors.New("s3: invalid listing page size")
+
 	// ErrBucketNotResponding is returned by HealthCheck when the bucket probe
 	// succeeds but returns a nil response.
 	ErrBucketNotResponding = errors.New("s3: the bucket is not responding")
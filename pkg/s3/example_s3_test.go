@@ -71,6 +71,68 @@ func (c *exampleS3Client) DeleteObject(
 	return &awss3.DeleteObjectOutput{}, nil
 }
 
+func (c *exampleS3Client) DeleteObjects(
+	_ context.Context,
+	params *awss3.DeleteObjectsInput,
+	_ ...func(*awss3.Options),
+) (*awss3.DeleteObjectsOutput, error) {
+	for _, obj := range params.Delete.Objects {
+		delete(c.objects, aws.ToString(obj.Key))
+	}
+
+	return &awss3.DeleteObjectsOutput{}, nil
+}
+
+func (c *exampleS3Client) HeadObject(
+	_ context.Context,
+	params *awss3.HeadObjectInput,
+	_ ...func(*awss3.Options),
+) (*awss3.HeadObjectOutput, error) {
+	body := c.objects[aws.ToString(params.Key)]
+
+	return &awss3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(body)))}, nil
+}
+
+func (c *exampleS3Client) CopyObject(
+	_ context.Context,
+	_ *awss3.CopyObjectInput,
+	_ ...func(*awss3.Options),
+) (*awss3.CopyObjectOutput, error) {
+	return &awss3.CopyObjectOutput{}, nil
+}
+
+func (c *exampleS3Client) CreateMultipartUpload(
+	_ context.Context,
+	_ *awss3.CreateMultipartUploadInput,
+	_ ...func(*awss3.Options),
+) (*awss3.CreateMultipartUploadOutput, error) {
+	return &awss3.CreateMultipartUploadOutput{}, nil
+}
+
+func (c *exampleS3Client) UploadPart(
+	_ context.Context,
+	_ *awss3.UploadPartInput,
+	_ ...func(*awss3.Options),
+) (*awss3.UploadPartOutput, error) {
+	return &awss3.UploadPartOutput{}, nil
+}
+
+func (c *exampleS3Client) CompleteMultipartUpload(
+	_ context.Context,
+	_ *awss3.CompleteMultipartUploadInput,
+	_ ...func(*awss3.Options),
+) (*awss3.CompleteMultipartUploadOutput, error) {
+	return &awss3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (c *exampleS3Client) AbortMultipartUpload(
+	_ context.Context,
+	_ *awss3.AbortMultipartUploadInput,
+	_ ...func(*awss3.Options),
+) (*awss3.AbortMultipartUploadOutput, error) {
+	return &awss3.AbortMultipartUploadOutput{}, nil
+}
+
 func (c *exampleS3Client) HeadBucket(
 	_ context.Context,
 	_ *awss3.HeadBucketInput,
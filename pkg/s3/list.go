@@ -0,0 +1,145 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Objects returns an iterator over the objects matching prefix; an empty
+// prefix lists all objects in the bucket. The objects are listed lazily, one
+// ListObjectsV2 page at a time, so only a page is held in memory; stopping the
+// iteration stops the listing. The objects are yielded in lexicographic key
+// order.
+//
+// With [WithDelimiter], the keys sharing a prefix up to the delimiter are
+// grouped in a single common prefix entry ([ObjectInfo.IsPrefix]), in key
+// order among the objects. [WithStartAfter] resumes a listing after a key, and
+// [WithPageSize] sets the number of entries per request.
+//
+// A failure is yielded with a zero ObjectInfo and ends the iteration.
+func (c *Client) Objects(ctx context.Context, prefix string, opts ...ListOption) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		input, err := c.listInput(prefix, opts)
+		if err != nil {
+			yield(ObjectInfo{}, err)
+			return
+		}
+
+		for {
+			page, err := c.s3.ListObjectsV2(ctx, input)
+			if err != nil {
+				yield(ObjectInfo{}, fmt.Errorf("cannot list s3 objects: %w", err))
+				return
+			}
+
+			for _, obj := range pageObjects(page) {
+				if !yield(obj, nil) {
+					return
+				}
+			}
+
+			next := nextContinuationToken(page, input.ContinuationToken)
+			if next == nil {
+				return
+			}
+
+			input.ContinuationToken = next
+		}
+	}
+}
+
+// Keys returns an iterator over the keys matching prefix, including the common
+// prefixes with [WithDelimiter]. It is a thin projection over [Client.Objects].
+func (c *Client) Keys(ctx context.Context, prefix string, opts ...ListOption) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for obj, err := range c.Objects(ctx, prefix, opts...) {
+			if !yield(obj.Key, err) {
+				return
+			}
+		}
+	}
+}
+
+// listInput builds the ListObjectsV2 input of prefix with the listing options.
+func (c *Client) listInput(prefix string, opts []ListOption) (*s3.ListObjectsV2Input, error) {
+	lc := &listConfig{}
+
+	for _, apply := range opts {
+		apply(lc)
+	}
+
+	if lc.pageSize < 0 || lc.pageSize > MaxListPageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:     aws.String(c.bucketName),
+		Prefix:     aws.String(prefix),
+		Delimiter:  optString(lc.delimiter),
+		StartAfter: optString(lc.startAfter),
+	}
+
+	if lc.pageSize > 0 {
+		input.MaxKeys = aws.Int32(int32(lc.pageSize)) //nolint:gosec // bounded by MaxListPageSize
+	}
+
+	return input, nil
+}
+
+// pageObjects returns the objects and common prefixes contained in a
+// ListObjectsV2 page, in key order. A nil page (which an injected client can
+// return on success) contributes nothing.
+func pageObjects(page *s3.ListObjectsV2Output) []ObjectInfo {
+	if page == nil {
+		return nil
+	}
+
+	list := make([]ObjectInfo, 0, len(page.Contents)+len(page.CommonPrefixes))
+
+	for _, obj := range page.Contents {
+		list = append(list, ObjectInfo{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+			ETag:         aws.ToString(obj.ETag),
+			StorageClass: string(obj.StorageClass),
+		})
+	}
+
+	if len(page.CommonPrefixes) == 0 {
+		return list
+	}
+
+	for _, p := range page.CommonPrefixes {
+		list = append(list, ObjectInfo{Key: aws.ToString(p.Prefix), IsPrefix: true})
+	}
+
+	// S3 returns the objects and the common prefixes in two separately sorted lists.
+	slices.SortStableFunc(list, func(a, b ObjectInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return list
+}
+
+// nextContinuationToken returns the token to fetch the next page, or nil when
+// pagination should stop: on a nil or non-truncated page, a missing token, or a
+// token that did not advance (which guards against a non-conformant endpoint
+// that keeps reporting IsTruncated with an unchanging token and would loop forever).
+func nextContinuationToken(page *s3.ListObjectsV2Output, current *string) *string {
+	if page == nil || !aws.ToBool(page.IsTruncated) || page.NextContinuationToken == nil {
+		return nil
+	}
+
+	if aws.ToString(page.NextContinuationToken) == aws.ToString(current) {
+		return nil
+	}
+
+	return page.NextContinuationToken
+}
@@ -0,0 +1,190 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+// listPages returns a s3mock listing the pages in order, recording the inputs.
+func listPages(inputs *[]*s3.ListObjectsV2Input, pages ...*s3.ListObjectsV2Output) s3mock {
+	return s3mock{listFn: func(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+		input := *params
+		*inputs = append(*inputs, &input)
+
+		if len(*inputs) > len(pages) {
+			return nil, errors.New("list error")
+		}
+
+		return pages[len(*inputs)-1], nil
+	}}
+}
+
+func TestClient_Objects(t *testing.T) {
+	t.Parallel()
+
+	pages := []*s3.ListObjectsV2Output{
+		{
+			Contents: []types.Object{
+				{Key: aws.String("dir/a.txt"), Size: aws.Int64(1)},
+				{Key: aws.String("dir/c.txt"), StorageClass: types.ObjectStorageClassGlacier},
+			},
+			CommonPrefixes:        []types.CommonPrefix{{Prefix: aws.String("dir/b/")}},
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("token1"),
+		},
+		{
+			Contents:       []types.Object{{Key: aws.String("dir/d.txt")}},
+			CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("dir/e/")}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		pages      []*s3.ListObjectsV2Output
+		opts       []ListOption
+		limit      int
+		want       []ObjectInfo
+		wantInputs int
+		wantErr    error
+	}{
+		{
+			name:  "all pages",
+			pages: pages,
+			opts:  []ListOption{WithDelimiter("/"), WithStartAfter("dir/0"), WithPageSize(3)},
+			want: []ObjectInfo{
+				{Key: "dir/a.txt", Size: 1},
+				{Key: "dir/b/", IsPrefix: true},
+				{Key: "dir/c.txt", StorageClass: "GLACIER"},
+				{Key: "dir/d.txt"},
+				{Key: "dir/e/", IsPrefix: true},
+			},
+			wantInputs: 2,
+		},
+		{
+			name:       "stop early",
+			pages:      pages,
+			limit:      2,
+			want:       []ObjectInfo{{Key: "dir/a.txt", Size: 1}, {Key: "dir/b/", IsPrefix: true}},
+			wantInputs: 1,
+		},
+		{
+			name:  "page error",
+			pages: pages[:1],
+			want: []ObjectInfo{
+				{Key: "dir/a.txt", Size: 1},
+				{Key: "dir/b/", IsPrefix: true},
+				{Key: "dir/c.txt", StorageClass: "GLACIER"},
+			},
+			wantInputs: 2,
+			wantErr:    errors.New("cannot list s3 objects: list error"),
+		},
+		{
+			name:       "nil page",
+			pages:      []*s3.ListObjectsV2Output{nil},
+			wantInputs: 1,
+		},
+		{
+			name:    "negative page size",
+			opts:    []ListOption{WithPageSize(-1)},
+			wantErr: ErrInvalidPageSize,
+		},
+		{
+			name:    "page size too large",
+			opts:    []ListOption{WithPageSize(MaxListPageSize + 1)},
+			wantErr: ErrInvalidPageSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var inputs []*s3.ListObjectsV2Input
+
+			cli, err := New(t.Context(), "bucket", WithS3Client(listPages(&inputs, tt.pages...)))
+			require.NoError(t, err)
+
+			var (
+				got     []ObjectInfo
+				gotErrs []error
+			)
+
+			for obj, err := range cli.Objects(t.Context(), "dir/", tt.opts...) {
+				if err != nil {
+					require.Zero(t, obj)
+
+					gotErrs = append(gotErrs, err)
+
+					continue
+				}
+
+				got = append(got, obj)
+
+				if len(got) == tt.limit {
+					break
+				}
+			}
+
+			require.Equal(t, tt.want, got)
+			require.Len(t, inputs, tt.wantInputs)
+
+			if tt.wantErr != nil {
+				require.Len(t, gotErrs, 1)
+				require.EqualError(t, gotErrs[0], tt.wantErr.Error())
+			} else {
+				require.Empty(t, gotErrs)
+			}
+
+			if len(tt.opts) == 3 {
+				require.Equal(t, &s3.ListObjectsV2Input{
+					Bucket:     aws.String("bucket"),
+					Prefix:     aws.String("dir/"),
+					Delimiter:  aws.String("/"),
+					StartAfter: aws.String("dir/0"),
+					MaxKeys:    aws.Int32(3),
+				}, inputs[0])
+				require.Equal(t, "token1", aws.ToString(inputs[1].ContinuationToken))
+			}
+		})
+	}
+}
+
+func TestClient_Keys(t *testing.T) {
+	t.Parallel()
+
+	var inputs []*s3.ListObjectsV2Input
+
+	mock := listPages(&inputs, &s3.ListObjectsV2Output{
+		Contents:       []types.Object{{Key: aws.String("a")}, {Key: aws.String("c")}},
+		CommonPrefixes: []types.CommonPrefix{{Prefix: aws.String("b/")}},
+	})
+
+	cli, err := New(t.Context(), "bucket", WithS3Client(mock))
+	require.NoError(t, err)
+
+	var got []string
+
+	for key, err := range cli.Keys(t.Context(), "", WithDelimiter("/")) {
+		require.NoError(t, err)
+
+		got = append(got, key)
+	}
+
+	require.Equal(t, []string{"a", "b/", "c"}, got)
+
+	// the mock lists a single page
+	for key, err := range cli.Keys(t.Context(), "") {
+		require.Empty(t, key)
+		require.ErrorContains(t, err, "list error")
+	}
+
+	keys, err := cli.ListKeys(t.Context(), "", WithPageSize(-1))
+	require.ErrorIs(t, err, ErrInvalidPageSize)
+	require.Nil(t, keys)
+}
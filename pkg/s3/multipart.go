@@ -0,0 +1,181 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// maxParts is the maximum number of parts of a multipart upload.
+const maxParts = 10000
+
+// errNoUploadID is returned when S3 creates a multipart upload without ID.
+var errNoUploadID = errors.New("s3: multipart upload created without ID")
+
+// putMultipart uploads first and the rest of r to key with a multipart
+// upload, aborting it on failure.
+func (c *Client) putMultipart(ctx context.Context, key string, r io.Reader, first []byte, pc *putConfig) error {
+	resp, err := c.s3.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(c.bucketName),
+		Key:                  aws.String(key),
+		ContentType:          pc.contentTypePtr(),
+		Metadata:             pc.metadata,
+		ServerSideEncryption: pc.sse,
+		SSEKMSKeyId:          pc.kmsKeyIDPtr(),
+		StorageClass:         pc.storageClass,
+		Tagging:              pc.tagging(),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create s3 multipart upload: %w", err)
+	}
+
+	if resp == nil || aws.ToString(resp.UploadId) == "" {
+		return errNoUploadID
+	}
+
+	uploadID := resp.UploadId
+
+	parts, err := c.uploadParts(ctx, key, uploadID, r, first)
+	if err == nil {
+		_, err = c.s3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(c.bucketName),
+			Key:             aws.String(key),
+			UploadId:        uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err == nil {
+			return nil
+		}
+
+		err = fmt.Errorf("cannot complete s3 multipart upload: %w", err)
+	}
+
+	// the upload is aborted even when ctx is canceled, to release the parts
+	_, aerr := c.s3.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(c.bucketName),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+	})
+	if aerr != nil {
+		return errors.Join(err, fmt.Errorf("cannot abort s3 multipart upload: %w", aerr))
+	}
+
+	return err
+}
+
+// uploadParts uploads first and the rest of r as parts of the multipart
+// upload, up to uploadConcurrency in parallel, and returns the completed
+// parts ordered by number. The first failure stops the upload.
+func (c *Client) uploadParts(ctx context.Context, key string, uploadID *string, r io.Reader, first []byte) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		parts []types.CompletedPart
+	)
+
+	slots := make(chan struct{}, c.uploadConcurrency)
+	data := first
+
+	for num := int32(1); len(data) > 0; num++ {
+		if num > maxParts {
+			cancel(ErrTooManyParts)
+			break
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		partData := data
+
+		wg.Go(func() {
+			defer func() { <-slots }()
+
+			part, err := c.uploadPart(ctx, key, uploadID, num, partData)
+			if err != nil {
+				cancel(err)
+				return
+			}
+
+			mu.Lock()
+			parts = append(parts, part)
+			mu.Unlock()
+		})
+
+		if int64(len(data)) < c.partSize {
+			break
+		}
+
+		var err error
+
+		data, err = readPart(r, c.partSize)
+		if err != nil {
+			cancel(fmt.Errorf("cannot read s3 object data: %w", err))
+			break
+		}
+	}
+
+	wg.Wait()
+
+	err := context.Cause(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slices.SortFunc(parts, func(a, b types.CompletedPart) int {
+		return int(aws.ToInt32(a.PartNumber) - aws.ToInt32(b.PartNumber))
+	})
+
+	return parts, nil
+}
+
+// uploadPart uploads a part of a multipart upload.
+func (c *Client) uploadPart(ctx context.Context, key string, uploadID *string, num int32, data []byte) (types.CompletedPart, error) {
+	resp, err := c.s3.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(c.bucketName),
+		Key:           aws.String(key),
+		UploadId:      uploadID,
+		PartNumber:    aws.Int32(num),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return types.CompletedPart{}, fmt.Errorf("cannot upload s3 multipart upload part %d: %w", num, err)
+	}
+
+	var etag *string
+
+	if resp != nil {
+		etag = resp.ETag
+	}
+
+	return types.CompletedPart{ETag: etag, PartNumber: aws.Int32(num)}, nil
+}
+
+// readPart reads up to size bytes from r; it returns less only at the end of r.
+func readPart(r io.Reader, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return buf[:n], nil
+}
@@ -0,0 +1,208 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartMock records the multipart upload requests sent to a s3mock.
+type multipartMock struct {
+	mu        sync.Mutex
+	parts     map[int32]string
+	completed []types.CompletedPart
+	aborted   bool
+
+	errCreate   error
+	nilCreate   bool
+	errPart     error
+	errComplete error
+	errAbort    error
+}
+
+func (m *multipartMock) mock() s3mock {
+	return s3mock{
+		createFn: func(_ context.Context, params *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+			if m.errCreate != nil {
+				return nil, m.errCreate
+			}
+
+			if m.nilCreate {
+				return nil, nil //nolint:nilnil
+			}
+
+			if aws.ToString(params.ContentType) != "text/plain" {
+				return nil, errors.New("missing content type")
+			}
+
+			return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+		},
+		uploadPartFn: func(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+			num := aws.ToInt32(params.PartNumber)
+
+			if m.errPart != nil && num == 2 {
+				return nil, m.errPart
+			}
+
+			data, err := io.ReadAll(params.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			m.parts[num] = string(data)
+
+			return &s3.UploadPartOutput{ETag: aws.String("etag-" + strconv.Itoa(int(num)))}, nil
+		},
+		completeFn: func(_ context.Context, params *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+			if m.errComplete != nil {
+				return nil, m.errComplete
+			}
+
+			m.completed = params.MultipartUpload.Parts
+
+			return &s3.CompleteMultipartUploadOutput{}, nil
+		},
+		abortFn: func(_ context.Context, params *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+			if aws.ToString(params.UploadId) != "upload-1" {
+				return nil, errors.New("unknown upload ID")
+			}
+
+			m.aborted = true
+
+			return &s3.AbortMultipartUploadOutput{}, m.errAbort
+		},
+	}
+}
+
+func TestClient_Put_multipart(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mock        *multipartMock
+		reader      io.Reader
+		partSize    int64
+		wantParts   map[int32]string
+		wantAborted bool
+		wantErr     []string
+	}{
+		{
+			name:      "success",
+			mock:      &multipartMock{},
+			reader:    strings.NewReader("abcdefghij"),
+			partSize:  4,
+			wantParts: map[int32]string{1: "abcd", 2: "efgh", 3: "ij"},
+		},
+		{
+			name:      "multiple of part size",
+			mock:      &multipartMock{},
+			reader:    iotest.OneByteReader(strings.NewReader("abcdefgh")),
+			partSize:  4,
+			wantParts: map[int32]string{1: "abcd", 2: "efgh"},
+		},
+		{
+			name:     "create error",
+			mock:     &multipartMock{errCreate: errors.New("create error")},
+			reader:   strings.NewReader("abcdefghij"),
+			partSize: 4,
+			wantErr:  []string{"create error"},
+		},
+		{
+			name:     "nil create response",
+			mock:     &multipartMock{nilCreate: true},
+			reader:   strings.NewReader("abcdefghij"),
+			partSize: 4,
+			wantErr:  []string{errNoUploadID.Error()},
+		},
+		{
+			name:        "part error",
+			mock:        &multipartMock{errPart: errors.New("part error")},
+			reader:      strings.NewReader("abcdefghij"),
+			partSize:    4,
+			wantAborted: true,
+			wantErr:     []string{"part 2: part error"},
+		},
+		{
+			name:        "read error",
+			mock:        &multipartMock{},
+			reader:      io.MultiReader(strings.NewReader("abcd"), iotest.ErrReader(errors.New("read error"))),
+			partSize:    4,
+			wantAborted: true,
+			wantErr:     []string{"read error"},
+		},
+		{
+			name:        "complete error",
+			mock:        &multipartMock{errComplete: errors.New("complete error")},
+			reader:      strings.NewReader("abcdefghij"),
+			partSize:    4,
+			wantAborted: true,
+			wantErr:     []string{"complete error"},
+		},
+		{
+			name:        "abort error",
+			mock:        &multipartMock{errComplete: errors.New("complete error"), errAbort: errors.New("abort error")},
+			reader:      strings.NewReader("abcdefghij"),
+			partSize:    4,
+			wantAborted: true,
+			wantErr:     []string{"complete error", "abort error"},
+		},
+		{
+			name:        "too many parts",
+			mock:        &multipartMock{},
+			reader:      strings.NewReader(strings.Repeat("a", maxParts+1)),
+			partSize:    1,
+			wantAborted: true,
+			wantErr:     []string{ErrTooManyParts.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.mock.parts = make(map[int32]string)
+
+			cli, err := New(t.Context(), "bucket", WithS3Client(tt.mock.mock()), WithUploadConcurrency(2))
+			require.NoError(t, err)
+
+			cli.partSize = tt.partSize
+
+			err = cli.Put(t.Context(), "key", tt.reader, WithContentType("text/plain"))
+			require.Equal(t, tt.wantAborted, tt.mock.aborted)
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+
+				for _, msg := range tt.wantErr {
+					require.ErrorContains(t, err, msg)
+				}
+
+				require.Nil(t, tt.mock.completed)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantParts, tt.mock.parts)
+			require.Len(t, tt.mock.completed, len(tt.wantParts))
+
+			for i, part := range tt.mock.completed {
+				require.Equal(t, int32(i+1), aws.ToInt32(part.PartNumber))
+				require.Equal(t, "etag-"+strconv.Itoa(i+1), aws.ToString(part.ETag))
+			}
+		})
+	}
+}
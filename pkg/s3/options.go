@@ -3,9 +3,11 @@ package s3
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	sep "github.com/aws/smithy-go/endpoints"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
 )
@@ -62,6 +64,26 @@ func WithEndpointImmutable(url string) Option {
 	)
 }
 
+// WithPartSize sets the size in bytes of the multipart upload parts used by
+// [Client.Put] (default [DefaultPartSize], from [MinPartSize] to
+// [MaxPartSize]). Content shorter than a part is uploaded with a single
+// request. Each concurrent part is buffered in memory, and an upload has at
+// most 10000 parts, so the part size bounds both the memory use and the
+// maximum size of a streamed object.
+func WithPartSize(size int64) Option {
+	return func(c *cfg) {
+		c.partSize = size
+	}
+}
+
+// WithUploadConcurrency sets the number of parts of a multipart upload sent
+// in parallel (default [DefaultUploadConcurrency]). It must be positive.
+func WithUploadConcurrency(n int) Option {
+	return func(c *cfg) {
+		c.uploadConcurrency = n
+	}
+}
+
 // endpointResolver resolves all S3 requests to a fixed endpoint URL.
 type endpointResolver struct {
 	url string
@@ -79,3 +101,120 @@ func (r *endpointResolver) ResolveEndpoint(_ context.Context, _ s3.EndpointParam
 
 	return sep.Endpoint{URI: *u}, nil
 }
+
+// PutOption sets an object property for [Client.Put], [Client.Copy] and
+// [Client.PresignPut].
+type PutOption func(*putConfig)
+
+// WithContentType sets the object Content-Type.
+func WithContentType(contentType string) PutOption {
+	return func(pc *putConfig) {
+		pc.contentType = contentType
+	}
+}
+
+// WithMetadata sets the user-defined object metadata (x-amz-meta-* headers).
+func WithMetadata(metadata map[string]string) PutOption {
+	return func(pc *putConfig) {
+		pc.metadata = metadata
+	}
+}
+
+// WithServerSideEncryption sets the server-side encryption algorithm of the
+// object, e.g. types.ServerSideEncryptionAwsKms with an optional KMS key ID
+// (empty for the AWS managed key).
+func WithServerSideEncryption(algorithm types.ServerSideEncryption, kmsKeyID string) PutOption {
+	return func(pc *putConfig) {
+		pc.sse = algorithm
+		pc.kmsKeyID = kmsKeyID
+	}
+}
+
+// WithStorageClass sets the object storage class, e.g. types.StorageClassStandardIa.
+func WithStorageClass(class types.StorageClass) PutOption {
+	return func(pc *putConfig) {
+		pc.storageClass = class
+	}
+}
+
+// WithTags sets the object tags.
+func WithTags(tags map[string]string) PutOption {
+	return func(pc *putConfig) {
+		pc.tags = tags
+	}
+}
+
+// GetOption sets a byte range or a condition for [Client.Get] and
+// [Client.PresignGet].
+type GetOption func(*getConfig)
+
+// WithRange downloads the bytes from start to end, inclusive and zero-based;
+// a negative end downloads to the end of the object. See [Object.ContentRange].
+func WithRange(start, end int64) GetOption {
+	return func(gc *getConfig) {
+		gc.rangeSet = true
+		gc.rangeStart = start
+		gc.rangeEnd = end
+	}
+}
+
+// WithIfMatch downloads the object only if its ETag matches etag, otherwise
+// the download fails with [ErrPreconditionFailed].
+func WithIfMatch(etag string) GetOption {
+	return func(gc *getConfig) {
+		gc.ifMatch = etag
+	}
+}
+
+// WithIfNoneMatch downloads the object only if its ETag differs from etag,
+// otherwise the download fails with [ErrNotModified].
+func WithIfNoneMatch(etag string) GetOption {
+	return func(gc *getConfig) {
+		gc.ifNoneMatch = etag
+	}
+}
+
+// WithIfModifiedSince downloads the object only if modified after t,
+// otherwise the download fails with [ErrNotModified].
+func WithIfModifiedSince(t time.Time) GetOption {
+	return func(gc *getConfig) {
+		gc.ifModifiedSince = t
+	}
+}
+
+// WithIfUnmodifiedSince downloads the object only if not modified after t,
+// otherwise the download fails with [ErrPreconditionFailed].
+func WithIfUnmodifiedSince(t time.Time) GetOption {
+	return func(gc *getConfig) {
+		gc.ifUnmodifiedSince = t
+	}
+}
+
+// ListOption sets a listing parameter for [Client.Objects], [Client.Keys],
+// [Client.ListObjects] and [Client.ListKeys].
+type ListOption func(*listConfig)
+
+// WithDelimiter groups the keys that share a prefix up to the first delimiter
+// after the listing prefix (e.g. "/") in a single common prefix entry, to list
+// a bucket like a directory tree. See [ObjectInfo.IsPrefix].
+func WithDelimiter(delimiter string) ListOption {
+	return func(lc *listConfig) {
+		lc.delimiter = delimiter
+	}
+}
+
+// WithStartAfter lists the keys after key in lexicographic order, to resume a
+// listing.
+func WithStartAfter(key string) ListOption {
+	return func(lc *listConfig) {
+		lc.startAfter = key
+	}
+}
+
+// WithPageSize sets the maximum number of entries of each listing request, up
+// to [MaxListPageSize] (the S3 default when zero).
+func WithPageSize(n int) ListOption {
+	return func(lc *listConfig) {
+		lc.pageSize = n
+	}
+}
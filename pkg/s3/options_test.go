@@ -102,3 +102,19 @@ func Test_ResolveEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func Test_WithPartSize(t *testing.T) {
+	t.Parallel()
+
+	conf := &cfg{}
+	WithPartSize(MinPartSize)(conf)
+	require.Equal(t, int64(MinPartSize), conf.partSize)
+}
+
+func Test_WithUploadConcurrency(t *testing.T) {
+	t.Parallel()
+
+	conf := &cfg{}
+	WithUploadConcurrency(7)(conf)
+	require.Equal(t, 7, conf.uploadConcurrency)
+}
@@ -0,0 +1,102 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// maxPresignExpiration is the maximum validity of a presigned URL.
+const maxPresignExpiration = 7 * 24 * time.Hour
+
+// PresignedRequest is a presigned HTTP request granting temporary access to
+// an object without AWS credentials.
+type PresignedRequest struct {
+	// URL is the presigned URL.
+	URL string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Header contains the signed headers that the request must carry with the
+	// same values, e.g. the metadata set with WithMetadata.
+	Header http.Header
+}
+
+// PresignGet returns a presigned request downloading the object key, valid for
+// expires (1 second to 7 days). The options set a byte range or conditions as
+// with [Client.Get].
+//
+// It returns [ErrEmptyKey], [ErrInvalidRange], [ErrInvalidExpiration], or
+// [ErrPresignNotSupported] when the client does not use the AWS SDK S3 client.
+func (c *Client) PresignGet(ctx context.Context, key string, expires time.Duration, opts ...GetOption) (*PresignedRequest, error) {
+	err := c.checkPresign(expires)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := c.getInput(key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.presigner.PresignGetObject(ctx, input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("cannot presign s3 object download: %w", err)
+	}
+
+	return &PresignedRequest{URL: req.URL, Method: req.Method, Header: req.SignedHeader}, nil
+}
+
+// PresignPut returns a presigned request uploading the object key with a
+// single PUT, valid for expires (1 second to 7 days). The options set the
+// object properties as with [Client.Put]; the uploader must send the returned
+// signed headers.
+//
+// It returns [ErrEmptyKey], [ErrInvalidExpiration], or [ErrPresignNotSupported]
+// when the client does not use the AWS SDK S3 client.
+func (c *Client) PresignPut(ctx context.Context, key string, expires time.Duration, opts ...PutOption) (*PresignedRequest, error) {
+	err := c.checkPresign(expires)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+
+	pc := newPutConfig(opts)
+
+	req, err := c.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(c.bucketName),
+		Key:                  aws.String(key),
+		ContentType:          pc.contentTypePtr(),
+		Metadata:             pc.metadata,
+		ServerSideEncryption: pc.sse,
+		SSEKMSKeyId:          pc.kmsKeyIDPtr(),
+		StorageClass:         pc.storageClass,
+		Tagging:              pc.tagging(),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, fmt.Errorf("cannot presign s3 object upload: %w", err)
+	}
+
+	return &PresignedRequest{URL: req.URL, Method: req.Method, Header: req.SignedHeader}, nil
+}
+
+// checkPresign validates the presigning support and expiration.
+func (c *Client) checkPresign(expires time.Duration) error {
+	if c.presigner == nil {
+		return ErrPresignNotSupported
+	}
+
+	if expires < time.Second || expires > maxPresignExpiration {
+		return ErrInvalidExpiration
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package s3
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Presign(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(t.Context(), "bucket", WithAWSOptions(testAWSOptions()), WithEndpointImmutable("https://s3.test.invalid"))
+	require.NoError(t, err)
+
+	req, err := cli.PresignGet(t.Context(), "dir/k1", 15*time.Minute, WithRange(0, 9))
+	require.NoError(t, err)
+	require.Equal(t, http.MethodGet, req.Method)
+	require.Equal(t, "bytes=0-9", req.Header.Get("Range"))
+
+	u, err := url.Parse(req.URL)
+	require.NoError(t, err)
+	require.Equal(t, "/dir/k1", u.Path)
+	require.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+	require.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+
+	req, err = cli.PresignPut(t.Context(), "k1", maxPresignExpiration, WithServerSideEncryption(types.ServerSideEncryptionAes256, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Equal(t, "AES256", req.Header.Get("X-Amz-Server-Side-Encryption"))
+
+	_, err = cli.PresignGet(t.Context(), "", time.Minute)
+	require.ErrorIs(t, err, ErrEmptyKey)
+
+	_, err = cli.PresignGet(t.Context(), "k1", time.Minute, WithRange(2, 1))
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = cli.PresignPut(t.Context(), "", time.Minute)
+	require.ErrorIs(t, err, ErrEmptyKey)
+
+	_, err = cli.PresignGet(t.Context(), "k1", time.Millisecond)
+	require.ErrorIs(t, err, ErrInvalidExpiration)
+
+	_, err = cli.PresignPut(t.Context(), "k1", maxPresignExpiration+time.Second)
+	require.ErrorIs(t, err, ErrInvalidExpiration)
+
+	mocked, err := New(t.Context(), "bucket", WithS3Client(s3mock{}))
+	require.NoError(t, err)
+
+	_, err = mocked.PresignGet(t.Context(), "k1", time.Minute)
+	require.ErrorIs(t, err, ErrPresignNotSupported)
+
+	_, err = mocked.PresignPut(t.Context(), "k1", time.Minute)
+	require.ErrorIs(t, err, ErrPresignNotSupported)
+}
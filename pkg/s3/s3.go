@@ -2,10 +2,12 @@
 Package s3 provides helpers built on the AWS SDK v2 S3 client for common bucket
 object operations:
 
-  - upload object data,
-  - download object data,
-  - list object keys by prefix,
-  - delete objects.
+  - upload object data, with multipart uploads for large or streamed content,
+  - download object data, whole or by byte range,
+  - copy objects and read their metadata,
+  - list objects by prefix, lazily or into a slice,
+  - delete objects, one by one or in batches,
+  - presign download and upload URLs.
 
 It is built on github.com/aws/aws-sdk-go-v2/service/s3.
 
@@ -14,10 +16,18 @@ It is built on github.com/aws/aws-sdk-go-v2/service/s3.
   - [New] to create a bucket-scoped [Client].
   - [Client.Put] to upload from an [io.Reader].
   - [Client.Get] to fetch an object and access its body stream.
+  - [Client.Head] to read the object metadata without downloading it.
+  - [Client.Copy] to copy an object server-side.
+  - [Client.PresignGet] and [Client.PresignPut] to grant temporary access to
+    an object without AWS credentials.
+  - [Client.Objects] and [Client.Keys] to iterate lazily over the objects or
+    keys, optionally filtered by prefix.
   - [Client.ListKeys] to list object keys, optionally filtered by prefix.
   - [Client.ListObjects] to list objects with per-object metadata (size,
     last-modified, ETag), optionally filtered by prefix.
   - [Client.Delete] to remove an object by key.
+  - [Client.DeleteMany] to remove objects in batches, reporting the failed
+    keys with [DeleteError].
   - [Client.HealthCheck] to verify bucket reachability and access permissions.
 
 # Configuration & Extensibility
@@ -30,7 +40,36 @@ and exposes option hooks:
   - [WithS3Client] to inject a custom S3 implementation (tests and advanced
     integrations; skips AWS configuration loading),
   - [WithEndpointMutable] and [WithEndpointImmutable] for endpoint overrides
-    (useful for local S3-compatible environments and tests).
+    (useful for local S3-compatible environments and tests),
+  - [WithPartSize] and [WithUploadConcurrency] to tune multipart uploads.
+
+# Uploads
+
+[Client.Put] buffers up to one part of the content: shorter content is sent
+with a single request, while longer content, or a stream of unknown length, is
+sent with a multipart upload of parallel parts. A failed multipart upload is
+aborted, so no partial object or orphaned parts are left behind.
+
+The [PutOption] values set the object content type ([WithContentType]),
+metadata ([WithMetadata]), server-side encryption
+([WithServerSideEncryption]), storage class ([WithStorageClass]) and tags
+([WithTags]); they also apply to [Client.Copy] and [Client.PresignPut].
+
+# Downloads
+
+The [GetOption] values request a byte range ([WithRange]) or set conditions
+([WithIfMatch], [WithIfNoneMatch], [WithIfModifiedSince],
+[WithIfUnmodifiedSince]). Unmet conditions return errors matching
+[ErrNotModified] or [ErrPreconditionFailed], and a missing object returns an
+error matching [ErrObjectNotFound].
+
+# Listing
+
+[Client.Objects] and [Client.Keys] return iterators fetching one page at a
+time, so buckets with millions of objects are listed in constant memory;
+[Client.ListObjects] and [Client.ListKeys] collect them into a slice. The
+[ListOption] values group keys into common prefixes ([WithDelimiter]), resume
+a listing ([WithStartAfter]) and set the page size ([WithPageSize]).
 
 # Usage
 
@@ -39,23 +78,35 @@ and exposes option hooks:
 	    return err
 	}
 
-	if err := c.Put(ctx, "reports/latest.json", reader); err != nil {
+	if err := c.Put(ctx, "reports/latest.json", reader, s3.WithContentType("application/json")); err != nil {
 	    return err
 	}
 
-	obj, err := c.Get(ctx, "reports/latest.json")
+	obj, err := c.Get(ctx, "reports/latest.json", s3.WithRange(0, 1023))
 	if err != nil {
 	    return err
 	}
-	_ = obj
+	defer obj.Close()
 
-	keys, err := c.ListKeys(ctx, "reports/")
+	req, err := c.PresignGet(ctx, "reports/latest.json", 15*time.Minute)
 	if err != nil {
 	    return err
 	}
-	_ = keys
+	_ = req.URL
+
+	var old []string
+
+	for obj, err := range c.Objects(ctx, "reports/", s3.WithDelimiter("/")) {
+	    if err != nil {
+	        return err
+	    }
+
+	    if !obj.IsPrefix && obj.LastModified.Before(cutoff) {
+	        old = append(old, obj.Key)
+	    }
+	}
 
-	if err := c.Delete(ctx, "reports/old.json"); err != nil {
+	if err := c.DeleteMany(ctx, old); err != nil {
 	    return err
 	}
 
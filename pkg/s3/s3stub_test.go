@@ -0,0 +1,454 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/awsopt"
+)
+
+// stubObject is an object stored by s3stub.
+type stubObject struct {
+	data        []byte
+	contentType string
+	metadata    http.Header
+}
+
+// s3stub is a minimal S3-compatible HTTP server supporting the requests sent
+// by Client, for the tests using the AWS SDK client through
+// WithEndpointImmutable. The bucket is not part of the request path.
+type s3stub struct {
+	mu      sync.Mutex
+	objects map[string]*stubObject
+	parts   map[string][]byte
+	upload  string // content type of the pending multipart upload
+	aborted int
+}
+
+// testAWSOptions returns AWS options with a region and static credentials, so
+// that requests can be signed without an AWS environment.
+func testAWSOptions() awsopt.Options {
+	o := awsopt.Options{}
+	o.WithRegion("eu-central-1")
+	o.WithAWSOption(config.WithCredentialsProvider(aws.CredentialsProviderFunc(
+		func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		})))
+
+	return o
+}
+
+func newS3Stub(t *testing.T) (*s3stub, *Client) {
+	t.Helper()
+
+	stub := &s3stub{
+		objects: make(map[string]*stubObject),
+		parts:   make(map[string][]byte),
+	}
+
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	cli, err := New(
+		t.Context(),
+		"bucket",
+		WithAWSOptions(testAWSOptions()),
+		WithEndpointImmutable(srv.URL),
+		WithPartSize(MinPartSize),
+	)
+	require.NoError(t, err)
+
+	return stub, cli
+}
+
+func (s *s3stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	q := r.URL.Query()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && q.Get("list-type") == "2":
+		s.list(w, q)
+	case r.Method == http.MethodPost && q.Has("delete"):
+		s.deleteObjects(w, body)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.upload = r.Header.Get("Content-Type")
+
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Key>%s</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`, key)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		s.parts[q.Get("partNumber")] = body
+		w.Header().Set("ETag", `"etag-`+q.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		s.complete(w, key, body)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		s.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copy(w, r, key)
+	case r.Method == http.MethodPut:
+		s.objects[key] = &stubObject{data: body, contentType: r.Header.Get("Content-Type"), metadata: metaHeaders(r.Header)}
+	case r.Method == http.MethodHead, r.Method == http.MethodGet:
+		s.get(w, r, key)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *s3stub) complete(w http.ResponseWriter, key string, body []byte) {
+	var req struct {
+		Parts []struct {
+			ETag       string
+			PartNumber string
+		} `xml:"Part"`
+	}
+
+	err := xml.Unmarshal(body, &req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var data []byte
+
+	for _, p := range req.Parts {
+		if p.ETag != `"etag-`+p.PartNumber+`"` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data = append(data, s.parts[p.PartNumber]...)
+	}
+
+	s.objects[key] = &stubObject{data: data, contentType: s.upload}
+
+	fmt.Fprintf(w, `<CompleteMultipartUploadResult><Key>%s</Key><ETag>"etag"</ETag></CompleteMultipartUploadResult>`, key)
+}
+
+func (s *s3stub) copy(w http.ResponseWriter, r *http.Request, key string) {
+	src, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	obj, ok := s.objects[strings.TrimPrefix(src, "bucket/")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	dst := *obj
+
+	if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		dst.contentType = r.Header.Get("Content-Type")
+		dst.metadata = metaHeaders(r.Header)
+	}
+
+	s.objects[key] = &dst
+
+	fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+}
+
+func (s *s3stub) get(w http.ResponseWriter, r *http.Request, key string) {
+	obj, ok := s.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%x"`, len(obj.data))
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if m := r.Header.Get("If-Match"); m != "" && m != etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	for k, v := range obj.metadata {
+		w.Header()[k] = v
+	}
+
+	w.Header().Set("Content-Type", obj.contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+
+	data := obj.data
+	status := http.StatusOK
+
+	var start, end int
+
+	if n, _ := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); n == 2 {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func (s *s3stub) list(w http.ResponseWriter, q url.Values) {
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	after := max(q.Get("start-after"), q.Get("continuation-token"))
+
+	maxKeys, err := strconv.Atoi(q.Get("max-keys"))
+	if err != nil {
+		maxKeys = 1000
+	}
+
+	var (
+		keys     []string
+		last     string
+		prefixes = make(map[string]bool)
+	)
+
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	out := `<ListBucketResult>`
+	n := 0
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+
+		cp := ""
+		if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			cp = key[:len(prefix)+i+len(delimiter)]
+		}
+
+		if prefixes[cp] {
+			last = key
+			continue
+		}
+
+		if n == maxKeys {
+			out += `<IsTruncated>true</IsTruncated><NextContinuationToken>` + last + `</NextContinuationToken>`
+			break
+		}
+
+		if cp != "" {
+			prefixes[cp] = true
+			out += `<CommonPrefixes><Prefix>` + cp + `</Prefix></CommonPrefixes>`
+			last = key
+			n++
+
+			continue
+		}
+
+		out += fmt.Sprintf(`<Contents><Key>%s</Key><Size>%d</Size></Contents>`, key, len(s.objects[key].data))
+		last = key
+		n++
+	}
+
+	fmt.Fprint(w, out+`</ListBucketResult>`)
+}
+
+func (s *s3stub) deleteObjects(w http.ResponseWriter, body []byte) {
+	var req struct {
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+
+	err := xml.Unmarshal(body, &req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	out := `<DeleteResult>`
+
+	for _, obj := range req.Objects {
+		if strings.HasPrefix(obj.Key, "locked/") {
+			out += `<Error><Key>` + obj.Key + `</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+			continue
+		}
+
+		delete(s.objects, obj.Key)
+	}
+
+	fmt.Fprint(w, out+`</DeleteResult>`)
+}
+
+// metaHeaders returns the x-amz-meta-* headers.
+func metaHeaders(h http.Header) http.Header {
+	meta := make(http.Header)
+
+	for k, v := range h {
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+			meta[k] = v
+		}
+	}
+
+	return meta
+}
+
+func TestClient_stub(t *testing.T) {
+	t.Parallel()
+
+	stub, cli := newS3Stub(t)
+	ctx := t.Context()
+
+	// single request upload with options
+	err := cli.Put(ctx, "dir/small.txt", strings.NewReader("hello world"),
+		WithContentType("text/plain"),
+		WithMetadata(map[string]string{"owner": "test"}),
+	)
+	require.NoError(t, err)
+
+	info, err := cli.Head(ctx, "dir/small.txt")
+	require.NoError(t, err)
+	require.Equal(t, int64(11), info.Size)
+	require.Equal(t, "text/plain", info.ContentType)
+	require.Equal(t, map[string]string{"owner": "test"}, info.Metadata)
+
+	// ranged and conditional downloads
+	obj, err := cli.Get(ctx, "dir/small.txt", WithRange(6, 10))
+	require.NoError(t, err)
+	require.Equal(t, "bytes 6-10/11", obj.ContentRange())
+	require.Equal(t, map[string]string{"owner": "test"}, obj.Metadata())
+
+	data, err := io.ReadAll(obj.Body())
+	require.NoError(t, err)
+	require.NoError(t, obj.Close())
+	require.Equal(t, "world", string(data))
+
+	_, err = cli.Get(ctx, "dir/small.txt", WithIfNoneMatch(info.ETag))
+	require.ErrorIs(t, err, ErrNotModified)
+
+	_, err = cli.Get(ctx, "dir/small.txt", WithIfMatch(`"other"`))
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+
+	_, err = cli.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrObjectNotFound)
+
+	_, err = cli.Head(ctx, "missing")
+	require.ErrorIs(t, err, ErrObjectNotFound)
+
+	// server-side copy
+	require.NoError(t, cli.Copy(ctx, "dir/small.txt", "dir/copy.txt"))
+	require.NoError(t, cli.Copy(ctx, "dir/small.txt", "dir/replaced.txt", WithContentType("text/markdown")))
+	require.ErrorIs(t, cli.Copy(ctx, "missing", "dir/copy.txt"), ErrObjectNotFound)
+
+	info, err = cli.Head(ctx, "dir/copy.txt")
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", info.ContentType)
+	require.Equal(t, map[string]string{"owner": "test"}, info.Metadata)
+
+	info, err = cli.Head(ctx, "dir/replaced.txt")
+	require.NoError(t, err)
+	require.Equal(t, "text/markdown", info.ContentType)
+	require.Empty(t, info.Metadata)
+
+	// multipart upload of a stream of unknown length
+	large := bytes.Repeat([]byte("0123456789"), (2*MinPartSize+MinPartSize/2)/10)
+
+	err = cli.Put(ctx, "large.bin", io.MultiReader(bytes.NewReader(large)), WithContentType("application/octet-stream"))
+	require.NoError(t, err)
+
+	obj, err = cli.Get(ctx, "large.bin")
+	require.NoError(t, err)
+
+	data, err = io.ReadAll(obj.Body())
+	require.NoError(t, err)
+	require.NoError(t, obj.Close())
+	require.Equal(t, large, data)
+	require.Equal(t, "application/octet-stream", obj.ContentType())
+	require.Len(t, stub.parts, 3)
+	require.Zero(t, stub.aborted)
+
+	// presigned URLs
+	req, err := cli.PresignGet(ctx, "dir/small.txt", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodGet, req.Method)
+
+	resp, err := http.Get(req.URL) //nolint:noctx
+	require.NoError(t, err)
+
+	data, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "hello world", string(data))
+
+	req, err = cli.PresignPut(ctx, "dir/upload.txt", time.Hour, WithContentType("text/csv"), WithMetadata(map[string]string{"owner": "test"}), WithStorageClass("STANDARD_IA"))
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Contains(t, req.URL, "X-Amz-Expires=3600")
+	require.Equal(t, []string{"test"}, req.Header.Values("X-Amz-Meta-Owner"))
+
+	preq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, strings.NewReader("a,b"))
+	require.NoError(t, err)
+
+	preq.Header = req.Header.Clone()
+
+	resp, err = http.DefaultClient.Do(preq)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "a,b", string(stub.objects["dir/upload.txt"].data))
+	require.Equal(t, []string{"test"}, stub.objects["dir/upload.txt"].metadata.Values("X-Amz-Meta-Owner"))
+
+	// lazy listing with common prefixes and batch delete
+	require.NoError(t, cli.Put(ctx, "locked/a.txt", strings.NewReader("a")))
+
+	var entries []string
+
+	for obj, err := range cli.Objects(ctx, "", WithDelimiter("/"), WithPageSize(1)) {
+		require.NoError(t, err)
+
+		entries = append(entries, obj.Key+"/"+strconv.FormatBool(obj.IsPrefix))
+	}
+
+	require.Equal(t, []string{"dir//true", "large.bin/false", "locked//true"}, entries)
+
+	keys, err := cli.ListKeys(ctx, "dir/", WithStartAfter("dir/replaced.txt"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"dir/small.txt", "dir/upload.txt"}, keys)
+
+	err = cli.DeleteMany(ctx, []string{"dir/copy.txt", "locked/a.txt", "large.bin"})
+
+	var derr *DeleteError
+
+	require.ErrorAs(t, err, &derr)
+	require.Equal(t, []string{"locked/a.txt"}, derr.Keys())
+	require.Equal(t, "AccessDenied", derr.Entries[0].Code)
+	require.NotContains(t, stub.objects, "dir/copy.txt")
+	require.NotContains(t, stub.objects, "large.bin")
+}
@@ -32,6 +32,9 @@ Rollback behavior is guarded to avoid noisy false failures:
 For a similar helper using github.com/jmoiron/sqlx instead of database/sql,
 see:
 github.com/tecnickcom/nurago/pkg/sqlxtransaction
+
+To publish messages atomically with the transaction, enqueue them inside the
+[ExecFunc] with github.com/tecnickcom/nurago/pkg/outbox.
 */
 package sqltransaction
 
@@ -33,6 +33,9 @@ Rollback behavior is deferred and guarded:
 For a similar helper based on the standard database/sql package (instead of
 github.com/jmoiron/sqlx), see:
 github.com/tecnickcom/nurago/pkg/sqltransaction
+
+To publish messages atomically with the transaction, enqueue them inside the
+[ExecFunc] with github.com/tecnickcom/nurago/pkg/outbox.
 */
 package sqlxtransaction
 
@@ -0,0 +1,113 @@
+package sqs
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+const (
+	// DefaultTraceIDAttribute is the default name of the message attribute
+	// carrying the trace ID, matching the traceid HTTP header.
+	DefaultTraceIDAttribute = traceid.DefaultHeader
+
+	// MaxMessageAttributes is the maximum number of attributes of an SQS
+	// message, including the trace ID and payload offloading ones.
+	MaxMessageAttributes = 10
+
+	// Message attribute data types.
+	dataTypeString = "String"
+	dataTypeNumber = "Number"
+
+	// regexPatternAttributeName validates the message attribute names.
+	regexPatternAttributeName = `^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,255}$`
+)
+
+// regexAttributeName is the precompiled validator for message attribute names.
+var regexAttributeName = regexp.MustCompile(regexPatternAttributeName)
+
+// messageAttributes converts the attributes of a message to send, adding the
+// trace ID of ctx unless already set. It returns [ErrInvalidAttributes] for
+// too many attributes, an invalid or reserved name, or an empty value.
+func (c *Client) messageAttributes(ctx context.Context, attrs map[string]string) (map[string]types.MessageAttributeValue, error) {
+	out := make(map[string]types.MessageAttributeValue, len(attrs)+1)
+
+	for name, value := range attrs {
+		if !validAttributeName(name) || value == "" {
+			return nil, ErrInvalidAttributes
+		}
+
+		out[name] = types.MessageAttributeValue{DataType: aws.String(dataTypeString), StringValue: aws.String(value)}
+	}
+
+	if c.traceIDAttribute != "" {
+		if _, ok := out[c.traceIDAttribute]; !ok {
+			if id := traceid.FromContext(ctx, ""); traceid.Valid(id) {
+				out[c.traceIDAttribute] = types.MessageAttributeValue{DataType: aws.String(dataTypeString), StringValue: aws.String(id)}
+			}
+		}
+	}
+
+	if len(out) > MaxMessageAttributes {
+		return nil, ErrInvalidAttributes
+	}
+
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	return out, nil
+}
+
+// validAttributeName reports whether name is a valid user attribute name:
+// up to 256 alphanumeric, '-', '_' and '.' characters, not starting or ending
+// with '.', without "..", and without the reserved "AWS." and "Amazon."
+// prefixes or the payload offloading attribute names.
+func validAttributeName(name string) bool {
+	if !regexAttributeName.MatchString(name) || strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
+		return false
+	}
+
+	lname := strings.ToLower(name)
+
+	return !strings.HasPrefix(lname, "aws.") &&
+		!strings.HasPrefix(lname, "amazon.") &&
+		name != payloadSizeAttribute &&
+		name != legacyPayloadSizeAttribute
+}
+
+// messageSize returns the size of a message as counted by SQS: the body plus
+// the name, data type and value of each attribute.
+func messageSize(body string, attrs map[string]types.MessageAttributeValue) int {
+	size := len(body)
+
+	for name, v := range attrs {
+		size += len(name) + len(aws.ToString(v.DataType)) + len(aws.ToString(v.StringValue)) + len(v.BinaryValue)
+	}
+
+	return size
+}
+
+// receivedAttributes returns the String and Number attributes of a received
+// message; Binary attributes are skipped.
+func receivedAttributes(attrs map[string]types.MessageAttributeValue) map[string]string {
+	out := make(map[string]string, len(attrs))
+
+	for name, v := range attrs {
+		dt := aws.ToString(v.DataType)
+
+		if strings.HasPrefix(dt, dataTypeString) || strings.HasPrefix(dt, dataTypeNumber) {
+			out[name] = aws.ToString(v.StringValue)
+		}
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return out
+}
@@ -0,0 +1,177 @@
+package sqs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+func TestClient_SendWithAttributes(t *testing.T) {
+	t.Parallel()
+
+	tooMany := make(map[string]string, MaxMessageAttributes)
+	for i := range MaxMessageAttributes {
+		tooMany["attr"+strconv.Itoa(i)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context //nolint:containedctx
+		opts    []Option
+		attrs   map[string]string
+		want    map[string]types.MessageAttributeValue
+		wantErr bool
+	}{
+		{
+			name: "no attributes",
+			ctx:  t.Context(),
+		},
+		{
+			name:  "attributes and trace ID",
+			ctx:   traceid.NewContext(t.Context(), "trace-123"),
+			attrs: map[string]string{"content-type": "application/json"},
+			want: map[string]types.MessageAttributeValue{
+				"content-type": {DataType: aws.String("String"), StringValue: aws.String("application/json")},
+				"X-Request-ID": {DataType: aws.String("String"), StringValue: aws.String("trace-123")},
+			},
+		},
+		{
+			name:  "explicit trace ID attribute",
+			ctx:   traceid.NewContext(t.Context(), "trace-123"),
+			attrs: map[string]string{"X-Request-ID": "custom"},
+			want: map[string]types.MessageAttributeValue{
+				"X-Request-ID": {DataType: aws.String("String"), StringValue: aws.String("custom")},
+			},
+		},
+		{
+			name: "custom trace ID attribute",
+			ctx:  traceid.NewContext(t.Context(), "trace-123"),
+			opts: []Option{WithTraceIDAttribute("traceid")},
+			want: map[string]types.MessageAttributeValue{
+				"traceid": {DataType: aws.String("String"), StringValue: aws.String("trace-123")},
+			},
+		},
+		{
+			name: "disabled trace ID",
+			ctx:  traceid.NewContext(t.Context(), "trace-123"),
+			opts: []Option{WithTraceIDAttribute("")},
+		},
+		{
+			name: "invalid trace ID",
+			ctx:  traceid.NewContext(t.Context(), "trace 123"),
+		},
+		{
+			name:    "reserved name",
+			ctx:     t.Context(),
+			attrs:   map[string]string{"AWS.trace": "v"},
+			wantErr: true,
+		},
+		{
+			name:    "reserved payload name",
+			ctx:     t.Context(),
+			attrs:   map[string]string{payloadSizeAttribute: "1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			ctx:     t.Context(),
+			attrs:   map[string]string{"a..b": "v"},
+			wantErr: true,
+		},
+		{
+			name:    "empty value",
+			ctx:     t.Context(),
+			attrs:   map[string]string{"a": ""},
+			wantErr: true,
+		},
+		{
+			name:    "too many with trace ID",
+			ctx:     traceid.NewContext(t.Context(), "trace-123"),
+			attrs:   tooMany,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var input *sqs.SendMessageInput
+
+			mock := sqsmock{sendFn: func(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+				input = params
+				return &sqs.SendMessageOutput{}, nil
+			}}
+
+			opts := append([]Option{WithSQSClient(mock)}, tt.opts...)
+
+			cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", opts...)
+			require.NoError(t, err)
+
+			err = cli.SendWithAttributes(tt.ctx, "message", tt.attrs)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidAttributes)
+				require.Nil(t, input)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "message", aws.ToString(input.MessageBody))
+			require.Equal(t, tt.want, input.MessageAttributes)
+		})
+	}
+}
+
+func TestClient_Receive_attributes(t *testing.T) {
+	t.Parallel()
+
+	var input *sqs.ReceiveMessageInput
+
+	mock := sqsmock{receiveFn: func(_ context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+		input = params
+
+		return &sqs.ReceiveMessageOutput{Messages: []types.Message{{
+			Body:          aws.String("message"),
+			ReceiptHandle: aws.String("rh"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"X-Request-ID": {DataType: aws.String("String"), StringValue: aws.String("trace-123")},
+				"count":        {DataType: aws.String("Number.int"), StringValue: aws.String("42")},
+				"blob":         {DataType: aws.String("Binary"), BinaryValue: []byte("data")},
+			},
+		}}}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	msg, err := cli.Receive(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"All"}, input.MessageAttributeNames)
+	require.Equal(t, &Message{
+		Body:          "message",
+		ReceiptHandle: "rh",
+		Attributes:    map[string]string{"X-Request-ID": "trace-123", "count": "42"},
+		TraceID:       "trace-123",
+	}, msg)
+}
+
+func Test_validAttributeName(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, validAttributeName("content-type"))
+	require.True(t, validAttributeName("a.b_c-D9"))
+	require.False(t, validAttributeName(""))
+	require.False(t, validAttributeName(".a"))
+	require.False(t, validAttributeName("a."))
+	require.False(t, validAttributeName("a..b"))
+	require.False(t, validAttributeName("a b"))
+	require.False(t, validAttributeName("amazon.x"))
+	require.False(t, validAttributeName(legacyPayloadSizeAttribute))
+}
@@ -0,0 +1,431 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// MaxBatchSize is the maximum number of entries of an SQS batch request.
+	MaxBatchSize = 10
+
+	// MaxBatchPayloadSize is the maximum total size in bytes (256 KiB) of the
+	// message bodies and attributes of a SendMessageBatch request.
+	MaxBatchPayloadSize = 262144
+)
+
+// SendEntry is a message to publish with [Client.SendBatch].
+type SendEntry struct {
+	// Body is the message content.
+	Body string
+
+	// DeduplicationID is the optional MessageDeduplicationId of a FIFO queue
+	// message. See [Client.SendWithDeduplicationID].
+	DeduplicationID string
+
+	// Attributes are the optional String message attributes.
+	// See [Client.SendWithAttributes].
+	Attributes map[string]string
+}
+
+// BatchEntryError is the failure of an entry of a batch operation.
+type BatchEntryError struct {
+	// Index is the position of the entry in the batch operation input, or in
+	// the received messages for [Client.ReceiveBatch].
+	Index int
+
+	// Code is the SQS error code, empty when the whole request failed.
+	Code string
+
+	// SenderFault reports whether the failure was caused by the request.
+	SenderFault bool
+
+	// Err is the failure.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("entry %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BatchEntryError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports the failed entries of a batch operation, ordered by
+// index; the other entries succeeded. Retrieve it with errors.As.
+type BatchError struct {
+	Entries []*BatchEntryError
+}
+
+// Error implements the error interface.
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		msgs[i] = entry.Error()
+	}
+
+	return fmt.Sprintf("sqs: %d batch entries failed: %s", len(e.Entries), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed entries.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		errs[i] = entry
+	}
+
+	return errs
+}
+
+// Indexes returns the indexes of the failed entries, e.g. to retry them.
+func (e *BatchError) Indexes() []int {
+	idx := make([]int, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		idx[i] = entry.Index
+	}
+
+	return idx
+}
+
+// SendBatch publishes the messages to the queue with SendMessageBatch
+// requests of up to [MaxBatchSize] entries and [MaxBatchPayloadSize] bytes;
+// an entry larger than that is sent alone. The entries are validated first:
+// a deduplication ID is only allowed for FIFO queues ([ErrDedupIDNotAllowed])
+// and must be valid ([ErrInvalidDedupID]), and the attributes must be valid
+// ([ErrInvalidAttributes]), otherwise nothing is sent. Oversized bodies are
+// offloaded as with [Client.Send].
+//
+// When some entries fail, the others are still sent and a [BatchError]
+// reports the failed ones.
+func (c *Client) SendBatch(ctx context.Context, entries []SendEntry) error {
+	attrs := make([]map[string]types.MessageAttributeValue, len(entries))
+
+	for i, e := range entries {
+		a, err := c.messageAttributes(ctx, e.Attributes)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		attrs[i] = a
+
+		if e.DeduplicationID == "" {
+			continue
+		}
+
+		if c.messageGroupID == nil {
+			return fmt.Errorf("entry %d: %w", i, ErrDedupIDNotAllowed)
+		}
+
+		if !regexFifoID.MatchString(e.DeduplicationID) {
+			return fmt.Errorf("entry %d: %w", i, ErrInvalidDedupID)
+		}
+	}
+
+	prepared := c.prepareBatch(ctx, entries, attrs)
+	idx := make([]int, len(entries))
+
+	for i := range idx {
+		idx[i] = i
+	}
+
+	size := func(i int) int { return prepared[i].size }
+
+	return runBatches(idx, size, func(chunk []int) (map[int]*BatchEntryError, error) {
+		input := &sqs.SendMessageBatchInput{
+			QueueUrl: c.queueURL,
+			Entries:  make([]types.SendMessageBatchRequestEntry, 0, len(chunk)),
+		}
+
+		failed := make(map[int]*BatchEntryError)
+
+		for _, i := range chunk {
+			if prepared[i].err != nil {
+				failed[i] = &BatchEntryError{Index: i, Err: prepared[i].err}
+				continue
+			}
+
+			input.Entries = append(input.Entries, prepared[i].entry)
+		}
+
+		if len(input.Entries) == 0 {
+			return failed, nil
+		}
+
+		resp, err := c.sqs.SendMessageBatch(ctx, input)
+		if err != nil {
+			// best effort: the messages were not sent, so the payloads are orphaned
+			for _, i := range chunk {
+				_ = c.deletePayload(context.WithoutCancel(ctx), prepared[i].key)
+			}
+
+			return nil, fmt.Errorf("cannot send message batch to the queue: %w", err)
+		}
+
+		if resp != nil {
+			for i, f := range batchFailures(resp.Failed) {
+				failed[i] = f
+			}
+		}
+
+		// best effort: the failed messages were not sent, so their payloads are orphaned
+		for i := range failed {
+			_ = c.deletePayload(context.WithoutCancel(ctx), prepared[i].key)
+		}
+
+		return failed, nil
+	})
+}
+
+// preparedEntry is a batch entry ready to be sent, with its offloaded body
+// key, if any, and its size, or the error preventing it to be sent.
+type preparedEntry struct {
+	entry types.SendMessageBatchRequestEntry
+	key   string
+	size  int
+	err   error
+}
+
+// prepareBatch returns the batch request entries of the entries with their
+// message attributes attrs, offloading the oversized bodies.
+func (c *Client) prepareBatch(
+	ctx context.Context,
+	entries []SendEntry,
+	attrs []map[string]types.MessageAttributeValue,
+) []preparedEntry {
+	prepared := make([]preparedEntry, len(entries))
+
+	for i, e := range entries {
+		body, msgAttrs, key, err := c.offload(ctx, e.Body, attrs[i])
+		if err != nil {
+			prepared[i].err = err
+			continue
+		}
+
+		entry := types.SendMessageBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(i)),
+			MessageBody:       aws.String(body),
+			MessageGroupId:    c.messageGroupID,
+			MessageAttributes: msgAttrs,
+		}
+
+		if e.DeduplicationID != "" {
+			entry.MessageDeduplicationId = aws.String(e.DeduplicationID)
+		}
+
+		prepared[i] = preparedEntry{entry: entry, key: key, size: messageSize(body, msgAttrs)}
+	}
+
+	return prepared
+}
+
+// ReceiveBatch retrieves up to maxMessages (1 to [MaxBatchSize]) raw messages
+// from the queue with the configured wait and visibility settings. It returns
+// no messages when none is available within the wait time.
+//
+// The offloaded bodies are fetched from S3. When the body of some messages
+// cannot be fetched (e.g. [ErrInvalidPayloadPointer]), the other messages are
+// still returned, together with a [BatchError] reporting the failed ones by
+// position in the received batch; the failed messages become visible again
+// after the visibility timeout, so a queue redrive policy can move them to a
+// dead-letter queue.
+func (c *Client) ReceiveBatch(ctx context.Context, maxMessages int) ([]*Message, error) {
+	if maxMessages < 1 || maxMessages > MaxBatchSize {
+		return nil, ErrInvalidBatchSize
+	}
+
+	resp, err := c.sqs.ReceiveMessage(
+		ctx,
+		&sqs.ReceiveMessageInput{
+			QueueUrl:              c.queueURL,
+			WaitTimeSeconds:       c.waitTimeSeconds,
+			VisibilityTimeout:     c.visibilityTimeout,
+			MaxNumberOfMessages:   int32(maxMessages), //nolint:gosec // bounded by MaxBatchSize
+			MessageAttributeNames: allAttributes,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve messages from the queue: %w", err)
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	var (
+		msgs   = make([]*Message, 0, len(resp.Messages))
+		failed []*BatchEntryError
+	)
+
+	for i, m := range resp.Messages {
+		msg, err := c.message(ctx, m)
+		if err != nil {
+			failed = append(failed, &BatchEntryError{Index: i, Err: err})
+			continue
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	if len(failed) > 0 {
+		return msgs, &BatchError{Entries: failed}
+	}
+
+	return msgs, nil
+}
+
+// DeleteBatch removes the messages from the queue by receipt handle with
+// DeleteMessageBatch requests of up to [MaxBatchSize] entries, and then their
+// offloaded bodies, if any. Empty receipt handles are skipped.
+//
+// When some entries fail, the others are still deleted and a [BatchError]
+// reports the failed ones.
+func (c *Client) DeleteBatch(ctx context.Context, receiptHandles []string) error {
+	idx := make([]int, 0, len(receiptHandles))
+	handles := make([]string, len(receiptHandles))
+	keys := make([]string, len(receiptHandles))
+
+	for i, h := range receiptHandles {
+		if h != "" {
+			idx = append(idx, i)
+			handles[i], keys[i] = splitReceiptHandle(h)
+		}
+	}
+
+	return runBatches(idx, nil, func(chunk []int) (map[int]*BatchEntryError, error) {
+		input := &sqs.DeleteMessageBatchInput{
+			QueueUrl: c.queueURL,
+			Entries:  make([]types.DeleteMessageBatchRequestEntry, 0, len(chunk)),
+		}
+
+		for _, i := range chunk {
+			input.Entries = append(input.Entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: aws.String(handles[i]),
+			})
+		}
+
+		resp, err := c.sqs.DeleteMessageBatch(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("cannot delete message batch from the queue: %w", err)
+		}
+
+		var failed map[int]*BatchEntryError
+
+		if resp != nil {
+			failed = batchFailures(resp.Failed)
+		}
+
+		for _, i := range chunk {
+			if failed[i] != nil {
+				continue
+			}
+
+			err = c.deletePayload(ctx, keys[i])
+			if err != nil {
+				if failed == nil {
+					failed = make(map[int]*BatchEntryError)
+				}
+
+				failed[i] = &BatchEntryError{Index: i, Err: err}
+			}
+		}
+
+		return failed, nil
+	})
+}
+
+// runBatches calls send for each chunk of the entry input indexes idx, and
+// collects the failures. send returns the failed entries by input index, or
+// an error failing the whole chunk.
+func runBatches(idx []int, size func(i int) int, send func(chunk []int) (map[int]*BatchEntryError, error)) error {
+	var failed []*BatchEntryError
+
+	for _, chunk := range batchChunks(idx, size) {
+		entries, err := send(chunk)
+
+		for _, i := range chunk {
+			switch {
+			case err != nil:
+				failed = append(failed, &BatchEntryError{Index: i, Err: err})
+			case entries[i] != nil:
+				failed = append(failed, entries[i])
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Entries: failed}
+}
+
+// batchChunks splits the entry input indexes idx in chunks of up to
+// [MaxBatchSize] entries and, when size is not nil, of up to
+// [MaxBatchPayloadSize] bytes, except for a single larger entry.
+func batchChunks(idx []int, size func(i int) int) [][]int {
+	var (
+		chunks [][]int
+		start  int
+		total  int
+	)
+
+	for n, i := range idx {
+		s := 0
+		if size != nil {
+			s = size(i)
+		}
+
+		if n > start && (n-start == MaxBatchSize || total+s > MaxBatchPayloadSize) {
+			chunks = append(chunks, idx[start:n])
+			start, total = n, 0
+		}
+
+		total += s
+	}
+
+	if start < len(idx) {
+		chunks = append(chunks, idx[start:])
+	}
+
+	return chunks
+}
+
+// batchFailures converts the failed entries of a batch response, whose IDs
+// are the input indexes.
+func batchFailures(failed []types.BatchResultErrorEntry) map[int]*BatchEntryError {
+	if len(failed) == 0 {
+		return nil
+	}
+
+	entries := make(map[int]*BatchEntryError, len(failed))
+
+	for _, f := range failed {
+		i, err := strconv.Atoi(aws.ToString(f.Id))
+		if err != nil {
+			continue
+		}
+
+		code := aws.ToString(f.Code)
+
+		entries[i] = &BatchEntryError{
+			Index:       i,
+			Code:        code,
+			SenderFault: f.SenderFault,
+			Err:         fmt.Errorf("%w: %s: %s", ErrBatchEntryFailed, code, aws.ToString(f.Message)),
+		}
+	}
+
+	return entries
+}
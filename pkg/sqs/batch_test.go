@@ -0,0 +1,246 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendBatch(t *testing.T) {
+	t.Parallel()
+
+	entries := make([]SendEntry, 12)
+	for i := range entries {
+		entries[i] = SendEntry{Body: "msg" + strconv.Itoa(i), DeduplicationID: "dedup" + strconv.Itoa(i)}
+	}
+
+	var inputs []*sqs.SendMessageBatchInput
+
+	mock := sqsmock{sendBatchFn: func(_ context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+		inputs = append(inputs, params)
+
+		if len(inputs) == 2 {
+			return nil, errors.New("request error")
+		}
+
+		return &sqs.SendMessageBatchOutput{
+			Failed: []types.BatchResultErrorEntry{
+				{Id: aws.String("3"), Code: aws.String("InvalidMessageContents"), Message: aws.String("bad"), SenderFault: true},
+				{Id: aws.String("invalid")},
+			},
+		}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1.fifo", "GROUP", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	err = cli.SendBatch(t.Context(), entries)
+
+	var berr *BatchError
+
+	require.ErrorAs(t, err, &berr)
+	require.Equal(t, []int{3, 10, 11}, berr.Indexes())
+	require.ErrorIs(t, err, ErrBatchEntryFailed)
+	require.Equal(t, "InvalidMessageContents", berr.Entries[0].Code)
+	require.True(t, berr.Entries[0].SenderFault)
+	require.Empty(t, berr.Entries[1].Code)
+	require.Contains(t, err.Error(), "sqs: 3 batch entries failed: entry 3: ")
+
+	require.Len(t, inputs, 2)
+	require.Len(t, inputs[0].Entries, MaxBatchSize)
+	require.Len(t, inputs[1].Entries, 2)
+	require.Equal(t, "11", aws.ToString(inputs[1].Entries[1].Id))
+	require.Equal(t, "msg11", aws.ToString(inputs[1].Entries[1].MessageBody))
+	require.Equal(t, "dedup11", aws.ToString(inputs[1].Entries[1].MessageDeduplicationId))
+	require.Equal(t, "GROUP", aws.ToString(inputs[1].Entries[1].MessageGroupId))
+}
+
+func TestSendBatch_validation(t *testing.T) {
+	t.Parallel()
+
+	mock := sqsmock{sendBatchFn: func(_ context.Context, _ *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+		return nil, nil //nolint:nilnil
+	}}
+
+	std, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	fifo, err := New(t.Context(), "https://test_queue.invalid/queue1.fifo", "GROUP", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	require.NoError(t, std.SendBatch(t.Context(), nil))
+	require.NoError(t, std.SendBatch(t.Context(), []SendEntry{{Body: "a"}}))
+	require.NoError(t, fifo.SendBatch(t.Context(), []SendEntry{{Body: "a"}, {Body: "b", DeduplicationID: "d"}}))
+	require.ErrorIs(t, std.SendBatch(t.Context(), []SendEntry{{Body: "a", DeduplicationID: "d"}}), ErrDedupIDNotAllowed)
+	require.ErrorIs(t, fifo.SendBatch(t.Context(), []SendEntry{{Body: "a", DeduplicationID: "a b"}}), ErrInvalidDedupID)
+}
+
+func TestSendBatch_payloadSize(t *testing.T) {
+	t.Parallel()
+
+	entries := []SendEntry{
+		{Body: strings.Repeat("a", 100000)},
+		{Body: strings.Repeat("b", 100000)},
+		{Body: strings.Repeat("c", 100000)},
+		{Body: strings.Repeat("d", MaxBatchPayloadSize)},
+		{Body: "e"},
+		{Body: "f"},
+	}
+
+	var sizes [][]int
+
+	mock := sqsmock{sendBatchFn: func(_ context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+		var batch []int
+
+		for _, e := range params.Entries {
+			batch = append(batch, len(aws.ToString(e.MessageBody)))
+		}
+
+		sizes = append(sizes, batch)
+
+		return &sqs.SendMessageBatchOutput{}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	require.NoError(t, cli.SendBatch(t.Context(), entries))
+	require.Equal(t, [][]int{{100000, 100000}, {100000}, {MaxBatchPayloadSize}, {1, 1}}, sizes)
+}
+
+func TestReceiveBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		max     int
+		mock    SQS
+		want    []*Message
+		wantErr error
+	}{
+		{
+			name: "success",
+			max:  10,
+			mock: sqsmock{receiveFn: func(_ context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				if params.MaxNumberOfMessages != 10 {
+					return nil, errors.New("unexpected max number of messages")
+				}
+
+				return &sqs.ReceiveMessageOutput{Messages: []types.Message{
+					{Body: aws.String("a"), ReceiptHandle: aws.String("ra")},
+					{Body: aws.String("b"), ReceiptHandle: aws.String("rb")},
+				}}, nil
+			}},
+			want: []*Message{{Body: "a", ReceiptHandle: "ra"}, {Body: "b", ReceiptHandle: "rb"}},
+		},
+		{
+			name: "nil output",
+			max:  1,
+			mock: sqsmock{receiveFn: func(_ context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				return nil, nil //nolint:nilnil
+			}},
+		},
+		{
+			name: "error",
+			max:  1,
+			mock: sqsmock{receiveFn: func(_ context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				return nil, errors.New("receive error")
+			}},
+			wantErr: errors.New("cannot retrieve messages from the queue: receive error"),
+		},
+		{
+			name:    "too small",
+			max:     0,
+			mock:    sqsmock{},
+			wantErr: ErrInvalidBatchSize,
+		},
+		{
+			name:    "too large",
+			max:     11,
+			mock:    sqsmock{},
+			wantErr: ErrInvalidBatchSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(tt.mock))
+			require.NoError(t, err)
+
+			got, err := cli.ReceiveBatch(t.Context(), tt.max)
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeleteBatch(t *testing.T) {
+	t.Parallel()
+
+	var input *sqs.DeleteMessageBatchInput
+
+	mock := sqsmock{deleteBatchFn: func(_ context.Context, params *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+		input = params
+
+		return &sqs.DeleteMessageBatchOutput{
+			Failed: []types.BatchResultErrorEntry{
+				{Id: aws.String("2"), Code: aws.String("ReceiptHandleIsInvalid"), Message: aws.String("invalid")},
+			},
+		}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	require.NoError(t, cli.DeleteBatch(t.Context(), []string{"", ""}))
+	require.Nil(t, input)
+
+	err = cli.DeleteBatch(t.Context(), []string{"r0", "", "r2"})
+
+	var berr *BatchError
+
+	require.ErrorAs(t, err, &berr)
+	require.Equal(t, []int{2}, berr.Indexes())
+	require.Equal(t, "ReceiptHandleIsInvalid", berr.Entries[0].Code)
+
+	require.Equal(t, []types.DeleteMessageBatchRequestEntry{
+		{Id: aws.String("0"), ReceiptHandle: aws.String("r0")},
+		{Id: aws.String("2"), ReceiptHandle: aws.String("r2")},
+	}, input.Entries)
+
+	errReq := errors.New("request error")
+
+	mock.deleteBatchFn = func(_ context.Context, _ *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+		return nil, errReq
+	}
+
+	cli, err = New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	err = cli.DeleteBatch(t.Context(), []string{"r0"})
+	require.ErrorIs(t, err, errReq)
+	require.ErrorAs(t, err, &berr)
+	require.Equal(t, []int{0}, berr.Indexes())
+
+	mock.deleteBatchFn = func(_ context.Context, _ *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+		return nil, nil //nolint:nilnil
+	}
+
+	cli, err = New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+	require.NoError(t, cli.DeleteBatch(t.Context(), []string{"r0"}))
+}
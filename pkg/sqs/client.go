@@ -11,6 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/tecnickcom/nurago/pkg/encode"
+	"github.com/tecnickcom/nurago/pkg/random"
+	"github.com/tecnickcom/nurago/pkg/s3"
 )
 
 // Internal constants used by SQS queue/metadata validation.
@@ -24,6 +26,9 @@ const (
 // character set and length limits.
 var regexFifoID = regexp.MustCompile(regexPatternMessageGroupID)
 
+// allAttributes requests all the message attributes on receive.
+var allAttributes = []string{"All"}
+
 // TEncodeFunc is the type of function used to replace the default message encoding function used by SendData().
 type TEncodeFunc func(ctx context.Context, data any) (string, error)
 
@@ -32,10 +37,13 @@ type TDecodeFunc func(ctx context.Context, msg string, data any) error
 
 // SQS defines the AWS SDK SQS calls used by [Client].
 type SQS interface {
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
 	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
 }
 
 // Client wraps AWS SQS operations and typed message encoding/decoding for a single queue URL.
@@ -71,6 +79,18 @@ type Client struct {
 
 	// hcGetQueueAttributesInput is the input parameter for the GetQueueAttributes function used by the HealthCheck.
 	hcGetQueueAttributesInput *sqs.GetQueueAttributesInput
+
+	// traceIDAttribute is the name of the message attribute carrying the trace ID, empty when disabled.
+	traceIDAttribute string
+
+	// payloadStore is the S3 client storing the offloaded message bodies, nil when disabled.
+	payloadStore *s3.Client
+
+	// payloadThreshold is the message size in bytes above which the body is offloaded.
+	payloadThreshold int
+
+	// rnd generates the keys of the offloaded message bodies.
+	rnd *random.Rnd
 }
 
 // New builds a client for queueURL and validates FIFO message-group constraints.
@@ -84,7 +104,8 @@ type Client struct {
 // [ErrMissingMessageGroupID] when a FIFO queue is given without a valid group
 // ID, and [ErrUnexpectedMessageGroupID] when a group ID is set for a standard
 // queue. Invalid options surface [ErrNilEncodeFunc], [ErrNilDecodeFunc],
-// [ErrInvalidWaitTime], or [ErrInvalidVisibilityTimeout].
+// [ErrInvalidWaitTime], [ErrInvalidVisibilityTimeout], [ErrInvalidAttributes],
+// [ErrNilPayloadStore], or [ErrInvalidPayloadThreshold].
 //
 // The returned Client is safe for concurrent use.
 func New(ctx context.Context, queueURL, msgGroupID string, opts ...Option) (*Client, error) {
@@ -127,6 +148,10 @@ func New(ctx context.Context, queueURL, msgGroupID string, opts ...Option) (*Cli
 			QueueUrl:       aws.String(queueURL),
 			AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameLastModifiedTimestamp},
 		},
+		traceIDAttribute: cfg.traceIDAttribute,
+		payloadStore:     cfg.payloadStore,
+		payloadThreshold: cfg.payloadThreshold,
+		rnd:              random.New(nil),
 	}, nil
 }
 
@@ -151,6 +176,13 @@ type Message struct {
 
 	// ReceiptHandle is the identifier used to delete the message.
 	ReceiptHandle string
+
+	// Attributes are the String and Number message attributes, if any.
+	Attributes map[string]string
+
+	// TraceID is the valid trace ID carried by the message, if any.
+	// See WithTraceIDAttribute.
+	TraceID string
 }
 
 // Send publishes a raw string message to the queue.
@@ -160,7 +192,17 @@ type Message struct {
 // the request. Use SendWithDeduplicationID to supply an explicit
 // deduplication ID instead.
 func (c *Client) Send(ctx context.Context, message string) error {
-	return c.send(ctx, message, nil)
+	return c.send(ctx, message, nil, nil)
+}
+
+// SendWithAttributes publishes a raw string message to the queue with the
+// given String message attributes, in addition to the trace ID one.
+//
+// It returns [ErrInvalidAttributes] when there are more than 10 attributes,
+// or a name is invalid or reserved ("AWS." and "Amazon." prefixes), or a value
+// is empty. See Send for the FIFO-queue deduplication constraints.
+func (c *Client) SendWithAttributes(ctx context.Context, message string, attrs map[string]string) error {
+	return c.send(ctx, message, nil, attrs)
 }
 
 // SendWithDeduplicationID publishes a raw string message to the queue with an
@@ -183,19 +225,21 @@ func (c *Client) SendWithDeduplicationID(ctx context.Context, message, dedupID s
 		return ErrInvalidDedupID
 	}
 
-	return c.send(ctx, message, aws.String(dedupID))
+	return c.send(ctx, message, aws.String(dedupID), nil)
 }
 
 // Receive retrieves one raw message from the queue with configured wait/visibility settings.
 // Returns nil message when no message is available within waitTimeSeconds.
+// An offloaded body (see WithPayloadOffload) is fetched from S3.
 func (c *Client) Receive(ctx context.Context) (*Message, error) {
 	resp, err := c.sqs.ReceiveMessage(
 		ctx,
 		&sqs.ReceiveMessageInput{
-			QueueUrl:            c.queueURL,
-			WaitTimeSeconds:     c.waitTimeSeconds,
-			VisibilityTimeout:   c.visibilityTimeout,
-			MaxNumberOfMessages: 1,
+			QueueUrl:              c.queueURL,
+			WaitTimeSeconds:       c.waitTimeSeconds,
+			VisibilityTimeout:     c.visibilityTimeout,
+			MaxNumberOfMessages:   1,
+			MessageAttributeNames: allAttributes,
 		})
 	if err != nil {
 		return nil, fmt.Errorf("cannot retrieve message from the queue: %w", err)
@@ -207,29 +251,29 @@ func (c *Client) Receive(ctx context.Context) (*Message, error) {
 		return nil, nil //nolint:nilnil
 	}
 
-	return &Message{
-		Body:          aws.ToString(resp.Messages[0].Body),
-		ReceiptHandle: aws.ToString(resp.Messages[0].ReceiptHandle),
-	}, nil
+	return c.message(ctx, resp.Messages[0])
 }
 
-// Delete removes a message from the queue by receipt handle.
+// Delete removes a message from the queue by receipt handle, and then its
+// offloaded body, if any.
 func (c *Client) Delete(ctx context.Context, receiptHandle string) error {
 	if receiptHandle == "" {
 		return nil
 	}
 
+	handle, key := splitReceiptHandle(receiptHandle)
+
 	_, err := c.sqs.DeleteMessage(
 		ctx,
 		&sqs.DeleteMessageInput{
 			QueueUrl:      c.queueURL,
-			ReceiptHandle: aws.String(receiptHandle),
+			ReceiptHandle: aws.String(handle),
 		})
 	if err != nil {
 		return fmt.Errorf("cannot delete message from the queue: %w", err)
 	}
 
-	return nil
+	return c.deletePayload(ctx, key)
 }
 
 // MessageEncode encodes and serializes data into an SQS-compatible string payload.
@@ -320,17 +364,32 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 	return fmt.Errorf("%w: %s", ErrQueueNotResponding, aws.ToString(c.queueURL))
 }
 
-// send publishes a raw string message to the queue with an optional message deduplication ID.
-func (c *Client) send(ctx context.Context, message string, dedupID *string) error {
-	_, err := c.sqs.SendMessage(
+// send publishes a raw string message to the queue with an optional message
+// deduplication ID and attributes, offloading the body if too large.
+func (c *Client) send(ctx context.Context, message string, dedupID *string, attrs map[string]string) error {
+	msgAttrs, err := c.messageAttributes(ctx, attrs)
+	if err != nil {
+		return err
+	}
+
+	message, msgAttrs, key, err := c.offload(ctx, message, msgAttrs)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sqs.SendMessage(
 		ctx,
 		&sqs.SendMessageInput{
 			QueueUrl:               c.queueURL,
 			MessageGroupId:         c.messageGroupID,
 			MessageDeduplicationId: dedupID,
 			MessageBody:            aws.String(message),
+			MessageAttributes:      msgAttrs,
 		})
 	if err != nil {
+		// best effort: the message was not sent, so the payload is orphaned
+		_ = c.deletePayload(context.WithoutCancel(ctx), key)
+
 		return fmt.Errorf("cannot send message to the queue: %w", err)
 	}
 
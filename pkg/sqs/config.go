@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
+	"github.com/tecnickcom/nurago/pkg/s3"
 )
 
 const (
@@ -33,6 +34,10 @@ type cfg struct {
 	visibilityTimeout int32
 	messageEncodeFunc TEncodeFunc
 	messageDecodeFunc TDecodeFunc
+	traceIDAttribute  string
+	payloadOffload    bool
+	payloadStore      *s3.Client
+	payloadThreshold  int
 }
 
 // loadConfig applies options, validates boundaries/codecs, and resolves aws.Config.
@@ -48,6 +53,7 @@ func loadConfig(ctx context.Context, queueURL string, opts ...Option) (*cfg, err
 		visibilityTimeout: DefaultVisibilityTimeout,
 		messageEncodeFunc: DefaultMessageEncodeFunc,
 		messageDecodeFunc: DefaultMessageDecodeFunc,
+		traceIDAttribute:  DefaultTraceIDAttribute,
 	}
 
 	for _, apply := range opts {
@@ -70,6 +76,18 @@ func loadConfig(ctx context.Context, queueURL string, opts ...Option) (*cfg, err
 		return nil, ErrInvalidVisibilityTimeout
 	}
 
+	if c.traceIDAttribute != "" && !validAttributeName(c.traceIDAttribute) {
+		return nil, ErrInvalidAttributes
+	}
+
+	if c.payloadOffload && c.payloadStore == nil {
+		return nil, ErrNilPayloadStore
+	}
+
+	if c.payloadThreshold < 0 {
+		return nil, ErrInvalidPayloadThreshold
+	}
+
 	if c.sqsClient != nil {
 		// The injected client replaces the SDK client, so awsConfig and
 		// srvOptFns are never consumed: skip the (potentially slow or failing)
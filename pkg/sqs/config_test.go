@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
+	"github.com/tecnickcom/nurago/pkg/s3"
 )
 
 func Test_loadConfig(t *testing.T) {
@@ -112,6 +113,33 @@ func Test_loadConfig(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, got)
 
+	got, err = loadConfig(
+		t.Context(),
+		"https://test_queue.invalid/queue0.fifo",
+		WithTraceIDAttribute("AWS.trace"),
+	)
+
+	require.ErrorIs(t, err, ErrInvalidAttributes)
+	require.Nil(t, got)
+
+	got, err = loadConfig(
+		t.Context(),
+		"https://test_queue.invalid/queue0.fifo",
+		WithPayloadOffload(nil, DefaultPayloadOffloadThreshold),
+	)
+
+	require.ErrorIs(t, err, ErrNilPayloadStore)
+	require.Nil(t, got)
+
+	got, err = loadConfig(
+		t.Context(),
+		"https://test_queue.invalid/queue0.fifo",
+		WithPayloadOffload(&s3.Client{}, -1),
+	)
+
+	require.ErrorIs(t, err, ErrInvalidPayloadThreshold)
+	require.Nil(t, got)
+
 	// force aws config.LoadDefaultConfig to fail
 	t.Setenv("AWS_ENABLE_ENDPOINT_DISCOVERY", "ERROR")
 
@@ -41,4 +41,39 @@ var (
 	// ErrQueueNotResponding is returned by HealthCheck when the queue does not
 	// return the expected attribute.
 	ErrQueueNotResponding = errors.New("sqs: the queue is not responding")
+
+	// ErrInvalidBatchSize is returned by ReceiveBatch when the maximum number
+	// of messages is outside the valid 1..10 range.
+	ErrInvalidBatchSize = errors.New("sqs: the batch size must be between 1 and 10")
+
+	// ErrBatchEntryFailed is matched by the BatchEntryError of an entry
+	// rejected by SQS in a batch request.
+	ErrBatchEntryFailed = errors.New("sqs: batch entry failed")
+
+	// ErrNilClient is returned by NewWorker when the client is nil.
+	ErrNilClient = errors.New("sqs: nil client")
+
+	// ErrNilHandler is returned by NewWorker when the handler is nil.
+	ErrNilHandler = errors.New("sqs: nil handler")
+
+	// ErrInvalidConcurrency is returned by NewWorker when the concurrency is
+	// not positive.
+	ErrInvalidConcurrency = errors.New("sqs: the concurrency must be positive")
+
+	// ErrInvalidAttributes is returned when the message attributes are more than
+	// 10 or have an invalid or reserved name or an empty value, and by New for
+	// an invalid WithTraceIDAttribute name.
+	ErrInvalidAttributes = errors.New("sqs: invalid message attributes")
+
+	// ErrNilPayloadStore is returned by New when WithPayloadOffload is given a
+	// nil S3 client.
+	ErrNilPayloadStore = errors.New("sqs: nil payload offload store")
+
+	// ErrInvalidPayloadThreshold is returned by New when the WithPayloadOffload
+	// threshold is negative.
+	ErrInvalidPayloadThreshold = errors.New("sqs: the payload offload threshold must not be negative")
+
+	// ErrInvalidPayloadPointer is returned when receiving an offloaded message
+	// whose S3 pointer is malformed or refers to another bucket.
+	ErrInvalidPayloadPointer = errors.New("sqs: invalid offloaded payload pointer")
 )
@@ -48,6 +48,30 @@ func (c *exampleSQSClient) DeleteMessage(
 	return &awssqs.DeleteMessageOutput{}, nil
 }
 
+func (c *exampleSQSClient) ChangeMessageVisibility(
+	_ context.Context,
+	_ *awssqs.ChangeMessageVisibilityInput,
+	_ ...func(*awssqs.Options),
+) (*awssqs.ChangeMessageVisibilityOutput, error) {
+	return &awssqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func (c *exampleSQSClient) DeleteMessageBatch(
+	_ context.Context,
+	_ *awssqs.DeleteMessageBatchInput,
+	_ ...func(*awssqs.Options),
+) (*awssqs.DeleteMessageBatchOutput, error) {
+	return &awssqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (c *exampleSQSClient) SendMessageBatch(
+	_ context.Context,
+	_ *awssqs.SendMessageBatchInput,
+	_ ...func(*awssqs.Options),
+) (*awssqs.SendMessageBatchOutput, error) {
+	return &awssqs.SendMessageBatchOutput{}, nil
+}
+
 func (c *exampleSQSClient) GetQueueAttributes(
 	_ context.Context,
 	_ *awssqs.GetQueueAttributesInput,
@@ -3,11 +3,13 @@ package sqs
 import (
 	"context"
 	"net/url"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	sep "github.com/aws/smithy-go/endpoints"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
+	"github.com/tecnickcom/nurago/pkg/s3"
 )
 
 // SrvOptionFunc aliases an AWS SDK SQS service option mutator.
@@ -110,3 +112,76 @@ func WithMessageDecodeFunc(f TDecodeFunc) Option {
 		c.messageDecodeFunc = f
 	}
 }
+
+// WithTraceIDAttribute sets the name of the message attribute carrying the
+// trace ID (default [DefaultTraceIDAttribute]). The trace ID of the context
+// (see github.com/tecnickcom/nurago/pkg/traceid) is added to the sent messages
+// and exposed as [Message.TraceID] on receive. An empty name disables the
+// propagation.
+func WithTraceIDAttribute(name string) Option {
+	return func(c *cfg) {
+		c.traceIDAttribute = name
+	}
+}
+
+// WithPayloadOffload enables the extended-client mode: the bodies of the
+// messages larger than threshold bytes (body plus attributes, see
+// [DefaultPayloadOffloadThreshold]) are stored in the store bucket, and a
+// pointer to the object is sent instead. The received pointers are resolved
+// transparently, and the objects are deleted with the messages.
+//
+// The message format is compatible with the AWS SQS extended client
+// libraries. A 0 threshold offloads every message.
+func WithPayloadOffload(store *s3.Client, threshold int) Option {
+	return func(c *cfg) {
+		c.payloadOffload = true
+		c.payloadStore = store
+		c.payloadThreshold = threshold
+	}
+}
+
+// WorkerOption configures a [Worker].
+type WorkerOption func(*Worker)
+
+// WithConcurrency sets the maximum number of messages processed in parallel
+// by the worker (default [DefaultWorkerConcurrency]). It must be positive.
+func WithConcurrency(n int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithRetryDelay makes the worker set the visibility timeout of a failed
+// message to delay seconds (0 to 43200), so it is received again after the
+// delay instead of after the full visibility timeout; 0 retries it at once.
+func WithRetryDelay(delay int32) WorkerOption {
+	return func(w *Worker) {
+		w.retryDelay = delay
+	}
+}
+
+// WithErrorHandler sets the function receiving the worker failures: receive,
+// handler, heartbeat, visibility and delete errors. It is called from several
+// goroutines, so it must be safe for concurrent use. A nil handler discards
+// the errors (the default).
+func WithErrorHandler(fn func(error)) WorkerOption {
+	return func(w *Worker) {
+		w.errorHandler = fn
+	}
+}
+
+// WithShutdownWaitGroup sets the shared wait group tracking the worker
+// started with [Worker.Start] until it is drained.
+func WithShutdownWaitGroup(wg *sync.WaitGroup) WorkerOption {
+	return func(w *Worker) {
+		w.shutdownWaitGroup = wg
+	}
+}
+
+// WithShutdownSignalChan sets the shared channel whose closure stops and
+// drains the worker.
+func WithShutdownSignalChan(ch chan struct{}) WorkerOption {
+	return func(w *Worker) {
+		w.shutdownSignalChan = ch
+	}
+}
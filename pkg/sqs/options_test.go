@@ -2,13 +2,16 @@ package sqs
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	awssrv "github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/stretchr/testify/require"
 	"github.com/tecnickcom/nurago/pkg/awsopt"
+	"github.com/tecnickcom/nurago/pkg/s3"
 )
 
 func Test_WithAWSOptions(t *testing.T) {
@@ -151,3 +154,69 @@ func Test_WithSQSClient(t *testing.T) {
 	WithSQSClient(sqsmock{})(conf)
 	require.NotNil(t, conf.sqsClient)
 }
+
+func Test_WithTraceIDAttribute(t *testing.T) {
+	t.Parallel()
+
+	conf := &cfg{}
+	WithTraceIDAttribute("traceid")(conf)
+	require.Equal(t, "traceid", conf.traceIDAttribute)
+}
+
+func Test_WithPayloadOffload(t *testing.T) {
+	t.Parallel()
+
+	store := &s3.Client{}
+	conf := &cfg{}
+	WithPayloadOffload(store, 100)(conf)
+	require.True(t, conf.payloadOffload)
+	require.Same(t, store, conf.payloadStore)
+	require.Equal(t, 100, conf.payloadThreshold)
+}
+
+func Test_WithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	w := &Worker{}
+	WithConcurrency(3)(w)
+	require.Equal(t, 3, w.concurrency)
+}
+
+func Test_WithRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	w := &Worker{}
+	WithRetryDelay(5)(w)
+	require.Equal(t, int32(5), w.retryDelay)
+}
+
+func Test_WithErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	var got error
+
+	w := &Worker{}
+	WithErrorHandler(func(err error) { got = err })(w)
+
+	errTest := errors.New("test")
+	w.errorHandler(errTest)
+	require.Equal(t, errTest, got)
+}
+
+func Test_WithShutdownWaitGroup(t *testing.T) {
+	t.Parallel()
+
+	wg := &sync.WaitGroup{}
+	w := &Worker{}
+	WithShutdownWaitGroup(wg)(w)
+	require.Equal(t, wg, w.shutdownWaitGroup)
+}
+
+func Test_WithShutdownSignalChan(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan struct{})
+	w := &Worker{}
+	WithShutdownSignalChan(ch)(w)
+	require.Equal(t, ch, w.shutdownSignalChan)
+}
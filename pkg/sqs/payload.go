@@ -0,0 +1,203 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+const (
+	// DefaultPayloadOffloadThreshold is the message size in bytes (256 KiB)
+	// above which [WithPayloadOffload] is usually configured to store the body
+	// in S3: the default maximum message size of an SQS queue.
+	DefaultPayloadOffloadThreshold = 262144
+
+	// payloadSizeAttribute is the attribute carrying the size of an offloaded
+	// body, as set by the AWS SQS extended client libraries.
+	payloadSizeAttribute = "ExtendedPayloadSize"
+
+	// legacyPayloadSizeAttribute is the attribute used by the older versions
+	// of the AWS SQS extended client libraries.
+	legacyPayloadSizeAttribute = "SQSLargePayloadSize"
+
+	// payloadPointerClass is the class name heading the JSON pointer to an
+	// offloaded body.
+	payloadPointerClass = "software.amazon.payloadoffloading.PayloadS3Pointer"
+
+	// Markers embedding the S3 location of an offloaded body in a receipt
+	// handle, so it can be deleted with the message.
+	handleBucketMarker = "-..s3BucketName..-"
+	handleKeyMarker    = "-..s3Key..-"
+)
+
+// payloadPointer is the S3 location of an offloaded message body.
+type payloadPointer struct {
+	Bucket string `json:"s3BucketName"`
+	Key    string `json:"s3Key"`
+}
+
+// offload stores body in S3 when the message exceeds the offload threshold,
+// and returns the message to send instead: a pointer to the S3 object, with an
+// attribute carrying the original size. key is empty when the body is not
+// offloaded.
+func (c *Client) offload(
+	ctx context.Context,
+	body string,
+	attrs map[string]types.MessageAttributeValue,
+) (string, map[string]types.MessageAttributeValue, string, error) {
+	if c.payloadStore == nil || messageSize(body, attrs) <= c.payloadThreshold {
+		return body, attrs, "", nil
+	}
+
+	if len(attrs) >= MaxMessageAttributes {
+		return "", nil, "", ErrInvalidAttributes
+	}
+
+	key := c.rnd.UUIDv7().String()
+
+	pointer, err := json.Marshal([]any{payloadPointerClass, payloadPointer{Bucket: c.payloadStore.Bucket(), Key: key}})
+	if err != nil {
+		return "", nil, "", fmt.Errorf("cannot encode the payload pointer: %w", err)
+	}
+
+	err = c.payloadStore.Put(ctx, key, strings.NewReader(body))
+	if err != nil {
+		return "", nil, "", fmt.Errorf("cannot offload the message payload: %w", err)
+	}
+
+	if attrs == nil {
+		attrs = make(map[string]types.MessageAttributeValue, 1)
+	}
+
+	attrs[payloadSizeAttribute] = types.MessageAttributeValue{
+		DataType:    aws.String(dataTypeNumber),
+		StringValue: aws.String(strconv.Itoa(len(body))),
+	}
+
+	return string(pointer), attrs, key, nil
+}
+
+// message converts a received message. An offloaded body is fetched from S3,
+// and its location is embedded in the receipt handle for the deletion.
+func (c *Client) message(ctx context.Context, m types.Message) (*Message, error) {
+	msg := &Message{
+		Body:          aws.ToString(m.Body),
+		ReceiptHandle: aws.ToString(m.ReceiptHandle),
+		Attributes:    receivedAttributes(m.MessageAttributes),
+	}
+
+	if id := msg.Attributes[c.traceIDAttribute]; traceid.Valid(id) {
+		msg.TraceID = id
+	}
+
+	if c.payloadStore == nil || !isOffloaded(msg.Attributes) {
+		return msg, nil
+	}
+
+	delete(msg.Attributes, payloadSizeAttribute)
+	delete(msg.Attributes, legacyPayloadSizeAttribute)
+
+	if len(msg.Attributes) == 0 {
+		msg.Attributes = nil
+	}
+
+	ptr, err := parsePayloadPointer(msg.Body)
+	if err != nil || ptr.Bucket != c.payloadStore.Bucket() || ptr.Key == "" {
+		return nil, ErrInvalidPayloadPointer
+	}
+
+	obj, err := c.payloadStore.Get(ctx, ptr.Key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch the offloaded message payload: %w", err)
+	}
+
+	defer func() { _ = obj.Close() }()
+
+	body, err := io.ReadAll(obj.Body())
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the offloaded message payload: %w", err)
+	}
+
+	msg.Body = string(body)
+	msg.ReceiptHandle = handleBucketMarker + ptr.Bucket + handleBucketMarker +
+		handleKeyMarker + ptr.Key + handleKeyMarker + msg.ReceiptHandle
+
+	return msg, nil
+}
+
+// deletePayload deletes an offloaded body; an empty key is a no-op.
+func (c *Client) deletePayload(ctx context.Context, key string) error {
+	if key == "" || c.payloadStore == nil {
+		return nil
+	}
+
+	err := c.payloadStore.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("cannot delete the offloaded message payload: %w", err)
+	}
+
+	return nil
+}
+
+// isOffloaded reports whether the received attributes mark an offloaded body.
+func isOffloaded(attrs map[string]string) bool {
+	_, ok := attrs[payloadSizeAttribute]
+	if !ok {
+		_, ok = attrs[legacyPayloadSizeAttribute]
+	}
+
+	return ok
+}
+
+// parsePayloadPointer decodes the JSON pointer sent in place of an offloaded
+// body.
+func parsePayloadPointer(body string) (*payloadPointer, error) {
+	var parts []json.RawMessage
+
+	err := json.Unmarshal([]byte(body), &parts)
+	if err != nil || len(parts) != 2 || string(parts[0]) != strconv.Quote(payloadPointerClass) {
+		return nil, ErrInvalidPayloadPointer
+	}
+
+	var ptr payloadPointer
+
+	err = json.Unmarshal(parts[1], &ptr)
+	if err != nil {
+		return nil, ErrInvalidPayloadPointer
+	}
+
+	return &ptr, nil
+}
+
+// splitReceiptHandle separates the SQS receipt handle from the key of the
+// offloaded body embedded by [Client.message], if any.
+func splitReceiptHandle(h string) (string, string) {
+	rest, ok := strings.CutPrefix(h, handleBucketMarker)
+	if !ok {
+		return h, ""
+	}
+
+	_, rest, ok = strings.Cut(rest, handleBucketMarker)
+	if !ok {
+		return h, ""
+	}
+
+	rest, ok = strings.CutPrefix(rest, handleKeyMarker)
+	if !ok {
+		return h, ""
+	}
+
+	key, handle, ok := strings.Cut(rest, handleKeyMarker)
+	if !ok {
+		return h, ""
+	}
+
+	return handle, key
+}
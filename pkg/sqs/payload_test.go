@@ -0,0 +1,376 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/s3"
+)
+
+// s3store is an in-memory S3 bucket implementing s3.S3.
+type s3store struct {
+	mu      sync.Mutex
+	objects map[string]string
+	err     error
+}
+
+func (s *s3store) AbortMultipartUpload(_ context.Context, _ *awss3.AbortMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.AbortMultipartUploadOutput, error) {
+	return &awss3.AbortMultipartUploadOutput{}, nil
+}
+
+func (s *s3store) CompleteMultipartUpload(_ context.Context, _ *awss3.CompleteMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CompleteMultipartUploadOutput, error) {
+	return &awss3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (s *s3store) CopyObject(_ context.Context, _ *awss3.CopyObjectInput, _ ...func(*awss3.Options)) (*awss3.CopyObjectOutput, error) {
+	return &awss3.CopyObjectOutput{}, nil
+}
+
+func (s *s3store) CreateMultipartUpload(_ context.Context, _ *awss3.CreateMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CreateMultipartUploadOutput, error) {
+	return &awss3.CreateMultipartUploadOutput{}, nil
+}
+
+func (s *s3store) DeleteObject(_ context.Context, params *awss3.DeleteObjectInput, _ ...func(*awss3.Options)) (*awss3.DeleteObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	delete(s.objects, aws.ToString(params.Key))
+
+	return &awss3.DeleteObjectOutput{}, nil
+}
+
+func (s *s3store) DeleteObjects(_ context.Context, _ *awss3.DeleteObjectsInput, _ ...func(*awss3.Options)) (*awss3.DeleteObjectsOutput, error) {
+	return &awss3.DeleteObjectsOutput{}, nil
+}
+
+func (s *s3store) GetObject(_ context.Context, params *awss3.GetObjectInput, _ ...func(*awss3.Options)) (*awss3.GetObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &awss3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(s.objects[aws.ToString(params.Key)]))}, nil
+}
+
+func (s *s3store) HeadBucket(_ context.Context, _ *awss3.HeadBucketInput, _ ...func(*awss3.Options)) (*awss3.HeadBucketOutput, error) {
+	return &awss3.HeadBucketOutput{}, nil
+}
+
+func (s *s3store) HeadObject(_ context.Context, _ *awss3.HeadObjectInput, _ ...func(*awss3.Options)) (*awss3.HeadObjectOutput, error) {
+	return &awss3.HeadObjectOutput{}, nil
+}
+
+func (s *s3store) ListObjectsV2(_ context.Context, _ *awss3.ListObjectsV2Input, _ ...func(*awss3.Options)) (*awss3.ListObjectsV2Output, error) {
+	return &awss3.ListObjectsV2Output{}, nil
+}
+
+func (s *s3store) PutObject(_ context.Context, params *awss3.PutObjectInput, _ ...func(*awss3.Options)) (*awss3.PutObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	s.objects[aws.ToString(params.Key)] = string(data)
+
+	return &awss3.PutObjectOutput{}, nil
+}
+
+func (s *s3store) UploadPart(_ context.Context, _ *awss3.UploadPartInput, _ ...func(*awss3.Options)) (*awss3.UploadPartOutput, error) {
+	return &awss3.UploadPartOutput{}, nil
+}
+
+// sqsqueue is an in-memory queue backing a sqsmock, storing the sent messages.
+type sqsqueue struct {
+	msgs    []types.Message
+	deleted []string
+	err     error
+}
+
+func (q *sqsqueue) mock() sqsmock {
+	return sqsmock{
+		sendFn: func(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+			if q.err != nil {
+				return nil, q.err
+			}
+
+			q.msgs = append(q.msgs, types.Message{
+				Body:              params.MessageBody,
+				MessageAttributes: params.MessageAttributes,
+				ReceiptHandle:     aws.String("rh"),
+			})
+
+			return &sqs.SendMessageOutput{}, nil
+		},
+		sendBatchFn: func(_ context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+			if q.err != nil {
+				return nil, q.err
+			}
+
+			out := &sqs.SendMessageBatchOutput{}
+
+			for _, e := range params.Entries {
+				if aws.ToString(e.Id) == "1" {
+					out.Failed = append(out.Failed, types.BatchResultErrorEntry{Id: e.Id, Code: aws.String("InternalError")})
+					continue
+				}
+
+				q.msgs = append(q.msgs, types.Message{
+					Body:              e.MessageBody,
+					MessageAttributes: e.MessageAttributes,
+					ReceiptHandle:     aws.String("rh" + aws.ToString(e.Id)),
+				})
+			}
+
+			return out, nil
+		},
+		receiveFn: func(_ context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+			return &sqs.ReceiveMessageOutput{Messages: q.msgs}, nil
+		},
+		deleteFn: func(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+			q.deleted = append(q.deleted, aws.ToString(params.ReceiptHandle))
+			return &sqs.DeleteMessageOutput{}, nil
+		},
+		deleteBatchFn: func(_ context.Context, params *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+			for _, e := range params.Entries {
+				q.deleted = append(q.deleted, aws.ToString(e.ReceiptHandle))
+			}
+
+			return &sqs.DeleteMessageBatchOutput{}, nil
+		},
+		changeVisibilityFn: func(_ context.Context, params *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+			q.deleted = append(q.deleted, "visibility:"+aws.ToString(params.ReceiptHandle))
+			return &sqs.ChangeMessageVisibilityOutput{}, nil
+		},
+	}
+}
+
+func newOffloadClient(t *testing.T, q *sqsqueue, store *s3store, threshold int) *Client {
+	t.Helper()
+
+	s3c, err := s3.New(t.Context(), "bucket", s3.WithS3Client(store))
+	require.NoError(t, err)
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(q.mock()), WithPayloadOffload(s3c, threshold))
+	require.NoError(t, err)
+
+	return cli
+}
+
+func TestClient_payloadOffload(t *testing.T) {
+	t.Parallel()
+
+	q := &sqsqueue{}
+	store := &s3store{objects: make(map[string]string)}
+	cli := newOffloadClient(t, q, store, 10)
+
+	large := strings.Repeat("x", 11)
+
+	require.NoError(t, cli.Send(t.Context(), "small"))
+	require.NoError(t, cli.SendWithAttributes(t.Context(), large, map[string]string{"a": "b"}))
+
+	require.Len(t, q.msgs, 2)
+	require.Equal(t, "small", aws.ToString(q.msgs[0].Body))
+	require.Len(t, store.objects, 1)
+
+	var key string
+	for k := range store.objects {
+		key = k
+	}
+
+	require.Equal(t, large, store.objects[key])
+	require.JSONEq(t,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"`+key+`"}]`,
+		aws.ToString(q.msgs[1].Body))
+	require.Equal(t, "11", aws.ToString(q.msgs[1].MessageAttributes[payloadSizeAttribute].StringValue))
+
+	msgs, err := cli.ReceiveBatch(t.Context(), 2)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, "rh", msgs[0].ReceiptHandle)
+	require.Equal(t, large, msgs[1].Body)
+	require.Equal(t, map[string]string{"a": "b"}, msgs[1].Attributes)
+	require.Equal(t, "-..s3BucketName..-bucket-..s3BucketName..--..s3Key..-"+key+"-..s3Key..-rh", msgs[1].ReceiptHandle)
+
+	require.NoError(t, cli.ChangeVisibility(t.Context(), msgs[1].ReceiptHandle, 0))
+	require.NoError(t, cli.Delete(t.Context(), msgs[1].ReceiptHandle))
+	require.Equal(t, []string{"visibility:rh", "rh"}, q.deleted)
+	require.Empty(t, store.objects)
+}
+
+func TestClient_payloadOffload_batch(t *testing.T) {
+	t.Parallel()
+
+	q := &sqsqueue{}
+	store := &s3store{objects: make(map[string]string)}
+	cli := newOffloadClient(t, q, store, 0)
+
+	err := cli.SendBatch(t.Context(), []SendEntry{{Body: "first"}, {Body: "second"}})
+
+	var berr *BatchError
+
+	require.ErrorAs(t, err, &berr)
+	require.Equal(t, []int{1}, berr.Indexes())
+	require.Len(t, store.objects, 1, "the payload of the failed entry is deleted")
+
+	msgs, err := cli.ReceiveBatch(t.Context(), 1)
+	require.NoError(t, err)
+	require.Equal(t, "first", msgs[0].Body)
+
+	require.NoError(t, cli.DeleteBatch(t.Context(), []string{msgs[0].ReceiptHandle}))
+	require.Equal(t, []string{"rh0"}, q.deleted)
+	require.Empty(t, store.objects)
+
+	// send failure
+	q.err = errors.New("send error")
+
+	require.Error(t, cli.Send(t.Context(), "third"))
+	require.Error(t, cli.SendBatch(t.Context(), []SendEntry{{Body: "fourth"}}))
+	require.Empty(t, store.objects)
+}
+
+func TestClient_ReceiveBatch_partial(t *testing.T) {
+	t.Parallel()
+
+	q := &sqsqueue{}
+	store := &s3store{objects: map[string]string{"key": "offloaded"}}
+	cli := newOffloadClient(t, q, store, 0)
+
+	offloaded := func(body string) types.Message {
+		return types.Message{
+			Body:          aws.String(body),
+			ReceiptHandle: aws.String("rh"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				payloadSizeAttribute: {DataType: aws.String("Number"), StringValue: aws.String("9")},
+			},
+		}
+	}
+
+	q.msgs = []types.Message{
+		{Body: aws.String("plain"), ReceiptHandle: aws.String("rh")},
+		offloaded(`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"other","s3Key":"key"}]`),
+		offloaded(`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"key"}]`),
+		offloaded("invalid"),
+	}
+
+	msgs, err := cli.ReceiveBatch(t.Context(), 4)
+
+	var berr *BatchError
+
+	require.ErrorAs(t, err, &berr)
+	require.ErrorIs(t, err, ErrInvalidPayloadPointer)
+	require.Equal(t, []int{1, 3}, berr.Indexes())
+	require.Len(t, msgs, 2)
+	require.Equal(t, "plain", msgs[0].Body)
+	require.Equal(t, "offloaded", msgs[1].Body)
+}
+
+func TestClient_payloadOffload_errors(t *testing.T) {
+	t.Parallel()
+
+	q := &sqsqueue{}
+	store := &s3store{objects: make(map[string]string)}
+	cli := newOffloadClient(t, q, store, 0)
+
+	require.NoError(t, cli.Send(t.Context(), "message"))
+
+	errStore := errors.New("store error")
+	store.err = errStore
+
+	// fetch failure
+	_, err := cli.Receive(t.Context())
+	require.ErrorIs(t, err, errStore)
+
+	_, err = cli.ReceiveBatch(t.Context(), 1)
+	require.ErrorIs(t, err, errStore)
+
+	// upload failure
+	require.ErrorIs(t, cli.Send(t.Context(), "message"), errStore)
+
+	err = cli.SendBatch(t.Context(), []SendEntry{{Body: "message"}})
+	require.ErrorIs(t, err, errStore)
+
+	// delete failure
+	const handle = "-..s3BucketName..-bucket-..s3BucketName..--..s3Key..-key-..s3Key..-rh"
+
+	require.ErrorIs(t, cli.Delete(t.Context(), handle), errStore)
+
+	err = cli.DeleteBatch(t.Context(), []string{handle})
+
+	var berr *BatchError
+
+	require.ErrorAs(t, err, &berr)
+	require.ErrorIs(t, err, errStore)
+
+	// too many attributes for the payload size attribute
+	store.err = nil
+	attrs := make(map[string]string, MaxMessageAttributes)
+
+	for _, c := range "abcdefghij" {
+		attrs[string(c)] = "v"
+	}
+
+	require.ErrorIs(t, cli.SendWithAttributes(t.Context(), "message", attrs), ErrInvalidAttributes)
+
+	// invalid pointers
+	for _, body := range []string{
+		`invalid`,
+		`["other.Class",{"s3BucketName":"bucket","s3Key":"k"}]`,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"other","s3Key":"k"}]`,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":""}]`,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",[]]`,
+	} {
+		q.msgs = []types.Message{{
+			Body: aws.String(body),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				legacyPayloadSizeAttribute: {DataType: aws.String("Number"), StringValue: aws.String("1")},
+			},
+		}}
+
+		_, err = cli.Receive(t.Context())
+		require.ErrorIs(t, err, ErrInvalidPayloadPointer, body)
+	}
+}
+
+func Test_splitReceiptHandle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		handle     string
+		wantHandle string
+		wantKey    string
+	}{
+		{"rh", "rh", ""},
+		{"-..s3BucketName..-b-..s3BucketName..--..s3Key..-k-..s3Key..-rh", "rh", "k"},
+		{"-..s3BucketName..-b", "-..s3BucketName..-b", ""},
+		{"-..s3BucketName..-b-..s3BucketName..-rh", "-..s3BucketName..-b-..s3BucketName..-rh", ""},
+		{"-..s3BucketName..-b-..s3BucketName..--..s3Key..-k", "-..s3BucketName..-b-..s3BucketName..--..s3Key..-k", ""},
+	}
+
+	for _, tt := range tests {
+		handle, key := splitReceiptHandle(tt.handle)
+		require.Equal(t, tt.wantHandle, handle, tt.handle)
+		require.Equal(t, tt.wantKey, key, tt.handle)
+	}
+}
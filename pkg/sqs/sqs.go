@@ -1,7 +1,7 @@
 /*
 Package sqs wraps github.com/aws/aws-sdk-go-v2/service/sqs with an API that
 covers the common queue workflow: send, receive, decode, acknowledge (delete),
-and health-check.
+and health-check, plus batch operations and a long-running [Worker].
 
 # How It Works
 
@@ -23,7 +23,9 @@ and health-check.
     and [DefaultVisibilityTimeout] (600s).
   - Payloads can be sent/received as raw strings ([Client.Send],
     [Client.Receive]) or typed data ([Client.SendData], [Client.ReceiveData])
-    through pluggable encode/decode hooks.
+    through pluggable encode/decode hooks; the codec.StringEncodeFunc and
+    codec.StringDecodeFunc adapters of github.com/tecnickcom/nurago/pkg/codec
+    plug in its schema-aware JSON, Protobuf and Avro codecs.
   - Configuration and argument problems are reported as exported sentinel
     errors (see [ErrInvalidQueueURL] and the others in this package) that
     callers can match with errors.Is.
@@ -39,6 +41,49 @@ Receive flow behavior:
   - If decode fails, [Client.ReceiveData] still returns the receipt handle so
     callers can choose whether to delete or re-queue according to their policy.
 
+Message attributes and large payloads:
+
+  - [Client.SendWithAttributes] and [SendEntry.Attributes] set String message
+    attributes. The received String and Number attributes are exposed in
+    [Message.Attributes].
+  - The trace ID of the context (github.com/tecnickcom/nurago/pkg/traceid) is
+    sent as the [DefaultTraceIDAttribute] attribute, configurable with
+    [WithTraceIDAttribute]. On receive it is exposed in [Message.TraceID], and
+    the [Worker] stores it in the handler context.
+  - [WithPayloadOffload] enables the extended-client mode: a message larger
+    than the threshold has its body stored in an S3 bucket
+    (github.com/tecnickcom/nurago/pkg/s3), and a pointer to it is sent
+    instead. On receive the body is fetched back transparently, and the object
+    is deleted together with the message by [Client.Delete] or
+    [Client.DeleteBatch]. The format is compatible with the AWS SQS extended
+    client libraries.
+
+Batch and visibility operations:
+
+  - [Client.SendBatch], [Client.ReceiveBatch] and [Client.DeleteBatch] split
+    the entries into requests of up to [MaxBatchSize]. A partial failure is
+    reported as a [BatchError] listing the failed entry indexes, so callers
+    can retry only those. [Client.ReceiveBatch] returns the received messages
+    whose offloaded body cannot be fetched as a [BatchError] too, together with
+    the other messages.
+  - [Client.ChangeVisibility] hides a received message for longer, or makes it
+    visible again at once. [Client.Heartbeat] keeps extending the visibility
+    in the background while a slow message is processed.
+
+# Worker
+
+[NewWorker] creates a [Worker] that polls the queue and runs a [HandlerFunc]
+for each message, up to [WithConcurrency] at a time. Messages handled without
+error are deleted in batches. Failed messages stay in the queue and are
+redelivered after the visibility timeout, or after [WithRetryDelay]. While a
+message is processed, its visibility is extended with [Client.Heartbeat].
+
+[Worker.Start] runs the worker in the background and integrates with the
+github.com/tecnickcom/nurago/pkg/bootstrap shutdown via
+[WithShutdownWaitGroup] and [WithShutdownSignalChan]. On stop the worker
+drains: it stops polling, completes the in-flight messages and deletes the
+successful ones before releasing the wait group.
+
 # Usage
 
 	c, err := sqs.New(ctx,
@@ -65,5 +110,16 @@ Receive flow behavior:
 	if receiptHandle != "" {
 	    _ = c.Delete(ctx, receiptHandle)
 	}
+
+	// Process messages in the background until shutdown
+	w, err := sqs.NewWorker(c, handleMessage,
+	    sqs.WithConcurrency(20),
+	    sqs.WithShutdownWaitGroup(wg),
+	    sqs.WithShutdownSignalChan(sc),
+	)
+	if err != nil {
+	    return err
+	}
+	w.Start(ctx)
 */
 package sqs
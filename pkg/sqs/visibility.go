@@ -0,0 +1,88 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// minHeartbeatInterval is the minimum pause between two visibility extensions.
+const minHeartbeatInterval = time.Second
+
+// ChangeVisibility sets the visibility timeout of a received message to
+// timeout seconds from now (0 to 43200): 0 makes the message immediately
+// visible again, e.g. to retry it.
+func (c *Client) ChangeVisibility(ctx context.Context, receiptHandle string, timeout int32) error {
+	if timeout < 0 || timeout > maxVisibilityTimeout {
+		return ErrInvalidVisibilityTimeout
+	}
+
+	receiptHandle, _ = splitReceiptHandle(receiptHandle)
+
+	_, err := c.sqs.ChangeMessageVisibility(
+		ctx,
+		&sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          c.queueURL,
+			ReceiptHandle:     aws.String(receiptHandle),
+			VisibilityTimeout: timeout,
+		})
+	if err != nil {
+		return fmt.Errorf("cannot change the message visibility: %w", err)
+	}
+
+	return nil
+}
+
+// Heartbeat keeps a received message hidden while it is being processed: in
+// the background, every half visibility timeout (WithVisibilityTimeout), it
+// extends the message visibility timeout by a full one. The returned stop
+// function ends the heartbeat and returns the error that interrupted it, if
+// any; it is safe to call more than once. With a zero visibility timeout the
+// heartbeat does nothing.
+func (c *Client) Heartbeat(ctx context.Context, receiptHandle string) func() error {
+	if c.visibilityTimeout == 0 {
+		return func() error { return nil }
+	}
+
+	interval := max(time.Duration(c.visibilityTimeout)*time.Second/2, minHeartbeatInterval)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				done <- nil
+				return
+			case <-ticker.C:
+				err := c.ChangeVisibility(ctx, receiptHandle, c.visibilityTimeout)
+				if err != nil && ctx.Err() == nil {
+					done <- err
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		once sync.Once
+		err  error
+	)
+
+	return func() error {
+		once.Do(func() {
+			cancel()
+			err = <-done
+		})
+
+		return err
+	}
+}
@@ -0,0 +1,80 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeVisibility(t *testing.T) {
+	t.Parallel()
+
+	var input *sqs.ChangeMessageVisibilityInput
+
+	mock := sqsmock{changeVisibilityFn: func(_ context.Context, params *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+		input = params
+
+		if aws.ToString(params.ReceiptHandle) == "bad" {
+			return nil, errors.New("change error")
+		}
+
+		return &sqs.ChangeMessageVisibilityOutput{}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock))
+	require.NoError(t, err)
+
+	require.NoError(t, cli.ChangeVisibility(t.Context(), "rh", 30))
+	require.Equal(t, "rh", aws.ToString(input.ReceiptHandle))
+	require.Equal(t, int32(30), input.VisibilityTimeout)
+
+	require.Error(t, cli.ChangeVisibility(t.Context(), "bad", 30))
+	require.ErrorIs(t, cli.ChangeVisibility(t.Context(), "rh", -1), ErrInvalidVisibilityTimeout)
+	require.ErrorIs(t, cli.ChangeVisibility(t.Context(), "rh", maxVisibilityTimeout+1), ErrInvalidVisibilityTimeout)
+}
+
+func TestHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	mock := sqsmock{changeVisibilityFn: func(_ context.Context, params *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+		if params.VisibilityTimeout != 2 {
+			return nil, errors.New("unexpected visibility timeout")
+		}
+
+		if calls.Add(1) > 1 {
+			return nil, errors.New("change error")
+		}
+
+		return &sqs.ChangeMessageVisibilityOutput{}, nil
+	}}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock), WithVisibilityTimeout(2))
+	require.NoError(t, err)
+
+	// stopped before the first extension
+	stop := cli.Heartbeat(t.Context(), "rh")
+	require.NoError(t, stop())
+	require.NoError(t, stop())
+	require.Zero(t, calls.Load())
+
+	// the second extension fails
+	stop = cli.Heartbeat(t.Context(), "rh")
+
+	time.Sleep(2500 * time.Millisecond)
+
+	require.Error(t, stop())
+	require.Equal(t, int32(2), calls.Load())
+
+	// zero visibility timeout
+	cli, err = New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(mock), WithVisibilityTimeout(0))
+	require.NoError(t, err)
+	require.NoError(t, cli.Heartbeat(t.Context(), "rh")())
+}
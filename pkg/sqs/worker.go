@@ -0,0 +1,271 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+const (
+	// DefaultWorkerConcurrency is the default number of messages processed in
+	// parallel by a [Worker].
+	DefaultWorkerConcurrency = 10
+
+	// receiveErrorDelay is the pause of a Worker after a failed receive.
+	receiveErrorDelay = time.Second
+
+	// noRetryDelay disables the visibility change of the failed messages.
+	noRetryDelay = -1
+)
+
+// HandlerFunc processes a message received by a [Worker]. Returning nil
+// acknowledges the message, which is then deleted from the queue.
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+// Worker polls a queue and processes the messages with a handler.
+//
+// Create instances with [NewWorker].
+type Worker struct {
+	client             *Client
+	handler            HandlerFunc
+	concurrency        int
+	retryDelay         int32
+	errorHandler       func(error)
+	shutdownWaitGroup  *sync.WaitGroup
+	shutdownSignalChan chan struct{}
+}
+
+// NewWorker returns a Worker processing the messages of the client queue with
+// handler. It returns [ErrNilClient], [ErrNilHandler],
+// [ErrInvalidConcurrency], or [ErrInvalidVisibilityTimeout] for an invalid
+// retry delay.
+func NewWorker(client *Client, handler HandlerFunc, opts ...WorkerOption) (*Worker, error) {
+	if client == nil {
+		return nil, ErrNilClient
+	}
+
+	if handler == nil {
+		return nil, ErrNilHandler
+	}
+
+	w := &Worker{
+		client:       client,
+		handler:      handler,
+		concurrency:  DefaultWorkerConcurrency,
+		retryDelay:   noRetryDelay,
+		errorHandler: func(error) {},
+	}
+
+	for _, applyOpt := range opts {
+		applyOpt(w)
+	}
+
+	if w.concurrency < 1 {
+		return nil, ErrInvalidConcurrency
+	}
+
+	if w.retryDelay != noRetryDelay && (w.retryDelay < 0 || w.retryDelay > maxVisibilityTimeout) {
+		return nil, ErrInvalidVisibilityTimeout
+	}
+
+	if w.errorHandler == nil {
+		w.errorHandler = func(error) {}
+	}
+
+	return w, nil
+}
+
+// Start runs the worker in a background goroutine (see [Worker.Run]). With
+// WithShutdownWaitGroup, the wait group is incremented before Start returns
+// and decremented once the worker is drained, as expected by the
+// github.com/tecnickcom/nurago/pkg/bootstrap shutdown.
+func (w *Worker) Start(ctx context.Context) {
+	if w.shutdownWaitGroup != nil {
+		w.shutdownWaitGroup.Add(1)
+	}
+
+	go func() {
+		if w.shutdownWaitGroup != nil {
+			defer w.shutdownWaitGroup.Done()
+		}
+
+		w.Run(ctx)
+	}()
+}
+
+// Run polls the queue and processes the messages until ctx ends or the
+// WithShutdownSignalChan channel is closed, then drains the worker: it stops
+// polling, waits for the in-flight messages and deletes the processed ones.
+//
+// Up to WithConcurrency messages are processed in parallel; the worker never
+// receives more messages than it can process at once. While a message is
+// processed its visibility timeout is extended with [Client.Heartbeat]. The
+// successful messages are deleted with [Client.DeleteBatch]. A failed message
+// is left in the queue, to be received again after its visibility timeout or
+// after the WithRetryDelay delay, so a queue redrive policy can move the
+// messages failing too many times to a dead-letter queue.
+//
+// The handler context is not canceled by the stop, so the in-flight messages
+// complete: the handlers should bound their own duration. The failures are
+// reported to the WithErrorHandler function, including the received messages
+// that cannot be decoded (a [BatchError] from [Client.ReceiveBatch]), which
+// are left in the queue while the others are processed.
+func (w *Worker) Run(ctx context.Context) {
+	handlerCtx := context.WithoutCancel(ctx)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-w.shutdownSignalChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	slots := make(chan struct{}, w.concurrency)
+	acks := make(chan string, w.concurrency)
+	deleted := make(chan struct{})
+
+	go func() {
+		defer close(deleted)
+
+		w.deleteAcked(handlerCtx, acks)
+	}()
+
+	var handlers sync.WaitGroup
+
+	for w.acquire(ctx, slots) {
+		n := 1 + w.acquireFree(slots, MaxBatchSize-1)
+
+		msgs, err := w.client.ReceiveBatch(ctx, n)
+
+		for range n - len(msgs) {
+			<-slots
+		}
+
+		if err != nil && ctx.Err() == nil {
+			w.errorHandler(err)
+
+			var berr *BatchError
+			if !errors.As(err, &berr) {
+				sleep(ctx, receiveErrorDelay)
+			}
+		}
+
+		// on a partial failure the other messages are still processed
+		for _, msg := range msgs {
+			handlers.Go(func() {
+				defer func() { <-slots }()
+
+				w.process(handlerCtx, msg, acks)
+			})
+		}
+	}
+
+	handlers.Wait()
+	close(acks)
+	<-deleted
+}
+
+// acquire waits for a free processing slot, returning false when ctx ends.
+func (w *Worker) acquire(ctx context.Context, slots chan<- struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// acquireFree acquires up to limit of the currently free processing slots
+// without waiting, and returns their number.
+func (w *Worker) acquireFree(slots chan<- struct{}, limit int) int {
+	for n := range limit {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return n
+		}
+	}
+
+	return limit
+}
+
+// process handles a message, sending its receipt handle to acks on success.
+// The message trace ID, if any, is stored in the handler context.
+func (w *Worker) process(ctx context.Context, msg *Message, acks chan<- string) {
+	ctx = traceid.ForceContext(ctx, msg.TraceID)
+
+	stop := w.client.Heartbeat(ctx, msg.ReceiptHandle)
+
+	err := w.handler(ctx, msg)
+
+	herr := stop()
+	if herr != nil {
+		w.errorHandler(fmt.Errorf("sqs: message heartbeat failed: %w", herr))
+	}
+
+	if err == nil {
+		acks <- msg.ReceiptHandle
+		return
+	}
+
+	w.errorHandler(fmt.Errorf("sqs: message handler failed: %w", err))
+
+	if w.retryDelay == noRetryDelay {
+		return
+	}
+
+	err = w.client.ChangeVisibility(ctx, msg.ReceiptHandle, w.retryDelay)
+	if err != nil {
+		w.errorHandler(err)
+	}
+}
+
+// deleteAcked deletes the acknowledged messages, batching the receipt handles
+// available at once, until acks is closed.
+func (w *Worker) deleteAcked(ctx context.Context, acks <-chan string) {
+	for h := range acks {
+		handles := append(make([]string, 0, MaxBatchSize), h)
+
+	collect:
+		for len(handles) < MaxBatchSize {
+			select {
+			case h, ok := <-acks:
+				if !ok {
+					break collect
+				}
+
+				handles = append(handles, h)
+			default:
+				break collect
+			}
+		}
+
+		err := w.client.DeleteBatch(ctx, handles)
+		if err != nil {
+			w.errorHandler(err)
+		}
+	}
+}
+
+// sleep waits for d or until ctx ends.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,361 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tecnickcom/nurago/pkg/s3"
+	"github.com/tecnickcom/nurago/pkg/traceid"
+)
+
+func TestNewWorker(t *testing.T) {
+	t.Parallel()
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(sqsmock{}))
+	require.NoError(t, err)
+
+	handler := func(_ context.Context, _ *Message) error { return nil }
+
+	tests := []struct {
+		name    string
+		client  *Client
+		handler HandlerFunc
+		opts    []WorkerOption
+		wantErr error
+	}{
+		{
+			name:    "success",
+			client:  cli,
+			handler: handler,
+			opts:    []WorkerOption{WithConcurrency(1), WithRetryDelay(0), WithErrorHandler(nil)},
+		},
+		{
+			name:    "nil client",
+			handler: handler,
+			wantErr: ErrNilClient,
+		},
+		{
+			name:    "nil handler",
+			client:  cli,
+			wantErr: ErrNilHandler,
+		},
+		{
+			name:    "invalid concurrency",
+			client:  cli,
+			handler: handler,
+			opts:    []WorkerOption{WithConcurrency(0)},
+			wantErr: ErrInvalidConcurrency,
+		},
+		{
+			name:    "invalid retry delay",
+			client:  cli,
+			handler: handler,
+			opts:    []WorkerOption{WithRetryDelay(maxVisibilityTimeout + 1)},
+			wantErr: ErrInvalidVisibilityTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, err := NewWorker(tt.client, tt.handler, tt.opts...)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, w)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, w)
+			require.NotNil(t, w.errorHandler)
+		})
+	}
+}
+
+// fakeQueue is a concurrency-safe in-memory queue backing a sqsmock.
+type fakeQueue struct {
+	mu         sync.Mutex
+	pending    []string
+	deleted    []string
+	retried    map[string]int32
+	maxReceive int32
+	errReceive error
+	offloaded  map[string]bool
+}
+
+func (q *fakeQueue) mock() sqsmock {
+	return sqsmock{
+		receiveFn: func(_ context.Context, params *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			q.maxReceive = max(q.maxReceive, params.MaxNumberOfMessages)
+
+			if q.errReceive != nil {
+				err := q.errReceive
+				q.errReceive = nil
+
+				return nil, err
+			}
+
+			if len(q.pending) == 0 {
+				time.Sleep(time.Millisecond)
+				return &sqs.ReceiveMessageOutput{}, nil
+			}
+
+			n := min(int(params.MaxNumberOfMessages), len(q.pending))
+			out := &sqs.ReceiveMessageOutput{}
+
+			for _, body := range q.pending[:n] {
+				attrs := map[string]types.MessageAttributeValue{
+					DefaultTraceIDAttribute: {DataType: aws.String("String"), StringValue: aws.String("trace-" + body)},
+				}
+
+				if q.offloaded[body] {
+					attrs[payloadSizeAttribute] = types.MessageAttributeValue{DataType: aws.String("Number"), StringValue: aws.String("1")}
+				}
+
+				out.Messages = append(out.Messages, types.Message{
+					Body:              aws.String(body),
+					ReceiptHandle:     aws.String("rh-" + body),
+					MessageAttributes: attrs,
+				})
+			}
+
+			q.pending = q.pending[n:]
+
+			return out, nil
+		},
+		deleteBatchFn: func(_ context.Context, params *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			for _, e := range params.Entries {
+				q.deleted = append(q.deleted, aws.ToString(e.ReceiptHandle))
+			}
+
+			return &sqs.DeleteMessageBatchOutput{}, nil
+		},
+		changeVisibilityFn: func(_ context.Context, params *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+
+			q.retried[aws.ToString(params.ReceiptHandle)] = params.VisibilityTimeout
+
+			return &sqs.ChangeMessageVisibilityOutput{}, nil
+		},
+	}
+}
+
+func TestWorker_Start(t *testing.T) {
+	t.Parallel()
+
+	const total = 25
+
+	q := &fakeQueue{retried: make(map[string]int32)}
+
+	for i := range total {
+		q.pending = append(q.pending, strconv.Itoa(i))
+	}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(q.mock()), WithVisibilityTimeout(0))
+	require.NoError(t, err)
+
+	var (
+		processed atomic.Int32
+		running   atomic.Int32
+		peak      atomic.Int32
+		errCount  atomic.Int32
+	)
+
+	handler := func(_ context.Context, msg *Message) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		processed.Add(1)
+
+		if msg.Body == "7" {
+			return errors.New("handler error")
+		}
+
+		return nil
+	}
+
+	wg := &sync.WaitGroup{}
+	sigch := make(chan struct{})
+
+	w, err := NewWorker(
+		cli,
+		handler,
+		WithConcurrency(3),
+		WithRetryDelay(5),
+		WithErrorHandler(func(error) { errCount.Add(1) }),
+		WithShutdownWaitGroup(wg),
+		WithShutdownSignalChan(sigch),
+	)
+	require.NoError(t, err)
+
+	w.Start(t.Context())
+
+	require.Eventually(t, func() bool { return processed.Load() == total }, 5*time.Second, 5*time.Millisecond)
+
+	close(sigch)
+	wg.Wait()
+
+	require.LessOrEqual(t, peak.Load(), int32(3))
+	require.LessOrEqual(t, q.maxReceive, int32(3))
+	require.Len(t, q.deleted, total-1)
+	require.NotContains(t, q.deleted, "rh-7")
+	require.Equal(t, map[string]int32{"rh-7": 5}, q.retried)
+	require.Equal(t, int32(1), errCount.Load())
+}
+
+func TestWorker_Run_drain(t *testing.T) {
+	t.Parallel()
+
+	q := &fakeQueue{
+		pending:    []string{"a"},
+		retried:    make(map[string]int32),
+		errReceive: errors.New("receive error"),
+	}
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "", WithSQSClient(q.mock()), WithVisibilityTimeout(0))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var (
+		errs    []error
+		traceID string
+	)
+
+	handler := func(ctx context.Context, _ *Message) error {
+		traceID = traceid.FromContext(ctx, "")
+
+		close(started)
+		<-release
+
+		return ctx.Err()
+	}
+
+	w, err := NewWorker(cli, handler, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		w.Run(ctx)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("the worker returned before draining the in-flight message")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+
+	require.Equal(t, "trace-a", traceID)
+	require.Equal(t, []string{"rh-a"}, q.deleted)
+	require.Empty(t, q.retried)
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "receive error")
+}
+
+func TestWorker_Run_partialReceive(t *testing.T) {
+	t.Parallel()
+
+	q := &fakeQueue{
+		pending:   []string{"a", "bad", "b"},
+		retried:   make(map[string]int32),
+		offloaded: map[string]bool{"bad": true},
+	}
+
+	s3c, err := s3.New(t.Context(), "bucket", s3.WithS3Client(&s3store{objects: make(map[string]string)}))
+	require.NoError(t, err)
+
+	cli, err := New(t.Context(), "https://test_queue.invalid/queue1", "",
+		WithSQSClient(q.mock()), WithVisibilityTimeout(0), WithPayloadOffload(s3c, 0))
+	require.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		processed []string
+		errs      []error
+	)
+
+	handler := func(_ context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		processed = append(processed, msg.Body)
+
+		return nil
+	}
+
+	errHandler := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs = append(errs, err)
+	}
+
+	w, err := NewWorker(cli, handler, WithConcurrency(3), WithErrorHandler(errHandler))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		w.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(processed) == 2
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	require.ElementsMatch(t, []string{"a", "b"}, processed)
+	require.ElementsMatch(t, []string{"rh-a", "rh-b"}, q.deleted)
+	require.Len(t, errs, 1)
+
+	var berr *BatchError
+
+	require.ErrorAs(t, errs[0], &berr)
+	require.ErrorIs(t, errs[0], ErrInvalidPayloadPointer)
+	require.Equal(t, []int{1}, berr.Indexes())
+}
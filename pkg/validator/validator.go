@@ -168,6 +168,15 @@ func (v *Validator) tagError(fe vt.FieldError, tag string) error {
 	return ve
 }
 
+// Translate sets the message of ve from the error templates, and returns ve.
+// It lets other packages report their own validation failures with the same
+// messages as [Validator.ValidateStruct].
+func (v *Validator) Translate(ve *Error) *Error {
+	ve.Err = v.translate(ve)
+
+	return ve
+}
+
 // translate returns the error message associated with the tag.
 func (v *Validator) translate(ve *Error) string {
 	if t, ok := v.tpl[ve.Tag]; ok {
@@ -248,3 +248,16 @@ func TestValidator_ValidateStruct_falseifPrefix(t *testing.T) {
 	require.Len(t, errs, 1, "errors: %+v", errs)
 	require.Contains(t, errs[0].Error(), "falseifoo", "error must reference falseifoo: %q", errs[0].Error())
 }
+
+func TestValidator_Translate(t *testing.T) {
+	t.Parallel()
+
+	v, err := New(WithErrorTemplates(map[string]string{"min": `{{.Namespace}} must be at least {{.Param}}`}))
+	require.NoError(t, err)
+
+	ve := v.Translate(&Error{Tag: "min", Param: "3", FullTag: "min=3", Namespace: "query.limit"})
+	require.Equal(t, "query.limit must be at least 3", ve.Error())
+
+	ve = v.Translate(&Error{Tag: "custom", FullTag: "custom", Namespace: "body.name"})
+	require.Equal(t, "body.name is invalid because fails the rule: 'custom'", ve.Error())
+}
@@ -190,6 +190,26 @@ func (c *Client) Set(ctx context.Context, key string, value string, exp time.Dur
 	return nil
 }
 
+// Eval runs a Lua script on the server with the given keys and arguments and
+// returns its reply: an int64, a string, a []any of replies, or nil for a nil
+// reply. The script runs atomically, so it can implement read-modify-write
+// operations (e.g. rate-limit counters) without races between clients. keys
+// must list every key the script accesses, as required by Valkey Cluster.
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...string) (any, error) {
+	cmd := c.vkclient.B().Eval().Script(script).Numkeys(int64(len(keys))).Key(keys...).Arg(args...).Build()
+
+	res, err := c.vkclient.Do(ctx, cmd).ToAny()
+	if err != nil {
+		if errors.Is(err, libvalkey.Nil) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot evaluate script: %w", err)
+	}
+
+	return res, nil
+}
+
 // Get retrieves the raw string value for key.
 //
 // When the key does not exist, the returned error satisfies
@@ -191,6 +191,75 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mock    func(ctx context.Context, vkc *mock.Client)
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "success",
+			mock: func(ctx context.Context, vkc *mock.Client) {
+				vkc.EXPECT().Do(
+					ctx,
+					mock.Match("EVAL", "return 1", "1", "key1", "a", "b"),
+				).Return(mock.Result(mock.ValkeyArray(mock.ValkeyInt64(1), mock.ValkeyInt64(2))))
+			},
+			want: []any{int64(1), int64(2)},
+		},
+		{
+			name: "nil reply",
+			mock: func(ctx context.Context, vkc *mock.Client) {
+				vkc.EXPECT().Do(
+					ctx,
+					mock.Match("EVAL", "return 1", "1", "key1", "a", "b"),
+				).Return(mock.Result(mock.ValkeyNil()))
+			},
+			want: nil,
+		},
+		{
+			name: "error",
+			mock: func(ctx context.Context, vkc *mock.Client) {
+				vkc.EXPECT().Do(
+					ctx,
+					mock.Match("EVAL", "return 1", "1", "key1", "a", "b"),
+				).Return(mock.ErrorResult(errors.New("test error")))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(func() { ctrl.Finish() })
+
+			vkc := mock.NewClient(ctrl)
+			ctx := t.Context()
+
+			cli, err := New(ctx, getTestSrvOptions(), WithValkeyClient(vkc))
+			require.NoError(t, err)
+
+			tt.mock(ctx, vkc)
+
+			got, err := cli.Eval(ctx, "return 1", []string{"key1"}, "a", "b")
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestGet(t *testing.T) {
 	t.Parallel()
 